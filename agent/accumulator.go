@@ -17,6 +17,9 @@ type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
 	precision time.Duration
+
+	// tap is called with each metric before passing it on, if set
+	tap func(telegraf.Metric)
 }
 
 func NewAccumulator(
@@ -31,6 +34,22 @@ func NewAccumulator(
 	return &acc
 }
 
+// newTapAccumulator returns an accumulator passing each metric to the tap
+// function before sending it to the channel
+func newTapAccumulator(
+	maker MetricMaker,
+	metrics chan<- telegraf.Metric,
+	tap func(telegraf.Metric),
+) telegraf.Accumulator {
+	acc := accumulator{
+		maker:     maker,
+		metrics:   metrics,
+		precision: time.Nanosecond,
+		tap:       tap,
+	}
+	return &acc
+}
+
 func (ac *accumulator) AddFields(
 	measurement string,
 	fields map[string]interface{},
@@ -79,7 +98,7 @@ func (ac *accumulator) AddHistogram(
 func (ac *accumulator) AddMetric(m telegraf.Metric) {
 	m.SetTime(m.Time().Round(ac.precision))
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
 	}
 }
 
@@ -92,8 +111,15 @@ func (ac *accumulator) addMeasurement(
 ) {
 	m := metric.New(measurement, tags, fields, ac.getTime(t), tp)
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
+	}
+}
+
+func (ac *accumulator) send(m telegraf.Metric) {
+	if ac.tap != nil {
+		ac.tap(m)
 	}
+	ac.metrics <- m
 }
 
 // AddError passes a runtime error to the accumulator.
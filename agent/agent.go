@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"runtime"
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
@@ -24,6 +26,8 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	tap *tap
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -99,6 +103,10 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// tapProcessed is set if the metrics on the channel are the ones leaving
+	// the processors, i.e. there are no aggregators in between
+	tapProcessed bool
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -135,6 +143,17 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	if a.Config.Agent.TapSocket != "" {
+		t, err := newTap(a.Config.Agent.TapSocket, a.Config.Inputs, a.Config.Outputs)
+		if err != nil {
+			return err
+		}
+		log.Printf("I! [agent] Serving pipeline tap on %q", a.Config.Agent.TapSocket)
+		a.tap = t
+		go t.serve()
+		defer t.close()
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -155,6 +174,8 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 
 		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
+	} else {
+		ou.tapProcessed = true
 	}
 
 	var pu []*processorUnit
@@ -285,6 +306,11 @@ func (a *Agent) initPersister() error {
 			if !ok {
 				continue
 			}
+		} else if p, ok := processor.Processor.(processors.HasBatchUnwrap); ok {
+			plugin, ok = p.UnwrapBatch().(telegraf.StatefulPlugin)
+			if !ok {
+				continue
+			}
 		} else {
 			plugin, ok = processor.Processor.(telegraf.StatefulPlugin)
 			if !ok {
@@ -300,14 +326,15 @@ func (a *Agent) initPersister() error {
 	}
 
 	for _, aggregator := range a.Config.Aggregators {
-		plugin, ok := aggregator.Aggregator.(telegraf.StatefulPlugin)
-		if !ok {
+		if !persistAggregator(aggregator, a.Config.Persister) {
 			continue
 		}
 
+		// Register the running aggregator to store the aggregation window
+		// along with the state of the plugin
 		name := aggregator.LogName()
 		id := aggregator.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register(id, aggregator); err != nil {
 			return fmt.Errorf("could not register aggregator %s: %w", name, err)
 		}
 	}
@@ -341,7 +368,7 @@ func (a *Agent) initPersister() error {
 	return nil
 }
 
-func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) (*inputUnit, error) {
+func (a *Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) (*inputUnit, error) {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
@@ -361,7 +388,7 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 			precision = input.Config.Precision
 		}
 
-		acc := NewAccumulator(input, dst)
+		acc := newTapAccumulator(input, dst, a.tap.inputFunc(input))
 		acc.SetPrecision(getPrecision(precision, interval))
 
 		if err := input.Start(acc); err != nil {
@@ -432,7 +459,7 @@ func (a *Agent) runInputs(
 		}
 		tickers = append(tickers, ticker)
 
-		acc := NewAccumulator(input, unit.dst)
+		acc := newTapAccumulator(input, unit.dst, a.tap.inputFunc(input))
 		acc.SetPrecision(getPrecision(precision, interval))
 
 		wg.Add(1)
@@ -648,18 +675,39 @@ func (*Agent) startProcessors(dst chan<- telegraf.Metric, runningProcessors mode
 }
 
 // runProcessors begins processing metrics and runs until the source channel is closed and all metrics have been written.
-func (*Agent) runProcessors(units []*processorUnit) {
+// Batch processors are flushed every flush interval and when stopping.
+func (a *Agent) runProcessors(units []*processorUnit) {
 	var wg sync.WaitGroup
 	for _, unit := range units {
 		wg.Add(1)
 		go func(unit *processorUnit) {
 			defer wg.Done()
 
+			var flushC <-chan time.Time
+			if unit.processor.Batching() {
+				interval := time.Duration(a.Config.Agent.FlushInterval)
+				if unit.processor.Config.FlushInterval != 0 {
+					interval = unit.processor.Config.FlushInterval
+				}
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				flushC = ticker.C
+			}
+
 			acc := NewAccumulator(unit.processor, unit.dst)
-			for m := range unit.src {
-				if err := unit.processor.Add(m, acc); err != nil {
-					acc.AddError(err)
-					m.Drop()
+		loop:
+			for {
+				select {
+				case <-flushC:
+					unit.processor.Flush()
+				case m, ok := <-unit.src:
+					if !ok {
+						break loop
+					}
+					if err := unit.processor.Add(m, acc); err != nil {
+						acc.AddError(err)
+						m.Drop()
+					}
 				}
 			}
 			unit.processor.Stop()
@@ -690,11 +738,22 @@ func (a *Agent) runAggregators(
 ) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	interval := time.Duration(a.Config.Agent.Interval)
+	precision := time.Duration(a.Config.Agent.Precision)
+
 	// Before calling Add, initialize the aggregation window.  This ensures
-	// that any metric created after start time will be aggregated.
+	// that any metric created after start time will be aggregated. Data of
+	// windows restored from the state but ended in the meantime is pushed
+	// first to not mix it with the new window.
+	accs := make([]telegraf.Accumulator, 0, len(a.Config.Aggregators))
 	for _, agg := range a.Config.Aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
+
+		acc := NewAccumulator(agg, unit.aggC)
+		acc.SetPrecision(getPrecision(precision, interval))
+		agg.PushRestored(acc)
+		accs = append(accs, acc)
 	}
 
 	var wg sync.WaitGroup
@@ -702,6 +761,8 @@ func (a *Agent) runAggregators(
 	go func() {
 		defer wg.Done()
 		for metric := range unit.src {
+			a.tap.publish(TapStageProcessors, "", "", metric)
+
 			var dropOriginal bool
 			for _, agg := range a.Config.Aggregators {
 				if ok := agg.Add(metric); ok {
@@ -718,18 +779,12 @@ func (a *Agent) runAggregators(
 		cancel()
 	}()
 
-	for _, agg := range a.Config.Aggregators {
+	for i, agg := range a.Config.Aggregators {
 		wg.Add(1)
-		go func(agg *models.RunningAggregator) {
+		go func(agg *models.RunningAggregator, acc telegraf.Accumulator) {
 			defer wg.Done()
-
-			interval := time.Duration(a.Config.Agent.Interval)
-			precision := time.Duration(a.Config.Agent.Precision)
-
-			acc := NewAccumulator(agg, unit.aggC)
-			acc.SetPrecision(getPrecision(precision, interval))
 			a.push(ctx, agg, acc)
-		}(agg)
+		}(agg, accs[i])
 	}
 
 	wg.Wait()
@@ -757,7 +812,11 @@ func updateWindow(start time.Time, roundInterval bool, period time.Duration) (si
 }
 
 // push runs the push for a single aggregator every period.
-func (*Agent) push(ctx context.Context, aggregator *models.RunningAggregator, acc telegraf.Accumulator) {
+func (a *Agent) push(ctx context.Context, aggregator *models.RunningAggregator, acc telegraf.Accumulator) {
+	// Persisted aggregators keep the data of the incomplete window in the
+	// state instead of pushing partial results on shutdown
+	persisted := persistAggregator(aggregator, a.Config.Persister)
+
 	for {
 		// Ensures that Push will be called for each period, even if it has
 		// already elapsed before this function is called.  This is guaranteed
@@ -769,12 +828,22 @@ func (*Agent) push(ctx context.Context, aggregator *models.RunningAggregator, ac
 		case <-time.After(until):
 			aggregator.Push(acc)
 		case <-ctx.Done():
-			aggregator.Push(acc)
+			if !persisted {
+				aggregator.Push(acc)
+			}
 			return
 		}
 	}
 }
 
+// persistAggregator returns true if the state of the aggregator, including
+// the data of the current window, is persisted across restarts. This is
+// opt-in per aggregator as it changes the behavior on shutdown.
+func persistAggregator(aggregator *models.RunningAggregator, p *persister.Persister) bool {
+	_, stateful := aggregator.Aggregator.(telegraf.StatefulPlugin)
+	return stateful && aggregator.Config.PersistState && p != nil
+}
+
 // startOutputs calls Connect on all outputs and returns the source channel.
 // If an error occurs calling Connect, all started plugins have Close called.
 func (a *Agent) startOutputs(
@@ -862,7 +931,11 @@ func (a *Agent) runOutputs(
 	}
 
 	for metric := range unit.src {
+		if unit.tapProcessed {
+			a.tap.publish(TapStageProcessors, "", "", metric)
+		}
 		for i, output := range unit.outputs {
+			a.tap.publish(TapStageOutput, output.Config.Name, output.Config.Alias, metric)
 			if i == len(unit.outputs)-1 {
 				output.AddMetricNoCopy(metric)
 			} else {
@@ -1070,20 +1143,64 @@ func (a *Agent) Once(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
+	// Report the delivery for each output to allow checking failures
+	undelivered := writeDeliverySummary(os.Stderr, a.Config.Outputs)
+
 	if models.GlobalGatherErrors.Get() != 0 {
 		return fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
 	}
 
-	unsent := 0
-	for _, output := range a.Config.Outputs {
-		unsent += output.BufferLength()
-	}
-	if unsent != 0 {
-		return fmt.Errorf("output plugins unable to send %d metrics", unsent)
+	if undelivered != 0 {
+		return fmt.Errorf("output plugins unable to deliver %d metrics", undelivered)
 	}
 	return nil
 }
 
+// writeDeliverySummary writes the number of metrics handled by each output to
+// the given writer and returns the number of metrics not delivered. Metrics
+// rejected by the output, dropped due to a full buffer or still in the buffer
+// count as not delivered while filtered metrics are intentionally not sent.
+// Metrics exceeding the output's metric_max_age are reported as expired and
+// not counted as delivery failures, as they were never passed to the output.
+func writeDeliverySummary(w io.Writer, outputs []*models.RunningOutput) int64 {
+	if len(outputs) == 0 {
+		return 0
+	}
+
+	var undelivered int64
+	fmt.Fprintln(w, "Delivery summary:")
+	for _, output := range outputs {
+		stats := output.BufferStats()
+		unsent := int64(output.BufferLength())
+
+		// Expired metrics are removed from the buffer by rejecting them so
+		// exclude them from the rejected ones
+		var expired int64
+		if output.MetricsExpired != nil {
+			expired = output.MetricsExpired.Get()
+		}
+		rejected := stats.MetricsRejected.Get() - expired
+		failed := rejected + stats.MetricsDropped.Get() + unsent
+
+		status := "ok"
+		if failed > 0 {
+			status = "failed"
+		}
+		fmt.Fprintf(w, "  %s: %s (%d written, %d filtered, %d expired, %d rejected, %d dropped, %d unsent)\n",
+			output.LogName(),
+			status,
+			stats.MetricsWritten.Get(),
+			output.MetricsFiltered.Get(),
+			expired,
+			rejected,
+			stats.MetricsDropped.Get(),
+			unsent,
+		)
+		undelivered += failed
+	}
+	return undelivered
+}
+
 // runOnce runs the agent and performs a single gather sending output to the
 // outputC. After gathering pauses for the wait duration to allow service
 // inputs to run.
@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,8 +20,10 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -257,3 +261,155 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+func TestDeliverySummary(t *testing.T) {
+	delivered := models.NewRunningOutput(
+		&deliveryOutput{},
+		&models.OutputConfig{Name: "delivered", ID: "delivery-summary-ok"},
+		10, 100,
+	)
+	failing := models.NewRunningOutput(
+		&deliveryOutput{err: errors.New("connection refused")},
+		&models.OutputConfig{Name: "failing", Alias: "remote", ID: "delivery-summary-failed"},
+		10, 100,
+	)
+	expiring := models.NewRunningOutput(
+		&deliveryOutput{},
+		&models.OutputConfig{Name: "expiring", ID: "delivery-summary-expired", MetricMaxAge: time.Hour},
+		10, 100,
+	)
+	fields := []string{
+		"errors", "metrics_filtered", "write_time_ns", "startup_errors",
+		"metrics_added", "metrics_written", "metrics_rejected", "metrics_dropped", "buffer_size", "buffer_limit",
+	}
+	for _, tags := range []map[string]string{
+		{"_id": "delivery-summary-ok", "output": "delivered"},
+		{"_id": "delivery-summary-failed", "output": "failing", "alias": "remote"},
+		{"_id": "delivery-summary-expired", "output": "expiring"},
+	} {
+		for _, field := range fields {
+			defer selfstat.Unregister("write", field, tags)
+		}
+	}
+	defer selfstat.Unregister("write", "metrics_expired", map[string]string{"_id": "delivery-summary-expired", "output": "expiring"})
+
+	// The test metrics are far older than the age limit of the expiring output
+	outputs := []*models.RunningOutput{delivered, failing, expiring}
+	for _, output := range outputs {
+		output.AddMetric(testutil.TestMetric(1.0))
+		output.AddMetric(testutil.TestMetric(2.0))
+		require.NoError(t, output.Init())
+		_ = output.Write()
+	}
+
+	var buf bytes.Buffer
+	undelivered := writeDeliverySummary(&buf, outputs)
+	require.Equal(t, int64(2), undelivered)
+
+	expected := "Delivery summary:\n" +
+		"  outputs.delivered: ok (2 written, 0 filtered, 0 expired, 0 rejected, 0 dropped, 0 unsent)\n" +
+		"  outputs.failing::remote: failed (0 written, 0 filtered, 0 expired, 0 rejected, 0 dropped, 2 unsent)\n" +
+		"  outputs.expiring: ok (0 written, 0 filtered, 2 expired, 0 rejected, 0 dropped, 0 unsent)\n"
+	require.Equal(t, expected, buf.String())
+}
+
+// deliveryOutput is an output returning the given error on write
+type deliveryOutput struct {
+	err error
+}
+
+func (*deliveryOutput) SampleConfig() string {
+	return ""
+}
+
+func (*deliveryOutput) Connect() error {
+	return nil
+}
+
+func (*deliveryOutput) Close() error {
+	return nil
+}
+
+func (o *deliveryOutput) Write([]telegraf.Metric) error {
+	return o.err
+}
+
+func TestAggregatorPushOnShutdown(t *testing.T) {
+	tests := []struct {
+		name         string
+		persistState bool
+		statefile    bool
+		expected     int
+	}{
+		{
+			name:      "statefile without persist_state",
+			statefile: true,
+			expected:  1,
+		},
+		{
+			name:         "persist_state without statefile",
+			persistState: true,
+			expected:     1,
+		},
+		{
+			name:         "persisted",
+			persistState: true,
+			statefile:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			if tt.statefile {
+				cfg.Persister = &persister.Persister{Filename: filepath.Join(t.TempDir(), "state.json")}
+			}
+			a := NewAgent(cfg)
+
+			tags := map[string]string{"_id": "shutdown-push", "aggregator": "stateful"}
+			for _, field := range []string{"errors", "metrics_pushed", "metrics_filtered", "metrics_dropped", "push_time_ns"} {
+				defer selfstat.Unregister("aggregate", field, tags)
+			}
+			agg := models.NewRunningAggregator(&statefulAggregator{}, &models.AggregatorConfig{
+				Name:         "stateful",
+				ID:           "shutdown-push",
+				Period:       time.Hour,
+				PersistState: tt.persistState,
+			})
+			since, until := updateWindow(time.Now(), true, agg.Period())
+			agg.UpdateWindow(since, until)
+
+			// The aggregator must only skip pushing the incomplete window on
+			// shutdown if its state is persisted
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+			var acc testutil.Accumulator
+			a.push(ctx, agg, &acc)
+			require.Len(t, acc.GetTelegrafMetrics(), tt.expected)
+		})
+	}
+}
+
+// statefulAggregator is an aggregator implementing telegraf.StatefulPlugin
+// pushing a single metric
+type statefulAggregator struct{}
+
+func (*statefulAggregator) SampleConfig() string {
+	return ""
+}
+
+func (*statefulAggregator) Add(telegraf.Metric) {}
+
+func (*statefulAggregator) Push(acc telegraf.Accumulator) {
+	acc.AddFields("stateful", map[string]interface{}{"value": 1}, nil)
+}
+
+func (*statefulAggregator) Reset() {}
+
+func (*statefulAggregator) GetState() interface{} {
+	return 0
+}
+
+func (*statefulAggregator) SetState(interface{}) error {
+	return nil
+}
@@ -0,0 +1,290 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Stages of the pipeline the metrics can be tapped at
+const (
+	TapStageInput      = "input"
+	TapStageProcessors = "processors"
+	TapStageOutput     = "output"
+)
+
+// Number of metrics buffered per tap client, further metrics are dropped
+// if the client cannot keep up.
+const tapBufferSize = 1000
+
+// TapRequest is sent by a client as single JSON line to subscribe to the
+// metrics passing a stage of the pipeline.
+type TapRequest struct {
+	// Stage to tap the metrics at, one of "input", "processors" or "output"
+	Stage string `json:"stage"`
+	// Plugin is the name or alias of the input or output to tap
+	Plugin string `json:"plugin,omitempty"`
+	// SampleRate is the fraction of metrics to send, all metrics if zero
+	SampleRate float64 `json:"sample_rate,omitempty"`
+}
+
+// tap serves a local socket streaming the metrics passing a stage of the
+// running pipeline to the connected clients in line protocol.
+type tap struct {
+	listener net.Listener
+	inputs   map[string]bool
+	outputs  map[string]bool
+
+	sync.RWMutex
+	conns       map[net.Conn]bool
+	subscribers map[*tapSubscriber]bool
+	active      atomic.Int32
+	wg          sync.WaitGroup
+}
+
+type tapSubscriber struct {
+	TapRequest
+	metrics chan telegraf.Metric
+	dropped atomic.Uint64
+}
+
+func newTap(address string, inputs []*models.RunningInput, outputs []*models.RunningOutput) (*tap, error) {
+	// Remove a socket left over by a previous run but never other files
+	if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(address); err != nil {
+			return nil, fmt.Errorf("removing stale tap socket failed: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening on tap socket failed: %w", err)
+	}
+	// The socket exposes all metrics so restrict it to the user running
+	// Telegraf.
+	if err := os.Chmod(address, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("setting tap socket permissions failed: %w", err)
+	}
+
+	t := &tap{
+		listener:    listener,
+		inputs:      make(map[string]bool, len(inputs)),
+		outputs:     make(map[string]bool, len(outputs)),
+		conns:       make(map[net.Conn]bool),
+		subscribers: make(map[*tapSubscriber]bool),
+	}
+	for _, input := range inputs {
+		t.inputs[input.Config.Name] = true
+		if input.Config.Alias != "" {
+			t.inputs[input.Config.Alias] = true
+		}
+	}
+	for _, output := range outputs {
+		t.outputs[output.Config.Name] = true
+		if output.Config.Alias != "" {
+			t.outputs[output.Config.Alias] = true
+		}
+	}
+
+	return t, nil
+}
+
+// serve accepts clients until the tap is closed
+func (t *tap) serve() {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("E! [agent] Accepting tap client failed: %v", err)
+			}
+			return
+		}
+
+		t.Lock()
+		t.conns[conn] = true
+		t.Unlock()
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.handle(conn)
+
+			t.Lock()
+			delete(t.conns, conn)
+			t.Unlock()
+		}()
+	}
+}
+
+func (t *tap) close() {
+	t.listener.Close()
+
+	t.Lock()
+	for conn := range t.conns {
+		conn.Close()
+	}
+	t.Unlock()
+
+	t.wg.Wait()
+}
+
+// handle reads the request of the client and streams the metrics until
+// the client disconnects or the tap is closed
+func (t *tap) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return
+	}
+	reader := bufio.NewReader(io.LimitReader(conn, 4096))
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		fmt.Fprintf(conn, "# error: reading request failed: %v\n", err)
+		return
+	}
+	var req TapRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		fmt.Fprintf(conn, "# error: decoding request failed: %v\n", err)
+		return
+	}
+	if err := t.validate(&req); err != nil {
+		fmt.Fprintf(conn, "# error: %v\n", err)
+		return
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	sub := &tapSubscriber{
+		TapRequest: req,
+		metrics:    make(chan telegraf.Metric, tapBufferSize),
+	}
+	t.subscribe(sub)
+	defer t.unsubscribe(sub)
+	log.Printf("I! [agent] Tap client attached to stage %q", describeTapStage(req))
+
+	// The client does not send anything after the request, so a finished
+	// read means the client disconnected
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(io.Discard, conn) //nolint:errcheck // any error means the client is gone
+	}()
+
+	serializer := &influx.Serializer{SortFields: true, UintSupport: true}
+	for {
+		select {
+		case <-done:
+			log.Printf("I! [agent] Tap client detached from stage %q, %d metrics dropped", describeTapStage(req), sub.dropped.Load())
+			return
+		case m := <-sub.metrics:
+			octets, err := serializer.Serialize(m)
+			if err != nil {
+				log.Printf("D! [agent] Serializing metric for tap client failed: %v", err)
+				continue
+			}
+			if _, err := conn.Write(octets); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// validate checks that the request references an existing stage and plugin
+func (t *tap) validate(req *TapRequest) error {
+	switch req.Stage {
+	case TapStageInput:
+		if !t.inputs[req.Plugin] {
+			return fmt.Errorf("no input with name or alias %q", req.Plugin)
+		}
+	case TapStageOutput:
+		if !t.outputs[req.Plugin] {
+			return fmt.Errorf("no output with name or alias %q", req.Plugin)
+		}
+	case TapStageProcessors:
+		if req.Plugin != "" {
+			return errors.New("processors stage does not accept a plugin")
+		}
+	default:
+		return fmt.Errorf("unknown stage %q", req.Stage)
+	}
+
+	if req.SampleRate < 0 || req.SampleRate > 1 {
+		return fmt.Errorf("sample rate %v not in range (0,1]", req.SampleRate)
+	}
+	if req.SampleRate == 0 {
+		req.SampleRate = 1
+	}
+	return nil
+}
+
+func (t *tap) subscribe(sub *tapSubscriber) {
+	t.Lock()
+	defer t.Unlock()
+	t.subscribers[sub] = true
+	t.active.Add(1)
+}
+
+func (t *tap) unsubscribe(sub *tapSubscriber) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.subscribers, sub)
+	t.active.Add(-1)
+}
+
+// publish passes a copy of the metric to the clients tapping the stage of
+// the plugin with the given name and alias. The call does not block and is
+// cheap if no client is attached or the tap is disabled.
+func (t *tap) publish(stage, name, alias string, m telegraf.Metric) {
+	if t == nil || t.active.Load() == 0 {
+		return
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	for sub := range t.subscribers {
+		if sub.Stage != stage || (sub.Plugin != name && sub.Plugin != alias) {
+			continue
+		}
+		if sub.SampleRate < 1 && rand.Float64() >= sub.SampleRate {
+			continue
+		}
+		select {
+		case sub.metrics <- m.Copy():
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// inputFunc returns the function tapping the metrics of the given input or
+// nil if the tap is disabled
+func (t *tap) inputFunc(input *models.RunningInput) func(telegraf.Metric) {
+	if t == nil {
+		return nil
+	}
+	return func(m telegraf.Metric) {
+		t.publish(TapStageInput, input.Config.Name, input.Config.Alias, m)
+	}
+}
+
+func describeTapStage(req TapRequest) string {
+	if req.Plugin == "" {
+		return req.Stage
+	}
+	return req.Stage + ":" + req.Plugin
+}
@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// newTestTap serves a tap for a "cpu" input with alias "system", a "mem"
+// input and a "file" output on a temporary socket
+func newTestTap(t *testing.T) (*tap, string, []*models.RunningInput, []*models.RunningOutput) {
+	t.Helper()
+
+	// Keep the path short as the length of socket paths is limited
+	dir, err := os.MkdirTemp("", "tap")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	address := filepath.Join(dir, "tap.sock")
+
+	inputs := []*models.RunningInput{
+		models.NewRunningInput(&tapInput{}, &models.InputConfig{Name: "cpu", Alias: "system"}),
+		models.NewRunningInput(&tapInput{}, &models.InputConfig{Name: "mem"}),
+	}
+	outputs := []*models.RunningOutput{
+		models.NewRunningOutput(&deliveryOutput{}, &models.OutputConfig{Name: "file"}, 10, 100),
+	}
+
+	tp, err := newTap(address, inputs, outputs)
+	require.NoError(t, err)
+	go tp.serve()
+	t.Cleanup(tp.close)
+
+	info, err := os.Stat(address)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	return tp, address, inputs, outputs
+}
+
+func TestTapStream(t *testing.T) {
+	tp, address, inputs, outputs := newTestTap(t)
+
+	conn, err := net.Dial("unix", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(`{"stage":"input","plugin":"system"}` + "\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return tp.active.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	cpu := metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"usage_idle": 99.5}, time.Unix(10, 0))
+	mem := metric.New("mem", nil, map[string]interface{}{"free": int64(1024)}, time.Unix(10, 0))
+
+	// Only the metrics of the tapped input must be sent
+	tp.inputFunc(inputs[1])(mem)
+	tp.publish(TapStageOutput, outputs[0].Config.Name, outputs[0].Config.Alias, cpu)
+	tp.publish(TapStageProcessors, "", "", cpu)
+	acc := newTapAccumulator(inputs[0], make(chan telegraf.Metric, 1), tp.inputFunc(inputs[0]))
+	acc.AddMetric(cpu)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "cpu,cpu=cpu0 usage_idle=99.5 10000000000\n", line)
+
+	conn.Close()
+	require.Eventually(t, func() bool { return tp.active.Load() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTapInvalidRequest(t *testing.T) {
+	_, address, _, _ := newTestTap(t)
+
+	tests := []struct {
+		name     string
+		request  string
+		expected string
+	}{
+		{
+			name:     "invalid json",
+			request:  `stage=input`,
+			expected: "# error: decoding request failed",
+		},
+		{
+			name:     "unknown stage",
+			request:  `{"stage":"aggregators"}`,
+			expected: `# error: unknown stage "aggregators"`,
+		},
+		{
+			name:     "unknown input",
+			request:  `{"stage":"input","plugin":"disk"}`,
+			expected: `# error: no input with name or alias "disk"`,
+		},
+		{
+			name:     "unknown output",
+			request:  `{"stage":"output","plugin":"cpu"}`,
+			expected: `# error: no output with name or alias "cpu"`,
+		},
+		{
+			name:     "processors with plugin",
+			request:  `{"stage":"processors","plugin":"cpu"}`,
+			expected: "# error: processors stage does not accept a plugin",
+		},
+		{
+			name:     "invalid sample rate",
+			request:  `{"stage":"processors","sample_rate":2}`,
+			expected: "# error: sample rate 2 not in range (0,1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("unix", address)
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte(tt.request + "\n"))
+			require.NoError(t, err)
+
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			require.NoError(t, err)
+			require.Contains(t, line, tt.expected)
+		})
+	}
+}
+
+func TestTapDisabled(t *testing.T) {
+	var tp *tap
+	require.Nil(t, tp.inputFunc(&models.RunningInput{}))
+	tp.publish(TapStageProcessors, "", "", testutil.TestMetric(1.0))
+}
+
+type tapInput struct{}
+
+func (*tapInput) SampleConfig() string {
+	return ""
+}
+
+func (*tapInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
@@ -0,0 +1,131 @@
+// Command handling for the "inspect" command
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+func getInspectCommands(outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "inspect",
+			Usage: "stream the metrics passing a stage of a running agent",
+			Description: `
+The 'inspect' command attaches to the pipeline tap of a running agent and
+prints the metrics passing the given stage in line protocol without
+restarting or reconfiguring the agent. The agent must be started with the
+'tap_socket' setting in the [agent] section of the configuration.
+
+The stage is one of
+  input:<name>   metrics produced by the input with the given name or alias
+  processors     metrics leaving the processors, i.e. before aggregators and outputs
+  output:<name>  metrics passed to the output with the given name or alias,
+                 before applying the output's metric filters
+
+To print about one in ten metrics of the 'cpu' input run
+
+> telegraf inspect --socket /run/telegraf/tap.sock --stage input:cpu --sample-rate 0.1
+
+Metrics are dropped instead of slowing down the agent if the client cannot
+keep up.
+`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "socket",
+					Usage:    "path to the tap socket of the running agent",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "stage",
+					Usage:    "stage to tap, i.e. 'input:<name>', 'processors' or 'output:<name>'",
+					Required: true,
+				},
+				&cli.Float64Flag{
+					Name:  "sample-rate",
+					Usage: "fraction of the metrics to print between 0 and 1",
+					Value: 1,
+				},
+				&cli.IntFlag{
+					Name:  "count",
+					Usage: "exit after printing the given number of metrics, zero for no limit",
+				},
+			},
+			Action: func(cCtx *cli.Context) error {
+				req, err := parseTapStage(cCtx.String("stage"))
+				if err != nil {
+					return err
+				}
+				req.SampleRate = cCtx.Float64("sample-rate")
+				if req.SampleRate <= 0 || req.SampleRate > 1 {
+					return errors.New("sample-rate must be greater than 0 and at most 1")
+				}
+
+				conn, err := net.Dial("unix", cCtx.String("socket"))
+				if err != nil {
+					return fmt.Errorf("connecting to agent failed: %w", err)
+				}
+				defer conn.Close()
+
+				return inspect(conn, req, cCtx.Int("count"), outputBuffer)
+			},
+		},
+	}
+}
+
+// parseTapStage converts a stage given as "input:<name>", "processors" or
+// "output:<name>" into a tap request
+func parseTapStage(stage string) (agent.TapRequest, error) {
+	name, plugin, _ := strings.Cut(stage, ":")
+	switch name {
+	case agent.TapStageInput, agent.TapStageOutput:
+		if plugin == "" {
+			return agent.TapRequest{}, fmt.Errorf("stage %q requires a plugin name, e.g. '%s:<name>'", name, name)
+		}
+	case agent.TapStageProcessors:
+		if plugin != "" {
+			return agent.TapRequest{}, errors.New("stage 'processors' does not accept a plugin name")
+		}
+	default:
+		return agent.TapRequest{}, fmt.Errorf("unknown stage %q", name)
+	}
+	return agent.TapRequest{Stage: name, Plugin: plugin}, nil
+}
+
+// inspect sends the request and copies the received metrics to the writer
+// until the connection is closed or the given number of metrics is reached
+func inspect(conn io.ReadWriter, req agent.TapRequest, count int, w io.Writer) error {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(buf, '\n')); err != nil {
+		return fmt.Errorf("sending request failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var n int
+	for scanner.Scan() {
+		line := scanner.Text()
+		if msg, found := strings.CutPrefix(line, "# error: "); found {
+			return errors.New(msg)
+		}
+		fmt.Fprintln(w, line)
+
+		n++
+		if count > 0 && n >= count {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -263,6 +263,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
+	commands = append(commands, getInspectCommands(outputBuffer)...)
 
 	app := &cli.App{
 		Name:   "Telegraf",
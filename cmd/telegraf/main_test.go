@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -572,3 +573,40 @@ func TestFlagsAreSet(t *testing.T) {
 	require.Equal(t, expectedString, m.watchConfig)
 	require.Equal(t, expectedString, m.pidFile)
 }
+
+func TestParseTapStage(t *testing.T) {
+	req, err := parseTapStage("input:cpu")
+	require.NoError(t, err)
+	require.Equal(t, agent.TapRequest{Stage: "input", Plugin: "cpu"}, req)
+
+	req, err = parseTapStage("processors")
+	require.NoError(t, err)
+	require.Equal(t, agent.TapRequest{Stage: "processors"}, req)
+
+	_, err = parseTapStage("output")
+	require.ErrorContains(t, err, `stage "output" requires a plugin name`)
+	_, err = parseTapStage("processors:rename")
+	require.ErrorContains(t, err, "stage 'processors' does not accept a plugin name")
+	_, err = parseTapStage("aggregators")
+	require.ErrorContains(t, err, `unknown stage "aggregators"`)
+}
+
+func TestInspect(t *testing.T) {
+	var request bytes.Buffer
+	conn := struct {
+		io.Reader
+		io.Writer
+	}{
+		Reader: strings.NewReader("cpu usage=1 1\ncpu usage=2 2\ncpu usage=3 3\n"),
+		Writer: &request,
+	}
+
+	var buf bytes.Buffer
+	req := agent.TapRequest{Stage: "input", Plugin: "cpu", SampleRate: 0.5}
+	require.NoError(t, inspect(conn, req, 2, &buf))
+	require.Equal(t, `{"stage":"input","plugin":"cpu","sample_rate":0.5}`+"\n", request.String())
+	require.Equal(t, "cpu usage=1 1\ncpu usage=2 2\n", buf.String())
+
+	conn.Reader = strings.NewReader(`# error: no input with name or alias "cpu"` + "\n")
+	require.EqualError(t, inspect(conn, req, 0, &buf), `no input with name or alias "cpu"`)
+}
@@ -299,6 +299,11 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk_write_through" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// TapSocket is the path of the local socket to serve the pipeline tap
+	// on. Clients such as "telegraf inspect" can attach to the socket to
+	// stream the metrics passing a stage of the running pipeline.
+	TapSocket string `toml:"tap_socket"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -1099,6 +1104,64 @@ func (c *Config) addParser(parentcategory, parentname string, table *ast.Table)
 	return running, err
 }
 
+// addTableParsers creates a parser for each entry of the sub-table array with
+// the given name. The returned table contains the entries reduced to the
+// options not used by the parsers to be passed to the plugin.
+func (c *Config) addTableParsers(parentcategory, parentname string, table *ast.Table, key string) ([]telegraf.Parser, *ast.Table, error) {
+	node, found := table.Fields[key]
+	if !found {
+		return nil, table, nil
+	}
+
+	var subtables []*ast.Table
+	switch n := node.(type) {
+	case *ast.Table:
+		subtables = []*ast.Table{n}
+	case []*ast.Table:
+		subtables = n
+	default:
+		return nil, nil, fmt.Errorf("%q must be a table", key)
+	}
+
+	// Record the options not used by the parsers
+	tracker := c.toml.MissingField
+	defer func() { c.toml.MissingField = tracker }()
+
+	parsers := make([]telegraf.Parser, 0, len(subtables))
+	remaining := make([]*ast.Table, 0, len(subtables))
+	for i, subtable := range subtables {
+		unused := make(map[string]bool)
+		c.toml.MissingField = func(_ reflect.Type, key string) error {
+			unused[key] = true
+			return nil
+		}
+
+		parser, err := c.addParser(parentcategory, parentname, subtable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("adding parser for %s entry %d failed: %w", key, i+1, err)
+		}
+		parsers = append(parsers, parser)
+
+		reduced := *subtable
+		reduced.Fields = make(map[string]interface{}, len(unused))
+		for k, v := range subtable.Fields {
+			if unused[k] {
+				reduced.Fields[k] = v
+			}
+		}
+		remaining = append(remaining, &reduced)
+	}
+
+	reduced := *table
+	reduced.Fields = make(map[string]interface{}, len(table.Fields))
+	for k, v := range table.Fields {
+		reduced.Fields[k] = v
+	}
+	reduced.Fields[key] = remaining
+
+	return parsers, &reduced, nil
+}
+
 func (c *Config) probeSerializer(table *ast.Table) bool {
 	dataFormat := c.getFieldString(table, "data_format")
 	if dataFormat == "" {
@@ -1212,6 +1275,8 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 	var processor interface{}
 	if p, ok := streamingProcessor.(processors.HasUnwrap); ok {
 		processor = p.Unwrap()
+	} else if p, ok := streamingProcessor.(processors.HasBatchUnwrap); ok {
+		processor = p.UnwrapBatch()
 	} else {
 		processor = streamingProcessor
 	}
@@ -1238,6 +1303,20 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 		optionTestCount++
 	}
 
+	// Processors using multiple parsers configure each of them in an entry of
+	// a sub-table array. The processor only gets the options of the entries
+	// not used by the parsers, so unknown options are reported for the
+	// processor's entries.
+	pluginTable := table
+	if t, ok := processor.(telegraf.ParserTablesPlugin); ok {
+		parsers, reduced, err := c.addTableParsers("processors", name, table, t.ParserTables())
+		if err != nil {
+			return nil, 0, err
+		}
+		t.SetParserTables(parsers)
+		pluginTable = reduced
+	}
+
 	// If the (underlying) processor has a SetSerializer function it can accept
 	// arbitrary data-formats, so build the requested serializer and set it.
 	if t, ok := processor.(telegraf.SerializerPlugin); ok {
@@ -1258,7 +1337,7 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 		optionTestCount++
 	}
 
-	if err := c.toml.UnmarshalTable(table, processor); err != nil {
+	if err := c.toml.UnmarshalTable(pluginTable, processor); err != nil {
 		return nil, 0, fmt.Errorf("unmarshalling failed: %w", err)
 	}
 
@@ -1457,6 +1536,7 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	}
 
 	conf.DropOriginal = c.getFieldBool(tbl, "drop_original")
+	conf.PersistState = c.getFieldBool(tbl, "persist_state")
 	conf.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	conf.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
 	conf.NameOverride = c.getFieldString(tbl, "name_override")
@@ -1499,6 +1579,7 @@ func (c *Config) buildProcessor(category, name, source string, tbl *ast.Table) (
 	conf.Order = c.getFieldInt64(tbl, "order")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.FlushInterval, _ = c.getFieldDuration(tbl, "flush_interval")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1671,6 +1752,7 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.FlushJitter, _ = c.getFieldDuration(tbl, "flush_jitter")
 	oc.MetricBufferLimit = c.getFieldInt(tbl, "metric_buffer_limit")
 	oc.MetricBatchSize = c.getFieldInt(tbl, "metric_batch_size")
+	oc.MetricMaxAge, _ = c.getFieldDuration(tbl, "metric_max_age")
 	oc.Alias = c.getFieldString(tbl, "alias")
 	oc.NameOverride = c.getFieldString(tbl, "name_override")
 	oc.NameSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1702,10 +1784,10 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"metric_batch_size", "metric_buffer_limit", "metric_max_age", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
+		"pass", "period", "persist_state", "precision",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
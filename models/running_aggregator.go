@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
@@ -18,12 +21,23 @@ type RunningAggregator struct {
 	periodEnd   time.Time
 	log         telegraf.Logger
 
+	// restoredExpired is set if the restored state belongs to an aggregation
+	// window that ended before the state was restored
+	restoredExpired bool
+
 	MetricsPushed   selfstat.Stat
 	MetricsFiltered selfstat.Stat
 	MetricsDropped  selfstat.Stat
 	PushTime        selfstat.Stat
 }
 
+// aggregatorState is the persisted state of a stateful aggregator plugin
+// together with the end of the aggregation window the state belongs to
+type aggregatorState struct {
+	PeriodEnd time.Time       `json:"period_end"`
+	State     json.RawMessage `json:"state,omitempty"`
+}
+
 func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConfig) *RunningAggregator {
 	tags := map[string]string{
 		"_id":        config.ID,
@@ -77,6 +91,7 @@ type AggregatorConfig struct {
 	Alias        string
 	ID           string
 	DropOriginal bool
+	PersistState bool
 	Period       time.Duration
 	Delay        time.Duration
 	Grace        time.Duration
@@ -124,6 +139,74 @@ func (r *RunningAggregator) UpdateWindow(start, until time.Time) {
 	r.log.Debugf("Updated aggregation range [%s, %s]", start, until)
 }
 
+// GetState returns the state of the aggregator plugin, if it implements
+// telegraf.StatefulPlugin, including the end of the current aggregation
+// window
+func (r *RunningAggregator) GetState() interface{} {
+	plugin, ok := r.Aggregator.(telegraf.StatefulPlugin)
+	if !ok {
+		return aggregatorState{}
+	}
+
+	state, err := json.Marshal(plugin.GetState())
+	if err != nil {
+		r.log.Errorf("Serializing state failed: %v", err)
+		return aggregatorState{}
+	}
+	return aggregatorState{PeriodEnd: r.periodEnd, State: state}
+}
+
+// SetState restores the state of the aggregator plugin. If the aggregation
+// window of the state ended in the meantime, the restored data is kept for
+// being pushed by PushRestored.
+func (r *RunningAggregator) SetState(state interface{}) error {
+	plugin, ok := r.Aggregator.(telegraf.StatefulPlugin)
+	if !ok {
+		return nil
+	}
+	s, ok := state.(aggregatorState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	if len(s.State) == 0 {
+		return nil
+	}
+
+	// Use the initial state of the plugin as blueprint for unmarshalling
+	pstate := reflect.New(reflect.TypeOf(plugin.GetState()))
+	if err := json.Unmarshal(s.State, pstate.Interface()); err != nil {
+		return fmt.Errorf("unmarshalling plugin state failed: %w", err)
+	}
+	if err := plugin.SetState(pstate.Elem().Interface()); err != nil {
+		return err
+	}
+
+	r.restoredExpired = !time.Now().Before(s.PeriodEnd)
+	if r.restoredExpired {
+		r.log.Debugf("Restored data of aggregation window ended at %s", s.PeriodEnd)
+	}
+	return nil
+}
+
+// PushRestored pushes and resets the data restored by SetState if its
+// aggregation window already ended. This must be called before adding the
+// first metric to not mix the restored data with the current window.
+func (r *RunningAggregator) PushRestored(acc telegraf.Accumulator) {
+	r.Lock()
+	defer r.Unlock()
+
+	if !r.restoredExpired {
+		return
+	}
+	r.restoredExpired = false
+
+	start := time.Now()
+	r.Aggregator.Push(acc)
+	elapsed := time.Since(start)
+	r.PushTime.Incr(elapsed.Nanoseconds())
+	r.Aggregator.Reset()
+}
+
 func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.Metric {
 	m := makeMetric(
 		telegrafMetric,
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -239,6 +241,87 @@ func TestRunningAggregatorAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestRunningAggregatorState(t *testing.T) {
+	a := &mockStatefulAggregator{}
+	ra := NewRunningAggregator(a, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+
+	now := time.Now()
+	ra.UpdateWindow(now, now.Add(ra.Config.Period))
+	ra.Add(testutil.MustMetric("RITest",
+		map[string]string{},
+		map[string]interface{}{"value": int64(42)},
+		now,
+		telegraf.Untyped,
+	))
+
+	// Serialize the state like the persister does
+	buf, err := json.Marshal(ra.GetState())
+	require.NoError(t, err)
+	var st aggregatorState
+	require.NoError(t, json.Unmarshal(buf, &st))
+	require.True(t, st.PeriodEnd.Equal(now.Add(ra.Config.Period)))
+
+	// The data must be restored while the window is still valid
+	restored := &mockStatefulAggregator{}
+	rr := NewRunningAggregator(restored, &AggregatorConfig{Name: "TestRunningAggregator", Period: time.Minute})
+	require.NoError(t, rr.SetState(st))
+	require.Equal(t, int64(42), restored.sum)
+
+	// Restored data of a valid window must not be pushed on startup
+	var acc testutil.Accumulator
+	rr.PushRestored(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Equal(t, int64(42), restored.sum)
+}
+
+func TestRunningAggregatorStateExpiredWindow(t *testing.T) {
+	a := &mockStatefulAggregator{}
+	ra := NewRunningAggregator(a, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+
+	now := time.Now()
+	ra.UpdateWindow(now.Add(-2*ra.Config.Period), now.Add(-ra.Config.Period))
+	ra.Add(testutil.MustMetric("RITest",
+		map[string]string{},
+		map[string]interface{}{"value": int64(42)},
+		now.Add(-90*time.Second),
+		telegraf.Untyped,
+	))
+
+	buf, err := json.Marshal(ra.GetState())
+	require.NoError(t, err)
+	var st aggregatorState
+	require.NoError(t, json.Unmarshal(buf, &st))
+
+	// Restart after the window ended, the restored data must be pushed once
+	restored := &mockStatefulAggregator{}
+	rr := NewRunningAggregator(restored, &AggregatorConfig{Name: "TestRunningAggregator", Period: time.Minute})
+	require.NoError(t, rr.SetState(st))
+
+	var acc testutil.Accumulator
+	rr.PushRestored(&acc)
+	expected := []telegraf.Metric{
+		testutil.MustMetric("TestMetric",
+			map[string]string{},
+			map[string]interface{}{"sum": int64(42)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Zero(t, restored.sum)
+
+	acc.ClearMetrics()
+	rr.PushRestored(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
 type mockAggregator struct {
 	sum int64
 }
@@ -265,3 +348,20 @@ func (t *mockAggregator) Add(in telegraf.Metric) {
 		}
 	}
 }
+
+type mockStatefulAggregator struct {
+	mockAggregator
+}
+
+func (t *mockStatefulAggregator) GetState() interface{} {
+	return t.sum
+}
+
+func (t *mockStatefulAggregator) SetState(state interface{}) error {
+	sum, ok := state.(int64)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	t.sum = sum
+	return nil
+}
@@ -34,6 +34,7 @@ type OutputConfig struct {
 	FlushJitter       time.Duration
 	MetricBufferLimit int
 	MetricBatchSize   int
+	MetricMaxAge      time.Duration
 
 	NameOverride string
 	NamePrefix   string
@@ -57,6 +58,7 @@ type RunningOutput struct {
 	MetricBatchSize   int
 
 	MetricsFiltered selfstat.Stat
+	MetricsExpired  selfstat.Stat
 	WriteTime       selfstat.Stat
 	StartupErrors   selfstat.Stat
 
@@ -133,6 +135,12 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		log: logger,
 	}
 
+	// Only register the expiry statistic if the feature is used to not
+	// change the internal metrics of existing setups
+	if config.MetricMaxAge > 0 {
+		ro.MetricsExpired = selfstat.Register("write", "metrics_expired", tags)
+	}
+
 	return ro
 }
 
@@ -363,8 +371,52 @@ func (r *RunningOutput) doTransaction() error {
 	if len(tx.Batch) == 0 {
 		return nil
 	}
-	err := r.writeMetrics(tx.Batch)
-	r.updateTransaction(tx, err)
+
+	// Without an age limit we can pass the whole batch to the output
+	if r.Config.MetricMaxAge <= 0 {
+		err := r.writeMetrics(tx.Batch)
+		r.updateTransaction(tx, err)
+		r.buffer.EndTransaction(tx)
+		return err
+	}
+
+	// Separate the metrics exceeding the maximum age and only write the
+	// remaining ones. The expired metrics are rejected so they are removed
+	// from the buffer without being delivered.
+	cutoff := time.Now().Add(-r.Config.MetricMaxAge)
+	batch := make([]telegraf.Metric, 0, len(tx.Batch))
+	indices := make([]int, 0, len(tx.Batch))
+	var expired []int
+	for i, m := range tx.Batch {
+		if m.Time().Before(cutoff) {
+			expired = append(expired, i)
+			continue
+		}
+		batch = append(batch, m)
+		indices = append(indices, i)
+	}
+	if len(expired) > 0 {
+		r.MetricsExpired.Incr(int64(len(expired)))
+		r.log.Warnf("Dropped %d metrics older than %s", len(expired), r.Config.MetricMaxAge)
+	}
+
+	var err error
+	subtx := &Transaction{Batch: batch}
+	if len(batch) > 0 {
+		err = r.writeMetrics(batch)
+		r.updateTransaction(subtx, err)
+	}
+
+	// Map the result of the write back to the indices of the transaction
+	tx.Accept = make([]int, 0, len(subtx.Accept))
+	for _, idx := range subtx.Accept {
+		tx.Accept = append(tx.Accept, indices[idx])
+	}
+	tx.Reject = make([]int, 0, len(expired)+len(subtx.Reject))
+	tx.Reject = append(tx.Reject, expired...)
+	for _, idx := range subtx.Reject {
+		tx.Reject = append(tx.Reject, indices[idx])
+	}
 	r.buffer.EndTransaction(tx)
 
 	return err
@@ -423,3 +475,9 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// BufferStats returns the statistics of the output's buffer such as the
+// number of written, rejected and dropped metrics
+func (r *RunningOutput) BufferStats() BufferStats {
+	return r.buffer.Stats()
+}
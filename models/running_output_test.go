@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1069,6 +1070,69 @@ func BenchmarkRunningOutputAddFailWrites(b *testing.B) {
 	}
 }
 
+func TestRunningOutputMetricMaxAge(t *testing.T) {
+	now := time.Now()
+	fresh := []telegraf.Metric{
+		metric.New("fresh", map[string]string{}, map[string]interface{}{"value": 1}, now),
+		metric.New("fresh", map[string]string{}, map[string]interface{}{"value": 2}, now.Add(-time.Minute)),
+	}
+
+	plugin := &mockOutput{}
+	model := NewRunningOutput(plugin, &OutputConfig{Name: "test_max_age", MetricMaxAge: time.Hour}, 10, 100)
+	defer selfstat.Unregister("write", "metrics_expired", map[string]string{"_id": "", "output": "test_max_age"})
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	// The test metrics are far older than the age limit
+	for _, m := range first5 {
+		model.AddMetric(m)
+	}
+	model.AddMetric(fresh[0])
+	model.AddMetric(next5[0])
+	model.AddMetric(fresh[1])
+	require.Equal(t, 8, model.buffer.Len())
+
+	require.NoError(t, model.Write())
+	testutil.RequireMetricsEqual(t, fresh, plugin.Metrics())
+	require.Zero(t, model.buffer.Len())
+	require.Equal(t, int64(6), model.MetricsExpired.Get())
+}
+
+func TestRunningOutputMetricMaxAgePartialWrite(t *testing.T) {
+	now := time.Now()
+	fresh := make([]telegraf.Metric, 0, 4)
+	for i := range 4 {
+		m := metric.New("fresh", map[string]string{}, map[string]interface{}{"value": i}, now)
+		fresh = append(fresh, m)
+	}
+
+	plugin := &mockOutput{batchAcceptSize: 2}
+	model := NewRunningOutput(plugin, &OutputConfig{Name: "test_max_age_partial", MetricMaxAge: time.Hour}, 10, 100)
+	defer selfstat.Unregister("write", "metrics_expired", map[string]string{"_id": "", "output": "test_max_age_partial"})
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	model.AddMetric(first5[0])
+	model.AddMetric(fresh[0])
+	model.AddMetric(first5[1])
+	model.AddMetric(fresh[1])
+	model.AddMetric(fresh[2])
+	model.AddMetric(fresh[3])
+
+	// The expired metrics are removed from the buffer alongside the accepted
+	// ones while the remaining fresh metrics are kept for the next write
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+	testutil.RequireMetricsEqual(t, fresh[:2], plugin.Metrics())
+	require.Equal(t, 2, model.buffer.Len())
+	require.Equal(t, int64(2), model.MetricsExpired.Get())
+
+	require.NoError(t, model.Write())
+	testutil.RequireMetricsEqual(t, fresh, plugin.Metrics())
+	require.Zero(t, model.buffer.Len())
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
@@ -13,6 +14,11 @@ type RunningProcessor struct {
 	log       telegraf.Logger
 	Processor telegraf.StreamingProcessor
 	Config    *ProcessorConfig
+
+	MetricsIn      selfstat.Stat
+	MetricsOut     selfstat.Stat
+	MetricsDropped selfstat.Stat
+	ProcessTime    selfstat.Stat
 }
 
 type RunningProcessors []*RunningProcessor
@@ -30,6 +36,31 @@ type ProcessorConfig struct {
 	Order    int64
 	Filter   Filter
 	LogLevel string
+
+	// FlushInterval is the interval at which batch processors are flushed
+	FlushInterval time.Duration
+}
+
+// flusher is implemented by processors buffering metrics until being flushed
+type flusher interface {
+	Flush()
+}
+
+// unwrapper is implemented by processors wrapping a standard processor and
+// thus emitting all resulting metrics within the call to Add
+type unwrapper interface {
+	Unwrap() telegraf.Processor
+}
+
+// countingAccumulator counts the metrics added by the processor
+type countingAccumulator struct {
+	telegraf.Accumulator
+	count int64
+}
+
+func (a *countingAccumulator) AddMetric(m telegraf.Metric) {
+	a.count++
+	a.Accumulator.AddMetric(m)
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -54,11 +85,32 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 	return &RunningProcessor{
 		Processor: processor,
 		Config:    config,
-		log:       logger,
+		MetricsIn: selfstat.Register(
+			"process",
+			"metrics_in",
+			tags,
+		),
+		MetricsOut: selfstat.Register(
+			"process",
+			"metrics_out",
+			tags,
+		),
+		MetricsDropped: selfstat.Register(
+			"process",
+			"metrics_dropped",
+			tags,
+		),
+		ProcessTime: selfstat.Register(
+			"process",
+			"process_time_ns",
+			tags,
+		),
+		log: logger,
 	}
 }
 
-func (*RunningProcessor) metricFiltered(metric telegraf.Metric) {
+func (rp *RunningProcessor) metricFiltered(metric telegraf.Metric) {
+	rp.MetricsDropped.Incr(1)
 	metric.Drop()
 }
 
@@ -87,7 +139,8 @@ func (rp *RunningProcessor) LogName() string {
 	return logName("processors", rp.Config.Name, rp.Config.Alias)
 }
 
-func (*RunningProcessor) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+func (rp *RunningProcessor) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	rp.MetricsOut.Incr(1)
 	return metric
 }
 
@@ -96,6 +149,8 @@ func (rp *RunningProcessor) Start(acc telegraf.Accumulator) error {
 }
 
 func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	rp.MetricsIn.Incr(1)
+
 	ok, err := rp.Config.Filter.Select(m)
 	if err != nil {
 		rp.log.Errorf("filtering failed: %v", err)
@@ -112,7 +167,40 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
-	return rp.Processor.Add(m, acc)
+	// Standard processors emit their results synchronously so metrics dropped
+	// by the plugin are the difference of the metrics received and emitted.
+	// For all other processors the metrics might be emitted later on.
+	_, synchronous := rp.Processor.(unwrapper)
+	counter := &countingAccumulator{Accumulator: acc}
+	if synchronous {
+		acc = counter
+	}
+
+	start := time.Now()
+	err = rp.Processor.Add(m, acc)
+	rp.ProcessTime.Incr(time.Since(start).Nanoseconds())
+	if err != nil {
+		// the metric is dropped by the agent
+		rp.MetricsDropped.Incr(1)
+	} else if synchronous && counter.count == 0 {
+		rp.MetricsDropped.Incr(1)
+	}
+	return err
+}
+
+// Batching returns true if the processor buffers metrics until being flushed
+func (rp *RunningProcessor) Batching() bool {
+	_, ok := rp.Processor.(flusher)
+	return ok
+}
+
+// Flush hands the buffered metrics to the processor if it is a batch processor
+func (rp *RunningProcessor) Flush() {
+	if p, ok := rp.Processor.(flusher); ok {
+		start := time.Now()
+		p.Flush()
+		rp.ProcessTime.Incr(time.Since(start).Nanoseconds())
+	}
 }
 
 func (rp *RunningProcessor) Stop() {
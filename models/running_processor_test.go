@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -160,10 +161,7 @@ func TestRunningProcessorApply(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			rp := &models.RunningProcessor{
-				Processor: tt.args.Processor,
-				Config:    tt.args.Config,
-			}
+			rp := models.NewRunningProcessor(tt.args.Processor, tt.args.Config)
 			err := rp.Config.Filter.Compile()
 			require.NoError(t, err)
 
@@ -206,6 +204,175 @@ func TestRunningProcessorOrder(t *testing.T) {
 		procs)
 }
 
+func TestRunningProcessorBatch(t *testing.T) {
+	var batches [][]telegraf.Metric
+	mock := &mockBatchProcessor{
+		applyF: func(in []telegraf.Metric) []telegraf.Metric {
+			batches = append(batches, in)
+			if len(in) == 0 {
+				return nil
+			}
+			// Only keep the last metric of the batch
+			for _, m := range in[:len(in)-1] {
+				m.Drop()
+			}
+			return in[len(in)-1:]
+		},
+	}
+	rp := models.NewRunningProcessor(
+		processors.NewStreamingProcessorFromBatchProcessor(mock),
+		&models.ProcessorConfig{Name: "batch", ID: "batch"},
+	)
+	require.NoError(t, rp.Config.Filter.Compile())
+	require.NoError(t, rp.Init())
+	require.True(t, rp.Batching())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(2, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, rp.Start(&acc))
+
+	// Nothing is passed downstream before flushing
+	require.NoError(t, rp.Add(input[0], &acc))
+	require.NoError(t, rp.Add(input[1], &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	rp.Flush()
+	testutil.RequireMetricsEqual(t, input[1:2], acc.GetTelegrafMetrics())
+
+	// An empty flush still signals the processor
+	rp.Flush()
+
+	// Stopping flushes the remaining metrics
+	require.NoError(t, rp.Add(input[2], &acc))
+	rp.Stop()
+	testutil.RequireMetricsEqual(t, input[1:], acc.GetTelegrafMetrics())
+
+	require.Len(t, batches, 3)
+	require.Len(t, batches[0], 2)
+	require.Empty(t, batches[1])
+	require.Len(t, batches[2], 1)
+}
+
+func TestRunningProcessorBatchFinalize(t *testing.T) {
+	var held []telegraf.Metric
+	mock := &mockFinalizingBatchProcessor{
+		mockBatchProcessor: mockBatchProcessor{
+			applyF: func(in []telegraf.Metric) []telegraf.Metric {
+				// Hold back all metrics
+				held = append(held, in...)
+				return nil
+			},
+		},
+		finalizeF: func() []telegraf.Metric {
+			return held
+		},
+	}
+	rp := models.NewRunningProcessor(
+		processors.NewStreamingProcessorFromBatchProcessor(mock),
+		&models.ProcessorConfig{Name: "batch", ID: "batch"},
+	)
+	require.NoError(t, rp.Config.Filter.Compile())
+	require.NoError(t, rp.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, rp.Start(&acc))
+	require.NoError(t, rp.Add(input[0], &acc))
+	rp.Flush()
+	require.NoError(t, rp.Add(input[1], &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Stopping flushes the remaining metrics and releases the held back ones
+	rp.Stop()
+	testutil.RequireMetricsEqual(t, input, acc.GetTelegrafMetrics())
+}
+
+func TestRunningProcessorStatistics(t *testing.T) {
+	mock := &mockProcessor{
+		applyF: func(in ...telegraf.Metric) []telegraf.Metric {
+			out := make([]telegraf.Metric, 0, len(in))
+			for _, m := range in {
+				if m.HasTag("drop") {
+					m.Drop()
+					continue
+				}
+				out = append(out, m)
+			}
+			return out
+		},
+	}
+	rp := models.NewRunningProcessor(
+		processors.NewStreamingProcessorFromProcessor(mock),
+		&models.ProcessorConfig{
+			Name:  "mock",
+			Alias: "stats",
+			ID:    "stats",
+			Filter: models.Filter{
+				NamePass:     []string{"cpu", "mem"},
+				FieldExclude: []string{"ignored"},
+			},
+		},
+	)
+	tags := map[string]string{"_id": "stats", "processor": "mock", "alias": "stats"}
+	for _, field := range []string{"errors", "metrics_in", "metrics_out", "metrics_dropped", "process_time_ns"} {
+		defer selfstat.Unregister("process", field, tags)
+	}
+	require.NoError(t, rp.Config.Filter.Compile())
+	require.NoError(t, rp.Init())
+
+	input := []telegraf.Metric{
+		// processed and passed on
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		// dropped by the processor
+		testutil.MustMetric("cpu", map[string]string{"drop": "true"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		// dropped as no fields are left after filtering
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"ignored": 3.0}, time.Unix(0, 0)),
+		// not selected and passed on
+		testutil.MustMetric("disk", map[string]string{}, map[string]interface{}{"value": 4.0}, time.Unix(0, 0)),
+	}
+
+	acc := &makerAccumulator{maker: rp}
+	require.NoError(t, rp.Start(acc))
+	for _, m := range input {
+		require.NoError(t, rp.Add(m, acc))
+	}
+	rp.Stop()
+
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Equal(t, int64(4), rp.MetricsIn.Get())
+	require.Equal(t, int64(2), rp.MetricsOut.Get())
+	require.Equal(t, int64(2), rp.MetricsDropped.Get())
+	require.Positive(t, rp.ProcessTime.Get())
+}
+
+func TestRunningProcessorNotBatching(t *testing.T) {
+	rp := &models.RunningProcessor{
+		Processor: processors.NewStreamingProcessorFromProcessor(&mockProcessor{}),
+	}
+	require.False(t, rp.Batching())
+}
+
+// makerAccumulator passes the metrics through the metric maker like the agent does
+type makerAccumulator struct {
+	testutil.Accumulator
+	maker interface {
+		MakeMetric(telegraf.Metric) telegraf.Metric
+	}
+}
+
+func (a *makerAccumulator) AddMetric(m telegraf.Metric) {
+	a.Accumulator.AddMetric(a.maker.MakeMetric(m))
+}
+
 // mockProcessor is a processor with an overridable apply implementation.
 type mockProcessor struct {
 	applyF      func(in ...telegraf.Metric) []telegraf.Metric
@@ -224,3 +391,27 @@ func (p *mockProcessor) Init() error {
 func (p *mockProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return p.applyF(in...)
 }
+
+// mockBatchProcessor is a batch processor with an overridable apply implementation.
+type mockBatchProcessor struct {
+	applyF func(in []telegraf.Metric) []telegraf.Metric
+}
+
+func (*mockBatchProcessor) SampleConfig() string {
+	return ""
+}
+
+func (p *mockBatchProcessor) ApplyBatch(in []telegraf.Metric) []telegraf.Metric {
+	return p.applyF(in)
+}
+
+// mockFinalizingBatchProcessor is a batch processor releasing held back
+// metrics when stopping.
+type mockFinalizingBatchProcessor struct {
+	mockBatchProcessor
+	finalizeF func() []telegraf.Metric
+}
+
+func (p *mockFinalizingBatchProcessor) Finalize() []telegraf.Metric {
+	return p.finalizeF()
+}
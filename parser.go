@@ -38,3 +38,14 @@ type ParserFuncPlugin interface {
 	// GetParser returns a new parser.
 	SetParserFunc(fn ParserFunc)
 }
+
+// ParserTablesPlugin is an interface for plugins using multiple parsers, each
+// configured in an entry of a sub-table array. The plugin only receives the
+// options of the entries not used by the parser.
+type ParserTablesPlugin interface {
+	// ParserTables returns the name of the sub-table array
+	ParserTables() string
+
+	// SetParserTables sets the parsers in the order of the sub-table entries
+	SetParserTables(parsers []Parser)
+}
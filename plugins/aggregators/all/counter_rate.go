@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.counter_rate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/counter_rate" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.distinct_count
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/distinct_count" // register plugin
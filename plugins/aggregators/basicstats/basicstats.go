@@ -2,11 +2,19 @@
 package basicstats
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/caio/go-tdigest"
+
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -14,11 +22,14 @@ import (
 var sampleConfig string
 
 type BasicStats struct {
-	Stats []string        `toml:"stats"`
-	Log   telegraf.Logger `toml:"-"`
+	Stats                 []string        `toml:"stats"`
+	Percentiles           []float64       `toml:"percentiles"`
+	PercentileCompression float64         `toml:"percentile_compression"`
+	Log                   telegraf.Logger `toml:"-"`
 
 	cache       map[uint64]aggregate
 	statsConfig *configuredStats
+	suffixes    []string
 }
 
 type configuredStats struct {
@@ -59,6 +70,33 @@ type basicstats struct {
 	M2       float64   // intermediate value for variance/stdev
 	PREVIOUS float64   // intermediate value for diff
 	TIME     time.Time // intermediate value for rate
+	digest   *tdigest.TDigest
+}
+
+// state contains the data of the current aggregation window by series
+type state []seriesState
+
+type seriesState struct {
+	Name   string                `json:"name"`
+	Tags   map[string]string     `json:"tags,omitempty"`
+	Fields map[string]fieldState `json:"fields"`
+}
+
+type fieldState struct {
+	Count    float64       `json:"count"`
+	Min      float64       `json:"min"`
+	Max      float64       `json:"max"`
+	Sum      float64       `json:"sum"`
+	Mean     float64       `json:"mean"`
+	Diff     float64       `json:"diff"`
+	Rate     float64       `json:"rate"`
+	Interval time.Duration `json:"interval"`
+	Last     float64       `json:"last"`
+	First    float64       `json:"first"`
+	M2       float64       `json:"m2"`
+	Previous float64       `json:"previous"`
+	Time     time.Time     `json:"time"`
+	Digest   []byte        `json:"digest,omitempty"`
 }
 
 func (*BasicStats) SampleConfig() string {
@@ -68,6 +106,114 @@ func (*BasicStats) SampleConfig() string {
 func (b *BasicStats) Init() error {
 	b.initConfiguredStats()
 
+	if len(b.Percentiles) == 0 {
+		return nil
+	}
+	if b.PercentileCompression <= 0 {
+		return errors.New("percentile_compression must be positive")
+	}
+	b.suffixes = make([]string, 0, len(b.Percentiles))
+	for i, p := range b.Percentiles {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("percentile %v out of range", p)
+		}
+		for _, prev := range b.Percentiles[:i] {
+			if p == prev {
+				return fmt.Errorf("duplicate percentile %v", p)
+			}
+		}
+		// Use an underscore instead of the decimal point to get valid field
+		// names for fractional percentiles, e.g. "_p99_9" for 99.9
+		suffix := strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "_")
+		b.suffixes = append(b.suffixes, "_p"+suffix)
+	}
+
+	return nil
+}
+
+// GetState returns the data of the current aggregation window
+func (b *BasicStats) GetState() interface{} {
+	st := make(state, 0, len(b.cache))
+	for _, a := range b.cache {
+		series := seriesState{
+			Name:   a.name,
+			Tags:   a.tags,
+			Fields: make(map[string]fieldState, len(a.fields)),
+		}
+		for k, v := range a.fields {
+			f := fieldState{
+				Count:    v.count,
+				Min:      v.min,
+				Max:      v.max,
+				Sum:      v.sum,
+				Mean:     v.mean,
+				Diff:     v.diff,
+				Rate:     v.rate,
+				Interval: v.interval,
+				Last:     v.last,
+				First:    v.first,
+				M2:       v.M2,
+				Previous: v.PREVIOUS,
+				Time:     v.TIME,
+			}
+			if v.digest != nil {
+				digest, err := v.digest.AsBytes()
+				if err != nil {
+					b.Log.Errorf("Serializing percentiles of field %q failed: %v", k, err)
+				}
+				f.Digest = digest
+			}
+			series.Fields[k] = f
+		}
+		st = append(st, series)
+	}
+	return st
+}
+
+func (b *BasicStats) SetState(s interface{}) error {
+	st, ok := s.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", s)
+	}
+
+	for _, series := range st {
+		a := aggregate{
+			name:   series.Name,
+			tags:   series.Tags,
+			fields: make(map[string]basicstats, len(series.Fields)),
+		}
+		if a.tags == nil {
+			a.tags = make(map[string]string)
+		}
+		for k, f := range series.Fields {
+			v := basicstats{
+				count:    f.Count,
+				min:      f.Min,
+				max:      f.Max,
+				sum:      f.Sum,
+				mean:     f.Mean,
+				diff:     f.Diff,
+				rate:     f.Rate,
+				interval: f.Interval,
+				last:     f.Last,
+				first:    f.First,
+				M2:       f.M2,
+				PREVIOUS: f.Previous,
+				TIME:     f.Time,
+			}
+			// Percentiles can only be restored if they are still configured
+			if len(b.Percentiles) > 0 && len(f.Digest) > 0 {
+				digest, err := tdigest.FromBytes(bytes.NewReader(f.Digest))
+				if err != nil {
+					return fmt.Errorf("restoring percentiles of field %q failed: %w", k, err)
+				}
+				v.digest = digest
+			}
+			a.fields[k] = v
+		}
+		id := metric.New(a.name, a.tags, nil, time.Time{}).HashID()
+		b.cache[id] = a
+	}
 	return nil
 }
 
@@ -95,6 +241,7 @@ func (b *BasicStats) Add(in telegraf.Metric) {
 					M2:       0.0,
 					PREVIOUS: fv,
 					TIME:     in.Time(),
+					digest:   b.newDigest(field.Key, fv),
 				}
 			}
 		}
@@ -118,6 +265,7 @@ func (b *BasicStats) Add(in telegraf.Metric) {
 						M2:       0.0,
 						PREVIOUS: fv,
 						TIME:     in.Time(),
+						digest:   b.newDigest(field.Key, fv),
 					}
 					continue
 				}
@@ -156,6 +304,12 @@ func (b *BasicStats) Add(in telegraf.Metric) {
 				}
 				// last compute
 				tmp.last = fv
+				// percentile compute
+				if tmp.digest != nil {
+					if err := tmp.digest.Add(fv); err != nil {
+						b.Log.Errorf("Adding value of field %q to percentiles failed: %v", field.Key, err)
+					}
+				}
 				// store final data
 				b.cache[id].fields[field.Key] = tmp
 			}
@@ -188,6 +342,11 @@ func (b *BasicStats) Push(acc telegraf.Accumulator) {
 			if b.statsConfig.first {
 				fields[k+"_first"] = v.first
 			}
+			if v.digest != nil {
+				for i, p := range b.Percentiles {
+					fields[k+b.suffixes[i]] = v.digest.Quantile(p / 100)
+				}
+			}
 
 			// v.count always >=1
 			if v.count > 1 {
@@ -299,6 +458,23 @@ func (b *BasicStats) initConfiguredStats() {
 	}
 }
 
+// newDigest returns a t-digest containing the initial value if percentiles
+// are configured
+func (b *BasicStats) newDigest(field string, value float64) *tdigest.TDigest {
+	if len(b.Percentiles) == 0 {
+		return nil
+	}
+	digest, err := tdigest.New(tdigest.Compression(b.PercentileCompression))
+	if err != nil {
+		b.Log.Errorf("Creating percentiles for field %q failed: %v", field, err)
+		return nil
+	}
+	if err := digest.Add(value); err != nil {
+		b.Log.Errorf("Adding value of field %q to percentiles failed: %v", field, err)
+	}
+	return digest
+}
+
 func convert(in interface{}) (float64, bool) {
 	switch v := in.(type) {
 	case float64:
@@ -314,7 +490,8 @@ func convert(in interface{}) (float64, bool) {
 
 func newBasicStats() *BasicStats {
 	return &BasicStats{
-		cache: make(map[uint64]aggregate),
+		PercentileCompression: 100,
+		cache:                 make(map[uint64]aggregate),
 	}
 }
 
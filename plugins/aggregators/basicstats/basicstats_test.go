@@ -1,12 +1,14 @@
 package basicstats
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -824,3 +826,137 @@ func TestBasicStatsWithOnlyFirst(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
 }
+
+func TestBasicStatsWithPercentiles(t *testing.T) {
+	aggregator := newBasicStats()
+	aggregator.Stats = []string{"count"}
+	aggregator.Percentiles = []float64{50, 90, 99.9}
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+
+	for i := 1; i <= 1000; i++ {
+		aggregator.Add(metric.New("m1",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"a": int64(i), "b": "ignored"},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	var acc testutil.Accumulator
+	aggregator.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+
+	fields := acc.Metrics[0].Fields
+	require.Len(t, fields, 4)
+	require.InDelta(t, float64(1000), fields["a_count"], 0)
+	require.InDelta(t, 500, fields["a_p50"], 1)
+	require.InDelta(t, 900, fields["a_p90"], 1)
+	require.InDelta(t, 999, fields["a_p99_9"], 1)
+}
+
+func TestBasicStatsWithPercentilesSingleValue(t *testing.T) {
+	aggregator := newBasicStats()
+	aggregator.Stats = make([]string, 0)
+	aggregator.Percentiles = []float64{50}
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+
+	aggregator.Add(m1)
+
+	var acc testutil.Accumulator
+	aggregator.Push(&acc)
+
+	expected := map[string]interface{}{
+		"a_p50": float64(1),
+		"b_p50": float64(1),
+		"c_p50": float64(2),
+		"d_p50": float64(2),
+		"g_p50": float64(3),
+	}
+	acc.AssertContainsTaggedFields(t, "m1", expected, map[string]string{"foo": "bar"})
+}
+
+func TestBasicStatsPercentilesInitFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		percentiles []float64
+		compression float64
+		expected    string
+	}{
+		{
+			name:        "out of range",
+			percentiles: []float64{50, 101},
+			compression: 100,
+			expected:    "percentile 101 out of range",
+		},
+		{
+			name:        "duplicate",
+			percentiles: []float64{90, 90},
+			compression: 100,
+			expected:    "duplicate percentile 90",
+		},
+		{
+			name:        "invalid compression",
+			percentiles: []float64{90},
+			expected:    "percentile_compression must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := newBasicStats()
+			aggregator.Percentiles = tt.percentiles
+			aggregator.PercentileCompression = tt.compression
+			aggregator.Log = testutil.Logger{}
+			require.ErrorContains(t, aggregator.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBasicStatsState(t *testing.T) {
+	newAggregator := func() *BasicStats {
+		aggregator := newBasicStats()
+		aggregator.Stats = []string{"count", "min", "max", "mean", "stdev", "sum", "diff", "rate", "interval", "last", "first"}
+		aggregator.Percentiles = []float64{50}
+		aggregator.Log = testutil.Logger{}
+		require.NoError(t, aggregator.Init())
+		return aggregator
+	}
+
+	metrics := make([]telegraf.Metric, 0, 20)
+	for i := range 20 {
+		metrics = append(metrics, metric.New("m1",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"a": int64(i * i), "b": float64(i) / 2},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	// Reference aggregating all metrics without interruption
+	reference := newAggregator()
+	for _, m := range metrics {
+		reference.Add(m)
+	}
+	var expected testutil.Accumulator
+	reference.Push(&expected)
+
+	// Aggregate the first half, then restore the serialized state in a new
+	// instance and aggregate the second half
+	aggregator := newAggregator()
+	for _, m := range metrics[:10] {
+		aggregator.Add(m)
+	}
+	buf, err := json.Marshal(aggregator.GetState())
+	require.NoError(t, err)
+	var st state
+	require.NoError(t, json.Unmarshal(buf, &st))
+
+	restored := newAggregator()
+	require.NoError(t, restored.SetState(st))
+	for _, m := range metrics[10:] {
+		restored.Add(m)
+	}
+	var actual testutil.Accumulator
+	restored.Push(&actual)
+
+	testutil.RequireMetricsEqual(t, expected.GetTelegrafMetrics(), actual.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,176 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package counter_rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CounterRate struct {
+	Fields    []string        `toml:"fields"`
+	Suffix    string          `toml:"suffix"`
+	Unit      config.Duration `toml:"unit"`
+	CarryOver bool            `toml:"carry_over"`
+	Log       telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*aggregate
+}
+
+type aggregate struct {
+	name     string
+	tags     map[string]string
+	counters map[string]*counter
+}
+
+// counter contains the increase of a counter field between the first and
+// the last sample of the period
+type counter struct {
+	first    time.Time
+	last     time.Time
+	value    float64
+	increase float64
+	updated  bool
+}
+
+func (*CounterRate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CounterRate) Init() error {
+	if c.Unit <= 0 {
+		return errors.New("unit must be positive")
+	}
+
+	f, err := filter.Compile(c.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	c.fieldFilter = f
+	c.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (c *CounterRate) Add(in telegraf.Metric) {
+	id := in.HashID()
+	agg, found := c.cache[id]
+	if !found {
+		agg = &aggregate{
+			name:     in.Name(),
+			tags:     in.Tags(),
+			counters: make(map[string]*counter),
+		}
+	}
+
+	ts := in.Time()
+	for _, field := range in.FieldList() {
+		if c.fieldFilter != nil && !c.fieldFilter.Match(field.Key) {
+			continue
+		}
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		cnt, ok := agg.counters[field.Key]
+		if !ok {
+			agg.counters[field.Key] = &counter{first: ts, last: ts, value: v, updated: true}
+			continue
+		}
+		if !ts.After(cnt.last) {
+			c.Log.Debugf("Ignoring sample of %q in %q not newer than the last one", field.Key, in.Name())
+			continue
+		}
+
+		// A decreasing value indicates a counter reset so the counter
+		// started at zero again
+		delta := v - cnt.value
+		if delta < 0 {
+			c.Log.Debugf("Detected reset of %q in %q", field.Key, in.Name())
+			delta = v
+		}
+		cnt.increase += delta
+		cnt.value = v
+		cnt.last = ts
+		cnt.updated = true
+	}
+
+	if !found && len(agg.counters) > 0 {
+		c.cache[id] = agg
+	}
+}
+
+func (c *CounterRate) Push(acc telegraf.Accumulator) {
+	unit := float64(c.Unit)
+	for _, agg := range c.cache {
+		fields := make(map[string]interface{}, len(agg.counters))
+		for key, cnt := range agg.counters {
+			// At least two samples are required to compute a rate
+			elapsed := cnt.last.Sub(cnt.first)
+			if elapsed <= 0 {
+				continue
+			}
+			fields[key+c.Suffix] = cnt.increase * unit / float64(elapsed)
+		}
+		if len(fields) > 0 {
+			acc.AddFields(agg.name, fields, agg.tags)
+		}
+	}
+}
+
+func (c *CounterRate) Reset() {
+	if !c.CarryOver {
+		c.cache = make(map[uint64]*aggregate)
+		return
+	}
+
+	// Continue with the last sample and remove the counters without samples
+	// in the past period
+	for id, agg := range c.cache {
+		for key, cnt := range agg.counters {
+			if !cnt.updated {
+				delete(agg.counters, key)
+				continue
+			}
+			cnt.first = cnt.last
+			cnt.increase = 0
+			cnt.updated = false
+		}
+		if len(agg.counters) == 0 {
+			delete(c.cache, id)
+		}
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	aggregators.Add("counter_rate", func() telegraf.Aggregator {
+		return &CounterRate{
+			Suffix:    "_rate",
+			Unit:      config.Duration(time.Second),
+			CarryOver: true,
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package counter_rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin(t *testing.T) *CounterRate {
+	t.Helper()
+
+	plugin := aggregators.Aggregators["counter_rate"]().(*CounterRate)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &CounterRate{Unit: config.Duration(0)}
+	require.ErrorContains(t, plugin.Init(), "unit must be positive")
+}
+
+func TestRate(t *testing.T) {
+	plugin := newPlugin(t)
+	plugin.Unit = config.Duration(time.Minute)
+
+	samples := []struct {
+		offset time.Duration
+		fields map[string]interface{}
+	}{
+		{offset: 0, fields: map[string]interface{}{"requests": int64(100), "bytes": uint64(1000), "state": "ok"}},
+		{offset: 10 * time.Second, fields: map[string]interface{}{"requests": int64(160), "bytes": uint64(1500)}},
+		{offset: 20 * time.Second, fields: map[string]interface{}{"requests": int64(220)}},
+		{offset: 30 * time.Second, fields: map[string]interface{}{"requests": int64(280), "bytes": uint64(3000)}},
+	}
+	for _, s := range samples {
+		plugin.Add(metric.New("http", map[string]string{"host": "a"}, s.fields, time.Unix(0, 0).Add(s.offset)))
+	}
+	// Series with only one sample must not produce a rate
+	plugin.Add(metric.New("http", map[string]string{"host": "b"}, map[string]interface{}{"requests": int64(5)}, time.Unix(0, 0)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"requests_rate": float64(360),
+				"bytes_rate":    float64(4000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRateWithReset(t *testing.T) {
+	plugin := newPlugin(t)
+
+	// The counter restarts between the second and third sample
+	for i, v := range []float64{50, 80, 10, 40} {
+		plugin.Add(metric.New("net", nil, map[string]interface{}{"packets": v}, time.Unix(int64(10*i), 0)))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{}, map[string]interface{}{"packets_rate": float64(70) / 30}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestFieldFilter(t *testing.T) {
+	plugin := &CounterRate{
+		Fields: []string{"rx_*"},
+		Suffix: "_per_second",
+		Unit:   config.Duration(time.Second),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("net", nil, map[string]interface{}{"rx_bytes": 10, "tx_bytes": 10}, time.Unix(0, 0)))
+	plugin.Add(metric.New("net", nil, map[string]interface{}{"rx_bytes": 30, "tx_bytes": 30}, time.Unix(10, 0)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{}, map[string]interface{}{"rx_bytes_per_second": float64(2)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestCarryOver(t *testing.T) {
+	for _, carryOver := range []bool{true, false} {
+		t.Run("carry over "+map[bool]string{true: "on", false: "off"}[carryOver], func(t *testing.T) {
+			plugin := newPlugin(t)
+			plugin.CarryOver = carryOver
+
+			plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 100}, time.Unix(0, 0)))
+			plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 200}, time.Unix(10, 0)))
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			plugin.Reset()
+			require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+			// The second period only contains a single sample
+			plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 500}, time.Unix(20, 0)))
+			acc.ClearMetrics()
+			plugin.Push(&acc)
+			plugin.Reset()
+
+			if !carryOver {
+				require.Empty(t, acc.GetTelegrafMetrics())
+				return
+			}
+			expected := []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"ticks_rate": float64(30)}, time.Unix(0, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+			// Series without samples in a period are removed
+			acc.ClearMetrics()
+			plugin.Push(&acc)
+			plugin.Reset()
+			require.Empty(t, acc.GetTelegrafMetrics())
+			require.Empty(t, plugin.cache)
+		})
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	plugin := newPlugin(t)
+
+	plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 100}, time.Unix(10, 0)))
+	plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 50}, time.Unix(5, 0)))
+	plugin.Add(metric.New("cpu", nil, map[string]interface{}{"ticks": 150}, time.Unix(20, 0)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"ticks_rate": float64(5)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
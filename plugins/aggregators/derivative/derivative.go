@@ -3,10 +3,12 @@ package derivative
 
 import (
 	_ "embed"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -36,6 +38,22 @@ type event struct {
 	time   time.Time
 }
 
+// state contains the first and last event of each series
+type state []seriesState
+
+type seriesState struct {
+	Name     string            `json:"name"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	First    eventState        `json:"first"`
+	Last     eventState        `json:"last"`
+	RollOver uint              `json:"roll_over"`
+}
+
+type eventState struct {
+	Fields map[string]float64 `json:"fields"`
+	Time   time.Time          `json:"time"`
+}
+
 func (d *Derivative) Init() error {
 	d.Suffix = strings.TrimSpace(d.Suffix)
 	d.Variable = strings.TrimSpace(d.Variable)
@@ -46,6 +64,47 @@ func (*Derivative) SampleConfig() string {
 	return sampleConfig
 }
 
+// GetState returns the first and last event of each series
+func (d *Derivative) GetState() interface{} {
+	st := make(state, 0, len(d.cache))
+	for _, aggregate := range d.cache {
+		st = append(st, seriesState{
+			Name:     aggregate.name,
+			Tags:     aggregate.tags,
+			First:    eventState{Fields: aggregate.first.fields, Time: aggregate.first.time},
+			Last:     eventState{Fields: aggregate.last.fields, Time: aggregate.last.time},
+			RollOver: aggregate.rollOver,
+		})
+	}
+	return st
+}
+
+func (d *Derivative) SetState(s interface{}) error {
+	st, ok := s.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", s)
+	}
+
+	for _, series := range st {
+		a := &aggregate{
+			name:     series.Name,
+			tags:     series.Tags,
+			first:    &event{fields: series.First.Fields, time: series.First.Time},
+			rollOver: series.RollOver,
+		}
+		if a.tags == nil {
+			a.tags = make(map[string]string)
+		}
+		// A series with a single event uses the same event as first and last
+		a.last = a.first
+		if !series.Last.Time.Equal(series.First.Time) {
+			a.last = &event{fields: series.Last.Fields, time: series.Last.Time}
+		}
+		d.cache[metric.New(a.name, a.tags, nil, time.Time{}).HashID()] = a
+	}
+	return nil
+}
+
 func (d *Derivative) Add(in telegraf.Metric) {
 	id := in.HashID()
 	current, ok := d.cache[id]
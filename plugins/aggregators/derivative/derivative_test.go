@@ -1,6 +1,7 @@
 package derivative
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -416,3 +417,30 @@ func TestCalculatesCorrectDerivativeOnTwoConsecutivePeriods(t *testing.T) {
 		"value_rate": 2.0,
 	})
 }
+
+func TestState(t *testing.T) {
+	derivative := newDerivative()
+	derivative.Log = testutil.Logger{}
+	require.NoError(t, derivative.Init())
+	derivative.Add(metric.New("test", map[string]string{"state": "full"}, map[string]interface{}{"value": int64(10)}, time.Unix(0, 0)))
+
+	// Restore the serialized state in a new instance and add the last event
+	buf, err := json.Marshal(derivative.GetState())
+	require.NoError(t, err)
+	var st state
+	require.NoError(t, json.Unmarshal(buf, &st))
+
+	restored := newDerivative()
+	restored.Log = testutil.Logger{}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(st))
+	for _, a := range restored.cache {
+		require.Same(t, a.first, a.last)
+	}
+	restored.Add(metric.New("test", map[string]string{"state": "full"}, map[string]interface{}{"value": int64(40)}, time.Unix(10, 0)))
+
+	acc := testutil.Accumulator{}
+	restored.Push(&acc)
+
+	acc.AssertContainsTaggedFields(t, "test", map[string]interface{}{"value_rate": 3.0}, map[string]string{"state": "full"})
+}
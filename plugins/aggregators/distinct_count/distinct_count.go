@@ -0,0 +1,166 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package distinct_count
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	minPrecision = 4
+	maxPrecision = 16
+)
+
+type DistinctCount struct {
+	Tags       []string        `toml:"tags"`
+	Fields     []string        `toml:"fields"`
+	Precision  uint8           `toml:"precision"`
+	EmitSketch bool            `toml:"emit_sketch"`
+	Log        telegraf.Logger `toml:"-"`
+
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+	cache       map[string]*aggregate
+}
+
+// aggregate contains the sketches of a series, i.e. the metric name and the
+// tags not being counted
+type aggregate struct {
+	name     string
+	tags     map[string]string
+	sketches map[string]*sketch
+}
+
+func (*DistinctCount) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DistinctCount) Init() error {
+	if len(d.Tags) == 0 && len(d.Fields) == 0 {
+		return errors.New("at least one of tags or fields must be set")
+	}
+	if d.Precision < minPrecision || d.Precision > maxPrecision {
+		return fmt.Errorf("precision must be between %d and %d", minPrecision, maxPrecision)
+	}
+
+	var err error
+	if d.tagFilter, err = filter.Compile(d.Tags); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if d.fieldFilter, err = filter.Compile(d.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	d.cache = make(map[string]*aggregate)
+
+	return nil
+}
+
+func (d *DistinctCount) Add(in telegraf.Metric) {
+	// Collect the values to count and identify the series by the remaining
+	// tags as the counted tags would otherwise split the series
+	values := make(map[string]string)
+	tags := make(map[string]string)
+	var id strings.Builder
+	id.WriteString(in.Name())
+	for _, tag := range in.TagList() {
+		if d.tagFilter != nil && d.tagFilter.Match(tag.Key) {
+			values[tag.Key] = tag.Value
+			continue
+		}
+		tags[tag.Key] = tag.Value
+		id.WriteString("\x00" + tag.Key + "\x00" + tag.Value)
+	}
+	if d.fieldFilter != nil {
+		for _, field := range in.FieldList() {
+			if !d.fieldFilter.Match(field.Key) {
+				continue
+			}
+			v, ok := convert(field.Value)
+			if !ok {
+				d.Log.Debugf("Ignoring field %q of %q with unsupported type %T", field.Key, in.Name(), field.Value)
+				continue
+			}
+			values[field.Key] = v
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	agg, found := d.cache[id.String()]
+	if !found {
+		agg = &aggregate{
+			name:     in.Name(),
+			tags:     tags,
+			sketches: make(map[string]*sketch, len(values)),
+		}
+		d.cache[id.String()] = agg
+	}
+	for key, value := range values {
+		s, found := agg.sketches[key]
+		if !found {
+			s = newSketch(d.Precision)
+			agg.sketches[key] = s
+		}
+		s.insert(value)
+	}
+}
+
+func (d *DistinctCount) Push(acc telegraf.Accumulator) {
+	for _, agg := range d.cache {
+		fields := make(map[string]interface{}, len(agg.sketches))
+		for key, s := range agg.sketches {
+			fields[key+"_distinct"] = s.estimate()
+			if d.EmitSketch {
+				data, err := s.MarshalBinary()
+				if err != nil {
+					d.Log.Errorf("Encoding sketch of %q in %q failed: %v", key, agg.name, err)
+					continue
+				}
+				fields[key+"_sketch"] = base64.StdEncoding.EncodeToString(data)
+			}
+		}
+		acc.AddFields(agg.name, fields, agg.tags)
+	}
+}
+
+func (d *DistinctCount) Reset() {
+	d.cache = make(map[string]*aggregate)
+}
+
+// convert returns the string representation of the field value used for
+// counting, so e.g. the integer 42 and the string "42" are the same value
+func convert(in interface{}) (string, bool) {
+	switch v := in.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func init() {
+	aggregators.Add("distinct_count", func() telegraf.Aggregator {
+		return &DistinctCount{
+			Precision: 14,
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package distinct_count
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DistinctCount
+		expected string
+	}{
+		{
+			name:     "nothing to count",
+			plugin:   &DistinctCount{Precision: 14},
+			expected: "at least one of tags or fields must be set",
+		},
+		{
+			name:     "precision too low",
+			plugin:   &DistinctCount{Tags: []string{"client_ip"}, Precision: 3},
+			expected: "precision must be between 4 and 16",
+		},
+		{
+			name:     "precision too high",
+			plugin:   &DistinctCount{Tags: []string{"client_ip"}, Precision: 17},
+			expected: "precision must be between 4 and 16",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	plugin := &DistinctCount{
+		Tags:      []string{"client_ip"},
+		Fields:    []string{"user", "status"},
+		Precision: 14,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("access", map[string]string{"host": "a", "client_ip": "10.0.0.1"}, map[string]interface{}{"user": "alice", "status": int64(200)}, time.Unix(0, 0)),
+		metric.New("access", map[string]string{"host": "a", "client_ip": "10.0.0.2"}, map[string]interface{}{"user": "bob", "status": int64(200)}, time.Unix(1, 0)),
+		metric.New("access", map[string]string{"host": "a", "client_ip": "10.0.0.1"}, map[string]interface{}{"user": "alice", "status": int64(404)}, time.Unix(2, 0)),
+		metric.New("access", map[string]string{"host": "a", "client_ip": "10.0.0.3"}, map[string]interface{}{"user": "alice"}, time.Unix(3, 0)),
+		metric.New("access", map[string]string{"host": "b", "client_ip": "10.0.0.1"}, map[string]interface{}{"bytes": int64(5)}, time.Unix(4, 0)),
+		metric.New("access", map[string]string{"host": "c"}, map[string]interface{}{"bytes": int64(5)}, time.Unix(5, 0)),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"access",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"client_ip_distinct": uint64(3),
+				"user_distinct":      uint64(2),
+				"status_distinct":    uint64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"access",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"client_ip_distinct": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The counts must start over after the reset
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Add(input[0])
+	plugin.Push(&acc)
+	expected = []telegraf.Metric{
+		metric.New(
+			"access",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"client_ip_distinct": uint64(1),
+				"user_distinct":      uint64(1),
+				"status_distinct":    uint64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestEmitSketch(t *testing.T) {
+	plugin := &DistinctCount{
+		Tags:       []string{"client_ip"},
+		Precision:  10,
+		EmitSketch: true,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for i := range 100 {
+		plugin.Add(metric.New("access", map[string]string{"client_ip": "10.0.0." + strconv.Itoa(i)}, map[string]interface{}{"bytes": 1}, time.Unix(0, 0)))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+
+	count, found := metrics[0].GetField("client_ip_distinct")
+	require.True(t, found)
+	encoded, found := metrics[0].GetField("client_ip_sketch")
+	require.True(t, found)
+
+	// The emitted sketch must decode to the same estimate
+	data, err := base64.StdEncoding.DecodeString(encoded.(string))
+	require.NoError(t, err)
+	var s sketch
+	require.NoError(t, s.UnmarshalBinary(data))
+	require.Equal(t, uint8(10), s.precision)
+	require.Equal(t, count, s.estimate())
+}
@@ -0,0 +1,127 @@
+package distinct_count
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const sketchVersion = 1
+
+// sketch is a HyperLogLog sketch estimating the number of distinct values
+// with a relative standard error of about 1.04/sqrt(2^precision)
+type sketch struct {
+	precision uint8
+	registers []uint8
+}
+
+func newSketch(precision uint8) *sketch {
+	return &sketch{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+func (s *sketch) insert(value string) {
+	h := hash(value)
+
+	// The first bits select the register, the position of the first set bit
+	// of the remaining ones is the observed rank. The guard bit limits the
+	// rank if all remaining bits are zero.
+	idx := h >> (64 - s.precision)
+	w := h<<s.precision | 1<<(s.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// merge adds the values of the other sketch, both sketches must have the
+// same precision
+func (s *sketch) merge(other *sketch) error {
+	if s.precision != other.precision {
+		return fmt.Errorf("precision %d does not match %d", other.precision, s.precision)
+	}
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+	return nil
+}
+
+func (s *sketch) estimate() uint64 {
+	m := float64(len(s.registers))
+
+	var sum float64
+	var zeros int
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := alpha(len(s.registers)) * m * m / sum
+
+	// Use linear counting for small cardinalities where the raw estimate
+	// is biased
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(e))
+}
+
+// MarshalBinary encodes the sketch as version, precision and registers
+func (s *sketch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 2+len(s.registers))
+	buf = append(buf, sketchVersion, s.precision)
+	return append(buf, s.registers...), nil
+}
+
+func (s *sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("sketch too short")
+	}
+	if data[0] != sketchVersion {
+		return fmt.Errorf("unsupported sketch version %d", data[0])
+	}
+	precision := data[1]
+	if precision < minPrecision || precision > maxPrecision {
+		return fmt.Errorf("invalid precision %d", precision)
+	}
+	if len(data)-2 != 1<<precision {
+		return fmt.Errorf("expected %d registers but got %d", 1<<precision, len(data)-2)
+	}
+	s.precision = precision
+	s.registers = append([]uint8(nil), data[2:]...)
+	return nil
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// hash returns a stable 64-bit hash of the value. The FNV hash is finalized
+// with the MurmurHash3 mixer to spread the bits evenly as required for the
+// register selection.
+func hash(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,53 @@
+package distinct_count
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketchEstimate(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			s := newSketch(14)
+			for i := range n {
+				s.insert("10.0.0." + strconv.Itoa(i))
+				// Duplicates must not change the estimate
+				s.insert("10.0.0." + strconv.Itoa(i))
+			}
+			require.InEpsilon(t, float64(n)+1, float64(s.estimate())+1, 0.03)
+		})
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	a := newSketch(12)
+	b := newSketch(12)
+	for i := range 5000 {
+		a.insert(strconv.Itoa(i))
+		b.insert(strconv.Itoa(i + 2500))
+	}
+	require.NoError(t, a.merge(b))
+	require.InEpsilon(t, 7500, float64(a.estimate()), 0.05)
+
+	require.ErrorContains(t, a.merge(newSketch(10)), "precision 10 does not match 12")
+}
+
+func TestSketchMarshal(t *testing.T) {
+	s := newSketch(4)
+	for i := range 100 {
+		s.insert(strconv.Itoa(i))
+	}
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, 18)
+
+	var actual sketch
+	require.NoError(t, actual.UnmarshalBinary(data))
+	require.Equal(t, s, &actual)
+
+	require.ErrorContains(t, actual.UnmarshalBinary([]byte{2, 4}), "unsupported sketch version 2")
+	require.ErrorContains(t, actual.UnmarshalBinary([]byte{1, 20}), "invalid precision 20")
+	require.ErrorContains(t, actual.UnmarshalBinary(data[:10]), "expected 16 registers but got 8")
+}
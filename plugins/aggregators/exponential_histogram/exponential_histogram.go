@@ -0,0 +1,171 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package exponential_histogram
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	// bucketRightTag is the tag containing the right bucket border (inclusive)
+	bucketRightTag = "le"
+	// bucketLeftTag is the tag containing the left bucket border (exclusive)
+	bucketLeftTag = "gt"
+)
+
+type ExponentialHistogram struct {
+	Fields        []string `toml:"fields"`
+	MaxSize       int      `toml:"max_size"`
+	MaxScale      int32    `toml:"max_scale"`
+	ZeroThreshold float64  `toml:"zero_threshold"`
+	ResetBuckets  bool     `toml:"reset"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*aggregate
+}
+
+type aggregate struct {
+	name       string
+	tags       map[string]string
+	histograms map[string]*histogram
+}
+
+func (*ExponentialHistogram) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *ExponentialHistogram) Init() error {
+	if e.MaxSize < 2 {
+		return errors.New("max_size must be at least 2")
+	}
+	if e.MaxScale < minScale || e.MaxScale > maxScale {
+		return fmt.Errorf("max_scale must be in the range of [%d, %d]", minScale, maxScale)
+	}
+	if e.ZeroThreshold < 0 {
+		return errors.New("zero_threshold must not be negative")
+	}
+
+	f, err := filter.Compile(e.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	e.fieldFilter = f
+	e.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (e *ExponentialHistogram) Add(in telegraf.Metric) {
+	id := in.HashID()
+	agg, found := e.cache[id]
+	for _, field := range in.FieldList() {
+		if e.fieldFilter != nil && !e.fieldFilter.Match(field.Key) {
+			continue
+		}
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		if !found {
+			agg = &aggregate{
+				name:       in.Name(),
+				tags:       in.Tags(),
+				histograms: make(map[string]*histogram),
+			}
+			e.cache[id] = agg
+			found = true
+		}
+		h, ok := agg.histograms[field.Key]
+		if !ok {
+			h = newHistogram(e.MaxScale, e.MaxSize, e.ZeroThreshold)
+			agg.histograms[field.Key] = h
+		}
+		h.add(v)
+	}
+}
+
+func (e *ExponentialHistogram) Push(acc telegraf.Accumulator) {
+	for _, agg := range e.cache {
+		fields := make(map[string]interface{}, 7*len(agg.histograms))
+		for field, h := range agg.histograms {
+			fields[field+"_count"] = int64(h.count)
+			fields[field+"_sum"] = h.sum
+			fields[field+"_scale"] = int64(h.scale)
+			fields[field+"_zero_count"] = int64(h.zeroCount)
+			fields[field+"_zero_threshold"] = h.zeroThreshold
+			if h.count > 0 {
+				fields[field+"_min"] = h.min
+				fields[field+"_max"] = h.max
+			}
+
+			// Negative buckets cover the range [-base^(i+1), -base^i)
+			for i := len(h.negative.counts) - 1; i >= 0; i-- {
+				index := h.negative.offset + int32(i)
+				left := -lowerBoundary(index+1, h.scale)
+				right := -lowerBoundary(index, h.scale)
+				e.pushBucket(acc, agg, field, left, right, h.negative.counts[i])
+			}
+			for i, count := range h.positive.counts {
+				index := h.positive.offset + int32(i)
+				left := lowerBoundary(index, h.scale)
+				right := lowerBoundary(index+1, h.scale)
+				e.pushBucket(acc, agg, field, left, right, count)
+			}
+		}
+		acc.AddFields(agg.name, fields, agg.tags)
+	}
+}
+
+func (*ExponentialHistogram) pushBucket(acc telegraf.Accumulator, agg *aggregate, field string, left, right float64, count uint64) {
+	if count == 0 {
+		return
+	}
+	tags := make(map[string]string, len(agg.tags)+2)
+	for k, v := range agg.tags {
+		tags[k] = v
+	}
+	tags[bucketLeftTag] = strconv.FormatFloat(left, 'g', -1, 64)
+	tags[bucketRightTag] = strconv.FormatFloat(right, 'g', -1, 64)
+	acc.AddFields(agg.name, map[string]interface{}{field + "_bucket": int64(count)}, tags)
+}
+
+// Reset does nothing by default to accumulate the values across periods,
+// only if the 'reset' option is set the histograms are cleared.
+func (e *ExponentialHistogram) Reset() {
+	if e.ResetBuckets {
+		e.cache = make(map[uint64]*aggregate)
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("exponential_histogram", func() telegraf.Aggregator {
+		return &ExponentialHistogram{
+			MaxSize:  160,
+			MaxScale: 20,
+		}
+	})
+}
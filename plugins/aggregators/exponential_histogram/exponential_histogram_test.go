@@ -0,0 +1,193 @@
+package exponential_histogram
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestMapToIndex(t *testing.T) {
+	tests := []struct {
+		value    float64
+		scale    int32
+		expected int32
+	}{
+		{value: 1, scale: 0, expected: -1},
+		{value: 0.5, scale: 0, expected: -2},
+		{value: 2, scale: 0, expected: 0},
+		{value: 3, scale: 0, expected: 1},
+		{value: 4, scale: 0, expected: 1},
+		{value: 1, scale: 1, expected: -1},
+		{value: 1.5, scale: 1, expected: 1},
+		{value: 2, scale: 1, expected: 1},
+		{value: 0.5, scale: -1, expected: -1},
+		{value: 1, scale: -1, expected: -1},
+		{value: 2, scale: -1, expected: 0},
+		{value: 4, scale: -1, expected: 0},
+		{value: 5, scale: -1, expected: 1},
+		{value: math.MaxFloat64, scale: minScale, expected: 0},
+		{value: math.SmallestNonzeroFloat64, scale: minScale, expected: -2},
+	}
+	for _, tt := range tests {
+		index := mapToIndex(tt.value, tt.scale)
+		require.Equal(t, tt.expected, index, "value %v at scale %d", tt.value, tt.scale)
+		if tt.scale > minScale {
+			require.Greater(t, tt.value, lowerBoundary(index, tt.scale))
+			require.LessOrEqual(t, tt.value, lowerBoundary(index+1, tt.scale))
+		}
+	}
+}
+
+func TestHistogramDownscale(t *testing.T) {
+	h := newHistogram(0, 4, 0)
+	for _, v := range []float64{1, 2, 4, 8} {
+		h.add(v)
+	}
+	require.Equal(t, int32(0), h.scale)
+	require.Equal(t, buckets{offset: -1, counts: []uint64{1, 1, 1, 1}}, h.positive)
+
+	// Exceeding the maximum size must merge neighboring buckets
+	h.add(16)
+	require.Equal(t, int32(-1), h.scale)
+	require.Equal(t, buckets{offset: -1, counts: []uint64{1, 2, 2}}, h.positive)
+
+	// Negative values share the scale with the positive ones
+	h.add(-0.1)
+	require.Equal(t, buckets{offset: -2, counts: []uint64{1}}, h.negative)
+	require.Equal(t, uint64(6), h.count)
+	require.InDelta(t, 30.9, h.sum, 1e-9)
+	require.InDelta(t, -0.1, h.min, 0)
+	require.InDelta(t, 16.0, h.max, 0)
+}
+
+func TestHistogramRange(t *testing.T) {
+	h := newHistogram(maxScale, 160, 0)
+	for v := 1e-300; v < 1e300; v *= 10 {
+		h.add(v)
+	}
+	require.LessOrEqual(t, len(h.positive.counts), 160)
+	require.Less(t, h.scale, int32(maxScale))
+
+	var total uint64
+	for _, c := range h.positive.counts {
+		total += c
+	}
+	require.Equal(t, h.count, total)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *ExponentialHistogram
+		expected string
+	}{
+		{
+			name:     "max size too small",
+			plugin:   &ExponentialHistogram{MaxSize: 1},
+			expected: "max_size must be at least 2",
+		},
+		{
+			name:     "max scale out of range",
+			plugin:   &ExponentialHistogram{MaxSize: 160, MaxScale: 21},
+			expected: "max_scale must be in the range of [-10, 20]",
+		},
+		{
+			name:     "negative zero threshold",
+			plugin:   &ExponentialHistogram{MaxSize: 160, ZeroThreshold: -1},
+			expected: "zero_threshold must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPush(t *testing.T) {
+	plugin := &ExponentialHistogram{
+		Fields:        []string{"value"},
+		MaxSize:       160,
+		MaxScale:      0,
+		ZeroThreshold: 0.5,
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, v := range []interface{}{int64(0), 0.5, -3.0, uint64(3), 10.0, "ignored"} {
+		plugin.Add(metric.New(
+			"latency",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": v, "other": 1.0},
+			time.Unix(0, 0),
+		))
+	}
+	plugin.Add(metric.New("latency", map[string]string{"host": "b"}, map[string]interface{}{"other": 1.0}, time.Unix(0, 0)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"latency",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"value_count":          int64(5),
+				"value_sum":            10.5,
+				"value_min":            -3.0,
+				"value_max":            10.0,
+				"value_scale":          int64(0),
+				"value_zero_count":     int64(2),
+				"value_zero_threshold": 0.5,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"latency",
+			map[string]string{"host": "a", "gt": "-4", "le": "-2"},
+			map[string]interface{}{"value_bucket": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"latency",
+			map[string]string{"host": "a", "gt": "2", "le": "4"},
+			map[string]interface{}{"value_bucket": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"latency",
+			map[string]string{"host": "a", "gt": "8", "le": "16"},
+			map[string]interface{}{"value_bucket": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestReset(t *testing.T) {
+	for _, reset := range []bool{false, true} {
+		plugin := &ExponentialHistogram{MaxSize: 160, MaxScale: 20, ResetBuckets: reset}
+		require.NoError(t, plugin.Init())
+
+		m := metric.New("cpu", nil, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0))
+		plugin.Add(m)
+		plugin.Reset()
+		plugin.Add(m)
+
+		var acc testutil.Accumulator
+		plugin.Push(&acc)
+
+		expected := int64(2)
+		if reset {
+			expected = 1
+		}
+		require.True(t, acc.HasInt64Field("cpu", "usage_count"))
+		v, _ := acc.Int64Field("cpu", "usage_count")
+		require.Equal(t, expected, v)
+	}
+}
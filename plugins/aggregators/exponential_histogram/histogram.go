@@ -0,0 +1,160 @@
+package exponential_histogram
+
+import (
+	"math"
+)
+
+const (
+	// minScale is the lowest scale, at this scale a single bucket covers a
+	// factor of 2^1024 so all finite values fit into two buckets
+	minScale = -10
+	// maxScale is the highest scale supported by OpenTelemetry
+	maxScale = 20
+)
+
+// histogram is a base-2 exponential histogram as specified by OpenTelemetry.
+// The bucket with index i covers the range (base^i, base^(i+1)] with
+// base = 2^(2^-scale). Whenever the buckets of a sign would exceed the
+// maximum size, the scale is decreased by merging neighboring buckets.
+type histogram struct {
+	maxSize       int
+	zeroThreshold float64
+
+	scale     int32
+	count     uint64
+	sum       float64
+	min       float64
+	max       float64
+	zeroCount uint64
+	positive  buckets
+	negative  buckets
+}
+
+// buckets contains the consecutive bucket counts starting at the offset
+type buckets struct {
+	offset int32
+	counts []uint64
+}
+
+func newHistogram(scale int32, maxSize int, zeroThreshold float64) *histogram {
+	return &histogram{
+		maxSize:       maxSize,
+		zeroThreshold: zeroThreshold,
+		scale:         scale,
+		min:           math.Inf(1),
+		max:           math.Inf(-1),
+	}
+}
+
+func (h *histogram) add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+
+	h.count++
+	h.sum += v
+	h.min = math.Min(h.min, v)
+	h.max = math.Max(h.max, v)
+
+	abs := math.Abs(v)
+	if abs <= h.zeroThreshold || abs == 0 {
+		h.zeroCount++
+		return
+	}
+
+	b := &h.positive
+	if v < 0 {
+		b = &h.negative
+	}
+
+	index := mapToIndex(abs, h.scale)
+	if change := b.scaleChange(index, h.maxSize); change > 0 {
+		change = min(change, h.scale-minScale)
+		h.scale -= change
+		h.positive.downscale(change)
+		h.negative.downscale(change)
+		index = mapToIndex(abs, h.scale)
+	}
+	b.increment(index)
+}
+
+// mapToIndex returns the index of the bucket containing the positive value
+func mapToIndex(v float64, scale int32) int32 {
+	frac, exp := math.Frexp(v)
+	// Exact powers of two are the upper boundary of a bucket
+	exact := frac == 0.5
+
+	if scale <= 0 {
+		// The value is in the range [2^(exp-1), 2^exp)
+		index := int32(exp - 1)
+		if exact {
+			index--
+		}
+		return index >> -scale
+	}
+
+	if exact {
+		return (int32(exp-1) << scale) - 1
+	}
+	return int32(math.Ceil(math.Log2(v)*math.Ldexp(1, int(scale)))) - 1
+}
+
+// lowerBoundary returns the exclusive lower boundary of the bucket
+func lowerBoundary(index, scale int32) float64 {
+	return math.Exp2(math.Ldexp(float64(index), -int(scale)))
+}
+
+// scaleChange returns the number of scale steps required to keep the
+// buckets within the maximum size when adding the index
+func (b *buckets) scaleChange(index int32, maxSize int) int32 {
+	if len(b.counts) == 0 {
+		return 0
+	}
+	low, high := b.offset, b.offset+int32(len(b.counts))-1
+	if index < low {
+		low = index
+	} else if index > high {
+		high = index
+	}
+
+	var change int32
+	for int(high>>change)-int(low>>change)+1 > maxSize {
+		change++
+	}
+	return change
+}
+
+func (b *buckets) increment(index int32) {
+	if len(b.counts) == 0 {
+		b.offset = index
+		b.counts = []uint64{1}
+		return
+	}
+
+	switch end := b.offset + int32(len(b.counts)); {
+	case index < b.offset:
+		counts := make([]uint64, int(end-index))
+		copy(counts[b.offset-index:], b.counts)
+		b.counts = counts
+		b.offset = index
+	case index >= end:
+		b.counts = append(b.counts, make([]uint64, int(index-end)+1)...)
+	}
+	b.counts[index-b.offset]++
+}
+
+// downscale merges the buckets to match a scale reduced by the given change
+func (b *buckets) downscale(change int32) {
+	if change <= 0 || len(b.counts) == 0 {
+		return
+	}
+
+	offset := b.offset >> change
+	end := (b.offset + int32(len(b.counts)) - 1) >> change
+	counts := make([]uint64, int(end-offset)+1)
+	for i, c := range b.counts {
+		counts[((b.offset+int32(i))>>change)-offset] += c
+	}
+	b.offset = offset
+	b.counts = counts
+}
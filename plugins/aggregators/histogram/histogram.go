@@ -3,12 +3,16 @@ package histogram
 
 import (
 	_ "embed"
+	"errors"
+	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -26,6 +30,12 @@ const (
 	bucketLeftTag = "gt"
 	// bucketNegInf is the left bucket border for infinite values
 	bucketNegInf = "-Inf"
+
+	// defaultBucketsPerDecade is the default number of automatic buckets
+	// per power of ten, i.e. the borders are 1, 2, ..., 9 times the power
+	defaultBucketsPerDecade = 9
+	// defaultWarmup is the default duration for learning the automatic buckets
+	defaultWarmup = config.Duration(5 * time.Minute)
 )
 
 type Histogram struct {
@@ -37,13 +47,26 @@ type Histogram struct {
 
 	buckets bucketsByMetrics
 	cache   map[uint64]metricHistogramCollection
+	learned bucketsByMetrics
+	warmups map[string]*warmup
 }
 
 // bucketConfig is the config, which contains name, field of metric and histogram buckets.
 type bucketConfig struct {
-	Metric  string   `toml:"measurement_name"`
-	Fields  []string `toml:"fields"`
-	Buckets buckets  `toml:"buckets"`
+	Metric           string          `toml:"measurement_name"`
+	Fields           []string        `toml:"fields"`
+	Buckets          buckets         `toml:"buckets"`
+	AutoBuckets      bool            `toml:"auto_buckets"`
+	BucketsPerDecade int             `toml:"buckets_per_decade"`
+	Warmup           config.Duration `toml:"warmup"`
+}
+
+// warmup collects the value range of a field to derive the automatic buckets
+type warmup struct {
+	start       time.Time
+	minPositive float64
+	maxPositive float64
+	nonPositive bool
 }
 
 // bucketsByMetrics contains the buckets grouped by metric and field name
@@ -67,6 +90,21 @@ type metricHistogramCollection struct {
 // counts is the number of hits in the bucket
 type counts []int64
 
+// state contains the automatically derived buckets and the counts of the
+// histograms
+type state struct {
+	Learned bucketsByMetrics `json:"learned,omitempty"`
+	Series  []seriesState    `json:"series,omitempty"`
+}
+
+type seriesState struct {
+	Name       string            `json:"name"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Counts     map[string]counts `json:"counts"`
+	ExpireTime time.Time         `json:"expire_time"`
+	Updated    bool              `json:"updated"`
+}
+
 // groupedByCountFields contains grouped fields by their count and fields values
 type groupedByCountFields struct {
 	name            string
@@ -78,14 +116,97 @@ func (*Histogram) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *Histogram) Init() error {
+	for i := range h.Configs {
+		cfg := &h.Configs[i]
+		if !cfg.AutoBuckets {
+			continue
+		}
+		if len(cfg.Buckets) > 0 {
+			return fmt.Errorf("buckets and auto_buckets cannot be used together for %q", cfg.Metric)
+		}
+		if cfg.BucketsPerDecade == 0 {
+			cfg.BucketsPerDecade = defaultBucketsPerDecade
+		}
+		if cfg.BucketsPerDecade < 1 || cfg.BucketsPerDecade > 90 {
+			return errors.New("buckets_per_decade must be in the range of [1, 90]")
+		}
+		if cfg.Warmup == 0 {
+			cfg.Warmup = defaultWarmup
+		}
+		if cfg.Warmup < 0 {
+			return errors.New("warmup must not be negative")
+		}
+	}
+
+	return nil
+}
+
+// GetState returns the automatically derived buckets by metric and field as
+// well as the counts of the histograms
+func (h *Histogram) GetState() interface{} {
+	st := state{
+		Learned: h.learned,
+		Series:  make([]seriesState, 0, len(h.cache)),
+	}
+	for _, agr := range h.cache {
+		st.Series = append(st.Series, seriesState{
+			Name:       agr.name,
+			Tags:       agr.tags,
+			Counts:     agr.histogramCollection,
+			ExpireTime: agr.expireTime,
+			Updated:    agr.updated,
+		})
+	}
+	return st
+}
+
+func (h *Histogram) SetState(s interface{}) error {
+	st, ok := s.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", s)
+	}
+	for name, fields := range st.Learned {
+		for field, buckets := range fields {
+			h.setLearned(name, field, buckets)
+		}
+	}
+
+	for _, series := range st.Series {
+		agr := metricHistogramCollection{
+			name:                series.Name,
+			tags:                series.Tags,
+			histogramCollection: make(map[string]counts, len(series.Counts)),
+			expireTime:          series.ExpireTime,
+			updated:             series.Updated,
+		}
+		if agr.tags == nil {
+			agr.tags = make(map[string]string)
+		}
+		// Drop the counts if the buckets changed in the meantime
+		for field, c := range series.Counts {
+			if buckets := h.getBuckets(series.Name, field); buckets != nil && len(c) == len(buckets)+1 {
+				agr.histogramCollection[field] = c
+			}
+		}
+		if len(agr.histogramCollection) > 0 {
+			h.cache[metric.New(agr.name, agr.tags, nil, time.Time{}).HashID()] = agr
+		}
+	}
+	return nil
+}
+
 func (h *Histogram) Add(in telegraf.Metric) {
 	addTime := timeNow()
 
 	bucketsByField := make(map[string][]float64)
-	for field := range in.Fields() {
-		buckets := h.getBuckets(in.Name(), field)
+	for _, field := range in.FieldList() {
+		buckets := h.getBuckets(in.Name(), field.Key)
+		if buckets == nil {
+			buckets = h.learnBuckets(in.Name(), field.Key, field.Value, addTime)
+		}
 		if buckets != nil {
-			bucketsByField[field] = buckets
+			bucketsByField[field.Key] = buckets
 		}
 	}
 
@@ -219,17 +340,123 @@ func (h *Histogram) getBuckets(metric, field string) []float64 {
 				continue
 			}
 
+			buckets := cfg.Buckets
+			if cfg.AutoBuckets {
+				// Not available until the warm-up finished
+				if buckets = h.learned[metric][field]; buckets == nil {
+					continue
+				}
+			}
+
 			if _, ok := h.buckets[metric]; !ok {
 				h.buckets[metric] = make(bucketsByFields)
 			}
 
-			h.buckets[metric][field] = sortBuckets(cfg.Buckets)
+			h.buckets[metric][field] = sortBuckets(buckets)
 		}
 	}
 
 	return h.buckets[metric][field]
 }
 
+// learnBuckets collects the value range of fields with automatic buckets
+// during the warm-up and returns the derived buckets once it finished. The
+// values observed during the warm-up are not counted in the histogram.
+func (h *Histogram) learnBuckets(metric, field string, value interface{}, now time.Time) []float64 {
+	cfg := h.getAutoConfig(metric, field)
+	if cfg == nil {
+		return nil
+	}
+	v, ok := convert(value)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+
+	key := metric + "\x00" + field
+	w, ok := h.warmups[key]
+	if !ok {
+		w = &warmup{start: now, minPositive: math.Inf(1)}
+		h.warmups[key] = w
+	}
+	if v > 0 {
+		w.minPositive = math.Min(w.minPositive, v)
+		w.maxPositive = math.Max(w.maxPositive, v)
+	} else {
+		w.nonPositive = true
+	}
+	if now.Sub(w.start) < time.Duration(cfg.Warmup) {
+		return nil
+	}
+
+	delete(h.warmups, key)
+	buckets := logLinearBuckets(w.minPositive, w.maxPositive, w.nonPositive, cfg.BucketsPerDecade)
+	h.setLearned(metric, field, buckets)
+	return buckets
+}
+
+// getAutoConfig returns the config with automatic buckets matching the field
+func (h *Histogram) getAutoConfig(metric, field string) *bucketConfig {
+	for i, cfg := range h.Configs {
+		if cfg.Metric == metric && cfg.AutoBuckets && isBucketExists(field, cfg) {
+			return &h.Configs[i]
+		}
+	}
+	return nil
+}
+
+func (h *Histogram) setLearned(metric, field string, buckets []float64) {
+	if _, ok := h.learned[metric]; !ok {
+		h.learned[metric] = make(bucketsByFields)
+	}
+	h.learned[metric][field] = buckets
+
+	// Drop a cached lookup without buckets
+	if fields, ok := h.buckets[metric]; ok && fields[field] == nil {
+		delete(fields, field)
+	}
+}
+
+// logLinearBuckets returns bucket borders covering the given range of
+// positive values with the given number of linearly spaced borders per power
+// of ten, e.g. 1, 2, ..., 9, 10, 20, ..., 90, 100 for nine buckets per decade.
+// A border at zero is added for non-positive values.
+func logLinearBuckets(minValue, maxValue float64, nonPositive bool, perDecade int) []float64 {
+	var borders []float64
+	if nonPositive {
+		borders = append(borders, 0)
+	}
+	if maxValue <= 0 {
+		return borders
+	}
+
+	step := 9 / float64(perDecade)
+	for exp := math.Floor(math.Log10(minValue)); ; exp++ {
+		decade := math.Pow(10, exp)
+		for i := 0; i < perDecade; i++ {
+			// Round to avoid borders like 0.30000000000000004
+			border := roundSignificant(decade*(1+float64(i)*step), 12)
+			if border <= minValue {
+				// Only keep the largest border below the range
+				if n := len(borders); n > 0 && borders[n-1] > 0 {
+					borders = borders[:n-1]
+				}
+			}
+			borders = append(borders, border)
+			if border >= maxValue {
+				return borders
+			}
+		}
+	}
+}
+
+func roundSignificant(v float64, digits int) float64 {
+	if v == 0 {
+		return 0
+	}
+	scale := math.Pow(10, float64(digits)-math.Ceil(math.Log10(math.Abs(v))))
+	return math.Round(v*scale) / scale
+}
+
 // isBucketExists checks if buckets exists for the passed field
 func isBucketExists(field string, cfg bucketConfig) bool {
 	if len(cfg.Fields) == 0 {
@@ -309,6 +536,8 @@ func newHistogramAggregator() *Histogram {
 		Cumulative: true,
 	}
 	h.buckets = make(bucketsByMetrics)
+	h.learned = make(bucketsByMetrics)
+	h.warmups = make(map[string]*warmup)
 	h.resetCache()
 
 	return h
@@ -1,6 +1,7 @@
 package histogram
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -527,3 +528,150 @@ func assertContainsTaggedField(t *testing.T, acc *testutil.Accumulator, metricNa
 
 	require.Failf(t, "Unknown measurement", "Unknown measurement %q with tags: %v, fields: %v", metricName, tags, fields)
 }
+
+func TestLogLinearBuckets(t *testing.T) {
+	tests := []struct {
+		name        string
+		min         float64
+		max         float64
+		nonPositive bool
+		perDecade   int
+		expected    []float64
+	}{
+		{
+			name:      "nine per decade",
+			min:       5,
+			max:       120,
+			perDecade: 9,
+			expected:  []float64{5, 6, 7, 8, 9, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 200},
+		},
+		{
+			name:      "three per decade",
+			min:       5,
+			max:       120,
+			perDecade: 3,
+			expected:  []float64{4, 7, 10, 40, 70, 100, 400},
+		},
+		{
+			name:      "fractions",
+			min:       0.0023,
+			max:       0.3,
+			perDecade: 1,
+			expected:  []float64{0.001, 0.01, 0.1, 1},
+		},
+		{
+			name:        "with zero",
+			min:         0.15,
+			max:         0.29,
+			nonPositive: true,
+			perDecade:   9,
+			expected:    []float64{0, 0.1, 0.2, 0.3},
+		},
+		{
+			name:        "only zero",
+			nonPositive: true,
+			perDecade:   9,
+			expected:    []float64{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, logLinearBuckets(tt.min, tt.max, tt.nonPositive, tt.perDecade))
+		})
+	}
+}
+
+func TestHistogramAutoBucketsInitFail(t *testing.T) {
+	histogram := newHistogramAggregator()
+	histogram.Configs = []bucketConfig{{Metric: "cpu", AutoBuckets: true, Buckets: []float64{1}}}
+	require.ErrorContains(t, histogram.Init(), `buckets and auto_buckets cannot be used together for "cpu"`)
+
+	histogram = newHistogramAggregator()
+	histogram.Configs = []bucketConfig{{Metric: "cpu", AutoBuckets: true, BucketsPerDecade: 100}}
+	require.ErrorContains(t, histogram.Init(), "buckets_per_decade must be in the range of [1, 90]")
+}
+
+func TestHistogramAutoBuckets(t *testing.T) {
+	currentTime := time.Unix(0, 0)
+	timeNow = func() time.Time {
+		return currentTime
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+
+	histogram := newHistogramAggregator()
+	histogram.Configs = []bucketConfig{
+		{Metric: "http", Fields: []string{"latency"}, AutoBuckets: true, BucketsPerDecade: 1, Warmup: config.Duration(10 * time.Second)},
+	}
+	histogram.Cumulative = false
+	require.NoError(t, histogram.Init())
+
+	// Values during the warm-up only determine the buckets
+	for _, v := range []float64{5, 120} {
+		histogram.Add(metric.New("http", tags{}, fields{"latency": v, "other": 1.0}, time.Now()))
+		currentTime = currentTime.Add(5 * time.Second)
+	}
+	acc := &testutil.Accumulator{}
+	histogram.Push(acc)
+	require.Empty(t, acc.Metrics)
+
+	// The warm-up finished, so the value must be counted
+	histogram.Add(metric.New("http", tags{}, fields{"latency": 42.0}, time.Now()))
+	histogram.Push(acc)
+	require.Len(t, acc.Metrics, 5)
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(0)}, tags{bucketLeftTag: bucketNegInf, bucketRightTag: "1"})
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(0)}, tags{bucketLeftTag: "1", bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(1)}, tags{bucketLeftTag: "10", bucketRightTag: "100"})
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(0)}, tags{bucketLeftTag: "100", bucketRightTag: "1000"})
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(0)}, tags{bucketLeftTag: "1000", bucketRightTag: bucketPosInf})
+
+	// The learned buckets must be restored from the state
+	st := histogram.GetState().(state)
+	require.Equal(t, bucketsByMetrics{"http": bucketsByFields{"latency": buckets{1, 10, 100, 1000}}}, st.Learned)
+
+	restored := newHistogramAggregator()
+	restored.Configs = histogram.Configs
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state{Learned: st.Learned}))
+	restored.Add(metric.New("http", tags{}, fields{"latency": 7.0}, time.Now()))
+
+	acc = &testutil.Accumulator{}
+	restored.Push(acc)
+	require.Len(t, acc.Metrics, 5)
+	assertContainsTaggedField(t, acc, "http", fields{"latency_bucket": int64(1)}, tags{bucketRightTag: "10"})
+}
+
+// TestHistogramState tests restoring the counts of the histograms
+func TestHistogramState(t *testing.T) {
+	cfg := []bucketConfig{
+		{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0, 30.0, 40.0}},
+	}
+	histogram := newTestHistogram(cfg, false, true, false).(*Histogram)
+	require.NoError(t, histogram.Init())
+	histogram.Add(firstMetric1)
+
+	buf, err := json.Marshal(histogram.GetState())
+	require.NoError(t, err)
+	var st state
+	require.NoError(t, json.Unmarshal(buf, &st))
+
+	restored := newTestHistogram(cfg, false, true, false).(*Histogram)
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(st))
+	restored.Add(firstMetric2)
+
+	acc := &testutil.Accumulator{}
+	restored.Push(acc)
+	require.Len(t, acc.Metrics, 6)
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(0)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(2)}, tags{bucketRightTag: "20"})
+
+	// Counts not matching the configured buckets must be dropped
+	changed := newTestHistogram([]bucketConfig{
+		{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 100.0}},
+	}, false, true, false).(*Histogram)
+	require.NoError(t, changed.Init())
+	require.NoError(t, changed.SetState(st))
+	require.Empty(t, changed.cache)
+}
@@ -2,10 +2,17 @@
 package quantile
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/caio/go-tdigest"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -16,6 +23,7 @@ type Quantile struct {
 	Quantiles     []float64       `toml:"quantiles"`
 	Compression   float64         `toml:"compression"`
 	AlgorithmType string          `toml:"algorithm"`
+	Window        config.Duration `toml:"window"`
 	Log           telegraf.Logger `toml:"-"`
 
 	newAlgorithm newAlgorithmFunc
@@ -28,10 +36,42 @@ type aggregate struct {
 	name   string
 	fields map[string]algorithm
 	tags   map[string]string
+
+	// samples of each field within the sliding window
+	samples map[string][]sample
+}
+
+type sample struct {
+	timestamp time.Time
+	value     float64
 }
 
 type newAlgorithmFunc func(compression float64) (algorithm, error)
 
+// state contains the data of the current aggregation window or the samples
+// of the sliding window by series
+type state []seriesState
+
+type seriesState struct {
+	Name   string                `json:"name"`
+	Tags   map[string]string     `json:"tags,omitempty"`
+	Fields map[string]fieldState `json:"fields"`
+}
+
+type fieldState struct {
+	// Digest is the serialized t-digest
+	Digest []byte `json:"digest,omitempty"`
+	// Values are the values added to the exact algorithms
+	Values []float64 `json:"values,omitempty"`
+	// Samples are the samples of the sliding window
+	Samples []sampleState `json:"samples,omitempty"`
+}
+
+type sampleState struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
 func (*Quantile) SampleConfig() string {
 	return sampleConfig
 }
@@ -51,6 +91,10 @@ func (q *Quantile) Init() error {
 		return fmt.Errorf("cannot create %q algorithm: %w", q.AlgorithmType, err)
 	}
 
+	if q.Window < 0 {
+		return errors.New("window must not be negative")
+	}
+
 	if len(q.Quantiles) == 0 {
 		q.Quantiles = []float64{0.25, 0.5, 0.75}
 	}
@@ -68,12 +112,119 @@ func (q *Quantile) Init() error {
 		q.suffixes = append(q.suffixes, fmt.Sprintf("_%03d", int(qtl*100.0)))
 	}
 
-	q.Reset()
+	q.cache = make(map[uint64]aggregate)
+
+	return nil
+}
+
+// GetState returns the data of the current aggregation window or the samples
+// of the sliding window
+func (q *Quantile) GetState() interface{} {
+	st := make(state, 0, len(q.cache))
+	for _, a := range q.cache {
+		series := seriesState{
+			Name:   a.name,
+			Tags:   a.tags,
+			Fields: make(map[string]fieldState, len(a.fields)+len(a.samples)),
+		}
+		for k, algo := range a.fields {
+			var f fieldState
+			switch algo := algo.(type) {
+			case *tdigest.TDigest:
+				digest, err := algo.AsBytes()
+				if err != nil {
+					q.Log.Errorf("Serializing field %q failed: %v", k, err)
+					continue
+				}
+				f.Digest = digest
+			case *exactAlgorithmR7:
+				f.Values = algo.xs
+			case *exactAlgorithmR8:
+				f.Values = algo.xs
+			}
+			series.Fields[k] = f
+		}
+		for k, samples := range a.samples {
+			f := fieldState{Samples: make([]sampleState, 0, len(samples))}
+			for _, s := range samples {
+				f.Samples = append(f.Samples, sampleState{Time: s.timestamp, Value: s.value})
+			}
+			series.Fields[k] = f
+		}
+		st = append(st, series)
+	}
+	return st
+}
+
+func (q *Quantile) SetState(s interface{}) error {
+	st, ok := s.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", s)
+	}
+
+	for _, series := range st {
+		a := aggregate{
+			name: series.Name,
+			tags: series.Tags,
+		}
+		if a.tags == nil {
+			a.tags = make(map[string]string)
+		}
+		if q.Window > 0 {
+			a.samples = make(map[string][]sample, len(series.Fields))
+		} else {
+			a.fields = make(map[string]algorithm, len(series.Fields))
+		}
+
+		for k, f := range series.Fields {
+			if q.Window > 0 {
+				for _, s := range f.Samples {
+					a.samples[k] = append(a.samples[k], sample{timestamp: s.Time, value: s.Value})
+				}
+				continue
+			}
 
+			// Restore the digest only if the algorithm was not changed,
+			// values of the exact algorithms can be added to any algorithm
+			if len(f.Digest) > 0 {
+				if q.AlgorithmType != "t-digest" && q.AlgorithmType != "" {
+					continue
+				}
+				digest, err := tdigest.FromBytes(bytes.NewReader(f.Digest))
+				if err != nil {
+					return fmt.Errorf("restoring field %q failed: %w", k, err)
+				}
+				a.fields[k] = digest
+				continue
+			}
+			if len(f.Values) == 0 {
+				continue
+			}
+			algo, err := q.newAlgorithm(q.Compression)
+			if err != nil {
+				return fmt.Errorf("generating algorithm %s: %w", k, err)
+			}
+			for _, v := range f.Values {
+				if err := algo.Add(v); err != nil {
+					return fmt.Errorf("restoring field %q failed: %w", k, err)
+				}
+			}
+			a.fields[k] = algo
+		}
+		if len(a.fields) == 0 && len(a.samples) == 0 {
+			continue
+		}
+		q.cache[metric.New(a.name, a.tags, nil, time.Time{}).HashID()] = a
+	}
 	return nil
 }
 
 func (q *Quantile) Add(in telegraf.Metric) {
+	if q.Window > 0 {
+		q.addSamples(in)
+		return
+	}
+
 	id := in.HashID()
 	if cached, ok := q.cache[id]; ok {
 		fields := in.Fields()
@@ -112,7 +263,32 @@ func (q *Quantile) Add(in telegraf.Metric) {
 	q.cache[id] = a
 }
 
+// addSamples keeps the field values of the metric for computing the quantiles
+// of the sliding window
+func (q *Quantile) addSamples(in telegraf.Metric) {
+	id := in.HashID()
+	a, found := q.cache[id]
+	if !found {
+		a = aggregate{
+			name:    in.Name(),
+			tags:    in.Tags(),
+			samples: make(map[string][]sample),
+		}
+		q.cache[id] = a
+	}
+	for _, field := range in.FieldList() {
+		if v, isconvertible := convert(field.Value); isconvertible {
+			a.samples[field.Key] = append(a.samples[field.Key], sample{timestamp: in.Time(), value: v})
+		}
+	}
+}
+
 func (q *Quantile) Push(acc telegraf.Accumulator) {
+	if q.Window > 0 {
+		q.pushWindow(acc)
+		return
+	}
+
 	for _, aggregate := range q.cache {
 		fields := make(map[string]interface{}, len(aggregate.fields)*len(q.Quantiles))
 		for k, algo := range aggregate.fields {
@@ -124,8 +300,70 @@ func (q *Quantile) Push(acc telegraf.Accumulator) {
 	}
 }
 
+// pushWindow computes the quantiles of the samples within the window ending
+// now. The algorithms are recreated on each push as they cannot forget
+// samples leaving the window.
+func (q *Quantile) pushWindow(acc telegraf.Accumulator) {
+	cutoff := time.Now().Add(-time.Duration(q.Window))
+	for _, aggregate := range q.cache {
+		fields := make(map[string]interface{}, len(aggregate.samples)*len(q.Quantiles))
+		for k, samples := range aggregate.samples {
+			algo, err := q.newAlgorithm(q.Compression)
+			if err != nil {
+				q.Log.Errorf("generating algorithm %s: %v", k, err)
+				continue
+			}
+			var count int
+			for _, s := range samples {
+				if !s.timestamp.After(cutoff) {
+					continue
+				}
+				if err := algo.Add(s.value); err != nil {
+					q.Log.Errorf("adding field %s: %v", k, err)
+					continue
+				}
+				count++
+			}
+			if count == 0 {
+				continue
+			}
+			for i, qtl := range q.Quantiles {
+				fields[k+q.suffixes[i]] = algo.Quantile(qtl)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(aggregate.name, fields, aggregate.tags)
+		}
+	}
+}
+
 func (q *Quantile) Reset() {
-	q.cache = make(map[uint64]aggregate)
+	if q.Window <= 0 {
+		q.cache = make(map[uint64]aggregate)
+		return
+	}
+
+	// Keep the samples still within the window and remove series without
+	// any sample left
+	cutoff := time.Now().Add(-time.Duration(q.Window))
+	for id, aggregate := range q.cache {
+		for k, samples := range aggregate.samples {
+			kept := samples[:0]
+			for _, s := range samples {
+				if s.timestamp.After(cutoff) {
+					kept = append(kept, s)
+				}
+			}
+			if len(kept) == 0 {
+				delete(aggregate.samples, k)
+				continue
+			}
+			aggregate.samples[k] = kept
+		}
+		if len(aggregate.samples) == 0 {
+			delete(q.cache, id)
+		}
+	}
 }
 
 func convert(in interface{}) (float64, bool) {
@@ -1,6 +1,7 @@
 package quantile
 
 import (
+	"encoding/json"
 	"math/rand"
 	"testing"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -43,6 +46,12 @@ func TestConfigInvalidQuantiles(t *testing.T) {
 	require.Contains(t, err.Error(), "duplicate quantile")
 }
 
+func TestConfigInvalidWindow(t *testing.T) {
+	q := Quantile{Compression: 100, Window: config.Duration(-time.Minute)}
+	err := q.Init()
+	require.ErrorContains(t, err, "window must not be negative")
+}
+
 func TestSingleMetricTDigest(t *testing.T) {
 	acc := testutil.Accumulator{}
 
@@ -439,6 +448,53 @@ func TestMultipleMetricsExactR8(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), epsilon, sort)
 }
 
+func TestSlidingWindow(t *testing.T) {
+	q := Quantile{
+		AlgorithmType: "exact R7",
+		Quantiles:     []float64{0.5},
+		Window:        config.Duration(time.Minute),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, q.Init())
+
+	now := time.Now()
+	tags := map[string]string{"foo": "bar"}
+
+	// First period
+	q.Add(metric.New("test", tags, map[string]interface{}{"a": 1.0}, now.Add(-50*time.Second)))
+	q.Add(metric.New("test", tags, map[string]interface{}{"a": 3.0}, now.Add(-30*time.Second)))
+	q.Add(metric.New("old", nil, map[string]interface{}{"a": 42.0}, now.Add(-2*time.Minute)))
+
+	var acc testutil.Accumulator
+	q.Push(&acc)
+	expected := []telegraf.Metric{
+		metric.New("test", tags, map[string]interface{}{"a_050": 2.0}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The samples within the window must be kept and series without samples
+	// in the window must be removed on reset
+	q.Reset()
+	require.Len(t, q.cache, 1)
+
+	// Second period including the samples of the first one, excluding the
+	// samples outside the window
+	q.Add(metric.New("test", tags, map[string]interface{}{"a": 5.0}, now.Add(-10*time.Second)))
+	q.Add(metric.New("test", tags, map[string]interface{}{"a": 100.0}, now.Add(-5*time.Minute)))
+
+	acc.ClearMetrics()
+	q.Push(&acc)
+	expected = []telegraf.Metric{
+		metric.New("test", tags, map[string]interface{}{"a_050": 3.0}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	q.Reset()
+	for _, a := range q.cache {
+		require.Len(t, a.samples["a"], 3)
+	}
+}
+
 func BenchmarkDefaultTDigest(b *testing.B) {
 	metrics := make([]telegraf.Metric, 0, 100)
 	for i := 0; i < 100; i++ {
@@ -669,3 +725,75 @@ func BenchmarkDefaultExactR8100Q(b *testing.B) {
 		q.Push(&acc)
 	}
 }
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		window    time.Duration
+	}{
+		{algorithm: "t-digest"},
+		{algorithm: "exact R7"},
+		{algorithm: "exact R8"},
+		{algorithm: "exact R7", window: time.Hour},
+	}
+	for _, tt := range tests {
+		name := tt.algorithm
+		if tt.window > 0 {
+			name += " with window"
+		}
+		t.Run(name, func(t *testing.T) {
+			newAggregator := func() *Quantile {
+				q := &Quantile{
+					AlgorithmType: tt.algorithm,
+					Compression:   100,
+					Quantiles:     []float64{0.25, 0.5, 0.9},
+					Window:        config.Duration(tt.window),
+					Log:           testutil.Logger{},
+				}
+				require.NoError(t, q.Init())
+				return q
+			}
+
+			now := time.Now()
+			metrics := make([]telegraf.Metric, 0, 100)
+			for i := range 100 {
+				metrics = append(metrics, metric.New(
+					"test",
+					map[string]string{"foo": "bar"},
+					map[string]interface{}{"a": float64((i * 37) % 100)},
+					now.Add(time.Duration(i-100)*time.Second),
+				))
+			}
+
+			// Reference aggregating all metrics without interruption
+			reference := newAggregator()
+			for _, m := range metrics {
+				reference.Add(m)
+			}
+			var expected testutil.Accumulator
+			reference.Push(&expected)
+
+			// Aggregate the first half, then restore the serialized state in
+			// a new instance and aggregate the second half
+			q := newAggregator()
+			for _, m := range metrics[:50] {
+				q.Add(m)
+			}
+			buf, err := json.Marshal(q.GetState())
+			require.NoError(t, err)
+			var st state
+			require.NoError(t, json.Unmarshal(buf, &st))
+
+			restored := newAggregator()
+			require.NoError(t, restored.SetState(st))
+			for _, m := range metrics[50:] {
+				restored.Add(m)
+			}
+			var actual testutil.Accumulator
+			restored.Push(&actual)
+
+			require.NotEmpty(t, actual.GetTelegrafMetrics())
+			testutil.RequireMetricsEqual(t, expected.GetTelegrafMetrics(), actual.GetTelegrafMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0.02, 0))
+		})
+	}
+}
@@ -0,0 +1,274 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package session
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Default mapping of the syslog severity names and common aliases
+var defaultSeverityLevels = map[string]int64{
+	"trace":     0,
+	"debug":     0,
+	"info":      1,
+	"notice":    2,
+	"warn":      3,
+	"warning":   3,
+	"err":       4,
+	"error":     4,
+	"crit":      5,
+	"critical":  5,
+	"fatal":     5,
+	"alert":     6,
+	"emerg":     7,
+	"emergency": 7,
+	"panic":     7,
+}
+
+type Session struct {
+	CorrelationTag string           `toml:"correlation_tag"`
+	Timeout        config.Duration  `toml:"timeout"`
+	MaxDuration    config.Duration  `toml:"max_duration"`
+	SeverityField  string           `toml:"severity_field"`
+	SeverityTag    string           `toml:"severity_tag"`
+	SeverityLevels map[string]int64 `toml:"severity_levels"`
+	Log            telegraf.Logger  `toml:"-"`
+
+	levels   map[string]int64
+	sessions map[string]*session
+}
+
+// session contains the summary of the metrics sharing the same correlation
+// tag value. The first and last timestamps are taken from the metrics while
+// the started and seen times are the arrival times used for closing the
+// session.
+type session struct {
+	tags          map[string]string
+	count         int64
+	first         time.Time
+	last          time.Time
+	severity      int64
+	severityLabel string
+	hasSeverity   bool
+	started       time.Time
+	seen          time.Time
+}
+
+// state contains the sessions still open
+type state []sessionState
+
+type sessionState struct {
+	Tags          map[string]string `json:"tags"`
+	Count         int64             `json:"count"`
+	First         time.Time         `json:"first"`
+	Last          time.Time         `json:"last"`
+	Severity      *int64            `json:"severity,omitempty"`
+	SeverityLabel string            `json:"severity_label,omitempty"`
+	Started       time.Time         `json:"started"`
+	Seen          time.Time         `json:"seen"`
+}
+
+func (*Session) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Session) Init() error {
+	if s.CorrelationTag == "" {
+		return errors.New("correlation_tag must be set")
+	}
+	if s.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if s.MaxDuration < 0 {
+		return errors.New("max_duration must not be negative")
+	}
+	if s.SeverityField != "" && s.SeverityTag != "" {
+		return errors.New("severity_field and severity_tag cannot be set together")
+	}
+
+	levels := s.SeverityLevels
+	if len(levels) == 0 {
+		levels = defaultSeverityLevels
+	}
+	s.levels = make(map[string]int64, len(levels))
+	for label, level := range levels {
+		s.levels[strings.ToLower(label)] = level
+	}
+
+	s.sessions = make(map[string]*session)
+
+	return nil
+}
+
+func (s *Session) Add(in telegraf.Metric) {
+	id, found := in.GetTag(s.CorrelationTag)
+	if !found {
+		return
+	}
+
+	now := time.Now()
+	ts := in.Time()
+	sess, found := s.sessions[id]
+	if !found {
+		sess = &session{
+			tags:    in.Tags(),
+			first:   ts,
+			last:    ts,
+			started: now,
+		}
+		s.sessions[id] = sess
+	} else {
+		// Only keep the tags common to all metrics of the session
+		for k, v := range sess.tags {
+			if tv, found := in.GetTag(k); !found || tv != v {
+				delete(sess.tags, k)
+			}
+		}
+	}
+	sess.count++
+	sess.seen = now
+	if ts.Before(sess.first) {
+		sess.first = ts
+	}
+	if ts.After(sess.last) {
+		sess.last = ts
+	}
+
+	if level, label, found := s.severity(in); found {
+		if !sess.hasSeverity || level > sess.severity {
+			sess.severity = level
+			sess.severityLabel = label
+			sess.hasSeverity = true
+		}
+	}
+}
+
+func (s *Session) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	for id, sess := range s.sessions {
+		timedOut := now.Sub(sess.seen) >= time.Duration(s.Timeout)
+		tooLong := s.MaxDuration > 0 && now.Sub(sess.started) >= time.Duration(s.MaxDuration)
+		if !timedOut && !tooLong {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"count":    sess.count,
+			"duration": sess.last.Sub(sess.first).Seconds(),
+		}
+		if sess.hasSeverity {
+			fields["max_severity"] = sess.severity
+			if sess.severityLabel != "" {
+				fields["max_severity_label"] = sess.severityLabel
+			}
+		}
+		acc.AddFields("session", fields, sess.tags, sess.first)
+		delete(s.sessions, id)
+	}
+}
+
+func (*Session) Reset() {
+	// Sessions span multiple periods and are removed when being pushed
+}
+
+// GetState returns the sessions still open
+func (s *Session) GetState() interface{} {
+	st := make(state, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		ss := sessionState{
+			Tags:    sess.tags,
+			Count:   sess.count,
+			First:   sess.first,
+			Last:    sess.last,
+			Started: sess.started,
+			Seen:    sess.seen,
+		}
+		if sess.hasSeverity {
+			severity := sess.severity
+			ss.Severity = &severity
+			ss.SeverityLabel = sess.severityLabel
+		}
+		st = append(st, ss)
+	}
+	return st
+}
+
+func (s *Session) SetState(st interface{}) error {
+	sessions, ok := st.(state)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", st)
+	}
+
+	for _, ss := range sessions {
+		id, found := ss.Tags[s.CorrelationTag]
+		if !found {
+			s.Log.Debugf("Ignoring session without correlation tag %q", s.CorrelationTag)
+			continue
+		}
+		sess := &session{
+			tags:    ss.Tags,
+			count:   ss.Count,
+			first:   ss.First,
+			last:    ss.Last,
+			started: ss.Started,
+			seen:    ss.Seen,
+		}
+		if ss.Severity != nil {
+			sess.severity = *ss.Severity
+			sess.severityLabel = ss.SeverityLabel
+			sess.hasSeverity = true
+		}
+		s.sessions[id] = sess
+	}
+	return nil
+}
+
+// severity returns the severity level of the metric and the label if the
+// level was derived from a string
+func (s *Session) severity(in telegraf.Metric) (level int64, label string, found bool) {
+	var raw interface{}
+	switch {
+	case s.SeverityField != "":
+		raw, found = in.GetField(s.SeverityField)
+	case s.SeverityTag != "":
+		raw, found = in.GetTag(s.SeverityTag)
+	}
+	if !found {
+		return 0, "", false
+	}
+
+	if label, ok := raw.(string); ok {
+		if level, found := s.levels[strings.ToLower(label)]; found {
+			return level, label, true
+		}
+		// Tags might contain numeric levels
+		level, err := strconv.ParseInt(label, 10, 64)
+		return level, "", err == nil
+	}
+	level, err := internal.ToInt64(raw)
+	if err != nil {
+		s.Log.Debugf("Ignoring severity %v of type %T: %v", raw, raw, err)
+		return 0, "", false
+	}
+	return level, "", true
+}
+
+func init() {
+	aggregators.Add("session", func() telegraf.Aggregator {
+		return &Session{
+			Timeout: config.Duration(30 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin(t *testing.T, customize func(*Session)) *Session {
+	t.Helper()
+
+	plugin := aggregators.Aggregators["session"]().(*Session)
+	plugin.CorrelationTag = "request_id"
+	plugin.Log = testutil.Logger{}
+	customize(plugin)
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+// age moves the arrival times of all sessions into the past
+func age(plugin *Session, d time.Duration) {
+	for _, sess := range plugin.sessions {
+		sess.started = sess.started.Add(-d)
+		sess.seen = sess.seen.Add(-d)
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		customize func(*Session)
+		expected  string
+	}{
+		{
+			name:      "no correlation tag",
+			customize: func(s *Session) { s.CorrelationTag = "" },
+			expected:  "correlation_tag must be set",
+		},
+		{
+			name:      "zero timeout",
+			customize: func(s *Session) { s.Timeout = 0 },
+			expected:  "timeout must be positive",
+		},
+		{
+			name:      "negative max duration",
+			customize: func(s *Session) { s.MaxDuration = config.Duration(-time.Second) },
+			expected:  "max_duration must not be negative",
+		},
+		{
+			name: "severity field and tag",
+			customize: func(s *Session) {
+				s.SeverityField = "level"
+				s.SeverityTag = "level"
+			},
+			expected: "severity_field and severity_tag cannot be set together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := aggregators.Aggregators["session"]().(*Session)
+			plugin.CorrelationTag = "request_id"
+			tt.customize(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	plugin := newPlugin(t, func(s *Session) { s.SeverityTag = "level" })
+
+	start := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("nginx",
+			map[string]string{"request_id": "a", "host": "web1", "level": "info"},
+			map[string]interface{}{"status": 200},
+			start,
+		),
+		metric.New("app",
+			map[string]string{"request_id": "b", "host": "web1", "level": "INFO"},
+			map[string]interface{}{"message": "started"},
+			start.Add(100*time.Millisecond),
+		),
+		metric.New("app",
+			map[string]string{"request_id": "a", "host": "web1", "service": "api", "level": "error"},
+			map[string]interface{}{"message": "failed"},
+			start.Add(1500*time.Millisecond),
+		),
+		metric.New("app",
+			map[string]string{"request_id": "a", "host": "web1", "level": "warn"},
+			map[string]interface{}{"message": "retrying"},
+			start.Add(500*time.Millisecond),
+		),
+		metric.New("app",
+			map[string]string{"host": "web1", "level": "emerg"},
+			map[string]interface{}{"message": "uncorrelated"},
+			start,
+		),
+		metric.New("app",
+			map[string]string{"request_id": "c", "host": "web2", "level": "4"},
+			map[string]interface{}{"message": "numeric"},
+			start,
+		),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	// Sessions must not be emitted before the timeout
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Sessions must survive the reset and be emitted after the timeout
+	age(plugin, time.Minute)
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New("session",
+			map[string]string{"request_id": "a", "host": "web1"},
+			map[string]interface{}{
+				"count":              int64(3),
+				"duration":           1.5,
+				"max_severity":       int64(4),
+				"max_severity_label": "error",
+			},
+			start,
+		),
+		metric.New("session",
+			map[string]string{"request_id": "b", "host": "web1", "level": "INFO"},
+			map[string]interface{}{
+				"count":              int64(1),
+				"duration":           float64(0),
+				"max_severity":       int64(1),
+				"max_severity_label": "INFO",
+			},
+			start.Add(100*time.Millisecond),
+		),
+		metric.New("session",
+			map[string]string{"request_id": "c", "host": "web2", "level": "4"},
+			map[string]interface{}{
+				"count":        int64(1),
+				"duration":     float64(0),
+				"max_severity": int64(4),
+			},
+			start,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+	require.Empty(t, plugin.sessions)
+}
+
+func TestSeverityField(t *testing.T) {
+	plugin := newPlugin(t, func(s *Session) {
+		s.SeverityField = "severity"
+		s.SeverityLevels = map[string]int64{"OK": 0, "Degraded": 1, "Down": 2}
+	})
+
+	start := time.Unix(1700000000, 0)
+	for i, severity := range []interface{}{"ok", "down", int64(1), "unknown"} {
+		plugin.Add(metric.New("check",
+			map[string]string{"request_id": "a"},
+			map[string]interface{}{"severity": severity},
+			start.Add(time.Duration(i)*time.Second),
+		))
+	}
+	plugin.Add(metric.New("check",
+		map[string]string{"request_id": "b"},
+		map[string]interface{}{"severity": 2.7},
+		start,
+	))
+	plugin.Add(metric.New("check",
+		map[string]string{"request_id": "c"},
+		map[string]interface{}{"value": 42},
+		start,
+	))
+	age(plugin, time.Minute)
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("session",
+			map[string]string{"request_id": "a"},
+			map[string]interface{}{
+				"count":              int64(4),
+				"duration":           float64(3),
+				"max_severity":       int64(2),
+				"max_severity_label": "down",
+			},
+			start,
+		),
+		metric.New("session",
+			map[string]string{"request_id": "b"},
+			map[string]interface{}{
+				"count":        int64(1),
+				"duration":     float64(0),
+				"max_severity": int64(2),
+			},
+			start,
+		),
+		metric.New("session",
+			map[string]string{"request_id": "c"},
+			map[string]interface{}{
+				"count":    int64(1),
+				"duration": float64(0),
+			},
+			start,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestMaxDuration(t *testing.T) {
+	plugin := newPlugin(t, func(s *Session) { s.MaxDuration = config.Duration(time.Minute) })
+
+	start := time.Unix(1700000000, 0)
+	plugin.Add(metric.New("app", map[string]string{"request_id": "a"}, map[string]interface{}{"value": 1}, start))
+
+	// Keep the session active but exceed the maximum duration
+	age(plugin, 2*time.Minute)
+	plugin.Add(metric.New("app", map[string]string{"request_id": "a"}, map[string]interface{}{"value": 2}, start.Add(2*time.Minute)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// Subsequent metrics start a new session
+	plugin.Add(metric.New("app", map[string]string{"request_id": "a"}, map[string]interface{}{"value": 3}, start.Add(3*time.Minute)))
+	require.Contains(t, plugin.sessions, "a")
+	require.Equal(t, int64(1), plugin.sessions["a"].count)
+}
+
+func TestState(t *testing.T) {
+	plugin := newPlugin(t, func(s *Session) { s.SeverityField = "level" })
+
+	start := time.Unix(1700000000, 0)
+	plugin.Add(metric.New("app",
+		map[string]string{"request_id": "a"},
+		map[string]interface{}{"level": "warning"},
+		start,
+	))
+	plugin.Add(metric.New("app",
+		map[string]string{"request_id": "b"},
+		map[string]interface{}{"value": 1},
+		start,
+	))
+
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var st state
+	require.NoError(t, json.Unmarshal(buf, &st))
+
+	restored := newPlugin(t, func(s *Session) { s.SeverityField = "level" })
+	require.NoError(t, restored.SetState(st))
+	restored.Add(metric.New("app",
+		map[string]string{"request_id": "a"},
+		map[string]interface{}{"level": "info"},
+		start.Add(2*time.Second),
+	))
+	age(restored, time.Minute)
+
+	var acc testutil.Accumulator
+	restored.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("session",
+			map[string]string{"request_id": "a"},
+			map[string]interface{}{
+				"count":              int64(2),
+				"duration":           float64(2),
+				"max_severity":       int64(3),
+				"max_severity_label": "warning",
+			},
+			start,
+		),
+		metric.New("session",
+			map[string]string{"request_id": "b"},
+			map[string]interface{}{
+				"count":    int64(1),
+				"duration": float64(0),
+			},
+			start,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
@@ -0,0 +1,281 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package slo
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SLO struct {
+	Objective        float64           `toml:"objective"`
+	SuccessField     string            `toml:"success_field"`
+	TotalField       string            `toml:"total_field"`
+	Cumulative       bool              `toml:"cumulative"`
+	LatencyField     string            `toml:"latency_field"`
+	LatencyThreshold float64           `toml:"latency_threshold"`
+	Windows          []config.Duration `toml:"windows"`
+	BudgetWindow     config.Duration   `toml:"budget_window"`
+	Resolution       config.Duration   `toml:"resolution"`
+	Log              telegraf.Logger   `toml:"-"`
+
+	retention time.Duration
+	cache     map[uint64]*series
+}
+
+// series contains the number of good and total events of a series in
+// buckets of the configured resolution
+type series struct {
+	name    string
+	tags    map[string]string
+	buckets map[int64]*bucket
+	updated bool
+
+	// last values of cumulative counters
+	initialized bool
+	lastSuccess float64
+	lastTotal   float64
+}
+
+type bucket struct {
+	good  float64
+	total float64
+}
+
+func (*SLO) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SLO) Init() error {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return errors.New("objective must be between 0 and 1 (exclusive)")
+	}
+
+	ratio := s.SuccessField != "" || s.TotalField != ""
+	latency := s.LatencyField != ""
+	switch {
+	case ratio && latency:
+		return errors.New("success_field and total_field cannot be used together with latency_field")
+	case ratio:
+		if s.SuccessField == "" || s.TotalField == "" {
+			return errors.New("success_field and total_field must be set together")
+		}
+	case latency:
+		if s.Cumulative {
+			return errors.New("cumulative cannot be used with latency_field")
+		}
+	default:
+		return errors.New("either success_field and total_field or latency_field must be set")
+	}
+
+	if s.Resolution <= 0 {
+		return errors.New("resolution must be positive")
+	}
+	if s.BudgetWindow < s.Resolution {
+		return errors.New("budget_window must not be shorter than resolution")
+	}
+	s.retention = time.Duration(s.BudgetWindow)
+	seen := make(map[string]bool, len(s.Windows))
+	for _, w := range s.Windows {
+		if w < s.Resolution {
+			return fmt.Errorf("window %s must not be shorter than resolution", time.Duration(w))
+		}
+		name := windowName(time.Duration(w))
+		if seen[name] {
+			return fmt.Errorf("duplicate window %s", name)
+		}
+		seen[name] = true
+		s.retention = max(s.retention, time.Duration(w))
+	}
+
+	s.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (s *SLO) Add(in telegraf.Metric) {
+	var good, total float64
+	if s.LatencyField != "" {
+		raw, found := in.GetField(s.LatencyField)
+		if !found {
+			return
+		}
+		latency, ok := convert(raw)
+		if !ok {
+			return
+		}
+		total = 1
+		if latency <= s.LatencyThreshold {
+			good = 1
+		}
+	} else {
+		rawSuccess, foundSuccess := in.GetField(s.SuccessField)
+		rawTotal, foundTotal := in.GetField(s.TotalField)
+		if !foundSuccess || !foundTotal {
+			return
+		}
+		var okSuccess, okTotal bool
+		good, okSuccess = convert(rawSuccess)
+		total, okTotal = convert(rawTotal)
+		if !okSuccess || !okTotal {
+			return
+		}
+	}
+
+	id := in.HashID()
+	ser, found := s.cache[id]
+	if !found {
+		ser = &series{
+			name:    in.Name(),
+			tags:    in.Tags(),
+			buckets: make(map[int64]*bucket),
+		}
+		s.cache[id] = ser
+	}
+	ser.updated = true
+
+	if s.Cumulative {
+		if !ser.initialized {
+			ser.lastSuccess, ser.lastTotal = good, total
+			ser.initialized = true
+			return
+		}
+
+		// A decreasing value indicates a counter reset so the counters
+		// started at zero again
+		deltaGood, deltaTotal := good-ser.lastSuccess, total-ser.lastTotal
+		if deltaGood < 0 || deltaTotal < 0 {
+			s.Log.Debugf("Detected counter reset in %q", in.Name())
+			deltaGood, deltaTotal = good, total
+		}
+		ser.lastSuccess, ser.lastTotal = good, total
+		good, total = deltaGood, deltaTotal
+	}
+	if total <= 0 {
+		return
+	}
+	if good > total {
+		s.Log.Debugf("Limiting successful events %v to total events %v in %q", good, total, in.Name())
+		good = total
+	}
+
+	start := in.Time().Truncate(time.Duration(s.Resolution)).UnixNano()
+	b, found := ser.buckets[start]
+	if !found {
+		b = &bucket{}
+		ser.buckets[start] = b
+	}
+	b.good += good
+	b.total += total
+}
+
+func (s *SLO) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	budget := 1 - s.Objective
+	for _, ser := range s.cache {
+		fields := make(map[string]interface{}, 2*len(s.Windows)+3)
+		for _, w := range s.Windows {
+			good, total := ser.sum(now, time.Duration(w), time.Duration(s.Resolution))
+			if total == 0 {
+				continue
+			}
+			name := windowName(time.Duration(w))
+			fields["availability_"+name] = good / total
+			fields["burn_rate_"+name] = (1 - good/total) / budget
+		}
+
+		good, total := ser.sum(now, time.Duration(s.BudgetWindow), time.Duration(s.Resolution))
+		if total > 0 {
+			fields["availability"] = good / total
+			fields["error_budget_remaining"] = 1 - (1-good/total)/budget
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		fields["objective"] = s.Objective
+		acc.AddFields(ser.name, fields, ser.tags)
+	}
+}
+
+func (s *SLO) Reset() {
+	// Keep the buckets still within the longest window and remove series
+	// without any events left and without metrics in the past period
+	cutoff := time.Now().Add(-s.retention).Truncate(time.Duration(s.Resolution)).UnixNano()
+	for id, ser := range s.cache {
+		for start := range ser.buckets {
+			if start < cutoff {
+				delete(ser.buckets, start)
+			}
+		}
+		if len(ser.buckets) == 0 && !ser.updated {
+			delete(s.cache, id)
+		}
+		ser.updated = false
+	}
+}
+
+// sum returns the number of good and total events in the buckets
+// overlapping the window ending at the given time
+func (ser *series) sum(now time.Time, window, resolution time.Duration) (good, total float64) {
+	cutoff := now.Add(-window).UnixNano()
+	for start, b := range ser.buckets {
+		if start+int64(resolution) <= cutoff {
+			continue
+		}
+		good += b.good
+		total += b.total
+	}
+	return good, total
+}
+
+// windowName returns a short name of the window for use in field names,
+// e.g. "5m" or "30d"
+func windowName(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	aggregators.Add("slo", func() telegraf.Aggregator {
+		return &SLO{
+			Objective: 0.999,
+			Windows: []config.Duration{
+				config.Duration(5 * time.Minute),
+				config.Duration(time.Hour),
+				config.Duration(6 * time.Hour),
+			},
+			BudgetWindow: config.Duration(30 * 24 * time.Hour),
+			Resolution:   config.Duration(time.Minute),
+		}
+	})
+}
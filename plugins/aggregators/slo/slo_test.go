@@ -0,0 +1,252 @@
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin(t *testing.T, customize func(*SLO)) *SLO {
+	t.Helper()
+
+	plugin := aggregators.Aggregators["slo"]().(*SLO)
+	plugin.Log = testutil.Logger{}
+	customize(plugin)
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		customize func(*SLO)
+		expected  string
+	}{
+		{
+			name:      "invalid objective",
+			customize: func(s *SLO) { s.Objective = 1 },
+			expected:  "objective must be between 0 and 1",
+		},
+		{
+			name:      "no fields",
+			customize: func(*SLO) {},
+			expected:  "either success_field and total_field or latency_field must be set",
+		},
+		{
+			name:      "success without total",
+			customize: func(s *SLO) { s.SuccessField = "ok" },
+			expected:  "success_field and total_field must be set together",
+		},
+		{
+			name: "ratio and latency",
+			customize: func(s *SLO) {
+				s.SuccessField = "ok"
+				s.TotalField = "total"
+				s.LatencyField = "latency"
+			},
+			expected: "cannot be used together with latency_field",
+		},
+		{
+			name: "cumulative latency",
+			customize: func(s *SLO) {
+				s.LatencyField = "latency"
+				s.Cumulative = true
+			},
+			expected: "cumulative cannot be used with latency_field",
+		},
+		{
+			name: "window shorter than resolution",
+			customize: func(s *SLO) {
+				s.LatencyField = "latency"
+				s.Windows = []config.Duration{config.Duration(time.Second)}
+			},
+			expected: "window 1s must not be shorter than resolution",
+		},
+		{
+			name: "duplicate window",
+			customize: func(s *SLO) {
+				s.LatencyField = "latency"
+				s.Windows = []config.Duration{config.Duration(time.Hour), config.Duration(60 * time.Minute)}
+			},
+			expected: "duplicate window 1h",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := aggregators.Aggregators["slo"]().(*SLO)
+			plugin.Log = testutil.Logger{}
+			tt.customize(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWindowName(t *testing.T) {
+	require.Equal(t, "5m", windowName(5*time.Minute))
+	require.Equal(t, "6h", windowName(6*time.Hour))
+	require.Equal(t, "30d", windowName(720*time.Hour))
+	require.Equal(t, "90s", windowName(90*time.Second))
+	require.Equal(t, "1500ms", windowName(1500*time.Millisecond))
+}
+
+func TestRatio(t *testing.T) {
+	plugin := newPlugin(t, func(s *SLO) {
+		s.SuccessField = "success"
+		s.TotalField = "total"
+	})
+
+	now := time.Now()
+	tags := map[string]string{"service": "api"}
+	samples := []struct {
+		offset  time.Duration
+		success int64
+		total   int64
+	}{
+		{offset: 2 * time.Minute, success: 99, total: 100},
+		{offset: 30 * time.Minute, success: 90, total: 100},
+		{offset: 3 * time.Hour, success: 100, total: 100},
+		{offset: 10 * 24 * time.Hour, success: 1006, total: 1100},
+	}
+	for _, s := range samples {
+		plugin.Add(metric.New("http", tags, map[string]interface{}{"success": s.success, "total": s.total}, now.Add(-s.offset)))
+	}
+	// Metrics without the fields and events outside the budget window must
+	// be ignored
+	plugin.Add(metric.New("http", tags, map[string]interface{}{"success": int64(1)}, now))
+	plugin.Add(metric.New("http", tags, map[string]interface{}{"success": int64(0), "total": int64(10)}, now.Add(-800*time.Hour)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http",
+			tags,
+			map[string]interface{}{
+				"availability_5m":        0.99,
+				"burn_rate_5m":           10.0,
+				"availability_1h":        0.945,
+				"burn_rate_1h":           55.0,
+				"availability_6h":        289.0 / 300.0,
+				"burn_rate_6h":           (11.0 / 300.0) / 0.001,
+				"availability":           0.925,
+				"error_budget_remaining": -74.0,
+				"objective":              0.999,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestCumulative(t *testing.T) {
+	plugin := newPlugin(t, func(s *SLO) {
+		s.SuccessField = "success"
+		s.TotalField = "total"
+		s.Cumulative = true
+		s.Windows = []config.Duration{config.Duration(time.Hour)}
+	})
+
+	// The counters are reset before the fourth sample
+	now := time.Now()
+	samples := []struct {
+		success uint64
+		total   uint64
+	}{
+		{success: 1000, total: 1000},
+		{success: 1995, total: 2000},
+		{success: 2990, total: 3000},
+		{success: 995, total: 1000},
+	}
+	for i, s := range samples {
+		ts := now.Add(time.Duration(i-4) * time.Minute)
+		plugin.Add(metric.New("http", nil, map[string]interface{}{"success": s.success, "total": s.total}, ts))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http",
+			map[string]string{},
+			map[string]interface{}{
+				"availability_1h":        0.995,
+				"burn_rate_1h":           5.0,
+				"availability":           0.995,
+				"error_budget_remaining": -4.0,
+				"objective":              0.999,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestLatency(t *testing.T) {
+	plugin := newPlugin(t, func(s *SLO) {
+		s.Objective = 0.9
+		s.LatencyField = "response_time"
+		s.LatencyThreshold = 0.3
+		s.Windows = []config.Duration{config.Duration(5 * time.Minute)}
+	})
+
+	now := time.Now()
+	for i, v := range []float64{0.1, 0.2, 0.3, 0.5} {
+		plugin.Add(metric.New("http", nil, map[string]interface{}{"response_time": v}, now.Add(-time.Duration(i)*time.Second)))
+	}
+	plugin.Add(metric.New("http", nil, map[string]interface{}{"response_time": "slow"}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http",
+			map[string]string{},
+			map[string]interface{}{
+				"availability_5m":        0.75,
+				"burn_rate_5m":           2.5,
+				"availability":           0.75,
+				"error_budget_remaining": -1.5,
+				"objective":              0.9,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestReset(t *testing.T) {
+	plugin := newPlugin(t, func(s *SLO) {
+		s.SuccessField = "success"
+		s.TotalField = "total"
+		s.BudgetWindow = config.Duration(24 * time.Hour)
+	})
+
+	now := time.Now()
+	fields := map[string]interface{}{"success": 1.0, "total": 1.0}
+	plugin.Add(metric.New("http", map[string]string{"service": "a"}, fields, now.Add(-time.Hour)))
+	plugin.Add(metric.New("http", map[string]string{"service": "a"}, fields, now.Add(-48*time.Hour)))
+	plugin.Add(metric.New("http", map[string]string{"service": "b"}, fields, now.Add(-48*time.Hour)))
+
+	// Series updated in the past period must be kept until the next reset
+	plugin.Reset()
+	require.Len(t, plugin.cache, 2)
+
+	// Buckets outside the budget window must be removed, as well as series
+	// without buckets left
+	plugin.Reset()
+	require.Len(t, plugin.cache, 1)
+	for _, ser := range plugin.cache {
+		require.Len(t, ser.buckets, 1)
+	}
+}
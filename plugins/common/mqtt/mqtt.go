@@ -49,6 +49,7 @@ type MqttConfig struct {
 type Client interface {
 	Connect() (bool, error)
 	Publish(topic string, data []byte) error
+	PublishRetained(topic string, data []byte) error
 	SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error
 	AddRoute(topic string, callback paho.MessageHandler)
 	Close() error
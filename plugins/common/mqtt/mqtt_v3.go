@@ -114,7 +114,17 @@ func (m *mqttv311Client) Connect() (bool, error) {
 }
 
 func (m *mqttv311Client) Publish(topic string, body []byte) error {
-	token := m.client.Publish(topic, byte(m.qos), m.retain, body)
+	return m.publish(topic, body, m.retain)
+}
+
+// PublishRetained publishes the message with the retain flag set regardless
+// of the configured setting.
+func (m *mqttv311Client) PublishRetained(topic string, body []byte) error {
+	return m.publish(topic, body, true)
+}
+
+func (m *mqttv311Client) publish(topic string, body []byte, retain bool) error {
+	token := m.client.Publish(topic, byte(m.qos), retain, body)
 	if !token.WaitTimeout(m.timeout) {
 		return internal.ErrTimeout
 	}
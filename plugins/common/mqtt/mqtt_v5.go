@@ -136,13 +136,23 @@ func (m *mqttv5Client) Connect() (bool, error) {
 }
 
 func (m *mqttv5Client) Publish(topic string, body []byte) error {
+	return m.publish(topic, body, m.retain)
+}
+
+// PublishRetained publishes the message with the retain flag set regardless
+// of the configured setting.
+func (m *mqttv5Client) PublishRetained(topic string, body []byte) error {
+	return m.publish(topic, body, true)
+}
+
+func (m *mqttv5Client) publish(topic string, body []byte, retain bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
 	defer cancel()
 
 	_, err := m.client.Publish(ctx, &mqttv5.Publish{
 		Topic:      topic,
 		QoS:        byte(m.qos),
-		Retain:     m.retain,
+		Retain:     retain,
 		Payload:    body,
 		Properties: m.properties,
 	})
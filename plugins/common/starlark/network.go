@@ -0,0 +1,244 @@
+package starlark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+)
+
+const (
+	// maximum size of HTTP response bodies returned to scripts
+	maxBodySize = 1024 * 1024
+	// maximum number of redirects followed per HTTP request
+	maxRedirects = 10
+)
+
+// Network contains the settings for the network access of scripts via the
+// "http.star" and "dns.star" modules. Network access is disabled unless hosts
+// or domains are allowed.
+type Network struct {
+	HTTPAllowedHosts      []string        `toml:"http_allowed_hosts"`
+	DNSAllowedDomains     []string        `toml:"dns_allowed_domains"`
+	NetworkTimeout        config.Duration `toml:"network_timeout"`
+	NetworkBudget         int             `toml:"network_budget"`
+	NetworkBudgetInterval config.Duration `toml:"network_budget_interval"`
+
+	httpFilter filter.Filter
+	dnsFilter  filter.Filter
+	client     *http.Client
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	sync.Mutex
+	budgetStart time.Time
+	budgetUsed  int
+}
+
+func (n *Network) Init() error {
+	if len(n.HTTPAllowedHosts) == 0 && len(n.DNSAllowedDomains) == 0 {
+		return nil
+	}
+
+	if n.NetworkTimeout <= 0 {
+		return errors.New("network_timeout must be positive")
+	}
+	if n.NetworkBudget <= 0 {
+		return errors.New("network_budget must be positive")
+	}
+	if n.NetworkBudgetInterval <= 0 {
+		return errors.New("network_budget_interval must be positive")
+	}
+
+	var err error
+	if len(n.HTTPAllowedHosts) > 0 {
+		n.httpFilter, err = filter.Compile(n.HTTPAllowedHosts)
+		if err != nil {
+			return fmt.Errorf("creating HTTP host filter failed: %w", err)
+		}
+	}
+	if len(n.DNSAllowedDomains) > 0 {
+		n.dnsFilter, err = filter.Compile(n.DNSAllowedDomains)
+		if err != nil {
+			return fmt.Errorf("creating DNS domain filter failed: %w", err)
+		}
+	}
+
+	n.client = &http.Client{
+		Timeout: time.Duration(n.NetworkTimeout),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if err := n.checkURL(req.URL); err != nil {
+				return err
+			}
+			// Each redirect is a request of its own
+			return n.take()
+		},
+	}
+	if n.lookupHost == nil {
+		n.lookupHost = net.DefaultResolver.LookupHost
+	}
+
+	return nil
+}
+
+// LoadFunc returns a load function providing the network modules in addition
+// to the modules of the given load function
+func (n *Network) LoadFunc(
+	next func(module string, logger telegraf.Logger) (starlark.StringDict, error),
+) func(module string, logger telegraf.Logger) (starlark.StringDict, error) {
+	return func(module string, logger telegraf.Logger) (starlark.StringDict, error) {
+		switch module {
+		case "http.star":
+			if n.httpFilter == nil {
+				return nil, errors.New("module http.star is not available, no HTTP hosts are allowed")
+			}
+			return starlark.StringDict{
+				"http": &starlarkstruct.Module{
+					Name: "http",
+					Members: starlark.StringDict{
+						"get": starlark.NewBuiltin("http.get", n.httpGet),
+					},
+				},
+			}, nil
+		case "dns.star":
+			if n.dnsFilter == nil {
+				return nil, errors.New("module dns.star is not available, no DNS domains are allowed")
+			}
+			return starlark.StringDict{
+				"dns": &starlarkstruct.Module{
+					Name: "dns",
+					Members: starlark.StringDict{
+						"lookup": starlark.NewBuiltin("dns.lookup", n.dnsLookup),
+					},
+				},
+			}, nil
+		}
+		return next(module, logger)
+	}
+}
+
+// take one request from the budget of the current interval
+func (n *Network) take() error {
+	n.Lock()
+	defer n.Unlock()
+
+	now := time.Now()
+	if now.Sub(n.budgetStart) >= time.Duration(n.NetworkBudgetInterval) {
+		n.budgetStart = now
+		n.budgetUsed = 0
+	}
+	if n.budgetUsed >= n.NetworkBudget {
+		return fmt.Errorf("network budget of %d requests per %s exhausted", n.NetworkBudget, time.Duration(n.NetworkBudgetInterval))
+	}
+	n.budgetUsed++
+	return nil
+}
+
+func (n *Network) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme %q not allowed", u.Scheme)
+	}
+	if !n.httpFilter.Match(u.Hostname()) {
+		return fmt.Errorf("host %q not allowed", u.Hostname())
+	}
+	return nil
+}
+
+func (n *Network) httpGet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var address string
+	var headers *starlark.Dict
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "url", &address, "headers?", &headers); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parsing URL failed: %w", b.Name(), err)
+	}
+	if err := n.checkURL(u); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if headers != nil {
+		for _, item := range headers.Items() {
+			k, kok := starlark.AsString(item[0])
+			v, vok := starlark.AsString(item[1])
+			if !kok || !vok {
+				return nil, fmt.Errorf("%s: headers must be strings", b.Name())
+			}
+			req.Header.Set(k, v)
+		}
+	}
+
+	if err := n.take(); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading body failed: %w", b.Name(), err)
+	}
+
+	respHeaders := starlark.NewDict(len(resp.Header))
+	for k, v := range resp.Header {
+		if err := respHeaders.SetKey(starlark.String(strings.ToLower(k)), starlark.String(strings.Join(v, ", "))); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"status_code": starlark.MakeInt(resp.StatusCode),
+		"headers":     respHeaders,
+		"body":        starlark.String(body),
+	}), nil
+}
+
+func (n *Network) dnsLookup(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var host string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "host", &host); err != nil {
+		return nil, err
+	}
+	if !n.dnsFilter.Match(host) {
+		return nil, fmt.Errorf("%s: domain %q not allowed", b.Name(), host)
+	}
+
+	if err := n.take(); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(n.NetworkTimeout))
+	defer cancel()
+	addrs, err := n.lookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	result := make([]starlark.Value, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, starlark.String(addr))
+	}
+	return starlark.NewList(result), nil
+}
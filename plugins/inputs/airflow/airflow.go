@@ -0,0 +1,418 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package airflow
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Final states of task instances counted per task
+var taskStates = []string{"success", "failed", "up_for_retry", "upstream_failed", "skipped"}
+
+type Airflow struct {
+	URLs        []string        `toml:"urls"`
+	Collect     []string        `toml:"collect"`
+	DagInclude  []string        `toml:"dag_include"`
+	DagExclude  []string        `toml:"dag_exclude"`
+	TaskInclude []string        `toml:"task_include"`
+	TaskExclude []string        `toml:"task_exclude"`
+	Lookback    config.Duration `toml:"lookback"`
+	Username    config.Secret   `toml:"username"`
+	Password    config.Secret   `toml:"password"`
+	Log         telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client     *http.Client
+	dagFilter  filter.Filter
+	taskFilter filter.Filter
+
+	// end of the time range of the last collection of finished DAG runs and
+	// task instances per URL
+	since map[windowKey]time.Time
+	now   func() time.Time
+	sync.Mutex
+}
+
+type windowKey struct {
+	url     string
+	collect string
+}
+
+func (*Airflow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Airflow) Init() error {
+	if len(a.URLs) == 0 {
+		a.URLs = []string{"http://localhost:8080"}
+	}
+	for i, u := range a.URLs {
+		a.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	if len(a.Collect) == 0 {
+		a.Collect = []string{"health", "pools", "dag_runs", "task_instances"}
+	}
+	for _, c := range a.Collect {
+		switch c {
+		case "health", "pools", "dags", "dag_runs", "task_instances":
+		default:
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+
+	var err error
+	if a.dagFilter, err = filter.NewIncludeExcludeFilter(a.DagInclude, a.DagExclude); err != nil {
+		return fmt.Errorf("creating DAG filter failed: %w", err)
+	}
+	if a.taskFilter, err = filter.NewIncludeExcludeFilter(a.TaskInclude, a.TaskExclude); err != nil {
+		return fmt.Errorf("creating task filter failed: %w", err)
+	}
+
+	client, err := a.HTTPClientConfig.CreateClient(context.Background(), a.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	a.client = client
+
+	if a.now == nil {
+		a.now = time.Now
+	}
+	a.since = make(map[windowKey]time.Time, 2*len(a.URLs))
+	start := a.now().Add(-time.Duration(a.Lookback))
+	for _, u := range a.URLs {
+		a.since[windowKey{u, "dag_runs"}] = start
+		a.since[windowKey{u, "task_instances"}] = start
+	}
+
+	return nil
+}
+
+func (a *Airflow) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range a.URLs {
+		wg.Add(1)
+		go func(baseURL string) {
+			defer wg.Done()
+			a.gatherServer(acc, baseURL)
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (a *Airflow) Stop() {
+	if a.client != nil {
+		a.client.CloseIdleConnections()
+	}
+}
+
+func (a *Airflow) gatherServer(acc telegraf.Accumulator, baseURL string) {
+	now := a.now()
+	for _, c := range a.Collect {
+		// Finished DAG runs and task instances are collected for the time
+		// range since the last successful collection
+		key := windowKey{baseURL, c}
+		a.Lock()
+		since := a.since[key]
+		a.Unlock()
+
+		var err error
+		switch c {
+		case "health":
+			err = a.gatherHealth(acc, baseURL, now)
+		case "pools":
+			err = a.gatherPools(acc, baseURL, now)
+		case "dags":
+			err = a.gatherDAGs(acc, baseURL, now)
+		case "dag_runs":
+			err = a.gatherDagRuns(acc, baseURL, since, now)
+		case "task_instances":
+			err = a.gatherTaskInstances(acc, baseURL, since, now)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("[url=%s]: collecting %s failed: %w", baseURL, c, err))
+			continue
+		}
+
+		if c == "dag_runs" || c == "task_instances" {
+			a.Lock()
+			a.since[key] = now.Add(time.Microsecond)
+			a.Unlock()
+		}
+	}
+}
+
+func (a *Airflow) gatherHealth(acc telegraf.Accumulator, baseURL string, now time.Time) error {
+	var status healthStatus
+	if err := a.request(http.MethodGet, baseURL+"/api/v1/health", nil, &status); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"metadatabase_healthy": status.Metadatabase.Status == "healthy",
+		"scheduler_healthy":    status.Scheduler.Status == "healthy",
+	}
+	if hb := status.Scheduler.LatestSchedulerHeartbeat; hb != nil {
+		fields["scheduler_heartbeat_lag"] = now.Sub(*hb).Seconds()
+	}
+	if status.Triggerer != nil && status.Triggerer.Status != "" {
+		fields["triggerer_healthy"] = status.Triggerer.Status == "healthy"
+		if hb := status.Triggerer.LatestTriggererHeartbeat; hb != nil {
+			fields["triggerer_heartbeat_lag"] = now.Sub(*hb).Seconds()
+		}
+	}
+	acc.AddFields("airflow_health", fields, map[string]string{"url": baseURL}, now)
+
+	return nil
+}
+
+func (a *Airflow) gatherPools(acc telegraf.Accumulator, baseURL string, now time.Time) error {
+	for offset := 0; ; offset += pageLimit {
+		var pools poolCollection
+		address := fmt.Sprintf("%s/api/v1/pools?limit=%d&offset=%d", baseURL, pageLimit, offset)
+		if err := a.request(http.MethodGet, address, nil, &pools); err != nil {
+			return err
+		}
+
+		for _, p := range pools.Pools {
+			tags := map[string]string{
+				"url":  baseURL,
+				"pool": p.Name,
+			}
+			fields := map[string]interface{}{
+				"slots":           p.Slots,
+				"occupied_slots":  p.OccupiedSlots,
+				"running_slots":   p.RunningSlots,
+				"queued_slots":    p.QueuedSlots,
+				"scheduled_slots": p.ScheduledSlots,
+				"deferred_slots":  p.DeferredSlots,
+				"open_slots":      p.OpenSlots,
+			}
+			// The default pool reports -1 slots if unlimited
+			if p.Slots > 0 {
+				fields["utilization"] = float64(p.OccupiedSlots) / float64(p.Slots) * 100
+			}
+			acc.AddFields("airflow_pool", fields, tags, now)
+		}
+
+		if len(pools.Pools) == 0 || offset+len(pools.Pools) >= pools.TotalEntries {
+			return nil
+		}
+	}
+}
+
+func (a *Airflow) gatherDAGs(acc telegraf.Accumulator, baseURL string, now time.Time) error {
+	for offset := 0; ; offset += pageLimit {
+		var dags dagCollection
+		address := fmt.Sprintf("%s/api/v1/dags?limit=%d&offset=%d", baseURL, pageLimit, offset)
+		if err := a.request(http.MethodGet, address, nil, &dags); err != nil {
+			return err
+		}
+
+		for _, d := range dags.DAGs {
+			if !a.dagFilter.Match(d.DagID) {
+				continue
+			}
+			tags := map[string]string{
+				"url":    baseURL,
+				"dag_id": d.DagID,
+			}
+			fields := map[string]interface{}{
+				"is_paused": d.IsPaused,
+				"is_active": d.IsActive,
+			}
+			acc.AddFields("airflow_dag", fields, tags, now)
+		}
+
+		if len(dags.DAGs) == 0 || offset+len(dags.DAGs) >= dags.TotalEntries {
+			return nil
+		}
+	}
+}
+
+func (a *Airflow) gatherDagRuns(acc telegraf.Accumulator, baseURL string, since, until time.Time) error {
+	address := baseURL + "/api/v1/dags/~/dagRuns/list"
+	for offset := 0; ; offset += pageLimit {
+		var runs dagRunCollection
+		body := &listRequest{
+			PageOffset: offset,
+			PageLimit:  pageLimit,
+			EndDateGte: since.UTC().Format(time.RFC3339Nano),
+			EndDateLte: until.UTC().Format(time.RFC3339Nano),
+		}
+		if err := a.request(http.MethodPost, address, body, &runs); err != nil {
+			return err
+		}
+
+		for _, r := range runs.DagRuns {
+			if r.EndDate == nil || !a.dagFilter.Match(r.DagID) {
+				continue
+			}
+			tags := map[string]string{
+				"url":      baseURL,
+				"dag_id":   r.DagID,
+				"state":    r.State,
+				"run_type": r.RunType,
+			}
+			fields := map[string]interface{}{
+				"run_id": r.DagRunID,
+			}
+			if r.StartDate != nil {
+				fields["duration"] = r.EndDate.Sub(*r.StartDate).Seconds()
+			}
+			acc.AddFields("airflow_dag_run", fields, tags, *r.EndDate)
+		}
+
+		if len(runs.DagRuns) == 0 || offset+len(runs.DagRuns) >= runs.TotalEntries {
+			return nil
+		}
+	}
+}
+
+func (a *Airflow) gatherTaskInstances(acc telegraf.Accumulator, baseURL string, since, until time.Time) error {
+	type taskKey struct {
+		dagID  string
+		taskID string
+	}
+	type taskStats struct {
+		states      map[string]int64
+		durationMax float64
+	}
+
+	stats := make(map[taskKey]*taskStats)
+	var keys []taskKey
+
+	address := baseURL + "/api/v1/dags/~/dagRuns/~/taskInstances/list"
+	for offset := 0; ; offset += pageLimit {
+		var tasks taskInstanceCollection
+		body := &listRequest{
+			PageOffset: offset,
+			PageLimit:  pageLimit,
+			EndDateGte: since.UTC().Format(time.RFC3339Nano),
+			EndDateLte: until.UTC().Format(time.RFC3339Nano),
+			State:      taskStates,
+		}
+		if err := a.request(http.MethodPost, address, body, &tasks); err != nil {
+			return err
+		}
+
+		for _, t := range tasks.TaskInstances {
+			if !a.dagFilter.Match(t.DagID) || !a.taskFilter.Match(t.TaskID) {
+				continue
+			}
+			key := taskKey{t.DagID, t.TaskID}
+			s, found := stats[key]
+			if !found {
+				s = &taskStats{states: make(map[string]int64, len(taskStates))}
+				stats[key] = s
+				keys = append(keys, key)
+			}
+			s.states[t.State]++
+			if t.Duration != nil {
+				s.durationMax = max(s.durationMax, *t.Duration)
+			}
+		}
+
+		if len(tasks.TaskInstances) == 0 || offset+len(tasks.TaskInstances) >= tasks.TotalEntries {
+			break
+		}
+	}
+
+	for _, key := range keys {
+		s := stats[key]
+		tags := map[string]string{
+			"url":     baseURL,
+			"dag_id":  key.dagID,
+			"task_id": key.taskID,
+		}
+		fields := make(map[string]interface{}, len(taskStates)+1)
+		for _, state := range taskStates {
+			fields[state] = s.states[state]
+		}
+		fields["duration_max"] = s.durationMax
+		acc.AddFields("airflow_task", fields, tags, until)
+	}
+
+	return nil
+}
+
+func (a *Airflow) request(method, address string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request failed: %w", err)
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, address, reader)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if !a.Username.Empty() || !a.Password.Empty() {
+		username, err := a.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+		password, err := a.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s returned HTTP status %s: %q", address, resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %s failed: %w", address, err)
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("airflow", func() telegraf.Input {
+		return &Airflow{
+			Lookback: config.Duration(5 * time.Minute),
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
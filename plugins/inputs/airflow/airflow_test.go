@@ -0,0 +1,373 @@
+package airflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var now = time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC)
+
+type server struct {
+	*httptest.Server
+
+	sync.Mutex
+	requests map[string][]listRequest
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+
+	s := &server{requests: make(map[string][]listRequest)}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); ok && (user != "admin" || pass != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var filename string
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/health":
+			filename = "health.json"
+		case "GET /api/v1/pools":
+			filename = "pools.json"
+		case "GET /api/v1/dags":
+			filename = "dags.json"
+		case "POST /api/v1/dags/~/dagRuns/list":
+			filename = "dag_runs.json"
+		case "POST /api/v1/dags/~/dagRuns/~/taskInstances/list":
+			filename = "task_instances.json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var body listRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Error(err)
+				return
+			}
+			s.Lock()
+			s.requests[r.URL.Path] = append(s.requests[r.URL.Path], body)
+			s.Unlock()
+		}
+
+		buf, err := os.ReadFile(filepath.Join("testdata", filename))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Airflow{Collect: []string{"health", "variables"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' value "variables"`)
+}
+
+func TestGather(t *testing.T) {
+	srv := newServer(t)
+
+	plugin := &Airflow{
+		URLs:     []string{srv.URL + "/"},
+		Collect:  []string{"health", "pools", "dags", "dag_runs", "task_instances"},
+		Lookback: config.Duration(5 * time.Minute),
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+		now:      func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"airflow_health",
+			map[string]string{"url": srv.URL},
+			map[string]interface{}{
+				"metadatabase_healthy":    true,
+				"scheduler_healthy":       true,
+				"scheduler_heartbeat_lag": float64(2),
+				"triggerer_healthy":       false,
+				"triggerer_heartbeat_lag": float64(90),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_pool",
+			map[string]string{"url": srv.URL, "pool": "default_pool"},
+			map[string]interface{}{
+				"slots":           int64(128),
+				"occupied_slots":  int64(32),
+				"running_slots":   int64(24),
+				"queued_slots":    int64(6),
+				"scheduled_slots": int64(2),
+				"deferred_slots":  int64(0),
+				"open_slots":      int64(96),
+				"utilization":     float64(25),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_pool",
+			map[string]string{"url": srv.URL, "pool": "unlimited"},
+			map[string]interface{}{
+				"slots":           int64(-1),
+				"occupied_slots":  int64(3),
+				"running_slots":   int64(3),
+				"queued_slots":    int64(0),
+				"scheduled_slots": int64(0),
+				"deferred_slots":  int64(0),
+				"open_slots":      int64(-1),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_dag",
+			map[string]string{"url": srv.URL, "dag_id": "etl_daily"},
+			map[string]interface{}{"is_paused": false, "is_active": true},
+			now,
+		),
+		metric.New(
+			"airflow_dag",
+			map[string]string{"url": srv.URL, "dag_id": "report_weekly"},
+			map[string]interface{}{"is_paused": true, "is_active": true},
+			now,
+		),
+		metric.New(
+			"airflow_dag",
+			map[string]string{"url": srv.URL, "dag_id": "tmp_debug"},
+			map[string]interface{}{"is_paused": false, "is_active": false},
+			now,
+		),
+		metric.New(
+			"airflow_dag_run",
+			map[string]string{
+				"url":      srv.URL,
+				"dag_id":   "etl_daily",
+				"state":    "success",
+				"run_type": "scheduled",
+			},
+			map[string]interface{}{
+				"run_id":   "scheduled__2024-04-30T00:00:00+00:00",
+				"duration": float64(150),
+			},
+			time.Date(2024, 5, 1, 12, 2, 30, 0, time.UTC),
+		),
+		metric.New(
+			"airflow_dag_run",
+			map[string]string{
+				"url":      srv.URL,
+				"dag_id":   "report_weekly",
+				"state":    "failed",
+				"run_type": "manual",
+			},
+			map[string]interface{}{
+				"run_id":   "manual__2024-05-01T12:01:00+00:00",
+				"duration": float64(120.5),
+			},
+			time.Date(2024, 5, 1, 12, 3, 0, 500000000, time.UTC),
+		),
+		metric.New(
+			"airflow_dag_run",
+			map[string]string{
+				"url":      srv.URL,
+				"dag_id":   "tmp_debug",
+				"state":    "success",
+				"run_type": "manual",
+			},
+			map[string]interface{}{
+				"run_id":   "manual__2024-05-01T12:02:00+00:00",
+				"duration": float64(10),
+			},
+			time.Date(2024, 5, 1, 12, 2, 10, 0, time.UTC),
+		),
+		metric.New(
+			"airflow_task",
+			map[string]string{"url": srv.URL, "dag_id": "etl_daily", "task_id": "extract"},
+			map[string]interface{}{
+				"success":         int64(1),
+				"failed":          int64(0),
+				"up_for_retry":    int64(0),
+				"upstream_failed": int64(0),
+				"skipped":         int64(0),
+				"duration_max":    float64(42.5),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_task",
+			map[string]string{"url": srv.URL, "dag_id": "etl_daily", "task_id": "transform"},
+			map[string]interface{}{
+				"success":         int64(1),
+				"failed":          int64(0),
+				"up_for_retry":    int64(1),
+				"upstream_failed": int64(0),
+				"skipped":         int64(0),
+				"duration_max":    float64(61.25),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_task",
+			map[string]string{"url": srv.URL, "dag_id": "report_weekly", "task_id": "render"},
+			map[string]interface{}{
+				"success":         int64(0),
+				"failed":          int64(1),
+				"up_for_retry":    int64(0),
+				"upstream_failed": int64(0),
+				"skipped":         int64(0),
+				"duration_max":    float64(5),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_task",
+			map[string]string{"url": srv.URL, "dag_id": "report_weekly", "task_id": "send"},
+			map[string]interface{}{
+				"success":         int64(0),
+				"failed":          int64(0),
+				"up_for_retry":    int64(0),
+				"upstream_failed": int64(1),
+				"skipped":         int64(0),
+				"duration_max":    float64(0),
+			},
+			now,
+		),
+		metric.New(
+			"airflow_task",
+			map[string]string{"url": srv.URL, "dag_id": "tmp_debug", "task_id": "debug"},
+			map[string]interface{}{
+				"success":         int64(1),
+				"failed":          int64(0),
+				"up_for_retry":    int64(0),
+				"upstream_failed": int64(0),
+				"skipped":         int64(0),
+				"duration_max":    float64(1),
+			},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestGatherTimeRange(t *testing.T) {
+	srv := newServer(t)
+
+	current := now
+	plugin := &Airflow{
+		URLs:     []string{srv.URL},
+		Collect:  []string{"dag_runs", "task_instances"},
+		Lookback: config.Duration(5 * time.Minute),
+		Log:      testutil.Logger{},
+		now:      func() time.Time { return current },
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	current = now.Add(time.Minute)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []listRequest{
+		{
+			PageLimit:  pageLimit,
+			EndDateGte: "2024-05-01T12:00:00Z",
+			EndDateLte: "2024-05-01T12:05:00Z",
+		},
+		{
+			PageLimit:  pageLimit,
+			EndDateGte: "2024-05-01T12:05:00.000001Z",
+			EndDateLte: "2024-05-01T12:06:00Z",
+		},
+	}
+	require.Equal(t, expected, srv.requests["/api/v1/dags/~/dagRuns/list"])
+
+	for i := range expected {
+		expected[i].State = taskStates
+	}
+	require.Equal(t, expected, srv.requests["/api/v1/dags/~/dagRuns/~/taskInstances/list"])
+}
+
+func TestGatherFilter(t *testing.T) {
+	srv := newServer(t)
+
+	plugin := &Airflow{
+		URLs:        []string{srv.URL},
+		Collect:     []string{"dags", "dag_runs", "task_instances"},
+		DagExclude:  []string{"tmp_*"},
+		TaskInclude: []string{"transform", "render"},
+		Log:         testutil.Logger{},
+		now:         func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	counts := make(map[string]int)
+	for _, m := range acc.GetTelegrafMetrics() {
+		if dag, found := m.GetTag("dag_id"); found {
+			require.NotEqual(t, "tmp_debug", dag)
+		}
+		if task, found := m.GetTag("task_id"); found {
+			require.Contains(t, []string{"transform", "render"}, task)
+		}
+		counts[m.Name()]++
+	}
+	require.Equal(t, map[string]int{"airflow_dag": 2, "airflow_dag_run": 2, "airflow_task": 2}, counts)
+}
+
+func TestGatherUnauthorized(t *testing.T) {
+	srv := newServer(t)
+
+	plugin := &Airflow{
+		URLs:     []string{srv.URL},
+		Collect:  []string{"health", "dag_runs"},
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("wrong")),
+		Log:      testutil.Logger{},
+		now:      func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "401 Unauthorized")
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// The time range must not advance on failed collections
+	require.Equal(t, now.Add(-time.Duration(plugin.Lookback)), plugin.since[windowKey{srv.URL, "dag_runs"}])
+}
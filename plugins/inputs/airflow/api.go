@@ -0,0 +1,83 @@
+package airflow
+
+import "time"
+
+// Maximum number of items requested per page, Airflow limits the page size
+// to 'maximum_page_limit' (default 100)
+const pageLimit = 100
+
+// healthStatus is the response of the '/api/v1/health' endpoint
+type healthStatus struct {
+	Metadatabase struct {
+		Status string `json:"status"`
+	} `json:"metadatabase"`
+	Scheduler struct {
+		Status                   string     `json:"status"`
+		LatestSchedulerHeartbeat *time.Time `json:"latest_scheduler_heartbeat"`
+	} `json:"scheduler"`
+	Triggerer *struct {
+		Status                   string     `json:"status"`
+		LatestTriggererHeartbeat *time.Time `json:"latest_triggerer_heartbeat"`
+	} `json:"triggerer"`
+}
+
+// poolCollection is the response of the '/api/v1/pools' endpoint
+type poolCollection struct {
+	Pools []struct {
+		Name           string `json:"name"`
+		Slots          int64  `json:"slots"`
+		OccupiedSlots  int64  `json:"occupied_slots"`
+		RunningSlots   int64  `json:"running_slots"`
+		QueuedSlots    int64  `json:"queued_slots"`
+		ScheduledSlots int64  `json:"scheduled_slots"`
+		DeferredSlots  int64  `json:"deferred_slots"`
+		OpenSlots      int64  `json:"open_slots"`
+	} `json:"pools"`
+	TotalEntries int `json:"total_entries"`
+}
+
+// dagCollection is the response of the '/api/v1/dags' endpoint
+type dagCollection struct {
+	DAGs []struct {
+		DagID    string `json:"dag_id"`
+		IsPaused bool   `json:"is_paused"`
+		IsActive bool   `json:"is_active"`
+	} `json:"dags"`
+	TotalEntries int `json:"total_entries"`
+}
+
+// listRequest is the body of the batch list endpoints for DAG runs and task
+// instances
+type listRequest struct {
+	PageOffset int      `json:"page_offset"`
+	PageLimit  int      `json:"page_limit"`
+	EndDateGte string   `json:"end_date_gte"`
+	EndDateLte string   `json:"end_date_lte"`
+	State      []string `json:"state,omitempty"`
+}
+
+// dagRunCollection is the response of the '/api/v1/dags/~/dagRuns/list'
+// endpoint
+type dagRunCollection struct {
+	DagRuns []struct {
+		DagID     string     `json:"dag_id"`
+		DagRunID  string     `json:"dag_run_id"`
+		RunType   string     `json:"run_type"`
+		State     string     `json:"state"`
+		StartDate *time.Time `json:"start_date"`
+		EndDate   *time.Time `json:"end_date"`
+	} `json:"dag_runs"`
+	TotalEntries int `json:"total_entries"`
+}
+
+// taskInstanceCollection is the response of the
+// '/api/v1/dags/~/dagRuns/~/taskInstances/list' endpoint
+type taskInstanceCollection struct {
+	TaskInstances []struct {
+		DagID    string   `json:"dag_id"`
+		TaskID   string   `json:"task_id"`
+		State    string   `json:"state"`
+		Duration *float64 `json:"duration"`
+	} `json:"task_instances"`
+	TotalEntries int `json:"total_entries"`
+}
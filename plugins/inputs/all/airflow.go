@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.airflow
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/airflow" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.android
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/android" // register plugin
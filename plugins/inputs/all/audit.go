@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.audit
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/audit" // register plugin
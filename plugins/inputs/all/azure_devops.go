@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.azure_devops
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/azure_devops" // register plugin
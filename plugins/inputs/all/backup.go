@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.backup
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/backup" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.env_sensors
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/env_sensors" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.frr
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/frr" // register plugin
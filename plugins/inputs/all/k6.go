@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.k6
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/k6" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.keepalived
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/keepalived" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.linuxptp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/linuxptp" // register plugin
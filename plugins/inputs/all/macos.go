@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.macos
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/macos" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.osquery
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/osquery" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.pprof
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/pprof" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.remotefile
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/remotefile" // register plugin
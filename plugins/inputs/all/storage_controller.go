@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.storage_controller
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/storage_controller" // register plugin
@@ -0,0 +1,185 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package android
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Signal values reported by the cell info for cells that are registered,
+// in the order of the resulting fields
+var cellFields = []string{"dbm", "asu", "level", "rsrp", "rsrq", "rssi", "rssnr", "ss_rsrp", "ss_rsrq", "ss_sinr"}
+
+type Android struct {
+	Collect      []string        `toml:"collect"`
+	TermuxBinDir string          `toml:"termux_bin_dir"`
+	StoragePaths []string        `toml:"storage_paths"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+
+	collect map[string]bool
+}
+
+// batteryStatus is the output of termux-battery-status, optional values are
+// pointers to distinguish them from zero values
+type batteryStatus struct {
+	Health        string   `json:"health"`
+	Plugged       string   `json:"plugged"`
+	Status        string   `json:"status"`
+	Percentage    *int64   `json:"percentage"`
+	Temperature   *float64 `json:"temperature"`
+	Voltage       *int64   `json:"voltage"`
+	Current       *int64   `json:"current"`
+	ChargeCounter *int64   `json:"charge_counter"`
+	Cycle         *int64   `json:"cycle"`
+}
+
+func (*Android) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Android) init() error {
+	if len(a.Collect) == 0 {
+		a.Collect = []string{"battery", "cellular", "storage"}
+	}
+	a.collect = make(map[string]bool, len(a.Collect))
+	for _, c := range a.Collect {
+		switch c {
+		case "battery", "cellular", "storage":
+			a.collect[c] = true
+		default:
+			return fmt.Errorf("unknown collect option %q", c)
+		}
+	}
+
+	if a.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return nil
+}
+
+// parseBattery adds the battery metric from the JSON output of
+// termux-battery-status
+func parseBattery(acc telegraf.Accumulator, data []byte, ts time.Time) error {
+	var status batteryStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("decoding battery status failed: %w", err)
+	}
+
+	fields := make(map[string]interface{}, 9)
+	if status.Health != "" {
+		fields["health"] = status.Health
+	}
+	if status.Plugged != "" {
+		fields["plugged"] = status.Plugged
+	}
+	if status.Status != "" {
+		fields["status"] = status.Status
+	}
+	if status.Percentage != nil {
+		fields["percentage"] = *status.Percentage
+	}
+	if status.Temperature != nil {
+		fields["temperature_celsius"] = *status.Temperature
+	}
+	if status.Voltage != nil {
+		fields["voltage_mv"] = *status.Voltage
+	}
+	if status.Current != nil {
+		// Android reports the current in microampere
+		fields["current_ma"] = float64(*status.Current) / 1000
+	}
+	if status.ChargeCounter != nil {
+		fields["charge_counter_uah"] = *status.ChargeCounter
+	}
+	if status.Cycle != nil {
+		fields["cycle_count"] = *status.Cycle
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	acc.AddFields("android_battery", fields, nil, ts)
+	return nil
+}
+
+// parseCellInfo adds the signal metrics of the registered cells from the
+// JSON output of termux-telephony-cellinfo
+func parseCellInfo(acc telegraf.Accumulator, data []byte, ts time.Time) error {
+	// The command prints nothing if no cell information is available
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var cells []map[string]interface{}
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return fmt.Errorf("decoding cell info failed: %w", err)
+	}
+
+	var index int
+	for _, cell := range cells {
+		if registered, _ := cell["registered"].(bool); !registered {
+			continue
+		}
+		cellType, _ := cell["type"].(string)
+
+		fields := make(map[string]interface{}, len(cellFields))
+		for _, key := range cellFields {
+			v, ok := cell[key].(float64)
+			// Android reports unavailable values as the maximum integer
+			if !ok || v == math.MaxInt32 {
+				continue
+			}
+			fields[key] = int64(v)
+		}
+		if len(fields) > 0 {
+			tags := map[string]string{
+				"cell": strconv.Itoa(index),
+				"type": cellType,
+			}
+			acc.AddGauge("android_cellular", fields, tags, ts)
+		}
+		index++
+	}
+	return nil
+}
+
+// storageFields returns the fields of the storage metric for the given
+// file system statistics
+func storageFields(blockSize, blocks, free, available uint64) map[string]interface{} {
+	total := blocks * blockSize
+	// The used space includes the blocks reserved for the system
+	used := (blocks - free) * blockSize
+	fields := map[string]interface{}{
+		"total": total,
+		"free":  available * blockSize,
+		"used":  used,
+	}
+	if usable := used + available*blockSize; usable > 0 {
+		fields["used_percent"] = float64(used) / float64(usable) * 100
+	}
+	return fields
+}
+
+func init() {
+	inputs.Add("android", func() telegraf.Input {
+		return &Android{
+			TermuxBinDir: "/data/data/com.termux/files/usr/bin",
+			StoragePaths: []string{"/data", "/storage/emulated/0"},
+			Timeout:      config.Duration(5 * time.Second),
+		}
+	})
+}
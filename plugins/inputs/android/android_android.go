@@ -0,0 +1,65 @@
+//go:build android
+
+package android
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+func (a *Android) Init() error {
+	return a.init()
+}
+
+func (a *Android) Gather(acc telegraf.Accumulator) error {
+	if a.collect["battery"] {
+		ts := time.Now()
+		out, err := a.run("termux-battery-status")
+		if err != nil {
+			acc.AddError(err)
+		} else if err := parseBattery(acc, out, ts); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if a.collect["cellular"] {
+		ts := time.Now()
+		out, err := a.run("termux-telephony-cellinfo")
+		if err != nil {
+			acc.AddError(err)
+		} else if err := parseCellInfo(acc, out, ts); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if a.collect["storage"] {
+		ts := time.Now()
+		for _, path := range a.StoragePaths {
+			var stat syscall.Statfs_t
+			if err := syscall.Statfs(path, &stat); err != nil {
+				acc.AddError(fmt.Errorf("getting file system statistics of %q failed: %w", path, err))
+				continue
+			}
+			fields := storageFields(uint64(stat.Bsize), stat.Blocks, stat.Bfree, stat.Bavail)
+			acc.AddGauge("android_storage", fields, map[string]string{"path": path}, ts)
+		}
+	}
+
+	return nil
+}
+
+// run executes the given Termux:API command and returns its output
+func (a *Android) run(command string) ([]byte, error) {
+	cmd := exec.Command(filepath.Join(a.TermuxBinDir, command))
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(a.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", command, err)
+	}
+	return out, nil
+}
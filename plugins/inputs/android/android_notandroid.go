@@ -0,0 +1,17 @@
+//go:build !android
+
+package android
+
+import "github.com/influxdata/telegraf"
+
+func (a *Android) Init() error {
+	if err := a.init(); err != nil {
+		return err
+	}
+	a.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Android) Gather(telegraf.Accumulator) error {
+	return nil
+}
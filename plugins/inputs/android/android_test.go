@@ -0,0 +1,123 @@
+package android
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Android{
+		Collect: []string{"battery", "wifi"},
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.init(), `unknown collect option "wifi"`)
+
+	plugin = &Android{Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.init(), "timeout must be positive")
+}
+
+func TestParseBattery(t *testing.T) {
+	data, err := os.ReadFile("testdata/battery.json")
+	require.NoError(t, err)
+
+	ts := time.Unix(1712136097, 0)
+	expected := []telegraf.Metric{
+		metric.New(
+			"android_battery",
+			map[string]string{},
+			map[string]interface{}{
+				"health":              "GOOD",
+				"plugged":             "UNPLUGGED",
+				"status":              "DISCHARGING",
+				"percentage":          int64(77),
+				"temperature_celsius": 28.5,
+				"voltage_mv":          int64(3984),
+				"current_ma":          -355.468,
+				"charge_counter_uah":  int64(3015328),
+				"cycle_count":         int64(143),
+			},
+			ts,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, parseBattery(&acc, data, ts))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	require.ErrorContains(t, parseBattery(&acc, []byte("Termux:API is not installed"), ts), "decoding battery status failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestParseCellInfo(t *testing.T) {
+	data, err := os.ReadFile("testdata/cellinfo.json")
+	require.NoError(t, err)
+
+	ts := time.Unix(1712136097, 0)
+	expected := []telegraf.Metric{
+		metric.New(
+			"android_cellular",
+			map[string]string{"cell": "0", "type": "lte"},
+			map[string]interface{}{
+				"dbm":   int64(-104),
+				"asu":   int64(36),
+				"level": int64(2),
+				"rsrp":  int64(-104),
+				"rsrq":  int64(-11),
+				"rssi":  int64(-69),
+			},
+			ts,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"android_cellular",
+			map[string]string{"cell": "1", "type": "nr"},
+			map[string]interface{}{
+				"dbm":     int64(-95),
+				"asu":     int64(45),
+				"level":   int64(3),
+				"ss_rsrp": int64(-95),
+				"ss_rsrq": int64(-10),
+				"ss_sinr": int64(12),
+			},
+			ts,
+			telegraf.Gauge,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, parseCellInfo(&acc, data, ts))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// No output means no cell information is available
+	acc.ClearMetrics()
+	require.NoError(t, parseCellInfo(&acc, []byte("\n"), ts))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestStorageFields(t *testing.T) {
+	// 1000 blocks of 4096 bytes with 300 free blocks of which 200 are
+	// available to unprivileged users
+	expected := map[string]interface{}{
+		"total":        uint64(4096000),
+		"free":         uint64(819200),
+		"used":         uint64(2867200),
+		"used_percent": 77.77777777777779,
+	}
+	require.Equal(t, expected, storageFields(4096, 1000, 300, 200))
+
+	require.Equal(t, map[string]interface{}{
+		"total": uint64(0),
+		"free":  uint64(0),
+		"used":  uint64(0),
+	}, storageFields(4096, 0, 0, 0))
+}
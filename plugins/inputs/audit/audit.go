@@ -0,0 +1,339 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package audit
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/user"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Exit codes of system calls failing due to missing permissions
+var accessDeniedExitCodes = []string{"-1", "-13"} // EPERM, EACCES
+
+const unsetID = "4294967295"
+
+type Audit struct {
+	Source       string          `toml:"source"`
+	CountTypes   []string        `toml:"count_types"`
+	Keys         []string        `toml:"keys"`
+	Events       []string        `toml:"events"`
+	EventTimeout config.Duration `toml:"event_timeout"`
+	RetryDelay   config.Duration `toml:"retry_delay"`
+	Log          telegraf.Logger `toml:"-"`
+
+	socketPath string
+	typeFilter filter.Filter
+	keyFilter  filter.Filter
+
+	acc       telegraf.Accumulator
+	source    source
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	assembler *assembler
+	counters  map[counterKey]uint64
+	users     map[string]string
+	sync.Mutex
+}
+
+type counterKey struct {
+	typ    string
+	key    string
+	result string
+}
+
+func (*Audit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Audit) Init() error {
+	switch a.Source {
+	case "", "netlink":
+		a.Source = "netlink"
+	default:
+		u, err := url.Parse(a.Source)
+		if err != nil {
+			return fmt.Errorf("parsing source failed: %w", err)
+		}
+		if u.Scheme != "unix" || u.Path == "" {
+			return fmt.Errorf("invalid source %q", a.Source)
+		}
+		a.socketPath = u.Path
+	}
+
+	for _, e := range a.Events {
+		switch e {
+		case "execve", "failed_access":
+		default:
+			return fmt.Errorf("invalid event %q", e)
+		}
+	}
+
+	if a.EventTimeout <= 0 {
+		return errors.New("event_timeout must be positive")
+	}
+
+	var err error
+	if a.typeFilter, err = filter.Compile(a.CountTypes); err != nil {
+		return fmt.Errorf("compiling type filter failed: %w", err)
+	}
+	if a.keyFilter, err = filter.Compile(a.Keys); err != nil {
+		return fmt.Errorf("compiling key filter failed: %w", err)
+	}
+
+	a.assembler = newAssembler(time.Duration(a.EventTimeout))
+	a.counters = make(map[counterKey]uint64)
+	a.users = make(map[string]string)
+
+	return nil
+}
+
+func (a *Audit) Start(acc telegraf.Accumulator) error {
+	a.acc = acc
+
+	src, err := a.open()
+	if err != nil {
+		return err
+	}
+	a.source = src
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.receive(ctx, src)
+	}()
+
+	return nil
+}
+
+func (a *Audit) Gather(acc telegraf.Accumulator) error {
+	a.Lock()
+	defer a.Unlock()
+
+	a.processEvents(a.assembler.expire(time.Now()))
+
+	for k, count := range a.counters {
+		tags := map[string]string{"type": k.typ}
+		if k.key != "" {
+			tags["key"] = k.key
+		}
+		if k.result != "" {
+			tags["result"] = k.result
+		}
+		acc.AddCounter("audit", map[string]interface{}{"count": count}, tags)
+	}
+
+	return nil
+}
+
+func (a *Audit) Stop() {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.Lock()
+	if a.source != nil {
+		a.source.Close()
+	}
+	a.Unlock()
+	a.wg.Wait()
+}
+
+func (a *Audit) open() (source, error) {
+	if a.socketPath == "" {
+		return newNetlinkSource()
+	}
+	return newUnixSource(a.socketPath)
+}
+
+// receive processes the records of the source and reopens the source on
+// errors until the plugin is stopped
+func (a *Audit) receive(ctx context.Context, src source) {
+	for {
+		err := a.process(src)
+		src.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		a.Log.Errorf("Reading audit records failed: %v", err)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(a.RetryDelay)):
+			}
+
+			if src, err = a.open(); err != nil {
+				a.Log.Errorf("Reopening source failed: %v", err)
+				continue
+			}
+			break
+		}
+
+		a.Lock()
+		a.source = src
+		a.Unlock()
+		// Closing the source might have happened before storing it
+		if ctx.Err() != nil {
+			src.Close()
+			return
+		}
+	}
+}
+
+// process reads the records of the source until a fatal error occurs
+func (a *Audit) process(src source) error {
+	for {
+		r, err := src.read()
+		switch {
+		case errors.Is(err, errOverrun):
+			a.Log.Warn("Records lost, consider increasing the receive buffer size of the system")
+			continue
+		case errors.Is(err, errInvalidRecord):
+			a.acc.AddError(err)
+			continue
+		case err != nil:
+			return err
+		}
+
+		a.Lock()
+		now := time.Now()
+		a.processEvents(a.assembler.add(r, now))
+		a.processEvents(a.assembler.expire(now))
+		a.Unlock()
+	}
+}
+
+// processEvents updates the counters and emits the selected events, the
+// caller must hold the lock
+func (a *Audit) processEvents(events []*event) {
+	for _, e := range events {
+		key := e.key()
+		if a.keyFilter != nil && !a.keyFilter.Match(key) {
+			continue
+		}
+
+		typ := e.typeName()
+		if a.typeFilter == nil || a.typeFilter.Match(typ) {
+			a.counters[counterKey{typ: typ, key: key, result: e.result()}]++
+		}
+
+		for _, name := range a.Events {
+			switch name {
+			case "execve":
+				a.addExecve(e, key)
+			case "failed_access":
+				a.addFailedAccess(e, key)
+			}
+		}
+	}
+}
+
+func (a *Audit) addExecve(e *event, key string) {
+	if e.find(typeExecve) == nil {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"exe":          e.field("exe"),
+		"comm":         e.field("comm"),
+		"command_line": e.commandLine(),
+	}
+	if r := e.find(typeCWD); r != nil {
+		fields["cwd"] = r.fields["cwd"]
+	}
+	addIntegerFields(fields, e, "pid", "ppid")
+
+	a.acc.AddFields("audit_event", fields, a.eventTags(e, "execve", key), e.timestamp)
+}
+
+func (a *Audit) addFailedAccess(e *event, key string) {
+	r := e.find(typeSyscall)
+	if r == nil || r.fields["success"] != "no" || !slices.Contains(accessDeniedExitCodes, r.fields["exit"]) {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"exe":  e.field("exe"),
+		"comm": e.field("comm"),
+	}
+	if p := e.find(typePath); p != nil {
+		fields["path"] = p.fields["name"]
+	}
+	addIntegerFields(fields, e, "syscall", "exit", "pid")
+
+	a.acc.AddFields("audit_event", fields, a.eventTags(e, "failed_access", key), e.timestamp)
+}
+
+func (a *Audit) eventTags(e *event, name, key string) map[string]string {
+	auid := e.field("auid")
+	tags := map[string]string{
+		"event": name,
+		"auid":  auid,
+		"uid":   e.field("uid"),
+		"user":  a.userName(auid, e.field("AUID")),
+	}
+	if key != "" {
+		tags["key"] = key
+	}
+	return tags
+}
+
+// userName returns the login user of the event, preferring the name resolved
+// by auditd for enriched records over the local user database
+func (a *Audit) userName(auid, enriched string) string {
+	if auid == unsetID {
+		return "unset"
+	}
+	if enriched != "" {
+		return enriched
+	}
+
+	if name, found := a.users[auid]; found {
+		return name
+	}
+	name := auid
+	if u, err := user.LookupId(auid); err == nil {
+		name = u.Username
+	}
+	a.users[auid] = name
+	return name
+}
+
+func addIntegerFields(fields map[string]interface{}, e *event, names ...string) {
+	for _, name := range names {
+		if v, err := strconv.ParseInt(e.field(name), 10, 64); err == nil {
+			fields[name] = v
+		}
+	}
+}
+
+func init() {
+	inputs.Add("audit", func() telegraf.Input {
+		return &Audit{
+			Source:       "netlink",
+			Events:       []string{"execve", "failed_access"},
+			EventTimeout: config.Duration(2 * time.Second),
+			RetryDelay:   config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package audit
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Audit struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*Audit) SampleConfig() string { return sampleConfig }
+
+func (a *Audit) Init() error {
+	a.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Audit) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("audit", func() telegraf.Input {
+		return &Audit{}
+	})
+}
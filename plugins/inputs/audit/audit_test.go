@@ -0,0 +1,263 @@
+//go:build linux
+
+package audit
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Audit
+		expected string
+	}{
+		{
+			name:     "invalid source",
+			plugin:   &Audit{Source: "tcp://localhost:1234", EventTimeout: config.Duration(time.Second)},
+			expected: `invalid source "tcp://localhost:1234"`,
+		},
+		{
+			name:     "invalid event",
+			plugin:   &Audit{Events: []string{"foo"}, EventTimeout: config.Duration(time.Second)},
+			expected: `invalid event "foo"`,
+		},
+		{
+			name:     "invalid timeout",
+			plugin:   &Audit{},
+			expected: "event_timeout must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseRecord(t *testing.T) {
+	// Records received via netlink contain the message only
+	r, err := parseRecord(1309, "audit(1718352000.123:101): argc=2 a0=\"echo\" a1=68656C6C6F20776F726C64\x00")
+	require.NoError(t, err)
+	require.Equal(t, "EXECVE", r.typeName)
+	require.Equal(t, uint64(101), r.serial)
+	require.Equal(t, time.Unix(1718352000, 123*int64(time.Millisecond)), r.timestamp)
+	require.Equal(t, map[string]string{"argc": "2", "a0": "echo", "a1": "hello world"}, r.fields)
+
+	// System call arguments are not encoded
+	r, err = parseRecord(1300, "audit(1718352000.123:101): syscall=59 a0=55d0c2a1e4b0 comm=6D7920636F6D6D key=(null)")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"syscall": "59", "a0": "55d0c2a1e4b0", "comm": "my comm", "key": "(null)"}, r.fields)
+
+	r, err = parseRecord(1999, "audit(1718352000.123:101):")
+	require.NoError(t, err)
+	require.Equal(t, "UNKNOWN[1999]", r.typeName)
+	require.Empty(t, r.fields)
+
+	r, err = parseLine("node=host01 type=UNKNOWN[1999] msg=audit(1718352000.123:102): foo=bar")
+	require.NoError(t, err)
+	require.Equal(t, 1999, r.typ)
+	require.Equal(t, map[string]string{"foo": "bar"}, r.fields)
+
+	_, err = parseRecord(1300, "syscall=59")
+	require.ErrorContains(t, err, "missing record header")
+	_, err = parseLine("foo=bar msg=audit(1718352000.123:102): foo=bar")
+	require.ErrorContains(t, err, "missing record type")
+}
+
+func TestAssemblerTimeout(t *testing.T) {
+	a := newAssembler(2 * time.Second)
+	now := time.Now()
+
+	r, err := parseRecord(1300, "audit(1718352000.123:101): syscall=59 success=yes")
+	require.NoError(t, err)
+	require.Empty(t, a.add(r, now))
+	require.Empty(t, a.expire(now.Add(time.Second)))
+
+	// Standalone records complete immediately
+	r, err = parseRecord(1112, "audit(1718352000.200:102): pid=1 msg='op=login res=success'")
+	require.NoError(t, err)
+	events := a.add(r, now)
+	require.Len(t, events, 1)
+	require.Equal(t, "USER_LOGIN", events[0].typeName())
+	require.Equal(t, "success", events[0].result())
+
+	events = a.expire(now.Add(2 * time.Second))
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(101), events[0].serial)
+	require.Empty(t, a.pending)
+}
+
+func TestUnixSource(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "audisp.log"))
+	require.NoError(t, err)
+
+	addr := filepath.Join(t.TempDir(), "audispd_events")
+	listener, err := net.Listen("unix", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := conn.Write(data); err != nil {
+			return
+		}
+		// Keep the connection open until the plugin stops
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+
+	plugin := &Audit{
+		Source:       "unix://" + addr,
+		Events:       []string{"execve", "failed_access"},
+		EventTimeout: config.Duration(2 * time.Second),
+		RetryDelay:   config.Duration(time.Second),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	acc.Wait(2)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"audit_event",
+			map[string]string{
+				"event": "execve",
+				"key":   "exec",
+				"auid":  "1000",
+				"uid":   "1000",
+				"user":  "alice",
+			},
+			map[string]interface{}{
+				"exe":          "/usr/bin/ls",
+				"comm":         "ls",
+				"command_line": "ls -l /tmp/my dir",
+				"cwd":          "/home/alice",
+				"pid":          int64(1234),
+				"ppid":         int64(1200),
+			},
+			time.Unix(1718352000, 123*int64(time.Millisecond)),
+		),
+		metric.New(
+			"audit_event",
+			map[string]string{
+				"event": "failed_access",
+				"key":   "shadow",
+				"auid":  "4294967295",
+				"uid":   "998",
+				"user":  "unset",
+			},
+			map[string]interface{}{
+				"exe":     "/usr/bin/cat",
+				"comm":    "cat",
+				"path":    "/etc/shadow",
+				"syscall": int64(257),
+				"exit":    int64(-13),
+				"pid":     int64(1240),
+			},
+			time.Unix(1718352001, 456*int64(time.Millisecond)),
+		),
+		metric.New(
+			"audit",
+			map[string]string{"type": "USER_LOGIN", "result": "failed"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"audit",
+			map[string]string{"type": "SYSCALL", "key": "exec", "result": "success"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"audit",
+			map[string]string{"type": "SYSCALL", "key": "shadow", "result": "failed"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+
+	// Event metrics carry the timestamp of the audit event
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected[:2], actual[:2])
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestKeyAndTypeFilter(t *testing.T) {
+	plugin := &Audit{
+		CountTypes:   []string{"USER_*"},
+		Keys:         []string{"shadow"},
+		Events:       []string{"execve", "failed_access"},
+		EventTimeout: config.Duration(2 * time.Second),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+
+	data, err := os.ReadFile(filepath.Join("testdata", "audisp.log"))
+	require.NoError(t, err)
+	src := &lineSource{lines: strings.Split(strings.TrimSpace(string(data)), "\n")}
+	require.ErrorIs(t, plugin.process(src), errEndOfData)
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Only the failed access matches the key, events without a key are
+	// filtered and the failed access is not counted due to its type
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "audit_event", metrics[0].Name())
+	require.Equal(t, map[string]string{
+		"event": "failed_access",
+		"key":   "shadow",
+		"auid":  "4294967295",
+		"uid":   "998",
+		"user":  "unset",
+	}, metrics[0].Tags())
+}
+
+var errEndOfData = errors.New("end of data")
+
+// lineSource provides the records of the given lines in text format
+type lineSource struct {
+	lines []string
+}
+
+func (s *lineSource) read() (*record, error) {
+	if len(s.lines) == 0 {
+		return nil, errEndOfData
+	}
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+	return parseLine(line)
+}
+
+func (*lineSource) Close() error {
+	return nil
+}
@@ -0,0 +1,410 @@
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit record types as defined in linux/audit.h and libaudit.h
+const (
+	typeSyscall = 1300
+	typePath    = 1302
+	typeCWD     = 1307
+	typeExecve  = 1309
+	typeEOE     = 1320
+)
+
+var recordTypeNames = map[int]string{
+	1006: "LOGIN",
+	1100: "USER_AUTH",
+	1101: "USER_ACCT",
+	1102: "USER_MGMT",
+	1103: "CRED_ACQ",
+	1104: "CRED_DISP",
+	1105: "USER_START",
+	1106: "USER_END",
+	1107: "USER_AVC",
+	1108: "USER_CHAUTHTOK",
+	1109: "USER_ERR",
+	1110: "CRED_REFR",
+	1111: "USYS_CONFIG",
+	1112: "USER_LOGIN",
+	1113: "USER_LOGOUT",
+	1114: "ADD_USER",
+	1115: "DEL_USER",
+	1116: "ADD_GROUP",
+	1117: "DEL_GROUP",
+	1123: "USER_CMD",
+	1124: "USER_TTY",
+	1130: "SERVICE_START",
+	1131: "SERVICE_STOP",
+	1300: "SYSCALL",
+	1302: "PATH",
+	1303: "IPC",
+	1304: "SOCKETCALL",
+	1305: "CONFIG_CHANGE",
+	1306: "SOCKADDR",
+	1307: "CWD",
+	1309: "EXECVE",
+	1320: "EOE",
+	1325: "NETFILTER_CFG",
+	1326: "SECCOMP",
+	1327: "PROCTITLE",
+	1331: "FANOTIFY",
+	1334: "BPF",
+	1400: "AVC",
+	1701: "ANOM_ABEND",
+	1702: "ANOM_LINK",
+}
+
+var recordTypeNumbers = func() map[string]int {
+	m := make(map[string]int, len(recordTypeNames))
+	for k, v := range recordTypeNames {
+		m[v] = k
+	}
+	return m
+}()
+
+// Fields logged hex-encoded by the kernel if they contain special characters.
+// Quoted values of those fields are not encoded.
+var encodedFields = map[string]bool{
+	"comm":      true,
+	"cwd":       true,
+	"data":      true,
+	"dir":       true,
+	"exe":       true,
+	"key":       true,
+	"name":      true,
+	"new":       true,
+	"old":       true,
+	"path":      true,
+	"proctitle": true,
+	"watch":     true,
+}
+
+// record is a single audit record, multiple records with the same serial
+// form an event
+type record struct {
+	typ       int
+	typeName  string
+	timestamp time.Time
+	serial    uint64
+	fields    map[string]string
+}
+
+// parseRecord parses the message text of an audit record as received via
+// netlink, i.e. "audit(<seconds>.<milliseconds>:<serial>): <fields>".
+func parseRecord(typ int, msg string) (*record, error) {
+	msg = strings.TrimRight(msg, "\x00\n ")
+	// Enriched records separate the resolved fields by a group separator
+	msg = strings.ReplaceAll(msg, "\x1d", " ")
+	header, body, found := strings.Cut(msg, "): ")
+	if !found {
+		// Records without fields, e.g. end-of-event
+		header, found = strings.CutSuffix(msg, "):")
+		if !found {
+			return nil, errors.New("missing record header")
+		}
+	}
+	header, found = strings.CutPrefix(header, "audit(")
+	if !found {
+		return nil, errors.New("missing record header")
+	}
+
+	ts, serial, found := strings.Cut(header, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid record header %q", header)
+	}
+	sec, msec, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+	}
+	var ms int64
+	if msec != "" {
+		if ms, err = strconv.ParseInt(msec, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+		}
+	}
+	n, err := strconv.ParseUint(serial, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serial %q: %w", serial, err)
+	}
+
+	name, found := recordTypeNames[typ]
+	if !found {
+		name = fmt.Sprintf("UNKNOWN[%d]", typ)
+	}
+
+	return &record{
+		typ:       typ,
+		typeName:  name,
+		timestamp: time.Unix(s, ms*int64(time.Millisecond)),
+		serial:    n,
+		fields:    parseFields(body, typ == typeExecve),
+	}, nil
+}
+
+// parseLine parses a record in the text format of the audit log and the
+// audisp af_unix plugin, i.e. "type=<name> msg=audit(...): <fields>".
+func parseLine(line string) (*record, error) {
+	// Records of remote hosts are prefixed by the node name
+	if strings.HasPrefix(line, "node=") {
+		_, line, _ = strings.Cut(line, " ")
+	}
+
+	typeField, msg, found := strings.Cut(line, " msg=")
+	if !found {
+		return nil, errors.New("missing message")
+	}
+	name, found := strings.CutPrefix(typeField, "type=")
+	if !found {
+		return nil, errors.New("missing record type")
+	}
+
+	typ, found := recordTypeNumbers[name]
+	if !found {
+		// Unknown types are formatted as "UNKNOWN[<type>]"
+		if v, ok := strings.CutPrefix(name, "UNKNOWN["); ok {
+			n, err := strconv.Atoi(strings.TrimSuffix(v, "]"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid record type %q", name)
+			}
+			typ = n
+		}
+	}
+
+	r, err := parseRecord(typ, msg)
+	if err != nil {
+		return nil, err
+	}
+	if typ == 0 {
+		r.typeName = name
+	}
+	return r, nil
+}
+
+// parseFields splits the space separated key-value pairs of a record. The
+// "msg" field of user-space records is itself a list of key-value pairs and
+// is merged into the result. The arguments of execve records are decoded in
+// addition to the generally encoded fields.
+func parseFields(s string, execve bool) map[string]string {
+	fields := make(map[string]string)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		key, rest, found := strings.Cut(s, "=")
+		if !found {
+			break
+		}
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		case strings.HasPrefix(rest, `'`):
+			end := strings.IndexByte(rest[1:], '\'')
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+			if key == "msg" {
+				for k, v := range parseFields(value, execve) {
+					fields[k] = v
+				}
+				continue
+			}
+		default:
+			end := strings.IndexByte(rest, ' ')
+			if end < 0 {
+				value, s = rest, ""
+			} else {
+				value, s = rest[:end], rest[end:]
+			}
+			if encodedFields[key] || (execve && isArgument(key)) {
+				value = decodeValue(value)
+			}
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// decodeValue decodes hex-encoded values of unquoted fields
+func decodeValue(value string) string {
+	if value == "(null)" || len(value)%2 != 0 {
+		return value
+	}
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	// Arguments and proctitle are separated by null characters
+	return strings.ReplaceAll(string(decoded), "\x00", " ")
+}
+
+// isArgument returns true for the argument fields of execve records, i.e.
+// "a0", "a1", ... and "a0[0]" for arguments split across records
+func isArgument(key string) bool {
+	if len(key) < 2 || key[0] != 'a' {
+		return false
+	}
+	idx, _, _ := strings.Cut(key[1:], "[")
+	_, err := strconv.Atoi(idx)
+	return err == nil
+}
+
+// event is the set of records sharing the same serial number
+type event struct {
+	serial    uint64
+	timestamp time.Time
+	received  time.Time
+	records   []*record
+}
+
+// find returns the first record of the given type in the event
+func (e *event) find(typ int) *record {
+	for _, r := range e.records {
+		if r.typ == typ {
+			return r
+		}
+	}
+	return nil
+}
+
+// typeName returns the name of the event type, i.e. the type of the syscall
+// record or of the first record for events without a syscall.
+func (e *event) typeName() string {
+	if r := e.find(typeSyscall); r != nil {
+		return r.typeName
+	}
+	return e.records[0].typeName
+}
+
+// field returns the value of the field of the syscall record or of the first
+// record containing the field
+func (e *event) field(name string) string {
+	if r := e.find(typeSyscall); r != nil {
+		if v, found := r.fields[name]; found {
+			return v
+		}
+	}
+	for _, r := range e.records {
+		if v, found := r.fields[name]; found {
+			return v
+		}
+	}
+	return ""
+}
+
+// key returns the rule key of the event or an empty string for events not
+// triggered by a rule with a key
+func (e *event) key() string {
+	key := e.field("key")
+	if key == "(null)" {
+		return ""
+	}
+	return key
+}
+
+// result returns "success" or "failed" for events containing a result and an
+// empty string otherwise
+func (e *event) result() string {
+	if r := e.find(typeSyscall); r != nil {
+		switch r.fields["success"] {
+		case "yes":
+			return "success"
+		case "no":
+			return "failed"
+		}
+	}
+	switch e.field("res") {
+	case "success", "1":
+		return "success"
+	case "failed", "0":
+		return "failed"
+	}
+	return ""
+}
+
+// commandLine assembles the arguments of the execve record
+func (e *event) commandLine() string {
+	r := e.find(typeExecve)
+	if r == nil {
+		return ""
+	}
+	argc, err := strconv.Atoi(r.fields["argc"])
+	if err != nil {
+		return ""
+	}
+	args := make([]string, 0, argc)
+	for i := range argc {
+		args = append(args, r.fields["a"+strconv.Itoa(i)])
+	}
+	return strings.Join(args, " ")
+}
+
+// standalone returns true if the record type forms an event on its own.
+// Kernel records (1300-1699) are followed by further records of the same
+// event and are terminated by an end-of-event record.
+func standalone(typ int) bool {
+	return typ != 0 && (typ < 1300 || typ >= 1700)
+}
+
+// assembler collects the records of an event until the event is complete
+type assembler struct {
+	timeout time.Duration
+	pending map[uint64]*event
+}
+
+func newAssembler(timeout time.Duration) *assembler {
+	return &assembler{
+		timeout: timeout,
+		pending: make(map[uint64]*event),
+	}
+}
+
+// add adds the record and returns the events completed by this record
+func (a *assembler) add(r *record, now time.Time) []*event {
+	e, found := a.pending[r.serial]
+	if !found {
+		if r.typ == typeEOE {
+			return nil
+		}
+		e = &event{serial: r.serial, timestamp: r.timestamp, received: now}
+	}
+
+	if r.typ == typeEOE {
+		delete(a.pending, r.serial)
+		return []*event{e}
+	}
+
+	e.records = append(e.records, r)
+	if !found && standalone(r.typ) {
+		return []*event{e}
+	}
+	a.pending[r.serial] = e
+	return nil
+}
+
+// expire returns and removes the events not completed within the timeout
+// e.g. due to lost end-of-event records
+func (a *assembler) expire(now time.Time) []*event {
+	var expired []*event
+	for serial, e := range a.pending {
+		if now.Sub(e.received) >= a.timeout {
+			expired = append(expired, e)
+			delete(a.pending, serial)
+		}
+	}
+	return expired
+}
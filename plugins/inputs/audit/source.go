@@ -0,0 +1,136 @@
+//go:build linux
+
+package audit
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Multicast group of the audit netlink socket for reading events
+	// without interfering with auditd, see linux/audit.h
+	auditNetlinkGroupReadLog = 1
+
+	// Size of the netlink message header
+	netlinkHeaderSize = 16
+
+	// Maximum size of an audit record line including enriched fields
+	maxLineSize = 1024 * 1024
+)
+
+var (
+	// errInvalidRecord is returned for records failing to parse
+	errInvalidRecord = errors.New("invalid record")
+
+	// errOverrun is returned if the kernel dropped records due to a full
+	// receive buffer of the netlink socket
+	errOverrun = errors.New("receive buffer overrun, records lost")
+)
+
+// source provides the records of the audit subsystem. Errors other than
+// errInvalidRecord and errOverrun are fatal and require reopening the source.
+type source interface {
+	read() (*record, error)
+	Close() error
+}
+
+// netlinkSource receives the audit records from the kernel's netlink
+// multicast group
+type netlinkSource struct {
+	file *os.File
+	buf  []byte
+}
+
+func newNetlinkSource() (*netlinkSource, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, unix.NETLINK_AUDIT)
+	if err != nil {
+		return nil, fmt.Errorf("creating netlink socket failed: %w", err)
+	}
+
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: auditNetlinkGroupReadLog}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("joining audit multicast group failed: %w", err)
+	}
+
+	// Using a non-blocking socket allows to interrupt reads on close
+	return &netlinkSource{
+		file: os.NewFile(uintptr(fd), "audit-netlink"),
+		buf:  make([]byte, 16*1024),
+	}, nil
+}
+
+func (s *netlinkSource) read() (*record, error) {
+	n, err := s.file.Read(s.buf)
+	if err != nil {
+		if errors.Is(err, unix.ENOBUFS) {
+			return nil, errOverrun
+		}
+		return nil, err
+	}
+	if n < netlinkHeaderSize {
+		return nil, fmt.Errorf("%w: message truncated", errInvalidRecord)
+	}
+
+	// The kernel sends one record per message; the length of the header is
+	// not reliable for multicast messages so the payload is taken as is.
+	typ := binary.NativeEndian.Uint16(s.buf[4:6])
+	r, err := parseRecord(int(typ), string(s.buf[netlinkHeaderSize:n]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRecord, err)
+	}
+	return r, nil
+}
+
+func (s *netlinkSource) Close() error {
+	return s.file.Close()
+}
+
+// unixSource reads the records in text format from the socket of the audisp
+// af_unix plugin
+type unixSource struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func newUnixSource(path string) (*unixSource, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	return &unixSource{conn: conn, scanner: scanner}, nil
+}
+
+func (s *unixSource) read() (*record, error) {
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			continue
+		}
+		r, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		return r, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *unixSource) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,84 @@
+package azure_devops
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	apiVersion = "7.1"
+
+	// Maximum number of items requested per page
+	pageLimit = 1000
+)
+
+// listResponse is the envelope of all list endpoints, further pages are
+// indicated by the 'x-ms-continuationtoken' header
+type listResponse[T any] struct {
+	Count int `json:"count"`
+	Value []T `json:"value"`
+}
+
+// project is an item of the '_apis/projects' endpoint
+type project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// build is an item of the '{project}/_apis/build/builds' endpoint
+type build struct {
+	ID           int64      `json:"id"`
+	Status       string     `json:"status"`
+	Result       string     `json:"result"`
+	Reason       string     `json:"reason"`
+	SourceBranch string     `json:"sourceBranch"`
+	QueueTime    *time.Time `json:"queueTime"`
+	StartTime    *time.Time `json:"startTime"`
+	FinishTime   *time.Time `json:"finishTime"`
+	Definition   struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"definition"`
+	Queue struct {
+		Name string `json:"name"`
+		Pool struct {
+			Name     string `json:"name"`
+			IsHosted bool   `json:"isHosted"`
+		} `json:"pool"`
+	} `json:"queue"`
+}
+
+// deployment is an item of the '{project}/_apis/release/deployments'
+// endpoint of the release management API
+type deployment struct {
+	ID                 int64      `json:"id"`
+	DeploymentStatus   string     `json:"deploymentStatus"`
+	Attempt            int64      `json:"attempt"`
+	QueuedOn           *time.Time `json:"queuedOn"`
+	StartedOn          *time.Time `json:"startedOn"`
+	CompletedOn        *time.Time `json:"completedOn"`
+	Release            reference  `json:"release"`
+	ReleaseDefinition  reference  `json:"releaseDefinition"`
+	ReleaseEnvironment reference  `json:"releaseEnvironment"`
+}
+
+type reference struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// agentPool is an item of the '_apis/distributedtask/pools' endpoint
+type agentPool struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	IsHosted bool   `json:"isHosted"`
+}
+
+// agent is an item of the '_apis/distributedtask/pools/{id}/agents' endpoint
+type agent struct {
+	ID              int64           `json:"id"`
+	Name            string          `json:"name"`
+	Status          string          `json:"status"`
+	Enabled         bool            `json:"enabled"`
+	AssignedRequest json.RawMessage `json:"assignedRequest"`
+}
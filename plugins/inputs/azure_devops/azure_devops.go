@@ -0,0 +1,545 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package azure_devops
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Interval for refreshing the list of projects
+const projectsRefreshInterval = time.Hour
+
+// Results of builds and release deployments counted per pipeline, the first
+// entry is counted as failure
+var (
+	buildResults      = []string{"failed", "succeeded", "partiallySucceeded", "canceled"}
+	deploymentResults = []string{"failed", "succeeded", "partiallySucceeded", "notDeployed"}
+)
+
+type AzureDevOps struct {
+	URL            string          `toml:"url"`
+	ReleaseURL     string          `toml:"release_url"`
+	Token          config.Secret   `toml:"token"`
+	ProjectInclude []string        `toml:"project_include"`
+	ProjectExclude []string        `toml:"project_exclude"`
+	Collect        []string        `toml:"collect"`
+	Lookback       config.Duration `toml:"lookback"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client        *http.Client
+	organization  string
+	projectFilter filter.Filter
+
+	projects        []project
+	projectsUpdated time.Time
+
+	// end of the time range of the last collection of finished builds and
+	// deployments per project
+	since map[windowKey]time.Time
+	now   func() time.Time
+}
+
+type windowKey struct {
+	project string
+	collect string
+}
+
+func (*AzureDevOps) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AzureDevOps) Init() error {
+	if a.URL == "" {
+		return errors.New("url required")
+	}
+	a.URL = strings.TrimSuffix(a.URL, "/")
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	a.organization = path.Base(u.Path)
+	if a.organization == "." || a.organization == "/" {
+		// Legacy URLs contain the organization in the hostname
+		a.organization, _, _ = strings.Cut(u.Hostname(), ".")
+	}
+
+	if a.ReleaseURL == "" {
+		a.ReleaseURL = releaseURL(u)
+	}
+	a.ReleaseURL = strings.TrimSuffix(a.ReleaseURL, "/")
+
+	if len(a.Collect) == 0 {
+		a.Collect = []string{"builds", "releases", "agent_pools"}
+	}
+	for _, c := range a.Collect {
+		switch c {
+		case "builds", "releases", "agent_pools":
+		default:
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+
+	if a.projectFilter, err = filter.NewIncludeExcludeFilter(a.ProjectInclude, a.ProjectExclude); err != nil {
+		return fmt.Errorf("creating project filter failed: %w", err)
+	}
+
+	client, err := a.HTTPClientConfig.CreateClient(context.Background(), a.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	a.client = client
+
+	if a.now == nil {
+		a.now = time.Now
+	}
+	a.since = make(map[windowKey]time.Time)
+
+	return nil
+}
+
+func (a *AzureDevOps) Gather(acc telegraf.Accumulator) error {
+	now := a.now()
+
+	if slices.Contains(a.Collect, "agent_pools") {
+		if err := a.gatherAgentPools(acc, now); err != nil {
+			acc.AddError(fmt.Errorf("collecting agent_pools failed: %w", err))
+		}
+	}
+
+	if !slices.Contains(a.Collect, "builds") && !slices.Contains(a.Collect, "releases") {
+		return nil
+	}
+	projects, err := a.getProjects(now)
+	if err != nil {
+		return fmt.Errorf("listing projects failed: %w", err)
+	}
+
+	for _, p := range projects {
+		for _, c := range a.Collect {
+			if c == "agent_pools" {
+				continue
+			}
+
+			// Finished builds and deployments are collected for the time
+			// range since the last successful collection
+			key := windowKey{p.ID, c}
+			since, found := a.since[key]
+			if !found {
+				since = now.Add(-time.Duration(a.Lookback))
+			}
+
+			var err error
+			switch c {
+			case "builds":
+				err = a.gatherBuilds(acc, p, since, now)
+			case "releases":
+				err = a.gatherReleases(acc, p, since, now)
+			}
+			if err != nil {
+				acc.AddError(fmt.Errorf("[project=%s]: collecting %s failed: %w", p.Name, c, err))
+				continue
+			}
+			a.since[key] = now
+		}
+	}
+
+	return nil
+}
+
+func (a *AzureDevOps) Stop() {
+	if a.client != nil {
+		a.client.CloseIdleConnections()
+	}
+}
+
+// getProjects returns the projects matching the filter, the list of projects
+// is refreshed periodically to avoid an additional request every interval
+func (a *AzureDevOps) getProjects(now time.Time) ([]project, error) {
+	if a.projects != nil && now.Sub(a.projectsUpdated) < projectsRefreshInterval {
+		return a.projects, nil
+	}
+
+	projects := make([]project, 0)
+	params := url.Values{"$top": []string{strconv.Itoa(pageLimit)}}
+	var token string
+	for {
+		var resp listResponse[project]
+		var err error
+		token, err = a.list(a.URL+"/_apis/projects", params, token, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Value {
+			if a.projectFilter.Match(p.Name) {
+				projects = append(projects, p)
+			}
+		}
+		if token == "" {
+			break
+		}
+	}
+	a.Log.Debugf("Found %d matching projects", len(projects))
+
+	a.projects = projects
+	a.projectsUpdated = now
+	return projects, nil
+}
+
+func (a *AzureDevOps) gatherBuilds(acc telegraf.Accumulator, p project, since, until time.Time) error {
+	params := url.Values{
+		"minTime":      []string{since.UTC().Format(time.RFC3339Nano)},
+		"maxTime":      []string{until.UTC().Format(time.RFC3339Nano)},
+		"queryOrder":   []string{"finishTimeAscending"},
+		"statusFilter": []string{"completed"},
+		"$top":         []string{strconv.Itoa(pageLimit)},
+	}
+
+	stats := newPipelineStats()
+	address := a.URL + "/" + url.PathEscape(p.ID) + "/_apis/build/builds"
+	var token string
+	for {
+		var resp listResponse[build]
+		var err error
+		token, err = a.list(address, params, token, &resp)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range resp.Value {
+			// The time range is inclusive on both ends for the API
+			if b.FinishTime == nil || !b.FinishTime.Before(until) {
+				continue
+			}
+			tags := map[string]string{
+				"organization": a.organization,
+				"project":      p.Name,
+				"definition":   b.Definition.Name,
+				"result":       b.Result,
+				"reason":       b.Reason,
+				"branch":       b.SourceBranch,
+				"pool":         b.Queue.Pool.Name,
+			}
+			fields := map[string]interface{}{
+				"build_id": b.ID,
+			}
+			duration, queueTime := timings(b.QueueTime, b.StartTime, *b.FinishTime)
+			if duration >= 0 {
+				fields["duration"] = duration
+			}
+			if queueTime >= 0 {
+				fields["queue_time"] = queueTime
+			}
+			acc.AddFields("azure_devops_build", fields, tags, *b.FinishTime)
+
+			stats.add(pipelineKey{definition: b.Definition.Name}, b.Result, duration, queueTime)
+		}
+
+		if token == "" {
+			break
+		}
+	}
+
+	for _, key := range stats.keys {
+		tags := map[string]string{
+			"organization": a.organization,
+			"project":      p.Name,
+			"definition":   key.definition,
+		}
+		acc.AddFields("azure_devops_build_summary", stats.fields(key, buildResults), tags, until)
+	}
+
+	return nil
+}
+
+func (a *AzureDevOps) gatherReleases(acc telegraf.Accumulator, p project, since, until time.Time) error {
+	// Deployments cannot be queried by completion time so query all
+	// deployments modified after the start of the time range and filter
+	// by completion time
+	params := url.Values{
+		"minModifiedTime": []string{since.UTC().Format(time.RFC3339Nano)},
+		"queryOrder":      []string{"ascending"},
+		"$top":            []string{strconv.Itoa(pageLimit)},
+	}
+
+	stats := newPipelineStats()
+	address := a.ReleaseURL + "/" + url.PathEscape(p.ID) + "/_apis/release/deployments"
+	var token string
+	for {
+		var resp listResponse[deployment]
+		var err error
+		token, err = a.list(address, params, token, &resp)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range resp.Value {
+			if d.CompletedOn == nil || d.DeploymentStatus == "inProgress" {
+				continue
+			}
+			if d.CompletedOn.Before(since) || !d.CompletedOn.Before(until) {
+				continue
+			}
+			tags := map[string]string{
+				"organization": a.organization,
+				"project":      p.Name,
+				"definition":   d.ReleaseDefinition.Name,
+				"environment":  d.ReleaseEnvironment.Name,
+				"status":       d.DeploymentStatus,
+			}
+			fields := map[string]interface{}{
+				"deployment_id": d.ID,
+				"release_id":    d.Release.ID,
+				"attempt":       d.Attempt,
+			}
+			duration, queueTime := timings(d.QueuedOn, d.StartedOn, *d.CompletedOn)
+			if duration >= 0 {
+				fields["duration"] = duration
+			}
+			if queueTime >= 0 {
+				fields["queue_time"] = queueTime
+			}
+			acc.AddFields("azure_devops_deployment", fields, tags, *d.CompletedOn)
+
+			key := pipelineKey{definition: d.ReleaseDefinition.Name, environment: d.ReleaseEnvironment.Name}
+			stats.add(key, d.DeploymentStatus, duration, queueTime)
+		}
+
+		if token == "" {
+			break
+		}
+	}
+
+	for _, key := range stats.keys {
+		tags := map[string]string{
+			"organization": a.organization,
+			"project":      p.Name,
+			"definition":   key.definition,
+			"environment":  key.environment,
+		}
+		acc.AddFields("azure_devops_deployment_summary", stats.fields(key, deploymentResults), tags, until)
+	}
+
+	return nil
+}
+
+func (a *AzureDevOps) gatherAgentPools(acc telegraf.Accumulator, now time.Time) error {
+	var pools listResponse[agentPool]
+	if _, err := a.list(a.URL+"/_apis/distributedtask/pools", nil, "", &pools); err != nil {
+		return err
+	}
+
+	params := url.Values{"includeAssignedRequest": []string{"true"}}
+	for _, p := range pools.Value {
+		var agents listResponse[agent]
+		address := a.URL + "/_apis/distributedtask/pools/" + strconv.FormatInt(p.ID, 10) + "/agents"
+		if _, err := a.list(address, params, "", &agents); err != nil {
+			acc.AddError(fmt.Errorf("[pool=%s]: listing agents failed: %w", p.Name, err))
+			continue
+		}
+
+		var online, enabled, available, busy int64
+		for _, ag := range agents.Value {
+			if ag.Status == "online" {
+				online++
+			}
+			if ag.Enabled {
+				enabled++
+			}
+			if ag.Status == "online" && ag.Enabled {
+				available++
+			}
+			if len(ag.AssignedRequest) > 0 && string(ag.AssignedRequest) != "null" {
+				busy++
+			}
+		}
+
+		tags := map[string]string{
+			"organization": a.organization,
+			"pool":         p.Name,
+			"hosted":       strconv.FormatBool(p.IsHosted),
+		}
+		fields := map[string]interface{}{
+			"agents":  int64(len(agents.Value)),
+			"online":  online,
+			"enabled": enabled,
+			"busy":    busy,
+		}
+		if available > 0 {
+			fields["utilization"] = float64(busy) / float64(available) * 100
+		}
+		acc.AddFields("azure_devops_agent_pool", fields, tags, now)
+	}
+
+	return nil
+}
+
+// list requests a page of the given endpoint and returns the continuation
+// token for the next page, the token is empty for the last page
+func (a *AzureDevOps) list(address string, params url.Values, token string, v interface{}) (string, error) {
+	query := maps.Clone(params)
+	if query == nil {
+		query = make(url.Values, 2)
+	}
+	query.Set("api-version", apiVersion)
+	if token != "" {
+		query.Set("continuationToken", token)
+	}
+	address += "?" + query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if !a.Token.Empty() {
+		pat, err := a.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		// Personal access tokens are passed as password with an empty user
+		req.SetBasicAuth("", pat.String())
+		pat.Destroy()
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Invalid tokens result in a redirect to the sign-in page with HTTP 203
+	if resp.StatusCode != http.StatusOK {
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return "", fmt.Errorf("%s returned HTTP status %s: %q", address, resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("decoding response of %s failed: %w", address, err)
+	}
+	return resp.Header.Get("X-Ms-Continuationtoken"), nil
+}
+
+// releaseURL returns the URL of the release management API which uses a
+// separate host for Azure DevOps Services
+func releaseURL(u *url.URL) string {
+	r := *u
+	switch {
+	case r.Host == "dev.azure.com":
+		r.Host = "vsrm.dev.azure.com"
+	case strings.HasSuffix(r.Host, ".visualstudio.com") && !strings.Contains(r.Host, ".vsrm."):
+		org, domain, _ := strings.Cut(r.Host, ".")
+		r.Host = org + ".vsrm." + domain
+	}
+	return r.String()
+}
+
+// timings returns the duration of a run and the time it was queued in
+// seconds, the values are negative if the corresponding times are unknown
+func timings(queued, started *time.Time, finished time.Time) (duration, queueTime float64) {
+	duration, queueTime = -1, -1
+	if started != nil && !started.IsZero() {
+		duration = finished.Sub(*started).Seconds()
+		if queued != nil && !queued.IsZero() {
+			queueTime = started.Sub(*queued).Seconds()
+		}
+	}
+	return duration, queueTime
+}
+
+type pipelineKey struct {
+	definition  string
+	environment string
+}
+
+// pipelineStats aggregates the results of the runs per pipeline
+type pipelineStats struct {
+	keys  []pipelineKey
+	stats map[pipelineKey]*runStats
+}
+
+type runStats struct {
+	results        map[string]int64
+	total          int64
+	durationSum    float64
+	durationCount  int64
+	queueTimeSum   float64
+	queueTimeCount int64
+}
+
+func newPipelineStats() *pipelineStats {
+	return &pipelineStats{stats: make(map[pipelineKey]*runStats)}
+}
+
+func (p *pipelineStats) add(key pipelineKey, result string, duration, queueTime float64) {
+	s, found := p.stats[key]
+	if !found {
+		s = &runStats{results: make(map[string]int64)}
+		p.stats[key] = s
+		p.keys = append(p.keys, key)
+	}
+	s.results[result]++
+	s.total++
+	if duration >= 0 {
+		s.durationSum += duration
+		s.durationCount++
+	}
+	if queueTime >= 0 {
+		s.queueTimeSum += queueTime
+		s.queueTimeCount++
+	}
+}
+
+// fields returns the number of runs per result and the failure rate, the
+// first of the given results is counted as failure
+func (p *pipelineStats) fields(key pipelineKey, results []string) map[string]interface{} {
+	s := p.stats[key]
+	fields := make(map[string]interface{}, len(results)+4)
+	for _, r := range results {
+		fields[internal.SnakeCase(r)] = s.results[r]
+	}
+	fields["total"] = s.total
+	fields["failure_rate"] = float64(s.results[results[0]]) / float64(s.total) * 100
+	if s.durationCount > 0 {
+		fields["duration_avg"] = s.durationSum / float64(s.durationCount)
+	}
+	if s.queueTimeCount > 0 {
+		fields["queue_time_avg"] = s.queueTimeSum / float64(s.queueTimeCount)
+	}
+	return fields
+}
+
+func init() {
+	inputs.Add("azure_devops", func() telegraf.Input {
+		return &AzureDevOps{
+			Lookback: config.Duration(5 * time.Minute),
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
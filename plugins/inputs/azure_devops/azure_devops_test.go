@@ -0,0 +1,402 @@
+package azure_devops
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var now = time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC)
+
+const fabrikamID = "eb6e4656-77fc-42a1-9181-4c6d8e9da5d1"
+
+type server struct {
+	*httptest.Server
+
+	sync.Mutex
+	requests map[string][]url.Values
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+
+	s := &server{requests: make(map[string][]url.Values)}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("api-version") != apiVersion {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.Lock()
+		s.requests[r.URL.Path] = append(s.requests[r.URL.Path], query)
+		s.Unlock()
+
+		var filename string
+		switch r.URL.Path {
+		case "/myorg/_apis/projects":
+			filename = "projects.json"
+		case "/myorg/" + fabrikamID + "/_apis/build/builds":
+			// Return the builds on two pages
+			if query.Get("continuationToken") == "" {
+				w.Header().Set("x-ms-continuationtoken", "1043")
+				filename = "builds_1.json"
+			} else {
+				filename = "builds_2.json"
+			}
+		case "/myorg/" + fabrikamID + "/_apis/release/deployments":
+			filename = "deployments.json"
+		case "/myorg/_apis/distributedtask/pools":
+			filename = "pools.json"
+		case "/myorg/_apis/distributedtask/pools/1/agents":
+			filename = "agents_1.json"
+		case "/myorg/_apis/distributedtask/pools/9/agents":
+			filename = "agents_9.json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		buf, err := os.ReadFile(filepath.Join("testdata", filename))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AzureDevOps
+		expected string
+	}{
+		{
+			name:     "no url",
+			plugin:   &AzureDevOps{},
+			expected: "url required",
+		},
+		{
+			name: "invalid collect",
+			plugin: &AzureDevOps{
+				URL:     "https://dev.azure.com/myorg",
+				Collect: []string{"pipelines"},
+			},
+			expected: `invalid 'collect' value "pipelines"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitURLs(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		organization string
+		releaseURL   string
+	}{
+		{
+			name:         "services",
+			url:          "https://dev.azure.com/myorg/",
+			organization: "myorg",
+			releaseURL:   "https://vsrm.dev.azure.com/myorg",
+		},
+		{
+			name:         "legacy services",
+			url:          "https://myorg.visualstudio.com",
+			organization: "myorg",
+			releaseURL:   "https://myorg.vsrm.visualstudio.com",
+		},
+		{
+			name:         "server",
+			url:          "https://tfs.example.com/tfs/DefaultCollection",
+			organization: "DefaultCollection",
+			releaseURL:   "https://tfs.example.com/tfs/DefaultCollection",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AzureDevOps{
+				URL: tt.url,
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.Equal(t, tt.organization, plugin.organization)
+			require.Equal(t, tt.releaseURL, plugin.ReleaseURL)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	s := newServer(t)
+
+	plugin := &AzureDevOps{
+		URL:            s.URL + "/myorg",
+		ReleaseURL:     s.URL + "/myorg",
+		Token:          config.NewSecret([]byte("secret")),
+		ProjectExclude: []string{"Sandbox"},
+		Lookback:       config.Duration(5 * time.Minute),
+		Log:            testutil.Logger{},
+		now:            func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"azure_devops_agent_pool",
+			map[string]string{
+				"organization": "myorg",
+				"pool":         "Default",
+				"hosted":       "false",
+			},
+			map[string]interface{}{
+				"agents":      int64(3),
+				"online":      int64(2),
+				"enabled":     int64(2),
+				"busy":        int64(1),
+				"utilization": float64(50),
+			},
+			now,
+		),
+		metric.New(
+			"azure_devops_agent_pool",
+			map[string]string{
+				"organization": "myorg",
+				"pool":         "Azure Pipelines",
+				"hosted":       "true",
+			},
+			map[string]interface{}{
+				"agents":  int64(0),
+				"online":  int64(0),
+				"enabled": int64(0),
+				"busy":    int64(0),
+			},
+			now,
+		),
+		metric.New(
+			"azure_devops_build",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-ci",
+				"result":       "succeeded",
+				"reason":       "individualCI",
+				"branch":       "refs/heads/main",
+				"pool":         "Azure Pipelines",
+			},
+			map[string]interface{}{
+				"build_id":   int64(1041),
+				"duration":   float64(180),
+				"queue_time": float64(30),
+			},
+			time.Date(2024, 5, 1, 12, 3, 40, 0, time.UTC),
+		),
+		metric.New(
+			"azure_devops_build",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-ci",
+				"result":       "failed",
+				"reason":       "pullRequest",
+				"branch":       "refs/pull/17/merge",
+				"pool":         "Azure Pipelines",
+			},
+			map[string]interface{}{
+				"build_id":   int64(1042),
+				"duration":   float64(160),
+				"queue_time": float64(20),
+			},
+			time.Date(2024, 5, 1, 12, 4, 0, 0, time.UTC),
+		),
+		metric.New(
+			"azure_devops_build",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-ci",
+				"result":       "canceled",
+				"reason":       "manual",
+				"branch":       "refs/heads/main",
+				"pool":         "Default",
+			},
+			map[string]interface{}{
+				"build_id": int64(1043),
+			},
+			time.Date(2024, 5, 1, 12, 4, 35, 0, time.UTC),
+		),
+		metric.New(
+			"azure_devops_build_summary",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-ci",
+			},
+			map[string]interface{}{
+				"total":               int64(3),
+				"succeeded":           int64(1),
+				"partially_succeeded": int64(0),
+				"failed":              int64(1),
+				"canceled":            int64(1),
+				"failure_rate":        float64(100) / 3,
+				"duration_avg":        float64(170),
+				"queue_time_avg":      float64(25),
+			},
+			now,
+		),
+		metric.New(
+			"azure_devops_deployment",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-cd",
+				"environment":  "staging",
+				"status":       "succeeded",
+			},
+			map[string]interface{}{
+				"deployment_id": int64(310),
+				"release_id":    int64(88),
+				"attempt":       int64(1),
+				"duration":      float64(60),
+				"queue_time":    float64(30),
+			},
+			time.Date(2024, 5, 1, 12, 2, 30, 0, time.UTC),
+		),
+		metric.New(
+			"azure_devops_deployment",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-cd",
+				"environment":  "production",
+				"status":       "failed",
+			},
+			map[string]interface{}{
+				"deployment_id": int64(311),
+				"release_id":    int64(88),
+				"attempt":       int64(2),
+				"duration":      float64(120),
+				"queue_time":    float64(5),
+			},
+			time.Date(2024, 5, 1, 12, 4, 45, 0, time.UTC),
+		),
+		metric.New(
+			"azure_devops_deployment_summary",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-cd",
+				"environment":  "staging",
+			},
+			map[string]interface{}{
+				"total":               int64(1),
+				"succeeded":           int64(1),
+				"partially_succeeded": int64(0),
+				"failed":              int64(0),
+				"not_deployed":        int64(0),
+				"failure_rate":        float64(0),
+				"duration_avg":        float64(60),
+				"queue_time_avg":      float64(30),
+			},
+			now,
+		),
+		metric.New(
+			"azure_devops_deployment_summary",
+			map[string]string{
+				"organization": "myorg",
+				"project":      "Fabrikam",
+				"definition":   "webapp-cd",
+				"environment":  "production",
+			},
+			map[string]interface{}{
+				"total":               int64(1),
+				"succeeded":           int64(0),
+				"partially_succeeded": int64(0),
+				"failed":              int64(1),
+				"not_deployed":        int64(0),
+				"failure_rate":        float64(100),
+				"duration_avg":        float64(120),
+				"queue_time_avg":      float64(5),
+			},
+			now,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.SortMetrics(), cmpopts.EquateApprox(0, 1e-9))
+
+	s.Lock()
+	builds := s.requests["/myorg/"+fabrikamID+"/_apis/build/builds"]
+	require.Len(t, builds, 2)
+	require.Equal(t, "2024-05-01T12:00:00Z", builds[0].Get("minTime"))
+	require.Equal(t, "2024-05-01T12:05:00Z", builds[0].Get("maxTime"))
+	require.Equal(t, "1043", builds[1].Get("continuationToken"))
+	deployments := s.requests["/myorg/"+fabrikamID+"/_apis/release/deployments"]
+	require.Len(t, deployments, 1)
+	require.Equal(t, "2024-05-01T12:00:00Z", deployments[0].Get("minModifiedTime"))
+	s.Unlock()
+
+	// The next collection continues at the end of the previous time range
+	// and reuses the list of projects
+	plugin.now = func() time.Time { return now.Add(time.Minute) }
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	s.Lock()
+	defer s.Unlock()
+	require.Len(t, s.requests["/myorg/_apis/projects"], 1)
+	builds = s.requests["/myorg/"+fabrikamID+"/_apis/build/builds"]
+	require.Len(t, builds, 4)
+	require.Equal(t, "2024-05-01T12:05:00Z", builds[2].Get("minTime"))
+	require.Equal(t, "2024-05-01T12:06:00Z", builds[2].Get("maxTime"))
+}
+
+func TestGatherUnauthorized(t *testing.T) {
+	s := newServer(t)
+
+	plugin := &AzureDevOps{
+		URL:     s.URL + "/myorg",
+		Token:   config.NewSecret([]byte("wrong")),
+		Collect: []string{"builds"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "401 Unauthorized")
+}
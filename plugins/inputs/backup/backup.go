@@ -0,0 +1,155 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package backup
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// execCommand is used to mock commands in tests.
+var execCommand = exec.Command
+
+type Backup struct {
+	Timeout      config.Duration `toml:"timeout"`
+	Repositories []*repository   `toml:"repository"`
+	Log          telegraf.Logger `toml:"-"`
+
+	now func() time.Time
+}
+
+type repository struct {
+	Type        string        `toml:"type"`
+	Name        string        `toml:"name"`
+	Location    string        `toml:"location"`
+	Password    config.Secret `toml:"password"`
+	Binary      string        `toml:"binary"`
+	Environment []string      `toml:"environment"`
+}
+
+func (*Backup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *Backup) Init() error {
+	if len(b.Repositories) == 0 {
+		return errors.New("no repository configured")
+	}
+
+	for i, r := range b.Repositories {
+		switch r.Type {
+		case "restic", "borg":
+		case "":
+			return fmt.Errorf("type required for repository %d", i+1)
+		default:
+			return fmt.Errorf("invalid type %q for repository %d", r.Type, i+1)
+		}
+		if r.Location == "" {
+			return fmt.Errorf("location required for repository %d", i+1)
+		}
+		if r.Name == "" {
+			r.Name = r.Location
+		}
+		if r.Binary == "" {
+			r.Binary = r.Type
+		}
+	}
+
+	if b.Timeout <= 0 {
+		b.Timeout = config.Duration(time.Minute)
+	}
+
+	if b.now == nil {
+		b.now = time.Now
+	}
+
+	return nil
+}
+
+func (b *Backup) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, r := range b.Repositories {
+		wg.Add(1)
+		go func(r *repository) {
+			defer wg.Done()
+
+			var fields map[string]interface{}
+			var err error
+			switch r.Type {
+			case "restic":
+				fields, err = b.gatherRestic(acc, r)
+			case "borg":
+				fields, err = b.gatherBorg(acc, r)
+			}
+
+			// Always report the repository status to allow alerting on
+			// failing or unreachable repositories
+			if err != nil {
+				acc.AddError(fmt.Errorf("gathering %s repository %q failed: %w", r.Type, r.Name, err))
+				fields = map[string]interface{}{"status_code": 1}
+			} else {
+				fields["status_code"] = 0
+			}
+			tags := map[string]string{
+				"tool":       r.Type,
+				"repository": r.Name,
+			}
+			acc.AddFields("backup_repository", fields, tags)
+		}(r)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// run executes the tool with the given arguments and returns the standard
+// output. The repository location and password are passed via environment
+// variables to avoid exposing them on the command-line.
+func (b *Backup) run(r *repository, env []string, args ...string) ([]byte, error) {
+	cmd := execCommand(r.Binary, args...)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, r.Environment...)
+	cmd.Env = append(cmd.Env, env...)
+
+	if !r.Password.Empty() {
+		passwd, err := r.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		variable := "RESTIC_PASSWORD="
+		if r.Type == "borg" {
+			variable = "BORG_PASSPHRASE="
+		}
+		cmd.Env = append(cmd.Env, variable+passwd.String())
+		passwd.Destroy()
+	}
+
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(b.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %s %v failed: %w", filepath.Base(r.Binary), args, err)
+	}
+	return out, nil
+}
+
+func init() {
+	inputs.Add("backup", func() telegraf.Input {
+		return &Backup{
+			Timeout: config.Duration(time.Minute),
+		}
+	})
+}
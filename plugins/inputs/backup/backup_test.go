@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		repos    []*repository
+		expected string
+	}{
+		{
+			name:     "no repository",
+			expected: "no repository configured",
+		},
+		{
+			name:     "missing type",
+			repos:    []*repository{{Location: "/srv/repo"}},
+			expected: "type required for repository 1",
+		},
+		{
+			name:     "invalid type",
+			repos:    []*repository{{Type: "duplicity", Location: "/srv/repo"}},
+			expected: `invalid type "duplicity" for repository 1`,
+		},
+		{
+			name:     "missing location",
+			repos:    []*repository{{Type: "borg"}},
+			expected: "location required for repository 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Backup{Repositories: tt.repos}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherRestic(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	plugin := &Backup{
+		Repositories: []*repository{
+			{
+				Type:     "restic",
+				Name:     "local",
+				Location: "/srv/restic-repo",
+				Password: config.NewSecret([]byte("secret")),
+			},
+		},
+		Log: testutil.Logger{},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"backup_snapshot",
+			map[string]string{
+				"tool":       "restic",
+				"repository": "local",
+				"host":       "myhost",
+				"paths":      "/etc,/home",
+			},
+			map[string]interface{}{
+				"id":         "4bb5b6a0",
+				"age":        float64(7200),
+				"timestamp":  int64(1714557600),
+				"duration":   float64(150),
+				"size":       uint64(104857600),
+				"added_size": uint64(524288),
+				"files":      uint64(1515),
+			},
+			now,
+		),
+		metric.New(
+			"backup_snapshot",
+			map[string]string{
+				"tool":       "restic",
+				"repository": "local",
+				"host":       "dbhost",
+				"paths":      "/var/lib/postgresql",
+			},
+			map[string]interface{}{
+				"id":        "9ac2d7b0",
+				"age":       float64(50400.123456789),
+				"timestamp": int64(1714514400),
+			},
+			now,
+		),
+		metric.New(
+			"backup_repository",
+			map[string]string{
+				"tool":       "restic",
+				"repository": "local",
+			},
+			map[string]interface{}{
+				"size":              uint64(73400320),
+				"uncompressed_size": uint64(209715200),
+				"compression_ratio": float64(2.857142857142857),
+				"blobs":             uint64(4242),
+				"snapshots":         uint64(42),
+				"status_code":       0,
+			},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherBorg(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	plugin := &Backup{
+		Repositories: []*repository{
+			{
+				Type:     "borg",
+				Location: "/srv/borg-repo",
+				Password: config.NewSecret([]byte("secret")),
+			},
+		},
+		Log: testutil.Logger{},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"backup_snapshot",
+			map[string]string{
+				"tool":       "borg",
+				"repository": "/srv/borg-repo",
+				"host":       "myhost",
+			},
+			map[string]interface{}{
+				"id":         "myhost-2024-05-01T10:00:00",
+				"age":        float64(7200),
+				"timestamp":  int64(1714557600),
+				"duration":   float64(300.5),
+				"size":       uint64(157286400),
+				"added_size": uint64(1048576),
+				"files":      uint64(20000),
+			},
+			now,
+		),
+		metric.New(
+			"backup_repository",
+			map[string]string{
+				"tool":       "borg",
+				"repository": "/srv/borg-repo",
+			},
+			map[string]interface{}{
+				"size":              uint64(262144000),
+				"uncompressed_size": uint64(786432000),
+				"original_size":     uint64(3145728000),
+				"chunks":            uint64(25000),
+				"compression_ratio": float64(3),
+				"snapshots":         3,
+				"status_code":       0,
+			},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFailure(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	plugin := &Backup{
+		Repositories: []*repository{
+			{
+				Type:     "restic",
+				Location: "/srv/restic-repo",
+				Password: config.NewSecret([]byte("wrong")),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `gathering restic repository "/srv/restic-repo" failed`)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"backup_repository",
+			map[string]string{
+				"tool":       "restic",
+				"repository": "/srv/restic-repo",
+			},
+			map[string]interface{}{"status_code": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func fakeExecCommand(command string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+func TestHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	// Find the tool and sub-command following the separator
+	idx := slices.Index(os.Args, "--")
+	if idx < 0 || len(os.Args) < idx+3 {
+		fmt.Fprint(os.Stderr, "invalid arguments")
+		os.Exit(42) //nolint:revive // os.Exit called intentionally
+	}
+	tool, cmd := os.Args[idx+1], os.Args[idx+2]
+
+	// Check the repository and credentials are passed via the environment
+	var location, password string
+	switch tool {
+	case "restic":
+		location, password = os.Getenv("RESTIC_REPOSITORY"), os.Getenv("RESTIC_PASSWORD")
+	case "borg":
+		location, password = os.Getenv("BORG_REPO"), os.Getenv("BORG_PASSPHRASE")
+	}
+	if location == "" || password != "secret" {
+		fmt.Fprint(os.Stderr, "wrong password")
+		os.Exit(1) //nolint:revive // os.Exit called intentionally
+	}
+
+	buf, err := os.ReadFile(filepath.Join("testdata", tool+"_"+cmd+".json"))
+	if err != nil {
+		fmt.Fprint(os.Stderr, "unknown command")
+		os.Exit(42) //nolint:revive // os.Exit called intentionally
+	}
+	fmt.Fprint(os.Stdout, string(buf))
+	os.Exit(0) //nolint:revive // os.Exit called intentionally
+}
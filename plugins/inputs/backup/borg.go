@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// borgTime handles the timestamps of borg which are in local time without
+// a timezone for borg 1.x
+type borgTime struct {
+	time.Time
+}
+
+func (t *borgTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		t.Time = ts
+		return nil
+	}
+
+	ts, err := time.ParseInLocation("2006-01-02T15:04:05.999999", s, time.Local)
+	if err != nil {
+		return err
+	}
+	t.Time = ts
+	return nil
+}
+
+type borgInfo struct {
+	Archives []struct {
+		Name     string   `json:"name"`
+		ID       string   `json:"id"`
+		Start    borgTime `json:"start"`
+		Duration float64  `json:"duration"`
+		Hostname string   `json:"hostname"`
+		Stats    struct {
+			CompressedSize   uint64 `json:"compressed_size"`
+			DeduplicatedSize uint64 `json:"deduplicated_size"`
+			NumFiles         uint64 `json:"nfiles"`
+			OriginalSize     uint64 `json:"original_size"`
+		} `json:"stats"`
+	} `json:"archives"`
+	Cache struct {
+		Stats struct {
+			TotalChunks       uint64 `json:"total_chunks"`
+			TotalUniqueChunks uint64 `json:"total_unique_chunks"`
+			TotalSize         uint64 `json:"total_size"`
+			UniqueCompressed  uint64 `json:"unique_csize"`
+			UniqueSize        uint64 `json:"unique_size"`
+		} `json:"stats"`
+	} `json:"cache"`
+}
+
+type borgList struct {
+	Archives []json.RawMessage `json:"archives"`
+}
+
+func (b *Backup) gatherBorg(acc telegraf.Accumulator, r *repository) (map[string]interface{}, error) {
+	env := []string{"BORG_REPO=" + r.Location}
+
+	// Get the repository statistics and the latest archive
+	buf, err := b.run(r, env, "info", "--json", "--last", "1")
+	if err != nil {
+		return nil, err
+	}
+	var info borgInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return nil, fmt.Errorf("parsing info failed: %w", err)
+	}
+
+	now := b.now()
+	for _, archive := range info.Archives {
+		tags := map[string]string{
+			"tool":       r.Type,
+			"repository": r.Name,
+			"host":       archive.Hostname,
+		}
+		fields := map[string]interface{}{
+			"id":         archive.Name,
+			"age":        now.Sub(archive.Start.Time).Seconds(),
+			"timestamp":  archive.Start.Unix(),
+			"duration":   archive.Duration,
+			"size":       archive.Stats.OriginalSize,
+			"added_size": archive.Stats.DeduplicatedSize,
+			"files":      archive.Stats.NumFiles,
+		}
+		acc.AddFields("backup_snapshot", fields, tags, now)
+	}
+
+	// Count the archives in the repository
+	buf, err = b.run(r, env, "list", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var list borgList
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, fmt.Errorf("parsing list failed: %w", err)
+	}
+
+	stats := info.Cache.Stats
+	fields := map[string]interface{}{
+		"size":              stats.UniqueCompressed,
+		"uncompressed_size": stats.UniqueSize,
+		"original_size":     stats.TotalSize,
+		"chunks":            stats.TotalUniqueChunks,
+		"snapshots":         len(list.Archives),
+	}
+	if stats.UniqueCompressed > 0 {
+		fields["compression_ratio"] = float64(stats.UniqueSize) / float64(stats.UniqueCompressed)
+	}
+	return fields, nil
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type resticSnapshot struct {
+	Time     time.Time `json:"time"`
+	Paths    []string  `json:"paths"`
+	Hostname string    `json:"hostname"`
+	ShortID  string    `json:"short_id"`
+	Summary  *struct {
+		BackupStart         time.Time `json:"backup_start"`
+		BackupEnd           time.Time `json:"backup_end"`
+		DataAdded           uint64    `json:"data_added"`
+		TotalFilesProcessed uint64    `json:"total_files_processed"`
+		TotalBytesProcessed uint64    `json:"total_bytes_processed"`
+	} `json:"summary"`
+}
+
+type resticStats struct {
+	TotalSize             uint64  `json:"total_size"`
+	TotalUncompressedSize uint64  `json:"total_uncompressed_size"`
+	CompressionRatio      float64 `json:"compression_ratio"`
+	TotalBlobCount        uint64  `json:"total_blob_count"`
+	SnapshotsCount        uint64  `json:"snapshots_count"`
+}
+
+func (b *Backup) gatherRestic(acc telegraf.Accumulator, r *repository) (map[string]interface{}, error) {
+	env := []string{"RESTIC_REPOSITORY=" + r.Location}
+
+	// Get the latest snapshot for each host and path combination
+	buf, err := b.run(r, env, "snapshots", "--json", "--no-lock", "--latest", "1")
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []resticSnapshot
+	if err := json.Unmarshal(buf, &snapshots); err != nil {
+		return nil, fmt.Errorf("parsing snapshots failed: %w", err)
+	}
+
+	now := b.now()
+	for _, snapshot := range snapshots {
+		tags := map[string]string{
+			"tool":       r.Type,
+			"repository": r.Name,
+			"host":       snapshot.Hostname,
+			"paths":      strings.Join(snapshot.Paths, ","),
+		}
+		fields := map[string]interface{}{
+			"id":        snapshot.ShortID,
+			"age":       now.Sub(snapshot.Time).Seconds(),
+			"timestamp": snapshot.Time.Unix(),
+		}
+		// The summary is only available for snapshots created by restic
+		// v0.17.0 or later
+		if s := snapshot.Summary; s != nil {
+			fields["duration"] = s.BackupEnd.Sub(s.BackupStart).Seconds()
+			fields["size"] = s.TotalBytesProcessed
+			fields["added_size"] = s.DataAdded
+			fields["files"] = s.TotalFilesProcessed
+		}
+		acc.AddFields("backup_snapshot", fields, tags, now)
+	}
+
+	buf, err = b.run(r, env, "stats", "--json", "--no-lock", "--mode", "raw-data")
+	if err != nil {
+		return nil, err
+	}
+	var stats resticStats
+	if err := json.Unmarshal(buf, &stats); err != nil {
+		return nil, fmt.Errorf("parsing stats failed: %w", err)
+	}
+
+	fields := map[string]interface{}{
+		"size":              stats.TotalSize,
+		"uncompressed_size": stats.TotalUncompressedSize,
+		"compression_ratio": stats.CompressionRatio,
+		"blobs":             stats.TotalBlobCount,
+		"snapshots":         stats.SnapshotsCount,
+	}
+	return fields, nil
+}
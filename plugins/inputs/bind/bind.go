@@ -21,6 +21,7 @@ type Bind struct {
 	Urls                 []string        `toml:"urls"`
 	GatherMemoryContexts bool            `toml:"gather_memory_contexts"`
 	GatherViews          bool            `toml:"gather_views"`
+	GatherZones          bool            `toml:"gather_zones"`
 	Timeout              config.Duration `toml:"timeout"`
 	CountersAsInt        bool            `toml:"report_counters_as_int"`
 
@@ -1884,3 +1884,87 @@ func TestBindUnparsableURL(t *testing.T) {
 	err := acc.GatherError(b.Gather)
 	require.Contains(t, err.Error(), "unable to parse address")
 }
+
+func TestBindJsonZones(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+	url := ts.Listener.Addr().String()
+	host, port, err := net.SplitHostPort(url)
+	require.NoError(t, err)
+
+	b := Bind{
+		Urls:        []string{ts.URL + "/json/v1"},
+		GatherZones: true,
+		client: http.Client{
+			Timeout: 4 * time.Second,
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(b.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"bind_zone",
+			map[string]string{
+				"url":    url,
+				"source": host,
+				"port":   port,
+				"view":   "_default",
+				"zone":   "example.com",
+				"class":  "IN",
+				"type":   "primary",
+			},
+			map[string]interface{}{
+				"serial": uint64(2019040301),
+				"loaded": int64(1554276580),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"bind_zone",
+			map[string]string{
+				"url":    url,
+				"source": host,
+				"port":   port,
+				"view":   "_default",
+				"zone":   "example.org",
+				"class":  "IN",
+				"type":   "secondary",
+			},
+			map[string]interface{}{
+				"serial":  uint64(2019033001),
+				"loaded":  int64(1554276581),
+				"expires": int64(1554881381),
+				"refresh": int64(1554280181),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"bind_counter",
+			map[string]string{
+				"url":    url,
+				"source": host,
+				"port":   port,
+				"view":   "_default",
+				"zone":   "example.com",
+				"type":   "rcode",
+			},
+			map[string]interface{}{
+				"QryAuthAns":  int64(12),
+				"QryNXDOMAIN": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if _, found := m.GetTag("zone"); found {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
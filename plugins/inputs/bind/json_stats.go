@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,6 +43,24 @@ type jsonView struct {
 	Resolver map[string]map[string]int
 }
 
+type jsonZoneStats struct {
+	Views map[string]struct {
+		Zones []jsonZone
+	}
+}
+
+type jsonZone struct {
+	Name    string
+	Class   string
+	Type    string
+	Serial  json.RawMessage
+	Loaded  string
+	Expires string
+	Refresh string
+	RCodes  map[string]int
+	QTypes  map[string]int
+}
+
 // addJSONCounter adds a counter array to a Telegraf Accumulator, with the specified tags.
 func addJSONCounter(acc telegraf.Accumulator, commonTags map[string]string, stats map[string]int) {
 	grouper := metric.NewSeriesGrouper()
@@ -148,6 +167,70 @@ func (b *Bind) addStatsJSON(stats jsonStats, acc telegraf.Accumulator, urlTag st
 	}
 }
 
+// addZonesJSON adds the status and, if zone statistics are enabled in BIND,
+// the counters of each zone to the telegraf.Accumulator.
+func addZonesJSON(stats jsonZoneStats, acc telegraf.Accumulator, urlTag string) {
+	grouper := metric.NewSeriesGrouper()
+	ts := time.Now()
+	host, port, err := net.SplitHostPort(urlTag)
+	if err != nil {
+		acc.AddError(err)
+	}
+
+	for vName, view := range stats.Views {
+		for _, zone := range view.Zones {
+			tags := map[string]string{
+				"url":    urlTag,
+				"source": host,
+				"port":   port,
+				"view":   vName,
+				"zone":   zone.Name,
+				"class":  zone.Class,
+				"type":   zone.Type,
+			}
+
+			// The serial is reported as "-" for zones that are not loaded
+			fields := make(map[string]interface{}, 4)
+			if serial, err := strconv.ParseUint(string(zone.Serial), 10, 32); err == nil {
+				fields["serial"] = serial
+			}
+			for key, value := range map[string]string{"loaded": zone.Loaded, "expires": zone.Expires, "refresh": zone.Refresh} {
+				if value == "" {
+					continue
+				}
+				t, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					acc.AddError(fmt.Errorf("parsing %s time of zone %q failed: %w", key, zone.Name, err))
+					continue
+				}
+				fields[key] = t.Unix()
+			}
+			if len(fields) > 0 {
+				acc.AddGauge("bind_zone", fields, tags, ts)
+			}
+
+			for cntrType, counters := range map[string]map[string]int{"rcode": zone.RCodes, "qtype": zone.QTypes} {
+				for cntrName, value := range counters {
+					tags := map[string]string{
+						"url":    urlTag,
+						"source": host,
+						"port":   port,
+						"view":   vName,
+						"zone":   zone.Name,
+						"type":   cntrType,
+					}
+					grouper.Add("bind_counter", tags, ts, cntrName, value)
+				}
+			}
+		}
+	}
+
+	// Add grouped metrics
+	for _, groupedMetric := range grouper.Metrics() {
+		acc.AddMetric(groupedMetric)
+	}
+}
+
 // readStatsJSON takes a base URL to probe, and requests the individual statistics blobs that we
 // are interested in. These individual blobs have a combined size which is significantly smaller
 // than if we requested everything at once (e.g. taskmgr and socketmgr can be omitted).
@@ -156,32 +239,41 @@ func (b *Bind) readStatsJSON(addr *url.URL, acc telegraf.Accumulator) error {
 
 	// Progressively build up full jsonStats struct by parsing the individual HTTP responses
 	for _, suffix := range [...]string{"/server", "/net", "/mem"} {
-		err := func() error {
-			scrapeURL := addr.String() + suffix
+		if err := b.readJSON(addr.String()+suffix, &stats); err != nil {
+			return err
+		}
+	}
 
-			resp, err := b.client.Get(scrapeURL)
-			if err != nil {
-				return err
-			}
+	b.addStatsJSON(stats, acc, addr.Host)
 
-			defer resp.Body.Close()
+	// The zone list is only requested on demand as it grows with the number
+	// of zones served
+	if b.GatherZones {
+		var zones jsonZoneStats
+		if err := b.readJSON(addr.String()+"/zones", &zones); err != nil {
+			return err
+		}
+		addZonesJSON(zones, acc, addr.Host)
+	}
 
-			if resp.StatusCode != http.StatusOK {
-				return fmt.Errorf("%s returned HTTP status: %s", scrapeURL, resp.Status)
-			}
+	return nil
+}
 
-			if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
-				return fmt.Errorf("unable to decode JSON blob: %w", err)
-			}
+// readJSON requests the statistics blob at the given URL and decodes it into v
+func (b *Bind) readJSON(scrapeURL string, v interface{}) error {
+	resp, err := b.client.Get(scrapeURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-			return nil
-		}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status: %s", scrapeURL, resp.Status)
+	}
 
-		if err != nil {
-			return err
-		}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("unable to decode JSON blob: %w", err)
 	}
 
-	b.addStatsJSON(stats, acc, addr.Host)
 	return nil
 }
@@ -3,21 +3,30 @@ package cloud_pubsub
 
 import (
 	"context"
+	"crypto/tls"
 	_ "embed"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/pubsub"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
 	"google.golang.org/api/option"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -36,6 +45,7 @@ type PubSub struct {
 	CredentialsFile string `toml:"credentials_file"`
 	Project         string `toml:"project"`
 	Subscription    string `toml:"subscription"`
+	Mode            string `toml:"mode"`
 
 	// Subscription ReceiveSettings
 	MaxExtension           config.Duration `toml:"max_extension"`
@@ -50,9 +60,35 @@ type PubSub struct {
 
 	Base64Data bool `toml:"base64_data"`
 
-	ContentEncoding      string          `toml:"content_encoding"`
-	MaxDecompressionSize config.Size     `toml:"max_decompression_size"`
-	Log                  telegraf.Logger `toml:"-"`
+	ContentEncoding          string      `toml:"content_encoding"`
+	ContentEncodingAttribute string      `toml:"content_encoding_attribute"`
+	MaxDecompressionSize     config.Size `toml:"max_decompression_size"`
+
+	ParseErrorAction   string          `toml:"parse_error_action"`
+	NackDelay          config.Duration `toml:"nack_delay"`
+	NackMaxDelay       config.Duration `toml:"nack_max_delay"`
+	DeliveryAttemptTag string          `toml:"delivery_attempt_tag"`
+
+	AttributesInclude []string `toml:"attributes_include"`
+	OrderingKeyTag    string   `toml:"ordering_key_tag"`
+
+	// Subscription creation settings
+	CreateSubscription      bool             `toml:"create_subscription"`
+	Topic                   string           `toml:"topic"`
+	SubscriptionAckDeadline config.Duration  `toml:"subscription_ack_deadline"`
+	SubscriptionRetention   config.Duration  `toml:"subscription_retention"`
+	SubscriptionFilter      string           `toml:"subscription_filter"`
+	SubscriptionExpiration  *config.Duration `toml:"subscription_expiration"`
+
+	// Push subscription settings
+	ServiceAddress     string `toml:"service_address"`
+	Path               string `toml:"path"`
+	PushAudience       string `toml:"push_audience"`
+	PushServiceAccount string `toml:"push_service_account"`
+	PushSkipAuth       bool   `toml:"push_insecure_skip_authentication"`
+	common_tls.ServerConfig
+
+	Log telegraf.Logger `toml:"-"`
 
 	sub     subscription
 	stubSub func() subscription
@@ -63,10 +99,27 @@ type PubSub struct {
 	wg     *sync.WaitGroup
 	acc    telegraf.TrackingAccumulator
 
-	undelivered  map[telegraf.TrackingID]message
+	undelivered  map[telegraf.TrackingID]delivery
 	sem          semaphore
 	decoder      internal.ContentDecoder
+	decoders     map[string]internal.ContentDecoder
 	decoderMutex sync.Mutex
+
+	redelivered    selfstat.Stat
+	nacked         selfstat.Stat
+	ackFailed      selfstat.Stat
+	receiveLatency selfstat.Stat
+	ackLatency     selfstat.Stat
+
+	attributeFilter filter.Filter
+
+	tlsConf          *tls.Config
+	listener         net.Listener
+	server           *http.Server
+	subscriptionPath string
+	topicProject     string
+	topicID          string
+	validate         func(ctx context.Context, token, audience string) (*idtoken.Payload, error)
 }
 
 type (
@@ -74,6 +127,12 @@ type (
 	semaphore chan empty
 )
 
+// delivery is a message waiting for its metrics to be delivered
+type delivery struct {
+	msg      message
+	received time.Time
+}
+
 func (*PubSub) SampleConfig() string {
 	return sampleConfig
 }
@@ -87,23 +146,84 @@ func (ps *PubSub) Init() error {
 		return errors.New(`"project" is required`)
 	}
 
+	switch ps.Mode {
+	case "", "pull":
+		ps.Mode = "pull"
+	case "push":
+		if err := ps.initPush(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid value %q for mode", ps.Mode)
+	}
+
+	if ps.CreateSubscription {
+		if err := ps.initCreate(); err != nil {
+			return err
+		}
+	}
+
+	var options []internal.DecodingOption
+	if ps.MaxDecompressionSize > 0 {
+		options = append(options, internal.WithMaxDecompressionSize(int64(ps.MaxDecompressionSize)))
+	}
 	switch ps.ContentEncoding {
 	case "", "identity":
 		ps.ContentEncoding = "identity"
-	case "gzip":
+	case "gzip", "zlib", "zstd":
 		var err error
-		var options []internal.DecodingOption
-		if ps.MaxDecompressionSize > 0 {
-			options = append(options, internal.WithMaxDecompressionSize(int64(ps.MaxDecompressionSize)))
-		}
 		ps.decoder, err = internal.NewContentDecoder(ps.ContentEncoding, options...)
 		if err != nil {
 			return err
 		}
+	case "auto":
+		if ps.ContentEncodingAttribute == "" {
+			return errors.New(`"content_encoding_attribute" is required for automatic content encoding`)
+		}
+		ps.decoders = make(map[string]internal.ContentDecoder, 3)
+		for _, encoding := range []string{"gzip", "zlib", "zstd"} {
+			decoder, err := internal.NewContentDecoder(encoding, options...)
+			if err != nil {
+				return err
+			}
+			ps.decoders[encoding] = decoder
+		}
 	default:
 		return fmt.Errorf("invalid value %q for content_encoding", ps.ContentEncoding)
 	}
 
+	switch ps.ParseErrorAction {
+	case "":
+		ps.ParseErrorAction = "ack"
+	case "ack", "nack":
+	default:
+		return fmt.Errorf("invalid value %q for parse_error_action", ps.ParseErrorAction)
+	}
+	if ps.NackDelay < 0 {
+		return errors.New("nack_delay must not be negative")
+	}
+	if ps.NackMaxDelay < ps.NackDelay {
+		ps.NackMaxDelay = ps.NackDelay
+	}
+
+	if len(ps.AttributesInclude) > 0 {
+		f, err := filter.Compile(ps.AttributesInclude)
+		if err != nil {
+			return fmt.Errorf("compiling attribute filter failed: %w", err)
+		}
+		ps.attributeFilter = f
+	}
+
+	tags := map[string]string{
+		"project":      ps.Project,
+		"subscription": ps.Subscription,
+	}
+	ps.redelivered = selfstat.Register("cloud_pubsub", "messages_redelivered", tags)
+	ps.nacked = selfstat.Register("cloud_pubsub", "messages_nacked", tags)
+	ps.ackFailed = selfstat.Register("cloud_pubsub", "acks_failed", tags)
+	ps.receiveLatency = selfstat.RegisterTiming("cloud_pubsub", "receive_latency_ns", tags)
+	ps.ackLatency = selfstat.RegisterTiming("cloud_pubsub", "ack_latency_ns", tags)
+
 	return nil
 }
 
@@ -112,8 +232,8 @@ func (ps *PubSub) SetParser(parser telegraf.Parser) {
 }
 
 // Start initializes the plugin and processing messages from Google PubSub.
-// Two goroutines are started - one pulling for the subscription, one
-// receiving delivery notifications from the accumulator.
+// Two goroutines are started - one pulling for the subscription or serving
+// push requests, one receiving delivery notifications from the accumulator.
 func (ps *PubSub) Start(ac telegraf.Accumulator) error {
 	ps.sem = make(semaphore, ps.MaxUndeliveredMessages)
 	ps.acc = ac.WithTracking(ps.MaxUndeliveredMessages)
@@ -122,6 +242,20 @@ func (ps *PubSub) Start(ac telegraf.Accumulator) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	ps.cancel = cancel
 
+	ps.wg = &sync.WaitGroup{}
+	if ps.Mode == "push" {
+		if err := ps.startPush(); err != nil {
+			cancel()
+			return fmt.Errorf("starting push listener failed: %w", err)
+		}
+		ps.wg.Add(1)
+		go func() {
+			defer ps.wg.Done()
+			ps.waitForDelivery(ctx)
+		}()
+		return nil
+	}
+
 	if ps.stubSub != nil {
 		ps.sub = ps.stubSub()
 	} else {
@@ -132,7 +266,6 @@ func (ps *PubSub) Start(ac telegraf.Accumulator) error {
 		ps.sub = subRef
 	}
 
-	ps.wg = &sync.WaitGroup{}
 	// Start goroutine to handle delivery notifications from accumulator.
 	ps.wg.Add(1)
 	go func() {
@@ -158,6 +291,11 @@ func (*PubSub) Gather(telegraf.Accumulator) error {
 // Stop ensures the PubSub subscriptions receivers are stopped by
 // canceling the context and waits for goroutines to finish.
 func (ps *PubSub) Stop() {
+	if ps.server != nil {
+		// Closing the server cancels the context of all pending requests so
+		// Pub/Sub will redeliver the messages not yet acknowledged.
+		ps.server.Close()
+	}
 	ps.cancel()
 	ps.wg.Wait()
 }
@@ -201,14 +339,21 @@ func (ps *PubSub) startReceiver(parentCtx context.Context) error {
 
 // onMessage handles parsing and adding a received message to the accumulator.
 func (ps *PubSub) onMessage(ctx context.Context, msg message) error {
+	received := time.Now()
+	if published := msg.PublishTime(); !published.IsZero() {
+		// Avoid negative latencies due to clock skew
+		ps.receiveLatency.Incr(max(received.Sub(published), 0).Nanoseconds())
+	}
+
 	if ps.MaxMessageLen > 0 && len(msg.Data()) > ps.MaxMessageLen {
-		msg.Ack()
+		ps.ack(ctx, msg, received)
 		return fmt.Errorf("message longer than max_message_len (%d > %d)", len(msg.Data()), ps.MaxMessageLen)
 	}
 
-	data, err := ps.decompressData(msg.Data())
+	encoding := ps.contentEncoding(msg)
+	data, err := ps.decompressData(encoding, msg.Data())
 	if err != nil {
-		return fmt.Errorf("unable to decompress %s message: %w", ps.ContentEncoding, err)
+		return fmt.Errorf("unable to decompress %s message: %w", encoding, err)
 	}
 
 	data, err = ps.decodeB64Data(data)
@@ -216,14 +361,23 @@ func (ps *PubSub) onMessage(ctx context.Context, msg message) error {
 		return fmt.Errorf("unable to decode base64 message: %w", err)
 	}
 
+	attempt := msg.DeliveryAttempt()
+	if attempt > 1 {
+		ps.redelivered.Incr(1)
+	}
+
 	metrics, err := ps.parser.Parse(data)
 	if err != nil {
-		msg.Ack()
+		if ps.ParseErrorAction == "nack" {
+			ps.nackWithDelay(ctx, msg)
+		} else {
+			ps.ack(ctx, msg, received)
+		}
 		return fmt.Errorf("unable to parse message: %w", err)
 	}
 
 	if len(metrics) == 0 {
-		msg.Ack()
+		ps.ack(ctx, msg, received)
 
 		once.Do(func() {
 			ps.Log.Debug(internal.NoMetricsCreatedMsg)
@@ -232,6 +386,13 @@ func (ps *PubSub) onMessage(ctx context.Context, msg message) error {
 		return nil
 	}
 
+	if ps.DeliveryAttemptTag != "" && attempt > 0 {
+		for _, m := range metrics {
+			m.AddTag(ps.DeliveryAttemptTag, strconv.Itoa(attempt))
+		}
+	}
+	ps.addMessageTags(metrics, msg)
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -244,21 +405,164 @@ func (ps *PubSub) onMessage(ctx context.Context, msg message) error {
 
 	id := ps.acc.AddTrackingMetricGroup(metrics)
 	if ps.undelivered == nil {
-		ps.undelivered = make(map[telegraf.TrackingID]message)
+		ps.undelivered = make(map[telegraf.TrackingID]delivery)
 	}
-	ps.undelivered[id] = msg
+	ps.undelivered[id] = delivery{msg: msg, received: received}
 
 	return nil
 }
 
-func (ps *PubSub) decompressData(data []byte) ([]byte, error) {
-	if ps.ContentEncoding == "identity" {
+// addMessageTags adds the selected message attributes and the ordering key
+// as tags. Tags already present in the parsed metrics take precedence.
+func (ps *PubSub) addMessageTags(metrics []telegraf.Metric, msg message) {
+	tags := make(map[string]string)
+	if ps.attributeFilter != nil {
+		for k, v := range msg.Attributes() {
+			if ps.attributeFilter.Match(k) {
+				tags[k] = v
+			}
+		}
+	}
+	if ps.OrderingKeyTag != "" {
+		if key := msg.OrderingKey(); key != "" {
+			tags[ps.OrderingKeyTag] = key
+		}
+	}
+	if len(tags) == 0 {
+		return
+	}
+
+	for _, m := range metrics {
+		for k, v := range tags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+}
+
+// ack acknowledges the message and checks the result of the acknowledgement.
+// For subscriptions with exactly-once delivery the acknowledgement can fail
+// after the client library exhausted its retries of transient errors, e.g.
+// if the acknowledgement deadline expired. In this case Pub/Sub will
+// redeliver the message. The time from receiving the message to the
+// successful acknowledgement is recorded as ack latency.
+func (ps *PubSub) ack(ctx context.Context, msg message, received time.Time) {
+	result := msg.AckWithResult()
+
+	// Avoid starting a goroutine for results available immediately as it is
+	// the case for subscriptions without exactly-once delivery.
+	select {
+	case <-result.Ready():
+		ps.checkAckResult(ctx, msg.ID(), result, received)
+		return
+	default:
+	}
+
+	ps.wg.Add(1)
+	go func() {
+		defer ps.wg.Done()
+		ps.checkAckResult(ctx, msg.ID(), result, received)
+	}()
+}
+
+func (ps *PubSub) checkAckResult(ctx context.Context, id string, result ackResult, received time.Time) {
+	status, err := result.Get(ctx)
+	if status == pubsub.AcknowledgeStatusSuccess && err == nil {
+		ps.ackLatency.Incr(time.Since(received).Nanoseconds())
+		return
+	}
+	if ctx.Err() != nil {
+		// The plugin is stopping so the result is unknown
+		return
+	}
+
+	ps.ackFailed.Incr(1)
+	ps.Log.Warnf("Acknowledging message %s failed with status %q, expect a redelivery: %v", id, ackStatusName(status), err)
+}
+
+func ackStatusName(status pubsub.AcknowledgeStatus) string {
+	switch status {
+	case pubsub.AcknowledgeStatusSuccess:
+		return "success"
+	case pubsub.AcknowledgeStatusPermissionDenied:
+		return "permission denied"
+	case pubsub.AcknowledgeStatusFailedPrecondition:
+		return "failed precondition"
+	case pubsub.AcknowledgeStatusInvalidAckID:
+		return "invalid ack ID"
+	}
+	return "other"
+}
+
+// nackWithDelay negatively acknowledges the message after a delay growing
+// exponentially with the delivery attempt to avoid hot redelivery loops.
+// Push subscriptions are nacked immediately as Pub/Sub applies the retry
+// policy of the subscription to delay the redelivery.
+func (ps *PubSub) nackWithDelay(ctx context.Context, msg message) {
+	ps.nacked.Incr(1)
+
+	delay := nackBackoff(time.Duration(ps.NackDelay), time.Duration(ps.NackMaxDelay), msg.DeliveryAttempt())
+	if delay <= 0 || ps.Mode == "push" {
+		msg.Nack()
+		return
+	}
+
+	ps.wg.Add(1)
+	go func() {
+		defer ps.wg.Done()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		msg.Nack()
+	}()
+}
+
+// nackBackoff doubles the base delay for each delivery attempt after the
+// first one, limited to the given maximum. The attempt is zero if unknown.
+func nackBackoff(base, limit time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// contentEncoding returns the encoding of the message data, either the
+// configured one or, in automatic mode, the one given by the message attribute.
+// Messages without the attribute are assumed to be uncompressed.
+func (ps *PubSub) contentEncoding(msg message) string {
+	if ps.ContentEncoding != "auto" {
+		return ps.ContentEncoding
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(msg.Attributes()[ps.ContentEncodingAttribute]))
+	if encoding == "" {
+		return "identity"
+	}
+	return encoding
+}
+
+func (ps *PubSub) decompressData(encoding string, data []byte) ([]byte, error) {
+	if encoding == "identity" {
 		return data, nil
 	}
 
+	decoder := ps.decoder
+	if ps.decoders != nil {
+		var found bool
+		if decoder, found = ps.decoders[encoding]; !found {
+			return nil, errors.New("unsupported content encoding")
+		}
+	}
+
 	ps.decoderMutex.Lock()
 	defer ps.decoderMutex.Unlock()
-	data, err := ps.decoder.Decode(data)
+	data, err := decoder.Decode(data)
 	if err != nil {
 		return nil, err
 	}
@@ -285,25 +589,23 @@ func (ps *PubSub) waitForDelivery(parentCtx context.Context) {
 			return
 		case info := <-ps.acc.Delivered():
 			<-ps.sem
-			msg := ps.removeDelivered(info.ID())
-
-			if msg != nil {
-				msg.Ack()
+			if d, found := ps.removeDelivered(info.ID()); found {
+				ps.ack(parentCtx, d.msg, d.received)
 			}
 		}
 	}
 }
 
-func (ps *PubSub) removeDelivered(id telegraf.TrackingID) message {
+func (ps *PubSub) removeDelivered(id telegraf.TrackingID) (delivery, bool) {
 	ps.Lock()
 	defer ps.Unlock()
 
-	msg, ok := ps.undelivered[id]
+	d, ok := ps.undelivered[id]
 	if !ok {
-		return nil
+		return delivery{}, false
 	}
 	delete(ps.undelivered, id)
-	return msg
+	return d, true
 }
 
 func (ps *PubSub) getPubSubClient() (*pubsub.Client, error) {
@@ -338,6 +640,11 @@ func (ps *PubSub) getGCPSubscription(subID string) (subscription, error) {
 		return nil, err
 	}
 	s := client.Subscription(subID)
+	if ps.CreateSubscription {
+		if err := ps.ensureSubscription(context.Background(), client, s); err != nil {
+			return nil, err
+		}
+	}
 	s.ReceiveSettings = pubsub.ReceiveSettings{
 		NumGoroutines:          ps.MaxReceiverGoRoutines,
 		MaxExtension:           time.Duration(ps.MaxExtension),
@@ -350,7 +657,9 @@ func (ps *PubSub) getGCPSubscription(subID string) (subscription, error) {
 func init() {
 	inputs.Add("cloud_pubsub", func() telegraf.Input {
 		ps := &PubSub{
-			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+			MaxUndeliveredMessages:   defaultMaxUndeliveredMessages,
+			NackMaxDelay:             config.Duration(10 * time.Minute),
+			ContentEncodingAttribute: "content-encoding",
 		}
 		return ps
 	})
@@ -3,12 +3,17 @@ package cloud_pubsub
 import (
 	"encoding/base64"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -158,6 +163,63 @@ func TestRunGzipDecode(t *testing.T) {
 	validateTestInfluxMetric(t, metric)
 }
 
+func TestRunAutoDecode(t *testing.T) {
+	subID := "sub-run-auto"
+
+	testParser := &influx.Parser{}
+	require.NoError(t, testParser.Init())
+
+	sub := &stubSub{
+		id:       subID,
+		messages: make(chan *testMsg, 100),
+	}
+	sub.receiver = testMessagesReceive(sub)
+
+	ps := &PubSub{
+		Log:                      testutil.Logger{},
+		parser:                   testParser,
+		stubSub:                  func() subscription { return sub },
+		Project:                  "projectIDontMatterForTests",
+		Subscription:             subID,
+		MaxUndeliveredMessages:   defaultMaxUndeliveredMessages,
+		ContentEncoding:          "auto",
+		ContentEncodingAttribute: "content-encoding",
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, ps.Init())
+	require.NoError(t, ps.Start(acc))
+	defer ps.Stop()
+
+	for _, encoding := range []string{"gzip", "ZSTD", "zlib", ""} {
+		enc, err := internal.NewContentEncoder(strings.ToLower(encoding))
+		require.NoError(t, err)
+		encoded, err := enc.Encode([]byte(msgInflux))
+		require.NoError(t, err)
+
+		msg := &testMsg{
+			value:   string(encoded),
+			tracker: &testTracker{},
+		}
+		if encoding != "" {
+			msg.attributes = map[string]string{"content-encoding": encoding}
+		}
+		sub.messages <- msg
+	}
+	sub.messages <- &testMsg{
+		value:      msgInflux,
+		attributes: map[string]string{"content-encoding": "br"},
+		tracker:    &testTracker{},
+	}
+
+	acc.Wait(4)
+	acc.WaitError(1)
+	require.ErrorContains(t, acc.FirstError(), "unable to decompress br message: unsupported content encoding")
+	for _, m := range acc.Metrics {
+		validateTestInfluxMetric(t, m)
+	}
+}
+
 func TestRunInvalidMessages(t *testing.T) {
 	subID := "sub-invalid-messages"
 
@@ -298,3 +360,421 @@ func validateTestInfluxMetric(t *testing.T, m *testutil.Metric) {
 	require.InDelta(t, 23422.0, m.Fields["value"], testutil.DefaultDelta)
 	require.Equal(t, int64(1422568543702900257), m.Time.UnixNano())
 }
+
+// unregisterStats removes the global statistics of the plugin after the test
+// to start from zero on repeated runs
+func unregisterStats(t *testing.T, ps *PubSub) {
+	tags := map[string]string{
+		"project":      ps.Project,
+		"subscription": ps.Subscription,
+	}
+	t.Cleanup(func() {
+		for _, field := range []string{
+			"messages_redelivered",
+			"messages_nacked",
+			"acks_failed",
+			"receive_latency_ns",
+			"ack_latency_ns",
+		} {
+			selfstat.Unregister("cloud_pubsub", field, tags)
+		}
+	})
+}
+
+func TestRunInvalidMessagesNack(t *testing.T) {
+	subID := "sub-invalid-messages-nack"
+
+	testParser := &influx.Parser{}
+	require.NoError(t, testParser.Init())
+
+	sub := &stubSub{
+		id:       subID,
+		messages: make(chan *testMsg, 100),
+	}
+	sub.receiver = testMessagesReceive(sub)
+
+	ps := &PubSub{
+		Log:                    testutil.Logger{},
+		parser:                 testParser,
+		stubSub:                func() subscription { return sub },
+		Project:                "projectIDontMatterForTests",
+		Subscription:           subID,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		ParseErrorAction:       "nack",
+		NackDelay:              config.Duration(10 * time.Millisecond),
+	}
+	unregisterStats(t, ps)
+
+	acc := &testutil.Accumulator{}
+
+	require.NoError(t, ps.Init())
+	require.NoError(t, ps.Start(acc))
+	defer ps.Stop()
+
+	testTracker := &testTracker{}
+	msg := &testMsg{
+		value:   "~invalidInfluxMsg~",
+		attempt: 3,
+		tracker: testTracker,
+	}
+	sub.messages <- msg
+
+	acc.WaitError(1)
+
+	// Make sure we negatively acknowledged the message for redelivery
+	testTracker.waitForNack(1)
+
+	require.Equal(t, 0, acc.NFields())
+	require.Zero(t, testTracker.numAcks)
+	require.Equal(t, int64(1), ps.nacked.Get())
+	require.Equal(t, int64(1), ps.redelivered.Get())
+}
+
+func TestRunDeliveryAttemptTag(t *testing.T) {
+	subID := "sub-delivery-attempt"
+
+	testParser := &influx.Parser{}
+	require.NoError(t, testParser.Init())
+
+	sub := &stubSub{
+		id:       subID,
+		messages: make(chan *testMsg, 100),
+	}
+	sub.receiver = testMessagesReceive(sub)
+
+	ps := &PubSub{
+		Log:                    testutil.Logger{},
+		parser:                 testParser,
+		stubSub:                func() subscription { return sub },
+		Project:                "projectIDontMatterForTests",
+		Subscription:           subID,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		DeliveryAttemptTag:     "delivery_attempt",
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, ps.Init())
+	require.NoError(t, ps.Start(acc))
+	defer ps.Stop()
+
+	sub.messages <- &testMsg{
+		value:   msgInflux,
+		attempt: 2,
+		tracker: &testTracker{},
+	}
+
+	acc.Wait(1)
+	require.Equal(t, "2", acc.Metrics[0].Tags["delivery_attempt"])
+}
+
+func TestNackBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		limit    time.Duration
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			name:     "disabled",
+			attempt:  5,
+			expected: 0,
+		},
+		{
+			name:     "unknown attempt",
+			base:     time.Second,
+			limit:    time.Minute,
+			attempt:  0,
+			expected: time.Second,
+		},
+		{
+			name:     "first attempt",
+			base:     time.Second,
+			limit:    time.Minute,
+			attempt:  1,
+			expected: time.Second,
+		},
+		{
+			name:     "fourth attempt",
+			base:     time.Second,
+			limit:    time.Minute,
+			attempt:  4,
+			expected: 8 * time.Second,
+		},
+		{
+			name:     "limited",
+			base:     time.Second,
+			limit:    time.Minute,
+			attempt:  100,
+			expected: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, nackBackoff(tt.base, tt.limit, tt.attempt))
+		})
+	}
+}
+
+func TestRunAttributeTags(t *testing.T) {
+	subID := "sub-attribute-tags"
+
+	testParser := &influx.Parser{}
+	require.NoError(t, testParser.Init())
+
+	sub := &stubSub{
+		id:       subID,
+		messages: make(chan *testMsg, 100),
+	}
+	sub.receiver = testMessagesReceive(sub)
+
+	ps := &PubSub{
+		Log:                    testutil.Logger{},
+		parser:                 testParser,
+		stubSub:                func() subscription { return sub },
+		Project:                "projectIDontMatterForTests",
+		Subscription:           subID,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		AttributesInclude:      []string{"region", "app_*", "host"},
+		OrderingKeyTag:         "ordering_key",
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, ps.Init())
+	require.NoError(t, ps.Start(acc))
+	defer ps.Stop()
+
+	sub.messages <- &testMsg{
+		value: msgInflux,
+		attributes: map[string]string{
+			"region":      "europe-west1",
+			"app_name":    "frontend",
+			"app_version": "1.2.3",
+			"secret":      "discarded",
+			"host":        "not-overriding",
+		},
+		orderingKey: "server01",
+		tracker:     &testTracker{},
+	}
+
+	acc.Wait(1)
+	expected := map[string]string{
+		"host":         "server01",
+		"region":       "europe-west1",
+		"app_name":     "frontend",
+		"app_version":  "1.2.3",
+		"ordering_key": "server01",
+	}
+	require.Equal(t, expected, acc.Metrics[0].Tags)
+}
+
+func TestRunAckResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   pubsub.AcknowledgeStatus
+		expected int64
+	}{
+		{
+			name:   "success",
+			status: pubsub.AcknowledgeStatusSuccess,
+		},
+		{
+			name:     "invalid ack id",
+			status:   pubsub.AcknowledgeStatusInvalidAckID,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subID := "sub-ack-result-" + tt.name
+
+			testParser := &influx.Parser{}
+			require.NoError(t, testParser.Init())
+
+			sub := &stubSub{
+				id:       subID,
+				messages: make(chan *testMsg, 100),
+			}
+			sub.receiver = testMessagesReceive(sub)
+
+			ps := &PubSub{
+				Log:                    testutil.Logger{},
+				parser:                 testParser,
+				stubSub:                func() subscription { return sub },
+				Project:                "projectIDontMatterForTests",
+				Subscription:           subID,
+				MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+			}
+			unregisterStats(t, ps)
+
+			acc := &testutil.Accumulator{}
+			require.NoError(t, ps.Init())
+			require.NoError(t, ps.Start(acc))
+			defer ps.Stop()
+
+			tracker := &testTracker{}
+			sub.messages <- &testMsg{
+				value:     msgInflux,
+				ackStatus: tt.status,
+				tracker:   tracker,
+			}
+
+			acc.Wait(1)
+			for _, m := range acc.GetTelegrafMetrics() {
+				m.Accept()
+			}
+			tracker.waitForAck(1)
+
+			require.Eventually(t, func() bool {
+				return ps.ackFailed.Get() == tt.expected
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestRunLatencyStats(t *testing.T) {
+	subID := "sub-latency"
+
+	testParser := &influx.Parser{}
+	require.NoError(t, testParser.Init())
+
+	sub := &stubSub{
+		id:       subID,
+		messages: make(chan *testMsg, 100),
+	}
+	sub.receiver = testMessagesReceive(sub)
+
+	ps := &PubSub{
+		Log:                    testutil.Logger{},
+		parser:                 testParser,
+		stubSub:                func() subscription { return sub },
+		Project:                "projectIDontMatterForTests",
+		Subscription:           subID,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, ps.Init())
+	require.NoError(t, ps.Start(acc))
+	defer ps.Stop()
+
+	tracker := &testTracker{}
+	sub.messages <- &testMsg{
+		value:       msgInflux,
+		publishTime: time.Now().Add(-time.Minute),
+		tracker:     tracker,
+	}
+
+	acc.Wait(1)
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	tracker.waitForAck(1)
+
+	require.GreaterOrEqual(t, ps.receiveLatency.Get(), time.Minute.Nanoseconds())
+	require.Eventually(t, func() bool {
+		return ps.ackLatency.Get() > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestInitCreateSubscription(t *testing.T) {
+	day := config.Duration(24 * time.Hour)
+	hour := config.Duration(time.Hour)
+	never := config.Duration(0)
+
+	tests := []struct {
+		name     string
+		plugin   *PubSub
+		expected string
+	}{
+		{
+			name:     "missing topic",
+			plugin:   &PubSub{},
+			expected: `"topic" is required for creating the subscription`,
+		},
+		{
+			name:     "invalid topic",
+			plugin:   &PubSub{Topic: "projects/other/subscriptions/foo"},
+			expected: `invalid topic "projects/other/subscriptions/foo"`,
+		},
+		{
+			name:     "push mode",
+			plugin:   &PubSub{Mode: "push", Topic: "foo", PushAudience: "https://telegraf.example.com/push"},
+			expected: "creating subscriptions is only supported in pull mode",
+		},
+		{
+			name:     "ack deadline too long",
+			plugin:   &PubSub{Topic: "foo", SubscriptionAckDeadline: config.Duration(time.Hour)},
+			expected: "subscription_ack_deadline must be between 10s and 10m",
+		},
+		{
+			name:     "expiration too short",
+			plugin:   &PubSub{Topic: "foo", SubscriptionExpiration: &hour},
+			expected: "subscription_expiration must be zero or at least one day",
+		},
+		{
+			name:   "valid",
+			plugin: &PubSub{Topic: "foo", SubscriptionExpiration: &day},
+		},
+		{
+			name:   "never expire",
+			plugin: &PubSub{Topic: "foo", SubscriptionExpiration: &never},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Project = "my-project"
+			tt.plugin.Subscription = "my-subscription"
+			tt.plugin.CreateSubscription = true
+			err := tt.plugin.Init()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseTopic(t *testing.T) {
+	project, id, err := parseTopic("metrics", "my-project")
+	require.NoError(t, err)
+	require.Equal(t, "my-project", project)
+	require.Equal(t, "metrics", id)
+
+	project, id, err = parseTopic("projects/other-project/topics/metrics", "my-project")
+	require.NoError(t, err)
+	require.Equal(t, "other-project", project)
+	require.Equal(t, "metrics", id)
+
+	for _, topic := range []string{"other-project/metrics", "projects//topics/metrics", "projects/other-project/topics/"} {
+		_, _, err := parseTopic(topic, "my-project")
+		require.ErrorContains(t, err, "invalid topic", topic)
+	}
+}
+
+func TestSubscriptionConfig(t *testing.T) {
+	// Unset values use the defaults of Pub/Sub
+	plugin := &PubSub{}
+	cfg := plugin.subscriptionConfig(nil)
+	require.Zero(t, cfg.AckDeadline)
+	require.Zero(t, cfg.RetentionDuration)
+	require.Empty(t, cfg.Filter)
+	require.Nil(t, cfg.ExpirationPolicy)
+
+	never := config.Duration(0)
+	plugin = &PubSub{
+		SubscriptionAckDeadline: config.Duration(time.Minute),
+		SubscriptionRetention:   config.Duration(24 * time.Hour),
+		SubscriptionFilter:      `attributes.region = "eu"`,
+		SubscriptionExpiration:  &never,
+	}
+	cfg = plugin.subscriptionConfig(nil)
+	require.Equal(t, time.Minute, cfg.AckDeadline)
+	require.Equal(t, 24*time.Hour, cfg.RetentionDuration)
+	require.Equal(t, `attributes.region = "eu"`, cfg.Filter)
+	require.Equal(t, time.Duration(0), cfg.ExpirationPolicy)
+}
@@ -0,0 +1,102 @@
+package cloud_pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (ps *PubSub) initCreate() error {
+	if ps.Mode == "push" {
+		return errors.New("creating subscriptions is only supported in pull mode")
+	}
+	if ps.Topic == "" {
+		return errors.New(`"topic" is required for creating the subscription`)
+	}
+
+	var err error
+	if ps.topicProject, ps.topicID, err = parseTopic(ps.Topic, ps.Project); err != nil {
+		return err
+	}
+
+	if ps.SubscriptionAckDeadline != 0 {
+		deadline := time.Duration(ps.SubscriptionAckDeadline)
+		if deadline < 10*time.Second || deadline > 10*time.Minute {
+			return errors.New("subscription_ack_deadline must be between 10s and 10m")
+		}
+	}
+	if ps.SubscriptionRetention < 0 {
+		return errors.New("subscription_retention must not be negative")
+	}
+	if ps.SubscriptionExpiration != nil {
+		ttl := time.Duration(*ps.SubscriptionExpiration)
+		if ttl < 0 || (ttl > 0 && ttl < 24*time.Hour) {
+			return errors.New("subscription_expiration must be zero or at least one day")
+		}
+	}
+
+	return nil
+}
+
+// parseTopic returns the project and ID of the topic given either as ID in
+// the subscription's project or in the form "projects/<project>/topics/<id>".
+func parseTopic(topic, project string) (string, string, error) {
+	if !strings.HasPrefix(topic, "projects/") {
+		if strings.Contains(topic, "/") {
+			return "", "", fmt.Errorf("invalid topic %q", topic)
+		}
+		return project, topic, nil
+	}
+
+	parts := strings.Split(topic, "/")
+	if len(parts) != 4 || parts[1] == "" || parts[2] != "topics" || parts[3] == "" {
+		return "", "", fmt.Errorf("invalid topic %q", topic)
+	}
+	return parts[1], parts[3], nil
+}
+
+// subscriptionConfig returns the configuration for creating the subscription.
+// Settings not configured are left to the defaults of Pub/Sub.
+func (ps *PubSub) subscriptionConfig(topic *pubsub.Topic) pubsub.SubscriptionConfig {
+	cfg := pubsub.SubscriptionConfig{
+		Topic:             topic,
+		AckDeadline:       time.Duration(ps.SubscriptionAckDeadline),
+		RetentionDuration: time.Duration(ps.SubscriptionRetention),
+		Filter:            ps.SubscriptionFilter,
+	}
+	if ps.SubscriptionExpiration != nil {
+		// A duration of zero means the subscription never expires
+		cfg.ExpirationPolicy = time.Duration(*ps.SubscriptionExpiration)
+	}
+	return cfg
+}
+
+// ensureSubscription creates the subscription if it does not exist. Existing
+// subscriptions are used as they are, even if their settings differ.
+func (ps *PubSub) ensureSubscription(ctx context.Context, client *pubsub.Client, sub *pubsub.Subscription) error {
+	exists, err := sub.Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("checking subscription failed: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	topic := client.TopicInProject(ps.topicID, ps.topicProject)
+	if _, err := client.CreateSubscription(ctx, sub.ID(), ps.subscriptionConfig(topic)); err != nil {
+		// Another instance might have created the subscription in between
+		if status.Code(err) == codes.AlreadyExists {
+			return nil
+		}
+		return fmt.Errorf("creating subscription failed: %w", err)
+	}
+	ps.Log.Infof("Created subscription %s for topic %s", sub.ID(), topic.String())
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package cloud_pubsub
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"google.golang.org/api/idtoken"
+)
+
+// Pub/Sub messages are limited to 10MB, allow for the base64 encoding of the
+// data and the surrounding JSON envelope of push requests.
+const maxPushBodySize = 16 * 1024 * 1024
+
+// pushPayload is the wrapped push request body as documented in
+// https://cloud.google.com/pubsub/docs/push#receive_push
+type pushPayload struct {
+	Message struct {
+		Attributes  map[string]string `json:"attributes"`
+		Data        []byte            `json:"data"`
+		MessageID   string            `json:"messageId"`
+		PublishTime time.Time         `json:"publishTime"`
+		OrderingKey string            `json:"orderingKey"`
+	} `json:"message"`
+	Subscription    string `json:"subscription"`
+	DeliveryAttempt int    `json:"deliveryAttempt"`
+}
+
+// pushMessage implements the message interface for push deliveries. The
+// first acknowledgement decides about the response sent to Pub/Sub.
+type pushMessage struct {
+	payload *pushPayload
+	once    sync.Once
+	done    chan bool
+}
+
+func newPushMessage(payload *pushPayload) *pushMessage {
+	return &pushMessage{
+		payload: payload,
+		done:    make(chan bool, 1),
+	}
+}
+
+// Ack acknowledges the message, indicating successful processing.
+func (m *pushMessage) Ack() {
+	m.once.Do(func() { m.done <- true })
+}
+
+// AckWithResult acknowledges the message. Push subscriptions do not support
+// exactly-once delivery so the result is always successful.
+func (m *pushMessage) AckWithResult() ackResult {
+	m.Ack()
+	return successAckResult{}
+}
+
+// Nack negatively acknowledges the message, indicating it should be redelivered.
+func (m *pushMessage) Nack() {
+	m.once.Do(func() { m.done <- false })
+}
+
+// ID returns the unique identifier of the message.
+func (m *pushMessage) ID() string {
+	return m.payload.Message.MessageID
+}
+
+// Data returns the payload of the message.
+func (m *pushMessage) Data() []byte {
+	return m.payload.Message.Data
+}
+
+// Attributes returns the attributes of the message as a key-value map.
+func (m *pushMessage) Attributes() map[string]string {
+	return m.payload.Message.Attributes
+}
+
+// PublishTime returns the time when the message was published.
+func (m *pushMessage) PublishTime() time.Time {
+	return m.payload.Message.PublishTime
+}
+
+// DeliveryAttempt returns the number of delivery attempts or zero if unknown.
+func (m *pushMessage) DeliveryAttempt() int {
+	return m.payload.DeliveryAttempt
+}
+
+// OrderingKey returns the ordering key of the message if any.
+func (m *pushMessage) OrderingKey() string {
+	return m.payload.Message.OrderingKey
+}
+
+type successAckResult struct{}
+
+var closedChannel = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (successAckResult) Ready() <-chan struct{} {
+	return closedChannel
+}
+
+func (successAckResult) Get(context.Context) (pubsub.AcknowledgeStatus, error) {
+	return pubsub.AcknowledgeStatusSuccess, nil
+}
+
+func (ps *PubSub) initPush() error {
+	if ps.ServiceAddress == "" {
+		ps.ServiceAddress = ":8443"
+	}
+	if ps.Path == "" {
+		ps.Path = "/"
+	}
+
+	tlsConf, err := ps.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ps.tlsConf = tlsConf
+
+	// Without authentication anyone able to reach the listener can inject
+	// metrics, so require an explicit opt-out
+	if ps.PushSkipAuth {
+		if ps.PushAudience != "" || ps.PushServiceAccount != "" {
+			return errors.New(`"push_insecure_skip_authentication" cannot be used with "push_audience" or "push_service_account"`)
+		}
+		ps.Log.Warn("Authentication of push requests is disabled, anyone able to reach the listener can send metrics!")
+	} else {
+		if ps.PushAudience == "" {
+			return errors.New(`"push_audience" is required for authenticating push requests`)
+		}
+		validator, err := idtoken.NewValidator(context.Background())
+		if err != nil {
+			return fmt.Errorf("creating token validator failed: %w", err)
+		}
+		ps.validate = validator.Validate
+	}
+	ps.subscriptionPath = "projects/" + ps.Project + "/subscriptions/" + ps.Subscription
+
+	return nil
+}
+
+func (ps *PubSub) startPush() error {
+	var err error
+	if ps.tlsConf != nil {
+		ps.listener, err = tls.Listen("tcp", ps.ServiceAddress, ps.tlsConf)
+	} else {
+		ps.listener, err = net.Listen("tcp", ps.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	ps.server = &http.Server{
+		Handler:           ps,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         ps.tlsConf,
+	}
+
+	ps.wg.Add(1)
+	go func() {
+		defer ps.wg.Done()
+		if err := ps.server.Serve(ps.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ps.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+	ps.Log.Infof("Listening for push deliveries of subscription %s on %s", ps.Subscription, ps.listener.Addr())
+
+	return nil
+}
+
+// ServeHTTP implements [http.Handler] for push deliveries. Pub/Sub treats
+// success responses as acknowledgement and all others as negative
+// acknowledgement, so the response is only sent after the metrics of the
+// message were delivered to the outputs.
+func (ps *PubSub) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != ps.Path {
+		http.NotFound(res, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if ps.validate != nil {
+		if err := ps.authenticate(req); err != nil {
+			ps.Log.Debugf("Rejecting push request from %s: %v", req.RemoteAddr, err)
+			http.Error(res, "Unauthorized.", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(res, req.Body, maxPushBodySize))
+	if err != nil {
+		res.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		ps.Log.Errorf("Decoding push request failed: %v", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if payload.Subscription != ps.subscriptionPath {
+		ps.Log.Errorf("Received push request for unexpected subscription %q", payload.Subscription)
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	msg := newPushMessage(&payload)
+	if err := ps.onMessage(req.Context(), msg); err != nil {
+		ps.acc.AddError(fmt.Errorf("unable to add message from subscription %s: %w", ps.Subscription, err))
+		// Request a redelivery for all messages not explicitly acknowledged
+		// or negatively acknowledged during processing.
+		msg.Nack()
+	}
+
+	select {
+	case <-req.Context().Done():
+		res.WriteHeader(http.StatusServiceUnavailable)
+	case ack := <-msg.done:
+		if ack {
+			res.WriteHeader(http.StatusNoContent)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// authenticate verifies the OIDC token sent by Pub/Sub in the authorization
+// header, see https://cloud.google.com/pubsub/docs/authenticate-push-subscriptions
+func (ps *PubSub) authenticate(req *http.Request) error {
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return errors.New("missing bearer token")
+	}
+
+	payload, err := ps.validate(req.Context(), token, ps.PushAudience)
+	if err != nil {
+		return err
+	}
+	if payload.Issuer != "accounts.google.com" && payload.Issuer != "https://accounts.google.com" {
+		return fmt.Errorf("unexpected issuer %q", payload.Issuer)
+	}
+
+	if ps.PushServiceAccount != "" {
+		email, _ := payload.Claims["email"].(string)
+		verified, _ := payload.Claims["email_verified"].(bool)
+		if email != ps.PushServiceAccount || !verified {
+			return fmt.Errorf("unexpected service account %q", email)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package cloud_pubsub
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/idtoken"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func pushRequest(subscription, data string, attempt int) *http.Request {
+	body := fmt.Sprintf(`{
+		"message": {
+			"attributes": {"origin": "edge"},
+			"data": %q,
+			"messageId": "2070443601311540",
+			"publishTime": "2021-02-26T19:13:55.749Z",
+			"orderingKey": "sensor-1"
+		},
+		"subscription": %q,
+		"deliveryAttempt": %d
+	}`, base64.StdEncoding.EncodeToString([]byte(data)), subscription, attempt)
+	return httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body))
+}
+
+func newPushPlugin(t *testing.T) *PubSub {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	return &PubSub{
+		Log:                    testutil.Logger{},
+		parser:                 parser,
+		Project:                "my-project",
+		Subscription:           "my-subscription",
+		Mode:                   "push",
+		ServiceAddress:         "127.0.0.1:0",
+		Path:                   "/push",
+		PushSkipAuth:           true,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+	}
+}
+
+func TestPushDelivery(t *testing.T) {
+	ps := newPushPlugin(t)
+	ps.DeliveryAttemptTag = "attempt"
+	ps.AttributesInclude = []string{"origin"}
+	ps.OrderingKeyTag = "ordering_key"
+	require.NoError(t, ps.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, ps.Start(&acc))
+	defer ps.Stop()
+
+	// The response is only sent after the metrics are delivered
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ps.ServeHTTP(rec, pushRequest("projects/my-project/subscriptions/my-subscription", msgInflux, 2))
+	}()
+
+	acc.Wait(1)
+	select {
+	case <-done:
+		require.FailNow(t, "response sent before delivery")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_load_short",
+			map[string]string{
+				"host":         "server01",
+				"origin":       "edge",
+				"ordering_key": "sensor-1",
+				"attempt":      "2",
+			},
+			map[string]interface{}{"value": float64(23422)},
+			time.Unix(0, 1422568543702900257),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	for _, m := range actual {
+		m.Accept()
+	}
+	<-done
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestPushParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		expected int
+	}{
+		{
+			name:     "ack",
+			action:   "ack",
+			expected: http.StatusNoContent,
+		},
+		{
+			name:     "nack",
+			action:   "nack",
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := newPushPlugin(t)
+			ps.ParseErrorAction = tt.action
+			require.NoError(t, ps.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, ps.Start(&acc))
+			defer ps.Stop()
+
+			rec := httptest.NewRecorder()
+			ps.ServeHTTP(rec, pushRequest("projects/my-project/subscriptions/my-subscription", "invalid", 1))
+			require.Equal(t, tt.expected, rec.Code)
+			require.Len(t, acc.Errors, 1)
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestPushInvalidRequests(t *testing.T) {
+	ps := newPushPlugin(t)
+	require.NoError(t, ps.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, ps.Start(&acc))
+	defer ps.Stop()
+
+	rec := httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/other", strings.NewReader("{}")))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/push", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader("not json")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	ps.ServeHTTP(rec, pushRequest("projects/my-project/subscriptions/other", msgInflux, 1))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestPushAuthentication(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		payload *idtoken.Payload
+		err     error
+		valid   bool
+	}{
+		{
+			name:   "valid",
+			header: "Bearer token",
+			payload: &idtoken.Payload{
+				Issuer: "https://accounts.google.com",
+				Claims: map[string]interface{}{
+					"email":          "pusher@my-project.iam.gserviceaccount.com",
+					"email_verified": true,
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "missing token",
+		},
+		{
+			name:   "invalid token",
+			header: "Bearer token",
+			err:    errors.New("idtoken: token expired"),
+		},
+		{
+			name:   "wrong issuer",
+			header: "Bearer token",
+			payload: &idtoken.Payload{
+				Issuer: "https://example.com",
+				Claims: map[string]interface{}{
+					"email":          "pusher@my-project.iam.gserviceaccount.com",
+					"email_verified": true,
+				},
+			},
+		},
+		{
+			name:   "wrong service account",
+			header: "Bearer token",
+			payload: &idtoken.Payload{
+				Issuer: "accounts.google.com",
+				Claims: map[string]interface{}{
+					"email":          "other@my-project.iam.gserviceaccount.com",
+					"email_verified": true,
+				},
+			},
+		},
+		{
+			name:   "unverified service account",
+			header: "Bearer token",
+			payload: &idtoken.Payload{
+				Issuer: "accounts.google.com",
+				Claims: map[string]interface{}{
+					"email": "pusher@my-project.iam.gserviceaccount.com",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := newPushPlugin(t)
+			ps.PushSkipAuth = false
+			ps.PushAudience = "https://telegraf.example.com/push"
+			ps.PushServiceAccount = "pusher@my-project.iam.gserviceaccount.com"
+			require.NoError(t, ps.Init())
+			ps.validate = func(_ context.Context, token, audience string) (*idtoken.Payload, error) {
+				require.Equal(t, "token", token)
+				require.Equal(t, "https://telegraf.example.com/push", audience)
+				return tt.payload, tt.err
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/push", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			err := ps.authenticate(req)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPushTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+
+	ps := newPushPlugin(t)
+	ps.ServerConfig = *pki.TLSServerConfig()
+	require.NoError(t, ps.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, ps.Start(&acc))
+	defer ps.Stop()
+
+	tlsConf, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+
+	req := pushRequest("projects/my-project/subscriptions/other", msgInflux, 1)
+	req.RequestURI = ""
+	req.URL.Scheme = "https"
+	req.URL.Host = ps.listener.Addr().String()
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestPushInitErrors(t *testing.T) {
+	ps := newPushPlugin(t)
+	ps.PushSkipAuth = false
+	require.ErrorContains(t, ps.Init(), `"push_audience" is required`)
+
+	ps = newPushPlugin(t)
+	ps.PushSkipAuth = false
+	ps.PushServiceAccount = "pusher@my-project.iam.gserviceaccount.com"
+	require.ErrorContains(t, ps.Init(), `"push_audience" is required`)
+
+	ps = newPushPlugin(t)
+	ps.PushAudience = "https://telegraf.example.com/push"
+	require.ErrorContains(t, ps.Init(), "cannot be used with")
+
+	ps = newPushPlugin(t)
+	ps.Mode = "streaming"
+	require.ErrorContains(t, ps.Init(), "invalid value")
+}
@@ -18,6 +18,10 @@ type (
 	message interface {
 		// Ack acknowledges the message, indicating successful processing.
 		Ack()
+		// AckWithResult acknowledges the message and returns the result of
+		// the acknowledgement which might fail for subscriptions with
+		// exactly-once delivery.
+		AckWithResult() ackResult
 		// Nack negatively acknowledges the message, indicating it should be redelivered.
 		Nack()
 		// ID returns the unique identifier of the message.
@@ -28,6 +32,17 @@ type (
 		Attributes() map[string]string
 		// PublishTime returns the time when the message was published.
 		PublishTime() time.Time
+		// DeliveryAttempt returns the number of delivery attempts or zero if unknown.
+		DeliveryAttempt() int
+		// OrderingKey returns the ordering key of the message if any.
+		OrderingKey() string
+	}
+
+	ackResult interface {
+		// Ready returns a channel closed when the result is available.
+		Ready() <-chan struct{}
+		// Get blocks until the result is available or the context is done.
+		Get(ctx context.Context) (pubsub.AcknowledgeStatus, error)
 	}
 
 	gcpSubscription struct {
@@ -59,6 +74,13 @@ func (env *gcpMessage) Ack() {
 	env.msg.Ack()
 }
 
+// AckWithResult acknowledges the message and returns the result of the
+// acknowledgement. The result is immediately successful for subscriptions
+// without exactly-once delivery.
+func (env *gcpMessage) AckWithResult() ackResult {
+	return env.msg.AckWithResult()
+}
+
 // Nack negatively acknowledges the message, indicating it should be redelivered.
 func (env *gcpMessage) Nack() {
 	env.msg.Nack()
@@ -83,3 +105,17 @@ func (env *gcpMessage) Attributes() map[string]string {
 func (env *gcpMessage) PublishTime() time.Time {
 	return env.msg.PublishTime
 }
+
+// DeliveryAttempt returns the number of delivery attempts or zero if unknown.
+// The attempt is only known for subscriptions with a dead-letter policy.
+func (env *gcpMessage) DeliveryAttempt() int {
+	if env.msg.DeliveryAttempt == nil {
+		return 0
+	}
+	return *env.msg.DeliveryAttempt
+}
+
+// OrderingKey returns the ordering key of the message if any.
+func (env *gcpMessage) OrderingKey() string {
+	return env.msg.OrderingKey
+}
@@ -2,8 +2,11 @@ package cloud_pubsub
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
+
+	"cloud.google.com/go/pubsub"
 )
 
 type stubSub struct {
@@ -46,6 +49,9 @@ type testMsg struct {
 	value       string
 	attributes  map[string]string
 	publishTime time.Time
+	attempt     int
+	orderingKey string
+	ackStatus   pubsub.AcknowledgeStatus
 
 	tracker *testTracker
 }
@@ -54,6 +60,11 @@ func (tm *testMsg) Ack() {
 	tm.tracker.ack()
 }
 
+func (tm *testMsg) AckWithResult() ackResult {
+	tm.tracker.ack()
+	return &testAckResult{status: tm.ackStatus}
+}
+
 func (tm *testMsg) Nack() {
 	tm.tracker.nack()
 }
@@ -74,6 +85,30 @@ func (tm *testMsg) PublishTime() time.Time {
 	return tm.publishTime
 }
 
+func (tm *testMsg) DeliveryAttempt() int {
+	return tm.attempt
+}
+
+func (tm *testMsg) OrderingKey() string {
+	return tm.orderingKey
+}
+
+// testAckResult resolves asynchronously to mimic exactly-once delivery
+type testAckResult struct {
+	status pubsub.AcknowledgeStatus
+}
+
+func (*testAckResult) Ready() <-chan struct{} {
+	return nil
+}
+
+func (r *testAckResult) Get(context.Context) (pubsub.AcknowledgeStatus, error) {
+	if r.status != pubsub.AcknowledgeStatusSuccess {
+		return r.status, errors.New("PERMANENT_FAILURE_INVALID_ACK_ID")
+	}
+	return r.status, nil
+}
+
 type testTracker struct {
 	sync.Mutex
 	*sync.Cond
@@ -98,6 +133,20 @@ func (t *testTracker) ack() {
 	defer t.Unlock()
 
 	t.numAcks++
+	if t.Cond != nil {
+		t.Broadcast()
+	}
+}
+
+func (t *testTracker) waitForNack(num int) {
+	t.Lock()
+	if t.Cond == nil {
+		t.Cond = sync.NewCond(&t.Mutex)
+	}
+	for t.numNacks < num {
+		t.Wait()
+	}
+	t.Unlock()
 }
 
 func (t *testTracker) nack() {
@@ -105,4 +154,7 @@ func (t *testTracker) nack() {
 	defer t.Unlock()
 
 	t.numNacks++
+	if t.Cond != nil {
+		t.Broadcast()
+	}
 }
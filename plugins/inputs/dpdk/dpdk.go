@@ -30,6 +30,7 @@ const (
 	pluginName                 = "dpdk"
 	ethdevListCommand          = "/ethdev/list"
 	rawdevListCommand          = "/rawdev/list"
+	cryptodevListCommand       = "/cryptodev/list"
 
 	dpdkMetadataFieldPidName     = "pid"
 	dpdkMetadataFieldVersionName = "version"
@@ -46,6 +47,7 @@ type Dpdk struct {
 	DeviceTypes               []string        `toml:"device_types"`
 	EthdevConfig              ethdevConfig    `toml:"ethdev"`
 	AdditionalCommands        []string        `toml:"additional_commands"`
+	AllowedCommands           []string        `toml:"allowed_commands"`
 	MetadataFields            []string        `toml:"metadata_fields"`
 	PluginOptions             []string        `toml:"plugin_options"`
 	UnreachableSocketBehavior string          `toml:"unreachable_socket_behavior"`
@@ -54,12 +56,15 @@ type Dpdk struct {
 	connectors                   []*dpdkConnector
 	rawdevCommands               []string
 	ethdevCommands               []string
+	cryptodevCommands            []string
 	ethdevExcludedCommandsFilter filter.Filter
+	allowedCommandsFilter        filter.Filter
 	socketGlobPath               *globpath.GlobPath
 }
 
 type ethdevConfig struct {
 	EthdevExcludeCommands []string `toml:"exclude_commands"`
+	SplitQueueXstats      bool     `toml:"split_queue_xstats"`
 }
 
 func (*Dpdk) SampleConfig() string {
@@ -69,11 +74,23 @@ func (*Dpdk) SampleConfig() string {
 func (dpdk *Dpdk) Init() error {
 	dpdk.setupDefaultValues()
 
-	err := dpdk.validateAdditionalCommands()
+	var err error
+	dpdk.allowedCommandsFilter, err = filter.Compile(dpdk.AllowedCommands)
+	if err != nil {
+		return fmt.Errorf("error occurred during filter preparation for allowed commands: %w", err)
+	}
+
+	err = dpdk.validateAdditionalCommands()
 	if err != nil {
 		return err
 	}
 
+	for _, deviceType := range dpdk.DeviceTypes {
+		if err := choice.Check(deviceType, []string{"ethdev", "rawdev", "cryptodev"}); err != nil {
+			return fmt.Errorf("device_types: %w", err)
+		}
+	}
+
 	if dpdk.AccessTimeout < 0 {
 		return errors.New("socket_access_timeout should be positive number or equal to 0 (to disable timeouts)")
 	}
@@ -113,7 +130,7 @@ func (dpdk *Dpdk) Gather(acc telegraf.Accumulator) error {
 	for _, dpdkConn := range dpdk.connectors {
 		commands := dpdk.gatherCommands(acc, dpdkConn)
 		for _, command := range commands {
-			dpdkConn.processCommand(acc, dpdk.Log, command, dpdk.MetadataFields)
+			dpdkConn.processCommand(acc, dpdk.Log, command, dpdk.MetadataFields, dpdk.EthdevConfig.SplitQueueXstats)
 		}
 	}
 	return nil
@@ -151,7 +168,8 @@ func (dpdk *Dpdk) setupDefaultValues() {
 	}
 
 	dpdk.rawdevCommands = []string{"/rawdev/xstats"}
-	dpdk.ethdevCommands = []string{"/ethdev/stats", "/ethdev/xstats", "/ethdev/info", ethdevLinkStatusCommand}
+	dpdk.ethdevCommands = []string{"/ethdev/stats", ethdevXstatsCommand, "/ethdev/info", ethdevLinkStatusCommand}
+	dpdk.cryptodevCommands = []string{"/cryptodev/stats", "/cryptodev/info"}
 }
 
 func (dpdk *Dpdk) getDpdkInMemorySocketPaths() []string {
@@ -192,6 +210,10 @@ func (dpdk *Dpdk) validateAdditionalCommands() error {
 		if len(cmd) >= maxCommandLengthWithParams {
 			return fmt.Errorf("command with parameters %q shall be less than %v characters", cmd, maxCommandLengthWithParams)
 		}
+
+		if !dpdk.isCommandAllowed(cmd) {
+			return fmt.Errorf("%q command is not allowed by allowed_commands", cmd)
+		}
 	}
 
 	return nil
@@ -261,25 +283,45 @@ func (dpdk *Dpdk) gatherCommands(acc telegraf.Accumulator, dpdkConnector *dpdkCo
 	var commands []string
 	if choice.Contains("ethdev", dpdk.DeviceTypes) {
 		ethdevCommands := removeSubset(dpdk.ethdevCommands, dpdk.ethdevExcludedCommandsFilter)
-		ethdevCommands, err := dpdkConnector.appendCommandsWithParamsFromList(ethdevListCommand, ethdevCommands)
-		if err != nil {
-			acc.AddError(fmt.Errorf("error occurred during fetching of %q params: %w", ethdevListCommand, err))
-		}
-		commands = append(commands, ethdevCommands...)
+		commands = append(commands, dpdk.gatherDeviceCommands(acc, dpdkConnector, ethdevListCommand, ethdevCommands)...)
 	}
 
 	if choice.Contains("rawdev", dpdk.DeviceTypes) {
-		rawdevCommands, err := dpdkConnector.appendCommandsWithParamsFromList(rawdevListCommand, dpdk.rawdevCommands)
-		if err != nil {
-			acc.AddError(fmt.Errorf("error occurred during fetching of %q params: %w", rawdevListCommand, err))
-		}
-		commands = append(commands, rawdevCommands...)
+		commands = append(commands, dpdk.gatherDeviceCommands(acc, dpdkConnector, rawdevListCommand, dpdk.rawdevCommands)...)
+	}
+
+	if choice.Contains("cryptodev", dpdk.DeviceTypes) {
+		commands = append(commands, dpdk.gatherDeviceCommands(acc, dpdkConnector, cryptodevListCommand, dpdk.cryptodevCommands)...)
 	}
 
 	commands = append(commands, dpdk.AdditionalCommands...)
 	return uniqueValues(commands)
 }
 
+// Gathers the allowed device commands combined with the identifiers of all devices of the given list command
+func (dpdk *Dpdk) gatherDeviceCommands(acc telegraf.Accumulator, dpdkConnector *dpdkConnector, listCommand string, commands []string) []string {
+	allowed := make([]string, 0, len(commands))
+	for _, command := range commands {
+		if dpdk.isCommandAllowed(command) {
+			allowed = append(allowed, command)
+		}
+	}
+	if len(allowed) == 0 || !dpdk.isCommandAllowed(listCommand) {
+		return nil
+	}
+
+	result, err := dpdkConnector.appendCommandsWithParamsFromList(listCommand, allowed)
+	if err != nil {
+		acc.AddError(fmt.Errorf("error occurred during fetching of %q params: %w", listCommand, err))
+	}
+	return result
+}
+
+// Checks if the command without params matches the allowed commands, all commands are allowed if none are configured
+func (dpdk *Dpdk) isCommandAllowed(command string) bool {
+	return dpdk.allowedCommandsFilter == nil || dpdk.allowedCommandsFilter.Match(stripParams(command))
+}
+
 func init() {
 	inputs.Add(pluginName, func() telegraf.Input {
 		dpdk := &Dpdk{
@@ -4,6 +4,7 @@ package dpdk
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -15,6 +16,7 @@ const (
 )
 
 const (
+	ethdevXstatsCommand        = "/ethdev/xstats"
 	ethdevLinkStatusCommand    = "/ethdev/link_status"
 	linkStatusStringFieldName  = "status"
 	linkStatusIntegerFieldName = "link_status"
@@ -25,8 +27,16 @@ var (
 		"down": down,
 		"up":   up,
 	}
+
+	// Per-queue extended statistics of ethdev, e.g. "rx_q0_packets"
+	queueXstatRegex = regexp.MustCompile(`^(rx|tx)_q(\d+)_(.+)$`)
 )
 
+type queueKey struct {
+	direction string
+	queueID   string
+}
+
 func processCommandResponse(command string, data map[string]interface{}) error {
 	if command == ethdevLinkStatusCommand {
 		return processLinkStatusCmd(data)
@@ -53,3 +63,21 @@ func parseLinkStatus(s string) (linkStatus, bool) {
 	value, ok := linkStatusMap[strings.ToLower(s)]
 	return value, ok
 }
+
+// Moves the per-queue extended statistics from data to separate field sets per queue
+func splitQueueXstats(data map[string]interface{}) map[queueKey]map[string]interface{} {
+	queues := make(map[queueKey]map[string]interface{})
+	for name, value := range data {
+		match := queueXstatRegex.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		key := queueKey{direction: match[1], queueID: match[2]}
+		if _, found := queues[key]; !found {
+			queues[key] = make(map[string]interface{})
+		}
+		queues[key][match[3]] = value
+		delete(data, name)
+	}
+	return queues
+}
@@ -18,7 +18,7 @@ func Test_LinkStatusCommand(t *testing.T) {
 		response := fmt.Sprintf(`{%q:{%q: "DOWN"}}`, ethdevLinkStatusCommand, linkStatusStringFieldName)
 		simulateResponse(mockConn, response, nil)
 		dpdkConn := dpdk.connectors[0]
-		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil)
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil, false)
 
 		expected := []telegraf.Metric{
 			testutil.MustMetric(
@@ -45,7 +45,7 @@ func Test_LinkStatusCommand(t *testing.T) {
 		response := fmt.Sprintf(`{%q:{%q: "UP"}}`, ethdevLinkStatusCommand, linkStatusStringFieldName)
 		simulateResponse(mockConn, response, nil)
 		dpdkConn := dpdk.connectors[0]
-		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil)
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil, false)
 
 		expected := []telegraf.Metric{
 			testutil.MustMetric(
@@ -72,7 +72,7 @@ func Test_LinkStatusCommand(t *testing.T) {
 		response := fmt.Sprintf(`{%q:{}}`, ethdevLinkStatusCommand)
 		simulateResponse(mockConn, response, nil)
 		dpdkConn := dpdk.connectors[0]
-		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil)
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil, false)
 
 		actual := mockAcc.GetTelegrafMetrics()
 		testutil.RequireMetricsEqual(t, nil, actual, testutil.IgnoreTime())
@@ -84,7 +84,7 @@ func Test_LinkStatusCommand(t *testing.T) {
 		response := fmt.Sprintf(`{%q:{"tag1": 1}}`, ethdevLinkStatusCommand)
 		simulateResponse(mockConn, response, nil)
 		dpdkConn := dpdk.connectors[0]
-		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil)
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil, false)
 		expected := []telegraf.Metric{
 			testutil.MustMetric(
 				"dpdk",
@@ -109,7 +109,7 @@ func Test_LinkStatusCommand(t *testing.T) {
 		response := fmt.Sprintf(`{%q:{%q: "BOB"}}`, ethdevLinkStatusCommand, linkStatusStringFieldName)
 		simulateResponse(mockConn, response, nil)
 		dpdkConn := dpdk.connectors[0]
-		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil)
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevLinkStatusCommand+",1", nil, false)
 
 		expected := []telegraf.Metric{
 			testutil.MustMetric(
@@ -129,3 +129,65 @@ func Test_LinkStatusCommand(t *testing.T) {
 		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 	})
 }
+
+func Test_QueueXstats(t *testing.T) {
+	t.Run("when splitting is enabled then per-queue xstats should be separate metrics", func(t *testing.T) {
+		mockConn, dpdk, mockAcc := prepareEnvironment()
+		defer mockConn.AssertExpectations(t)
+		response := `{"/ethdev/xstats":{"rx_good_packets":10,"rx_q0_packets":4,"rx_q0_bytes":400,"rx_q1_packets":6,"tx_q0_errors":1}}`
+		simulateResponse(mockConn, response, nil)
+		dpdkConn := dpdk.connectors[0]
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevXstatsCommand+",0", nil, true)
+
+		expected := []telegraf.Metric{
+			testutil.MustMetric(
+				"dpdk",
+				map[string]string{"command": ethdevXstatsCommand, "params": "0"},
+				map[string]interface{}{"rx_good_packets": float64(10)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"dpdk",
+				map[string]string{"command": ethdevXstatsCommand, "params": "0", "direction": "rx", "queue_id": "0"},
+				map[string]interface{}{"packets": float64(4), "bytes": float64(400)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"dpdk",
+				map[string]string{"command": ethdevXstatsCommand, "params": "0", "direction": "rx", "queue_id": "1"},
+				map[string]interface{}{"packets": float64(6)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"dpdk",
+				map[string]string{"command": ethdevXstatsCommand, "params": "0", "direction": "tx", "queue_id": "0"},
+				map[string]interface{}{"errors": float64(1)},
+				time.Unix(0, 0),
+			),
+		}
+
+		actual := mockAcc.GetTelegrafMetrics()
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+	})
+
+	t.Run("when splitting is disabled then per-queue xstats should be kept", func(t *testing.T) {
+		mockConn, dpdk, mockAcc := prepareEnvironment()
+		defer mockConn.AssertExpectations(t)
+		response := `{"/ethdev/xstats":{"rx_good_packets":10,"rx_q0_packets":4}}`
+		simulateResponse(mockConn, response, nil)
+		dpdkConn := dpdk.connectors[0]
+		dpdkConn.processCommand(mockAcc, testutil.Logger{}, ethdevXstatsCommand+",0", nil, false)
+
+		expected := []telegraf.Metric{
+			testutil.MustMetric(
+				"dpdk",
+				map[string]string{"command": ethdevXstatsCommand, "params": "0"},
+				map[string]interface{}{"rx_good_packets": float64(10), "rx_q0_packets": float64(4)},
+				time.Unix(0, 0),
+			),
+		}
+
+		actual := mockAcc.GetTelegrafMetrics()
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	})
+}
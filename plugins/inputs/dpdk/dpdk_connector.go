@@ -100,45 +100,63 @@ func (conn *dpdkConnector) appendCommandsWithParamsFromList(listCommand string,
 	return result, nil
 }
 
+// Executes command using provided connection and returns response
+// If the connection broke, e.g. because the DPDK application was restarted, the command is retried once
+// on a new connection. Timed out commands are not retried.
+func (conn *dpdkConnector) getCommandResponse(fullCommand string) ([]byte, error) {
+	buf, retry, err := conn.executeCommand(fullCommand)
+	if err == nil || !retry {
+		return buf, err
+	}
+
+	buf, _, retryErr := conn.executeCommand(fullCommand)
+	if retryErr != nil {
+		return nil, fmt.Errorf("%w; retrying on new connection failed: %w", err, retryErr)
+	}
+	return buf, nil
+}
+
 // Executes command using provided connection and returns response
 // If error (such as timeout) occurred, then connection is discarded and recreated
 // because otherwise behavior of connection is undefined (e.g. it could return result of timed out command instead of latest)
-func (conn *dpdkConnector) getCommandResponse(fullCommand string) ([]byte, error) {
+// The returned flag indicates that the connection broke and the command can be retried.
+func (conn *dpdkConnector) executeCommand(fullCommand string) ([]byte, bool, error) {
 	connection, err := conn.getConnection()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get connection to execute %q command: %w", fullCommand, err)
+		return nil, false, fmt.Errorf("failed to get connection to execute %q command: %w", fullCommand, err)
 	}
 
 	err = conn.setTimeout()
 	if err != nil {
-		return nil, fmt.Errorf("failed to set timeout for %q command: %w", fullCommand, err)
+		return nil, false, fmt.Errorf("failed to set timeout for %q command: %w", fullCommand, err)
 	}
 
 	_, err = connection.Write([]byte(fullCommand))
 	if err != nil {
 		if closeErr := conn.tryClose(); closeErr != nil {
-			return nil, fmt.Errorf("failed to send %q command: %w and failed to close connection: %w", fullCommand, err, closeErr)
+			return nil, false, fmt.Errorf("failed to send %q command: %w and failed to close connection: %w", fullCommand, err, closeErr)
 		}
-		return nil, fmt.Errorf("failed to send %q command: %w", fullCommand, err)
+		return nil, !isTimeout(err), fmt.Errorf("failed to send %q command: %w", fullCommand, err)
 	}
 
 	buf := make([]byte, conn.initMessage.MaxOutputLen)
 	messageLength, err := connection.Read(buf)
 	if err != nil {
 		if closeErr := conn.tryClose(); closeErr != nil {
-			return nil, fmt.Errorf("failed read response of %q command: %w and failed to close connection: %w", fullCommand, err, closeErr)
+			return nil, false, fmt.Errorf("failed read response of %q command: %w and failed to close connection: %w", fullCommand, err, closeErr)
 		}
-		return nil, fmt.Errorf("failed to read response of %q command: %w", fullCommand, err)
+		return nil, !isTimeout(err), fmt.Errorf("failed to read response of %q command: %w", fullCommand, err)
 	}
 
 	if messageLength == 0 {
-		return nil, fmt.Errorf("got empty response during execution of %q command", fullCommand)
+		return nil, false, fmt.Errorf("got empty response during execution of %q command", fullCommand)
 	}
-	return buf[:messageLength], nil
+	return buf[:messageLength], false, nil
 }
 
 // Executes command, parses response and creates/writes metrics from response to accumulator
-func (conn *dpdkConnector) processCommand(acc telegraf.Accumulator, log telegraf.Logger, commandWithParams string, metadataFields []string) {
+// If splitQueues is set, the per-queue extended statistics of ethdev are written as separate metrics per queue
+func (conn *dpdkConnector) processCommand(acc telegraf.Accumulator, log telegraf.Logger, commandWithParams string, metadataFields []string, splitQueues bool) {
 	buf, err := conn.getCommandResponse(commandWithParams)
 	if err != nil {
 		acc.AddError(err)
@@ -171,13 +189,26 @@ func (conn *dpdkConnector) processCommand(acc telegraf.Accumulator, log telegraf
 		log.Warnf("Failed to process a response of the command: %s. Error: %v. Continue to handle data", command, err)
 	}
 
+	params := getParams(commandWithParams)
+	if splitQueues && command == ethdevXstatsCommand {
+		for key, fields := range splitQueueXstats(jf.Fields) {
+			conn.addMetadataFields(metadataFields, fields)
+			acc.AddFields(pluginName, fields, map[string]string{
+				"command":   command,
+				"params":    params,
+				"direction": key.direction,
+				"queue_id":  key.queueID,
+			})
+		}
+	}
+
 	// Add metadata fields if required
 	conn.addMetadataFields(metadataFields, jf.Fields)
 
 	// Add common fields
 	acc.AddFields(pluginName, jf.Fields, map[string]string{
 		"command": command,
-		"params":  getParams(commandWithParams),
+		"params":  params,
 	})
 }
 
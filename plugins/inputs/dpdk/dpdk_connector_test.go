@@ -5,7 +5,9 @@ package dpdk
 import (
 	"encoding/json"
 	"errors"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -184,6 +186,70 @@ func Test_getCommandResponse(t *testing.T) {
 	})
 }
 
+func Test_getCommandResponseReconnect(t *testing.T) {
+	t.Run("should retry command on new connection if connection broke", func(t *testing.T) {
+		pathToSocket, socket := createSocketForTest(t, "")
+		go func() {
+			conn, err := socket.Accept()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+
+			initMessage, err := json.Marshal(initMessage{Pid: 2, Version: "restarted", MaxOutputLen: 1024})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := conn.Write(initMessage); err != nil {
+				t.Error(err)
+				return
+			}
+
+			buf := make([]byte, 1024)
+			n, err := conn.Read(buf)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := conn.Write([]byte(`{"` + string(buf[:n]) + `":1}`)); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		mockConn := &mocks.Conn{}
+		defer mockConn.AssertExpectations(t)
+		mockConn.On("SetDeadline", mock.Anything).Return(nil)
+		mockConn.On("Write", mock.Anything).Return(0, errors.New("broken pipe"))
+		mockConn.On("Close").Return(nil)
+		connector := &dpdkConnector{
+			pathToSocket:  pathToSocket,
+			accessTimeout: 2 * time.Second,
+			connection:    mockConn,
+			initMessage:   &initMessage{Pid: 1, MaxOutputLen: 1024},
+		}
+
+		buf, err := connector.getCommandResponse("/ethdev/list")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"/ethdev/list":1}`, string(buf))
+		require.Equal(t, 2, connector.initMessage.Pid)
+		require.NoError(t, connector.tryClose())
+	})
+
+	t.Run("should not retry command if timeout occurred", func(t *testing.T) {
+		mockConn, dpdk, _ := prepareEnvironment()
+		defer mockConn.AssertExpectations(t)
+		mockConn.On("SetDeadline", mock.Anything).Return(nil)
+		mockConn.On("Write", mock.Anything).Return(0, os.ErrDeadlineExceeded)
+		mockConn.On("Close").Return(nil)
+
+		_, err := dpdk.connectors[0].getCommandResponse("/")
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+		require.NotContains(t, err.Error(), "retrying")
+	})
+}
+
 func Test_processCommand(t *testing.T) {
 	t.Run("should pass if received valid response", func(t *testing.T) {
 		mockConn, dpdk, mockAcc := prepareEnvironment()
@@ -192,7 +258,7 @@ func Test_processCommand(t *testing.T) {
 		simulateResponse(mockConn, response, nil)
 
 		for _, dpdkConn := range dpdk.connectors {
-			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil)
+			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil, false)
 		}
 
 		require.Empty(t, mockAcc.Errors)
@@ -205,7 +271,7 @@ func Test_processCommand(t *testing.T) {
 		simulateResponse(mockConn, response, nil)
 
 		for _, dpdkConn := range dpdk.connectors {
-			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil)
+			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil, false)
 		}
 
 		require.Len(t, mockAcc.Errors, 1)
@@ -219,7 +285,7 @@ func Test_processCommand(t *testing.T) {
 		mockConn.On("SetDeadline", mock.Anything).Return(nil)
 		mockConn.On("Close").Return(nil)
 		for _, dpdkConn := range dpdk.connectors {
-			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil)
+			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/", nil, false)
 		}
 
 		require.Len(t, mockAcc.Errors, 1)
@@ -232,7 +298,7 @@ func Test_processCommand(t *testing.T) {
 		response := `{"/test": null}`
 		simulateResponse(mockConn, response, nil)
 		for _, dpdkConn := range dpdk.connectors {
-			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/test,param", nil)
+			dpdkConn.processCommand(mockAcc, testutil.Logger{}, "/test,param", nil, false)
 		}
 
 		require.Empty(t, mockAcc.Errors)
@@ -110,6 +110,25 @@ func Test_Init(t *testing.T) {
 	})
 }
 
+func Test_InitAllowedCommands(t *testing.T) {
+	t.Run("when additional command is not allowed then error should be returned", func(t *testing.T) {
+		dpdk := Dpdk{
+			AdditionalCommands: []string{"/ethdev/stats,0", "/l3fwd-power/stats"},
+			AllowedCommands:    []string{"/ethdev/*"},
+			Log:                testutil.Logger{},
+		}
+		require.ErrorContains(t, dpdk.Init(), `"/l3fwd-power/stats" command is not allowed by allowed_commands`)
+	})
+
+	t.Run("when device type is unknown then error should be returned", func(t *testing.T) {
+		dpdk := Dpdk{
+			DeviceTypes: []string{"ethdev", "eventdev"},
+			Log:         testutil.Logger{},
+		}
+		require.ErrorContains(t, dpdk.Init(), "device_types")
+	})
+}
+
 func Test_Start(t *testing.T) {
 	t.Run("when socket doesn't exist err should be returned", func(t *testing.T) {
 		dpdk := Dpdk{
@@ -785,6 +804,74 @@ func Test_Gather(t *testing.T) {
 	})
 }
 
+func Test_Gather_DeviceCommands(t *testing.T) {
+	responses := map[string]string{
+		"/ethdev/list":       `{"/ethdev/list":[0]}`,
+		"/ethdev/stats,0":    `{"/ethdev/stats":{"ipackets":10}}`,
+		"/ethdev/xstats,0":   `{"/ethdev/xstats":{"rx_good_packets":10}}`,
+		"/cryptodev/list":    `{"/cryptodev/list":[1]}`,
+		"/cryptodev/stats,1": `{"/cryptodev/stats":{"enqueued_count":5,"dequeued_count":4}}`,
+		"/cryptodev/info,1":  `{"/cryptodev/info":{"device_name":"crypto_aesni_mb","max_nb_queue_pairs":8}}`,
+	}
+
+	mockConn, dpdk, mockAcc := prepareEnvironment()
+	defer mockConn.AssertExpectations(t)
+	var commands []string
+	mockConn.On("SetDeadline", mock.Anything).Return(nil)
+	mockConn.On("Write", mock.Anything).Run(func(arg mock.Arguments) {
+		commands = append(commands, string(arg.Get(0).([]byte)))
+	}).Return(0, nil)
+	mockConn.On("Read", mock.Anything).Return(func(buf []byte) int {
+		return copy(buf, responses[commands[len(commands)-1]])
+	}, nil)
+
+	dpdk.DeviceTypes = []string{"ethdev", "cryptodev"}
+	dpdk.AllowedCommands = []string{"/ethdev/list", "/ethdev/*stats", "/cryptodev/*"}
+	dpdk.MetadataFields = []string{}
+	dpdk.PluginOptions = []string{}
+	dpdk.UnreachableSocketBehavior = unreachableSocketBehaviorIgnore
+	require.NoError(t, dpdk.Init())
+
+	// Keep the mocked connection as no socket exists
+	dpdk.SocketPath = ""
+	require.NoError(t, dpdk.Gather(mockAcc))
+	require.Empty(t, mockAcc.Errors)
+
+	// Disallowed commands like "/ethdev/info" and "/ethdev/link_status" are not executed
+	require.ElementsMatch(t, []string{
+		"/ethdev/list", "/ethdev/stats,0", "/ethdev/xstats,0",
+		"/cryptodev/list", "/cryptodev/stats,1", "/cryptodev/info,1",
+	}, commands)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"dpdk",
+			map[string]string{"command": "/ethdev/stats", "params": "0"},
+			map[string]interface{}{"ipackets": float64(10)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"dpdk",
+			map[string]string{"command": "/ethdev/xstats", "params": "0"},
+			map[string]interface{}{"rx_good_packets": float64(10)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"dpdk",
+			map[string]string{"command": "/cryptodev/stats", "params": "1"},
+			map[string]interface{}{"enqueued_count": float64(5), "dequeued_count": float64(4)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"dpdk",
+			map[string]string{"command": "/cryptodev/info", "params": "1"},
+			map[string]interface{}{"device_name": "crypto_aesni_mb", "max_nb_queue_pairs": float64(8)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, mockAcc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func Test_Gather_MultiSocket(t *testing.T) {
 	t.Run("Test Gather without Metadata Fields", func(t *testing.T) {
 		mockConns, dpdk, mockAcc := prepareEnvironmentWithMultiSockets()
@@ -0,0 +1,158 @@
+//go:build linux
+
+package env_sensors
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Registers of the Bosch BME280, see the datasheet for details
+const (
+	bme280RegCalib1   = 0x88
+	bme280RegChipID   = 0xd0
+	bme280RegCalib2   = 0xe1
+	bme280RegCtrlHum  = 0xf2
+	bme280RegStatus   = 0xf3
+	bme280RegCtrlMeas = 0xf4
+	bme280RegData     = 0xf7
+
+	bme280ChipID = 0x60
+)
+
+type bme280Calibration struct {
+	t1                             uint16
+	t2, t3                         int16
+	p1                             uint16
+	p2, p3, p4, p5, p6, p7, p8, p9 int16
+	h1                             uint8
+	h2                             int16
+	h3                             uint8
+	h4, h5                         int16
+	h6                             int8
+}
+
+// readBME280 triggers a single measurement in forced mode and returns the
+// temperature in degree Celsius, the relative humidity in percent and the
+// pressure in hPa
+func readBME280(dev i2cDevice) (map[string]float64, error) {
+	id := make([]byte, 1)
+	if err := dev.tx([]byte{bme280RegChipID}, id); err != nil {
+		return nil, fmt.Errorf("reading chip ID failed: %w", err)
+	}
+	if id[0] != bme280ChipID {
+		return nil, fmt.Errorf("unexpected chip ID 0x%02x", id[0])
+	}
+
+	calib1 := make([]byte, 26)
+	if err := dev.tx([]byte{bme280RegCalib1}, calib1); err != nil {
+		return nil, fmt.Errorf("reading calibration failed: %w", err)
+	}
+	calib2 := make([]byte, 7)
+	if err := dev.tx([]byte{bme280RegCalib2}, calib2); err != nil {
+		return nil, fmt.Errorf("reading calibration failed: %w", err)
+	}
+	calib := parseBME280Calibration(calib1, calib2)
+
+	// Use oversampling x1 for all measurements and start a measurement in
+	// forced mode; the humidity control only takes effect after writing the
+	// measurement control register
+	if err := dev.tx([]byte{bme280RegCtrlHum, 0x01}, nil); err != nil {
+		return nil, fmt.Errorf("configuring humidity failed: %w", err)
+	}
+	if err := dev.tx([]byte{bme280RegCtrlMeas, 0x25}, nil); err != nil {
+		return nil, fmt.Errorf("starting measurement failed: %w", err)
+	}
+
+	// The measurement takes at most 10ms with the above settings
+	status := make([]byte, 1)
+	for range 10 {
+		time.Sleep(10 * time.Millisecond)
+		if err := dev.tx([]byte{bme280RegStatus}, status); err != nil {
+			return nil, fmt.Errorf("reading status failed: %w", err)
+		}
+		if status[0]&0x08 == 0 {
+			break
+		}
+	}
+	if status[0]&0x08 != 0 {
+		return nil, errors.New("timeout waiting for measurement")
+	}
+
+	data := make([]byte, 8)
+	if err := dev.tx([]byte{bme280RegData}, data); err != nil {
+		return nil, fmt.Errorf("reading data failed: %w", err)
+	}
+	return calib.compensate(data), nil
+}
+
+func parseBME280Calibration(calib1, calib2 []byte) *bme280Calibration {
+	le := binary.LittleEndian
+	return &bme280Calibration{
+		t1: le.Uint16(calib1[0:]),
+		t2: int16(le.Uint16(calib1[2:])),
+		t3: int16(le.Uint16(calib1[4:])),
+		p1: le.Uint16(calib1[6:]),
+		p2: int16(le.Uint16(calib1[8:])),
+		p3: int16(le.Uint16(calib1[10:])),
+		p4: int16(le.Uint16(calib1[12:])),
+		p5: int16(le.Uint16(calib1[14:])),
+		p6: int16(le.Uint16(calib1[16:])),
+		p7: int16(le.Uint16(calib1[18:])),
+		p8: int16(le.Uint16(calib1[20:])),
+		p9: int16(le.Uint16(calib1[22:])),
+		h1: calib1[25],
+		h2: int16(le.Uint16(calib2[0:])),
+		h3: calib2[2],
+		// The 12-bit values share the nibbles of the register at 0xe5 and
+		// are signed
+		h4: int16(int8(calib2[3]))<<4 | int16(calib2[4]&0x0f),
+		h5: int16(int8(calib2[5]))<<4 | int16(calib2[4]>>4),
+		h6: int8(calib2[6]),
+	}
+}
+
+// compensate converts the raw data using the floating-point formulas of the
+// datasheet
+func (c *bme280Calibration) compensate(data []byte) map[string]float64 {
+	rawP := float64(uint32(data[0])<<12 | uint32(data[1])<<4 | uint32(data[2])>>4)
+	rawT := float64(uint32(data[3])<<12 | uint32(data[4])<<4 | uint32(data[5])>>4)
+	rawH := float64(uint32(data[6])<<8 | uint32(data[7]))
+
+	// Temperature
+	v1 := (rawT/16384.0 - float64(c.t1)/1024.0) * float64(c.t2)
+	v2 := (rawT/131072.0 - float64(c.t1)/8192.0) * (rawT/131072.0 - float64(c.t1)/8192.0) * float64(c.t3)
+	tfine := v1 + v2
+	temperature := tfine / 5120.0
+
+	// Pressure
+	var pressure float64
+	v1 = tfine/2.0 - 64000.0
+	v2 = v1 * v1 * float64(c.p6) / 32768.0
+	v2 += v1 * float64(c.p5) * 2.0
+	v2 = v2/4.0 + float64(c.p4)*65536.0
+	v1 = (float64(c.p3)*v1*v1/524288.0 + float64(c.p2)*v1) / 524288.0
+	v1 = (1.0 + v1/32768.0) * float64(c.p1)
+	if v1 != 0 {
+		p := 1048576.0 - rawP
+		p = (p - v2/4096.0) * 6250.0 / v1
+		v1 = float64(c.p9) * p * p / 2147483648.0
+		v2 = p * float64(c.p8) / 32768.0
+		pressure = (p + (v1+v2+float64(c.p7))/16.0) / 100.0
+	}
+
+	// Humidity
+	h := tfine - 76800.0
+	h = (rawH - (float64(c.h4)*64.0 + float64(c.h5)/16384.0*h)) *
+		(float64(c.h2) / 65536.0 * (1.0 + float64(c.h6)/67108864.0*h*(1.0+float64(c.h3)/67108864.0*h)))
+	h *= 1.0 - float64(c.h1)*h/524288.0
+	humidity := min(max(h, 0), 100)
+
+	return map[string]float64{
+		"temperature": temperature,
+		"humidity":    humidity,
+		"pressure":    pressure,
+	}
+}
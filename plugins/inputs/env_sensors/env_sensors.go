@@ -0,0 +1,227 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package env_sensors
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type EnvSensors struct {
+	OneWirePath         string          `toml:"onewire_path"`
+	OneWireAutodiscover bool            `toml:"onewire_autodiscover"`
+	Sensors             []*Sensor       `toml:"sensor"`
+	Log                 telegraf.Logger `toml:"-"`
+
+	// Function to open an I2C device, replaceable for testing
+	openI2C func(bus int, address uint16) (i2cDevice, error)
+}
+
+// Sensor is the configuration of a single sensor including its calibration
+type Sensor struct {
+	Type              string  `toml:"type"`
+	ID                string  `toml:"id"`
+	Bus               int     `toml:"bus"`
+	Address           uint16  `toml:"address"`
+	Name              string  `toml:"name"`
+	TemperatureOffset float64 `toml:"temperature_offset"`
+	HumidityOffset    float64 `toml:"humidity_offset"`
+	PressureOffset    float64 `toml:"pressure_offset"`
+}
+
+func (*EnvSensors) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EnvSensors) Init() error {
+	for i, s := range e.Sensors {
+		switch s.Type {
+		case "ds18b20":
+			if s.ID == "" {
+				return fmt.Errorf("sensor %d: id required for type %q", i+1, s.Type)
+			}
+		case "bme280":
+			if s.Address == 0 {
+				s.Address = 0x76
+			}
+		case "sht3x":
+			if s.Address == 0 {
+				s.Address = 0x44
+			}
+		case "":
+			return fmt.Errorf("sensor %d: type required", i+1)
+		default:
+			return fmt.Errorf("sensor %d: unknown type %q", i+1, s.Type)
+		}
+		if s.Bus < 0 {
+			return fmt.Errorf("sensor %d: invalid bus %d", i+1, s.Bus)
+		}
+	}
+
+	if e.openI2C == nil {
+		e.openI2C = openI2CDevice
+	}
+
+	return nil
+}
+
+func (e *EnvSensors) Gather(acc telegraf.Accumulator) error {
+	sensors := e.Sensors
+	if e.OneWireAutodiscover {
+		discovered, err := e.discoverOneWire()
+		if err != nil {
+			acc.AddError(fmt.Errorf("discovering 1-Wire sensors failed: %w", err))
+		}
+		sensors = append(sensors, discovered...)
+	}
+
+	for _, s := range sensors {
+		values, err := e.read(s)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading sensor %q failed: %w", s.identifier(), err))
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			switch k {
+			case "temperature":
+				v += s.TemperatureOffset
+			case "humidity":
+				v = min(max(v+s.HumidityOffset, 0), 100)
+			case "pressure":
+				v += s.PressureOffset
+			}
+			fields[k] = v
+		}
+		tags := map[string]string{
+			"sensor": s.identifier(),
+			"type":   s.Type,
+		}
+		acc.AddFields("env_sensors", fields, tags)
+	}
+
+	return nil
+}
+
+// read returns the uncalibrated values of the sensor
+func (e *EnvSensors) read(s *Sensor) (map[string]float64, error) {
+	if s.Type == "ds18b20" {
+		temperature, err := readOneWire(filepath.Join(e.OneWirePath, s.ID, "w1_slave"))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]float64{"temperature": temperature}, nil
+	}
+
+	dev, err := e.openI2C(s.Bus, s.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer dev.close()
+
+	switch s.Type {
+	case "bme280":
+		return readBME280(dev)
+	case "sht3x":
+		return readSHT3x(dev)
+	}
+	return nil, fmt.Errorf("unknown type %q", s.Type)
+}
+
+// discoverOneWire returns the DS18B20 sensors found in the 1-Wire device
+// directory which are not explicitly configured
+func (e *EnvSensors) discoverOneWire() ([]*Sensor, error) {
+	matches, err := filepath.Glob(filepath.Join(e.OneWirePath, "28-*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		if _, err := os.Stat(e.OneWirePath); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("directory %q not found, is the w1-gpio module loaded", e.OneWirePath)
+		}
+	}
+
+	configured := make(map[string]bool, len(e.Sensors))
+	for _, s := range e.Sensors {
+		if s.Type == "ds18b20" {
+			configured[s.ID] = true
+		}
+	}
+
+	sensors := make([]*Sensor, 0, len(matches))
+	for _, match := range matches {
+		id := filepath.Base(match)
+		if configured[id] {
+			continue
+		}
+		sensors = append(sensors, &Sensor{Type: "ds18b20", ID: id})
+	}
+	return sensors, nil
+}
+
+// identifier returns the name of the sensor or a name derived from its
+// address if no name is configured
+func (s *Sensor) identifier() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.Type == "ds18b20" {
+		return s.ID
+	}
+	return fmt.Sprintf("i2c-%d-0x%02x", s.Bus, s.Address)
+}
+
+// readOneWire reads the temperature in degree Celsius from the "w1_slave"
+// file of the w1_therm kernel driver
+func readOneWire(path string) (float64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return parseOneWire(string(buf))
+}
+
+// parseOneWire parses the content of a "w1_slave" file, e.g.
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+func parseOneWire(content string) (float64, error) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) != 2 {
+		return 0, fmt.Errorf("unexpected content %q", content)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return 0, errors.New("checksum mismatch")
+	}
+	_, raw, found := strings.Cut(lines[1], "t=")
+	if !found {
+		return 0, fmt.Errorf("missing temperature in %q", lines[1])
+	}
+
+	var millidegrees int64
+	if _, err := fmt.Sscanf(strings.TrimSpace(raw), "%d", &millidegrees); err != nil {
+		return 0, fmt.Errorf("invalid temperature %q: %w", raw, err)
+	}
+	return float64(millidegrees) / 1000.0, nil
+}
+
+func init() {
+	inputs.Add("env_sensors", func() telegraf.Input {
+		return &EnvSensors{
+			OneWirePath:         "/sys/bus/w1/devices",
+			OneWireAutodiscover: true,
+		}
+	})
+}
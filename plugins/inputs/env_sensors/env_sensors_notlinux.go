@@ -0,0 +1,35 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package env_sensors
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type EnvSensors struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*EnvSensors) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EnvSensors) Init() error {
+	e.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*EnvSensors) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("env_sensors", func() telegraf.Input {
+		return &EnvSensors{}
+	})
+}
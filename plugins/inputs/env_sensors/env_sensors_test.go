@@ -0,0 +1,265 @@
+//go:build linux
+
+package env_sensors
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		sensor   *Sensor
+		expected string
+	}{
+		{
+			name:     "missing type",
+			sensor:   &Sensor{ID: "28-0000054c2ec2"},
+			expected: "sensor 1: type required",
+		},
+		{
+			name:     "unknown type",
+			sensor:   &Sensor{Type: "dht22"},
+			expected: `sensor 1: unknown type "dht22"`,
+		},
+		{
+			name:     "missing id",
+			sensor:   &Sensor{Type: "ds18b20"},
+			expected: `sensor 1: id required for type "ds18b20"`,
+		},
+		{
+			name:     "invalid bus",
+			sensor:   &Sensor{Type: "sht3x", Bus: -1},
+			expected: "sensor 1: invalid bus -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &EnvSensors{Sensors: []*Sensor{tt.sensor}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseOneWire(t *testing.T) {
+	value, err := parseOneWire("72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n")
+	require.NoError(t, err)
+	require.InDelta(t, 23.125, value, 1e-9)
+
+	value, err = parseOneWire("5e ff 4b 46 7f ff 02 10 5d : crc=5d YES\n5e ff 4b 46 7f ff 02 10 5d t=-10125\n")
+	require.NoError(t, err)
+	require.InDelta(t, -10.125, value, 1e-9)
+
+	_, err = parseOneWire("72 01 4b 46 7f ff 0e 10 57 : crc=00 NO\n72 01 4b 46 7f ff 0e 10 57 t=23125\n")
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = parseOneWire("")
+	require.Error(t, err)
+}
+
+func TestGatherOneWire(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"28-0000054c2ec2": "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n",
+		"28-0000054d1a7f": "91 01 4b 46 7f ff 0f 10 25 : crc=25 YES\n91 01 4b 46 7f ff 0f 10 25 t=25062\n",
+		"28-0000054e0000": "00 00 00 00 00 00 00 00 00 : crc=12 NO\n00 00 00 00 00 00 00 00 00 t=0\n",
+	}
+	for id, content := range files {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, id), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, id, "w1_slave"), []byte(content), 0640))
+	}
+	// The bus master must not be discovered as sensor
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "w1_bus_master1"), 0750))
+
+	plugin := &EnvSensors{
+		OneWirePath:         dir,
+		OneWireAutodiscover: true,
+		Sensors: []*Sensor{
+			{
+				Type:              "ds18b20",
+				ID:                "28-0000054c2ec2",
+				Name:              "outdoor",
+				TemperatureOffset: -0.5,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"env_sensors",
+			map[string]string{"sensor": "outdoor", "type": "ds18b20"},
+			map[string]interface{}{"temperature": 22.625},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"env_sensors",
+			map[string]string{"sensor": "28-0000054d1a7f", "type": "ds18b20"},
+			map[string]interface{}{"temperature": 25.062},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `reading sensor "28-0000054e0000" failed: checksum mismatch`)
+}
+
+func TestGatherOneWireMissingDirectory(t *testing.T) {
+	plugin := &EnvSensors{
+		OneWirePath:         filepath.Join(t.TempDir(), "missing"),
+		OneWireAutodiscover: true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "is the w1-gpio module loaded")
+}
+
+func TestSHT3xChecksum(t *testing.T) {
+	// Example from the Sensirion datasheet
+	require.Equal(t, byte(0x92), crc8([]byte{0xbe, 0xef}))
+
+	_, err := parseSHT3x([]byte{0x66, 0x66, 0x00, 0x80, 0x00, 0x00})
+	require.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestBME280Compensation(t *testing.T) {
+	// Calibration and raw values of the compensation example in the BMP280
+	// datasheet extended by typical humidity calibration values
+	calib := &bme280Calibration{
+		t1: 27504, t2: 26435, t3: -1000,
+		p1: 36477, p2: -10685, p3: 3024, p4: 2855, p5: 140, p6: -7, p7: 15500, p8: -14600, p9: 6000,
+		h1: 75, h2: 362, h3: 0, h4: 313, h5: 50, h6: 30,
+	}
+	rawT := uint32(519888)
+	rawP := uint32(415148)
+	rawH := uint32(30000)
+	data := []byte{
+		byte(rawP >> 12), byte(rawP >> 4), byte(rawP << 4),
+		byte(rawT >> 12), byte(rawT >> 4), byte(rawT << 4),
+		byte(rawH >> 8), byte(rawH),
+	}
+
+	values := calib.compensate(data)
+	require.InDelta(t, 25.08, values["temperature"], 0.01)
+	require.InDelta(t, 1006.53, values["pressure"], 0.01)
+	require.Greater(t, values["humidity"], 0.0)
+	require.Less(t, values["humidity"], 100.0)
+}
+
+func TestParseBME280Calibration(t *testing.T) {
+	calib1 := make([]byte, 26)
+	le := binary.LittleEndian
+	le.PutUint16(calib1[0:], 27504)
+	le.PutUint16(calib1[2:], 26435)
+	le.PutUint16(calib1[4:], uint16(0xfc18)) // -1000
+	calib1[25] = 75
+	// H2 = 362, H3 = 0, H4 = 313 (0x139), H5 = -50 (0xfce), H6 = 30
+	calib2 := []byte{0x6a, 0x01, 0x00, 0x13, 0xe9, 0xfc, 0x1e}
+
+	calib := parseBME280Calibration(calib1, calib2)
+	require.Equal(t, uint16(27504), calib.t1)
+	require.Equal(t, int16(26435), calib.t2)
+	require.Equal(t, int16(-1000), calib.t3)
+	require.Equal(t, uint8(75), calib.h1)
+	require.Equal(t, int16(362), calib.h2)
+	require.Equal(t, uint8(0), calib.h3)
+	require.Equal(t, int16(313), calib.h4)
+	require.Equal(t, int16(-50), calib.h5)
+	require.Equal(t, int8(30), calib.h6)
+}
+
+func TestGatherI2C(t *testing.T) {
+	// SHT3x measurement with 25 °C and 50 % relative humidity
+	sht3x := []byte{0x66, 0x66, 0x00, 0x80, 0x00, 0x00}
+	sht3x[2] = crc8(sht3x[0:2])
+	sht3x[5] = crc8(sht3x[3:5])
+
+	devices := map[uint16]*fakeDevice{
+		0x44: {
+			respond: func(_ []byte) []byte { return sht3x },
+		},
+		0x45: {
+			respond: func(w []byte) []byte {
+				if len(w) == 1 && w[0] == bme280RegChipID {
+					return []byte{0x58}
+				}
+				return nil
+			},
+		},
+	}
+
+	plugin := &EnvSensors{
+		Sensors: []*Sensor{
+			{Type: "sht3x", Bus: 1, HumidityOffset: 60},
+			{Type: "bme280", Bus: 1, Address: 0x45},
+			{Type: "sht3x", Bus: 2},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.openI2C = func(bus int, address uint16) (i2cDevice, error) {
+		if bus != 1 {
+			return nil, errors.New("no such device")
+		}
+		return devices[address], nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{"sensor": "i2c-1-0x44", "type": "sht3x"}, metrics[0].Tags())
+	temperature, _ := metrics[0].GetField("temperature")
+	require.InDelta(t, 25.0, temperature, 0.01)
+	humidity, _ := metrics[0].GetField("humidity")
+	require.InDelta(t, 100.0, humidity, 1e-9)
+
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], `reading sensor "i2c-1-0x45" failed: unexpected chip ID 0x58`)
+	require.ErrorContains(t, acc.Errors[1], `reading sensor "i2c-2-0x44" failed: no such device`)
+	require.True(t, devices[0x44].closed)
+	require.True(t, devices[0x45].closed)
+}
+
+type fakeDevice struct {
+	respond func(w []byte) []byte
+	last    []byte
+	closed  bool
+}
+
+func (d *fakeDevice) tx(w, r []byte) error {
+	if len(w) > 0 {
+		d.last = w
+	}
+	if len(r) > 0 {
+		copy(r, d.respond(d.last))
+	}
+	return nil
+}
+
+func (d *fakeDevice) close() error {
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package env_sensors
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ioctl request to set the address of the target device, see
+// linux/i2c-dev.h
+const i2cSlave = 0x0703
+
+// i2cDevice is a device on an I2C bus
+type i2cDevice interface {
+	// tx writes the given bytes to the device and reads the response into
+	// the given buffer afterwards, any of the two might be empty
+	tx(w, r []byte) error
+	close() error
+}
+
+type i2cFile struct {
+	f *os.File
+}
+
+// openI2CDevice opens the device with the given address on the bus using the
+// i2c-dev kernel interface
+func openI2CDevice(bus int, address uint16) (i2cDevice, error) {
+	f, err := os.OpenFile(fmt.Sprintf("/dev/i2c-%d", bus), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.IoctlSetInt(int(f.Fd()), i2cSlave, int(address)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("setting address 0x%02x failed: %w", address, err)
+	}
+	return &i2cFile{f: f}, nil
+}
+
+func (d *i2cFile) tx(w, r []byte) error {
+	if len(w) > 0 {
+		if _, err := d.f.Write(w); err != nil {
+			return err
+		}
+	}
+	if len(r) > 0 {
+		if _, err := io.ReadFull(d.f, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *i2cFile) close() error {
+	return d.f.Close()
+}
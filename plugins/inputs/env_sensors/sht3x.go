@@ -0,0 +1,58 @@
+//go:build linux
+
+package env_sensors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// readSHT3x triggers a single-shot measurement with high repeatability and
+// returns the temperature in degree Celsius and the relative humidity in
+// percent
+func readSHT3x(dev i2cDevice) (map[string]float64, error) {
+	if err := dev.tx([]byte{0x24, 0x00}, nil); err != nil {
+		return nil, fmt.Errorf("starting measurement failed: %w", err)
+	}
+
+	// The measurement takes at most 15.5ms with high repeatability
+	time.Sleep(20 * time.Millisecond)
+
+	data := make([]byte, 6)
+	if err := dev.tx(nil, data); err != nil {
+		return nil, fmt.Errorf("reading data failed: %w", err)
+	}
+	return parseSHT3x(data)
+}
+
+func parseSHT3x(data []byte) (map[string]float64, error) {
+	if crc8(data[0:2]) != data[2] || crc8(data[3:5]) != data[5] {
+		return nil, errors.New("checksum mismatch")
+	}
+
+	rawT := float64(uint16(data[0])<<8 | uint16(data[1]))
+	rawH := float64(uint16(data[3])<<8 | uint16(data[4]))
+
+	return map[string]float64{
+		"temperature": -45.0 + 175.0*rawT/65535.0,
+		"humidity":    100.0 * rawH / 65535.0,
+	}, nil
+}
+
+// crc8 computes the checksum used by Sensirion sensors with the polynomial
+// 0x31 and the initial value 0xff
+func crc8(data []byte) byte {
+	crc := byte(0xff)
+	for _, b := range data {
+		crc ^= b
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,360 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package frr
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	bgpSummaryCommand    = "show bgp summary json"
+	ospfNeighborCommand  = "show ip ospf neighbor json"
+	ipv4RoutesCommand    = "show ip route summary json"
+	ipv6RoutesCommand    = "show ipv6 route summary json"
+	establishedPeerState = "Established"
+)
+
+// BGP peer states as defined in RFC 4271 and the BGP4-MIB
+var bgpStates = map[string]int64{
+	"Idle":        1,
+	"Connect":     2,
+	"Active":      3,
+	"OpenSent":    4,
+	"OpenConfirm": 5,
+	"Established": 6,
+}
+
+// OSPF neighbor states as defined in the OSPF-MIB
+var ospfStates = map[string]int64{
+	"Down":     1,
+	"Attempt":  2,
+	"Init":     3,
+	"2-Way":    4,
+	"ExStart":  5,
+	"Exchange": 6,
+	"Loading":  7,
+	"Full":     8,
+}
+
+type FRR struct {
+	Binary  string          `toml:"binary"`
+	Timeout config.Duration `toml:"timeout"`
+	UseSudo bool            `toml:"use_sudo"`
+	Collect []string        `toml:"collect"`
+	Log     telegraf.Logger `toml:"-"`
+
+	run runner
+
+	// state of the previous gather for detecting flaps and churn
+	peers         map[peerKey]peerState
+	tableVersions map[tableKey]int64
+}
+
+type runner func(binary string, timeout config.Duration, useSudo bool, command string) ([]byte, error)
+
+type peerKey struct {
+	vrf  string
+	afi  string
+	peer string
+}
+
+type peerState struct {
+	state   string
+	dropped int64
+}
+
+type tableKey struct {
+	vrf string
+	afi string
+}
+
+func (*FRR) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FRR) Init() error {
+	if f.Binary == "" {
+		f.Binary = "/usr/bin/vtysh"
+	}
+	if f.Timeout <= 0 {
+		f.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(f.Collect) == 0 {
+		f.Collect = []string{"bgp", "ospf", "routes"}
+	}
+	for _, c := range f.Collect {
+		switch c {
+		case "bgp", "ospf", "routes":
+		default:
+			return fmt.Errorf("invalid value %q in 'collect'", c)
+		}
+	}
+
+	f.peers = make(map[peerKey]peerState)
+	f.tableVersions = make(map[tableKey]int64)
+
+	return nil
+}
+
+func (f *FRR) Gather(acc telegraf.Accumulator) error {
+	for _, c := range f.Collect {
+		var err error
+		switch c {
+		case "bgp":
+			err = f.gatherBGP(acc)
+		case "ospf":
+			err = f.gatherOSPF(acc)
+		case "routes":
+			err = f.gatherRoutes(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s failed: %w", c, err))
+		}
+	}
+	return nil
+}
+
+func (f *FRR) query(command string, v interface{}) error {
+	out, err := f.run(f.Binary, f.Timeout, f.UseSudo, command)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("decoding output of %q failed: %w", command, err)
+	}
+	return nil
+}
+
+func (f *FRR) gatherBGP(acc telegraf.Accumulator) error {
+	var summaries map[string]bgpSummary
+	if err := f.query(bgpSummaryCommand, &summaries); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	seen := make(map[peerKey]bool)
+	for family, summary := range summaries {
+		afi := internal.SnakeCase(family)
+		vrf := summary.VrfName
+		if vrf == "" {
+			vrf = "default"
+		}
+
+		tags := map[string]string{
+			"vrf":            vrf,
+			"address_family": afi,
+			"router_id":      summary.RouterID,
+			"local_as":       fmt.Sprintf("%d", summary.AS),
+		}
+		fields := map[string]interface{}{
+			"table_version": summary.TableVersion,
+			"rib_count":     summary.RibCount,
+			"peer_count":    summary.PeerCount,
+			"failed_peers":  summary.FailedPeers,
+		}
+
+		// The table version is incremented on every route change so its
+		// difference reflects the route churn since the last gather
+		tk := tableKey{vrf: vrf, afi: afi}
+		if prev, found := f.tableVersions[tk]; found && summary.TableVersion >= prev {
+			fields["route_changes"] = summary.TableVersion - prev
+		}
+		f.tableVersions[tk] = summary.TableVersion
+		acc.AddFields("frr_bgp", fields, tags, now)
+
+		for addr, peer := range summary.Peers {
+			state := normalizeBGPState(peer.State)
+			ptags := map[string]string{
+				"vrf":            vrf,
+				"address_family": afi,
+				"peer":           addr,
+				"remote_as":      fmt.Sprintf("%d", peer.RemoteAS),
+			}
+			if peer.Hostname != "" {
+				ptags["peer_hostname"] = peer.Hostname
+			}
+			pfields := map[string]interface{}{
+				"state":                   state,
+				"messages_received":       peer.MsgRcvd,
+				"messages_sent":           peer.MsgSent,
+				"in_queue":                peer.InQ,
+				"out_queue":               peer.OutQ,
+				"prefixes_received":       peer.PfxRcd,
+				"prefixes_sent":           peer.PfxSnt,
+				"uptime_ms":               peer.PeerUptimeMsec,
+				"connections_established": peer.ConnectionsEstablished,
+				"connections_dropped":     peer.ConnectionsDropped,
+			}
+			if code, found := bgpStates[state]; found {
+				pfields["state_code"] = code
+			}
+			acc.AddFields("frr_bgp_peer", pfields, ptags, now)
+
+			pk := peerKey{vrf: vrf, afi: afi, peer: addr}
+			seen[pk] = true
+			current := peerState{state: state, dropped: peer.ConnectionsDropped}
+			if prev, found := f.peers[pk]; found && isFlap(prev, current) {
+				acc.AddFields(
+					"frr_bgp_session_flap",
+					map[string]interface{}{
+						"previous_state": prev.state,
+						"state":          state,
+						"drops":          max(current.dropped-prev.dropped, 0),
+					},
+					ptags,
+					now,
+				)
+			}
+			f.peers[pk] = current
+		}
+	}
+
+	// Forget peers removed from the configuration
+	for pk := range f.peers {
+		if !seen[pk] {
+			delete(f.peers, pk)
+		}
+	}
+
+	return nil
+}
+
+func (f *FRR) gatherOSPF(acc telegraf.Accumulator) error {
+	var response ospfNeighbors
+	if err := f.query(ospfNeighborCommand, &response); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	for id, neighbors := range response.Neighbors {
+		for _, n := range neighbors {
+			// The state is reported as "<state>/<role>" e.g. "Full/DR"
+			state, role, _ := strings.Cut(n.State, "/")
+			if n.NbrState != "" {
+				state, role, _ = strings.Cut(n.NbrState, "/")
+			}
+			if n.Role != "" {
+				role = n.Role
+			}
+
+			address := n.IfaceAddress
+			if address == "" {
+				address = n.Address
+			}
+			iface, _, _ := strings.Cut(n.IfaceName, ":")
+
+			tags := map[string]string{
+				"neighbor_id": id,
+				"address":     address,
+				"interface":   iface,
+			}
+			if role != "" {
+				tags["role"] = role
+			}
+			fields := map[string]interface{}{
+				"state":              state,
+				"priority":           n.Priority,
+				"dead_time_ms":       n.DeadTimeMsecs,
+				"retransmit_counter": n.RetransmitCounter,
+				"request_counter":    n.RequestCounter,
+				"db_summary_counter": n.DBSummaryCounter,
+			}
+			if n.UpTimeInMsec > 0 {
+				fields["uptime_ms"] = n.UpTimeInMsec
+			}
+			if code, found := ospfStates[state]; found {
+				fields["state_code"] = code
+			}
+			acc.AddFields("frr_ospf_neighbor", fields, tags, now)
+		}
+	}
+
+	return nil
+}
+
+func (f *FRR) gatherRoutes(acc telegraf.Accumulator) error {
+	for afi, command := range map[string]string{"ipv4": ipv4RoutesCommand, "ipv6": ipv6RoutesCommand} {
+		var summary routeSummary
+		if err := f.query(command, &summary); err != nil {
+			return err
+		}
+		now := time.Now()
+
+		for _, r := range summary.Routes {
+			tags := map[string]string{
+				"address_family": afi,
+				"type":           r.Type,
+			}
+			fields := map[string]interface{}{
+				"rib": r.RIB,
+				"fib": r.FIB,
+			}
+			acc.AddFields("frr_routes", fields, tags, now)
+		}
+		acc.AddFields(
+			"frr_routes",
+			map[string]interface{}{
+				"rib": summary.RoutesTotal,
+				"fib": summary.RoutesTotalFib,
+			},
+			map[string]string{
+				"address_family": afi,
+				"type":           "total",
+			},
+			now,
+		)
+	}
+
+	return nil
+}
+
+// normalizeBGPState strips annotations like "Idle (Admin)" or "Idle (PfxCt)"
+// from the peer state
+func normalizeBGPState(state string) string {
+	s, _, _ := strings.Cut(state, " ")
+	return s
+}
+
+// isFlap reports whether the BGP session went down or reconnected between
+// two observations of the same peer
+func isFlap(prev, current peerState) bool {
+	if prev.state != current.state && (prev.state == establishedPeerState || current.state == establishedPeerState) {
+		return true
+	}
+	// The session might have dropped and re-established within the interval
+	return current.dropped > prev.dropped
+}
+
+func vtysh(binary string, timeout config.Duration, useSudo bool, command string) ([]byte, error) {
+	cmd := exec.Command(binary, "-c", command)
+	if useSudo {
+		cmd = exec.Command("sudo", "-n", binary, "-c", command)
+	}
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %q failed: %w - %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+func init() {
+	inputs.Add("frr", func() telegraf.Input {
+		return &FRR{
+			Binary:  "/usr/bin/vtysh",
+			Timeout: config.Duration(5 * time.Second),
+			run:     vtysh,
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package frr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func mockRunner(t *testing.T, files map[string]string) runner {
+	return func(_ string, _ config.Duration, _ bool, command string) ([]byte, error) {
+		fn, found := files[command]
+		if !found {
+			return nil, errors.New("command not supported")
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		require.NoError(t, err)
+		return buf, nil
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &FRR{Collect: []string{"isis"}}
+	require.ErrorContains(t, plugin.Init(), `invalid value "isis"`)
+}
+
+func TestGatherOSPF(t *testing.T) {
+	plugin := &FRR{
+		Collect: []string{"ospf"},
+		Log:     &testutil.Logger{},
+		run:     mockRunner(t, map[string]string{ospfNeighborCommand: "ospf_neighbor.json"}),
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"frr_ospf_neighbor",
+			map[string]string{
+				"neighbor_id": "10.0.0.2",
+				"address":     "192.168.1.2",
+				"interface":   "eth0",
+				"role":        "DR",
+			},
+			map[string]interface{}{
+				"state":              "Full",
+				"state_code":         int64(8),
+				"priority":           int64(1),
+				"uptime_ms":          int64(86400000),
+				"dead_time_ms":       int64(35000),
+				"retransmit_counter": int64(0),
+				"request_counter":    int64(0),
+				"db_summary_counter": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"frr_ospf_neighbor",
+			map[string]string{
+				"neighbor_id": "10.0.0.4",
+				"address":     "192.168.2.4",
+				"interface":   "eth1",
+				"role":        "DROther",
+			},
+			map[string]interface{}{
+				"state":              "Init",
+				"state_code":         int64(3),
+				"priority":           int64(0),
+				"dead_time_ms":       int64(31000),
+				"retransmit_counter": int64(2),
+				"request_counter":    int64(1),
+				"db_summary_counter": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherRoutes(t *testing.T) {
+	plugin := &FRR{
+		Collect: []string{"routes"},
+		Log:     &testutil.Logger{},
+		run: mockRunner(t, map[string]string{
+			ipv4RoutesCommand: "ip_route_summary.json",
+			ipv6RoutesCommand: "ipv6_route_summary.json",
+		}),
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New("frr_routes", map[string]string{"address_family": "ipv4", "type": "connected"},
+			map[string]interface{}{"rib": int64(2), "fib": int64(2)}, time.Unix(0, 0)),
+		metric.New("frr_routes", map[string]string{"address_family": "ipv4", "type": "local"},
+			map[string]interface{}{"rib": int64(2), "fib": int64(2)}, time.Unix(0, 0)),
+		metric.New("frr_routes", map[string]string{"address_family": "ipv4", "type": "bgp"},
+			map[string]interface{}{"rib": int64(6), "fib": int64(5)}, time.Unix(0, 0)),
+		metric.New("frr_routes", map[string]string{"address_family": "ipv4", "type": "total"},
+			map[string]interface{}{"rib": int64(10), "fib": int64(9)}, time.Unix(0, 0)),
+		metric.New("frr_routes", map[string]string{"address_family": "ipv6", "type": "connected"},
+			map[string]interface{}{"rib": int64(1), "fib": int64(1)}, time.Unix(0, 0)),
+		metric.New("frr_routes", map[string]string{"address_family": "ipv6", "type": "total"},
+			map[string]interface{}{"rib": int64(1), "fib": int64(1)}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherBGPFlap(t *testing.T) {
+	files := map[string]string{bgpSummaryCommand: "bgp_summary.json"}
+	plugin := &FRR{
+		Collect: []string{"bgp"},
+		Log:     &testutil.Logger{},
+		run:     mockRunner(t, files),
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first gather only reports the current state
+	expected := []telegraf.Metric{
+		metric.New(
+			"frr_bgp",
+			map[string]string{
+				"vrf":            "default",
+				"address_family": "ipv4_unicast",
+				"router_id":      "10.0.0.1",
+				"local_as":       "65001",
+			},
+			map[string]interface{}{
+				"table_version": int64(12),
+				"rib_count":     int64(9),
+				"peer_count":    int64(2),
+				"failed_peers":  int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"frr_bgp_peer",
+			map[string]string{
+				"vrf":            "default",
+				"address_family": "ipv4_unicast",
+				"peer":           "10.0.0.2",
+				"peer_hostname":  "r2",
+				"remote_as":      "65002",
+			},
+			map[string]interface{}{
+				"state":                   "Established",
+				"state_code":              int64(6),
+				"messages_received":       int64(120),
+				"messages_sent":           int64(118),
+				"in_queue":                int64(0),
+				"out_queue":               int64(0),
+				"prefixes_received":       int64(5),
+				"prefixes_sent":           int64(4),
+				"uptime_ms":               int64(3723000),
+				"connections_established": int64(1),
+				"connections_dropped":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"frr_bgp_peer",
+			map[string]string{
+				"vrf":            "default",
+				"address_family": "ipv4_unicast",
+				"peer":           "10.0.0.3",
+				"remote_as":      "65003",
+			},
+			map[string]interface{}{
+				"state":                   "Idle",
+				"state_code":              int64(1),
+				"messages_received":       int64(0),
+				"messages_sent":           int64(0),
+				"in_queue":                int64(0),
+				"out_queue":               int64(0),
+				"prefixes_received":       int64(0),
+				"prefixes_sent":           int64(0),
+				"uptime_ms":               int64(0),
+				"connections_established": int64(0),
+				"connections_dropped":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The session to r2 dropped and re-established within the interval
+	files[bgpSummaryCommand] = "bgp_summary_flap.json"
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	actual := acc.GetTelegrafMetrics()
+	var flaps []telegraf.Metric
+	for _, m := range actual {
+		switch m.Name() {
+		case "frr_bgp":
+			v, found := m.GetField("route_changes")
+			require.True(t, found)
+			require.Equal(t, int64(8), v)
+		case "frr_bgp_session_flap":
+			flaps = append(flaps, m)
+		}
+	}
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"frr_bgp_session_flap",
+			map[string]string{
+				"vrf":            "default",
+				"address_family": "ipv4_unicast",
+				"peer":           "10.0.0.2",
+				"peer_hostname":  "r2",
+				"remote_as":      "65002",
+			},
+			map[string]interface{}{
+				"previous_state": "Established",
+				"state":          "Established",
+				"drops":          int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, flaps, testutil.IgnoreTime())
+}
+
+func TestIsFlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     peerState
+		current  peerState
+		expected bool
+	}{
+		{
+			name:    "unchanged",
+			prev:    peerState{state: "Established"},
+			current: peerState{state: "Established"},
+		},
+		{
+			name:     "session down",
+			prev:     peerState{state: "Established"},
+			current:  peerState{state: "Active"},
+			expected: true,
+		},
+		{
+			name:     "session up",
+			prev:     peerState{state: "OpenConfirm"},
+			current:  peerState{state: "Established"},
+			expected: true,
+		},
+		{
+			name:    "connecting",
+			prev:    peerState{state: "Connect"},
+			current: peerState{state: "Active"},
+		},
+		{
+			name:     "dropped in between",
+			prev:     peerState{state: "Established", dropped: 3},
+			current:  peerState{state: "Established", dropped: 4},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, isFlap(tt.prev, tt.current))
+		})
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	plugin := &FRR{
+		Log: &testutil.Logger{},
+		run: mockRunner(t, map[string]string{ospfNeighborCommand: "ospf_neighbor.json"}),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "gathering bgp failed")
+	require.ErrorContains(t, acc.Errors[1], "gathering routes failed")
+}
@@ -0,0 +1,61 @@
+package frr
+
+// bgpSummary is the per address-family section of "show bgp summary json"
+type bgpSummary struct {
+	RouterID     string             `json:"routerId"`
+	AS           int64              `json:"as"`
+	VrfName      string             `json:"vrfName"`
+	TableVersion int64              `json:"tableVersion"`
+	RibCount     int64              `json:"ribCount"`
+	PeerCount    int64              `json:"peerCount"`
+	FailedPeers  int64              `json:"failedPeers"`
+	Peers        map[string]bgpPeer `json:"peers"`
+}
+
+type bgpPeer struct {
+	Hostname               string `json:"hostname"`
+	RemoteAS               int64  `json:"remoteAs"`
+	MsgRcvd                int64  `json:"msgRcvd"`
+	MsgSent                int64  `json:"msgSent"`
+	InQ                    int64  `json:"inq"`
+	OutQ                   int64  `json:"outq"`
+	PeerUptimeMsec         int64  `json:"peerUptimeMsec"`
+	PfxRcd                 int64  `json:"pfxRcd"`
+	PfxSnt                 int64  `json:"pfxSnt"`
+	State                  string `json:"state"`
+	ConnectionsEstablished int64  `json:"connectionsEstablished"`
+	ConnectionsDropped     int64  `json:"connectionsDropped"`
+}
+
+// ospfNeighbors is the output of "show ip ospf neighbor json"
+type ospfNeighbors struct {
+	Neighbors map[string][]ospfNeighbor `json:"neighbors"`
+}
+
+type ospfNeighbor struct {
+	Priority          int64  `json:"priority"`
+	State             string `json:"state"`
+	NbrState          string `json:"nbrState"`
+	Role              string `json:"role"`
+	UpTimeInMsec      int64  `json:"upTimeInMsec"`
+	DeadTimeMsecs     int64  `json:"deadTimeMsecs"`
+	Address           string `json:"address"`
+	IfaceAddress      string `json:"ifaceAddress"`
+	IfaceName         string `json:"ifaceName"`
+	RetransmitCounter int64  `json:"retransmitCounter"`
+	RequestCounter    int64  `json:"requestCounter"`
+	DBSummaryCounter  int64  `json:"dbSummaryCounter"`
+}
+
+// routeSummary is the output of "show ip[v6] route summary json"
+type routeSummary struct {
+	Routes         []routeType `json:"routes"`
+	RoutesTotal    int64       `json:"routesTotal"`
+	RoutesTotalFib int64       `json:"routesTotalFib"`
+}
+
+type routeType struct {
+	Type string `json:"type"`
+	RIB  int64  `json:"rib"`
+	FIB  int64  `json:"fib"`
+}
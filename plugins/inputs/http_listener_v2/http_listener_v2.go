@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -29,6 +30,7 @@ import (
 	"github.com/influxdata/telegraf/internal/choice"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheusremotewrite"
 )
 
 //go:embed sample.conf
@@ -301,9 +303,31 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		h.acc.AddMetric(m)
 	}
 
+	if isRemoteWriteV2(req.Header.Get("Content-Type")) {
+		// Senders of Remote-Write 2.0 requests treat a response without the
+		// number of written elements as failure
+		stats, err := prometheusremotewrite.CountWriteV2(bytes)
+		if err != nil {
+			h.Log.Debugf("Counting remote-write elements failed: %v", err)
+		}
+		res.Header().Set("X-Prometheus-Remote-Write-Samples-Written", strconv.Itoa(stats.Samples))
+		res.Header().Set("X-Prometheus-Remote-Write-Histograms-Written", strconv.Itoa(stats.Histograms))
+		res.Header().Set("X-Prometheus-Remote-Write-Exemplars-Written", strconv.Itoa(stats.Exemplars))
+	}
+
 	res.WriteHeader(h.SuccessCode)
 }
 
+// isRemoteWriteV2 checks if the content-type denotes a Prometheus
+// Remote-Write 2.0 request
+func isRemoteWriteV2(contentType string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/x-protobuf" && params["proto"] == "io.prometheus.write.v2.Request"
+}
+
 func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
 	encoding := req.Header.Get("Content-Encoding")
 
@@ -18,11 +18,13 @@ import (
 	"time"
 
 	"github.com/golang/snappy"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheusremotewrite"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -486,6 +488,50 @@ func TestWriteHTTPSnappyData(t *testing.T) {
 	}
 }
 
+// test that Prometheus Remote-Write 2.0 requests report the written elements
+func TestWriteRemoteWriteV2(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.Parser = &prometheusremotewrite.Parser{}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	input := writev2.Request{
+		Symbols: []string{"", "__name__", "up", "job", "node"},
+		Timeseries: []writev2.TimeSeries{
+			{
+				LabelsRefs: []uint32{1, 2, 3, 4},
+				Samples:    []writev2.Sample{{Value: 1, Timestamp: 1718352000000}, {Value: 0, Timestamp: 1718352010000}},
+				Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
+			},
+		},
+	}
+	data, err := input.Marshal()
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), bytes.NewBuffer(snappy.Encode(nil, data)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v2.Request")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+	require.Equal(t, "2", resp.Header.Get("X-Prometheus-Remote-Write-Samples-Written"))
+	require.Equal(t, "0", resp.Header.Get("X-Prometheus-Remote-Write-Histograms-Written"))
+	require.Equal(t, "0", resp.Header.Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+
+	acc.Wait(2)
+	acc.AssertContainsTaggedFields(t, "prometheus_remote_write",
+		map[string]interface{}{"up": float64(0)},
+		map[string]string{"job": "node"},
+	)
+}
+
 // writes 25,000 metrics to the listener with 10 different writers
 func TestWriteHTTPHighTraffic(t *testing.T) {
 	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
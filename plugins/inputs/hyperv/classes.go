@@ -0,0 +1,172 @@
+package hyperv
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// statClass describes the WMI performance class of a statistic
+type statClass struct {
+	measurement string
+	class       string
+	// mapping of the class properties to the field names
+	fields map[string]string
+	// tag holding the name filtered by the name filter of the statistic
+	filterTag string
+	// tags returns the tags for the instance name or false if the instance
+	// should be skipped
+	tags func(name string) (map[string]string, bool)
+}
+
+var statClasses = map[string]statClass{
+	"cpu": {
+		measurement: "hyperv_vcpu",
+		class:       "Win32_PerfFormattedData_HvStats_HyperVHypervisorVirtualProcessor",
+		fields: map[string]string{
+			"PercentGuestRunTime":      "guest_run_time_percent",
+			"PercentHypervisorRunTime": "hypervisor_run_time_percent",
+			"PercentTotalRunTime":      "total_run_time_percent",
+			"CPUWaitTimePerDispatch":   "cpu_wait_time_per_dispatch",
+		},
+		filterTag: "vm",
+		tags:      vcpuTags,
+	},
+	"memory": {
+		measurement: "hyperv_dynamic_memory",
+		class:       "Win32_PerfFormattedData_BalancerStats_HyperVDynamicMemoryVM",
+		fields: map[string]string{
+			"AveragePressure":            "average_pressure",
+			"CurrentPressure":            "current_pressure",
+			"MaximumPressure":            "maximum_pressure",
+			"MinimumPressure":            "minimum_pressure",
+			"PhysicalMemory":             "physical_memory_mb",
+			"GuestVisiblePhysicalMemory": "guest_visible_memory_mb",
+			"GuestAvailableMemory":       "guest_available_memory_mb",
+			"AddedMemory":                "added_memory_mb",
+			"RemovedMemory":              "removed_memory_mb",
+		},
+		filterTag: "vm",
+		tags:      nameTags("vm"),
+	},
+	"switch": {
+		measurement: "hyperv_vmswitch",
+		class:       "Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch",
+		fields: map[string]string{
+			"BytesReceivedPersec":          "bytes_received_persec",
+			"BytesSentPersec":              "bytes_sent_persec",
+			"PacketsReceivedPersec":        "packets_received_persec",
+			"PacketsSentPersec":            "packets_sent_persec",
+			"DroppedPacketsIncomingPersec": "dropped_packets_incoming_persec",
+			"DroppedPacketsOutgoingPersec": "dropped_packets_outgoing_persec",
+		},
+		filterTag: "switch",
+		tags:      nameTags("switch"),
+	},
+}
+
+// properties returns the properties to query for the class in a stable order
+func (c *statClass) properties() []string {
+	return append([]string{"Name"}, slices.Sorted(maps.Keys(c.fields))...)
+}
+
+// addRows adds the metrics for the queried property values of the class
+// instances, skipping instances not accepted by the name filter
+func (c *statClass) addRows(acc telegraf.Accumulator, rows []map[string]interface{}, accept filter.Filter) {
+	for _, row := range rows {
+		name, ok := row["Name"].(string)
+		if !ok {
+			acc.AddError(fmt.Errorf("invalid instance name %v (%T) in %s", row["Name"], row["Name"], c.class))
+			continue
+		}
+		tags, ok := c.tags(name)
+		if !ok {
+			continue
+		}
+		if accept != nil && !accept.Match(tags[c.filterTag]) {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(c.fields))
+		for property, field := range c.fields {
+			raw, found := row[property]
+			if !found || raw == nil {
+				continue
+			}
+			v, err := toNumber(raw)
+			if err != nil {
+				acc.AddError(fmt.Errorf("converting property %q of %q in %s failed: %w", property, name, c.class, err))
+				continue
+			}
+			fields[field] = v
+		}
+		if len(fields) > 0 {
+			acc.AddFields(c.measurement, fields, tags)
+		}
+	}
+}
+
+// vcpuTags splits virtual processor instance names of the form
+// "<vm>:Hv VP <index>" into the virtual machine name and processor index
+func vcpuTags(name string) (map[string]string, bool) {
+	idx := strings.LastIndex(name, ":Hv VP ")
+	if idx < 0 {
+		return nil, false
+	}
+	return map[string]string{
+		"vm":   name[:idx],
+		"vcpu": name[idx+len(":Hv VP "):],
+	}, true
+}
+
+// nameTags returns a function using the instance name as the given tag,
+// skipping the aggregated "_Total" instance
+func nameTags(key string) func(name string) (map[string]string, bool) {
+	return func(name string) (map[string]string, bool) {
+		if name == "" || name == "_Total" {
+			return nil, false
+		}
+		return map[string]string{key: name}, true
+	}
+}
+
+// toNumber converts the property values to numbers, WMI returns 64-bit
+// integers as strings
+func toNumber(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		if u, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return u, nil
+		}
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
@@ -0,0 +1,213 @@
+package hyperv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestProperties(t *testing.T) {
+	c := statClasses["switch"]
+	expected := []string{
+		"Name",
+		"BytesReceivedPersec",
+		"BytesSentPersec",
+		"DroppedPacketsIncomingPersec",
+		"DroppedPacketsOutgoingPersec",
+		"PacketsReceivedPersec",
+		"PacketsSentPersec",
+	}
+	require.Equal(t, expected, c.properties())
+}
+
+func TestAddRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		stat     string
+		filter   []string
+		rows     []map[string]interface{}
+		expected []telegraf.Metric
+	}{
+		{
+			name: "cpu",
+			stat: "cpu",
+			rows: []map[string]interface{}{
+				{
+					"Name":                     "web:01:Hv VP 0",
+					"PercentGuestRunTime":      "12",
+					"PercentHypervisorRunTime": "1",
+					"PercentTotalRunTime":      "13",
+					"CPUWaitTimePerDispatch":   "4711",
+				},
+				{
+					"Name":                     "db:Hv VP 1",
+					"PercentGuestRunTime":      "80",
+					"PercentHypervisorRunTime": "2",
+					"PercentTotalRunTime":      "82",
+					"CPUWaitTimePerDispatch":   nil,
+				},
+				{
+					"Name":                "_Total",
+					"PercentGuestRunTime": "46",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"hyperv_vcpu",
+					map[string]string{"vm": "web:01", "vcpu": "0"},
+					map[string]interface{}{
+						"guest_run_time_percent":      uint64(12),
+						"hypervisor_run_time_percent": uint64(1),
+						"total_run_time_percent":      uint64(13),
+						"cpu_wait_time_per_dispatch":  uint64(4711),
+					},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"hyperv_vcpu",
+					map[string]string{"vm": "db", "vcpu": "1"},
+					map[string]interface{}{
+						"guest_run_time_percent":      uint64(80),
+						"hypervisor_run_time_percent": uint64(2),
+						"total_run_time_percent":      uint64(82),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "memory with filter",
+			stat:   "memory",
+			filter: []string{"web*"},
+			rows: []map[string]interface{}{
+				{
+					"Name":                       "web01",
+					"AveragePressure":            uint32(85),
+					"CurrentPressure":            uint32(90),
+					"MaximumPressure":            uint32(120),
+					"MinimumPressure":            uint32(60),
+					"PhysicalMemory":             "4096",
+					"GuestVisiblePhysicalMemory": "4096",
+					"GuestAvailableMemory":       "512",
+					"AddedMemory":                "2048",
+					"RemovedMemory":              "0",
+				},
+				{
+					"Name":            "db",
+					"AveragePressure": uint32(50),
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"hyperv_dynamic_memory",
+					map[string]string{"vm": "web01"},
+					map[string]interface{}{
+						"average_pressure":          uint64(85),
+						"current_pressure":          uint64(90),
+						"maximum_pressure":          uint64(120),
+						"minimum_pressure":          uint64(60),
+						"physical_memory_mb":        uint64(4096),
+						"guest_visible_memory_mb":   uint64(4096),
+						"guest_available_memory_mb": uint64(512),
+						"added_memory_mb":           uint64(2048),
+						"removed_memory_mb":         uint64(0),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "switch",
+			stat: "switch",
+			rows: []map[string]interface{}{
+				{
+					"Name":                         "External",
+					"BytesReceivedPersec":          "125000",
+					"BytesSentPersec":              "64000",
+					"PacketsReceivedPersec":        "100",
+					"PacketsSentPersec":            "80",
+					"DroppedPacketsIncomingPersec": "0",
+					"DroppedPacketsOutgoingPersec": "2",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"hyperv_vmswitch",
+					map[string]string{"switch": "External"},
+					map[string]interface{}{
+						"bytes_received_persec":           uint64(125000),
+						"bytes_sent_persec":               uint64(64000),
+						"packets_received_persec":         uint64(100),
+						"packets_sent_persec":             uint64(80),
+						"dropped_packets_incoming_persec": uint64(0),
+						"dropped_packets_outgoing_persec": uint64(2),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept, err := filter.Compile(tt.filter)
+			require.NoError(t, err)
+
+			var acc testutil.Accumulator
+			c := statClasses[tt.stat]
+			c.addRows(&acc, tt.rows, accept)
+			require.Empty(t, acc.Errors)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestAddRowsInvalid(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"Name": 42},
+		{"Name": "External", "BytesReceivedPersec": true, "BytesSentPersec": "10"},
+	}
+
+	var acc testutil.Accumulator
+	c := statClasses["switch"]
+	c.addRows(&acc, rows, nil)
+	require.Len(t, acc.Errors, 2)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"hyperv_vmswitch",
+			map[string]string{"switch": "External"},
+			map[string]interface{}{"bytes_sent_persec": uint64(10)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestToNumber(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{value: int32(-5), expected: int64(-5)},
+		{value: uint32(5), expected: uint64(5)},
+		{value: float32(1.5), expected: float64(1.5)},
+		{value: "18446744073709551615", expected: uint64(18446744073709551615)},
+		{value: "-42", expected: int64(-42)},
+		{value: "0.25", expected: float64(0.25)},
+	}
+	for _, tt := range tests {
+		v, err := toNumber(tt.value)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, v)
+	}
+
+	_, err := toNumber("abc")
+	require.Error(t, err)
+}
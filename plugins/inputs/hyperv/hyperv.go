@@ -0,0 +1,78 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build windows
+
+package hyperv
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type HyperV struct {
+	VMs      []string        `toml:"vms"`
+	Switches []string        `toml:"switches"`
+	Collect  []string        `toml:"collect"`
+	Log      telegraf.Logger `toml:"-"`
+
+	filters map[string]filter.Filter
+	query   func(class string, properties []string) ([]map[string]interface{}, error)
+}
+
+func (*HyperV) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HyperV) Init() error {
+	for _, c := range h.Collect {
+		if _, found := statClasses[c]; !found {
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+
+	vmFilter, err := filter.Compile(h.VMs)
+	if err != nil {
+		return fmt.Errorf("creating virtual machine filter failed: %w", err)
+	}
+	switchFilter, err := filter.Compile(h.Switches)
+	if err != nil {
+		return fmt.Errorf("creating switch filter failed: %w", err)
+	}
+	h.filters = map[string]filter.Filter{
+		"vm":     vmFilter,
+		"switch": switchFilter,
+	}
+
+	if h.query == nil {
+		h.query = queryWMI
+	}
+
+	return nil
+}
+
+func (h *HyperV) Gather(acc telegraf.Accumulator) error {
+	for _, c := range h.Collect {
+		class := statClasses[c]
+		rows, err := h.query(class.class, class.properties())
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying %s statistics failed: %w", c, err))
+			continue
+		}
+		class.addRows(acc, rows, h.filters[class.filterTag])
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("hyperv", func() telegraf.Input {
+		return &HyperV{
+			Collect: []string{"cpu", "memory", "switch"},
+		}
+	})
+}
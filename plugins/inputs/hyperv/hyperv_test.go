@@ -0,0 +1,48 @@
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &HyperV{Collect: []string{"disk"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' value "disk"`)
+}
+
+func TestGather(t *testing.T) {
+	plugin := &HyperV{
+		VMs:     []string{"web*"},
+		Collect: []string{"cpu", "switch"},
+		Log:     testutil.Logger{},
+		query: func(class string, _ []string) ([]map[string]interface{}, error) {
+			switch class {
+			case statClasses["cpu"].class:
+				return []map[string]interface{}{
+					{"Name": "web01:Hv VP 0", "PercentTotalRunTime": "10"},
+					{"Name": "db:Hv VP 0", "PercentTotalRunTime": "20"},
+				}, nil
+			case statClasses["switch"].class:
+				return nil, errors.New("access denied")
+			}
+			return nil, nil
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying switch statistics failed: access denied")
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	vm, _ := metrics[0].GetTag("vm")
+	require.Equal(t, "web01", vm)
+}
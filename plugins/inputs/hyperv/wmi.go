@@ -0,0 +1,100 @@
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+// queryWMI returns the given properties of all instances of the class in the
+// local "root\cimv2" namespace
+func queryWMI(class string, properties []string) ([]map[string]interface{}, error) {
+	// The only way to run WMI queries in parallel while being thread-safe is to
+	// ensure the CoInitialize[Ex]() call is bound to its current OS thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	}
+	if unknown == nil {
+		return nil, errors.New("failed to create WbemScripting.SWbemLocator, maybe WMI is broken")
+	}
+	defer unknown.Release()
+
+	wmi, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to QueryInterface: %w", err)
+	}
+	defer wmi.Release()
+
+	serviceRaw, err := oleutil.CallMethod(wmi, "ConnectServer", nil, `root\cimv2`)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	wql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(properties, ", "), class)
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", wql)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery for query %s: %w", wql, err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	countRaw, err := oleutil.GetProperty(result, "Count")
+	if err != nil {
+		return nil, fmt.Errorf("failed getting Count: %w", err)
+	}
+	count := countRaw.Val
+	defer countRaw.Clear()
+
+	rows := make([]map[string]interface{}, 0, count)
+	for i := int64(0); i < count; i++ {
+		itemRaw, err := oleutil.CallMethod(result, "ItemIndex", i)
+		if err != nil {
+			return nil, fmt.Errorf("failed calling method ItemIndex: %w", err)
+		}
+		row, err := extractProperties(itemRaw, properties)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func extractProperties(itemRaw *ole.VARIANT, properties []string) (map[string]interface{}, error) {
+	item := itemRaw.ToIDispatch()
+	defer item.Release()
+
+	row := make(map[string]interface{}, len(properties))
+	for _, name := range properties {
+		propertyRaw, err := oleutil.GetProperty(item, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting property %q failed: %w", name, err)
+		}
+		row[name] = propertyRaw.Value()
+		propertyRaw.Clear()
+	}
+	return row, nil
+}
@@ -4,13 +4,17 @@ package internal
 import (
 	_ "embed"
 	"fmt"
+	"math"
 	"runtime"
 	"runtime/metrics"
+	"strconv"
 	"strings"
 
 	"github.com/influxdata/telegraf"
 	inter "github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -18,15 +22,33 @@ import (
 var sampleConfig string
 
 type Internal struct {
-	CollectMemstats bool `toml:"collect_memstats"`
-	CollectGostats  bool `toml:"collect_gostats"`
-	PerInstance     bool `toml:"per_instance"`
+	CollectMemstats  bool            `toml:"collect_memstats"`
+	CollectGostats   bool            `toml:"collect_gostats"`
+	GostatsQuantiles []float64       `toml:"gostats_quantiles"`
+	CollectBuildInfo bool            `toml:"collect_build_info"`
+	PerInstance      bool            `toml:"per_instance"`
+	Log              telegraf.Logger `toml:"-"`
+
+	quantileSuffixes []string
 }
 
 func (*Internal) SampleConfig() string {
 	return sampleConfig
 }
 
+func (s *Internal) Init() error {
+	s.quantileSuffixes = make([]string, 0, len(s.GostatsQuantiles))
+	for _, q := range s.GostatsQuantiles {
+		if q < 0 || q > 1 {
+			return fmt.Errorf("quantile %v out of range [0,1]", q)
+		}
+		// Converts 0.999 to _p99_9, rounding to avoid artifacts like 28.999999999999996
+		suffix := strconv.FormatFloat(math.Round(q*1e8)/1e6, 'f', -1, 64)
+		s.quantileSuffixes = append(s.quantileSuffixes, "_p"+strings.ReplaceAll(suffix, ".", "_"))
+	}
+	return nil
+}
+
 func (s *Internal) Gather(acc telegraf.Accumulator) error {
 	if s.PerInstance {
 		collectIndividualPluginStat(acc)
@@ -39,7 +61,11 @@ func (s *Internal) Gather(acc telegraf.Accumulator) error {
 	}
 
 	if s.CollectGostats {
-		collectGoStat(acc)
+		s.collectGoStat(acc)
+	}
+
+	if s.CollectBuildInfo {
+		collectBuildInfo(acc)
 	}
 
 	return nil
@@ -106,7 +132,7 @@ func collectMemStat(acc telegraf.Accumulator) {
 	acc.AddFields("internal_memstats", fields, make(map[string]string))
 }
 
-func collectGoStat(acc telegraf.Accumulator) {
+func (s *Internal) collectGoStat(acc telegraf.Accumulator) {
 	descs := metrics.All()
 	samples := make([]metrics.Sample, len(descs))
 	for i := range samples {
@@ -125,15 +151,16 @@ func collectGoStat(acc telegraf.Accumulator) {
 			fields[name] = sample.Value.Float64()
 		case metrics.KindFloat64Histogram:
 			// The histogram may be quite large, so let's just pull out
-			// a crude estimate for the median for the sake of this example.
-			fields[name] = medianBucket(sample.Value.Float64Histogram())
+			// a crude estimate for the median and the requested quantiles
+			h := sample.Value.Float64Histogram()
+			fields[name] = quantileBucket(h, 0.5)
+			for i, q := range s.GostatsQuantiles {
+				fields[name+s.quantileSuffixes[i]] = quantileBucket(h, q)
+			}
 		default:
-			// This may happen as new metrics get added.
-			//
-			// The safest thing to do here is to simply log it somewhere
-			// as something to look into, but ignore it for now.
-			// In the worst case, you might temporarily miss out on a new metric.
-			fmt.Printf("%s: unexpected metric Kind: %v\n", name, sample.Value.Kind())
+			// This may happen as new metrics get added, in the worst case
+			// we temporarily miss out on a new metric.
+			s.Log.Debugf("Unexpected kind %v of metric %q", sample.Value.Kind(), sample.Name)
 		}
 	}
 
@@ -152,16 +179,21 @@ func sanitizeName(name string) string {
 	return name
 }
 
-func medianBucket(h *metrics.Float64Histogram) float64 {
+// quantileBucket returns the lower boundary of the bucket containing the
+// given quantile or the upper boundary for the lowest, unbounded bucket
+func quantileBucket(h *metrics.Float64Histogram, q float64) float64 {
 	total := uint64(0)
 	for _, count := range h.Counts {
 		total += count
 	}
-	thresh := total / 2
+	thresh := uint64(q * float64(total))
 	total = 0
 	for i, count := range h.Counts {
 		total += count
 		if total >= thresh {
+			if math.IsInf(h.Buckets[i], -1) {
+				return h.Buckets[i+1]
+			}
 			return h.Buckets[i]
 		}
 	}
@@ -170,6 +202,32 @@ func medianBucket(h *metrics.Float64Histogram) float64 {
 	return 0.0
 }
 
+func collectBuildInfo(acc telegraf.Accumulator) {
+	tags := map[string]string{
+		"version":    inter.Version,
+		"go_version": strings.TrimPrefix(runtime.Version(), "go"),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+	}
+	if inter.Branch != "" {
+		tags["branch"] = inter.Branch
+	}
+	if inter.Commit != "" {
+		tags["commit"] = inter.Commit
+	}
+
+	// Number of plugins compiled into the binary, differing for custom builds.
+	// Processors are not counted as the registry pulls in the agent models.
+	fields := map[string]any{
+		"inputs":      len(inputs.Inputs),
+		"outputs":     len(outputs.Outputs),
+		"aggregators": len(aggregators.Aggregators),
+		"gomaxprocs":  runtime.GOMAXPROCS(0),
+		"num_cpu":     runtime.NumCPU(),
+	}
+	acc.AddFields("internal_build_info", fields, tags)
+}
+
 func init() {
 	inputs.Add("internal", func() telegraf.Input {
 		return &Internal{
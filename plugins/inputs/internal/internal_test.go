@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"math"
+	"runtime/metrics"
 	"strconv"
 	"testing"
 	"time"
@@ -257,3 +259,60 @@ func TestAccumulatedPerType(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestInitInvalidQuantile(t *testing.T) {
+	s := Internal{GostatsQuantiles: []float64{0.5, 1.5}}
+	require.ErrorContains(t, s.Init(), "out of range")
+}
+
+func TestGostatsQuantiles(t *testing.T) {
+	s := Internal{
+		CollectGostats:   true,
+		GostatsQuantiles: []float64{0.99, 0.999},
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+
+	m, found := acc.Get("internal_gostats")
+	require.True(t, found)
+	fields := m.Fields
+	for _, name := range []string{"gc_pauses_seconds", "sched_latencies_seconds"} {
+		require.Contains(t, fields, name)
+		require.Contains(t, fields, name+"_p99")
+		require.Contains(t, fields, name+"_p99_9")
+		require.LessOrEqual(t, fields[name], fields[name+"_p99"])
+		require.LessOrEqual(t, fields[name+"_p99"], fields[name+"_p99_9"])
+	}
+}
+
+func TestQuantileBucket(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{0, 50, 40, 10},
+		Buckets: []float64{math.Inf(-1), 1, 2, 3, math.Inf(1)},
+	}
+	require.InDelta(t, 1.0, quantileBucket(h, 0.5), 0)
+	require.InDelta(t, 2.0, quantileBucket(h, 0.9), 0)
+	require.InDelta(t, 3.0, quantileBucket(h, 0.99), 0)
+	// The lowest bucket is unbounded so the upper boundary is reported
+	require.InDelta(t, 1.0, quantileBucket(h, 0), 0)
+}
+
+func TestBuildInfo(t *testing.T) {
+	s := Internal{CollectBuildInfo: true}
+	require.NoError(t, s.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+
+	require.True(t, acc.HasMeasurement("internal_build_info"))
+	require.True(t, acc.HasTag("internal_build_info", "version"))
+	require.True(t, acc.HasTag("internal_build_info", "go_version"))
+	require.True(t, acc.HasTag("internal_build_info", "os"))
+	require.True(t, acc.HasTag("internal_build_info", "arch"))
+	for _, field := range []string{"inputs", "outputs", "aggregators", "gomaxprocs", "num_cpu"} {
+		require.True(t, acc.HasIntField("internal_build_info", field), field)
+	}
+}
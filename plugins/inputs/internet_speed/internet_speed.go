@@ -35,9 +35,11 @@ type InternetSpeed struct {
 	Cache            bool     `toml:"cache"`
 	Connections      int      `toml:"connections"`
 	TestMode         string   `toml:"test_mode"`
+	ClientInfo       bool     `toml:"include_client_info"`
 
 	Log telegraf.Logger `toml:"-"`
 
+	client       *speedtest.Speedtest
 	server       *speedtest.Server // The main(best) server
 	servers      speedtest.Servers // Auxiliary servers
 	serverFilter filter.Filter
@@ -75,6 +77,10 @@ func (is *InternetSpeed) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	// The client information might change independently of the server, e.g.
+	// when switching the uplink, so query it on each gather
+	user := is.fetchClientInfo()
+
 	err := is.server.PingTest(nil)
 	if err != nil {
 		return fmt.Errorf("ping test failed: %w", err)
@@ -126,23 +132,38 @@ func (is *InternetSpeed) Gather(acc telegraf.Accumulator) error {
 		packetLoss = pLoss.LossPercent()
 	}
 
+	fields, tags := is.buildMetric(is.server, user, packetLoss)
+
+	// Recycle the history of each test to prevent data backlog.
+	is.server.Context.Reset()
+	acc.AddFields(measurement, fields, tags)
+	return nil
+}
+
+func (is *InternetSpeed) buildMetric(server *speedtest.Server, user *speedtest.User, packetLoss float64) (map[string]any, map[string]string) {
 	fields := map[string]any{
-		"download":    is.server.DLSpeed.Mbps(),
-		"upload":      is.server.ULSpeed.Mbps(),
-		"latency":     timeDurationMillisecondToFloat64(is.server.Latency),
-		"jitter":      timeDurationMillisecondToFloat64(is.server.Jitter),
+		"download":    server.DLSpeed.Mbps(),
+		"upload":      server.ULSpeed.Mbps(),
+		"latency":     timeDurationMillisecondToFloat64(server.Latency),
+		"jitter":      timeDurationMillisecondToFloat64(server.Jitter),
 		"packet_loss": packetLoss,
-		"location":    is.server.Name,
+		"location":    server.Name,
 	}
 	tags := map[string]string{
-		"server_id": is.server.ID,
-		"source":    is.server.Host,
+		"server_id": server.ID,
+		"source":    server.Host,
 		"test_mode": is.TestMode,
 	}
-	// Recycle the history of each test to prevent data backlog.
-	is.server.Context.Reset()
-	acc.AddFields(measurement, fields, tags)
-	return nil
+
+	// Add the client information all or nothing to avoid splitting the series
+	if is.ClientInfo && user != nil {
+		tags["isp"] = user.Isp
+		tags["server_country"] = server.Country
+		tags["server_sponsor"] = server.Sponsor
+		fields["external_ip"] = user.IP
+	}
+
+	return fields, tags
 }
 
 func (is *InternetSpeed) findClosestServer() error {
@@ -151,17 +172,17 @@ func (is *InternetSpeed) findClosestServer() error {
 		proto = speedtest.ICMP
 	}
 
-	client := speedtest.New(speedtest.WithUserConfig(&speedtest.UserConfig{
+	is.client = speedtest.New(speedtest.WithUserConfig(&speedtest.UserConfig{
 		UserAgent:  internal.ProductToken(),
 		PingMode:   proto,
 		SavingMode: is.MemorySavingMode,
 	}))
 	if is.Connections > 0 {
-		client.SetNThread(is.Connections)
+		is.client.SetNThread(is.Connections)
 	}
 
 	var err error
-	is.servers, err = client.FetchServers()
+	is.servers, err = is.client.FetchServers()
 	if err != nil {
 		return fmt.Errorf("fetching server list failed: %w", err)
 	}
@@ -196,6 +217,21 @@ func (is *InternetSpeed) findClosestServer() error {
 	return errors.New("no server set: filter excluded all servers or no available server found")
 }
 
+// fetchClientInfo returns the client information if enabled. The information
+// is optional so do not fail but omit the information on errors.
+func (is *InternetSpeed) fetchClientInfo() *speedtest.User {
+	if !is.ClientInfo {
+		return nil
+	}
+
+	user, err := is.client.FetchUserInfo()
+	if err != nil {
+		is.Log.Warnf("Fetching client information failed: %v", err)
+		return nil
+	}
+	return user
+}
+
 func timeDurationMillisecondToFloat64(d time.Duration) float64 {
 	return float64(d) / float64(time.Millisecond)
 }
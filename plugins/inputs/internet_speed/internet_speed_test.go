@@ -2,7 +2,9 @@ package internet_speed
 
 import (
 	"testing"
+	"time"
 
+	"github.com/showwin/speedtest-go/speedtest"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/testutil"
@@ -39,3 +41,89 @@ func TestDataGen(t *testing.T) {
 	require.True(t, ok)
 	acc.AssertContainsTaggedFields(t, "internet_speed", metric.Fields, metric.Tags)
 }
+
+func TestBuildMetricClientInfo(t *testing.T) {
+	server := &speedtest.Server{
+		ID:      "54619",
+		Host:    "speedtest02.z4internet.com:8080",
+		Name:    "Somewhere, TX",
+		Country: "United States",
+		Sponsor: "Z4 Internet",
+		Latency: 37 * time.Millisecond,
+		Jitter:  2 * time.Millisecond,
+	}
+	user := &speedtest.User{
+		IP:  "203.0.113.17",
+		Isp: "Example ISP",
+	}
+
+	baseFields := map[string]any{
+		"download":    0.0,
+		"upload":      0.0,
+		"latency":     37.0,
+		"jitter":      2.0,
+		"packet_loss": 0.5,
+		"location":    "Somewhere, TX",
+	}
+	baseTags := map[string]string{
+		"server_id": "54619",
+		"source":    "speedtest02.z4internet.com:8080",
+		"test_mode": testModeSingle,
+	}
+
+	tests := []struct {
+		name           string
+		clientInfo     bool
+		user           *speedtest.User
+		expectedFields map[string]any
+		expectedTags   map[string]string
+	}{
+		{
+			name:           "disabled",
+			user:           user,
+			expectedFields: baseFields,
+			expectedTags:   baseTags,
+		},
+		{
+			name:       "enabled",
+			clientInfo: true,
+			user:       user,
+			expectedFields: map[string]any{
+				"download":    0.0,
+				"upload":      0.0,
+				"latency":     37.0,
+				"jitter":      2.0,
+				"packet_loss": 0.5,
+				"location":    "Somewhere, TX",
+				"external_ip": "203.0.113.17",
+			},
+			expectedTags: map[string]string{
+				"server_id":      "54619",
+				"source":         "speedtest02.z4internet.com:8080",
+				"test_mode":      testModeSingle,
+				"isp":            "Example ISP",
+				"server_country": "United States",
+				"server_sponsor": "Z4 Internet",
+			},
+		},
+		{
+			name:           "enabled without client information",
+			clientInfo:     true,
+			expectedFields: baseFields,
+			expectedTags:   baseTags,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &InternetSpeed{
+				TestMode:   testModeSingle,
+				ClientInfo: tt.clientInfo,
+				Log:        testutil.Logger{},
+			}
+			fields, tags := plugin.buildMetric(server, tt.user, 0.5)
+			require.Equal(t, tt.expectedFields, fields)
+			require.Equal(t, tt.expectedTags, tags)
+		})
+	}
+}
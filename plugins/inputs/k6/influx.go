@@ -0,0 +1,126 @@
+package k6
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/parsers/influx/influx_upstream"
+)
+
+// startInflux starts a HTTP listener implementing the parts of the InfluxDB
+// v1 API used by the k6 InfluxDB output
+func (k *K6) startInflux() error {
+	listener, err := net.Listen("tcp", k.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	k.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/write", k.handleWrite)
+	mux.HandleFunc("/query", handleQuery)
+	mux.HandleFunc("/ping", handlePing)
+	k.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		if err := k.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			k.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+	k.Log.Infof("Listening for k6 InfluxDB output on %s", listener.Addr())
+
+	return nil
+}
+
+func (k *K6) handleWrite(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := http.MaxBytesReader(res, req.Body, int64(k.MaxBodySize))
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		r, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Close()
+		body = r
+	}
+
+	parser := influx_upstream.NewStreamParser(body)
+	if precision := req.URL.Query().Get("precision"); precision != "" {
+		if err := parser.SetTimePrecision(precisionMultiplier(precision)); err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var parseErrors int
+	for {
+		m, err := parser.Next()
+		if err != nil {
+			var parseErr *influx_upstream.ParseError
+			if errors.As(err, &parseErr) {
+				if parseErrors == 0 {
+					k.Log.Debugf("Parsing sample failed: %v", err)
+				}
+				parseErrors++
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		k.add(m)
+	}
+
+	if parseErrors > 0 {
+		http.Error(res, "partial write: invalid samples", http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// handleQuery answers all queries with an empty result as k6 creates the
+// database on startup
+func handleQuery(res http.ResponseWriter, _ *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Influxdb-Version", "1.8")
+	res.WriteHeader(http.StatusOK)
+	//nolint:errcheck // nothing we can do if writing the response fails
+	res.Write([]byte(`{"results":[{"statement_id":0}]}`))
+}
+
+func handlePing(res http.ResponseWriter, _ *http.Request) {
+	res.Header().Set("X-Influxdb-Version", "1.8")
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func precisionMultiplier(precision string) time.Duration {
+	switch precision {
+	case "u", "us":
+		return time.Microsecond
+	case "ms":
+		return time.Millisecond
+	case "s":
+		return time.Second
+	case "m":
+		return time.Minute
+	case "h":
+		return time.Hour
+	}
+	return time.Nanosecond
+}
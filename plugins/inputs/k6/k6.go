@@ -0,0 +1,194 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package k6
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type K6 struct {
+	Protocol        string          `toml:"protocol"`
+	ServiceAddress  string          `toml:"service_address"`
+	MaxBodySize     config.Size     `toml:"max_body_size"`
+	StatsdNamespace string          `toml:"statsd_namespace"`
+	ScenarioTag     string          `toml:"scenario_tag"`
+	Thresholds      []*Threshold    `toml:"threshold"`
+	Log             telegraf.Logger `toml:"-"`
+
+	acc      telegraf.Accumulator
+	listener net.Listener
+	conn     net.PacketConn
+	server   *http.Server
+	wg       sync.WaitGroup
+
+	// Samples of metrics with thresholds per metric and scenario
+	samples map[sampleKey][]float64
+	mu      sync.Mutex
+
+	thresholds map[string]*Threshold
+}
+
+type sampleKey struct {
+	metric   string
+	scenario string
+}
+
+func (*K6) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *K6) Init() error {
+	switch k.Protocol {
+	case "influx":
+		if k.ServiceAddress == "" {
+			k.ServiceAddress = ":8186"
+		}
+	case "statsd":
+		if k.ServiceAddress == "" {
+			k.ServiceAddress = ":8125"
+		}
+	default:
+		return fmt.Errorf("invalid protocol %q", k.Protocol)
+	}
+
+	k.thresholds = make(map[string]*Threshold, len(k.Thresholds))
+	for _, t := range k.Thresholds {
+		if t.Metric == "" {
+			return errors.New("threshold without metric")
+		}
+		if _, found := k.thresholds[t.Metric]; found {
+			return fmt.Errorf("duplicate thresholds for metric %q", t.Metric)
+		}
+		if len(t.Conditions) == 0 {
+			return fmt.Errorf("no conditions for metric %q", t.Metric)
+		}
+		for _, expression := range t.Conditions {
+			c, err := parseCondition(expression)
+			if err != nil {
+				return fmt.Errorf("metric %q: %w", t.Metric, err)
+			}
+			t.conditions = append(t.conditions, c)
+		}
+		k.thresholds[t.Metric] = t
+	}
+	k.samples = make(map[sampleKey][]float64)
+
+	return nil
+}
+
+func (k *K6) Start(acc telegraf.Accumulator) error {
+	k.acc = acc
+
+	var err error
+	switch k.Protocol {
+	case "influx":
+		err = k.startInflux()
+	case "statsd":
+		err = k.startStatsd()
+	}
+	return err
+}
+
+// Gather evaluates the thresholds for the samples received since the last
+// gather cycle
+func (k *K6) Gather(acc telegraf.Accumulator) error {
+	k.mu.Lock()
+	samples := k.samples
+	k.samples = make(map[sampleKey][]float64)
+	k.mu.Unlock()
+
+	now := time.Now()
+	for key, values := range samples {
+		for _, c := range k.thresholds[key.metric].conditions {
+			value, passed := c.evaluate(values)
+			tags := map[string]string{
+				"metric":    key.metric,
+				"threshold": c.expression,
+			}
+			if key.scenario != "" {
+				tags["scenario"] = key.scenario
+			}
+			fields := map[string]interface{}{
+				"value":   value,
+				"passed":  passed,
+				"samples": len(values),
+			}
+			acc.AddFields("k6_threshold", fields, tags, now)
+		}
+	}
+
+	return nil
+}
+
+func (k *K6) Stop() {
+	if k.server != nil {
+		k.server.Close()
+	}
+	if k.conn != nil {
+		k.conn.Close()
+	}
+	k.wg.Wait()
+}
+
+// add passes the sample metric on and records its value if thresholds are
+// defined for the metric
+func (k *K6) add(m telegraf.Metric) {
+	if t, found := k.thresholds[m.Name()]; found {
+		if v, ok := m.GetField("value"); ok {
+			if value, ok := toFloat(v); ok {
+				scenario, _ := m.GetTag(k.ScenarioTag)
+				key := sampleKey{metric: t.Metric, scenario: scenario}
+				k.mu.Lock()
+				k.samples[key] = append(k.samples[key], value)
+				k.mu.Unlock()
+			}
+		}
+	}
+	k.acc.AddMetric(m)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// trimNamespace removes the configured prefix of StatsD metric names
+func (k *K6) trimNamespace(name string) string {
+	return strings.TrimPrefix(name, k.StatsdNamespace)
+}
+
+func init() {
+	inputs.Add("k6", func() telegraf.Input {
+		return &K6{
+			Protocol:        "influx",
+			MaxBodySize:     config.Size(32 * 1024 * 1024),
+			StatsdNamespace: "k6.",
+			ScenarioTag:     "scenario",
+		}
+	})
+}
@@ -0,0 +1,344 @@
+package k6
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		protocol   string
+		thresholds []*Threshold
+		expected   string
+	}{
+		{
+			name:     "invalid protocol",
+			protocol: "foo",
+			expected: `invalid protocol "foo"`,
+		},
+		{
+			name:       "missing metric",
+			protocol:   "influx",
+			thresholds: []*Threshold{{Conditions: []string{"avg<1"}}},
+			expected:   "threshold without metric",
+		},
+		{
+			name:       "missing conditions",
+			protocol:   "influx",
+			thresholds: []*Threshold{{Metric: "http_reqs"}},
+			expected:   `no conditions for metric "http_reqs"`,
+		},
+		{
+			name:     "duplicate metric",
+			protocol: "influx",
+			thresholds: []*Threshold{
+				{Metric: "http_reqs", Conditions: []string{"count>1"}},
+				{Metric: "http_reqs", Conditions: []string{"count<10"}},
+			},
+			expected: `duplicate thresholds for metric "http_reqs"`,
+		},
+		{
+			name:       "invalid condition",
+			protocol:   "statsd",
+			thresholds: []*Threshold{{Metric: "http_reqs", Conditions: []string{"sum<1"}}},
+			expected:   `metric "http_reqs": invalid threshold "sum<1"`,
+		},
+		{
+			name:       "invalid percentile",
+			protocol:   "statsd",
+			thresholds: []*Threshold{{Metric: "http_req_duration", Conditions: []string{"p(101)<1"}}},
+			expected:   `invalid percentile in threshold "p(101)<1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &K6{
+				Protocol:   tt.protocol,
+				Thresholds: tt.thresholds,
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDefaultAddress(t *testing.T) {
+	plugin := &K6{Protocol: "influx"}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, ":8186", plugin.ServiceAddress)
+
+	plugin = &K6{Protocol: "statsd"}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, ":8125", plugin.ServiceAddress)
+}
+
+func TestConditions(t *testing.T) {
+	samples := []float64{5, 1, 4, 2, 3, 0}
+
+	tests := []struct {
+		expression string
+		value      float64
+		passed     bool
+	}{
+		{expression: "avg<3", value: 2.5, passed: true},
+		{expression: "min>0", value: 0, passed: false},
+		{expression: "max<=5", value: 5, passed: true},
+		{expression: "med==2.5", value: 2.5, passed: true},
+		{expression: "p(90)<4", value: 4.5, passed: false},
+		{expression: "p( 20 ) >= 1", value: 1, passed: true},
+		{expression: "count>10", value: 15, passed: true},
+		{expression: "rate===0.5", value: 5.0 / 6.0, passed: false},
+		{expression: "value!=0", value: 0, passed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			c, err := parseCondition(tt.expression)
+			require.NoError(t, err)
+			value, passed := c.evaluate(samples)
+			require.InDelta(t, tt.value, value, 1e-9)
+			require.Equal(t, tt.passed, passed)
+		})
+	}
+}
+
+func TestParseStatsd(t *testing.T) {
+	plugin := &K6{StatsdNamespace: "k6."}
+	now := time.Unix(1718352000, 0)
+
+	tests := []struct {
+		name     string
+		line     string
+		expected telegraf.Metric
+	}{
+		{
+			name: "trend with tags",
+			line: "k6.http_req_duration:123.45|ms|#scenario:default,method:GET,status:200",
+			expected: metric.New(
+				"http_req_duration",
+				map[string]string{"scenario": "default", "method": "GET", "status": "200"},
+				map[string]interface{}{"value": 123.45},
+				now,
+			),
+		},
+		{
+			name: "counter with sample rate",
+			line: "k6.http_reqs:1|c|@1|#scenario:login",
+			expected: metric.New(
+				"http_reqs",
+				map[string]string{"scenario": "login"},
+				map[string]interface{}{"value": float64(1)},
+				now,
+			),
+		},
+		{
+			name: "gauge without tags",
+			line: "k6.vus:10|g",
+			expected: metric.New(
+				"vus",
+				map[string]string{},
+				map[string]interface{}{"value": float64(10)},
+				now,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := plugin.parseStatsd(tt.line, now)
+			require.NoError(t, err)
+			testutil.RequireMetricEqual(t, tt.expected, m)
+		})
+	}
+}
+
+func TestParseStatsdInvalid(t *testing.T) {
+	plugin := &K6{StatsdNamespace: "k6."}
+
+	for _, line := range []string{"k6.vus", ":1|g", "k6.vus:1", "k6.vus:1|s", "k6.vus:abc|g"} {
+		_, err := plugin.parseStatsd(line, time.Now())
+		require.Error(t, err, line)
+	}
+}
+
+func TestInflux(t *testing.T) {
+	plugin := &K6{
+		Protocol:       "influx",
+		ServiceAddress: "127.0.0.1:0",
+		MaxBodySize:    config.Size(1024 * 1024),
+		ScenarioTag:    "scenario",
+		Thresholds: []*Threshold{
+			{Metric: "http_req_duration", Conditions: []string{"p(95)<500"}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	addr := "http://" + plugin.listener.Addr().String()
+
+	// Check the endpoints used by k6 on startup
+	resp, err := http.Post(addr+"/query?q=CREATE+DATABASE+%22k6%22", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write([]byte(
+		"http_req_duration,scenario=default,status=200 value=100,vu=1i 1718352000000000\n" +
+			"http_req_duration,scenario=default,status=200 value=600,vu=2i 1718352001000000\n" +
+			"http_reqs,scenario=default,status=200 value=1,vu=1i 1718352000000000\n",
+	))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req, err := http.NewRequest(http.MethodPost, addr+"/write?db=k6&precision=u", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http_req_duration",
+			map[string]string{"scenario": "default", "status": "200"},
+			map[string]interface{}{"value": float64(100), "vu": int64(1)},
+			time.Unix(1718352000, 0),
+		),
+		metric.New(
+			"http_req_duration",
+			map[string]string{"scenario": "default", "status": "200"},
+			map[string]interface{}{"value": float64(600), "vu": int64(2)},
+			time.Unix(1718352001, 0),
+		),
+		metric.New(
+			"http_reqs",
+			map[string]string{"scenario": "default", "status": "200"},
+			map[string]interface{}{"value": float64(1), "vu": int64(1)},
+			time.Unix(1718352000, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{
+		metric.New(
+			"k6_threshold",
+			map[string]string{
+				"metric":    "http_req_duration",
+				"threshold": "p(95)<500",
+				"scenario":  "default",
+			},
+			map[string]interface{}{
+				"value":   float64(575),
+				"passed":  false,
+				"samples": 2,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Samples are only evaluated once
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInfluxInvalid(t *testing.T) {
+	plugin := &K6{
+		Protocol:       "influx",
+		ServiceAddress: "127.0.0.1:0",
+		MaxBodySize:    config.Size(1024 * 1024),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	addr := "http://" + plugin.listener.Addr().String()
+	body := bytes.NewBufferString("vus value=1 1718352000000000000\nthis is invalid\n")
+	resp, err := http.Post(addr+"/write", "text/plain", body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	resp, err = http.Get(addr + "/write")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestStatsd(t *testing.T) {
+	plugin := &K6{
+		Protocol:        "statsd",
+		ServiceAddress:  "127.0.0.1:0",
+		StatsdNamespace: "k6.",
+		ScenarioTag:     "scenario",
+		Thresholds: []*Threshold{
+			{Metric: "http_req_failed", Conditions: []string{"rate<0.5"}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("udp", plugin.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(
+		"k6.http_req_failed:0|g|#scenario:a\n" +
+			"k6.http_req_failed:1|g|#scenario:a\n" +
+			"k6.http_req_failed:0|g|#scenario:b\n",
+	))
+	require.NoError(t, err)
+	acc.Wait(3)
+
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"k6_threshold",
+			map[string]string{"metric": "http_req_failed", "threshold": "rate<0.5", "scenario": "a"},
+			map[string]interface{}{"value": 0.5, "passed": false, "samples": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"k6_threshold",
+			map[string]string{"metric": "http_req_failed", "threshold": "rate<0.5", "scenario": "b"},
+			map[string]interface{}{"value": float64(0), "passed": true, "samples": 1},
+			time.Unix(0, 0),
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "k6_threshold" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
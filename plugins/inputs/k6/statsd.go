@@ -0,0 +1,98 @@
+package k6
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// startStatsd starts a UDP listener for the k6 StatsD output
+func (k *K6) startStatsd() error {
+	conn, err := net.ListenPacket("udp", k.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	k.conn = conn
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		buf := make([]byte, 65535)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					k.Log.Errorf("Reading failed: %v", err)
+				}
+				return
+			}
+
+			now := time.Now()
+			for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+				if len(bytes.TrimSpace(line)) == 0 {
+					continue
+				}
+				m, err := k.parseStatsd(string(line), now)
+				if err != nil {
+					k.Log.Debugf("Parsing sample %q failed: %v", line, err)
+					continue
+				}
+				k.add(m)
+			}
+		}
+	}()
+	k.Log.Infof("Listening for k6 StatsD output on %s", conn.LocalAddr())
+
+	return nil
+}
+
+// parseStatsd parses a StatsD line of the form
+// "<name>:<value>|<type>[|@<sample rate>][|#<tag>:<value>,...]" using the
+// DogStatsD extension for tags as sent by k6 with tags enabled
+func (k *K6) parseStatsd(line string, now time.Time) (telegraf.Metric, error) {
+	name, rest, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found || name == "" {
+		return nil, errors.New("missing metric name")
+	}
+
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 {
+		return nil, errors.New("missing metric type")
+	}
+
+	// Counters, gauges and timings (trends) are all reported as single
+	// samples with the value of the k6 sample
+	switch parts[1] {
+	case "c", "g", "ms", "h", "d":
+	default:
+		return nil, fmt.Errorf("unsupported metric type %q", parts[1])
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	tags := make(map[string]string)
+	for _, part := range parts[2:] {
+		if !strings.HasPrefix(part, "#") {
+			// Ignore the sample rate as k6 sends all samples
+			continue
+		}
+		for _, tag := range strings.Split(part[1:], ",") {
+			key, val, _ := strings.Cut(tag, ":")
+			if key != "" && val != "" {
+				tags[key] = val
+			}
+		}
+	}
+
+	fields := map[string]interface{}{"value": value}
+	return metric.New(k.trimNamespace(name), tags, fields, now), nil
+}
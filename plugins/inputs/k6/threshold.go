@@ -0,0 +1,135 @@
+package k6
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var thresholdRe = regexp.MustCompile(`^\s*(avg|min|max|med|count|rate|value|p\(\s*([0-9.]+)\s*\))\s*(<=|>=|===|==|!=|<|>)\s*(\S+)\s*$`)
+
+// Threshold defines the conditions evaluated for a k6 metric
+type Threshold struct {
+	Metric     string   `toml:"metric"`
+	Conditions []string `toml:"conditions"`
+
+	conditions []*condition
+}
+
+// condition is a k6 threshold expression such as "p(95)<500"
+type condition struct {
+	expression  string
+	aggregation string
+	percentile  float64
+	operator    string
+	limit       float64
+}
+
+func parseCondition(expression string) (*condition, error) {
+	groups := thresholdRe.FindStringSubmatch(expression)
+	if groups == nil {
+		return nil, fmt.Errorf("invalid threshold %q", expression)
+	}
+
+	c := &condition{
+		expression:  expression,
+		aggregation: groups[1],
+		operator:    groups[3],
+	}
+	if groups[2] != "" {
+		p, err := strconv.ParseFloat(groups[2], 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile in threshold %q", expression)
+		}
+		c.aggregation = "p"
+		c.percentile = p
+	}
+	if c.operator == "===" {
+		c.operator = "=="
+	}
+
+	limit, err := strconv.ParseFloat(groups[4], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value in threshold %q", expression)
+	}
+	c.limit = limit
+
+	return c, nil
+}
+
+// evaluate computes the aggregated value of the samples and checks it
+// against the limit
+func (c *condition) evaluate(samples []float64) (float64, bool) {
+	value := aggregate(c.aggregation, c.percentile, samples)
+
+	var passed bool
+	switch c.operator {
+	case "<":
+		passed = value < c.limit
+	case "<=":
+		passed = value <= c.limit
+	case ">":
+		passed = value > c.limit
+	case ">=":
+		passed = value >= c.limit
+	case "==":
+		passed = value == c.limit
+	case "!=":
+		passed = value != c.limit
+	}
+	return value, passed
+}
+
+// aggregate follows the semantic of the k6 metric sinks: "count" sums the
+// values like a k6 counter, "rate" is the fraction of non-zero values like a
+// k6 rate and "value" is the last value like a k6 gauge.
+func aggregate(aggregation string, percentile float64, samples []float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+
+	switch aggregation {
+	case "count":
+		var sum float64
+		for _, v := range samples {
+			sum += v
+		}
+		return sum
+	case "rate":
+		var nonzero int
+		for _, v := range samples {
+			if v != 0 {
+				nonzero++
+			}
+		}
+		return float64(nonzero) / float64(len(samples))
+	case "value":
+		return samples[len(samples)-1]
+	case "avg":
+		var sum float64
+		for _, v := range samples {
+			sum += v
+		}
+		return sum / float64(len(samples))
+	case "min":
+		return slices.Min(samples)
+	case "max":
+		return slices.Max(samples)
+	case "med":
+		percentile = 50
+	}
+
+	// Compute the percentile using linear interpolation between the
+	// closest ranks as k6 does
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	rank := percentile / 100 * float64(len(sorted)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	if lower == upper {
+		return sorted[int(lower)]
+	}
+	return sorted[int(lower)] + (sorted[int(upper)]-sorted[int(lower)])*(rank-lower)
+}
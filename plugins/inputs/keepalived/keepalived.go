@@ -0,0 +1,198 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package keepalived
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// VRRP instance states as defined by keepalived
+var stateNames = map[int]string{
+	0: "INIT",
+	1: "BACKUP",
+	2: "MASTER",
+	3: "FAULT",
+}
+
+type Keepalived struct {
+	JSONFile   string          `toml:"json_file"`
+	PIDFile    string          `toml:"pid_file"`
+	JSONSignal int             `toml:"json_signal"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+
+	// last known state of the VRRP instances for detecting transitions
+	states map[string]int
+}
+
+type instance struct {
+	Data  instanceData     `json:"data"`
+	Stats map[string]int64 `json:"stats"`
+}
+
+type instanceData struct {
+	Name              string   `json:"iname"`
+	Interface         string   `json:"ifp_ifname"`
+	VRID              int      `json:"vrid"`
+	State             int      `json:"state"`
+	WantState         int      `json:"wantstate"`
+	BasePriority      int64    `json:"base_priority"`
+	EffectivePriority int64    `json:"effective_priority"`
+	LastTransition    float64  `json:"last_transition"`
+	VIPs              []string `json:"vips"`
+}
+
+func (*Keepalived) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *Keepalived) Init() error {
+	if k.JSONFile == "" {
+		return errors.New("json_file cannot be empty")
+	}
+	if k.PIDFile != "" && k.JSONSignal <= 0 {
+		return fmt.Errorf("invalid json_signal %d", k.JSONSignal)
+	}
+	k.states = make(map[string]int)
+	return nil
+}
+
+func (k *Keepalived) Gather(acc telegraf.Accumulator) error {
+	if k.PIDFile != "" {
+		if err := k.triggerDump(); err != nil {
+			return err
+		}
+	}
+
+	buf, err := os.ReadFile(k.JSONFile)
+	if err != nil {
+		return fmt.Errorf("reading statistics failed: %w", err)
+	}
+	var instances []instance
+	if err := json.Unmarshal(buf, &instances); err != nil {
+		return fmt.Errorf("parsing statistics failed: %w", err)
+	}
+
+	now := time.Now()
+	for _, inst := range instances {
+		k.addInstance(acc, &inst, now)
+	}
+	return nil
+}
+
+// triggerDump sends the JSON signal to keepalived and waits for the
+// statistics file to be rewritten
+func (k *Keepalived) triggerDump() error {
+	buf, err := os.ReadFile(k.PIDFile)
+	if err != nil {
+		return fmt.Errorf("reading PID file failed: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return fmt.Errorf("parsing PID file failed: %w", err)
+	}
+
+	var previous time.Time
+	if info, err := os.Stat(k.JSONFile); err == nil {
+		previous = info.ModTime()
+	}
+
+	if err := syscall.Kill(pid, syscall.Signal(k.JSONSignal)); err != nil {
+		return fmt.Errorf("sending signal %d to process %d failed: %w", k.JSONSignal, pid, err)
+	}
+
+	deadline := time.Now().Add(time.Duration(k.Timeout))
+	for {
+		if info, err := os.Stat(k.JSONFile); err == nil && !info.ModTime().Equal(previous) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %q to be written", k.JSONFile)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (k *Keepalived) addInstance(acc telegraf.Accumulator, inst *instance, now time.Time) {
+	data := &inst.Data
+	tags := map[string]string{
+		"instance":  data.Name,
+		"interface": data.Interface,
+		"vrid":      strconv.Itoa(data.VRID),
+	}
+
+	fields := map[string]interface{}{
+		"state":              stateName(data.State),
+		"state_code":         data.State,
+		"wanted_state":       stateName(data.WantState),
+		"base_priority":      data.BasePriority,
+		"effective_priority": data.EffectivePriority,
+		"vips":               len(data.VIPs),
+	}
+	if data.LastTransition > 0 {
+		fields["last_transition"] = int64(data.LastTransition)
+	}
+	for name, value := range inst.Stats {
+		fields[name] = value
+	}
+	acc.AddFields("keepalived_vrrp", fields, tags, now)
+
+	// Report the ownership of the virtual IPs
+	for _, vip := range data.VIPs {
+		// The entries might contain the device and scope after the address
+		address, _, _ := strings.Cut(strings.TrimSpace(vip), " ")
+		if address == "" {
+			continue
+		}
+		vipTags := map[string]string{
+			"instance": data.Name,
+			"vip":      address,
+		}
+		acc.AddFields("keepalived_vip", map[string]interface{}{"owner": data.State == 2}, vipTags, now)
+	}
+
+	// Emit an event if the state changed since the last gather
+	previous, found := k.states[data.Name]
+	k.states[data.Name] = data.State
+	if found && previous != data.State {
+		k.Log.Infof("VRRP instance %q changed from %s to %s", data.Name, stateName(previous), stateName(data.State))
+		transitionFields := map[string]interface{}{
+			"from_state": stateName(previous),
+			"to_state":   stateName(data.State),
+		}
+		acc.AddFields("keepalived_transition", transitionFields, tags, now)
+	}
+}
+
+func stateName(state int) string {
+	if name, found := stateNames[state]; found {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+func init() {
+	inputs.Add("keepalived", func() telegraf.Input {
+		return &Keepalived{
+			JSONFile:   "/tmp/keepalived.json",
+			JSONSignal: 36,
+			Timeout:    config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package keepalived
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Keepalived struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*Keepalived) SampleConfig() string { return sampleConfig }
+
+func (k *Keepalived) Init() error {
+	k.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Keepalived) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("keepalived", func() telegraf.Input {
+		return &Keepalived{}
+	})
+}
@@ -0,0 +1,206 @@
+//go:build linux
+
+package keepalived
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Keepalived{}
+	require.ErrorContains(t, plugin.Init(), "json_file cannot be empty")
+
+	plugin = &Keepalived{JSONFile: "stats.json", PIDFile: "keepalived.pid"}
+	require.ErrorContains(t, plugin.Init(), "invalid json_signal 0")
+}
+
+func TestGather(t *testing.T) {
+	plugin := &Keepalived{
+		JSONFile: filepath.Join("testdata", "keepalived.json"),
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"keepalived_vrrp",
+			map[string]string{"instance": "VI_1", "interface": "eth0", "vrid": "51"},
+			map[string]interface{}{
+				"state":               "MASTER",
+				"state_code":          2,
+				"wanted_state":        "MASTER",
+				"base_priority":       int64(150),
+				"effective_priority":  int64(150),
+				"vips":                2,
+				"last_transition":     int64(1700000000),
+				"advert_rcvd":         int64(12),
+				"advert_sent":         int64(3600),
+				"become_master":       int64(2),
+				"release_master":      int64(1),
+				"packet_len_err":      int64(0),
+				"advert_interval_err": int64(0),
+				"ip_ttl_err":          int64(0),
+				"invalid_type_rcvd":   int64(0),
+				"addr_list_err":       int64(0),
+				"invalid_authtype":    int64(0),
+				"authtype_mismatch":   int64(0),
+				"auth_failure":        int64(0),
+				"pri_zero_rcvd":       int64(0),
+				"pri_zero_sent":       int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"keepalived_vip",
+			map[string]string{"instance": "VI_1", "vip": "192.168.10.100/24"},
+			map[string]interface{}{"owner": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"keepalived_vip",
+			map[string]string{"instance": "VI_1", "vip": "192.168.10.101/24"},
+			map[string]interface{}{"owner": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"keepalived_vrrp",
+			map[string]string{"instance": "VI_2", "interface": "eth1", "vrid": "52"},
+			map[string]interface{}{
+				"state":              "BACKUP",
+				"state_code":         1,
+				"wanted_state":       "BACKUP",
+				"base_priority":      int64(100),
+				"effective_priority": int64(90),
+				"vips":               1,
+				"last_transition":    int64(1700000100),
+				"advert_rcvd":        int64(3500),
+				"advert_sent":        int64(0),
+				"become_master":      int64(0),
+				"release_master":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"keepalived_vip",
+			map[string]string{"instance": "VI_2", "vip": "10.0.0.1"},
+			map[string]interface{}{"owner": false},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestTransition(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "keepalived.json"))
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "keepalived.json")
+	require.NoError(t, os.WriteFile(filename, buf, 0600))
+
+	plugin := &Keepalived{
+		JSONFile: filename,
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// No transitions are reported on the first gather
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.False(t, acc.HasMeasurement("keepalived_transition"))
+
+	// Fail over VI_2 to master
+	modified := strings.Replace(string(buf), `"state": 1,`, `"state": 2,`, 1)
+	require.NoError(t, os.WriteFile(filename, []byte(modified), 0600))
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"keepalived_transition",
+			map[string]string{"instance": "VI_2", "interface": "eth1", "vrid": "52"},
+			map[string]interface{}{"from_state": "BACKUP", "to_state": "MASTER"},
+			time.Unix(0, 0),
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "keepalived_transition" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestTriggerDump(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "keepalived.json"))
+	require.NoError(t, err)
+
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "keepalived.json")
+	pidfile := filepath.Join(tmpdir, "keepalived.pid")
+	require.NoError(t, os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600))
+
+	// Simulate keepalived writing the statistics on receiving the signal
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGUSR2)
+	defer signal.Stop(sigC)
+	done := make(chan error, 1)
+	go func() {
+		<-sigC
+		done <- os.WriteFile(filename, buf, 0600)
+	}()
+
+	plugin := &Keepalived{
+		JSONFile:   filename,
+		PIDFile:    pidfile,
+		JSONSignal: int(syscall.SIGUSR2),
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.NoError(t, <-done)
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestTriggerDumpTimeout(t *testing.T) {
+	tmpdir := t.TempDir()
+	pidfile := filepath.Join(tmpdir, "keepalived.pid")
+	require.NoError(t, os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0600))
+
+	// Ignore the signal without writing the file
+	signal.Ignore(syscall.SIGUSR2)
+	defer signal.Reset(syscall.SIGUSR2)
+
+	plugin := &Keepalived{
+		JSONFile:   filepath.Join(tmpdir, "keepalived.json"),
+		PIDFile:    pidfile,
+		JSONSignal: int(syscall.SIGUSR2),
+		Timeout:    config.Duration(100 * time.Millisecond),
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "timeout waiting for")
+}
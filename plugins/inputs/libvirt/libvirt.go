@@ -44,6 +44,7 @@ type Libvirt struct {
 	utils              utils
 	metricNumber       uint32
 	vcpuMappingEnabled bool
+	deviceTagsEnabled  bool
 	domainsMap         map[string]struct{}
 }
 
@@ -173,6 +174,11 @@ func (l *Libvirt) validateAdditionalStatistics() error {
 				return fmt.Errorf("duplicated additional statistic in config: %q", stat)
 			}
 			l.vcpuMappingEnabled = true
+		case "device_tags":
+			if l.deviceTagsEnabled {
+				return fmt.Errorf("duplicated additional statistic in config: %q", stat)
+			}
+			l.deviceTagsEnabled = true
 		default:
 			return fmt.Errorf("additional statistics: %v is not supported by this plugin", stat)
 		}
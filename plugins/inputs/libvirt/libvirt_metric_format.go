@@ -31,11 +31,11 @@ func (l *Libvirt) addMetrics(stats []golibvirt.DomainStatsRecord, vcpuInfos map[
 			case "vcpu":
 				l.addVcpuMetrics(values, domainName, vcpuInfos[domainName], acc)
 			case "net":
-				addInterfaceMetrics(values, domainName, acc)
+				addInterfaceMetrics(values, domainName, l.deviceTagsEnabled, acc)
 			case "perf":
 				addPerfMetrics(values, domainName, acc)
 			case "block":
-				addBlockMetrics(values, domainName, acc)
+				addBlockMetrics(values, domainName, l.deviceTagsEnabled, acc)
 			case "iothread":
 				addIothreadMetrics(values, domainName, acc)
 			case "memory":
@@ -283,7 +283,7 @@ func (l *Libvirt) getCurrentPCPUForVCPU(vcpuID string, vcpuInfos []vcpuAffinity)
 	return -1
 }
 
-func addInterfaceMetrics(metrics map[string]golibvirt.TypedParamValue, domainName string, acc telegraf.Accumulator) {
+func addInterfaceMetrics(metrics map[string]golibvirt.TypedParamValue, domainName string, deviceTags bool, acc telegraf.Accumulator) {
 	var netTotalFields = make(map[string]interface{})
 	var netData = make(map[string]map[string]interface{})
 
@@ -325,6 +325,7 @@ func addInterfaceMetrics(metrics map[string]golibvirt.TypedParamValue, domainNam
 				"domain_name":  domainName,
 				"interface_id": netID,
 			}
+			addDeviceTag(netTags, netFields, deviceTags)
 			acc.AddFields("libvirt_net", netFields, netTags)
 		}
 	}
@@ -351,7 +352,7 @@ func addPerfMetrics(metrics map[string]golibvirt.TypedParamValue, domainName str
 	}
 }
 
-func addBlockMetrics(metrics map[string]golibvirt.TypedParamValue, domainName string, acc telegraf.Accumulator) {
+func addBlockMetrics(metrics map[string]golibvirt.TypedParamValue, domainName string, deviceTags bool, acc telegraf.Accumulator) {
 	var blockTotalFields = make(map[string]interface{})
 	var blockData = make(map[string]map[string]interface{})
 
@@ -394,11 +395,23 @@ func addBlockMetrics(metrics map[string]golibvirt.TypedParamValue, domainName st
 				"domain_name": domainName,
 				"block_id":    blockID,
 			}
+			addDeviceTag(blockTags, blockFields, deviceTags)
 			acc.AddFields("libvirt_block", blockFields, blockTags)
 		}
 	}
 }
 
+// addDeviceTag adds the device name, e.g. "vda" or "vnet0", as tag if enabled
+// to allow identifying the device across changes of the device index
+func addDeviceTag(tags map[string]string, fields map[string]interface{}, enabled bool) {
+	if !enabled {
+		return
+	}
+	if name, ok := fields["name"].(string); ok && name != "" {
+		tags["device"] = name
+	}
+}
+
 func addIothreadMetrics(metrics map[string]golibvirt.TypedParamValue, domainName string, acc telegraf.Accumulator) {
 	var iothreadTotalFields = make(map[string]interface{})
 	var iothreadData = make(map[string]map[string]interface{})
@@ -2,6 +2,7 @@ package libvirt
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -235,6 +236,41 @@ func TestLibvirt_GatherMetrics(t *testing.T) {
 	}
 }
 
+func TestLibvirt_GatherDeviceTags(t *testing.T) {
+	var acc testutil.Accumulator
+	mockUtils := mockLibvirtUtils{}
+	l := Libvirt{
+		utils:                &mockUtils,
+		Log:                  testutil.Logger{},
+		StatisticsGroups:     []string{"interface", "block"},
+		AdditionalStatistics: []string{"device_tags"},
+	}
+	require.NoError(t, l.Init())
+
+	stats := append(slices.Clone(interfaceStats), blockStats...)
+	mockUtils.On("ensureConnected", mock.Anything).Return(nil).Once().
+		On("gatherAllDomains", mock.Anything).Return(domains, nil).Once().
+		On("gatherStatsForDomains", mock.Anything, mock.Anything).Return(stats, nil).Once()
+
+	require.NoError(t, l.Gather(&acc))
+	mockUtils.AssertExpectations(t)
+
+	var found int
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "libvirt_net" && m.Name() != "libvirt_block" {
+			require.False(t, m.HasTag("device"), "unexpected device tag for %q", m.Name())
+			continue
+		}
+		name, ok := m.GetField("name")
+		require.True(t, ok)
+		device, ok := m.GetTag("device")
+		require.True(t, ok, "missing device tag for %q", m.Name())
+		require.Equal(t, name, device)
+		found++
+	}
+	require.Positive(t, found)
+}
+
 func TestLibvirt_validateLibvirtUri(t *testing.T) {
 	t.Run("no error on good uri provided", func(t *testing.T) {
 		l := Libvirt{
@@ -0,0 +1,205 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package linuxptp
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LinuxPTP struct {
+	Sockets      []string        `toml:"sockets"`
+	DomainNumber uint8           `toml:"domain_number"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+
+	source   portIdentity
+	sequence uint16
+}
+
+func (*LinuxPTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *LinuxPTP) Init() error {
+	if len(l.Sockets) == 0 {
+		return errors.New("no sockets configured")
+	}
+
+	// Identify the requests by process similar to linuxptp's pmc
+	l.source = portIdentity{PortNumber: uint16(os.Getpid())}
+
+	return nil
+}
+
+func (l *LinuxPTP) Gather(acc telegraf.Accumulator) error {
+	for _, socket := range l.Sockets {
+		if err := l.gatherSocket(acc, socket); err != nil {
+			acc.AddError(fmt.Errorf("querying %q failed: %w", socket, err))
+		}
+	}
+	return nil
+}
+
+func (l *LinuxPTP) gatherSocket(acc telegraf.Accumulator, socket string) error {
+	// Use an abstract socket address for receiving the responses to avoid
+	// permission issues when creating a socket file next to the ptp4l socket.
+	local := &net.UnixAddr{Name: "@telegraf-linuxptp-" + uuid.NewString(), Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", local)
+	if err != nil {
+		return fmt.Errorf("creating local socket failed: %w", err)
+	}
+	defer conn.Close()
+
+	remote := &net.UnixAddr{Name: socket, Net: "unixgram"}
+
+	var dds defaultDataSet
+	if err := l.get(conn, remote, idDefaultDataSet, &dds); err != nil {
+		return fmt.Errorf("getting default data set failed: %w", err)
+	}
+	var cds currentDataSet
+	if err := l.get(conn, remote, idCurrentDataSet, &cds); err != nil {
+		return fmt.Errorf("getting current data set failed: %w", err)
+	}
+	var pds parentDataSet
+	if err := l.get(conn, remote, idParentDataSet, &pds); err != nil {
+		return fmt.Errorf("getting parent data set failed: %w", err)
+	}
+	var ts timeStatusNP
+	if err := l.get(conn, remote, idTimeStatusNP, &ts); err != nil {
+		return fmt.Errorf("getting time status failed: %w", err)
+	}
+
+	tags := map[string]string{
+		"socket":         socket,
+		"clock_identity": dds.ClockIdentity.String(),
+	}
+	fields := map[string]interface{}{
+		"domain":                        dds.DomainNumber,
+		"steps_removed":                 cds.StepsRemoved,
+		"offset_from_master":            scaledNanoseconds(cds.OffsetFromMaster),
+		"mean_path_delay":               scaledNanoseconds(cds.MeanPathDelay),
+		"master_offset":                 ts.MasterOffset,
+		"gm_present":                    ts.GmPresent != 0,
+		"gm_identity":                   pds.GrandmasterIdentity.String(),
+		"gm_priority1":                  pds.GrandmasterPriority1,
+		"gm_priority2":                  pds.GrandmasterPriority2,
+		"gm_clock_class":                pds.GrandmasterClockQuality.Class,
+		"gm_clock_accuracy":             pds.GrandmasterClockQuality.Accuracy,
+		"gm_offset_scaled_log_variance": pds.GrandmasterClockQuality.OffsetScaledLogVariance,
+	}
+	acc.AddFields("linuxptp", fields, tags)
+
+	ports, err := l.query(conn, remote, idPortDataSet, int(dds.NumberPorts))
+	if err != nil {
+		return fmt.Errorf("getting port data sets failed: %w", err)
+	}
+	for _, data := range ports {
+		var p portDataSet
+		if err := decodeData(data, &p); err != nil {
+			acc.AddError(fmt.Errorf("decoding port data set of %q failed: %w", socket, err))
+			continue
+		}
+
+		state, found := portStates[p.PortState]
+		if !found {
+			state = "unknown"
+		}
+		mechanism, found := delayMechanisms[p.DelayMechanism]
+		if !found {
+			mechanism = "unknown"
+		}
+
+		ptags := map[string]string{
+			"socket":         socket,
+			"clock_identity": p.PortIdentity.ClockIdentity.String(),
+			"port":           p.PortIdentity.String(),
+		}
+		pfields := map[string]interface{}{
+			"port_state":                 state,
+			"port_state_code":            p.PortState,
+			"peer_mean_path_delay":       scaledNanoseconds(p.PeerMeanPathDelay),
+			"delay_mechanism":            mechanism,
+			"log_sync_interval":          p.LogSyncInterval,
+			"log_announce_interval":      p.LogAnnounceInterval,
+			"log_min_delay_req_interval": p.LogMinDelayReqInterval,
+		}
+		acc.AddFields("linuxptp_port", pfields, ptags)
+	}
+
+	return nil
+}
+
+// get queries the data set with the given management ID and decodes the
+// response into the given value
+func (l *LinuxPTP) get(conn *net.UnixConn, remote *net.UnixAddr, id uint16, v interface{}) error {
+	responses, err := l.query(conn, remote, id, 1)
+	if err != nil {
+		return err
+	}
+	return decodeData(responses[0], v)
+}
+
+// query sends a GET request for the management ID and waits for the given
+// number of responses, e.g. one per port for port data sets.
+func (l *LinuxPTP) query(conn *net.UnixConn, remote *net.UnixAddr, id uint16, count int) ([][]byte, error) {
+	l.sequence++
+	sequence := l.sequence
+
+	req := encodeGet(l.DomainNumber, l.source, sequence, id)
+	if _, err := conn.WriteToUnix(req, remote); err != nil {
+		return nil, fmt.Errorf("sending request failed: %w", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(time.Duration(l.Timeout))); err != nil {
+		return nil, fmt.Errorf("setting deadline failed: %w", err)
+	}
+
+	responses := make([][]byte, 0, count)
+	buf := make([]byte, 1500)
+	for len(responses) < count {
+		n, _, err := conn.ReadFromUnix(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				return nil, fmt.Errorf("timeout after receiving %d of %d responses", len(responses), count)
+			}
+			return nil, fmt.Errorf("receiving response failed: %w", err)
+		}
+
+		resp, err := decodeResponse(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		// Skip late responses of previous requests
+		if resp.sequence != sequence || resp.id != id {
+			continue
+		}
+		responses = append(responses, resp.data)
+	}
+
+	return responses, nil
+}
+
+func init() {
+	inputs.Add("linuxptp", func() telegraf.Input {
+		return &LinuxPTP{
+			Sockets: []string{"/var/run/ptp4l"},
+			Timeout: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package linuxptp
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LinuxPTP struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*LinuxPTP) SampleConfig() string { return sampleConfig }
+
+func (l *LinuxPTP) Init() error {
+	l.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*LinuxPTP) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("linuxptp", func() telegraf.Input {
+		return &LinuxPTP{}
+	})
+}
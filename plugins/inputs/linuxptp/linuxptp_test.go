@@ -0,0 +1,302 @@
+//go:build linux
+
+package linuxptp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var (
+	localClock = clockIdentity{0x00, 0x1b, 0x21, 0xff, 0xfe, 0x8a, 0x3c, 0x10}
+	gmClock    = clockIdentity{0xec, 0x46, 0x70, 0xff, 0xfe, 0x0a, 0x91, 0x2e}
+)
+
+// fakePTP4L mimics the management interface of ptp4l by responding to the
+// GET requests with the given data sets
+type fakePTP4L struct {
+	conn     *net.UnixConn
+	datasets map[uint16][]interface{}
+	errors   map[uint16]uint16
+}
+
+func newFakePTP4L(t *testing.T) *fakePTP4L {
+	addr := &net.UnixAddr{Name: filepath.Join(t.TempDir(), "ptp4l"), Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &fakePTP4L{
+		conn: conn,
+		datasets: map[uint16][]interface{}{
+			idDefaultDataSet: {
+				defaultDataSet{
+					Flags:         0x01,
+					NumberPorts:   2,
+					Priority1:     128,
+					ClockQuality:  clockQuality{Class: 248, Accuracy: 0xfe, OffsetScaledLogVariance: 0xffff},
+					Priority2:     128,
+					ClockIdentity: localClock,
+					DomainNumber:  24,
+				},
+			},
+			idCurrentDataSet: {
+				currentDataSet{
+					StepsRemoved:     1,
+					OffsetFromMaster: -12 * 65536,
+					MeanPathDelay:    1536*65536 + 32768,
+				},
+			},
+			idParentDataSet: {
+				parentDataSet{
+					ParentPortIdentity:      portIdentity{ClockIdentity: gmClock, PortNumber: 1},
+					GrandmasterPriority1:    128,
+					GrandmasterClockQuality: clockQuality{Class: 6, Accuracy: 0x21, OffsetScaledLogVariance: 0x4e5d},
+					GrandmasterPriority2:    128,
+					GrandmasterIdentity:     gmClock,
+				},
+			},
+			idTimeStatusNP: {
+				timeStatusNP{
+					MasterOffset: -12,
+					IngressTime:  1718352000000000000,
+					GmPresent:    1,
+					GmIdentity:   gmClock,
+				},
+			},
+			idPortDataSet: {
+				portDataSet{
+					PortIdentity:        portIdentity{ClockIdentity: localClock, PortNumber: 1},
+					PortState:           9,
+					PeerMeanPathDelay:   0,
+					LogAnnounceInterval: 1,
+					LogSyncInterval:     -4,
+					DelayMechanism:      1,
+					VersionNumber:       2,
+				},
+				portDataSet{
+					PortIdentity:        portIdentity{ClockIdentity: localClock, PortNumber: 2},
+					PortState:           6,
+					PeerMeanPathDelay:   512 * 65536,
+					LogAnnounceInterval: 1,
+					LogSyncInterval:     -3,
+					DelayMechanism:      2,
+					VersionNumber:       2,
+				},
+			},
+		},
+		errors: make(map[uint16]uint16),
+	}
+}
+
+func (f *fakePTP4L) address() string {
+	return f.conn.LocalAddr().String()
+}
+
+func (f *fakePTP4L) serve(t *testing.T) {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := f.conn.ReadFromUnix(buf)
+		if err != nil {
+			return
+		}
+
+		r := bytes.NewReader(buf[:n])
+		var h header
+		var mh managementHeader
+		var tlv tlvHeader
+		var id uint16
+		require.NoError(t, binary.Read(r, binary.BigEndian, &h))
+		require.NoError(t, binary.Read(r, binary.BigEndian, &mh))
+		require.NoError(t, binary.Read(r, binary.BigEndian, &tlv))
+		require.NoError(t, binary.Read(r, binary.BigEndian, &id))
+		require.Equal(t, uint8(actionGet), mh.Action)
+		require.Equal(t, int(h.MessageLength), n)
+
+		if code, found := f.errors[id]; found {
+			var data bytes.Buffer
+			require.NoError(t, binary.Write(&data, binary.BigEndian, []uint16{code, id, 0, 0}))
+			_, err := f.conn.WriteToUnix(encodeTestResponse(h.SequenceID, tlvManagementErrorStatus, data.Bytes()), addr)
+			require.NoError(t, err)
+			continue
+		}
+
+		for _, ds := range f.datasets[id] {
+			var data bytes.Buffer
+			require.NoError(t, binary.Write(&data, binary.BigEndian, id))
+			require.NoError(t, binary.Write(&data, binary.BigEndian, ds))
+			if data.Len()%2 != 0 {
+				data.WriteByte(0)
+			}
+			_, err := f.conn.WriteToUnix(encodeTestResponse(h.SequenceID, tlvManagement, data.Bytes()), addr)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func encodeTestResponse(sequence, tlvType uint16, data []byte) []byte {
+	h := header{
+		MessageType:        messageTypeManagement,
+		Version:            ptpVersion,
+		SourcePortIdentity: portIdentity{ClockIdentity: localClock},
+		SequenceID:         sequence,
+		Control:            controlManagement,
+		LogMessageInterval: logIntervalUnused,
+	}
+	mh := managementHeader{Action: actionResponse}
+	tlv := tlvHeader{Type: tlvType, Length: uint16(len(data))}
+	h.MessageLength = uint16(binary.Size(h) + binary.Size(mh) + binary.Size(tlv) + len(data))
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, h)
+	_ = binary.Write(&buf, binary.BigEndian, mh)
+	_ = binary.Write(&buf, binary.BigEndian, tlv)
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestGather(t *testing.T) {
+	server := newFakePTP4L(t)
+	go server.serve(t)
+
+	plugin := &LinuxPTP{
+		Sockets:      []string{server.address()},
+		DomainNumber: 24,
+		Timeout:      config.Duration(time.Second),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"linuxptp",
+			map[string]string{
+				"socket":         server.address(),
+				"clock_identity": "001b21.fffe.8a3c10",
+			},
+			map[string]interface{}{
+				"domain":                        uint64(24),
+				"steps_removed":                 uint64(1),
+				"offset_from_master":            float64(-12),
+				"mean_path_delay":               float64(1536.5),
+				"master_offset":                 int64(-12),
+				"gm_present":                    true,
+				"gm_identity":                   "ec4670.fffe.0a912e",
+				"gm_priority1":                  uint64(128),
+				"gm_priority2":                  uint64(128),
+				"gm_clock_class":                uint64(6),
+				"gm_clock_accuracy":             uint64(0x21),
+				"gm_offset_scaled_log_variance": uint64(0x4e5d),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"linuxptp_port",
+			map[string]string{
+				"socket":         server.address(),
+				"clock_identity": "001b21.fffe.8a3c10",
+				"port":           "001b21.fffe.8a3c10-1",
+			},
+			map[string]interface{}{
+				"port_state":                 "slave",
+				"port_state_code":            uint64(9),
+				"peer_mean_path_delay":       float64(0),
+				"delay_mechanism":            "E2E",
+				"log_sync_interval":          int64(-4),
+				"log_announce_interval":      int64(1),
+				"log_min_delay_req_interval": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"linuxptp_port",
+			map[string]string{
+				"socket":         server.address(),
+				"clock_identity": "001b21.fffe.8a3c10",
+				"port":           "001b21.fffe.8a3c10-2",
+			},
+			map[string]interface{}{
+				"port_state":                 "master",
+				"port_state_code":            uint64(6),
+				"peer_mean_path_delay":       float64(512),
+				"delay_mechanism":            "P2P",
+				"log_sync_interval":          int64(-3),
+				"log_announce_interval":      int64(1),
+				"log_min_delay_req_interval": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherManagementError(t *testing.T) {
+	server := newFakePTP4L(t)
+	server.errors[idTimeStatusNP] = 0x0002 // NO_SUCH_ID
+	go server.serve(t)
+
+	plugin := &LinuxPTP{
+		Sockets: []string{server.address()},
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "management error 0x0002 for ID 0xc000")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestGatherTimeout(t *testing.T) {
+	server := newFakePTP4L(t)
+	// Only one of the two ports responds
+	server.datasets[idPortDataSet] = server.datasets[idPortDataSet][:1]
+	go server.serve(t)
+
+	plugin := &LinuxPTP{
+		Sockets: []string{server.address()},
+		Timeout: config.Duration(100 * time.Millisecond),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "timeout after receiving 1 of 2 responses")
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestEncodeGet(t *testing.T) {
+	msg := encodeGet(0, portIdentity{PortNumber: 0x1234}, 7, idCurrentDataSet)
+	expected := []byte{
+		// Header
+		0x0d, 0x02, 0x00, 0x36, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0x34,
+		0x00, 0x07, 0x04, 0x7f,
+		// Management header with wildcard target port
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x00, 0x00, 0x00, 0x00,
+		// Management TLV
+		0x00, 0x01, 0x00, 0x02, 0x20, 0x01,
+	}
+	require.Equal(t, expected, msg)
+}
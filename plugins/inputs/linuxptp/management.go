@@ -0,0 +1,260 @@
+package linuxptp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PTP management message constants as defined in IEEE 1588-2008 and the
+// linuxptp specific extensions
+const (
+	messageTypeManagement = 0x0d
+	ptpVersion            = 0x02
+	controlManagement     = 0x04
+	logIntervalUnused     = 0x7f
+
+	actionGet      = 0x00
+	actionResponse = 0x02
+
+	tlvManagement            = 0x0001
+	tlvManagementErrorStatus = 0x0002
+
+	idDefaultDataSet = 0x2000
+	idCurrentDataSet = 0x2001
+	idParentDataSet  = 0x2002
+	idPortDataSet    = 0x2004
+	idTimeStatusNP   = 0xc000
+)
+
+var portStates = map[uint8]string{
+	1: "initializing",
+	2: "faulty",
+	3: "disabled",
+	4: "listening",
+	5: "pre_master",
+	6: "master",
+	7: "passive",
+	8: "uncalibrated",
+	9: "slave",
+}
+
+var delayMechanisms = map[uint8]string{
+	0x01: "E2E",
+	0x02: "P2P",
+	0xfe: "disabled",
+}
+
+type clockIdentity [8]byte
+
+// String formats the identity the same way as linuxptp does
+func (c clockIdentity) String() string {
+	return fmt.Sprintf("%02x%02x%02x.%02x%02x.%02x%02x%02x", c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7])
+}
+
+type portIdentity struct {
+	ClockIdentity clockIdentity
+	PortNumber    uint16
+}
+
+func (p portIdentity) String() string {
+	return fmt.Sprintf("%s-%d", p.ClockIdentity, p.PortNumber)
+}
+
+type header struct {
+	MessageType        uint8
+	Version            uint8
+	MessageLength      uint16
+	DomainNumber       uint8
+	Reserved1          uint8
+	Flags              uint16
+	Correction         int64
+	Reserved2          uint32
+	SourcePortIdentity portIdentity
+	SequenceID         uint16
+	Control            uint8
+	LogMessageInterval int8
+}
+
+type managementHeader struct {
+	TargetPortIdentity   portIdentity
+	StartingBoundaryHops uint8
+	BoundaryHops         uint8
+	Action               uint8
+	Reserved             uint8
+}
+
+type tlvHeader struct {
+	Type   uint16
+	Length uint16
+}
+
+type clockQuality struct {
+	Class                   uint8
+	Accuracy                uint8
+	OffsetScaledLogVariance uint16
+}
+
+type defaultDataSet struct {
+	Flags         uint8
+	Reserved1     uint8
+	NumberPorts   uint16
+	Priority1     uint8
+	ClockQuality  clockQuality
+	Priority2     uint8
+	ClockIdentity clockIdentity
+	DomainNumber  uint8
+	Reserved2     uint8
+}
+
+type currentDataSet struct {
+	StepsRemoved     uint16
+	OffsetFromMaster int64
+	MeanPathDelay    int64
+}
+
+type parentDataSet struct {
+	ParentPortIdentity                    portIdentity
+	ParentStats                           uint8
+	Reserved                              uint8
+	ObservedParentOffsetScaledLogVariance uint16
+	ObservedParentClockPhaseChangeRate    int32
+	GrandmasterPriority1                  uint8
+	GrandmasterClockQuality               clockQuality
+	GrandmasterPriority2                  uint8
+	GrandmasterIdentity                   clockIdentity
+}
+
+type portDataSet struct {
+	PortIdentity            portIdentity
+	PortState               uint8
+	LogMinDelayReqInterval  int8
+	PeerMeanPathDelay       int64
+	LogAnnounceInterval     int8
+	AnnounceReceiptTimeout  uint8
+	LogSyncInterval         int8
+	DelayMechanism          uint8
+	LogMinPdelayReqInterval int8
+	VersionNumber           uint8
+}
+
+type timeStatusNP struct {
+	MasterOffset               int64
+	IngressTime                int64
+	CumulativeScaledRateOffset int32
+	ScaledLastGmPhaseChange    int32
+	GmTimeBaseIndicator        uint16
+	LastGmPhaseChange          [12]byte
+	GmPresent                  int32
+	GmIdentity                 clockIdentity
+}
+
+// scaledNanoseconds converts the PTP TimeInterval type, nanoseconds
+// multiplied by 2^16, to nanoseconds
+func scaledNanoseconds(v int64) float64 {
+	return float64(v) / 65536.0
+}
+
+// encodeGet creates a management GET request for the given management ID
+// addressed to all ports of the clock
+func encodeGet(domain uint8, source portIdentity, sequence, id uint16) []byte {
+	target := portIdentity{PortNumber: 0xffff}
+	for i := range target.ClockIdentity {
+		target.ClockIdentity[i] = 0xff
+	}
+
+	h := header{
+		MessageType:        messageTypeManagement,
+		Version:            ptpVersion,
+		DomainNumber:       domain,
+		SourcePortIdentity: source,
+		SequenceID:         sequence,
+		Control:            controlManagement,
+		LogMessageInterval: logIntervalUnused,
+	}
+	mh := managementHeader{
+		TargetPortIdentity: target,
+		Action:             actionGet,
+	}
+	tlv := tlvHeader{Type: tlvManagement, Length: 2}
+	h.MessageLength = uint16(binary.Size(h) + binary.Size(mh) + binary.Size(tlv) + 2)
+
+	var buf bytes.Buffer
+	// Writing to a buffer cannot fail
+	_ = binary.Write(&buf, binary.BigEndian, h)
+	_ = binary.Write(&buf, binary.BigEndian, mh)
+	_ = binary.Write(&buf, binary.BigEndian, tlv)
+	_ = binary.Write(&buf, binary.BigEndian, id)
+
+	return buf.Bytes()
+}
+
+// response is a decoded management response
+type response struct {
+	sequence uint16
+	id       uint16
+	data     []byte
+}
+
+// decodeResponse decodes a management response message and returns the
+// management ID and data of the contained TLV
+func decodeResponse(msg []byte) (*response, error) {
+	r := bytes.NewReader(msg)
+
+	var h header
+	if err := binary.Read(r, binary.BigEndian, &h); err != nil {
+		return nil, fmt.Errorf("reading header failed: %w", err)
+	}
+	if h.MessageType&0x0f != messageTypeManagement {
+		return nil, fmt.Errorf("unexpected message type 0x%x", h.MessageType&0x0f)
+	}
+	if int(h.MessageLength) > len(msg) {
+		return nil, fmt.Errorf("message truncated, expected %d bytes but got %d", h.MessageLength, len(msg))
+	}
+
+	var mh managementHeader
+	if err := binary.Read(r, binary.BigEndian, &mh); err != nil {
+		return nil, fmt.Errorf("reading management header failed: %w", err)
+	}
+	if mh.Action&0x0f != actionResponse {
+		return nil, fmt.Errorf("unexpected action 0x%x", mh.Action&0x0f)
+	}
+
+	var tlv tlvHeader
+	if err := binary.Read(r, binary.BigEndian, &tlv); err != nil {
+		return nil, fmt.Errorf("reading TLV header failed: %w", err)
+	}
+	if tlv.Length < 2 || int(tlv.Length) > r.Len() {
+		return nil, fmt.Errorf("invalid TLV length %d", tlv.Length)
+	}
+	data := make([]byte, tlv.Length)
+	if _, err := r.Read(data); err != nil {
+		return nil, fmt.Errorf("reading TLV data failed: %w", err)
+	}
+
+	switch tlv.Type {
+	case tlvManagement:
+		return &response{
+			sequence: h.SequenceID,
+			id:       binary.BigEndian.Uint16(data[0:2]),
+			data:     data[2:],
+		}, nil
+	case tlvManagementErrorStatus:
+		// The error status contains the error ID followed by the management ID
+		if len(data) < 4 {
+			return nil, errors.New("management error status truncated")
+		}
+		return nil, fmt.Errorf("management error 0x%04x for ID 0x%04x", binary.BigEndian.Uint16(data[0:2]), binary.BigEndian.Uint16(data[2:4]))
+	}
+
+	return nil, fmt.Errorf("unexpected TLV type 0x%04x", tlv.Type)
+}
+
+// decodeData decodes the TLV data into the given data set
+func decodeData(data []byte, v interface{}) error {
+	if len(data) < binary.Size(v) {
+		return fmt.Errorf("data truncated, expected %d bytes but got %d", binary.Size(v), len(data))
+	}
+	return binary.Read(bytes.NewReader(data), binary.BigEndian, v)
+}
@@ -0,0 +1,262 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package macos
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Levels of the thermal pressure reported by powermetrics
+var thermalPressureLevels = map[string]int64{
+	"Nominal":  0,
+	"Moderate": 1,
+	"Heavy":    2,
+	"Trapping": 3,
+	"Sleeping": 4,
+}
+
+type MacOS struct {
+	Collect          []string        `toml:"collect"`
+	UseSudo          bool            `toml:"use_sudo"`
+	PathPowermetrics string          `toml:"path_powermetrics"`
+	PathIoreg        string          `toml:"path_ioreg"`
+	SampleDuration   config.Duration `toml:"sample_duration"`
+	Timeout          config.Duration `toml:"timeout"`
+	Log              telegraf.Logger `toml:"-"`
+
+	collect map[string]bool
+}
+
+func (*MacOS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (m *MacOS) init() error {
+	if len(m.Collect) == 0 {
+		m.Collect = []string{"cpu", "gpu", "thermal", "battery"}
+	}
+	m.collect = make(map[string]bool, len(m.Collect))
+	for _, c := range m.Collect {
+		switch c {
+		case "cpu", "gpu", "thermal", "battery":
+			m.collect[c] = true
+		default:
+			return fmt.Errorf("unknown collect option %q", c)
+		}
+	}
+
+	if m.SampleDuration < config.Duration(time.Millisecond) {
+		return errors.New("sample_duration must be at least 1ms")
+	}
+	if m.Timeout <= m.SampleDuration {
+		return errors.New("timeout must be larger than sample_duration")
+	}
+	return nil
+}
+
+// samplers returns the powermetrics samplers required for the collected
+// metrics
+func (m *MacOS) samplers() []string {
+	var samplers []string
+	if m.collect["cpu"] {
+		samplers = append(samplers, "cpu_power")
+	}
+	if m.collect["gpu"] {
+		samplers = append(samplers, "gpu_power")
+	}
+	if m.collect["thermal"] {
+		samplers = append(samplers, "thermal")
+	}
+	return samplers
+}
+
+// parsePowermetrics adds the metrics of a single powermetrics sample in
+// plist format
+func (m *MacOS) parsePowermetrics(acc telegraf.Accumulator, data []byte, ts time.Time) error {
+	// Samples are terminated by a NUL character
+	data = bytes.Trim(data, "\x00 \t\r\n")
+	v, err := decodePlist(data)
+	if err != nil {
+		return fmt.Errorf("decoding powermetrics output failed: %w", err)
+	}
+	sample, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected powermetrics output of type %T", v)
+	}
+	processor, _ := sample["processor"].(map[string]interface{})
+
+	if m.collect["cpu"] && processor != nil {
+		clusters, _ := processor["clusters"].([]interface{})
+		for _, c := range clusters {
+			cluster, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, ok := cluster["name"].(string)
+			if !ok {
+				continue
+			}
+			fields := make(map[string]interface{}, 2)
+			if freq, ok := toFloat(cluster["freq_hz"]); ok {
+				fields["frequency_hz"] = freq
+			}
+			if idle, ok := toFloat(cluster["idle_ratio"]); ok {
+				fields["active_percent"] = (1 - idle) * 100
+			}
+			if len(fields) > 0 {
+				acc.AddGauge("macos_cpu_cluster", fields, map[string]string{"cluster": name}, ts)
+			}
+		}
+
+		fields := make(map[string]interface{}, 3)
+		for key, field := range map[string]string{
+			"cpu_power":      "power_mw",
+			"ane_power":      "ane_power_mw",
+			"combined_power": "package_power_mw",
+		} {
+			if v, ok := toFloat(processor[key]); ok {
+				fields[field] = v
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddGauge("macos_cpu", fields, nil, ts)
+		}
+	}
+
+	if m.collect["gpu"] {
+		fields := make(map[string]interface{}, 3)
+		if gpu, ok := sample["gpu"].(map[string]interface{}); ok {
+			if freq, ok := toFloat(gpu["freq_hz"]); ok {
+				fields["frequency_hz"] = freq
+			}
+			if idle, ok := toFloat(gpu["idle_ratio"]); ok {
+				fields["active_percent"] = (1 - idle) * 100
+			}
+		}
+		if v, ok := toFloat(processor["gpu_power"]); ok {
+			fields["power_mw"] = v
+		}
+		if len(fields) > 0 {
+			acc.AddGauge("macos_gpu", fields, nil, ts)
+		}
+	}
+
+	if m.collect["thermal"] {
+		if pressure, ok := sample["thermal_pressure"].(string); ok {
+			fields := map[string]interface{}{"pressure": pressure}
+			if level, found := thermalPressureLevels[pressure]; found {
+				fields["pressure_level"] = level
+			}
+			acc.AddGauge("macos_thermal", fields, nil, ts)
+		}
+	}
+
+	return nil
+}
+
+// parseBattery adds the metrics of the batteries in the plist output of
+// ioreg for the AppleSmartBattery class
+func parseBattery(acc telegraf.Accumulator, data []byte, ts time.Time) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	v, err := decodePlist(data)
+	if err != nil {
+		return fmt.Errorf("decoding ioreg output failed: %w", err)
+	}
+	batteries, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected ioreg output of type %T", v)
+	}
+
+	for i, b := range batteries {
+		battery, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Apple Silicon machines report the capacities in percent and the
+		// raw values in mAh in separate keys
+		maxCapacity, hasMax := battery["AppleRawMaxCapacity"].(int64)
+		if !hasMax {
+			maxCapacity, hasMax = battery["MaxCapacity"].(int64)
+		}
+		currentCapacity, hasCurrent := battery["AppleRawCurrentCapacity"].(int64)
+		if !hasCurrent {
+			currentCapacity, hasCurrent = battery["CurrentCapacity"].(int64)
+		}
+
+		fields := make(map[string]interface{}, 11)
+		if v, ok := battery["CycleCount"].(int64); ok {
+			fields["cycle_count"] = v
+		}
+		if v, ok := battery["DesignCapacity"].(int64); ok {
+			fields["design_capacity_mah"] = v
+			if hasMax && v > 0 {
+				fields["health_percent"] = float64(maxCapacity) * 100 / float64(v)
+			}
+		}
+		if hasMax {
+			fields["max_capacity_mah"] = maxCapacity
+		}
+		if hasCurrent {
+			fields["current_capacity_mah"] = currentCapacity
+		}
+		if v, ok := battery["Temperature"].(int64); ok {
+			fields["temperature_celsius"] = float64(v) / 100
+		}
+		if v, ok := battery["Voltage"].(int64); ok {
+			fields["voltage_mv"] = v
+		}
+		if v, ok := battery["Amperage"].(int64); ok {
+			fields["amperage_ma"] = v
+		}
+		for key, field := range map[string]string{
+			"IsCharging":        "charging",
+			"ExternalConnected": "external_connected",
+			"FullyCharged":      "fully_charged",
+		} {
+			if v, ok := battery[key].(bool); ok {
+				fields[field] = v
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		acc.AddGauge("macos_battery", fields, map[string]string{"battery": strconv.Itoa(i)}, ts)
+	}
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	inputs.Add("macos", func() telegraf.Input {
+		return &MacOS{
+			PathPowermetrics: "/usr/bin/powermetrics",
+			PathIoreg:        "/usr/sbin/ioreg",
+			SampleDuration:   config.Duration(time.Second),
+			Timeout:          config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,58 @@
+//go:build darwin
+
+package macos
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+func (m *MacOS) Init() error {
+	return m.init()
+}
+
+func (m *MacOS) Gather(acc telegraf.Accumulator) error {
+	if samplers := m.samplers(); len(samplers) > 0 {
+		ts := time.Now()
+		interval := strconv.FormatInt(time.Duration(m.SampleDuration).Milliseconds(), 10)
+		out, err := m.run(m.PathPowermetrics, "-n", "1", "-i", interval, "-f", "plist", "--samplers", strings.Join(samplers, ","))
+		if err != nil {
+			acc.AddError(err)
+		} else if err := m.parsePowermetrics(acc, out, ts); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if m.collect["battery"] {
+		// ioreg does not require root privileges
+		ts := time.Now()
+		cmd := exec.Command(m.PathIoreg, "-r", "-c", "AppleSmartBattery", "-a")
+		out, err := internal.StdOutputTimeout(cmd, time.Duration(m.Timeout))
+		if err != nil {
+			acc.AddError(fmt.Errorf("running %q failed: %w", m.PathIoreg, err))
+		} else if err := parseBattery(acc, out, ts); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+// run executes the command with sudo if configured and returns the output
+func (m *MacOS) run(command string, args ...string) ([]byte, error) {
+	cmd := exec.Command(command, args...)
+	if m.UseSudo {
+		cmd = exec.Command("sudo", append([]string{"-n", command}, args...)...)
+	}
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(m.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", command, err)
+	}
+	return out, nil
+}
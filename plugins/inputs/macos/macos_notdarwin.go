@@ -0,0 +1,17 @@
+//go:build !darwin
+
+package macos
+
+import "github.com/influxdata/telegraf"
+
+func (m *MacOS) Init() error {
+	if err := m.init(); err != nil {
+		return err
+	}
+	m.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*MacOS) Gather(telegraf.Accumulator) error {
+	return nil
+}
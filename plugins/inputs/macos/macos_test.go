@@ -0,0 +1,194 @@
+package macos
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *MacOS
+		expected string
+	}{
+		{
+			name: "unknown collect option",
+			plugin: &MacOS{
+				Collect:        []string{"cpu", "fans"},
+				SampleDuration: config.Duration(time.Second),
+				Timeout:        config.Duration(5 * time.Second),
+			},
+			expected: `unknown collect option "fans"`,
+		},
+		{
+			name:     "no sample duration",
+			plugin:   &MacOS{Timeout: config.Duration(5 * time.Second)},
+			expected: "sample_duration must be at least 1ms",
+		},
+		{
+			name: "timeout too short",
+			plugin: &MacOS{
+				SampleDuration: config.Duration(time.Second),
+				Timeout:        config.Duration(time.Second),
+			},
+			expected: "timeout must be larger than sample_duration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.init(), tt.expected)
+		})
+	}
+}
+
+func TestSamplers(t *testing.T) {
+	plugin := &MacOS{
+		SampleDuration: config.Duration(time.Second),
+		Timeout:        config.Duration(5 * time.Second),
+	}
+	require.NoError(t, plugin.init())
+	require.Equal(t, []string{"cpu_power", "gpu_power", "thermal"}, plugin.samplers())
+
+	plugin.Collect = []string{"battery"}
+	require.NoError(t, plugin.init())
+	require.Empty(t, plugin.samplers())
+}
+
+func TestParsePowermetrics(t *testing.T) {
+	data, err := os.ReadFile("testdata/powermetrics.plist")
+	require.NoError(t, err)
+
+	plugin := &MacOS{
+		SampleDuration: config.Duration(time.Second),
+		Timeout:        config.Duration(5 * time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.init())
+
+	ts := time.Unix(1712136097, 0)
+	expected := []telegraf.Metric{
+		metric.New(
+			"macos_cpu_cluster",
+			map[string]string{"cluster": "E-Cluster"},
+			map[string]interface{}{"frequency_hz": 1284520000.0, "active_percent": 25.0},
+			ts,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"macos_cpu_cluster",
+			map[string]string{"cluster": "P0-Cluster"},
+			map[string]interface{}{"frequency_hz": 3228000000.0, "active_percent": 9.999999999999998},
+			ts,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"macos_cpu",
+			map[string]string{},
+			map[string]interface{}{"power_mw": 311.5, "ane_power_mw": 0.0, "package_power_mw": 336.0},
+			ts,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"macos_gpu",
+			map[string]string{},
+			map[string]interface{}{"frequency_hz": 389000000.0, "active_percent": 5.000000000000004, "power_mw": 24.5},
+			ts,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"macos_thermal",
+			map[string]string{},
+			map[string]interface{}{"pressure": "Moderate", "pressure_level": int64(1)},
+			ts,
+			telegraf.Gauge,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parsePowermetrics(&acc, data, ts))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Only the selected metrics must be reported
+	plugin.Collect = []string{"thermal"}
+	require.NoError(t, plugin.init())
+	acc.ClearMetrics()
+	require.NoError(t, plugin.parsePowermetrics(&acc, data, ts))
+	testutil.RequireMetricsEqual(t, expected[4:], acc.GetTelegrafMetrics())
+}
+
+func TestParseBattery(t *testing.T) {
+	data, err := os.ReadFile("testdata/ioreg_battery.plist")
+	require.NoError(t, err)
+
+	ts := time.Unix(1712136097, 0)
+	expected := []telegraf.Metric{
+		metric.New(
+			"macos_battery",
+			map[string]string{"battery": "0"},
+			map[string]interface{}{
+				"cycle_count":          int64(142),
+				"design_capacity_mah":  int64(6249),
+				"max_capacity_mah":     int64(6075),
+				"current_capacity_mah": int64(5123),
+				"health_percent":       97.2155544887182,
+				"temperature_celsius":  30.51,
+				"voltage_mv":           int64(12604),
+				"amperage_ma":          int64(-627),
+				"charging":             false,
+				"external_connected":   false,
+				"fully_charged":        false,
+			},
+			ts,
+			telegraf.Gauge,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, parseBattery(&acc, data, ts))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestParseNoBattery(t *testing.T) {
+	var acc testutil.Accumulator
+	require.NoError(t, parseBattery(&acc, nil, time.Now()))
+	require.NoError(t, parseBattery(&acc, []byte(`<plist version="1.0"><array/></plist>`), time.Now()))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestDecodePlist(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>name</key><string>test</string>
+	<key>count</key><integer>-5</integer>
+	<key>ratio</key><real>0.5</real>
+	<key>enabled</key><true/>
+	<key>payload</key><data>aGVsbG8=</data>
+	<key>list</key><array><integer>1</integer><false/></array>
+</dict>
+</plist>`)
+	v, err := decodePlist(data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"name":    "test",
+		"count":   int64(-5),
+		"ratio":   0.5,
+		"enabled": true,
+		"payload": []byte("hello"),
+		"list":    []interface{}{int64(1), false},
+	}, v)
+
+	_, err = decodePlist([]byte(`<plist version="1.0"><integer>abc</integer></plist>`))
+	require.ErrorContains(t, err, `invalid integer "abc"`)
+	_, err = decodePlist([]byte(`<html></html>`))
+	require.ErrorContains(t, err, "no plist element found")
+}
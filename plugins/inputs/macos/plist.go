@@ -0,0 +1,148 @@
+package macos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodePlist decodes an XML property list as produced by powermetrics and
+// ioreg into maps, slices, strings, int64, float64 and bool values. Dates
+// are returned as strings and data as byte slices.
+func decodePlist(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no plist element found")
+			}
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "plist" {
+			continue
+		}
+
+		// The plist element contains a single value
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				return decodePlistValue(decoder, t)
+			case xml.EndElement:
+				return nil, nil
+			}
+		}
+	}
+}
+
+func decodePlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodePlistDict(decoder)
+	case "array":
+		return decodePlistArray(decoder)
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "string", "date":
+		return text, nil
+	case "integer":
+		if v, err := strconv.ParseInt(text, 0, 64); err == nil {
+			return v, nil
+		}
+		// Some values are reported as unsigned two's complement of negative
+		// numbers, e.g. the battery amperage
+		v, err := strconv.ParseUint(text, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return int64(v), nil
+	case "real":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q", text)
+		}
+		return v, nil
+	case "data":
+		v, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown plist element %q", start.Name.Local)
+}
+
+func decodePlistDict(decoder *xml.Decoder) (map[string]interface{}, error) {
+	dict := make(map[string]interface{})
+	var key string
+	var hasKey bool
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				if err := decoder.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				hasKey = true
+				continue
+			}
+			if !hasKey {
+				return nil, fmt.Errorf("value %q without key in dict", t.Name.Local)
+			}
+			v, err := decodePlistValue(decoder, t)
+			if err != nil {
+				return nil, fmt.Errorf("decoding %q failed: %w", key, err)
+			}
+			dict[key] = v
+			hasKey = false
+		case xml.EndElement:
+			return dict, nil
+		}
+	}
+}
+
+func decodePlistArray(decoder *xml.Decoder) ([]interface{}, error) {
+	array := make([]interface{}, 0)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			v, err := decodePlistValue(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		case xml.EndElement:
+			return array, nil
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package osquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// client runs a query and returns the resulting rows with all values being
+// formatted as strings
+type client interface {
+	query(sql string) ([]map[string]string, error)
+	close() error
+}
+
+// processClient runs the queries using the osqueryi shell
+type processClient struct {
+	binary  string
+	timeout time.Duration
+}
+
+func (c *processClient) query(sql string) ([]map[string]string, error) {
+	cmd := exec.Command(c.binary, "--json", sql)
+	out, err := internal.StdOutputTimeout(cmd, c.timeout)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, err
+	}
+	return decodeRows(out)
+}
+
+func (*processClient) close() error {
+	return nil
+}
+
+// decodeRows decodes the JSON output of osqueryi
+func decodeRows(buf []byte) ([]map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+
+	var raw []map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding output failed: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(raw))
+	for _, r := range raw {
+		row := make(map[string]string, len(r))
+		for k, v := range r {
+			switch v := v.(type) {
+			case nil:
+				row[k] = ""
+			case string:
+				row[k] = v
+			default:
+				row[k] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// socketClient queries osqueryd via the Thrift API of its extension socket
+type socketClient struct {
+	path    string
+	timeout time.Duration
+
+	transport thrift.TTransport
+	client    *thrift.TStandardClient
+}
+
+func (c *socketClient) connect() error {
+	addr, err := net.ResolveUnixAddr("unix", c.path)
+	if err != nil {
+		return err
+	}
+
+	conf := &thrift.TConfiguration{
+		ConnectTimeout: c.timeout,
+		SocketTimeout:  c.timeout,
+	}
+	socket := thrift.NewTSocketFromAddrConf(addr, conf)
+	if err := socket.Open(); err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", c.path, err)
+	}
+
+	c.transport = thrift.NewTBufferedTransport(socket, 8192)
+	protocol := thrift.NewTBinaryProtocolConf(c.transport, conf)
+	c.client = thrift.NewTStandardClient(protocol, protocol)
+
+	return nil
+}
+
+func (c *socketClient) query(sql string) ([]map[string]string, error) {
+	if c.client == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var result queryResult
+	if _, err := c.client.Call(ctx, "query", &queryArgs{sql: sql}, &result); err != nil {
+		// Reconnect on the next query as the connection state is unknown
+		c.close() //nolint:errcheck // the connection is broken anyway
+		return nil, err
+	}
+	if result.response == nil {
+		return nil, errors.New("empty response")
+	}
+	if result.response.code != 0 {
+		return nil, fmt.Errorf("query failed with status %d: %s", result.response.code, result.response.message)
+	}
+
+	return result.response.rows, nil
+}
+
+func (c *socketClient) close() error {
+	c.client = nil
+	if c.transport == nil {
+		return nil
+	}
+	err := c.transport.Close()
+	c.transport = nil
+	return err
+}
+
+// queryArgs are the arguments of the "query" call of the ExtensionManager
+// service, see osquery/extensions/osquery.thrift
+type queryArgs struct {
+	sql string
+}
+
+func (a *queryArgs) Write(ctx context.Context, p thrift.TProtocol) error {
+	if err := p.WriteStructBegin(ctx, "query_args"); err != nil {
+		return err
+	}
+	if err := p.WriteFieldBegin(ctx, "sql", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := p.WriteString(ctx, a.sql); err != nil {
+		return err
+	}
+	if err := p.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := p.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return p.WriteStructEnd(ctx)
+}
+
+func (*queryArgs) Read(context.Context, thrift.TProtocol) error {
+	return errors.New("reading query arguments not supported")
+}
+
+// queryResult is the result of the "query" call containing the
+// ExtensionResponse as field zero
+type queryResult struct {
+	response *extensionResponse
+}
+
+func (*queryResult) Write(context.Context, thrift.TProtocol) error {
+	return errors.New("writing query result not supported")
+}
+
+func (r *queryResult) Read(ctx context.Context, p thrift.TProtocol) error {
+	return readStruct(ctx, p, func(id int16, typ thrift.TType) (bool, error) {
+		if id != 0 || typ != thrift.STRUCT {
+			return false, nil
+		}
+		r.response = &extensionResponse{}
+		return true, r.response.read(ctx, p)
+	})
+}
+
+// extensionResponse contains the ExtensionStatus and the rows of the
+// ExtensionPluginResponse
+type extensionResponse struct {
+	code    int32
+	message string
+	rows    []map[string]string
+}
+
+func (r *extensionResponse) read(ctx context.Context, p thrift.TProtocol) error {
+	return readStruct(ctx, p, func(id int16, typ thrift.TType) (bool, error) {
+		switch {
+		case id == 1 && typ == thrift.STRUCT:
+			return true, r.readStatus(ctx, p)
+		case id == 2 && typ == thrift.LIST:
+			return true, r.readRows(ctx, p)
+		}
+		return false, nil
+	})
+}
+
+func (r *extensionResponse) readStatus(ctx context.Context, p thrift.TProtocol) error {
+	return readStruct(ctx, p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.I32:
+			r.code, err = p.ReadI32(ctx)
+		case id == 2 && typ == thrift.STRING:
+			r.message, err = p.ReadString(ctx)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (r *extensionResponse) readRows(ctx context.Context, p thrift.TProtocol) error {
+	elemType, size, err := p.ReadListBegin(ctx)
+	if err != nil {
+		return err
+	}
+	if elemType != thrift.MAP {
+		return fmt.Errorf("unexpected row type %v", elemType)
+	}
+
+	r.rows = make([]map[string]string, 0, size)
+	for range size {
+		keyType, valueType, n, err := p.ReadMapBegin(ctx)
+		if err != nil {
+			return err
+		}
+		if keyType != thrift.STRING || valueType != thrift.STRING {
+			return fmt.Errorf("unexpected column types %v and %v", keyType, valueType)
+		}
+		row := make(map[string]string, n)
+		for range n {
+			k, err := p.ReadString(ctx)
+			if err != nil {
+				return err
+			}
+			v, err := p.ReadString(ctx)
+			if err != nil {
+				return err
+			}
+			row[k] = v
+		}
+		if err := p.ReadMapEnd(ctx); err != nil {
+			return err
+		}
+		r.rows = append(r.rows, row)
+	}
+	return p.ReadListEnd(ctx)
+}
+
+// readStruct reads the fields of a struct calling the given function for
+// each field. Fields not consumed by the function are skipped.
+func readStruct(ctx context.Context, p thrift.TProtocol, field func(id int16, typ thrift.TType) (bool, error)) error {
+	if _, err := p.ReadStructBegin(ctx); err != nil {
+		return err
+	}
+	for {
+		_, typ, id, err := p.ReadFieldBegin(ctx)
+		if err != nil {
+			return err
+		}
+		if typ == thrift.STOP {
+			break
+		}
+		consumed, err := field(id, typ)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			if err := p.Skip(ctx, typ); err != nil {
+				return err
+			}
+		}
+		if err := p.ReadFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return p.ReadStructEnd(ctx)
+}
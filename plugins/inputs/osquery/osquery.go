@@ -0,0 +1,234 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package osquery
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Osquery struct {
+	Method  string          `toml:"method"`
+	Socket  string          `toml:"socket"`
+	Binary  string          `toml:"binary"`
+	Timeout config.Duration `toml:"timeout"`
+	Queries []*query        `toml:"query"`
+	Log     telegraf.Logger `toml:"-"`
+
+	client client
+}
+
+type query struct {
+	Query               string   `toml:"query"`
+	Measurement         string   `toml:"measurement"`
+	Differential        bool     `toml:"differential"`
+	TagColumnsInclude   []string `toml:"tag_columns_include"`
+	TagColumnsExclude   []string `toml:"tag_columns_exclude"`
+	FieldColumnsInclude []string `toml:"field_columns_include"`
+	FieldColumnsExclude []string `toml:"field_columns_exclude"`
+	FieldColumnsFloat   []string `toml:"field_columns_float"`
+	FieldColumnsInt     []string `toml:"field_columns_int"`
+	FieldColumnsUint    []string `toml:"field_columns_uint"`
+	FieldColumnsBool    []string `toml:"field_columns_bool"`
+
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+	floatFilter filter.Filter
+	intFilter   filter.Filter
+	uintFilter  filter.Filter
+	boolFilter  filter.Filter
+
+	// Rows of the previous run in differential mode
+	previous map[string]bool
+}
+
+func (*Osquery) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *Osquery) Init() error {
+	switch o.Method {
+	case "", "socket":
+		if o.Socket == "" {
+			return errors.New("socket required")
+		}
+		o.client = &socketClient{path: o.Socket, timeout: time.Duration(o.Timeout)}
+	case "osqueryi":
+		if o.Binary == "" {
+			return errors.New("binary required")
+		}
+		o.client = &processClient{binary: o.Binary, timeout: time.Duration(o.Timeout)}
+	default:
+		return fmt.Errorf("invalid method %q", o.Method)
+	}
+
+	if len(o.Queries) == 0 {
+		return errors.New("no queries configured")
+	}
+	for i, q := range o.Queries {
+		if err := q.init(); err != nil {
+			return fmt.Errorf("query %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Osquery) Gather(acc telegraf.Accumulator) error {
+	for _, q := range o.Queries {
+		rows, err := o.client.query(q.Query)
+		if err != nil {
+			acc.AddError(fmt.Errorf("running query %q failed: %w", q.Query, err))
+			continue
+		}
+		q.process(acc, rows, time.Now())
+	}
+	return nil
+}
+
+func (o *Osquery) Stop() {
+	if o.client != nil {
+		if err := o.client.close(); err != nil {
+			o.Log.Errorf("Closing connection failed: %v", err)
+		}
+	}
+}
+
+func (q *query) init() error {
+	if q.Query == "" {
+		return errors.New("empty query")
+	}
+	if q.Measurement == "" {
+		q.Measurement = "osquery"
+	}
+
+	var err error
+	if q.tagFilter, err = filter.NewIncludeExcludeFilterDefaults(q.TagColumnsInclude, q.TagColumnsExclude, false, false); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if q.fieldFilter, err = filter.NewIncludeExcludeFilter(q.FieldColumnsInclude, q.FieldColumnsExclude); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	if q.floatFilter, err = filter.Compile(q.FieldColumnsFloat); err != nil {
+		return fmt.Errorf("creating float filter failed: %w", err)
+	}
+	if q.intFilter, err = filter.Compile(q.FieldColumnsInt); err != nil {
+		return fmt.Errorf("creating int filter failed: %w", err)
+	}
+	if q.uintFilter, err = filter.Compile(q.FieldColumnsUint); err != nil {
+		return fmt.Errorf("creating uint filter failed: %w", err)
+	}
+	if q.boolFilter, err = filter.Compile(q.FieldColumnsBool); err != nil {
+		return fmt.Errorf("creating bool filter failed: %w", err)
+	}
+
+	return nil
+}
+
+// process converts the rows to metrics. In differential mode only rows added
+// or removed since the previous run are reported similar to osquery's
+// differential query logs. All rows are reported as added on the first run.
+func (q *query) process(acc telegraf.Accumulator, rows []map[string]string, now time.Time) {
+	if !q.Differential {
+		for _, row := range rows {
+			q.add(acc, row, "", now)
+		}
+		return
+	}
+
+	current := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		// Serializing maps sorts the keys, so the identity is stable
+		buf, err := json.Marshal(row)
+		if err != nil {
+			acc.AddError(fmt.Errorf("query %q: serializing row failed: %w", q.Query, err))
+			continue
+		}
+		id := string(buf)
+		if current[id] {
+			continue
+		}
+		current[id] = true
+		if !q.previous[id] {
+			q.add(acc, row, "added", now)
+		}
+	}
+	for id := range q.previous {
+		if current[id] {
+			continue
+		}
+		var row map[string]string
+		if err := json.Unmarshal([]byte(id), &row); err != nil {
+			acc.AddError(fmt.Errorf("query %q: restoring row failed: %w", q.Query, err))
+			continue
+		}
+		q.add(acc, row, "removed", now)
+	}
+	q.previous = current
+}
+
+func (q *query) add(acc telegraf.Accumulator, row map[string]string, action string, now time.Time) {
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	for column, value := range row {
+		if q.tagFilter.Match(column) {
+			tags[column] = value
+			continue
+		}
+		if !q.fieldFilter.Match(column) {
+			continue
+		}
+		v, err := q.convert(column, value)
+		if err != nil {
+			acc.AddError(fmt.Errorf("query %q: converting column %q failed: %w", q.Query, column, err))
+			continue
+		}
+		fields[column] = v
+	}
+	if action != "" {
+		tags["action"] = action
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	acc.AddFields(q.Measurement, fields, tags, now)
+}
+
+// convert converts the value according to the configured type as osquery
+// returns all values as strings
+func (q *query) convert(column, value string) (interface{}, error) {
+	switch {
+	case q.floatFilter != nil && q.floatFilter.Match(column):
+		return strconv.ParseFloat(value, 64)
+	case q.intFilter != nil && q.intFilter.Match(column):
+		return strconv.ParseInt(value, 10, 64)
+	case q.uintFilter != nil && q.uintFilter.Match(column):
+		return strconv.ParseUint(value, 10, 64)
+	case q.boolFilter != nil && q.boolFilter.Match(column):
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+func init() {
+	inputs.Add("osquery", func() telegraf.Input {
+		return &Osquery{
+			Method:  "socket",
+			Socket:  "/var/osquery/osquery.em",
+			Binary:  "osqueryi",
+			Timeout: config.Duration(10 * time.Second),
+		}
+	})
+}
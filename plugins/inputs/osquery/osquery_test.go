@@ -0,0 +1,370 @@
+package osquery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Osquery
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			plugin:   &Osquery{Method: "http"},
+			expected: `invalid method "http"`,
+		},
+		{
+			name:     "no queries",
+			plugin:   &Osquery{Method: "osqueryi", Binary: "osqueryi"},
+			expected: "no queries configured",
+		},
+		{
+			name: "empty query",
+			plugin: &Osquery{
+				Method:  "socket",
+				Socket:  "/var/osquery/osquery.em",
+				Queries: []*query{{}},
+			},
+			expected: "query 1: empty query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestConversion(t *testing.T) {
+	q := &query{
+		Query:               "SELECT * FROM test",
+		TagColumnsInclude:   []string{"name"},
+		FieldColumnsExclude: []string{"ignored"},
+		FieldColumnsFloat:   []string{"load"},
+		FieldColumnsInt:     []string{"pid"},
+		FieldColumnsUint:    []string{"*_size"},
+		FieldColumnsBool:    []string{"on_disk"},
+	}
+	require.NoError(t, q.init())
+
+	rows := []map[string]string{
+		{
+			"name":          "telegraf",
+			"path":          "/usr/bin/telegraf",
+			"load":          "0.25",
+			"pid":           "-1",
+			"resident_size": "1024",
+			"on_disk":       "1",
+			"ignored":       "foo",
+		},
+		{"name": "broken", "pid": "abc", "path": "/bin/broken"},
+	}
+
+	var acc testutil.Accumulator
+	now := time.Now()
+	q.process(&acc, rows, now)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"osquery",
+			map[string]string{"name": "telegraf"},
+			map[string]interface{}{
+				"path":          "/usr/bin/telegraf",
+				"load":          0.25,
+				"pid":           int64(-1),
+				"resident_size": uint64(1024),
+				"on_disk":       true,
+			},
+			now,
+		),
+		metric.New(
+			"osquery",
+			map[string]string{"name": "broken"},
+			map[string]interface{}{"path": "/bin/broken"},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `converting column "pid" failed`)
+}
+
+func TestDifferential(t *testing.T) {
+	q := &query{
+		Query:             "SELECT name, pid FROM processes",
+		Measurement:       "processes",
+		Differential:      true,
+		TagColumnsInclude: []string{"name"},
+	}
+	require.NoError(t, q.init())
+
+	// All rows are added on the first run
+	var acc testutil.Accumulator
+	now := time.Now()
+	q.process(&acc, []map[string]string{
+		{"name": "sshd", "pid": "100"},
+		{"name": "cron", "pid": "200"},
+	}, now)
+	expected := []telegraf.Metric{
+		metric.New(
+			"processes",
+			map[string]string{"name": "sshd", "action": "added"},
+			map[string]interface{}{"pid": "100"},
+			now,
+		),
+		metric.New(
+			"processes",
+			map[string]string{"name": "cron", "action": "added"},
+			map[string]interface{}{"pid": "200"},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Unchanged rows are not reported
+	acc.ClearMetrics()
+	q.process(&acc, []map[string]string{
+		{"name": "cron", "pid": "200"},
+		{"name": "sshd", "pid": "100"},
+	}, now)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// A changed row is reported as removed and added
+	acc.ClearMetrics()
+	q.process(&acc, []map[string]string{
+		{"name": "sshd", "pid": "100"},
+		{"name": "cron", "pid": "300"},
+	}, now)
+	expected = []telegraf.Metric{
+		metric.New(
+			"processes",
+			map[string]string{"name": "cron", "action": "added"},
+			map[string]interface{}{"pid": "300"},
+			now,
+		),
+		metric.New(
+			"processes",
+			map[string]string{"name": "cron", "action": "removed"},
+			map[string]interface{}{"pid": "200"},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Empty(t, acc.Errors)
+}
+
+func TestDecodeRows(t *testing.T) {
+	rows, err := decodeRows([]byte(`[
+		{"name": "launchd", "pid": "1", "uid": 0, "parent": null},
+		{"name": "sshd", "pid": "100", "uid": 0.5, "parent": "1"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"name": "launchd", "pid": "1", "uid": "0", "parent": ""},
+		{"name": "sshd", "pid": "100", "uid": "0.5", "parent": "1"},
+	}, rows)
+
+	_, err = decodeRows([]byte("Error: no such table: foo"))
+	require.ErrorContains(t, err, "decoding output failed")
+}
+
+func TestGatherSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows as osquery uses named pipes there")
+	}
+
+	addr := filepath.Join(t.TempDir(), "osquery.em")
+	server := &fakeExtensionManager{
+		responses: map[string]fakeResponse{
+			"SELECT version FROM osquery_info": {
+				rows: []map[string]string{{"version": "5.12.1", "pid": "42"}},
+			},
+			"SELECT * FROM foo": {
+				code:    1,
+				message: "no such table: foo",
+			},
+		},
+	}
+	listener, err := net.Listen("unix", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+	go server.serve(listener)
+
+	plugin := &Osquery{
+		Method:  "socket",
+		Socket:  addr,
+		Timeout: config.Duration(5 * time.Second),
+		Queries: []*query{
+			{
+				Query:             "SELECT version FROM osquery_info",
+				Measurement:       "osquery_info",
+				TagColumnsInclude: []string{"version"},
+				FieldColumnsInt:   []string{"pid"},
+			},
+			{Query: "SELECT * FROM foo"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"osquery_info",
+			map[string]string{"version": "5.12.1"},
+			map[string]interface{}{"pid": int64(42)},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Run multiple times to check reusing the connection
+	for range 2 {
+		var acc testutil.Accumulator
+		require.NoError(t, plugin.Gather(&acc))
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		require.Len(t, acc.Errors, 1)
+		require.ErrorContains(t, acc.Errors[0], "query failed with status 1: no such table: foo")
+	}
+}
+
+type fakeResponse struct {
+	code    int32
+	message string
+	rows    []map[string]string
+}
+
+// fakeExtensionManager answers the "query" calls of the osquery
+// ExtensionManager Thrift service
+type fakeExtensionManager struct {
+	responses map[string]fakeResponse
+}
+
+func (s *fakeExtensionManager) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeExtensionManager) handle(conn net.Conn) {
+	defer conn.Close()
+
+	ctx := context.Background()
+	transport := thrift.NewTBufferedTransport(thrift.NewTSocketFromConnConf(conn, nil), 8192)
+	p := thrift.NewTBinaryProtocolConf(transport, nil)
+	for {
+		name, _, seq, err := p.ReadMessageBegin(ctx)
+		if err != nil {
+			return
+		}
+		var sql string
+		if err := readStruct(ctx, p, func(id int16, typ thrift.TType) (bool, error) {
+			if id != 1 || typ != thrift.STRING {
+				return false, nil
+			}
+			sql, err = p.ReadString(ctx)
+			return true, err
+		}); err != nil {
+			return
+		}
+		if err := p.ReadMessageEnd(ctx); err != nil {
+			return
+		}
+		if name != "query" {
+			return
+		}
+
+		response, found := s.responses[sql]
+		if !found {
+			response = fakeResponse{code: 1, message: "unexpected query"}
+		}
+		if err := s.reply(ctx, p, seq, response); err != nil {
+			return
+		}
+	}
+}
+
+func (*fakeExtensionManager) reply(ctx context.Context, p thrift.TProtocol, seq int32, r fakeResponse) error {
+	return errors.Join(
+		p.WriteMessageBegin(ctx, "query", thrift.REPLY, seq),
+		p.WriteStructBegin(ctx, "query_result"),
+		p.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0),
+		p.WriteStructBegin(ctx, "ExtensionResponse"),
+
+		// Status
+		p.WriteFieldBegin(ctx, "status", thrift.STRUCT, 1),
+		p.WriteStructBegin(ctx, "ExtensionStatus"),
+		p.WriteFieldBegin(ctx, "code", thrift.I32, 1),
+		p.WriteI32(ctx, r.code),
+		p.WriteFieldEnd(ctx),
+		p.WriteFieldBegin(ctx, "message", thrift.STRING, 2),
+		p.WriteString(ctx, r.message),
+		p.WriteFieldEnd(ctx),
+		p.WriteFieldBegin(ctx, "uuid", thrift.I64, 3),
+		p.WriteI64(ctx, 0),
+		p.WriteFieldEnd(ctx),
+		p.WriteFieldStop(ctx),
+		p.WriteStructEnd(ctx),
+		p.WriteFieldEnd(ctx),
+
+		// Rows
+		writeRows(ctx, p, r.rows),
+
+		p.WriteFieldStop(ctx),
+		p.WriteStructEnd(ctx),
+		p.WriteFieldEnd(ctx),
+		p.WriteFieldStop(ctx),
+		p.WriteStructEnd(ctx),
+		p.WriteMessageEnd(ctx),
+		p.Flush(ctx),
+	)
+}
+
+func writeRows(ctx context.Context, p thrift.TProtocol, rows []map[string]string) error {
+	if err := p.WriteFieldBegin(ctx, "response", thrift.LIST, 2); err != nil {
+		return err
+	}
+	if err := p.WriteListBegin(ctx, thrift.MAP, len(rows)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := p.WriteMapBegin(ctx, thrift.STRING, thrift.STRING, len(row)); err != nil {
+			return err
+		}
+		for k, v := range row {
+			if err := p.WriteString(ctx, k); err != nil {
+				return err
+			}
+			if err := p.WriteString(ctx, v); err != nil {
+				return err
+			}
+		}
+		if err := p.WriteMapEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.WriteListEnd(ctx); err != nil {
+		return err
+	}
+	return p.WriteFieldEnd(ctx)
+}
@@ -0,0 +1,255 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package patroni
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Patroni struct {
+	URLs    []string        `toml:"urls"`
+	Cluster bool            `toml:"cluster"`
+	Log     telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+// memberStatus is the response of the '/patroni' endpoint
+type memberStatus struct {
+	State          string `json:"state"`
+	Role           string `json:"role"`
+	ServerVersion  int64  `json:"server_version"`
+	Timeline       int64  `json:"timeline"`
+	PendingRestart bool   `json:"pending_restart"`
+	Pause          bool   `json:"pause"`
+	Unlocked       bool   `json:"cluster_unlocked"`
+	DCSLastSeen    int64  `json:"dcs_last_seen"`
+	XLog           struct {
+		Location         *int64 `json:"location"`
+		ReceivedLocation *int64 `json:"received_location"`
+		ReplayedLocation *int64 `json:"replayed_location"`
+		Paused           *bool  `json:"paused"`
+	} `json:"xlog"`
+	Replication []struct {
+		ApplicationName string `json:"application_name"`
+		State           string `json:"state"`
+	} `json:"replication"`
+	Patroni struct {
+		Version string `json:"version"`
+		Scope   string `json:"scope"`
+		Name    string `json:"name"`
+	} `json:"patroni"`
+}
+
+// clusterStatus is the response of the '/cluster' endpoint
+type clusterStatus struct {
+	Scope   string `json:"scope"`
+	Members []struct {
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+		State    string `json:"state"`
+		Timeline *int64 `json:"timeline"`
+		// Lag in bytes or "unknown" if the lag cannot be determined
+		Lag interface{} `json:"lag"`
+	} `json:"members"`
+}
+
+func (*Patroni) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Patroni) Init() error {
+	if len(p.URLs) == 0 {
+		p.URLs = []string{"http://localhost:8008"}
+	}
+	for i, u := range p.URLs {
+		p.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	p.client = client
+
+	return nil
+}
+
+func (p *Patroni) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range p.URLs {
+		wg.Add(1)
+		go func(baseURL string) {
+			defer wg.Done()
+			if err := p.gatherMember(acc, baseURL); err != nil {
+				acc.AddError(fmt.Errorf("[url=%s]: %w", baseURL, err))
+			}
+			if !p.Cluster {
+				return
+			}
+			if err := p.gatherCluster(acc, baseURL); err != nil {
+				acc.AddError(fmt.Errorf("[url=%s]: %w", baseURL, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *Patroni) Stop() {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+}
+
+func (p *Patroni) gatherMember(acc telegraf.Accumulator, baseURL string) error {
+	var status memberStatus
+	if err := p.getJSON(baseURL+"/patroni", &status); err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"scope":  status.Patroni.Scope,
+		"member": status.Patroni.Name,
+	}
+	fields := map[string]interface{}{
+		"role":             status.Role,
+		"state":            status.State,
+		"leader":           isLeader(status.Role),
+		"timeline":         status.Timeline,
+		"server_version":   status.ServerVersion,
+		"pending_restart":  status.PendingRestart,
+		"pause":            status.Pause,
+		"cluster_unlocked": status.Unlocked,
+		"dcs_last_seen":    status.DCSLastSeen,
+	}
+	if status.Patroni.Version != "" {
+		fields["version"] = status.Patroni.Version
+	}
+
+	// The primary reports the current write location and its replicas
+	if status.XLog.Location != nil {
+		fields["xlog_location"] = *status.XLog.Location
+	}
+	if isLeader(status.Role) {
+		var streaming int64
+		for _, r := range status.Replication {
+			if r.State == "streaming" {
+				streaming++
+			}
+		}
+		fields["replicas"] = int64(len(status.Replication))
+		fields["replicas_streaming"] = streaming
+	}
+
+	// Replicas report the received and replayed locations
+	if status.XLog.ReceivedLocation != nil {
+		fields["xlog_received_location"] = *status.XLog.ReceivedLocation
+	}
+	if status.XLog.ReplayedLocation != nil {
+		fields["xlog_replayed_location"] = *status.XLog.ReplayedLocation
+	}
+	if status.XLog.ReceivedLocation != nil && status.XLog.ReplayedLocation != nil {
+		fields["replay_lag_bytes"] = max(*status.XLog.ReceivedLocation-*status.XLog.ReplayedLocation, 0)
+	}
+	if status.XLog.Paused != nil {
+		fields["xlog_paused"] = *status.XLog.Paused
+	}
+
+	acc.AddFields("patroni", fields, tags)
+	return nil
+}
+
+func (p *Patroni) gatherCluster(acc telegraf.Accumulator, baseURL string) error {
+	var status clusterStatus
+	if err := p.getJSON(baseURL+"/cluster", &status); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, m := range status.Members {
+		tags := map[string]string{
+			"scope":  status.Scope,
+			"member": m.Name,
+		}
+		fields := map[string]interface{}{
+			"role":   m.Role,
+			"state":  m.State,
+			"leader": isLeader(m.Role),
+		}
+		if m.Timeline != nil {
+			fields["timeline"] = *m.Timeline
+		}
+		// The lag is reported as "unknown" if it cannot be determined
+		if lag, ok := m.Lag.(float64); ok {
+			fields["lag_bytes"] = int64(lag)
+		}
+		acc.AddFields("patroni_cluster", fields, tags, now)
+	}
+
+	return nil
+}
+
+func (p *Patroni) getJSON(address string, v interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Patroni responds with 503 for endpoints not matching the role of the
+	// member but still returns the status
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s returned HTTP status %s: %q", address, resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %s failed: %w", address, err)
+	}
+	return nil
+}
+
+// isLeader returns true for the roles of the leader of a cluster, with the
+// role names differing between Patroni versions and standby clusters
+func isLeader(role string) bool {
+	switch role {
+	case "master", "primary", "leader", "standby_leader", "standby-leader":
+		return true
+	}
+	return false
+}
+
+func init() {
+	inputs.Add("patroni", func() telegraf.Input {
+		return &Patroni{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
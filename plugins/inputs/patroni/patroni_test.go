@@ -0,0 +1,187 @@
+package patroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T, status string) *httptest.Server {
+	t.Helper()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var filename string
+		switch r.URL.Path {
+		case "/patroni":
+			filename = status
+		case "/cluster":
+			filename = "cluster.json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", filename))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGatherPrimary(t *testing.T) {
+	server := newServer(t, "primary.json")
+
+	plugin := &Patroni{URLs: []string{server.URL + "/"}}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"patroni",
+			map[string]string{
+				"scope":  "demo",
+				"member": "node1",
+			},
+			map[string]interface{}{
+				"role":               "master",
+				"state":              "running",
+				"leader":             true,
+				"timeline":           int64(3),
+				"server_version":     int64(160002),
+				"pending_restart":    false,
+				"pause":              false,
+				"cluster_unlocked":   false,
+				"dcs_last_seen":      int64(1714641164),
+				"version":            "3.3.0",
+				"xlog_location":      int64(67109888),
+				"replicas":           int64(2),
+				"replicas_streaming": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherReplicaWithCluster(t *testing.T) {
+	server := newServer(t, "replica.json")
+
+	plugin := &Patroni{
+		URLs:    []string{server.URL},
+		Cluster: true,
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"patroni",
+			map[string]string{
+				"scope":  "demo",
+				"member": "node2",
+			},
+			map[string]interface{}{
+				"role":                   "replica",
+				"state":                  "running",
+				"leader":                 false,
+				"timeline":               int64(3),
+				"server_version":         int64(160002),
+				"pending_restart":        true,
+				"pause":                  false,
+				"cluster_unlocked":       false,
+				"dcs_last_seen":          int64(1714641165),
+				"version":                "3.3.0",
+				"xlog_received_location": int64(67109888),
+				"xlog_replayed_location": int64(67100000),
+				"xlog_paused":            false,
+				"replay_lag_bytes":       int64(9888),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"patroni_cluster",
+			map[string]string{
+				"scope":  "demo",
+				"member": "node1",
+			},
+			map[string]interface{}{
+				"role":     "leader",
+				"state":    "running",
+				"leader":   true,
+				"timeline": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"patroni_cluster",
+			map[string]string{
+				"scope":  "demo",
+				"member": "node2",
+			},
+			map[string]interface{}{
+				"role":      "replica",
+				"state":     "streaming",
+				"leader":    false,
+				"timeline":  int64(3),
+				"lag_bytes": int64(9888),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"patroni_cluster",
+			map[string]string{
+				"scope":  "demo",
+				"member": "node3",
+			},
+			map[string]interface{}{
+				"role":   "replica",
+				"state":  "starting",
+				"leader": false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plugin := &Patroni{URLs: []string{server.URL}}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "401 Unauthorized")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
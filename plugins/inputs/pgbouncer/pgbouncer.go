@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/influxdata/telegraf"
@@ -25,7 +26,8 @@ type PgBouncer struct {
 	ShowCommands []string `toml:"show_commands"`
 	postgresql.Config
 
-	service *postgresql.Service
+	service   *postgresql.Service
+	poolSizes map[string]int64
 }
 
 func (*PgBouncer) SampleConfig() string {
@@ -45,6 +47,15 @@ func (p *PgBouncer) Init() error {
 		}
 	}
 
+	// Gather the databases before the pools to compute the saturation of the
+	// pools from the configured pool sizes
+	idxDatabases := slices.Index(p.ShowCommands, "databases")
+	idxPools := slices.Index(p.ShowCommands, "pools")
+	if idxPools >= 0 && idxDatabases > idxPools {
+		p.ShowCommands[idxDatabases], p.ShowCommands[idxPools] = p.ShowCommands[idxPools], p.ShowCommands[idxDatabases]
+	}
+	p.poolSizes = make(map[string]int64)
+
 	// Create a postgres service for the queries
 	service, err := p.Config.CreateService()
 	if err != nil {
@@ -210,6 +221,7 @@ func (p *PgBouncer) showPools(acc telegraf.Accumulator) error {
 				fields[col] = *val
 			}
 		}
+		addPoolUsageFields(fields, p.poolSizes[tags["db"]])
 		acc.AddFields("pgbouncer_pools", fields, tags)
 	}
 
@@ -299,11 +311,51 @@ func (p *PgBouncer) showDatabase(acc telegraf.Accumulator) error {
 				fields[col] = *val
 			}
 		}
+		if size, ok := toInt64(fields["pool_size"]); ok {
+			p.poolSizes[tags["db"]] = size
+		}
 		acc.AddFields("pgbouncer_databases", fields, tags)
 	}
 	return rows.Err()
 }
 
+// addPoolUsageFields adds the maximum wait time of the clients in seconds, the
+// total number of server connections and, if the pool size is known, the
+// saturation of the pool with server connections
+func addPoolUsageFields(fields map[string]interface{}, poolSize int64) {
+	if maxwait, ok := toInt64(fields["maxwait"]); ok {
+		var waitTime float64
+		if maxwaitUs, ok := toInt64(fields["maxwait_us"]); ok {
+			waitTime = float64(maxwaitUs) / 1e6
+		}
+		fields["maxwait_time"] = float64(maxwait) + waitTime
+	}
+
+	var total int64
+	for _, col := range []string{"sv_active", "sv_idle", "sv_used", "sv_tested", "sv_login"} {
+		if v, ok := toInt64(fields[col]); ok {
+			total += v
+		}
+	}
+	fields["sv_total"] = total
+	if poolSize > 0 {
+		fields["sv_saturation"] = float64(total) / float64(poolSize)
+	}
+}
+
+// toInt64 converts the column values returned as integers or strings
+// depending on the pgbouncer version
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
 func init() {
 	inputs.Add("pgbouncer", func() telegraf.Input {
 		return &PgBouncer{
@@ -213,3 +213,38 @@ func TestPgBouncerGeneratesMetricsIntegrationShowCommands(t *testing.T) {
 	require.Positive(t, metricsCounted)
 	require.Equal(t, len(intMetricsPgBouncerPools)+len(intMetricsPgBouncerLists)+len(intMetricsPgBouncerDatabases), metricsCounted)
 }
+
+func TestCommandOrder(t *testing.T) {
+	p := &PgBouncer{
+		Config: postgresql.Config{
+			Address:     config.NewSecret([]byte("host=localhost user=pgbouncer")),
+			IsPgBouncer: true,
+		},
+		ShowCommands: []string{"stats", "pools", "lists", "databases"},
+	}
+	require.NoError(t, p.Init())
+	require.Equal(t, []string{"stats", "databases", "lists", "pools"}, p.ShowCommands)
+}
+
+func TestPoolUsageFields(t *testing.T) {
+	fields := map[string]interface{}{
+		"cl_active":  int64(10),
+		"cl_waiting": int64(2),
+		"sv_active":  int64(3),
+		"sv_idle":    "1",
+		"sv_used":    int64(1),
+		"sv_tested":  int64(0),
+		"sv_login":   int64(0),
+		"maxwait":    int64(2),
+		"maxwait_us": int64(500000),
+	}
+	addPoolUsageFields(fields, 10)
+	require.InDelta(t, 2.5, fields["maxwait_time"], 1e-9)
+	require.Equal(t, int64(5), fields["sv_total"])
+	require.InDelta(t, 0.5, fields["sv_saturation"], 1e-9)
+
+	// The saturation is unknown without the pool size
+	fields = map[string]interface{}{"sv_active": int64(3)}
+	addPoolUsageFields(fields, 0)
+	require.Equal(t, map[string]interface{}{"sv_active": int64(3), "sv_total": int64(3)}, fields)
+}
@@ -24,13 +24,17 @@ var sampleConfig string
 var ignoredColumns = map[string]bool{"stats_reset": true}
 
 type Postgresql struct {
-	Databases          []string        `deprecated:"1.22.4;use the sqlquery option to specify database to use"`
-	Query              []query         `toml:"query"`
-	PreparedStatements bool            `toml:"prepared_statements"`
-	Log                telegraf.Logger `toml:"-"`
+	Databases                 []string        `deprecated:"1.22.4;use the sqlquery option to specify database to use"`
+	Query                     []query         `toml:"query"`
+	PreparedStatements        bool            `toml:"prepared_statements"`
+	StatStatements            bool            `toml:"pg_stat_statements"`
+	StatStatementsTop         int             `toml:"pg_stat_statements_top"`
+	StatStatementsQueryLength int             `toml:"pg_stat_statements_query_length"`
+	Log                       telegraf.Logger `toml:"-"`
 	postgresql.Config
 
-	service *postgresql.Service
+	service    *postgresql.Service
+	statements *statementsTracker
 }
 
 type query struct {
@@ -92,6 +96,16 @@ func (p *Postgresql) Init() error {
 	}
 	p.Config.IsPgBouncer = !p.PreparedStatements
 
+	if p.StatStatements {
+		if p.StatStatementsTop < 1 {
+			return fmt.Errorf("invalid number of top statements %d", p.StatStatementsTop)
+		}
+		if p.StatStatementsQueryLength < 0 {
+			return fmt.Errorf("invalid query length %d", p.StatStatementsQueryLength)
+		}
+		p.statements = newStatementsTracker()
+	}
+
 	// Create a service to access the PostgreSQL server
 	service, err := p.Config.CreateService()
 	if err != nil {
@@ -125,6 +139,10 @@ func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(p.gatherMetricsFromQuery(acc, q, timestamp))
 		}
 	}
+
+	if p.statements != nil {
+		acc.AddError(p.gatherStatements(acc, dbVersion, timestamp))
+	}
 	return nil
 }
 
@@ -234,7 +252,9 @@ func init() {
 				MaxIdle: 1,
 				MaxOpen: 1,
 			},
-			PreparedStatements: true,
+			PreparedStatements:        true,
+			StatStatementsTop:         10,
+			StatStatementsQueryLength: 256,
 		}
 	})
 }
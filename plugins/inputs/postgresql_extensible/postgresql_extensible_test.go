@@ -366,3 +366,62 @@ func (f fakeRow) Scan(dest ...interface{}) error {
 	}
 	return nil
 }
+
+func TestStatementsInitFail(t *testing.T) {
+	plugin := &Postgresql{StatStatements: true, StatStatementsQueryLength: 256}
+	require.ErrorContains(t, plugin.Init(), "invalid number of top statements 0")
+
+	plugin = &Postgresql{StatStatements: true, StatStatementsTop: 10, StatStatementsQueryLength: -1}
+	require.ErrorContains(t, plugin.Init(), "invalid query length -1")
+}
+
+func TestStatementsQuery(t *testing.T) {
+	query := statementsQuery(1600, 5, 100)
+	require.Contains(t, query, "left(s.query, 100)")
+	require.Contains(t, query, "ORDER BY s.total_exec_time DESC")
+	require.Contains(t, query, "LIMIT 5")
+
+	query = statementsQuery(1200, 5, 100)
+	require.Contains(t, query, "ORDER BY s.total_time DESC")
+	require.NotContains(t, query, "total_exec_time")
+}
+
+func TestStatementsTracker(t *testing.T) {
+	reset := time.Date(2024, 6, 14, 8, 0, 0, 0, time.UTC)
+	tracker := newStatementsTracker()
+
+	// The first gather only provides the reference values
+	deltas := tracker.update([]statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 10, totalTime: 100, rows: 10},
+		{queryID: 2, database: "app", user: "alice", query: "SELECT 2", calls: 5, totalTime: 50, rows: 5},
+	}, reset)
+	require.Empty(t, deltas)
+
+	// Statements without calls in the interval are skipped, new statements
+	// have no reference and calls decreasing indicate a reset
+	deltas = tracker.update([]statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 15, totalTime: 130, rows: 20, sharedBlksHit: 4},
+		{queryID: 2, database: "app", user: "alice", query: "SELECT 2", calls: 5, totalTime: 50, rows: 5},
+		{queryID: 2, database: "app", user: "bob", query: "SELECT 2", calls: 3, totalTime: 30, rows: 3},
+	}, reset)
+	require.Equal(t, []statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 5, totalTime: 30, rows: 10, sharedBlksHit: 4},
+	}, deltas)
+
+	deltas = tracker.update([]statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 2, totalTime: 20, rows: 2},
+	}, reset)
+	require.Equal(t, []statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 2, totalTime: 20, rows: 2},
+	}, deltas)
+
+	// After a reset of all statistics, the values since the reset are used
+	deltas = tracker.update([]statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 3, totalTime: 25, rows: 3},
+		{queryID: 3, database: "app", user: "alice", query: "SELECT 3", calls: 1, totalTime: 1, rows: 1},
+	}, reset.Add(time.Hour))
+	require.Equal(t, []statementRow{
+		{queryID: 1, database: "app", user: "alice", query: "SELECT 1", calls: 3, totalTime: 25, rows: 3},
+		{queryID: 3, database: "app", user: "alice", query: "SELECT 3", calls: 1, totalTime: 1, rows: 1},
+	}, deltas)
+}
@@ -0,0 +1,155 @@
+package postgresql_extensible
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const statementsMeasurement = "postgresql_statements"
+
+// statementRow is the cumulative statistics of a statement as reported by
+// the pg_stat_statements extension
+type statementRow struct {
+	queryID        int64
+	database       string
+	user           string
+	query          string
+	calls          int64
+	totalTime      float64
+	rows           int64
+	sharedBlksHit  int64
+	sharedBlksRead int64
+}
+
+type statementKey struct {
+	queryID  int64
+	database string
+	user     string
+}
+
+// statementsTracker computes the statistics of the statements between two
+// gathers from the cumulative values
+type statementsTracker struct {
+	previous   map[statementKey]statementRow
+	statsReset time.Time
+}
+
+func newStatementsTracker() *statementsTracker {
+	return &statementsTracker{previous: make(map[statementKey]statementRow)}
+}
+
+// statementsQuery returns the query for the top statements by total
+// execution time; the column was renamed in PostgreSQL 13
+func statementsQuery(version, top, queryLength int) string {
+	totalTime := "total_exec_time"
+	if version > 0 && version < 1300 {
+		totalTime = "total_time"
+	}
+	return fmt.Sprintf(`SELECT s.queryid, d.datname, r.rolname, left(s.query, %d), s.calls, s.%s, s.rows,
+  s.shared_blks_hit, s.shared_blks_read
+FROM pg_stat_statements s
+  JOIN pg_database d ON d.oid = s.dbid
+  JOIN pg_roles r ON r.oid = s.userid
+WHERE s.queryid IS NOT NULL
+ORDER BY s.%s DESC
+LIMIT %d`, queryLength, totalTime, totalTime, top)
+}
+
+func (p *Postgresql) gatherStatements(acc telegraf.Accumulator, version int, timestamp time.Time) error {
+	// The time of the last reset is only available since PostgreSQL 14
+	var statsReset time.Time
+	if version >= 1400 {
+		var t sql.NullTime
+		if err := p.service.DB.QueryRow(`SELECT stats_reset FROM pg_stat_statements_info`).Scan(&t); err != nil {
+			return fmt.Errorf("querying pg_stat_statements reset time failed: %w", err)
+		}
+		statsReset = t.Time
+	}
+
+	rows, err := p.service.DB.Query(statementsQuery(version, p.StatStatementsTop, p.StatStatementsQueryLength))
+	if err != nil {
+		return fmt.Errorf("querying pg_stat_statements failed: %w", err)
+	}
+	defer rows.Close()
+
+	var statements []statementRow
+	for rows.Next() {
+		var s statementRow
+		err := rows.Scan(&s.queryID, &s.database, &s.user, &s.query, &s.calls, &s.totalTime, &s.rows, &s.sharedBlksHit, &s.sharedBlksRead)
+		if err != nil {
+			return fmt.Errorf("reading pg_stat_statements failed: %w", err)
+		}
+		statements = append(statements, s)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading pg_stat_statements failed: %w", err)
+	}
+
+	for _, s := range p.statements.update(statements, statsReset) {
+		tags := map[string]string{
+			"server":  p.service.SanitizedAddress,
+			"db":      s.database,
+			"user":    s.user,
+			"queryid": strconv.FormatInt(s.queryID, 10),
+		}
+		fields := map[string]interface{}{
+			"calls":            s.calls,
+			"total_time":       s.totalTime,
+			"mean_time":        s.totalTime / float64(s.calls),
+			"rows":             s.rows,
+			"shared_blks_hit":  s.sharedBlksHit,
+			"shared_blks_read": s.sharedBlksRead,
+		}
+		if p.StatStatementsQueryLength > 0 {
+			fields["query"] = s.query
+		}
+		acc.AddFields(statementsMeasurement, fields, tags, timestamp)
+	}
+
+	return nil
+}
+
+// update returns the statistics of the statements executed since the last
+// update. Statements seen for the first time are skipped as the interval of
+// the cumulative values is unknown, except after a reset of the statistics.
+// A decrease of the calls is handled as reset of the statement.
+func (t *statementsTracker) update(rows []statementRow, statsReset time.Time) []statementRow {
+	reset := !statsReset.Equal(t.statsReset) && !t.statsReset.IsZero()
+	t.statsReset = statsReset
+
+	current := make(map[statementKey]statementRow, len(rows))
+	deltas := make([]statementRow, 0, len(rows))
+	for _, row := range rows {
+		key := statementKey{queryID: row.queryID, database: row.database, user: row.user}
+		current[key] = row
+
+		previous, found := t.previous[key]
+		var delta statementRow
+		switch {
+		case reset || (found && row.calls < previous.calls):
+			delta = row
+		case found:
+			delta = row
+			delta.calls -= previous.calls
+			delta.totalTime -= previous.totalTime
+			delta.rows -= previous.rows
+			delta.sharedBlksHit -= previous.sharedBlksHit
+			delta.sharedBlksRead -= previous.sharedBlksRead
+		default:
+			continue
+		}
+
+		// Skip statements not executed within the interval
+		if delta.calls <= 0 {
+			continue
+		}
+		deltas = append(deltas, delta)
+	}
+	t.previous = current
+
+	return deltas
+}
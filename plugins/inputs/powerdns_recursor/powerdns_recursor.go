@@ -21,6 +21,7 @@ type PowerdnsRecursor struct {
 	SocketDir              string   `toml:"socket_dir"`
 	SocketMode             string   `toml:"socket_mode"`
 	ControlProtocolVersion int      `toml:"control_protocol_version"`
+	ThreadAsTag            bool     `toml:"thread_as_tag"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -53,7 +54,7 @@ func (p *PowerdnsRecursor) Init() error {
 	case 2:
 		p.gatherFromServer = p.gatherFromV2Server
 	case 3:
-		p.gatherFromServer = gatherFromV3Server
+		p.gatherFromServer = p.gatherFromV3Server
 	default:
 		return fmt.Errorf("unknown control protocol version '%d', allowed values are 1, 2, 3", p.ControlProtocolVersion)
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -673,3 +674,33 @@ func TestPowerdnsRecursorParseIntOverflowMetrics(t *testing.T) {
 		require.EqualValuesf(t, value, test.value, "Metric: %s, Expected: %d, actual: %d", test.key, test.value, value)
 	}
 }
+
+func TestThreadAsTag(t *testing.T) {
+	fields := parseResponse("cpu-msec-thread-0\t1200\ncpu-msec-thread-1\t800\nquestions\t42\n")
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"powerdns_recursor",
+			map[string]string{"server": "/tmp/pdns.controlsocket"},
+			map[string]interface{}{"questions": int64(42)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"powerdns_recursor_thread",
+			map[string]string{"server": "/tmp/pdns.controlsocket", "thread": "0"},
+			map[string]interface{}{"cpu-msec": int64(1200)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"powerdns_recursor_thread",
+			map[string]string{"server": "/tmp/pdns.controlsocket", "thread": "1"},
+			map[string]interface{}{"cpu-msec": int64(800)},
+			time.Unix(0, 0),
+		),
+	}
+
+	p := &PowerdnsRecursor{ThreadAsTag: true}
+	var acc testutil.Accumulator
+	p.addMetrics(&acc, "/tmp/pdns.controlsocket", fields)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
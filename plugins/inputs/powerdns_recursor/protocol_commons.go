@@ -7,9 +7,32 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 )
 
+// Prefix of the per-thread statistics, e.g. "cpu-msec-thread-0"
+const threadStatPrefix = "cpu-msec-thread-"
+
+// addMetrics adds the statistics of the server. With thread_as_tag enabled,
+// the per-thread statistics are moved to separate metrics tagged with the
+// thread number.
+func (p *PowerdnsRecursor) addMetrics(acc telegraf.Accumulator, address string, fields map[string]interface{}) {
+	if p.ThreadAsTag {
+		for key, value := range fields {
+			thread, found := strings.CutPrefix(key, threadStatPrefix)
+			if !found {
+				continue
+			}
+			delete(fields, key)
+			tags := map[string]string{"server": address, "thread": thread}
+			acc.AddFields("powerdns_recursor_thread", map[string]interface{}{"cpu-msec": value}, tags)
+		}
+	}
+
+	acc.AddFields("powerdns_recursor", fields, map[string]string{"server": address})
+}
+
 func parseResponse(metrics string) map[string]interface{} {
 	values := make(map[string]interface{})
 
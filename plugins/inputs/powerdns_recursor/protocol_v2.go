@@ -84,12 +84,7 @@ func (p *PowerdnsRecursor) gatherFromV2Server(address string, acc telegraf.Accum
 	metrics := string(buf)
 
 	// Process data
-	fields := parseResponse(metrics)
-
-	// Add server socket as a tag
-	tags := map[string]string{"server": address}
-
-	acc.AddFields("powerdns_recursor", fields, tags)
+	p.addMetrics(acc, address, parseResponse(metrics))
 
 	return nil
 }
@@ -16,7 +16,7 @@ import (
 // status: uint32
 // dataLength: size_t
 // data: byte[dataLength]
-func gatherFromV3Server(address string, acc telegraf.Accumulator) error {
+func (p *PowerdnsRecursor) gatherFromV3Server(address string, acc telegraf.Accumulator) error {
 	conn, err := net.Dial("unix", address)
 	if err != nil {
 		return err
@@ -80,12 +80,7 @@ func gatherFromV3Server(address string, acc telegraf.Accumulator) error {
 
 	// Process data
 	metrics := string(data)
-	fields := parseResponse(metrics)
-
-	// Add server socket as a tag
-	tags := map[string]string{"server": address}
-
-	acc.AddFields("powerdns_recursor", fields, tags)
+	p.addMetrics(acc, address, parseResponse(metrics))
 
 	return nil
 }
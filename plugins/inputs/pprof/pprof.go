@@ -0,0 +1,224 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package pprof
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum size of a profile to prevent running out of memory
+const maxProfileSize = 64 * 1024 * 1024
+
+// Profiles supported by the endpoints of the Go net/http/pprof package and
+// whether the profile is recorded over the sample duration
+var profileTypes = map[string]bool{
+	"cpu":          true,
+	"heap":         false,
+	"allocs":       true,
+	"goroutine":    false,
+	"block":        true,
+	"mutex":        true,
+	"threadcreate": false,
+}
+
+type Pprof struct {
+	URLs           []string        `toml:"urls"`
+	Profiles       []string        `toml:"profiles"`
+	SampleDuration config.Duration `toml:"sample_duration"`
+	TopFunctions   int             `toml:"top_functions"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+func (*Pprof) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Pprof) Init() error {
+	if len(p.URLs) == 0 {
+		return errors.New("no urls specified")
+	}
+	for i, u := range p.URLs {
+		if _, err := url.Parse(u); err != nil {
+			return fmt.Errorf("parsing url %q failed: %w", u, err)
+		}
+		p.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	if len(p.Profiles) == 0 {
+		p.Profiles = []string{"cpu", "heap", "goroutine"}
+	}
+	for _, profile := range p.Profiles {
+		if _, found := profileTypes[profile]; !found {
+			return fmt.Errorf("invalid profile %q", profile)
+		}
+	}
+
+	// Recording a profile over the sample duration requires at least one
+	// second as the endpoints only accept full seconds
+	if time.Duration(p.SampleDuration) < time.Second {
+		return errors.New("sample_duration must be at least one second")
+	}
+	if p.TopFunctions < 0 {
+		return errors.New("top_functions must not be negative")
+	}
+
+	// The requests of recorded profiles only return after the sample
+	// duration so extend the timeout accordingly
+	p.Timeout += p.SampleDuration
+	client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	p.client = client
+
+	return nil
+}
+
+func (p *Pprof) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range p.URLs {
+		for _, profile := range p.Profiles {
+			wg.Add(1)
+			go func(address, profile string) {
+				defer wg.Done()
+				if err := p.gatherProfile(acc, address, profile); err != nil {
+					acc.AddError(fmt.Errorf("collecting %s profile of %q failed: %w", profile, address, err))
+				}
+			}(u, profile)
+		}
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *Pprof) Stop() {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+}
+
+func (p *Pprof) gatherProfile(acc telegraf.Accumulator, address, profile string) error {
+	data, err := p.fetch(profileURL(address, profile, time.Duration(p.SampleDuration)))
+	if err != nil {
+		return err
+	}
+	prof, err := parseProfile(data)
+	if err != nil {
+		return fmt.Errorf("parsing profile failed: %w", err)
+	}
+
+	tags := map[string]string{
+		"url":     address,
+		"profile": profile,
+	}
+	fields := summarize(prof.sampleTypes, prof.totals())
+	if prof.durationNanos > 0 {
+		fields["duration_ns"] = prof.durationNanos
+		if v, found := fields["cpu_nanoseconds"]; found {
+			fields["cpu_usage"] = 100 * float64(v.(int64)) / float64(prof.durationNanos)
+		}
+	}
+	acc.AddFields("pprof", fields, tags)
+
+	if p.TopFunctions == 0 {
+		return nil
+	}
+	for _, f := range prof.topFunctions(p.TopFunctions) {
+		tags := map[string]string{
+			"url":      address,
+			"profile":  profile,
+			"function": f.name,
+		}
+		acc.AddFields("pprof_function", summarize(prof.sampleTypes, f.values), tags)
+	}
+
+	return nil
+}
+
+func (p *Pprof) fetch(address string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("%s returned HTTP status %s: %q", address, resp.Status, body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProfileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+	if len(data) > maxProfileSize {
+		return nil, fmt.Errorf("profile exceeds limit of %d bytes", maxProfileSize)
+	}
+	return data, nil
+}
+
+// profileURL returns the endpoint of the profile, profiles recorded over a
+// duration are requested for the given number of seconds
+func profileURL(address, profile string, duration time.Duration) string {
+	endpoint := profile
+	if profile == "cpu" {
+		endpoint = "profile"
+	}
+	if !profileTypes[profile] {
+		return address + "/" + endpoint
+	}
+	seconds := strconv.FormatInt(int64(duration/time.Second), 10)
+	return address + "/" + endpoint + "?seconds=" + seconds
+}
+
+// summarize returns the values as fields named by the sample type with the
+// unit as suffix, except for counts
+func summarize(types []valueType, values []int64) map[string]interface{} {
+	fields := make(map[string]interface{}, len(types)+2)
+	for i, vt := range types {
+		name := vt.Type
+		if vt.Unit != "" && vt.Unit != "count" {
+			name += "_" + vt.Unit
+		}
+		fields[name] = values[i]
+	}
+	return fields
+}
+
+func init() {
+	inputs.Add("pprof", func() telegraf.Input {
+		return &Pprof{
+			SampleDuration: config.Duration(5 * time.Second),
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
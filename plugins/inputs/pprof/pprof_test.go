@@ -0,0 +1,299 @@
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// cpuProfile returns an encoded CPU profile with a duration of one second
+// containing samples of an inlined and a non-inlined function
+func cpuProfile(t *testing.T, compress bool) []byte {
+	t.Helper()
+
+	strs := []string{"", "samples", "count", "cpu", "nanoseconds", "main.work", "main.helper", "main.main"}
+	index := func(s string) uint64 {
+		for i, v := range strs {
+			if v == s {
+				return uint64(i)
+			}
+		}
+		t.Fatalf("unknown string %q", s)
+		return 0
+	}
+
+	var buf []byte
+	appendMessage := func(num protowire.Number, msg []byte) {
+		buf = protowire.AppendTag(buf, num, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, msg)
+	}
+	appendVarint := func(msg []byte, num protowire.Number, v uint64) []byte {
+		msg = protowire.AppendTag(msg, num, protowire.VarintType)
+		return protowire.AppendVarint(msg, v)
+	}
+	appendPackedVarints := func(msg []byte, num protowire.Number, values ...uint64) []byte {
+		var packed []byte
+		for _, v := range values {
+			packed = protowire.AppendVarint(packed, v)
+		}
+		msg = protowire.AppendTag(msg, num, protowire.BytesType)
+		return protowire.AppendBytes(msg, packed)
+	}
+
+	for _, vt := range [][2]string{{"samples", "count"}, {"cpu", "nanoseconds"}} {
+		msg := appendVarint(nil, valueTypeType, index(vt[0]))
+		appendMessage(profileSampleType, appendVarint(msg, valueTypeUnit, index(vt[1])))
+	}
+
+	// Packed encoding
+	msg := appendPackedVarints(nil, sampleLocationID, 1, 3)
+	appendMessage(profileSample, appendPackedVarints(msg, sampleValue, 3, 30000000))
+	msg = appendPackedVarints(nil, sampleLocationID, 2, 3)
+	appendMessage(profileSample, appendPackedVarints(msg, sampleValue, 5, 50000000))
+	// Unpacked encoding
+	msg = appendVarint(nil, sampleLocationID, 1)
+	msg = appendVarint(msg, sampleValue, 2)
+	appendMessage(profileSample, appendVarint(msg, sampleValue, 20000000))
+
+	// Location 2 contains main.helper inlined into main.work
+	for id, functions := range map[uint64][]uint64{1: {1}, 2: {2, 1}, 3: {3}} {
+		msg := appendVarint(nil, locationID, id)
+		for _, fn := range functions {
+			var line []byte
+			line = appendVarint(line, lineFunctionID, fn)
+			msg = protowire.AppendTag(msg, locationLine, protowire.BytesType)
+			msg = protowire.AppendBytes(msg, line)
+		}
+		appendMessage(profileLocation, msg)
+	}
+	for id, name := range map[uint64]string{1: "main.work", 2: "main.helper", 3: "main.main"} {
+		msg := appendVarint(nil, functionID, id)
+		appendMessage(profileFunction, appendVarint(msg, functionName, index(name)))
+	}
+	for _, s := range strs {
+		buf = protowire.AppendTag(buf, profileStringTable, protowire.BytesType)
+		buf = protowire.AppendString(buf, s)
+	}
+	buf = appendVarint(buf, profileDurationNanos, uint64(time.Second))
+
+	if !compress {
+		return buf
+	}
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write(buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return compressed.Bytes()
+}
+
+func TestParseProfile(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		prof, err := parseProfile(cpuProfile(t, compress))
+		require.NoError(t, err)
+
+		expected := []valueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}}
+		require.Equal(t, expected, prof.sampleTypes)
+		require.Equal(t, []int64{10, 100000000}, prof.totals())
+		require.Equal(t, int64(time.Second), prof.durationNanos)
+
+		top := prof.topFunctions(5)
+		require.Equal(t, []functionValues{
+			{name: "main.helper", values: []int64{5, 50000000}},
+			{name: "main.work", values: []int64{5, 50000000}},
+		}, top)
+		require.Len(t, prof.topFunctions(1), 1)
+	}
+}
+
+func TestParseRuntimeProfile(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 0))
+
+	prof, err := parseProfile(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []valueType{{Type: "goroutine", Unit: "count"}}, prof.sampleTypes)
+	require.Positive(t, prof.totals()[0])
+	require.NotEmpty(t, prof.topFunctions(1)[0].name)
+}
+
+func TestParseProfileInvalid(t *testing.T) {
+	valid := cpuProfile(t, false)
+	_, err := parseProfile(valid[:len(valid)-3])
+	require.Error(t, err)
+
+	_, err = parseProfile([]byte{0x1f, 0x8b, 0x00})
+	require.ErrorContains(t, err, "decompressing profile failed")
+
+	_, err = parseProfile(nil)
+	require.ErrorContains(t, err, "profile has no sample types")
+
+	// Reference to a missing string
+	var buf []byte
+	buf = protowire.AppendTag(buf, profileSampleType, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, protowire.AppendVarint(protowire.AppendTag(nil, valueTypeType, protowire.VarintType), 5))
+	_, err = parseProfile(buf)
+	require.ErrorContains(t, err, "string index 5 out of range")
+}
+
+func TestProfileURL(t *testing.T) {
+	address := "http://localhost:6060/debug/pprof"
+	require.Equal(t, address+"/profile?seconds=5", profileURL(address, "cpu", 5*time.Second))
+	require.Equal(t, address+"/allocs?seconds=2", profileURL(address, "allocs", 2500*time.Millisecond))
+	require.Equal(t, address+"/heap", profileURL(address, "heap", 5*time.Second))
+	require.Equal(t, address+"/goroutine", profileURL(address, "goroutine", 5*time.Second))
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Pprof
+		expected string
+	}{
+		{
+			name:     "no urls",
+			plugin:   &Pprof{SampleDuration: config.Duration(time.Second)},
+			expected: "no urls specified",
+		},
+		{
+			name: "invalid profile",
+			plugin: &Pprof{
+				URLs:           []string{"http://localhost:6060/debug/pprof"},
+				Profiles:       []string{"trace"},
+				SampleDuration: config.Duration(time.Second),
+			},
+			expected: `invalid profile "trace"`,
+		},
+		{
+			name: "short sample duration",
+			plugin: &Pprof{
+				URLs:           []string{"http://localhost:6060/debug/pprof"},
+				SampleDuration: config.Duration(500 * time.Millisecond),
+			},
+			expected: "sample_duration must be at least one second",
+		},
+		{
+			name: "negative top functions",
+			plugin: &Pprof{
+				URLs:           []string{"http://localhost:6060/debug/pprof"},
+				SampleDuration: config.Duration(time.Second),
+				TopFunctions:   -1,
+			},
+			expected: "top_functions must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	profile := cpuProfile(t, true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/debug/pprof/profile":
+			if r.URL.Query().Get("seconds") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if _, err := w.Write(profile); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		case "/debug/pprof/goroutine":
+			if err := pprof.Lookup("goroutine").WriteTo(w, 0); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	address := server.URL + "/debug/pprof"
+	plugin := &Pprof{
+		URLs:           []string{address + "/"},
+		Profiles:       []string{"cpu", "goroutine"},
+		SampleDuration: config.Duration(time.Second),
+		TopFunctions:   1,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"pprof",
+			map[string]string{"url": address, "profile": "cpu"},
+			map[string]interface{}{
+				"samples":         int64(10),
+				"cpu_nanoseconds": int64(100000000),
+				"duration_ns":     int64(time.Second),
+				"cpu_usage":       float64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"pprof_function",
+			map[string]string{"url": address, "profile": "cpu", "function": "main.helper"},
+			map[string]interface{}{
+				"samples":         int64(5),
+				"cpu_nanoseconds": int64(50000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["profile"] == "cpu" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	require.True(t, acc.HasInt64Field("pprof", "goroutine"))
+	require.True(t, acc.HasTag("pprof_function", "function"))
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	plugin := &Pprof{
+		URLs:           []string{server.URL + "/debug/pprof"},
+		Profiles:       []string{"heap"},
+		SampleDuration: config.Duration(time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "collecting heap profile")
+	require.ErrorContains(t, acc.Errors[0], "404 Not Found")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
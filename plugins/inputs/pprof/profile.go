@@ -0,0 +1,332 @@
+package pprof
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the messages defined in the pprof profile.proto, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType        protowire.Number = 1
+	profileSample            protowire.Number = 2
+	profileLocation          protowire.Number = 4
+	profileFunction          protowire.Number = 5
+	profileStringTable       protowire.Number = 6
+	profileDurationNanos     protowire.Number = 10
+	profileDefaultSampleType protowire.Number = 14
+
+	valueTypeType protowire.Number = 1
+	valueTypeUnit protowire.Number = 2
+
+	sampleLocationID protowire.Number = 1
+	sampleValue      protowire.Number = 2
+
+	locationID   protowire.Number = 1
+	locationLine protowire.Number = 4
+
+	lineFunctionID protowire.Number = 1
+
+	functionID   protowire.Number = 1
+	functionName protowire.Number = 2
+)
+
+type valueType struct {
+	Type string
+	Unit string
+}
+
+type sample struct {
+	locations []uint64
+	values    []int64
+}
+
+// profile is the subset of a pprof profile required for summarizing it
+type profile struct {
+	sampleTypes       []valueType
+	samples           []sample
+	durationNanos     int64
+	defaultSampleType string
+
+	// function name per location, the name of the innermost function is
+	// used for inlined functions
+	locations map[uint64]string
+}
+
+// functionValues are the values of the samples with the function as leaf
+type functionValues struct {
+	name   string
+	values []int64
+}
+
+// parseProfile decodes a, possibly gzip compressed, pprof profile
+func parseProfile(data []byte) (*profile, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing profile failed: %w", err)
+		}
+		defer r.Close()
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("decompressing profile failed: %w", err)
+		}
+	}
+
+	// Strings are referenced by their index in the string table, which is
+	// usually encoded last, so resolve them after decoding the message
+	var strs []string
+	var sampleTypes [][2]int64
+	var defaultSampleType int64
+	locations := make(map[uint64]uint64)
+	functions := make(map[uint64]int64)
+	p := &profile{}
+	err := walkFields(data, func(f wireField) error {
+		switch {
+		case f.num == profileSampleType && f.typ == protowire.BytesType:
+			var vt [2]int64
+			err := walkFields(f.data, func(f wireField) error {
+				switch f.num {
+				case valueTypeType:
+					vt[0] = int64(f.value)
+				case valueTypeUnit:
+					vt[1] = int64(f.value)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("decoding sample type failed: %w", err)
+			}
+			sampleTypes = append(sampleTypes, vt)
+		case f.num == profileSample && f.typ == protowire.BytesType:
+			var s sample
+			err := walkFields(f.data, func(f wireField) error {
+				switch f.num {
+				case sampleLocationID:
+					return appendPacked(&s.locations, f, func(v uint64) uint64 { return v })
+				case sampleValue:
+					return appendPacked(&s.values, f, func(v uint64) int64 { return int64(v) })
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("decoding sample failed: %w", err)
+			}
+			p.samples = append(p.samples, s)
+		case f.num == profileLocation && f.typ == protowire.BytesType:
+			var id, function uint64
+			err := walkFields(f.data, func(f wireField) error {
+				switch {
+				case f.num == locationID:
+					id = f.value
+				case f.num == locationLine && f.typ == protowire.BytesType && function == 0:
+					// The first line is the innermost one for inlined calls
+					return walkFields(f.data, func(f wireField) error {
+						if f.num == lineFunctionID {
+							function = f.value
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("decoding location failed: %w", err)
+			}
+			locations[id] = function
+		case f.num == profileFunction && f.typ == protowire.BytesType:
+			var id uint64
+			var name int64
+			err := walkFields(f.data, func(f wireField) error {
+				switch f.num {
+				case functionID:
+					id = f.value
+				case functionName:
+					name = int64(f.value)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("decoding function failed: %w", err)
+			}
+			functions[id] = name
+		case f.num == profileStringTable && f.typ == protowire.BytesType:
+			strs = append(strs, string(f.data))
+		case f.num == profileDurationNanos:
+			p.durationNanos = int64(f.value)
+		case f.num == profileDefaultSampleType:
+			defaultSampleType = int64(f.value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(idx int64) (string, error) {
+		if idx < 0 || idx >= int64(len(strs)) {
+			return "", fmt.Errorf("string index %d out of range", idx)
+		}
+		return strs[idx], nil
+	}
+
+	if len(sampleTypes) == 0 {
+		return nil, errors.New("profile has no sample types")
+	}
+	for _, vt := range sampleTypes {
+		typ, err := str(vt[0])
+		if err != nil {
+			return nil, fmt.Errorf("decoding sample type failed: %w", err)
+		}
+		unit, err := str(vt[1])
+		if err != nil {
+			return nil, fmt.Errorf("decoding sample type failed: %w", err)
+		}
+		p.sampleTypes = append(p.sampleTypes, valueType{Type: typ, Unit: unit})
+	}
+	if p.defaultSampleType, err = str(defaultSampleType); err != nil {
+		return nil, fmt.Errorf("decoding default sample type failed: %w", err)
+	}
+
+	p.locations = make(map[uint64]string, len(locations))
+	for id, function := range locations {
+		name, err := str(functions[function])
+		if err != nil {
+			return nil, fmt.Errorf("decoding function %d failed: %w", function, err)
+		}
+		p.locations[id] = name
+	}
+
+	for i, s := range p.samples {
+		if len(s.values) != len(p.sampleTypes) {
+			return nil, fmt.Errorf("sample %d has %d values but expected %d", i+1, len(s.values), len(p.sampleTypes))
+		}
+	}
+
+	return p, nil
+}
+
+// totals returns the sum of the values of all samples per sample type
+func (p *profile) totals() []int64 {
+	totals := make([]int64, len(p.sampleTypes))
+	for _, s := range p.samples {
+		for i, v := range s.values {
+			totals[i] += v
+		}
+	}
+	return totals
+}
+
+// topFunctions returns the n functions with the highest value of the default
+// sample type, or the last sample type if no default is specified, in the
+// samples where the function is the leaf
+func (p *profile) topFunctions(n int) []functionValues {
+	idx := len(p.sampleTypes) - 1
+	for i, vt := range p.sampleTypes {
+		if vt.Type == p.defaultSampleType {
+			idx = i
+			break
+		}
+	}
+
+	functions := make(map[string][]int64)
+	for _, s := range p.samples {
+		if len(s.locations) == 0 {
+			continue
+		}
+		name := p.locations[s.locations[0]]
+		if name == "" {
+			name = "unknown"
+		}
+		values, found := functions[name]
+		if !found {
+			values = make([]int64, len(p.sampleTypes))
+			functions[name] = values
+		}
+		for i, v := range s.values {
+			values[i] += v
+		}
+	}
+
+	top := make([]functionValues, 0, len(functions))
+	for name, values := range functions {
+		top = append(top, functionValues{name: name, values: values})
+	}
+	slices.SortFunc(top, func(a, b functionValues) int {
+		if c := cmp.Compare(b.values[idx], a.values[idx]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// appendPacked appends the values of a repeated scalar field which might be
+// encoded packed or as individual fields
+func appendPacked[T any](values *[]T, f wireField, conv func(uint64) T) error {
+	switch f.typ {
+	case protowire.VarintType:
+		*values = append(*values, conv(f.value))
+	case protowire.BytesType:
+		buf := f.data
+		for len(buf) > 0 {
+			v, n := protowire.ConsumeVarint(buf)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			*values = append(*values, conv(v))
+			buf = buf[n:]
+		}
+	}
+	return nil
+}
+
+// wireField is a single field of an encoded protobuf message with the value
+// stored depending on the wire type
+type wireField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	value uint64
+	data  []byte
+}
+
+func walkFields(buf []byte, fn func(wireField) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		f := wireField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(buf)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(buf)
+		case protowire.BytesType:
+			f.data, n = protowire.ConsumeBytes(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
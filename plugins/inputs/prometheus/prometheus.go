@@ -56,6 +56,8 @@ type Prometheus struct {
 	MetricVersion        int               `toml:"metric_version"`
 	URLTag               string            `toml:"url_tag"`
 	IgnoreTimestamp      bool              `toml:"ignore_timestamp"`
+	TargetInfoJoin       bool              `toml:"target_info_join"`
+	TargetInfoTTL        config.Duration   `toml:"target_info_ttl"`
 
 	// Kubernetes service discovery
 	MonitorPods                 bool                `toml:"monitor_kubernetes_pods"`
@@ -92,6 +94,7 @@ type Prometheus struct {
 	client      *http.Client
 	headers     map[string]string
 	contentType string
+	targetInfo  *targetInfoCache
 
 	nsStore          cache.Store
 	nsAnnotationPass []models.TagFilter
@@ -255,6 +258,10 @@ func (p *Prometheus) Init() error {
 
 	p.kubernetesPods = make(map[podID]urlAndAddress)
 
+	if p.TargetInfoJoin {
+		p.targetInfo = newTargetInfoCache(time.Duration(p.TargetInfoTTL))
+	}
+
 	return nil
 }
 
@@ -581,6 +588,15 @@ func (p *Prometheus) gatherURL(u urlAndAddress, acc telegraf.Accumulator) (map[s
 		return requestFields, tags, fmt.Errorf("error reading metrics for %q: %w", u.url, err)
 	}
 
+	// Join the resource attributes of the target onto the series
+	if p.targetInfo != nil {
+		source := u.url.String()
+		p.targetInfo.update(source, metrics, time.Now())
+		for _, metric := range metrics {
+			p.targetInfo.join(source, metric)
+		}
+	}
+
 	for _, metric := range metrics {
 		tags := metric.Tags()
 		// strip user and password from URL
@@ -649,6 +665,7 @@ func init() {
 			consulServices: make(map[string]urlAndAddress),
 			httpServices:   make(map[string]urlAndAddress),
 			URLTag:         "url",
+			TargetInfoTTL:  config.Duration(10 * time.Minute),
 		}
 	})
 }
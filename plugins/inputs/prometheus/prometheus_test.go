@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -940,3 +941,86 @@ go_memstats_heap_alloc_bytes 1.581062048e+09
 
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestPrometheusTargetInfoJoin(t *testing.T) {
+	scrapes := []string{
+		`# HELP target_info Target metadata
+# TYPE target_info gauge
+target_info{job="shop",instance="a",service_version="1.2.3",instance_label="x"} 1
+target_info{job="shop",instance="b",service_version="1.2.4"} 1
+# HELP http_requests_total Number of requests
+# TYPE http_requests_total counter
+http_requests_total{job="shop",instance="a",instance_label="y"} 10
+http_requests_total{job="shop",instance="b"} 20
+http_requests_total{job="shop",instance="c"} 30
+`,
+		// Scrape without target info to check the cache
+		`# HELP http_requests_total Number of requests
+# TYPE http_requests_total counter
+http_requests_total{job="shop",instance="a"} 11
+`,
+	}
+
+	var scrape int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := fmt.Fprint(w, scrapes[scrape]); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	for _, version := range []int{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			p := &Prometheus{
+				Log:            testutil.Logger{},
+				URLs:           []string{ts.URL},
+				MetricVersion:  version,
+				TargetInfoJoin: true,
+				TargetInfoTTL:  config.Duration(time.Minute),
+			}
+			require.NoError(t, p.Init())
+
+			scrape = 0
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(p.Gather))
+			scrape = 1
+			require.NoError(t, acc.GatherError(p.Gather))
+
+			var actual []map[string]string
+			for _, m := range acc.GetTelegrafMetrics() {
+				if m.HasField("counter") || m.HasField("http_requests_total") {
+					actual = append(actual, m.Tags())
+				}
+			}
+			expected := []map[string]string{
+				{"job": "shop", "instance": "a", "instance_label": "y", "service_version": "1.2.3"},
+				{"job": "shop", "instance": "b", "service_version": "1.2.4"},
+				{"job": "shop", "instance": "c"},
+				{"job": "shop", "instance": "a", "instance_label": "x", "service_version": "1.2.3"},
+			}
+			require.ElementsMatch(t, expected, actual)
+		})
+	}
+}
+
+func TestTargetInfoCacheExpiry(t *testing.T) {
+	now := time.Now()
+	info := testutil.MustMetric(
+		"target_info",
+		map[string]string{"job": "shop", "instance": "a", "service_version": "1.2.3"},
+		map[string]interface{}{"gauge": float64(1)},
+		now,
+	)
+
+	c := newTargetInfoCache(time.Minute)
+	c.update("source", []telegraf.Metric{info}, now)
+	require.Len(t, c.entries, 1)
+
+	c.update("source", nil, now.Add(30*time.Second))
+	require.Len(t, c.entries, 1)
+
+	c.update("source", nil, now.Add(2*time.Minute))
+	require.Empty(t, c.entries)
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// targetInfoCache keeps the resource attributes of OpenTelemetry
+// "target_info" series per scraped URL and target, the target being
+// identified by the "job" and "instance" labels.
+type targetInfoCache struct {
+	ttl time.Duration
+
+	entries map[string]*targetInfo
+	sync.Mutex
+}
+
+type targetInfo struct {
+	tags map[string]string
+	seen time.Time
+}
+
+func newTargetInfoCache(ttl time.Duration) *targetInfoCache {
+	return &targetInfoCache{
+		ttl:     ttl,
+		entries: make(map[string]*targetInfo),
+	}
+}
+
+// isTargetInfo checks if the metric is a "target_info" series for all
+// combinations of metric versions and Prometheus or OpenMetrics format
+func isTargetInfo(m telegraf.Metric) bool {
+	switch m.Name() {
+	case "target_info":
+		return true
+	case "target":
+		return m.HasField("info")
+	}
+	return m.HasField("target_info")
+}
+
+func targetInfoKey(source string, m telegraf.Metric) string {
+	job, _ := m.GetTag("job")
+	instance, _ := m.GetTag("instance")
+	return source + "\x00" + job + "\x00" + instance
+}
+
+// update stores the resource attributes of all "target_info" series in the
+// given metrics and removes the entries not seen within the TTL
+func (c *targetInfoCache) update(source string, metrics []telegraf.Metric, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, m := range metrics {
+		if !isTargetInfo(m) {
+			continue
+		}
+		tags := m.Tags()
+		delete(tags, "job")
+		delete(tags, "instance")
+		c.entries[targetInfoKey(source, m)] = &targetInfo{tags: tags, seen: now}
+	}
+
+	for key, entry := range c.entries {
+		if now.Sub(entry.seen) > c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// join adds the resource attributes of the target to the metric without
+// overwriting existing tags
+func (c *targetInfoCache) join(source string, m telegraf.Metric) {
+	if isTargetInfo(m) {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	entry, found := c.entries[targetInfoKey(source, m)]
+	if !found {
+		return
+	}
+	for key, value := range entry.tags {
+		if !m.HasTag(key) {
+			m.AddTag(key, value)
+		}
+	}
+}
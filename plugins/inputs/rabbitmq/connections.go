@@ -0,0 +1,45 @@
+package rabbitmq
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+type connection struct {
+	Name       string `json:"name"`
+	Vhost      string `json:"vhost"`
+	User       string `json:"user"`
+	Node       string `json:"node"`
+	State      string `json:"state"`
+	Protocol   string `json:"protocol"`
+	Channels   int64  `json:"channels"`
+	ChannelMax int64  `json:"channel_max"`
+	RecvOct    int64  `json:"recv_oct"`
+	SendOct    int64  `json:"send_oct"`
+}
+
+func gatherConnections(r *RabbitMQ, acc telegraf.Accumulator) {
+	connections := make([]connection, 0)
+	if err := r.requestJSON("/api/connections", &connections); err != nil {
+		acc.AddError(err)
+		return
+	}
+
+	for _, c := range connections {
+		tags := map[string]string{
+			"url":        r.URL,
+			"connection": c.Name,
+			"vhost":      c.Vhost,
+			"user":       c.User,
+			"node":       c.Node,
+		}
+		fields := map[string]interface{}{
+			"state":       c.State,
+			"protocol":    c.Protocol,
+			"channels":    c.Channels,
+			"channel_max": c.ChannelMax,
+			"recv_bytes":  c.RecvOct,
+			"send_bytes":  c.SendOct,
+		}
+		acc.AddFields("rabbitmq_connection", fields, tags)
+	}
+}
@@ -0,0 +1,129 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// definitions contains the objects of a definitions export by kind
+type definitions map[string][]map[string]interface{}
+
+// definitionKeys defines the attributes identifying an object of the given
+// kind in the definitions. Global parameters are not compared as they contain
+// cluster specific values such as the cluster ID.
+var definitionKeys = map[string][]string{
+	"vhosts":            {"name"},
+	"users":             {"name"},
+	"permissions":       {"user", "vhost"},
+	"topic_permissions": {"user", "vhost", "exchange"},
+	"parameters":        {"vhost", "component", "name"},
+	"policies":          {"vhost", "name"},
+	"queues":            {"vhost", "name"},
+	"exchanges":         {"vhost", "name"},
+	"bindings":          {"vhost", "source", "destination", "destination_type", "routing_key", "arguments"},
+}
+
+type definitionsDrift struct {
+	missing    int64
+	unexpected int64
+	changed    int64
+}
+
+func parseDefinitions(buf []byte) (definitions, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, err
+	}
+
+	defs := make(definitions, len(definitionKeys))
+	for kind := range definitionKeys {
+		objects, found := raw[kind]
+		if !found {
+			continue
+		}
+		var list []map[string]interface{}
+		if err := json.Unmarshal(objects, &list); err != nil {
+			return nil, fmt.Errorf("decoding %s failed: %w", kind, err)
+		}
+		defs[kind] = list
+	}
+
+	return defs, nil
+}
+
+func gatherDefinitionsDrift(r *RabbitMQ, acc telegraf.Accumulator) {
+	buf, err := r.requestEndpoint("/api/definitions")
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	current, err := parseDefinitions(buf)
+	if err != nil {
+		acc.AddError(fmt.Errorf("decoding answer from %q failed: %w", "/api/definitions", err))
+		return
+	}
+
+	for kind, d := range compareDefinitions(r.referenceDefinitions, current) {
+		tags := map[string]string{
+			"url":  r.URL,
+			"kind": kind,
+		}
+		fields := map[string]interface{}{
+			"drift":      d.missing+d.unexpected+d.changed > 0,
+			"missing":    d.missing,
+			"unexpected": d.unexpected,
+			"changed":    d.changed,
+		}
+		acc.AddFields("rabbitmq_definitions", fields, tags)
+	}
+}
+
+// compareDefinitions returns the number of objects missing on the broker,
+// objects not contained in the reference and objects with differing
+// attributes for each kind of definition
+func compareDefinitions(reference, current definitions) map[string]definitionsDrift {
+	result := make(map[string]definitionsDrift, len(definitionKeys))
+	for kind, keys := range definitionKeys {
+		expected := indexDefinitions(reference[kind], keys)
+		actual := indexDefinitions(current[kind], keys)
+
+		var d definitionsDrift
+		for id, obj := range expected {
+			other, found := actual[id]
+			switch {
+			case !found:
+				d.missing++
+			case !reflect.DeepEqual(obj, other):
+				d.changed++
+			}
+		}
+		for id := range actual {
+			if _, found := expected[id]; !found {
+				d.unexpected++
+			}
+		}
+		result[kind] = d
+	}
+	return result
+}
+
+func indexDefinitions(objects []map[string]interface{}, keys []string) map[string]map[string]interface{} {
+	index := make(map[string]map[string]interface{}, len(objects))
+	for _, obj := range objects {
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			// Use the JSON representation to get a stable key for arguments
+			v, err := json.Marshal(obj[k])
+			if err != nil {
+				v = []byte(fmt.Sprint(obj[k]))
+			}
+			parts = append(parts, string(v))
+		}
+		index[strings.Join(parts, "\x00")] = obj
+	}
+	return index
+}
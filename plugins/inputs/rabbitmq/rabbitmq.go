@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -51,13 +52,18 @@ type RabbitMQ struct {
 	FederationUpstreamInclude []string `toml:"federation_upstream_include"`
 	FederationUpstreamExclude []string `toml:"federation_upstream_exclude"`
 
+	RaftMetricsURL       string `toml:"raft_metrics_url"`
+	DefinitionsReference string `toml:"definitions_reference"`
+
 	Log telegraf.Logger `toml:"-"`
 
-	client            *http.Client
-	excludeEveryQueue bool
-	metricFilter      filter.Filter
-	queueFilter       filter.Filter
-	upstreamFilter    filter.Filter
+	client               *http.Client
+	excludeEveryQueue    bool
+	includeConnections   bool
+	referenceDefinitions definitions
+	metricFilter         filter.Filter
+	queueFilter          filter.Filter
+	upstreamFilter       filter.Filter
 }
 
 type overviewResponse struct {
@@ -128,6 +134,14 @@ type queue struct {
 	SlaveNodes             []string `json:"slave_nodes"`
 	SynchronisedSlaveNodes []string `json:"synchronised_slave_nodes"`
 	HeadMessageTimestamp   *int64   `json:"head_message_timestamp"`
+
+	// Quorum queues and streams
+	Type            string           `json:"type"`
+	Members         []string         `json:"members"`
+	Online          []string         `json:"online"`
+	OpenFiles       map[string]int64 `json:"open_files"`
+	Segments        *int64           `json:"segments"`
+	CommittedOffset *int64           `json:"committed_offset"`
 }
 
 type node struct {
@@ -241,6 +255,7 @@ type errorResponse struct {
 type gatherFunc func(r *RabbitMQ, acc telegraf.Accumulator)
 
 var gatherFunctions = map[string]gatherFunc{
+	"connection": gatherConnections,
 	"exchange":   gatherExchanges,
 	"federation": gatherFederationLinks,
 	"node":       gatherNodes,
@@ -275,6 +290,26 @@ func (r *RabbitMQ) Init() error {
 		return err
 	}
 
+	// Connections are only gathered if explicitly included due to the
+	// potentially large number of series
+	if len(r.MetricInclude) > 0 {
+		f, err := filter.Compile(r.MetricInclude)
+		if err != nil {
+			return err
+		}
+		r.includeConnections = f.Match("connection") && r.metricFilter.Match("connection")
+	}
+
+	if r.DefinitionsReference != "" {
+		buf, err := os.ReadFile(r.DefinitionsReference)
+		if err != nil {
+			return fmt.Errorf("reading definitions reference failed: %w", err)
+		}
+		if r.referenceDefinitions, err = parseDefinitions(buf); err != nil {
+			return fmt.Errorf("parsing definitions reference %q failed: %w", r.DefinitionsReference, err)
+		}
+	}
+
 	tlsCfg, err := r.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
@@ -295,7 +330,7 @@ func (r *RabbitMQ) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 	for name, f := range gatherFunctions {
 		// Query only metrics that are supported
-		if !r.metricFilter.Match(name) {
+		if !r.metricFilter.Match(name) || (name == "connection" && !r.includeConnections) {
 			continue
 		}
 		wg.Add(1)
@@ -304,6 +339,20 @@ func (r *RabbitMQ) Gather(acc telegraf.Accumulator) error {
 			gf(r, acc)
 		}(f)
 	}
+	if r.RaftMetricsURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gatherRaftMetrics(r, acc)
+		}()
+	}
+	if r.DefinitionsReference != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gatherDefinitionsDrift(r, acc)
+		}()
+	}
 	wg.Wait()
 
 	return nil
@@ -598,6 +647,27 @@ func gatherQueues(r *RabbitMQ, acc telegraf.Accumulator) {
 			fields["head_message_timestamp"] = *queue.HeadMessageTimestamp
 		}
 
+		// Replicated queue types report their members and storage state
+		switch queue.Type {
+		case "quorum":
+			var openSegments int64
+			for _, n := range queue.OpenFiles {
+				openSegments += n
+			}
+			fields["members"] = len(queue.Members)
+			fields["online_members"] = len(queue.Online)
+			fields["open_segments"] = openSegments
+		case "stream":
+			fields["members"] = len(queue.Members)
+			fields["online_members"] = len(queue.Online)
+			if queue.Segments != nil {
+				fields["segments"] = *queue.Segments
+			}
+			if queue.CommittedOffset != nil {
+				fields["committed_offset"] = *queue.CommittedOffset
+			}
+		}
+
 		acc.AddFields(
 			"rabbitmq_queue",
 			fields,
@@ -686,3 +686,209 @@ func TestRabbitMQMetricFilerts(t *testing.T) {
 		require.ElementsMatch(t, expected, acc.Errors)
 	}
 }
+
+func newTestServerSet3(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var jsonFilePath string
+
+		switch r.URL.Path {
+		case "/api/queues":
+			jsonFilePath = "testdata/set3/queues.json"
+		case "/api/connections":
+			jsonFilePath = "testdata/set3/connections.json"
+		case "/api/definitions":
+			jsonFilePath = "testdata/set3/definitions.json"
+		case "/metrics/detailed":
+			jsonFilePath = "testdata/set3/raft.txt"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := os.ReadFile(jsonFilePath)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Errorf("Could not read from data file %q: %v", jsonFilePath, err)
+			return
+		}
+
+		if _, err = w.Write(data); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestRabbitMQReplicatedQueues(t *testing.T) {
+	ts := newTestServerSet3(t)
+
+	plugin := &RabbitMQ{
+		URL:           ts.URL,
+		Log:           testutil.Logger{},
+		MetricInclude: []string{"queue"},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	fields := make(map[string]map[string]interface{})
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "rabbitmq_queue", m.Name())
+		name, _ := m.GetTag("queue")
+		fields[name] = m.Fields()
+	}
+	require.Len(t, fields, 2)
+
+	require.Equal(t, int64(3), fields["orders"]["members"])
+	require.Equal(t, int64(2), fields["orders"]["online_members"])
+	require.Equal(t, int64(3), fields["orders"]["open_segments"])
+	require.NotContains(t, fields["orders"], "segments")
+
+	require.Equal(t, int64(3), fields["events"]["members"])
+	require.Equal(t, int64(3), fields["events"]["online_members"])
+	require.Equal(t, int64(4), fields["events"]["segments"])
+	require.Equal(t, int64(999), fields["events"]["committed_offset"])
+	require.NotContains(t, fields["events"], "open_segments")
+}
+
+func TestRabbitMQConnections(t *testing.T) {
+	ts := newTestServerSet3(t)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("rabbitmq_connection",
+			map[string]string{
+				"url":        ts.URL,
+				"connection": "10.0.0.5:51234 -> 10.0.0.1:5672",
+				"vhost":      "/",
+				"user":       "app",
+				"node":       "rabbit@node1",
+			},
+			map[string]interface{}{
+				"state":       "running",
+				"protocol":    "AMQP 0-9-1",
+				"channels":    int64(3),
+				"channel_max": int64(2047),
+				"recv_bytes":  int64(12345),
+				"send_bytes":  int64(67890),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &RabbitMQ{
+		URL:           ts.URL,
+		Log:           testutil.Logger{},
+		MetricInclude: []string{"connection"},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Connections must not be gathered unless explicitly included
+	plugin = &RabbitMQ{
+		URL:           ts.URL,
+		Log:           testutil.Logger{},
+		MetricExclude: []string{"exchange", "federation", "node", "overview", "queue"},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc = &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestRabbitMQRaftMetrics(t *testing.T) {
+	ts := newTestServerSet3(t)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("rabbitmq_quorum_queue",
+			map[string]string{
+				"url":   ts.URL,
+				"vhost": "/",
+				"queue": "orders",
+			},
+			map[string]interface{}{
+				"term":               int64(4),
+				"commit_index":       int64(1520),
+				"last_written_index": int64(1522),
+				"commit_latency":     float64(0.0042),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &RabbitMQ{
+		URL:            ts.URL,
+		Log:            testutil.Logger{},
+		MetricInclude:  []string{"none"},
+		QueueExclude:   []string{"other"},
+		RaftMetricsURL: ts.URL + "/metrics/detailed?family=ra_metrics",
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRabbitMQDefinitionsDrift(t *testing.T) {
+	ts := newTestServerSet3(t)
+
+	plugin := &RabbitMQ{
+		URL:                  ts.URL,
+		Log:                  testutil.Logger{},
+		MetricInclude:        []string{"none"},
+		DefinitionsReference: "testdata/set3/reference.json",
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	// Only the queues are drifting, all other kinds are unchanged
+	var expected []telegraf.Metric
+	for kind := range definitionKeys {
+		fields := map[string]interface{}{
+			"drift":      false,
+			"missing":    int64(0),
+			"unexpected": int64(0),
+			"changed":    int64(0),
+		}
+		if kind == "queues" {
+			fields = map[string]interface{}{
+				"drift":      true,
+				"missing":    int64(1),
+				"unexpected": int64(1),
+				"changed":    int64(1),
+			}
+		}
+		expected = append(expected, testutil.MustMetric("rabbitmq_definitions",
+			map[string]string{"url": ts.URL, "kind": kind},
+			fields,
+			time.Unix(0, 0),
+		))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestRabbitMQDefinitionsReferenceInvalid(t *testing.T) {
+	plugin := &RabbitMQ{
+		Log:                  testutil.Logger{},
+		DefinitionsReference: "testdata/set3/raft.txt",
+	}
+	require.ErrorContains(t, plugin.Init(), "parsing definitions reference")
+}
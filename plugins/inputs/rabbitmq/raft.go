@@ -0,0 +1,128 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// raftFields maps the Raft metrics of the RabbitMQ Prometheus plugin, without
+// prefix, to the field names of the quorum queue metric
+var raftFields = map[string]string{
+	"raft_term_total":                   "term",
+	"raft_log_snapshot_index":           "snapshot_index",
+	"raft_log_last_applied_index":       "last_applied_index",
+	"raft_log_commit_index":             "commit_index",
+	"raft_log_last_written_index":       "last_written_index",
+	"raft_entry_commit_latency_seconds": "commit_latency",
+}
+
+type raftQueue struct {
+	vhost string
+	queue string
+}
+
+func gatherRaftMetrics(r *RabbitMQ, acc telegraf.Accumulator) {
+	queues, err := r.requestRaftMetrics()
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+
+	for q, fields := range queues {
+		if !r.queueFilter.Match(q.queue) {
+			continue
+		}
+		tags := map[string]string{
+			"url":   r.URL,
+			"vhost": q.vhost,
+			"queue": q.queue,
+		}
+		acc.AddFields("rabbitmq_quorum_queue", fields, tags)
+	}
+}
+
+// requestRaftMetrics queries the per-object Raft metrics of the quorum queues
+// from the Prometheus endpoint and groups them by queue
+func (r *RabbitMQ) requestRaftMetrics() (map[raftQueue]map[string]interface{}, error) {
+	r.Log.Debugf("Requesting %q...", r.RaftMetricsURL)
+	resp, err := r.client.Get(r.RaftMetricsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("getting %q failed: %v %v", r.RaftMetricsURL, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return parseRaftMetrics(resp.Body)
+}
+
+func parseRaftMetrics(in io.Reader) (map[raftQueue]map[string]interface{}, error) {
+	queues := make(map[raftQueue]map[string]interface{})
+	decoder := expfmt.NewDecoder(in, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for {
+		var mf dto.MetricFamily
+		if err := decoder.Decode(&mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding raft metrics failed: %w", err)
+		}
+
+		// The metrics are prefixed differently for the per-object and the
+		// detailed endpoint
+		name := strings.TrimPrefix(mf.GetName(), "rabbitmq_")
+		name = strings.TrimPrefix(name, "detailed_")
+		field, found := raftFields[name]
+		if !found {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			var q raftQueue
+			for _, l := range m.GetLabel() {
+				switch l.GetName() {
+				case "vhost":
+					q.vhost = l.GetValue()
+				case "queue":
+					q.queue = l.GetValue()
+				}
+			}
+			if q.queue == "" {
+				continue
+			}
+
+			var value float64
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			if _, found := queues[q]; !found {
+				queues[q] = make(map[string]interface{})
+			}
+			if field == "commit_latency" {
+				queues[q][field] = value
+			} else {
+				queues[q][field] = int64(value)
+			}
+		}
+	}
+
+	return queues, nil
+}
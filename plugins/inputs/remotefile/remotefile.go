@@ -0,0 +1,432 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package remotefile
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/walk"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// The rclone log output is global so route it through a shared logger
+// instead of the logger of an individual plugin instance
+var (
+	rcloneLog  = logger.New("inputs", "remotefile", "rclone")
+	rcloneOnce sync.Once
+)
+
+type RemoteFile struct {
+	Remote          config.Secret   `toml:"remote"`
+	Files           []string        `toml:"files"`
+	Recursive       bool            `toml:"recursive"`
+	AfterProcessing string          `toml:"after_processing"`
+	MoveTo          string          `toml:"move_to"`
+	FileTag         string          `toml:"file_tag"`
+	MaxFileSize     config.Size     `toml:"max_file_size"`
+	ContentEncoding string          `toml:"content_encoding"`
+	MaxDecompress   config.Size     `toml:"max_decompression_size"`
+	MaxUndelivered  int             `toml:"max_undelivered_files"`
+	Log             telegraf.Logger `toml:"-"`
+
+	parserFunc telegraf.ParserFunc
+	filter     filter.Filter
+	decoders   map[string]internal.ContentDecoder
+	root       fs.Fs
+	ctx        context.Context
+	cancel     context.CancelFunc
+	acc        telegraf.TrackingAccumulator
+	sem        chan struct{}
+	wg         sync.WaitGroup
+
+	// pending contains the files with metrics not yet delivered by the
+	// outputs, keyed by the tracking ID of the file's metric group
+	pending   map[telegraf.TrackingID]fs.Object
+	processed map[string]fileState
+	sync.Mutex
+}
+
+// fileState identifies a version of a processed file to avoid processing the
+// same content multiple times
+type fileState struct {
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+func (*RemoteFile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RemoteFile) Init() error {
+	if r.Remote.Empty() {
+		return errors.New("remote required")
+	}
+
+	// Set defaults
+	if len(r.Files) == 0 {
+		r.Files = []string{"*"}
+	}
+	if r.MaxUndelivered <= 0 {
+		r.MaxUndelivered = 100
+	}
+	switch r.AfterProcessing {
+	case "":
+		r.AfterProcessing = "keep"
+	case "keep", "delete":
+	case "move":
+		if r.MoveTo == "" {
+			return errors.New("'move_to' required for moving processed files")
+		}
+		r.MoveTo = strings.Trim(path.Clean(r.MoveTo), "/")
+	default:
+		return fmt.Errorf("invalid 'after_processing' value %q", r.AfterProcessing)
+	}
+
+	var err error
+	if r.filter, err = filter.Compile(r.Files, '/'); err != nil {
+		return fmt.Errorf("compiling file filter failed: %w", err)
+	}
+
+	// Setup the decoders, with automatic detection we need all of them
+	var options []internal.DecodingOption
+	if r.MaxDecompress > 0 {
+		options = append(options, internal.WithMaxDecompressionSize(int64(r.MaxDecompress)))
+	}
+	encodings := []string{r.ContentEncoding}
+	switch r.ContentEncoding {
+	case "":
+		r.ContentEncoding = "identity"
+		encodings = []string{"identity"}
+	case "auto":
+		encodings = []string{"identity", "gzip", "zlib", "zstd"}
+	}
+	r.decoders = make(map[string]internal.ContentDecoder, len(encodings))
+	for _, encoding := range encodings {
+		if r.decoders[encoding], err = internal.NewContentDecoder(encoding, options...); err != nil {
+			return fmt.Errorf("creating %q decoder failed: %w", encoding, err)
+		}
+	}
+
+	if r.processed == nil {
+		r.processed = make(map[string]fileState)
+	}
+
+	return nil
+}
+
+func (r *RemoteFile) SetParserFunc(fn telegraf.ParserFunc) {
+	r.parserFunc = fn
+}
+
+func (r *RemoteFile) GetState() interface{} {
+	r.Lock()
+	defer r.Unlock()
+
+	// Leave out files with undelivered metrics to read them again after a
+	// restart instead of losing their data
+	state := make(map[string]fileState, len(r.processed))
+	for k, v := range r.processed {
+		if !r.isPending(k) {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+func (r *RemoteFile) SetState(state interface{}) error {
+	processed, ok := state.(map[string]fileState)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", state)
+	}
+	if r.processed == nil {
+		r.processed = make(map[string]fileState, len(processed))
+	}
+	for k, v := range processed {
+		r.processed[k] = v
+	}
+	return nil
+}
+
+func (r *RemoteFile) Start(acc telegraf.Accumulator) error {
+	remoteRaw, err := r.Remote.Get()
+	if err != nil {
+		return fmt.Errorf("getting remote secret failed: %w", err)
+	}
+	remote := remoteRaw.String()
+	remoteRaw.Destroy()
+
+	// Construct the underlying filesystem config
+	parsed, err := fspath.Parse(remote)
+	if err != nil {
+		return fmt.Errorf("parsing remote failed: %w", err)
+	}
+	info, err := fs.Find(parsed.Name)
+	if err != nil {
+		return fmt.Errorf("cannot find remote type %q: %w", parsed.Name, err)
+	}
+
+	rcloneOnce.Do(func() {
+		fs.LogOutput = func(level fs.LogLevel, text string) {
+			rcloneLog.Tracef("[%s] %s", level.String(), text)
+		}
+	})
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.root, err = info.NewFs(r.ctx, parsed.Name, parsed.Path, fs.ConfigMap(info.Prefix, info.Options, parsed.Name, parsed.Config))
+	if err != nil {
+		r.cancel()
+		return fmt.Errorf("creating remote failed: %w", err)
+	}
+	r.Log.Debugf("Connected to %s", r.root.String())
+
+	// Files are only finished after the outputs wrote all metrics of the file
+	// to avoid losing data if writing fails or Telegraf is restarted
+	r.pending = make(map[telegraf.TrackingID]fs.Object)
+	r.sem = make(chan struct{}, r.MaxUndelivered)
+	r.acc = acc.WithTracking(r.MaxUndelivered)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case info := <-r.acc.Delivered():
+				r.onDelivery(info)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *RemoteFile) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+func (r *RemoteFile) Gather(acc telegraf.Accumulator) error {
+	objects, err := r.list()
+	if err != nil {
+		return fmt.Errorf("listing files failed: %w", err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	present := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		fn := obj.Remote()
+		present[fn] = true
+
+		current := fileState{Size: obj.Size(), Modified: obj.ModTime(r.ctx)}
+		if prev, found := r.processed[fn]; found && prev.Size == current.Size && prev.Modified.Equal(current.Modified) {
+			continue
+		}
+		// Changed files still waiting for delivery must not be read as the
+		// new content would be deleted or moved with the old one
+		if r.AfterProcessing != "keep" && r.isPending(fn) {
+			continue
+		}
+		if r.MaxFileSize > 0 && current.Size > int64(r.MaxFileSize) {
+			acc.AddError(fmt.Errorf("skipping file %q of size %d exceeding the maximum size", fn, current.Size))
+			continue
+		}
+
+		// Read errors might be temporary so retry in the next gather cycle
+		data, err := r.read(obj)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading file %q failed: %w", fn, err))
+			continue
+		}
+
+		// Parsing errors will persist until the file is changed so remember
+		// the file as processed
+		r.processed[fn] = current
+		metrics, err := r.parse(data)
+		if err != nil {
+			acc.AddError(fmt.Errorf("parsing file %q failed: %w", fn, err))
+			continue
+		}
+		if len(metrics) == 0 {
+			if err := r.finish(obj); err != nil {
+				acc.AddError(fmt.Errorf("finishing file %q failed: %w", fn, err))
+			}
+			continue
+		}
+		for _, m := range metrics {
+			if r.FileTag != "" {
+				m.AddTag(r.FileTag, path.Base(fn))
+			}
+		}
+
+		// Do not read more files if too many files are still waiting for
+		// delivery, the file is read again in the next gather cycle
+		select {
+		case r.sem <- struct{}{}:
+		default:
+			delete(r.processed, fn)
+			r.Log.Debugf("Maximum number of undelivered files reached, deferring %q", fn)
+			continue
+		}
+		id := r.acc.AddTrackingMetricGroup(metrics)
+		r.pending[id] = obj
+	}
+
+	// Forget about files that vanished from the remote
+	for fn := range r.processed {
+		if !present[fn] {
+			delete(r.processed, fn)
+		}
+	}
+
+	return nil
+}
+
+func (r *RemoteFile) isPending(fn string) bool {
+	for _, obj := range r.pending {
+		if obj.Remote() == fn {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RemoteFile) onDelivery(info telegraf.DeliveryInfo) {
+	r.Lock()
+	defer r.Unlock()
+
+	obj, found := r.pending[info.ID()]
+	if !found {
+		return
+	}
+	delete(r.pending, info.ID())
+	<-r.sem
+
+	fn := obj.Remote()
+	if !info.Delivered() {
+		// Forget about the file to read it again in the next gather cycle
+		r.Log.Debugf("Metrics of file %q were not delivered, retrying", fn)
+		delete(r.processed, fn)
+		return
+	}
+	if err := r.finish(obj); err != nil {
+		r.Log.Errorf("Finishing file %q failed: %v", fn, err)
+	}
+}
+
+func (r *RemoteFile) list() ([]fs.Object, error) {
+	depth := 1
+	if r.Recursive {
+		depth = -1
+	}
+
+	var objects []fs.Object
+	err := walk.Walk(r.ctx, r.root, "", true, depth, func(_ string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			obj, ok := entry.(fs.Object)
+			if !ok {
+				continue
+			}
+			fn := obj.Remote()
+			if r.AfterProcessing == "move" && strings.HasPrefix(fn, r.MoveTo+"/") {
+				continue
+			}
+			if r.filter.Match(fn) {
+				objects = append(objects, obj)
+			}
+		}
+		return nil
+	})
+	return objects, err
+}
+
+func (r *RemoteFile) read(obj fs.Object) ([]byte, error) {
+	reader, err := obj.Open(r.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := r.ContentEncoding
+	if encoding == "auto" {
+		encoding = encodingFromExtension(obj.Remote())
+	}
+	return r.decoders[encoding].Decode(data)
+}
+
+// encodingFromExtension determines the content encoding from the file name
+// extension, falling back to "identity" for unknown extensions
+func encodingFromExtension(fn string) string {
+	switch strings.ToLower(path.Ext(fn)) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".zz", ".zlib":
+		return "zlib"
+	case ".zst", ".zstd":
+		return "zstd"
+	}
+	return "identity"
+}
+
+func (r *RemoteFile) parse(data []byte) ([]telegraf.Metric, error) {
+	// Use a new parser for each file as some parsers (e.g. CSV) keep state
+	parser, err := r.parserFunc()
+	if err != nil {
+		return nil, fmt.Errorf("creating parser failed: %w", err)
+	}
+	return parser.Parse(data)
+}
+
+func (r *RemoteFile) finish(obj fs.Object) error {
+	fn := obj.Remote()
+	switch r.AfterProcessing {
+	case "delete":
+		if err := operations.DeleteFile(r.ctx, obj); err != nil {
+			return err
+		}
+	case "move":
+		if _, err := operations.Move(r.ctx, r.root, nil, path.Join(r.MoveTo, fn), obj); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	delete(r.processed, fn)
+	return nil
+}
+
+func init() {
+	inputs.Add("remotefile", func() telegraf.Input {
+		return &RemoteFile{}
+	})
+}
@@ -0,0 +1,407 @@
+package remotefile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RemoteFile
+		expected string
+	}{
+		{
+			name:     "no remote",
+			plugin:   &RemoteFile{},
+			expected: "remote required",
+		},
+		{
+			name: "invalid action",
+			plugin: &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:")),
+				AfterProcessing: "archive",
+			},
+			expected: `invalid 'after_processing' value "archive"`,
+		},
+		{
+			name: "move without target",
+			plugin: &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:")),
+				AfterProcessing: "move",
+			},
+			expected: "'move_to' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test,source=a value=1i 1719410485000000000\n")
+	writeFile(t, tmpdir, "b.lp", "test,source=b value=2i 1719410485000000000\n")
+	writeFile(t, tmpdir, "c.txt", "test,source=c value=3i 1719410485000000000\n")
+	writeFile(t, tmpdir, "sub/d.lp", "test,source=d value=4i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote:  config.NewSecret([]byte("local:" + tmpdir)),
+		Files:   []string{"*.lp"},
+		FileTag: "filename",
+		Log:     &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Only the matching files should be read
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"source": "a", "filename": "a.lp"},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(1719410485, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{"source": "b", "filename": "b.lp"},
+			map[string]interface{}{"value": int64(2)},
+			time.Unix(1719410485, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+	require.Empty(t, acc.Errors)
+
+	// Unchanged files should not be read again
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Changed files should be read again
+	writeFile(t, tmpdir, "a.lp", "test,source=a value=1i 1719410485000000000\ntest,source=a value=5i 1719410486000000000\n")
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
+func TestGatherRecursive(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test,source=a value=1i 1719410485000000000\n")
+	writeFile(t, tmpdir, "sub/b.lp", "test,source=b value=2i 1719410485000000000\n")
+	writeFile(t, tmpdir, "other/c.lp", "test,source=c value=3i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote:    config.NewSecret([]byte("local:" + tmpdir)),
+		Files:     []string{"*.lp", "sub/**"},
+		Recursive: true,
+		Log:       &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	sources := make([]string, 0, 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		source, _ := m.GetTag("source")
+		sources = append(sources, source)
+	}
+	require.ElementsMatch(t, []string{"a", "b"}, sources)
+}
+
+func TestAfterProcessing(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		existing []string
+		missing  []string
+	}{
+		{
+			name:     "keep",
+			action:   "keep",
+			existing: []string{"a.lp", "broken.lp"},
+		},
+		{
+			name:     "delete",
+			action:   "delete",
+			existing: []string{"broken.lp"},
+			missing:  []string{"a.lp"},
+		},
+		{
+			name:     "move",
+			action:   "move",
+			existing: []string{"broken.lp", "done/a.lp"},
+			missing:  []string{"a.lp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := t.TempDir()
+			writeFile(t, tmpdir, "a.lp", "test value=1i 1719410485000000000\n")
+			writeFile(t, tmpdir, "broken.lp", "this is no line protocol\n")
+
+			plugin := &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:" + tmpdir)),
+				Recursive:       true,
+				AfterProcessing: tt.action,
+				MoveTo:          "done",
+				Log:             &testutil.Logger{},
+			}
+			plugin.SetParserFunc(newParser)
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			require.NoError(t, plugin.Gather(&acc))
+			require.Len(t, acc.GetTelegrafMetrics(), 1)
+			require.Len(t, acc.Errors, 1)
+			require.ErrorContains(t, acc.Errors[0], `parsing file "broken.lp" failed`)
+
+			// Files must not be touched before the metrics are delivered
+			require.FileExists(t, filepath.Join(tmpdir, "a.lp"))
+			for _, m := range acc.GetTelegrafMetrics() {
+				m.Accept()
+			}
+			require.Eventually(t, func() bool {
+				plugin.Lock()
+				defer plugin.Unlock()
+				return len(plugin.pending) == 0
+			}, 3*time.Second, 100*time.Millisecond)
+
+			for _, fn := range tt.existing {
+				require.FileExists(t, filepath.Join(tmpdir, fn))
+			}
+			for _, fn := range tt.missing {
+				require.NoFileExists(t, filepath.Join(tmpdir, fn))
+			}
+
+			// Neither processed nor broken files should be read again
+			acc.ClearMetrics()
+			acc.Errors = nil
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.GetTelegrafMetrics())
+			require.Empty(t, acc.Errors)
+		})
+	}
+}
+
+func TestUndeliveredFileKept(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test value=1i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote:          config.NewSecret([]byte("local:" + tmpdir)),
+		AfterProcessing: "delete",
+		Log:             &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Files with metrics still waiting for delivery must not be read again
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// Rejected metrics should keep the file and read it again
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Reject()
+	}
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return len(plugin.pending) == 0
+	}, 3*time.Second, 100*time.Millisecond)
+	require.FileExists(t, filepath.Join(tmpdir, "a.lp"))
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestMaxFileSize(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test value=1i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote:      config.NewSecret([]byte("local:" + tmpdir)),
+		MaxFileSize: config.Size(10),
+		Log:         &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "exceeding the maximum size")
+}
+
+func TestContentEncoding(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test,source=a value=1i 1719410485000000000\n")
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("test,source=b value=2i 1719410485000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	writeFile(t, tmpdir, "b.lp.gz", buf.String())
+
+	plugin := &RemoteFile{
+		Remote:          config.NewSecret([]byte("local:" + tmpdir)),
+		ContentEncoding: "auto",
+		Log:             &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(1)}, time.Unix(1719410485, 0)),
+		metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": int64(2)}, time.Unix(1719410485, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestState(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test value=1i 1719410485000000000\n")
+	writeFile(t, tmpdir, "b.lp", "test value=2i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Files:  []string{"a.lp"},
+		Log:    &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return len(plugin.pending) == 0
+	}, 3*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+	state := plugin.GetState()
+
+	// Restart the plugin with the previous state and only the unprocessed file
+	// should be read
+	plugin = &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Log:    &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.SetState(state))
+	require.NoError(t, plugin.Init())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(1719410485, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestStateUndelivered(t *testing.T) {
+	tmpdir := t.TempDir()
+	writeFile(t, tmpdir, "a.lp", "test value=1i 1719410485000000000\n")
+
+	plugin := &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Log:    &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	// Stop the plugin without delivering the metrics
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	state := plugin.GetState()
+	require.Empty(t, state)
+
+	// Restart the plugin with the previous state and the file with the
+	// undelivered metrics should be read again
+	plugin = &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Log:    &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.SetState(state))
+	require.NoError(t, plugin.Init())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Unix(1719410485, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func newParser() (telegraf.Parser, error) {
+	parser := &influx.Parser{}
+	err := parser.Init()
+	return parser, err
+}
+
+func writeFile(t *testing.T, dir, fn, content string) {
+	t.Helper()
+
+	fn = filepath.Join(dir, filepath.FromSlash(fn))
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0750))
+	require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+}
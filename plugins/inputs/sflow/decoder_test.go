@@ -67,7 +67,7 @@ func TestIPv4SW(t *testing.T) {
 	actual := make([]telegraf.Metric, 0)
 	dc := newDecoder()
 	dc.onPacket(func(p *v5Format) {
-		metrics := makeMetrics(p)
+		metrics := makeMetrics(p, false, false)
 		actual = append(actual, metrics...)
 	})
 	buf := bytes.NewReader(packet)
@@ -191,7 +191,7 @@ func TestExpandFlow(t *testing.T) {
 	dc := newDecoder()
 	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
 	require.NoError(t, err)
-	actual := makeMetrics(p)
+	actual := makeMetrics(p, false, false)
 
 	expected := []telegraf.Metric{
 		testutil.MustMetric(
@@ -332,7 +332,7 @@ func TestIPv4SWRT(t *testing.T) {
 	dc := newDecoder()
 	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
 	require.NoError(t, err)
-	actual := makeMetrics(p)
+	actual := makeMetrics(p, false, false)
 
 	expected := []telegraf.Metric{
 		testutil.MustMetric(
@@ -559,7 +559,7 @@ func TestIPv6SW(t *testing.T) {
 	dc := newDecoder()
 	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
 	require.NoError(t, err)
-	actual := makeMetrics(p)
+	actual := makeMetrics(p, false, false)
 
 	expected := []telegraf.Metric{
 
@@ -630,7 +630,7 @@ func TestExpandFlowCounter(t *testing.T) {
 	dc := newDecoder()
 	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
 	require.NoError(t, err)
-	actual := makeMetrics(p)
+	actual := makeMetrics(p, false, false)
 
 	expected := []telegraf.Metric{
 		testutil.MustMetric(
@@ -832,9 +832,174 @@ func TestFlowExpandCounter(t *testing.T) {
 	dc := newDecoder()
 	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
 	require.NoError(t, err)
-	actual := makeMetrics(p)
+	actual := makeMetrics(p, false, false)
 
 	// we don't do anything with samples yet
 	expected := make([]telegraf.Metric, 0)
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 }
+
+func TestIPv6SWExtendedRecords(t *testing.T) {
+	packet, err := hex.DecodeString(
+		"00000005000000010ae0648100000002000093d824ac82340000000100000001000000d000019f94000001010000100019f9400000000000000001010" +
+			"0000000000000020000000100000090000000010000058c00000008000000800008e3fffc10d4f4be04612486dd60000000054e113a2607f8b040020" +
+			"0140000000000000008262000edc000e804a25e30c581af36fa01bbfa6f054e249810b584bcbf12926c2e29a779c26c72db483e8191524fe2288bfda" +
+			"ceaf9d2e724d04305706efcfdef70db86873bbacf29698affe4e7d6faa21d302f9b4b023291a05a000003e90000001000000001000000000000000100000000",
+	)
+	require.NoError(t, err)
+
+	dc := newDecoder()
+	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
+	require.NoError(t, err)
+	actual := makeMetrics(p, true, false)
+
+	expected := []telegraf.Metric{
+
+		testutil.MustMetric(
+			"sflow",
+			map[string]string{
+				"agent_address":    "10.224.100.129",
+				"dst_ip":           "2620:ed:c000:e804:a25e:30c5:81af:36fa",
+				"dst_mac":          "00:08:e3:ff:fc:10",
+				"dst_port":         "64111",
+				"dst_priority":     "0",
+				"dst_vlan":         "1",
+				"ether_type":       "IPv6",
+				"header_protocol":  "ETHERNET-ISO88023",
+				"input_ifindex":    "257",
+				"output_ifindex":   "0",
+				"sample_direction": "ingress",
+				"source_id_index":  "257",
+				"source_id_type":   "0",
+				"src_ip":           "2607:f8b0:4002:14::8",
+				"src_mac":          "d4:f4:be:04:61:24",
+				"src_port":         "443",
+				"src_priority":     "0",
+				"src_vlan":         "1",
+			},
+			map[string]interface{}{
+				"bytes":          uint64(0x58c000),
+				"drops":          uint64(0x00),
+				"frame_length":   uint64(0x058c),
+				"header_length":  uint64(0x80),
+				"sampling_rate":  uint64(0x1000),
+				"payload_length": uint64(0x054e),
+				"udp_length":     uint64(0x054e),
+				"ip_dscp":        "0",
+				"ip_ecn":         "0",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestFlowExpandCounterSamples(t *testing.T) {
+	packet, err := hex.DecodeString(
+		"00000005000000010a000150000000000006d14d8ae0fe200000000200000004000000ac00006d15000000004b00ca000000000200000002000000340" +
+			"000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000584" +
+			"b00ca0000000001000000000000000000000001000000010000308ae33bb950eb92a8a3004d0bb406899571000000000000000000000000000012f7e" +
+			"d9c9db8c24ed90604eaf0bd04636edb00000000000000000000000100000004000000ac00006d15000000004b0054000000000200000002000000340" +
+			"000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000584" +
+			"b00540000000001000000003b9aca000000000100000003000067ba8e64fd23fa65f26d0215ec4a0021086600000000000000000000000000002002c" +
+			"3b21045c2378ad3001fb2f300061872000000000000000000000001",
+	)
+	require.NoError(t, err)
+
+	dc := newDecoder()
+	p, err := dc.decodeOnePacket(bytes.NewBuffer(packet))
+	require.NoError(t, err)
+	actual := makeMetrics(p, false, true)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"sflow_counters",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_index":        "1258342912",
+				"if_type":         "1",
+				"source_id_index": "1258342912",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_stats_alignment_errors":             uint64(0),
+				"dot3_stats_carrier_sense_errors":         uint64(0),
+				"dot3_stats_deferred_transmissions":       uint64(0),
+				"dot3_stats_excessive_collisions":         uint64(0),
+				"dot3_stats_fcs_errors":                   uint64(0),
+				"dot3_stats_frame_too_longs":              uint64(0),
+				"dot3_stats_internal_mac_receive_errors":  uint64(0),
+				"dot3_stats_internal_mac_transmit_errors": uint64(0),
+				"dot3_stats_late_collisions":              uint64(0),
+				"dot3_stats_multiple_collision_frames":    uint64(0),
+				"dot3_stats_single_collision_frames":      uint64(0),
+				"dot3_stats_sqe_test_errors":              uint64(0),
+				"dot3_stats_symbol_errors":                uint64(0),
+				"if_admin_status":                         uint64(1),
+				"if_direction":                            uint64(1),
+				"if_in_broadcast_pkts":                    uint64(109679985),
+				"if_in_discards":                          uint64(0),
+				"if_in_errors":                            uint64(0),
+				"if_in_multicast_pkts":                    uint64(5049268),
+				"if_in_octets":                            uint64(53373075962192),
+				"if_in_ucast_pkts":                        uint64(3952257187),
+				"if_in_unknown_protos":                    uint64(0),
+				"if_oper_status":                          uint64(0),
+				"if_out_broadcast_pkts":                   uint64(73625307),
+				"if_out_discards":                         uint64(0),
+				"if_out_errors":                           uint64(0),
+				"if_out_multicast_pkts":                   uint64(82505917),
+				"if_out_octets":                           uint64(20856052686264),
+				"if_out_ucast_pkts":                       uint64(3259947270),
+				"if_promiscuous_mode":                     uint64(1),
+				"if_speed":                                uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"sflow_counters",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_index":        "1258312704",
+				"if_type":         "1",
+				"source_id_index": "1258312704",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_stats_alignment_errors":             uint64(0),
+				"dot3_stats_carrier_sense_errors":         uint64(0),
+				"dot3_stats_deferred_transmissions":       uint64(0),
+				"dot3_stats_excessive_collisions":         uint64(0),
+				"dot3_stats_fcs_errors":                   uint64(0),
+				"dot3_stats_frame_too_longs":              uint64(0),
+				"dot3_stats_internal_mac_receive_errors":  uint64(0),
+				"dot3_stats_internal_mac_transmit_errors": uint64(0),
+				"dot3_stats_late_collisions":              uint64(0),
+				"dot3_stats_multiple_collision_frames":    uint64(0),
+				"dot3_stats_single_collision_frames":      uint64(0),
+				"dot3_stats_sqe_test_errors":              uint64(0),
+				"dot3_stats_symbol_errors":                uint64(0),
+				"if_admin_status":                         uint64(1),
+				"if_direction":                            uint64(1),
+				"if_in_broadcast_pkts":                    uint64(2164838),
+				"if_in_discards":                          uint64(0),
+				"if_in_errors":                            uint64(0),
+				"if_in_multicast_pkts":                    uint64(34991178),
+				"if_in_octets":                            uint64(114050950561059),
+				"if_in_ucast_pkts":                        uint64(4200985197),
+				"if_in_unknown_protos":                    uint64(0),
+				"if_oper_status":                          uint64(1),
+				"if_out_broadcast_pkts":                   uint64(399474),
+				"if_out_discards":                         uint64(0),
+				"if_out_errors":                           uint64(0),
+				"if_out_multicast_pkts":                   uint64(2077427),
+				"if_out_octets":                           uint64(35196245250117),
+				"if_out_ucast_pkts":                       uint64(3258419923),
+				"if_promiscuous_mode":                     uint64(1),
+				"if_speed":                                uint64(1000000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
@@ -8,7 +8,11 @@ import (
 	"github.com/influxdata/telegraf/metric"
 )
 
-func makeMetrics(p *v5Format) []telegraf.Metric {
+// makeMetrics returns the metrics of the flow samples in the packet. The
+// extended records of flow samples are only added to the flow metrics if
+// extendedRecords is set, counter samples are only emitted if counterSamples
+// is set as both change the series produced for existing setups.
+func makeMetrics(p *v5Format, extendedRecords, counterSamples bool) []telegraf.Metric {
 	now := time.Now()
 	metrics := make([]telegraf.Metric, 0)
 	tags := map[string]string{
@@ -16,6 +20,17 @@ func makeMetrics(p *v5Format) []telegraf.Metric {
 	}
 	fields := make(map[string]interface{}, 2)
 	for _, sample := range p.samples {
+		switch sample.smplType {
+		case sampleTypeCounterSample, sampleTypeCounterSampleExpanded:
+			if !counterSamples {
+				continue
+			}
+			if m := makeCounterMetric(p, sample.counterData, now); m != nil {
+				metrics = append(metrics, m)
+			}
+			continue
+		}
+
 		tags["input_ifindex"] = strconv.FormatUint(uint64(sample.smplData.inputIfIndex), 10)
 		tags["output_ifindex"] = strconv.FormatUint(uint64(sample.smplData.outputIfIndex), 10)
 		tags["sample_direction"] = sample.smplData.sampleDirection
@@ -24,10 +39,31 @@ func makeMetrics(p *v5Format) []telegraf.Metric {
 		fields["drops"] = sample.smplData.drops
 		fields["sampling_rate"] = sample.smplData.samplingRate
 
+		// Extended records describe the sampled packet of the other records
+		extendedTags := make(map[string]string)
+		extendedFields := make(map[string]interface{})
+		for _, flowRecord := range sample.smplData.flowRecords {
+			if !extendedRecords || flowRecord.flowData == nil || !flowRecord.flowFormat.extended() {
+				continue
+			}
+			for k, v := range flowRecord.flowData.getTags() {
+				extendedTags[k] = v
+			}
+			for k, v := range flowRecord.flowData.getFields() {
+				extendedFields[k] = v
+			}
+		}
+
 		for _, flowRecord := range sample.smplData.flowRecords {
-			if flowRecord.flowData != nil {
+			if flowRecord.flowData != nil && !flowRecord.flowFormat.extended() {
 				tags2 := flowRecord.flowData.getTags()
 				fields2 := flowRecord.flowData.getFields()
+				for k, v := range extendedTags {
+					tags2[k] = v
+				}
+				for k, v := range extendedFields {
+					fields2[k] = v
+				}
 				for k, v := range tags {
 					tags2[k] = v
 				}
@@ -41,3 +77,29 @@ func makeMetrics(p *v5Format) []telegraf.Metric {
 	}
 	return metrics
 }
+
+// makeCounterMetric returns a metric containing the decoded counter records
+// of the sample or nil if the sample contains no supported records
+func makeCounterMetric(p *v5Format, sample sampleDataCounterSample, now time.Time) telegraf.Metric {
+	tags := map[string]string{
+		"agent_address":   p.agentAddress.String(),
+		"source_id_index": strconv.FormatUint(uint64(sample.sourceIDIndex), 10),
+		"source_id_type":  strconv.FormatUint(uint64(sample.sourceIDType), 10),
+	}
+	fields := make(map[string]interface{})
+	for _, record := range sample.counterRecords {
+		if record.counterData == nil {
+			continue
+		}
+		for k, v := range record.counterData.getTags() {
+			tags[k] = v
+		}
+		for k, v := range record.counterData.getFields() {
+			fields[k] = v
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return metric.New("sflow_counters", tags, fields, now)
+}
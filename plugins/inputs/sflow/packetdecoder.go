@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs/sflow/binaryio"
@@ -130,6 +131,10 @@ func (d *packetDecoder) decodeSample(r io.Reader) (sample, error) {
 		sam.smplData, err = d.decodeFlowSample(mr)
 	case sampleTypeFlowSampleExpanded:
 		sam.smplData, err = d.decodeFlowSampleExpanded(mr)
+	case sampleTypeCounterSample:
+		sam.counterData, err = d.decodeCounterSample(mr)
+	case sampleTypeCounterSampleExpanded:
+		sam.counterData, err = d.decodeCounterSampleExpanded(mr)
 	default:
 		d.debug("Unknown sample type: ", sam.smplType)
 	}
@@ -242,6 +247,14 @@ func (d *packetDecoder) decodeFlowRecords(r io.Reader, samplingRate uint32) (rec
 		switch fr.flowFormat {
 		case flowFormatTypeRawPacketHeader: // sflow_version_5.txt line 1938
 			fr.flowData, err = d.decodeRawPacketHeaderFlowData(mr, samplingRate)
+		case flowFormatTypeExtendedSwitch:
+			fr.flowData, err = decodeExtendedSwitchFlowData(mr)
+		case flowFormatTypeExtendedRouter:
+			fr.flowData, err = decodeExtendedRouterFlowData(mr)
+		case flowFormatTypeExtendedGateway:
+			fr.flowData, err = decodeExtendedGatewayFlowData(mr)
+		case flowFormatTypeExtendedMPLS:
+			fr.flowData, err = decodeExtendedMPLSFlowData(mr)
 		default:
 			d.debug("Unknown flow format: ", fr.flowFormat)
 		}
@@ -257,6 +270,84 @@ func (d *packetDecoder) decodeFlowRecords(r io.Reader, samplingRate uint32) (rec
 	return recs, err
 }
 
+func decodeExtendedSwitchFlowData(r io.Reader) (h extendedSwitchFlowData, err error) {
+	if err := read(r, &h.srcVlan, "SrcVlan"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.srcPriority, "SrcPriority"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.dstVlan, "DstVlan"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.dstPriority, "DstPriority"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func decodeExtendedRouterFlowData(r io.Reader) (h extendedRouterFlowData, err error) {
+	if h.nextHop, err = decodeAddress(r, "NextHop"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.srcMaskLen, "SrcMaskLen"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.dstMaskLen, "DstMaskLen"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func decodeExtendedGatewayFlowData(r io.Reader) (h extendedGatewayFlowData, err error) {
+	if h.nextHop, err = decodeAddress(r, "NextHop"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.as, "AS"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.srcAS, "SrcAS"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.srcPeerAS, "SrcPeerAS"); err != nil {
+		return h, err
+	}
+	var count uint32
+	if err := read(r, &count, "DstASPath count"); err != nil {
+		return h, err
+	}
+	for i := uint32(0); i < count; i++ {
+		var segment asPathSegment
+		if err := read(r, &segment.segmentType, "ASPathSegmentType"); err != nil {
+			return h, err
+		}
+		if segment.asNumbers, err = decodeUint32Array(r, "ASPathSegment"); err != nil {
+			return h, err
+		}
+		h.dstASPath = append(h.dstASPath, segment)
+	}
+	if h.communities, err = decodeUint32Array(r, "Communities"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.localPref, "LocalPref"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func decodeExtendedMPLSFlowData(r io.Reader) (h extendedMPLSFlowData, err error) {
+	if h.nextHop, err = decodeAddress(r, "NextHop"); err != nil {
+		return h, err
+	}
+	if h.inStack, err = decodeUint32Array(r, "InStack"); err != nil {
+		return h, err
+	}
+	if h.outStack, err = decodeUint32Array(r, "OutStack"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
 func (d *packetDecoder) decodeRawPacketHeaderFlowData(r io.Reader, samplingRate uint32) (h rawPacketHeaderFlowData, err error) {
 	if err := read(r, &h.headerProtocol, "HeaderProtocol"); err != nil { // sflow_version_5.txt line 1940
 		return h, err
@@ -471,6 +562,175 @@ func decodeUDPHeader(r io.Reader) (h udpHeader, err error) {
 	return h, err
 }
 
+func (d *packetDecoder) decodeCounterSample(r io.Reader) (t sampleDataCounterSample, err error) {
+	if err := read(r, &t.sequenceNumber, "SequenceNumber"); err != nil {
+		return t, err
+	}
+	var sourceID uint32
+	if err := read(r, &sourceID, "SourceID"); err != nil {
+		return t, err
+	}
+	t.sourceIDIndex = sourceID & 0x00ffffff
+	t.sourceIDType = sourceID >> 24
+
+	t.counterRecords, err = d.decodeCounterRecords(r)
+	return t, err
+}
+
+func (d *packetDecoder) decodeCounterSampleExpanded(r io.Reader) (t sampleDataCounterSample, err error) {
+	if err := read(r, &t.sequenceNumber, "SequenceNumber"); err != nil {
+		return t, err
+	}
+	if err := read(r, &t.sourceIDType, "SourceIDType"); err != nil {
+		return t, err
+	}
+	if err := read(r, &t.sourceIDIndex, "SourceIDIndex"); err != nil {
+		return t, err
+	}
+
+	t.counterRecords, err = d.decodeCounterRecords(r)
+	return t, err
+}
+
+func (d *packetDecoder) decodeCounterRecords(r io.Reader) (recs []counterRecord, err error) {
+	var count uint32
+	if err := read(r, &count, "CounterRecord count"); err != nil {
+		return recs, err
+	}
+	for i := uint32(0); i < count; i++ {
+		cr := counterRecord{}
+		if err := read(r, &cr.counterFormat, "CounterFormat"); err != nil {
+			return recs, err
+		}
+		var counterDataLen uint32
+		if err := read(r, &counterDataLen, "Counter data length"); err != nil {
+			return recs, err
+		}
+
+		mr := binaryio.MinReader(r, int64(counterDataLen))
+
+		switch cr.counterFormat {
+		case counterFormatTypeGenericInterface:
+			cr.counterData, err = decodeInterfaceCounters(mr)
+		case counterFormatTypeEthernet:
+			cr.counterData, err = decodeEthernetCounters(mr)
+		default:
+			d.debug("Unknown counter format: ", cr.counterFormat)
+		}
+		if err != nil {
+			mr.Close()
+			return recs, err
+		}
+
+		recs = append(recs, cr)
+		mr.Close()
+	}
+
+	return recs, nil
+}
+
+func decodeInterfaceCounters(r io.Reader) (c interfaceCounters, err error) {
+	values := []struct {
+		data interface{}
+		name string
+	}{
+		{&c.ifIndex, "IfIndex"},
+		{&c.ifType, "IfType"},
+		{&c.ifSpeed, "IfSpeed"},
+		{&c.ifDirection, "IfDirection"},
+		{&c.ifStatus, "IfStatus"},
+		{&c.ifInOctets, "IfInOctets"},
+		{&c.ifInUcastPkts, "IfInUcastPkts"},
+		{&c.ifInMulticastPkts, "IfInMulticastPkts"},
+		{&c.ifInBroadcastPkts, "IfInBroadcastPkts"},
+		{&c.ifInDiscards, "IfInDiscards"},
+		{&c.ifInErrors, "IfInErrors"},
+		{&c.ifInUnknownProtos, "IfInUnknownProtos"},
+		{&c.ifOutOctets, "IfOutOctets"},
+		{&c.ifOutUcastPkts, "IfOutUcastPkts"},
+		{&c.ifOutMulticastPkts, "IfOutMulticastPkts"},
+		{&c.ifOutBroadcastPkts, "IfOutBroadcastPkts"},
+		{&c.ifOutDiscards, "IfOutDiscards"},
+		{&c.ifOutErrors, "IfOutErrors"},
+		{&c.ifPromiscuousMode, "IfPromiscuousMode"},
+	}
+	for _, v := range values {
+		if err := read(r, v.data, v.name); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func decodeEthernetCounters(r io.Reader) (c ethernetCounters, err error) {
+	values := []struct {
+		data interface{}
+		name string
+	}{
+		{&c.alignmentErrors, "Dot3StatsAlignmentErrors"},
+		{&c.fcsErrors, "Dot3StatsFCSErrors"},
+		{&c.singleCollisionFrames, "Dot3StatsSingleCollisionFrames"},
+		{&c.multipleCollisionFrames, "Dot3StatsMultipleCollisionFrames"},
+		{&c.sqeTestErrors, "Dot3StatsSQETestErrors"},
+		{&c.deferredTransmissions, "Dot3StatsDeferredTransmissions"},
+		{&c.lateCollisions, "Dot3StatsLateCollisions"},
+		{&c.excessiveCollisions, "Dot3StatsExcessiveCollisions"},
+		{&c.internalMacTransmitErrors, "Dot3StatsInternalMacTransmitErrors"},
+		{&c.carrierSenseErrors, "Dot3StatsCarrierSenseErrors"},
+		{&c.frameTooLongs, "Dot3StatsFrameTooLongs"},
+		{&c.internalMacReceiveErrors, "Dot3StatsInternalMacReceiveErrors"},
+		{&c.symbolErrors, "Dot3StatsSymbolErrors"},
+	}
+	for _, v := range values {
+		if err := read(r, v.data, v.name); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+// decodeAddress decodes an address prefixed by its type
+func decodeAddress(r io.Reader, name string) (net.IP, error) {
+	var typ addressType
+	if err := read(r, &typ, name+" type"); err != nil {
+		return nil, err
+	}
+	var ip net.IP
+	switch typ {
+	case addressTypeUnknown:
+		return net.IP{}, nil
+	case addressTypeIPV4:
+		ip = make(net.IP, 4)
+	case addressTypeIPV6:
+		ip = make(net.IP, 16)
+	default:
+		return nil, fmt.Errorf("unknown address IP type %d", typ)
+	}
+	if err := read(r, []byte(ip), name); err != nil {
+		return nil, err
+	}
+	return ip, nil
+}
+
+// decodeUint32Array decodes a variable-length array of unsigned integers
+func decodeUint32Array(r io.Reader, name string) ([]uint32, error) {
+	var count uint32
+	if err := read(r, &count, name+" count"); err != nil {
+		return nil, err
+	}
+	// The count is not used for preallocation to not allocate arbitrary
+	// amounts of memory for invalid packets
+	var values []uint32
+	for i := uint32(0); i < count; i++ {
+		var v uint32
+		if err := read(r, &v, name); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
 func read(r io.Reader, data interface{}, name string) error {
 	err := binary.Read(r, binary.BigEndian, data)
 	if err != nil {
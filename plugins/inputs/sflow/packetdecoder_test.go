@@ -2,6 +2,7 @@ package sflow
 
 import (
 	"bytes"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -203,3 +204,85 @@ func TestUnknownProtocol(t *testing.T) {
 
 	require.Equal(t, expected, actual)
 }
+
+func TestExtendedGatewayFlowData(t *testing.T) {
+	octets := bytes.NewBuffer([]byte{
+		0x00, 0x00, 0x00, 0x01, // next hop address type ipv4
+		0xc0, 0xa8, 0x01, 0x01, // next hop
+		0x00, 0x00, 0xfd, 0xe8, // as 65000
+		0x00, 0x00, 0xfd, 0xe9, // src_as 65001
+		0x00, 0x00, 0xfd, 0xea, // src_peer_as 65002
+		0x00, 0x00, 0x00, 0x02, // dst_as_path segment count
+		0x00, 0x00, 0x00, 0x02, // segment type sequence
+		0x00, 0x00, 0x00, 0x02, // as count
+		0x00, 0x00, 0x0d, 0x1c, // as 3356
+		0x00, 0x00, 0x0b, 0x62, // as 2914
+		0x00, 0x00, 0x00, 0x01, // segment type set
+		0x00, 0x00, 0x00, 0x02, // as count
+		0x00, 0x00, 0x3a, 0x98, // as 15000
+		0x00, 0x00, 0x3a, 0x99, // as 15001
+		0x00, 0x00, 0x00, 0x01, // community count
+		0xfd, 0xe8, 0x00, 0x64, // community 65000:100
+		0x00, 0x00, 0x00, 0x64, // localpref
+	})
+
+	actual, err := decodeExtendedGatewayFlowData(octets)
+	require.NoError(t, err)
+
+	expected := extendedGatewayFlowData{
+		nextHop:   net.IP{192, 168, 1, 1},
+		as:        65000,
+		srcAS:     65001,
+		srcPeerAS: 65002,
+		dstASPath: []asPathSegment{
+			{segmentType: asPathSegmentTypeSequence, asNumbers: []uint32{3356, 2914}},
+			{segmentType: asPathSegmentTypeSet, asNumbers: []uint32{15000, 15001}},
+		},
+		communities: []uint32{0xfde80064},
+		localPref:   100,
+	}
+	require.Equal(t, expected, actual)
+
+	require.Equal(t, map[string]string{
+		"bgp_next_hop": "192.168.1.1",
+		"src_as":       "65001",
+		"src_peer_as":  "65002",
+		"dst_peer_as":  "3356",
+		"dst_as":       "15001",
+	}, actual.getTags())
+	require.Equal(t, map[string]interface{}{
+		"as":              uint32(65000),
+		"as_path":         "3356 2914 {15000,15001}",
+		"bgp_communities": "65000:100",
+		"local_pref":      uint32(100),
+	}, actual.getFields())
+
+	// Truncated record
+	_, err = decodeExtendedGatewayFlowData(bytes.NewBuffer([]byte{0x00, 0x00, 0x00, 0x01, 0xc0, 0xa8}))
+	require.Error(t, err)
+}
+
+func TestExtendedMPLSFlowData(t *testing.T) {
+	octets := bytes.NewBuffer([]byte{
+		0x00, 0x00, 0x00, 0x02, // next hop address type ipv6
+		0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // next hop
+		0x00, 0x00, 0x00, 0x02, // in stack count
+		0x00, 0x06, 0x40, 0x40, // label 100, ttl 64
+		0x00, 0x0c, 0x81, 0x40, // label 200, bottom of stack, ttl 64
+		0x00, 0x00, 0x00, 0x00, // out stack count
+	})
+
+	actual, err := decodeExtendedMPLSFlowData(octets)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"mpls_next_hop": "2001:db8::1"}, actual.getTags())
+	require.Equal(t, map[string]interface{}{
+		"mpls_in_labels":  "100,200",
+		"mpls_out_labels": "",
+	}, actual.getFields())
+}
+
+func TestDecodeAddressInvalid(t *testing.T) {
+	_, err := decodeAddress(bytes.NewBuffer([]byte{0x00, 0x00, 0x00, 0x03}), "NextHop")
+	require.ErrorContains(t, err, "unknown address IP type 3")
+}
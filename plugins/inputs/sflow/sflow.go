@@ -14,6 +14,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -25,14 +26,17 @@ const (
 
 type SFlow struct {
 	ServiceAddress string      `toml:"service_address"`
-	ReadBufferSize config.Size `toml:"read_buffer_size"`
+	ReadBufferSize  config.Size `toml:"read_buffer_size"`
+	ExtendedRecords bool        `toml:"extended_records"`
+	CounterSamples  bool        `toml:"counter_samples"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	addr    net.Addr
-	decoder *packetDecoder
-	closer  io.Closer
-	wg      sync.WaitGroup
+	addr         net.Addr
+	decoder      *packetDecoder
+	closer       io.Closer
+	wg           sync.WaitGroup
+	decodeErrors map[string]selfstat.Stat // Decoding errors per agent address
 }
 
 func (*SFlow) SampleConfig() string {
@@ -42,13 +46,14 @@ func (*SFlow) SampleConfig() string {
 func (s *SFlow) Init() error {
 	s.decoder = newDecoder()
 	s.decoder.Log = s.Log
+	s.decodeErrors = make(map[string]selfstat.Stat)
 	return nil
 }
 
 // Start starts this sFlow listener listening on the configured network for sFlow packets
 func (s *SFlow) Start(acc telegraf.Accumulator) error {
 	s.decoder.onPacket(func(p *v5Format) {
-		metrics := makeMetrics(p)
+		metrics := makeMetrics(p, s.ExtendedRecords, s.CounterSamples)
 		for _, m := range metrics {
 			acc.AddMetric(m)
 		}
@@ -102,21 +107,44 @@ func (s *SFlow) address() net.Addr {
 func (s *SFlow) read(acc telegraf.Accumulator, conn net.PacketConn) {
 	buf := make([]byte, maxPacketSize)
 	for {
-		n, _, err := conn.ReadFrom(buf)
+		n, addr, err := conn.ReadFrom(buf)
 		if err != nil {
 			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
 				acc.AddError(err)
 			}
 			break
 		}
-		s.process(acc, buf[:n])
+		s.process(acc, buf[:n], addr)
 	}
 }
 
-func (s *SFlow) process(acc telegraf.Accumulator, buf []byte) {
+func (s *SFlow) process(acc telegraf.Accumulator, buf []byte, src net.Addr) {
 	if err := s.decoder.decode(bytes.NewBuffer(buf)); err != nil {
-		acc.AddError(fmt.Errorf("unable to parse incoming packet: %w", err))
+		// The agent address of the packet might not be decodable so use the
+		// sender's address to identify the agent
+		agent := src.String()
+		if udpAddr, ok := src.(*net.UDPAddr); ok {
+			agent = udpAddr.IP.String()
+		}
+		s.decodeErrorsStat(agent).Incr(1)
+		acc.AddError(fmt.Errorf("unable to parse incoming packet from %s: %w", agent, err))
+	}
+}
+
+// decodeErrorsStat returns the decoding error statistic of the given agent
+// and registers the statistic on the first error of the agent
+func (s *SFlow) decodeErrorsStat(agent string) selfstat.Stat {
+	if stat, found := s.decodeErrors[agent]; found {
+		return stat
+	}
+
+	tags := map[string]string{
+		"address":       s.ServiceAddress,
+		"agent_address": agent,
 	}
+	stat := selfstat.Register("sflow", "decode_errors", tags)
+	s.decodeErrors[agent] = stat
+	return stat
 }
 
 func listenUDP(network, address string) (*net.UDPConn, error) {
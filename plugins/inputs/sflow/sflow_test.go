@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -119,6 +120,41 @@ func TestSFlow(t *testing.T) {
 		testutil.IgnoreTime())
 }
 
+func TestDecodeErrors(t *testing.T) {
+	sflow := &SFlow{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, sflow.Init())
+
+	tags := map[string]string{
+		"address":       "udp://127.0.0.1:0",
+		"agent_address": "192.168.1.2",
+	}
+	otherTags := map[string]string{
+		"address":       "udp://127.0.0.1:0",
+		"agent_address": "192.168.1.3",
+	}
+	defer selfstat.Unregister("sflow", "decode_errors", tags)
+	defer selfstat.Unregister("sflow", "decode_errors", otherTags)
+
+	// Unsupported version and truncated packet
+	src := &net.UDPAddr{IP: net.ParseIP("192.168.1.2"), Port: 6343}
+	other := &net.UDPAddr{IP: net.ParseIP("192.168.1.3"), Port: 6343}
+	var acc testutil.Accumulator
+	sflow.process(&acc, []byte{0x00, 0x00, 0x00, 0x04}, src)
+	sflow.process(&acc, []byte{0x00, 0x00, 0x00, 0x05, 0x00, 0x00}, src)
+	sflow.process(&acc, []byte{0x00, 0x00, 0x00, 0x04}, other)
+	require.Len(t, acc.Errors, 3)
+	require.ErrorContains(t, acc.Errors[0], "unable to parse incoming packet from 192.168.1.2")
+	require.ErrorContains(t, acc.Errors[2], "unable to parse incoming packet from 192.168.1.3")
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// The errors are counted per agent
+	require.Equal(t, int64(2), selfstat.Register("sflow", "decode_errors", tags).Get())
+	require.Equal(t, int64(1), selfstat.Register("sflow", "decode_errors", otherTags).Get())
+}
+
 func BenchmarkSFlow(b *testing.B) {
 	sflow := &SFlow{
 		ServiceAddress: "udp://127.0.0.1:0",
@@ -3,6 +3,7 @@ package sflow
 import (
 	"net"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -34,13 +35,16 @@ type v5Format struct {
 type sampleType uint32
 
 const (
-	sampleTypeFlowSample         sampleType = 1 // sflow_version_5.txt line: 1614
-	sampleTypeFlowSampleExpanded sampleType = 3 // sflow_version_5.txt line: 1698
+	sampleTypeFlowSample            sampleType = 1 // sflow_version_5.txt line: 1614
+	sampleTypeCounterSample         sampleType = 2 // sflow_version_5.txt: counters_sample
+	sampleTypeFlowSampleExpanded    sampleType = 3 // sflow_version_5.txt line: 1698
+	sampleTypeCounterSampleExpanded sampleType = 4 // sflow_version_5.txt: counters_sample_expanded
 )
 
 type sample struct {
-	smplType sampleType
-	smplData sampleDataFlowSampleExpanded
+	smplType    sampleType
+	smplData    sampleDataFlowSampleExpanded
+	counterData sampleDataCounterSample
 }
 
 type sampleDataFlowSampleExpanded struct {
@@ -58,12 +62,29 @@ type sampleDataFlowSampleExpanded struct {
 	flowRecords     []flowRecord
 }
 
+type sampleDataCounterSample struct {
+	sequenceNumber uint32
+	sourceIDType   uint32
+	sourceIDIndex  uint32
+	counterRecords []counterRecord
+}
+
 type flowFormatType uint32
 
 const (
-	flowFormatTypeRawPacketHeader flowFormatType = 1 // sflow_version_5.txt line: 1938
+	flowFormatTypeRawPacketHeader flowFormatType = 1    // sflow_version_5.txt line: 1938
+	flowFormatTypeExtendedSwitch  flowFormatType = 1001 // sflow_version_5.txt: extended_switch
+	flowFormatTypeExtendedRouter  flowFormatType = 1002 // sflow_version_5.txt: extended_router
+	flowFormatTypeExtendedGateway flowFormatType = 1003 // sflow_version_5.txt: extended_gateway
+	flowFormatTypeExtendedMPLS    flowFormatType = 1006 // sflow_version_5.txt: extended_mpls
 )
 
+// extended returns true for records providing additional information on the
+// sampled packet of the flow sample
+func (f flowFormatType) extended() bool {
+	return f >= flowFormatTypeExtendedSwitch
+}
+
 type flowData containsMetricData
 
 type flowRecord struct {
@@ -295,3 +316,248 @@ func (h udpHeader) getFields() map[string]interface{} {
 		"udp_length": h.udpLength,
 	}
 }
+
+// extendedSwitchFlowData contains the extended_switch structure of sflow_version_5.txt
+type extendedSwitchFlowData struct {
+	srcVlan     uint32
+	srcPriority uint32
+	dstVlan     uint32
+	dstPriority uint32
+}
+
+func (h extendedSwitchFlowData) getTags() map[string]string {
+	return map[string]string{
+		"src_vlan":     strconv.FormatUint(uint64(h.srcVlan), 10),
+		"src_priority": strconv.FormatUint(uint64(h.srcPriority), 10),
+		"dst_vlan":     strconv.FormatUint(uint64(h.dstVlan), 10),
+		"dst_priority": strconv.FormatUint(uint64(h.dstPriority), 10),
+	}
+}
+
+func (extendedSwitchFlowData) getFields() map[string]interface{} {
+	return make(map[string]interface{})
+}
+
+// extendedRouterFlowData contains the extended_router structure of sflow_version_5.txt
+type extendedRouterFlowData struct {
+	nextHop    net.IP
+	srcMaskLen uint32
+	dstMaskLen uint32
+}
+
+func (h extendedRouterFlowData) getTags() map[string]string {
+	return map[string]string{
+		"next_hop":     h.nextHop.String(),
+		"src_mask_len": strconv.FormatUint(uint64(h.srcMaskLen), 10),
+		"dst_mask_len": strconv.FormatUint(uint64(h.dstMaskLen), 10),
+	}
+}
+
+func (extendedRouterFlowData) getFields() map[string]interface{} {
+	return make(map[string]interface{})
+}
+
+type asPathSegmentType uint32
+
+const (
+	asPathSegmentTypeSet      asPathSegmentType = 1
+	asPathSegmentTypeSequence asPathSegmentType = 2
+)
+
+type asPathSegment struct {
+	segmentType asPathSegmentType
+	asNumbers   []uint32
+}
+
+// extendedGatewayFlowData contains the extended_gateway structure of sflow_version_5.txt
+type extendedGatewayFlowData struct {
+	nextHop     net.IP
+	as          uint32
+	srcAS       uint32
+	srcPeerAS   uint32
+	dstASPath   []asPathSegment
+	communities []uint32
+	localPref   uint32
+}
+
+func (h extendedGatewayFlowData) getTags() map[string]string {
+	t := map[string]string{
+		"bgp_next_hop": h.nextHop.String(),
+		"src_as":       strconv.FormatUint(uint64(h.srcAS), 10),
+		"src_peer_as":  strconv.FormatUint(uint64(h.srcPeerAS), 10),
+	}
+
+	// The peer is the first and the destination the last AS of the path
+	var path []uint32
+	for _, segment := range h.dstASPath {
+		path = append(path, segment.asNumbers...)
+	}
+	if len(path) > 0 {
+		t["dst_peer_as"] = strconv.FormatUint(uint64(path[0]), 10)
+		t["dst_as"] = strconv.FormatUint(uint64(path[len(path)-1]), 10)
+	}
+	return t
+}
+
+func (h extendedGatewayFlowData) getFields() map[string]interface{} {
+	segments := make([]string, 0, len(h.dstASPath))
+	for _, segment := range h.dstASPath {
+		numbers := make([]string, 0, len(segment.asNumbers))
+		for _, n := range segment.asNumbers {
+			numbers = append(numbers, strconv.FormatUint(uint64(n), 10))
+		}
+		if segment.segmentType == asPathSegmentTypeSet {
+			segments = append(segments, "{"+strings.Join(numbers, ",")+"}")
+		} else {
+			segments = append(segments, strings.Join(numbers, " "))
+		}
+	}
+
+	communities := make([]string, 0, len(h.communities))
+	for _, c := range h.communities {
+		communities = append(communities, strconv.FormatUint(uint64(c>>16), 10)+":"+strconv.FormatUint(uint64(c&0xFFFF), 10))
+	}
+
+	return map[string]interface{}{
+		"as":              h.as,
+		"as_path":         strings.Join(segments, " "),
+		"bgp_communities": strings.Join(communities, " "),
+		"local_pref":      h.localPref,
+	}
+}
+
+// extendedMPLSFlowData contains the extended_mpls structure of sflow_version_5.txt
+type extendedMPLSFlowData struct {
+	nextHop  net.IP
+	inStack  []uint32
+	outStack []uint32
+}
+
+func (h extendedMPLSFlowData) getTags() map[string]string {
+	return map[string]string{
+		"mpls_next_hop": h.nextHop.String(),
+	}
+}
+
+func (h extendedMPLSFlowData) getFields() map[string]interface{} {
+	return map[string]interface{}{
+		"mpls_in_labels":  mplsLabels(h.inStack),
+		"mpls_out_labels": mplsLabels(h.outStack),
+	}
+}
+
+// mplsLabels returns the labels of the label stack entries in the stack
+// order, the traffic class, bottom of stack flag and TTL are ignored
+func mplsLabels(stack []uint32) string {
+	labels := make([]string, 0, len(stack))
+	for _, entry := range stack {
+		labels = append(labels, strconv.FormatUint(uint64(entry>>12), 10))
+	}
+	return strings.Join(labels, ",")
+}
+
+type counterFormatType uint32
+
+const (
+	counterFormatTypeGenericInterface counterFormatType = 1 // sflow_version_5.txt: if_counters
+	counterFormatTypeEthernet         counterFormatType = 2 // sflow_version_5.txt: ethernet_counters
+)
+
+type counterData containsMetricData
+
+type counterRecord struct {
+	counterFormat counterFormatType
+	counterData   counterData
+}
+
+// interfaceCounters contains the if_counters structure of sflow_version_5.txt
+type interfaceCounters struct {
+	ifIndex            uint32
+	ifType             uint32
+	ifSpeed            uint64
+	ifDirection        uint32
+	ifStatus           uint32
+	ifInOctets         uint64
+	ifInUcastPkts      uint32
+	ifInMulticastPkts  uint32
+	ifInBroadcastPkts  uint32
+	ifInDiscards       uint32
+	ifInErrors         uint32
+	ifInUnknownProtos  uint32
+	ifOutOctets        uint64
+	ifOutUcastPkts     uint32
+	ifOutMulticastPkts uint32
+	ifOutBroadcastPkts uint32
+	ifOutDiscards      uint32
+	ifOutErrors        uint32
+	ifPromiscuousMode  uint32
+}
+
+func (c interfaceCounters) getTags() map[string]string {
+	return map[string]string{
+		"if_index": strconv.FormatUint(uint64(c.ifIndex), 10),
+		"if_type":  strconv.FormatUint(uint64(c.ifType), 10),
+	}
+}
+
+func (c interfaceCounters) getFields() map[string]interface{} {
+	return map[string]interface{}{
+		"if_speed":              c.ifSpeed,
+		"if_direction":          c.ifDirection,
+		"if_admin_status":       c.ifStatus & 0x1,
+		"if_oper_status":        (c.ifStatus >> 1) & 0x1,
+		"if_in_octets":          c.ifInOctets,
+		"if_in_ucast_pkts":      c.ifInUcastPkts,
+		"if_in_multicast_pkts":  c.ifInMulticastPkts,
+		"if_in_broadcast_pkts":  c.ifInBroadcastPkts,
+		"if_in_discards":        c.ifInDiscards,
+		"if_in_errors":          c.ifInErrors,
+		"if_in_unknown_protos":  c.ifInUnknownProtos,
+		"if_out_octets":         c.ifOutOctets,
+		"if_out_ucast_pkts":     c.ifOutUcastPkts,
+		"if_out_multicast_pkts": c.ifOutMulticastPkts,
+		"if_out_broadcast_pkts": c.ifOutBroadcastPkts,
+		"if_out_discards":       c.ifOutDiscards,
+		"if_out_errors":         c.ifOutErrors,
+		"if_promiscuous_mode":   c.ifPromiscuousMode,
+	}
+}
+
+// ethernetCounters contains the ethernet_counters structure of sflow_version_5.txt
+type ethernetCounters struct {
+	alignmentErrors           uint32
+	fcsErrors                 uint32
+	singleCollisionFrames     uint32
+	multipleCollisionFrames   uint32
+	sqeTestErrors             uint32
+	deferredTransmissions     uint32
+	lateCollisions            uint32
+	excessiveCollisions       uint32
+	internalMacTransmitErrors uint32
+	carrierSenseErrors        uint32
+	frameTooLongs             uint32
+	internalMacReceiveErrors  uint32
+	symbolErrors              uint32
+}
+
+func (ethernetCounters) getTags() map[string]string {
+	return make(map[string]string)
+}
+
+func (c ethernetCounters) getFields() map[string]interface{} {
+	return map[string]interface{}{
+		"dot3_stats_alignment_errors":             c.alignmentErrors,
+		"dot3_stats_fcs_errors":                   c.fcsErrors,
+		"dot3_stats_single_collision_frames":      c.singleCollisionFrames,
+		"dot3_stats_multiple_collision_frames":    c.multipleCollisionFrames,
+		"dot3_stats_sqe_test_errors":              c.sqeTestErrors,
+		"dot3_stats_deferred_transmissions":       c.deferredTransmissions,
+		"dot3_stats_late_collisions":              c.lateCollisions,
+		"dot3_stats_excessive_collisions":         c.excessiveCollisions,
+		"dot3_stats_internal_mac_transmit_errors": c.internalMacTransmitErrors,
+		"dot3_stats_carrier_sense_errors":         c.carrierSenseErrors,
+		"dot3_stats_frame_too_longs":              c.frameTooLongs,
+		"dot3_stats_internal_mac_receive_errors":  c.internalMacReceiveErrors,
+		"dot3_stats_symbol_errors":                c.symbolErrors,
+	}
+}
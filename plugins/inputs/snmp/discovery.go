@@ -0,0 +1,493 @@
+package snmp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	probing "github.com/prometheus-community/pro-bing"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+const (
+	sysObjectIDOid = ".1.3.6.1.2.1.1.2.0"
+	sysNameOid     = ".1.3.6.1.2.1.1.5.0"
+)
+
+// Discovery defines the networks periodically swept for SNMP agents
+type Discovery struct {
+	Networks      []string        `toml:"networks"`
+	Interval      config.Duration `toml:"interval"`
+	Method        string          `toml:"method"`
+	Port          uint16          `toml:"port"`
+	Timeout       config.Duration `toml:"timeout"`
+	Concurrency   int             `toml:"concurrency"`
+	MaxHosts      int             `toml:"max_hosts"`
+	DeviceTTL     config.Duration `toml:"device_ttl"`
+	InventoryFile string          `toml:"inventory_file"`
+	Profiles      []Profile       `toml:"profile"`
+
+	hosts []netip.Addr
+}
+
+// Profile defines the fields and tables queried from discovered devices
+// with a matching sysObjectID
+type Profile struct {
+	Name         string       `toml:"name"`
+	SysObjectIDs []string     `toml:"sys_object_ids"`
+	Fields       []snmp.Field `toml:"field"`
+	Tables       []snmp.Table `toml:"table"`
+
+	filter filter.Filter
+}
+
+// device is a discovered SNMP agent as stored in the inventory
+type device struct {
+	Address     string    `json:"address"`
+	SysObjectID string    `json:"sys_object_id"`
+	SysName     string    `json:"sys_name,omitempty"`
+	Profile     string    `json:"profile,omitempty"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+
+	profile *Profile
+}
+
+// prober queries the identity of the device at the given address
+type prober func(ctx context.Context, address string) (*device, error)
+
+func (d *Discovery) init(tr snmp.Translator) error {
+	if len(d.Networks) == 0 {
+		return errors.New("no networks to discover")
+	}
+	if d.Interval <= 0 {
+		d.Interval = config.Duration(time.Hour)
+	}
+	switch d.Method {
+	case "":
+		d.Method = "snmp"
+	case "snmp", "icmp":
+	default:
+		return fmt.Errorf("invalid discovery method %q", d.Method)
+	}
+	if d.Port == 0 {
+		d.Port = 161
+	}
+	if d.Timeout <= 0 {
+		d.Timeout = config.Duration(time.Second)
+	}
+	if d.Concurrency < 1 {
+		d.Concurrency = 32
+	}
+	if d.MaxHosts < 1 {
+		d.MaxHosts = 4096
+	}
+
+	hosts, err := expandNetworks(d.Networks, d.MaxHosts)
+	if err != nil {
+		return err
+	}
+	d.hosts = hosts
+
+	for i := range d.Profiles {
+		p := &d.Profiles[i]
+		if p.Name == "" {
+			return fmt.Errorf("profile %d has no name", i+1)
+		}
+		if len(p.SysObjectIDs) == 0 {
+			return fmt.Errorf("profile %q has no sysObjectID", p.Name)
+		}
+		patterns := make([]string, 0, len(p.SysObjectIDs))
+		for _, oid := range p.SysObjectIDs {
+			patterns = append(patterns, normalizeOID(oid))
+		}
+		if p.filter, err = filter.Compile(patterns); err != nil {
+			return fmt.Errorf("creating sysObjectID filter of profile %q failed: %w", p.Name, err)
+		}
+		for j := range p.Fields {
+			if err := p.Fields[j].Init(tr); err != nil {
+				return fmt.Errorf("initializing field %s of profile %q: %w", p.Fields[j].Name, p.Name, err)
+			}
+		}
+		for j := range p.Tables {
+			if err := p.Tables[j].Init(tr); err != nil {
+				return fmt.Errorf("initializing table %s of profile %q: %w", p.Tables[j].Name, p.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// exclude removes the hosts of the given agents from the hosts to probe to
+// avoid querying the same device twice
+func (d *Discovery) exclude(agents []string) {
+	static := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		if !strings.Contains(agent, "://") {
+			agent = "udp://" + agent
+		}
+		if u, err := url.Parse(agent); err == nil {
+			static[u.Hostname()] = true
+		}
+	}
+	d.hosts = slices.DeleteFunc(d.hosts, func(addr netip.Addr) bool {
+		return static[addr.String()]
+	})
+}
+
+// match returns the first profile matching the sysObjectID
+func (d *Discovery) match(sysObjectID string) *Profile {
+	oid := normalizeOID(sysObjectID)
+	for i := range d.Profiles {
+		if d.Profiles[i].filter.Match(oid) {
+			return &d.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// expandNetworks returns all host addresses of the given networks in CIDR
+// notation, excluding the network and broadcast address of IPv4 networks
+func expandNetworks(networks []string, limit int) ([]netip.Addr, error) {
+	var hosts []netip.Addr
+	for _, n := range networks {
+		prefix, err := netip.ParsePrefix(n)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(n)
+			if aerr != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", n, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefix = prefix.Masked()
+
+		skipEdges := prefix.Addr().Is4() && prefix.Bits() < 31
+		for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+			if skipEdges && (addr == prefix.Addr() || !prefix.Contains(addr.Next())) {
+				continue
+			}
+			if len(hosts) >= limit {
+				return nil, fmt.Errorf("networks contain more than %d hosts", limit)
+			}
+			hosts = append(hosts, addr)
+		}
+	}
+	return hosts, nil
+}
+
+// normalizeOID ensures a leading dot for numeric OIDs
+func normalizeOID(oid string) string {
+	if strings.HasPrefix(oid, ".") {
+		return oid
+	}
+	return "." + oid
+}
+
+// startDiscovery loads the persisted inventory and periodically sweeps the
+// networks in the background
+func (s *Snmp) startDiscovery() error {
+	if s.Discovery.InventoryFile != "" {
+		devices, err := loadInventory(s.Discovery.InventoryFile)
+		if err != nil {
+			return err
+		}
+		for _, dev := range devices {
+			dev.profile = s.Discovery.match(dev.SysObjectID)
+			s.discovered[dev.Address] = dev
+		}
+		s.Log.Debugf("Loaded %d devices from inventory", len(devices))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(s.Discovery.Interval))
+		defer ticker.Stop()
+		for {
+			s.sweep(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *Snmp) stopDiscovery() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// sweep probes all hosts of the configured networks and updates the inventory
+func (s *Snmp) sweep(ctx context.Context) {
+	start := time.Now()
+
+	var mu sync.Mutex
+	var found []*device
+
+	hosts := make(chan netip.Addr)
+	var wg sync.WaitGroup
+	for range s.Discovery.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				address := net.JoinHostPort(host.String(), strconv.Itoa(int(s.Discovery.Port)))
+				dev, err := s.probe(ctx, address)
+				if err != nil {
+					s.Log.Tracef("Probing %s failed: %v", address, err)
+					continue
+				}
+				mu.Lock()
+				found = append(found, dev)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, host := range s.Discovery.hosts {
+		if ctx.Err() != nil {
+			break
+		}
+		hosts <- host
+	}
+	close(hosts)
+	wg.Wait()
+
+	// Do not touch the inventory with incomplete results
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.updateInventory(found, start)
+	s.Log.Debugf("Discovered %d devices in %s", len(found), time.Since(start))
+
+	if s.Discovery.InventoryFile != "" {
+		if err := saveInventory(s.Discovery.InventoryFile, s.discoveredDevices()); err != nil {
+			s.Log.Errorf("Saving inventory failed: %v", err)
+		}
+	}
+}
+
+// updateInventory merges the found devices into the inventory and removes
+// devices not seen within the device TTL
+func (s *Snmp) updateInventory(found []*device, now time.Time) {
+	s.discoveryMu.Lock()
+	defer s.discoveryMu.Unlock()
+
+	for _, dev := range found {
+		dev.LastSeen = now
+		dev.FirstSeen = now
+		if existing, ok := s.discovered[dev.Address]; ok {
+			dev.FirstSeen = existing.FirstSeen
+		}
+		if dev.profile = s.Discovery.match(dev.SysObjectID); dev.profile != nil {
+			dev.Profile = dev.profile.Name
+		} else {
+			dev.Profile = ""
+		}
+		if _, ok := s.discovered[dev.Address]; !ok {
+			s.Log.Infof("Discovered device %s (%s) with profile %q", dev.Address, dev.SysObjectID, dev.Profile)
+		}
+		s.discovered[dev.Address] = dev
+	}
+
+	if s.Discovery.DeviceTTL <= 0 {
+		return
+	}
+	threshold := now.Add(-time.Duration(s.Discovery.DeviceTTL))
+	for address, dev := range s.discovered {
+		if dev.LastSeen.Before(threshold) {
+			s.Log.Infof("Removing device %s not seen since %s", address, dev.LastSeen)
+			delete(s.discovered, address)
+			delete(s.discoveredConns, address)
+		}
+	}
+}
+
+// discoveredDevices returns the devices of the inventory sorted by address
+func (s *Snmp) discoveredDevices() []*device {
+	s.discoveryMu.Lock()
+	defer s.discoveryMu.Unlock()
+
+	devices := make([]*device, 0, len(s.discovered))
+	for _, dev := range s.discovered {
+		devices = append(devices, dev)
+	}
+	slices.SortFunc(devices, func(a, b *device) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return devices
+}
+
+// getDiscoveredConnection returns the cached connection for the discovered
+// device or creates a new one
+func (s *Snmp) getDiscoveredConnection(address string) (snmp.Connection, error) {
+	s.discoveryMu.Lock()
+	gs, found := s.discoveredConns[address]
+	s.discoveryMu.Unlock()
+	if found {
+		if err := gs.Reconnect(); err != nil {
+			return gs, fmt.Errorf("reconnecting: %w", err)
+		}
+		return gs, nil
+	}
+
+	wrapper, err := snmp.NewWrapper(s.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := wrapper.SetAgent(address); err != nil {
+		return nil, err
+	}
+
+	s.discoveryMu.Lock()
+	s.discoveredConns[address] = wrapper
+	s.discoveryMu.Unlock()
+
+	if err := wrapper.Connect(); err != nil {
+		return nil, fmt.Errorf("setting up connection: %w", err)
+	}
+	return wrapper, nil
+}
+
+// probeAgent queries the sysObjectID and sysName of the agent, optionally
+// checking if the host responds to ICMP echo requests first
+func (s *Snmp) probeAgent(ctx context.Context, address string) (*device, error) {
+	if s.Discovery.Method == "icmp" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if err := ping(ctx, host, time.Duration(s.Discovery.Timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := s.ClientConfig
+	cfg.Timeout = s.Discovery.Timeout
+	cfg.Retries = 0
+	gs, err := snmp.NewWrapper(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := gs.SetAgent(address); err != nil {
+		return nil, err
+	}
+	if err := gs.Connect(); err != nil {
+		return nil, err
+	}
+	defer gs.Conn.Close()
+
+	packet, err := gs.Get([]string{sysObjectIDOid, sysNameOid})
+	if err != nil {
+		return nil, err
+	}
+	return parseIdentity(address, packet)
+}
+
+// parseIdentity extracts the device identity from the response to the
+// sysObjectID and sysName request
+func parseIdentity(address string, packet *gosnmp.SnmpPacket) (*device, error) {
+	dev := &device{Address: address}
+	for _, v := range packet.Variables {
+		switch normalizeOID(v.Name) {
+		case sysObjectIDOid:
+			if oid, ok := v.Value.(string); ok {
+				dev.SysObjectID = normalizeOID(oid)
+			}
+		case sysNameOid:
+			switch name := v.Value.(type) {
+			case string:
+				dev.SysName = name
+			case []byte:
+				dev.SysName = string(name)
+			}
+		}
+	}
+	if dev.SysObjectID == "" {
+		return nil, errors.New("no sysObjectID in response")
+	}
+	return dev, nil
+}
+
+func ping(ctx context.Context, host string, timeout time.Duration) error {
+	pinger, err := probing.NewPinger(host)
+	if err != nil {
+		return err
+	}
+	pinger.SetPrivileged(true)
+	pinger.Count = 1
+	pinger.Timeout = timeout
+	if err := pinger.RunWithContext(ctx); err != nil {
+		return err
+	}
+	if pinger.Statistics().PacketsRecv == 0 {
+		return errors.New("no ICMP echo reply")
+	}
+	return nil
+}
+
+func loadInventory(path string) ([]*device, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading inventory failed: %w", err)
+	}
+
+	var devices []*device
+	if err := json.Unmarshal(buf, &devices); err != nil {
+		return nil, fmt.Errorf("decoding inventory %q failed: %w", path, err)
+	}
+	return devices, nil
+}
+
+// saveInventory writes the devices to a temporary file first to not corrupt
+// the inventory if writing fails
+func saveInventory(path string, devices []*device) error {
+	buf, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,260 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestExpandNetworks(t *testing.T) {
+	hosts, err := expandNetworks([]string{"10.0.0.0/30", "10.0.1.5", "10.0.2.0/31", "fd00::/127"}, 10)
+	require.NoError(t, err)
+
+	expected := []netip.Addr{
+		netip.MustParseAddr("10.0.0.1"),
+		netip.MustParseAddr("10.0.0.2"),
+		netip.MustParseAddr("10.0.1.5"),
+		netip.MustParseAddr("10.0.2.0"),
+		netip.MustParseAddr("10.0.2.1"),
+		netip.MustParseAddr("fd00::"),
+		netip.MustParseAddr("fd00::1"),
+	}
+	require.Equal(t, expected, hosts)
+
+	_, err = expandNetworks([]string{"10.0.0.0/24"}, 100)
+	require.ErrorContains(t, err, "networks contain more than 100 hosts")
+
+	_, err = expandNetworks([]string{"10.0.0.0/33"}, 100)
+	require.ErrorContains(t, err, `invalid network "10.0.0.0/33"`)
+}
+
+func TestDiscoveryInitFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery *Discovery
+		expected  string
+	}{
+		{
+			name:      "no networks",
+			discovery: &Discovery{},
+			expected:  "no networks to discover",
+		},
+		{
+			name:      "invalid method",
+			discovery: &Discovery{Networks: []string{"10.0.0.0/30"}, Method: "arp"},
+			expected:  `invalid discovery method "arp"`,
+		},
+		{
+			name: "profile without name",
+			discovery: &Discovery{
+				Networks: []string{"10.0.0.0/30"},
+				Profiles: []Profile{{SysObjectIDs: []string{".1.3.6.1.4.1.9.*"}}},
+			},
+			expected: "profile 1 has no name",
+		},
+		{
+			name: "profile without sysObjectID",
+			discovery: &Discovery{
+				Networks: []string{"10.0.0.0/30"},
+				Profiles: []Profile{{Name: "cisco"}},
+			},
+			expected: `profile "cisco" has no sysObjectID`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Snmp{
+				Discovery:    tt.discovery,
+				ClientConfig: snmp.ClientConfig{Translator: "netsnmp"},
+				Log:          testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDiscoveryExcludesStaticAgents(t *testing.T) {
+	plugin := &Snmp{
+		Agents:       []string{"udp://10.0.0.1:161", "10.0.0.2"},
+		Discovery:    &Discovery{Networks: []string{"10.0.0.0/29"}},
+		ClientConfig: snmp.ClientConfig{Translator: "netsnmp"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []netip.Addr{
+		netip.MustParseAddr("10.0.0.3"),
+		netip.MustParseAddr("10.0.0.4"),
+		netip.MustParseAddr("10.0.0.5"),
+		netip.MustParseAddr("10.0.0.6"),
+	}
+	require.Equal(t, expected, plugin.Discovery.hosts)
+}
+
+func TestParseIdentity(t *testing.T) {
+	packet := &gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1208"},
+			{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("switch01")},
+		},
+	}
+	dev, err := parseIdentity("10.0.0.1:161", packet)
+	require.NoError(t, err)
+	require.Equal(t, &device{Address: "10.0.0.1:161", SysObjectID: ".1.3.6.1.4.1.9.1.1208", SysName: "switch01"}, dev)
+
+	packet = &gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.NoSuchObject},
+		},
+	}
+	_, err = parseIdentity("10.0.0.1:161", packet)
+	require.ErrorContains(t, err, "no sysObjectID in response")
+}
+
+func TestDiscoverySweep(t *testing.T) {
+	inventory := filepath.Join(t.TempDir(), "inventory.json")
+
+	responses := map[string]*device{
+		"10.0.0.1:161": {Address: "10.0.0.1:161", SysObjectID: ".1.3.6.1.4.1.9.1.1208", SysName: "switch01"},
+		"10.0.0.2:161": {Address: "10.0.0.2:161", SysObjectID: ".1.3.6.1.4.1.8072.3.2.10"},
+	}
+	probe := func(_ context.Context, address string) (*device, error) {
+		dev, found := responses[address]
+		if !found {
+			return nil, errors.New("timeout")
+		}
+		// Return a copy as the inventory takes ownership of the device
+		d := *dev
+		return &d, nil
+	}
+
+	plugin := &Snmp{
+		Discovery: &Discovery{
+			Networks:      []string{"10.0.0.0/29"},
+			DeviceTTL:     config.Duration(time.Hour),
+			InventoryFile: inventory,
+			Profiles: []Profile{
+				{Name: "cisco", SysObjectIDs: []string{"1.3.6.1.4.1.9.*"}},
+				{Name: "linux", SysObjectIDs: []string{".1.3.6.1.4.1.8072.3.2.10"}},
+			},
+		},
+		ClientConfig: snmp.ClientConfig{Translator: "netsnmp"},
+		Log:          testutil.Logger{},
+		probe:        probe,
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.sweep(t.Context())
+	devices := plugin.discoveredDevices()
+	require.Len(t, devices, 2)
+	require.Equal(t, "10.0.0.1:161", devices[0].Address)
+	require.Equal(t, "cisco", devices[0].Profile)
+	require.Equal(t, "switch01", devices[0].SysName)
+	require.Equal(t, "10.0.0.2:161", devices[1].Address)
+	require.Equal(t, "linux", devices[1].Profile)
+	firstSeen := devices[0].FirstSeen
+
+	// Devices keep their first appearance and vanished devices are kept
+	// until the TTL expired
+	delete(responses, "10.0.0.2:161")
+	plugin.sweep(t.Context())
+	devices = plugin.discoveredDevices()
+	require.Len(t, devices, 2)
+	require.Equal(t, firstSeen, devices[0].FirstSeen)
+
+	plugin.updateInventory(nil, time.Now().Add(2*time.Hour))
+	require.Empty(t, plugin.discoveredDevices())
+
+	// The inventory is restored on startup
+	restored := &Snmp{
+		Discovery: &Discovery{
+			Networks:      []string{"10.0.0.0/29"},
+			Interval:      config.Duration(time.Hour),
+			InventoryFile: inventory,
+			Profiles: []Profile{
+				{Name: "cisco", SysObjectIDs: []string{"1.3.6.1.4.1.9.*"}},
+			},
+		},
+		ClientConfig: snmp.ClientConfig{Translator: "netsnmp"},
+		Log:          testutil.Logger{},
+		probe: func(context.Context, string) (*device, error) {
+			return nil, errors.New("timeout")
+		},
+	}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.Start(nil))
+	defer restored.Stop()
+
+	devices = restored.discoveredDevices()
+	require.Len(t, devices, 2)
+	require.Equal(t, "10.0.0.1:161", devices[0].Address)
+	require.Same(t, &restored.Discovery.Profiles[0], devices[0].profile)
+	require.Nil(t, devices[1].profile)
+}
+
+func TestGatherDiscovered(t *testing.T) {
+	plugin := &Snmp{
+		Name: "snmp",
+		Fields: []snmp.Field{
+			{Name: "myfield1", Oid: ".1.0.0.1.1", IsTag: true},
+		},
+		Discovery: &Discovery{
+			Networks: []string{"10.0.0.0/30"},
+			Profiles: []Profile{
+				{
+					Name:         "test",
+					SysObjectIDs: []string{".1.3.6.1.4.1.8072.*"},
+					Fields: []snmp.Field{
+						{Name: "myfield2", Oid: ".1.0.0.1.2"},
+					},
+					Tables: []snmp.Table{
+						{
+							Name: "myOtherTable",
+							Fields: []snmp.Field{
+								{Name: "myOtherField", Oid: ".1.0.0.0.1.5"},
+							},
+						},
+					},
+				},
+			},
+		},
+		ClientConfig: snmp.ClientConfig{Translator: "netsnmp"},
+		Log:          testutil.Logger{},
+		probe: func(_ context.Context, address string) (*device, error) {
+			if address != "10.0.0.1:161" {
+				return nil, errors.New("timeout")
+			}
+			return &device{Address: address, SysObjectID: ".1.3.6.1.4.1.8072.3.2.10"}, nil
+		},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.sweep(t.Context())
+
+	// Use the test connection for the discovered device
+	plugin.discoveredConns["10.0.0.1:161"] = tsc
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 2)
+
+	m := acc.Metrics[0]
+	require.Equal(t, "snmp", m.Measurement)
+	require.Equal(t, "tsc", m.Tags["agent_host"])
+	require.Equal(t, "baz", m.Tags["myfield1"])
+	require.Equal(t, map[string]interface{}{"myfield2": 234}, m.Fields)
+
+	m = acc.Metrics[1]
+	require.Equal(t, "myOtherTable", m.Measurement)
+	require.Equal(t, map[string]interface{}{"myOtherField": 123456}, m.Fields)
+}
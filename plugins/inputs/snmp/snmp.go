@@ -2,9 +2,11 @@
 package snmp
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -35,11 +37,22 @@ type Snmp struct {
 	Name   string       `toml:"name"`
 	Fields []snmp.Field `toml:"field"`
 
+	// Discovery of agents in the given networks
+	Discovery *Discovery `toml:"discovery"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	connectionCache []snmp.Connection
 
 	translator snmp.Translator
+
+	// State of the discovery
+	probe           prober
+	discovered      map[string]*device
+	discoveredConns map[string]snmp.Connection
+	discoveryMu     sync.Mutex
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
 }
 
 func (*Snmp) SampleConfig() string {
@@ -78,6 +91,18 @@ func (s *Snmp) Init() error {
 		}
 	}
 
+	if s.Discovery != nil {
+		if err := s.Discovery.init(s.translator); err != nil {
+			return fmt.Errorf("initializing discovery: %w", err)
+		}
+		s.Discovery.exclude(s.Agents)
+		s.discovered = make(map[string]*device)
+		s.discoveredConns = make(map[string]snmp.Connection)
+		if s.probe == nil {
+			s.probe = s.probeAgent
+		}
+	}
+
 	if len(s.AgentHostTag) == 0 {
 		s.AgentHostTag = "agent_host"
 	}
@@ -91,6 +116,13 @@ func (s *Snmp) Init() error {
 	return nil
 }
 
+func (s *Snmp) Start(telegraf.Accumulator) error {
+	if s.Discovery == nil {
+		return nil
+	}
+	return s.startDiscovery()
+}
+
 func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 	for i, agent := range s.Agents {
@@ -102,30 +134,60 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
 				return
 			}
+			s.gatherAgent(acc, gs, agent, s.Fields, s.Tables)
+		}(i, agent)
+	}
 
-			// First is the top-level fields. We treat the fields as table prefixes with an empty index.
-			t := snmp.Table{
-				Name:   s.Name,
-				Fields: s.Fields,
-			}
-			topTags := make(map[string]string)
-			if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
-				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
-			}
+	// Discovered devices are queried with the fields and tables of their
+	// profile in addition to the global ones
+	if s.Discovery != nil {
+		for _, dev := range s.discoveredDevices() {
+			wg.Add(1)
+			go func(dev *device) {
+				defer wg.Done()
+				gs, err := s.getDiscoveredConnection(dev.Address)
+				if err != nil {
+					acc.AddError(fmt.Errorf("agent %s: %w", dev.Address, err))
+					return
+				}
 
-			// Now is the real tables.
-			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
-					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
+				fields, tables := s.Fields, s.Tables
+				if dev.profile != nil {
+					fields = slices.Concat(s.Fields, dev.profile.Fields)
+					tables = slices.Concat(s.Tables, dev.profile.Tables)
 				}
-			}
-		}(i, agent)
+				s.gatherAgent(acc, gs, dev.Address, fields, tables)
+			}(dev)
+		}
 	}
 	wg.Wait()
 
 	return nil
 }
 
+func (s *Snmp) Stop() {
+	s.stopDiscovery()
+}
+
+func (s *Snmp) gatherAgent(acc telegraf.Accumulator, gs snmp.Connection, agent string, fields []snmp.Field, tables []snmp.Table) {
+	// First is the top-level fields. We treat the fields as table prefixes with an empty index.
+	t := snmp.Table{
+		Name:   s.Name,
+		Fields: fields,
+	}
+	topTags := make(map[string]string)
+	if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+	}
+
+	// Now is the real tables.
+	for _, t := range tables {
+		if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+			acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
+		}
+	}
+}
+
 func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.Table, topTags map[string]string, walk bool) error {
 	rt, err := t.Build(gs, walk)
 	if err != nil {
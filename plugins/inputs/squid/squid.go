@@ -0,0 +1,331 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package squid
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Mapping of the cache manager pages to the collection options
+var pages = map[string]string{
+	"counters": "counters",
+	"info":     "info",
+	"peers":    "server_list",
+}
+
+// Mapping of the keys in the 'info' page to field names, keys with a
+// "5min: x%, 60min: y%" value produce a field for each interval
+var infoFields = map[string]string{
+	"Number of clients accessing cache":            "clients",
+	"Number of HTTP requests received":             "http_requests",
+	"Request failure ratio":                        "request_failure_ratio",
+	"Average HTTP requests per minute since start": "http_requests_per_minute",
+	"Hits as % of all requests":                    "request_hit_ratio",
+	"Hits as % of bytes sent":                      "byte_hit_ratio",
+	"Memory hits as % of hit requests":             "memory_hit_ratio",
+	"Disk hits as % of hit requests":               "disk_hit_ratio",
+	"Storage Swap size":                            "disk_cache_size_kb",
+	"Storage Swap capacity":                        "disk_cache_used_percent",
+	"Storage Mem size":                             "memory_cache_size_kb",
+	"Storage Mem capacity":                         "memory_cache_used_percent",
+	"Mean Object Size":                             "mean_object_size_kb",
+	"UP Time":                                      "uptime",
+	"CPU Usage":                                    "cpu_usage_percent",
+	"Maximum Resident Size":                        "max_resident_size_kb",
+	"Total accounted":                              "memory_accounted_kb",
+	"Maximum number of file descriptors":           "file_descriptors_max",
+	"Number of file desc currently in use":         "file_descriptors_used",
+	"Available number of file descriptors":         "file_descriptors_available",
+}
+
+// Mapping of the keys in the 'server_list' page to field names
+var peerFields = map[string]string{
+	"FETCHES":     "fetches",
+	"OPEN CONNS":  "open_connections",
+	"AVG RTT":     "avg_rtt_ms",
+	"PINGS SENT":  "pings_sent",
+	"PINGS ACKED": "pings_acked",
+	"IGNORED":     "ignored",
+}
+
+type Squid struct {
+	URLs     []string        `toml:"urls"`
+	Collect  []string        `toml:"collect"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Log      telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+func (*Squid) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Squid) Init() error {
+	if len(s.URLs) == 0 {
+		s.URLs = []string{"http://localhost:3128"}
+	}
+	for i, u := range s.URLs {
+		s.URLs[i] = strings.TrimSuffix(u, "/")
+	}
+
+	if len(s.Collect) == 0 {
+		s.Collect = []string{"counters", "info", "peers"}
+	}
+	for _, c := range s.Collect {
+		if _, found := pages[c]; !found {
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *Squid) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range s.URLs {
+		wg.Add(1)
+		go func(baseURL string) {
+			defer wg.Done()
+			if err := s.gatherServer(acc, baseURL); err != nil {
+				acc.AddError(fmt.Errorf("[url=%s]: %w", baseURL, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *Squid) Stop() {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+}
+
+func (s *Squid) gatherServer(acc telegraf.Accumulator, baseURL string) error {
+	for _, c := range s.Collect {
+		body, err := s.fetch(baseURL + "/squid-internal-mgr/" + pages[c])
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		tags := map[string]string{"url": baseURL}
+		switch c {
+		case "counters":
+			acc.AddFields("squid_counters", parseCounters(body), tags, now)
+		case "info":
+			acc.AddFields("squid_info", parseInfo(body), tags, now)
+		case "peers":
+			for _, p := range parsePeers(body) {
+				p.tags["url"] = baseURL
+				acc.AddFields("squid_peer", p.fields, p.tags, now)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Squid) fetch(address string) (string, error) {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request failed: %w", err)
+	}
+
+	if !s.Username.Empty() || !s.Password.Empty() {
+		username, err := s.Username.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+		password, err := s.Password.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return "", fmt.Errorf("%s returned HTTP status %s: %q", address, resp.Status, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading body of %s failed: %w", address, err)
+	}
+	return string(body), nil
+}
+
+// parseCounters parses the "key = value" lines of the 'counters' page
+func parseCounters(body string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "sample_time" {
+			continue
+		}
+		if v, ok := parseNumber(value); ok {
+			fields[strings.ReplaceAll(key, ".", "_")] = v
+		}
+	}
+	return fields
+}
+
+// parseInfo parses the "key: value" lines of the 'info' page
+func parseInfo(body string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		name, found := infoFields[strings.TrimSpace(key)]
+		if !found {
+			continue
+		}
+
+		// Ratios are reported for the last five and the last sixty minutes
+		// in the form "5min: 25.0%, 60min: 30.5%"
+		if strings.Contains(value, "5min:") {
+			for _, part := range strings.Split(value, ",") {
+				interval, v, found := strings.Cut(part, ":")
+				if !found {
+					continue
+				}
+				if n, ok := parseNumber(v); ok {
+					fields[name+"_"+strings.TrimSpace(interval)] = n
+				}
+			}
+			continue
+		}
+
+		if v, ok := parseNumber(value); ok {
+			fields[name] = v
+		}
+	}
+	return fields
+}
+
+type peer struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+// parsePeers parses the blocks of the 'server_list' page, each starting with
+// the type and name of the peer e.g. "Parent  : proxy1.example.com"
+func parsePeers(body string) []peer {
+	var peers []peer
+	var current *peer
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Indented lines contain the histogram of the ping replies
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ") {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Parent", "Sibling", "Multicast":
+			peers = append(peers, peer{
+				tags: map[string]string{
+					"peer":      value,
+					"peer_type": strings.ToLower(key),
+				},
+				fields: make(map[string]interface{}),
+			})
+			current = &peers[len(peers)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if key == "Status" {
+			current.fields["status"] = value
+			current.fields["up"] = strings.EqualFold(value, "up")
+			continue
+		}
+		if name, found := peerFields[key]; found {
+			if v, ok := parseNumber(value); ok {
+				current.fields[name] = v
+			}
+		}
+	}
+
+	// Skip peers without any information
+	return slices.DeleteFunc(peers, func(p peer) bool { return len(p.fields) == 0 })
+}
+
+// parseNumber parses the first word of the value as integer or float,
+// ignoring units and percent signs following the number
+func parseNumber(value string) (interface{}, bool) {
+	parts := strings.Fields(value)
+	if len(parts) == 0 {
+		return nil, false
+	}
+	s := strings.TrimSuffix(parts[0], "%")
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, true
+	}
+	return nil, false
+}
+
+func init() {
+	inputs.Add("squid", func() telegraf.Input {
+		return &Squid{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package squid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); ok && (username != "manager" || password != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var filename string
+		switch r.URL.Path {
+		case "/squid-internal-mgr/counters":
+			filename = "counters.txt"
+		case "/squid-internal-mgr/info":
+			filename = "info.txt"
+		case "/squid-internal-mgr/server_list":
+			filename = "server_list.txt"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", filename))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &Squid{Collect: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' value "foo"`)
+}
+
+func TestGather(t *testing.T) {
+	server := newServer(t)
+
+	plugin := &Squid{
+		URLs:     []string{server.URL + "/"},
+		Username: config.NewSecret([]byte("manager")),
+		Password: config.NewSecret([]byte("secret")),
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"squid_counters",
+			map[string]string{"url": server.URL},
+			map[string]interface{}{
+				"client_http_requests":       int64(12345),
+				"client_http_hits":           int64(2345),
+				"client_http_errors":         int64(12),
+				"client_http_kbytes_in":      int64(1234),
+				"client_http_kbytes_out":     int64(56789),
+				"client_http_hit_kbytes_out": int64(8765),
+				"server_all_requests":        int64(10000),
+				"server_all_errors":          int64(3),
+				"server_all_kbytes_in":       int64(50000),
+				"server_all_kbytes_out":      int64(1200),
+				"icp_pkts_sent":              int64(100),
+				"icp_pkts_recv":              int64(98),
+				"cpu_time":                   float64(12.345678),
+				"wall_time":                  float64(0.001234),
+				"aborted_requests":           int64(7),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"squid_info",
+			map[string]string{"url": server.URL},
+			map[string]interface{}{
+				"clients":                    int64(12),
+				"http_requests":              int64(12345),
+				"request_failure_ratio":      float64(0.01),
+				"http_requests_per_minute":   float64(92.9),
+				"request_hit_ratio_5min":     float64(25.0),
+				"request_hit_ratio_60min":    float64(30.5),
+				"byte_hit_ratio_5min":        float64(10.2),
+				"byte_hit_ratio_60min":       float64(12.3),
+				"memory_hit_ratio_5min":      float64(50.0),
+				"memory_hit_ratio_60min":     float64(45.0),
+				"disk_hit_ratio_5min":        float64(40.0),
+				"disk_hit_ratio_60min":       float64(42.0),
+				"disk_cache_size_kb":         int64(102400),
+				"disk_cache_used_percent":    float64(10.0),
+				"memory_cache_size_kb":       int64(8192),
+				"memory_cache_used_percent":  float64(3.1),
+				"mean_object_size_kb":        float64(22.5),
+				"uptime":                     float64(7970.123),
+				"cpu_usage_percent":          float64(0.15),
+				"max_resident_size_kb":       int64(123456),
+				"memory_accounted_kb":        int64(12345),
+				"file_descriptors_max":       int64(1024),
+				"file_descriptors_used":      int64(15),
+				"file_descriptors_available": int64(1009),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"squid_peer",
+			map[string]string{
+				"url":       server.URL,
+				"peer":      "proxy1.example.com",
+				"peer_type": "parent",
+			},
+			map[string]interface{}{
+				"status":           "Up",
+				"up":               true,
+				"fetches":          int64(1234),
+				"open_connections": int64(2),
+				"avg_rtt_ms":       int64(15),
+				"pings_sent":       int64(100),
+				"pings_acked":      int64(98),
+				"ignored":          int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"squid_peer",
+			map[string]string{
+				"url":       server.URL,
+				"peer":      "proxy2.example.com",
+				"peer_type": "sibling",
+			},
+			map[string]interface{}{
+				"status":           "Down",
+				"up":               false,
+				"fetches":          int64(0),
+				"open_connections": int64(0),
+				"avg_rtt_ms":       int64(0),
+				"pings_sent":       int64(50),
+				"pings_acked":      int64(0),
+				"ignored":          int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherCollectSubset(t *testing.T) {
+	server := newServer(t)
+
+	plugin := &Squid{
+		URLs:    []string{server.URL},
+		Collect: []string{"peers"},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "squid_peer", m.Name())
+	}
+}
+
+func TestGatherUnauthorized(t *testing.T) {
+	server := newServer(t)
+
+	plugin := &Squid{
+		URLs:     []string{server.URL},
+		Username: config.NewSecret([]byte("manager")),
+		Password: config.NewSecret([]byte("wrong")),
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "401 Unauthorized")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected interface{}
+	}{
+		{value: " 42", expected: int64(42)},
+		{value: "\t0.01", expected: float64(0.01)},
+		{value: " 10.0% used, 90.0% free", expected: float64(10.0)},
+		{value: "102400 KB", expected: int64(102400)},
+	}
+	for _, tt := range tests {
+		v, ok := parseNumber(tt.value)
+		require.True(t, ok)
+		require.Equal(t, tt.expected, v)
+	}
+
+	_, ok := parseNumber("Up")
+	require.False(t, ok)
+}
@@ -0,0 +1,153 @@
+package storage_controller
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	ssacliControllerRe = regexp.MustCompile(`^(.+) in Slot (\S+)`)
+	ssacliDriveRe      = regexp.MustCompile(`^(physicaldrive|logicaldrive) (\S+) \((.*)\): (.+)$`)
+)
+
+type ssacliController struct {
+	model  string
+	slot   string
+	status map[string]string
+}
+
+func (s *StorageController) gatherSsacli(acc telegraf.Accumulator) error {
+	buf, err := s.run(s.SsacliBin, "ctrl", "all", "show", "status")
+	if err != nil {
+		return err
+	}
+
+	for _, ctrl := range parseSsacliControllers(buf) {
+		tags := map[string]string{
+			"tool":       "ssacli",
+			"controller": ctrl.slot,
+			"model":      ctrl.model,
+		}
+		fields := make(map[string]interface{})
+		if v, found := ctrl.status["Controller Status"]; found {
+			fields["status"] = v
+			fields["ok"] = v == "OK"
+		}
+		if v, found := ctrl.status["Cache Status"]; found {
+			fields["cache_status"] = v
+		}
+		if v, found := ctrl.status["Battery/Capacitor Status"]; found {
+			fields["battery_status"] = v
+			fields["battery_ok"] = v == "OK"
+		}
+		acc.AddFields("storage_controller", fields, tags)
+
+		for _, kind := range []string{"ld", "pd"} {
+			buf, err := s.run(s.SsacliBin, "ctrl", "slot="+ctrl.slot, kind, "all", "show", "status")
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			parseSsacliDrives(acc, ctrl.slot, buf)
+		}
+	}
+
+	return nil
+}
+
+func parseSsacliControllers(buf []byte) []*ssacliController {
+	var controllers []*ssacliController
+	var current *ssacliController
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Controller headers are not indented while the status lines are
+		if !strings.HasPrefix(line, " ") {
+			current = nil
+			if m := ssacliControllerRe.FindStringSubmatch(line); m != nil {
+				current = &ssacliController{
+					model:  m[1],
+					slot:   m[2],
+					status: make(map[string]string),
+				}
+				controllers = append(controllers, current)
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if k, v, found := strings.Cut(line, ":"); found {
+			current.status[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return controllers
+}
+
+func parseSsacliDrives(acc telegraf.Accumulator, slot string, buf []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		m := ssacliDriveRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		kind, id, details, state := m[1], m[2], strings.Split(m[3], ", "), m[4]
+
+		switch kind {
+		case "logicaldrive":
+			// Details are "<size>, <raid level>"
+			tags := map[string]string{
+				"tool":       "ssacli",
+				"controller": slot,
+				"vd":         id,
+			}
+			if len(details) > 1 {
+				tags["raid_level"] = details[len(details)-1]
+			}
+			fields := map[string]interface{}{
+				"state": state,
+				"ok":    state == "OK",
+			}
+			if size, ok := parseSize(details[0]); ok {
+				fields["size"] = size
+			}
+			acc.AddFields("storage_controller_vd", fields, tags)
+		case "physicaldrive":
+			// Details are "port <port>:box <box>:bay <bay>[, <interface> <media>], <size>"
+			tags := map[string]string{
+				"tool":       "ssacli",
+				"controller": slot,
+				"drive":      id,
+			}
+			if len(details) > 2 {
+				if intf, media, found := strings.Cut(details[1], " "); found {
+					tags["interface"] = intf
+					tags["media"] = media
+				}
+			}
+			predictive := 0
+			if strings.Contains(state, "Predictive Failure") {
+				predictive = 1
+			}
+			fields := map[string]interface{}{
+				"state":               state,
+				"ok":                  state == "OK",
+				"predictive_failures": predictive,
+			}
+			if size, ok := parseSize(details[len(details)-1]); ok {
+				fields["size"] = size
+			}
+			acc.AddFields("storage_controller_pd", fields, tags)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package storage_controller
+
+import (
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// execCommand is used to mock commands in tests.
+var execCommand = exec.Command
+
+type StorageController struct {
+	Tools      []string        `toml:"tools"`
+	StorcliBin string          `toml:"storcli_path"`
+	SsacliBin  string          `toml:"ssacli_path"`
+	UseSudo    bool            `toml:"use_sudo"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+}
+
+func (*StorageController) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *StorageController) Init() error {
+	if len(s.Tools) == 0 {
+		s.Tools = []string{"storcli"}
+	}
+	for _, tool := range s.Tools {
+		switch tool {
+		case "storcli", "ssacli":
+		default:
+			return fmt.Errorf("invalid tool %q", tool)
+		}
+	}
+
+	if s.StorcliBin == "" {
+		s.StorcliBin = "/opt/MegaRAID/storcli/storcli64"
+	}
+	if s.SsacliBin == "" {
+		s.SsacliBin = "/usr/sbin/ssacli"
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = config.Duration(30 * time.Second)
+	}
+
+	return nil
+}
+
+func (s *StorageController) Gather(acc telegraf.Accumulator) error {
+	for _, tool := range s.Tools {
+		var err error
+		switch tool {
+		case "storcli":
+			err = s.gatherStorcli(acc)
+		case "ssacli":
+			err = s.gatherSsacli(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s failed: %w", tool, err))
+		}
+	}
+	return nil
+}
+
+func (s *StorageController) run(bin string, args ...string) ([]byte, error) {
+	cmd := execCommand(bin, args...)
+	if s.UseSudo {
+		cmd = execCommand("sudo", append([]string{"-n", bin}, args...)...)
+	}
+	out, err := internal.CombinedOutputTimeout(cmd, time.Duration(s.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %q with %v failed: %w", bin, args, err)
+	}
+	return out, nil
+}
+
+// parseTemperature extracts the temperature in degree Celsius from strings
+// like "28C" or "30C (86.00 F)"
+func parseTemperature(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	idx := strings.Index(s, "C")
+	if idx < 1 {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s[:idx], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseSize converts sizes like "278.875 GB" or "300 GB" to bytes
+func parseSize(s string) (uint64, bool) {
+	value, unit, found := strings.Cut(strings.TrimSpace(s), " ")
+	if !found {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	var factor float64
+	switch strings.ToUpper(unit) {
+	case "B":
+		factor = 1
+	case "KB":
+		factor = 1 << 10
+	case "MB":
+		factor = 1 << 20
+	case "GB":
+		factor = 1 << 30
+	case "TB":
+		factor = 1 << 40
+	case "PB":
+		factor = 1 << 50
+	default:
+		return 0, false
+	}
+	return uint64(v * factor), true
+}
+
+func init() {
+	inputs.Add("storage_controller", func() telegraf.Input {
+		return &StorageController{
+			Timeout: config.Duration(30 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package storage_controller
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &StorageController{Tools: []string{"megacli"}}
+	require.ErrorContains(t, plugin.Init(), `invalid tool "megacli"`)
+}
+
+func TestGatherStorcli(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	plugin := &StorageController{
+		Tools:      []string{"storcli"},
+		StorcliBin: "storcli",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"storage_controller",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"model":      "PERC H730P Mini",
+				"serial":     "5AB012C",
+			},
+			map[string]interface{}{
+				"status":                      "Needs Attention",
+				"ok":                          false,
+				"memory_correctable_errors":   int64(2),
+				"memory_uncorrectable_errors": int64(0),
+				"battery_model":               "CVPM02",
+				"battery_status":              "Optimal",
+				"battery_ok":                  true,
+				"battery_temperature":         int64(28),
+				"patrol_read_mode":            "Auto",
+				"patrol_read_state":           "Stopped",
+				"patrol_read_iterations":      int64(91),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_vd",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"vd":         "0/0",
+				"raid_level": "RAID1",
+				"name":       "os",
+			},
+			map[string]interface{}{
+				"state": "Optl",
+				"ok":    true,
+				"size":  uint64(299439751168),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_vd",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"vd":         "1/1",
+				"raid_level": "RAID5",
+			},
+			map[string]interface{}{
+				"state": "Dgrd",
+				"ok":    false,
+				"size":  uint64(1197368162648),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"drive":      "32:0",
+				"model":      "ST300MM0008",
+				"interface":  "SAS",
+				"media":      "HDD",
+			},
+			map[string]interface{}{
+				"state":               "Onln",
+				"ok":                  true,
+				"size":                uint64(299439751168),
+				"media_errors":        int64(0),
+				"other_errors":        int64(0),
+				"predictive_failures": int64(0),
+				"smart_alert":         false,
+				"temperature":         int64(30),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"drive":      "32:1",
+				"model":      "ST300MM0008",
+				"interface":  "SAS",
+				"media":      "HDD",
+			},
+			map[string]interface{}{
+				"state":               "Onln",
+				"ok":                  true,
+				"size":                uint64(299439751168),
+				"media_errors":        int64(12),
+				"other_errors":        int64(1),
+				"predictive_failures": int64(3),
+				"smart_alert":         true,
+				"temperature":         int64(33),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "storcli",
+				"controller": "0",
+				"drive":      "32:2",
+				"model":      "ST600MM0088",
+				"interface":  "SAS",
+				"media":      "HDD",
+			},
+			map[string]interface{}{
+				"state": "Offln",
+				"ok":    false,
+				"size":  uint64(599583876972),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherSsacli(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	plugin := &StorageController{
+		Tools:     []string{"ssacli"},
+		SsacliBin: "ssacli",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"storage_controller",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"model":      "Smart Array P420i",
+			},
+			map[string]interface{}{
+				"status":         "OK",
+				"ok":             true,
+				"cache_status":   "OK",
+				"battery_status": "Failed (Replace Batteries)",
+				"battery_ok":     false,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_vd",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"vd":         "1",
+				"raid_level": "RAID 1",
+			},
+			map[string]interface{}{
+				"state": "OK",
+				"ok":    true,
+				"size":  uint64(300003465625),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_vd",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"vd":         "2",
+				"raid_level": "RAID 5",
+			},
+			map[string]interface{}{
+				"state": "Interim Recovery Mode",
+				"ok":    false,
+				"size":  uint64(1209462790553),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"drive":      "1I:2:1",
+				"interface":  "SAS",
+				"media":      "HDD",
+			},
+			map[string]interface{}{
+				"state":               "OK",
+				"ok":                  true,
+				"predictive_failures": 0,
+				"size":                uint64(322122547200),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"drive":      "1I:2:2",
+				"interface":  "SAS",
+				"media":      "HDD",
+			},
+			map[string]interface{}{
+				"state":               "Predictive Failure",
+				"ok":                  false,
+				"predictive_failures": 1,
+				"size":                uint64(322122547200),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"storage_controller_pd",
+			map[string]string{
+				"tool":       "ssacli",
+				"controller": "0",
+				"drive":      "2I:2:5",
+			},
+			map[string]interface{}{
+				"state":               "Failed",
+				"ok":                  false,
+				"predictive_failures": 0,
+				"size":                uint64(644245094400),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseSize(t *testing.T) {
+	for input, expected := range map[string]uint64{
+		"512 B":      512,
+		"1 KB":       1024,
+		"278.875 GB": 299439751168,
+		"1.5 TB":     1649267441664,
+	} {
+		actual, ok := parseSize(input)
+		require.Truef(t, ok, "parsing %q failed", input)
+		require.Equal(t, expected, actual)
+	}
+
+	for _, input := range []string{"", "GB", "abc GB", "12 XB"} {
+		_, ok := parseSize(input)
+		require.Falsef(t, ok, "parsing %q should fail", input)
+	}
+}
+
+func fakeExecCommand(command string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+func TestHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	idx := slices.Index(os.Args, "--")
+	if idx < 0 {
+		fmt.Fprint(os.Stdout, "invalid arguments")
+		os.Exit(42) //nolint:revive // os.Exit called intentionally
+	}
+
+	var filename string
+	switch strings.Join(os.Args[idx+1:], " ") {
+	case "storcli /call show all J":
+		filename = "storcli_show_all.json"
+	case "storcli /call show patrolread J":
+		filename = "storcli_patrolread.json"
+	case "storcli /call/eall/sall show all J":
+		filename = "storcli_drives.json"
+	case "ssacli ctrl all show status":
+		filename = "ssacli_ctrl.txt"
+	case "ssacli ctrl slot=0 ld all show status":
+		filename = "ssacli_ld.txt"
+	case "ssacli ctrl slot=0 pd all show status":
+		filename = "ssacli_pd.txt"
+	default:
+		fmt.Fprint(os.Stdout, "unknown command")
+		os.Exit(42) //nolint:revive // os.Exit called intentionally
+	}
+
+	buf, err := os.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		fmt.Fprint(os.Stdout, "unknown filename")
+		os.Exit(42) //nolint:revive // os.Exit called intentionally
+	}
+	fmt.Fprint(os.Stdout, string(buf))
+	os.Exit(0) //nolint:revive // os.Exit called intentionally
+}
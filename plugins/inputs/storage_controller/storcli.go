@@ -0,0 +1,273 @@
+package storage_controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+type storcliOutput struct {
+	Controllers []struct {
+		CommandStatus struct {
+			Controller  interface{} `json:"Controller"`
+			Status      string      `json:"Status"`
+			Description string      `json:"Description"`
+		} `json:"Command Status"`
+		ResponseData json.RawMessage `json:"Response Data"`
+	} `json:"Controllers"`
+}
+
+type storcliShowAll struct {
+	Basics struct {
+		Model        string `json:"Model"`
+		SerialNumber string `json:"Serial Number"`
+	} `json:"Basics"`
+	Status struct {
+		ControllerStatus          string `json:"Controller Status"`
+		MemoryCorrectableErrors   int64  `json:"Memory Correctable Errors"`
+		MemoryUncorrectableErrors int64  `json:"Memory Uncorrectable Errors"`
+	} `json:"Status"`
+	VDList []struct {
+		DGVD  string `json:"DG/VD"`
+		Type  string `json:"TYPE"`
+		State string `json:"State"`
+		Size  string `json:"Size"`
+		Name  string `json:"Name"`
+	} `json:"VD LIST"`
+	PDList         []storcliPD      `json:"PD LIST"`
+	BBUInfo        []storcliBattery `json:"BBU_Info"`
+	CachevaultInfo []storcliBattery `json:"Cachevault_Info"`
+}
+
+type storcliPD struct {
+	EIDSlot string `json:"EID:Slt"`
+	State   string `json:"State"`
+	Size    string `json:"Size"`
+	Intf    string `json:"Intf"`
+	Med     string `json:"Med"`
+	Model   string `json:"Model"`
+}
+
+type storcliBattery struct {
+	Model string `json:"Model"`
+	State string `json:"State"`
+	Temp  string `json:"Temp"`
+}
+
+type storcliDriveState struct {
+	MediaErrorCount        int64  `json:"Media Error Count"`
+	OtherErrorCount        int64  `json:"Other Error Count"`
+	PredictiveFailureCount int64  `json:"Predictive Failure Count"`
+	DriveTemperature       string `json:"Drive Temperature"`
+	SmartAlert             string `json:"S.M.A.R.T alert flagged by drive"`
+}
+
+type storcliPatrolRead struct {
+	Properties []struct {
+		Name  string      `json:"Ctrl_Prop"`
+		Value interface{} `json:"Value"`
+	} `json:"Controller Properties"`
+}
+
+// Physical drive states considered healthy, i.e. online, unconfigured good,
+// global and dedicated hot-spare and JBOD
+var storcliPDHealthy = map[string]bool{
+	"Onln":  true,
+	"UGood": true,
+	"GHS":   true,
+	"DHS":   true,
+	"JBOD":  true,
+}
+
+func (s *StorageController) gatherStorcli(acc telegraf.Accumulator) error {
+	controllers, err := s.storcli("/call", "show", "all", "J")
+	if err != nil {
+		return err
+	}
+	patrolReads, err := s.storcli("/call", "show", "patrolread", "J")
+	if err != nil {
+		return err
+	}
+	driveDetails, err := s.storcli("/call/eall/sall", "show", "all", "J")
+	if err != nil {
+		return err
+	}
+
+	for ctrl, data := range controllers {
+		var info storcliShowAll
+		if err := json.Unmarshal(data, &info); err != nil {
+			acc.AddError(fmt.Errorf("parsing data of controller %s failed: %w", ctrl, err))
+			continue
+		}
+
+		tags := map[string]string{
+			"tool":       "storcli",
+			"controller": ctrl,
+			"model":      info.Basics.Model,
+			"serial":     info.Basics.SerialNumber,
+		}
+		fields := map[string]interface{}{
+			"status":                      info.Status.ControllerStatus,
+			"ok":                          info.Status.ControllerStatus == "Optimal",
+			"memory_correctable_errors":   info.Status.MemoryCorrectableErrors,
+			"memory_uncorrectable_errors": info.Status.MemoryUncorrectableErrors,
+		}
+
+		// A controller either has a battery backup unit or a cache-vault
+		batteries := slices.Concat(info.BBUInfo, info.CachevaultInfo)
+		if len(batteries) > 0 {
+			fields["battery_model"] = batteries[0].Model
+			fields["battery_status"] = batteries[0].State
+			fields["battery_ok"] = batteries[0].State == "Optimal"
+			if t, ok := parseTemperature(batteries[0].Temp); ok {
+				fields["battery_temperature"] = t
+			}
+		}
+
+		if raw, found := patrolReads[ctrl]; found {
+			var pr storcliPatrolRead
+			if err := json.Unmarshal(raw, &pr); err != nil {
+				acc.AddError(fmt.Errorf("parsing patrol read of controller %s failed: %w", ctrl, err))
+			}
+			for _, p := range pr.Properties {
+				value := strings.TrimSpace(fmt.Sprintf("%v", p.Value))
+				switch p.Name {
+				case "PR Mode":
+					fields["patrol_read_mode"] = value
+				case "PR Current State":
+					fields["patrol_read_state"] = value
+				case "PR iterations completed":
+					if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+						fields["patrol_read_iterations"] = v
+					}
+				}
+			}
+		}
+		acc.AddFields("storage_controller", fields, tags)
+
+		for _, vd := range info.VDList {
+			vtags := map[string]string{
+				"tool":       "storcli",
+				"controller": ctrl,
+				"vd":         vd.DGVD,
+				"raid_level": vd.Type,
+			}
+			if vd.Name != "" {
+				vtags["name"] = vd.Name
+			}
+			vfields := map[string]interface{}{
+				"state": vd.State,
+				"ok":    vd.State == "Optl",
+			}
+			if size, ok := parseSize(vd.Size); ok {
+				vfields["size"] = size
+			}
+			acc.AddFields("storage_controller_vd", vfields, vtags)
+		}
+
+		details := storcliDriveDetails(driveDetails[ctrl])
+		for _, pd := range info.PDList {
+			drive := strings.TrimSpace(pd.EIDSlot)
+			ptags := map[string]string{
+				"tool":       "storcli",
+				"controller": ctrl,
+				"drive":      drive,
+				"model":      strings.TrimSpace(pd.Model),
+				"interface":  pd.Intf,
+				"media":      pd.Med,
+			}
+			pfields := map[string]interface{}{
+				"state": pd.State,
+				"ok":    storcliPDHealthy[pd.State],
+			}
+			if size, ok := parseSize(pd.Size); ok {
+				pfields["size"] = size
+			}
+			if d, found := details[drive]; found {
+				pfields["media_errors"] = d.MediaErrorCount
+				pfields["other_errors"] = d.OtherErrorCount
+				pfields["predictive_failures"] = d.PredictiveFailureCount
+				pfields["smart_alert"] = d.SmartAlert == "Yes"
+				if t, ok := parseTemperature(d.DriveTemperature); ok {
+					pfields["temperature"] = t
+				}
+			}
+			acc.AddFields("storage_controller_pd", pfields, ptags)
+		}
+	}
+
+	return nil
+}
+
+// storcli runs the given command and returns the response data per
+// controller
+func (s *StorageController) storcli(args ...string) (map[string]json.RawMessage, error) {
+	buf, err := s.run(s.StorcliBin, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out storcliOutput
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, fmt.Errorf("parsing output of %v failed: %w", args, err)
+	}
+
+	result := make(map[string]json.RawMessage, len(out.Controllers))
+	for _, c := range out.Controllers {
+		ctrl := fmt.Sprintf("%v", c.CommandStatus.Controller)
+		if c.CommandStatus.Status != "Success" {
+			s.Log.Debugf("Command %v failed for controller %s: %s", args, ctrl, c.CommandStatus.Description)
+			continue
+		}
+		result[ctrl] = c.ResponseData
+	}
+	return result, nil
+}
+
+// storcliDriveDetails extracts the drive state information keyed by the
+// "EID:Slt" identifier used in the physical drive list
+func storcliDriveDetails(raw json.RawMessage) map[string]storcliDriveState {
+	details := make(map[string]storcliDriveState)
+	if len(raw) == 0 {
+		return details
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return details
+	}
+
+	for key, value := range data {
+		// Keys are of the form "Drive /c0/e32/s0 - Detailed Information"
+		path, found := strings.CutSuffix(key, " - Detailed Information")
+		if !found {
+			continue
+		}
+		path = strings.TrimPrefix(path, "Drive ")
+
+		var detail map[string]json.RawMessage
+		if err := json.Unmarshal(value, &detail); err != nil {
+			continue
+		}
+		var state storcliDriveState
+		if err := json.Unmarshal(detail["Drive "+path+" State"], &state); err != nil {
+			continue
+		}
+
+		// Convert the path "/c0/e32/s0" to "32:0"
+		var enclosure, slot string
+		for _, part := range strings.Split(path, "/") {
+			if v, ok := strings.CutPrefix(part, "e"); ok {
+				enclosure = v
+			} else if v, ok := strings.CutPrefix(part, "s"); ok {
+				slot = v
+			}
+		}
+		details[enclosure+":"+slot] = state
+	}
+	return details
+}
@@ -0,0 +1,230 @@
+//go:build windows
+
+package win_services
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	modwevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtQuery  = modwevtapi.NewProc("EvtQuery")
+	procEvtNext   = modwevtapi.NewProc("EvtNext")
+	procEvtRender = modwevtapi.NewProc("EvtRender")
+	procEvtClose  = modwevtapi.NewProc("EvtClose")
+)
+
+// EVT_QUERY_FLAGS and EVT_RENDER_FLAGS enumerations
+// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_query_flags
+const (
+	evtQueryChannelPath      = 0x1
+	evtQueryForwardDirection = 0x100
+	evtRenderEventXML        = 1
+)
+
+// Events of the service control manager in the system log
+const (
+	eventTerminatedWithAction = 7031
+	eventTerminated           = 7034
+	eventStateChanged         = 7036
+)
+
+// Number of event handles fetched at once
+const eventBatchSize = 64
+
+// eventProvider sets interface for querying the rendered XML of the service
+// events within the given time window from the event log
+type eventProvider interface {
+	query(window time.Duration) ([][]byte, error)
+}
+
+// serviceEvents holds the number of events per service
+type serviceEvents struct {
+	// restarts holds the number of transitions into the running state by
+	// lowercase service name
+	restarts map[string]int
+	// terminations holds the number of unexpected terminations by lowercase
+	// display name as the events do not contain the service name
+	terminations map[string]int
+}
+
+type scmEvent struct {
+	EventID int            `xml:"System>EventID"`
+	Data    []scmEventData `xml:"EventData>Data"`
+	Binary  string         `xml:"EventData>Binary"`
+}
+
+type scmEventData struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// countServiceEvents counts the restarts and unexpected terminations in the
+// given rendered events
+func countServiceEvents(raw [][]byte, log telegraf.Logger) *serviceEvents {
+	events := &serviceEvents{
+		restarts:     make(map[string]int),
+		terminations: make(map[string]int),
+	}
+
+	for _, buf := range raw {
+		var e scmEvent
+		if err := xml.Unmarshal(buf, &e); err != nil {
+			log.Debugf("Skipping invalid event: %v", err)
+			continue
+		}
+
+		switch e.EventID {
+		case eventTerminatedWithAction, eventTerminated:
+			// The first parameter contains the display name of the service
+			for _, d := range e.Data {
+				if d.Name == "param1" {
+					events.terminations[strings.ToLower(d.Value)]++
+					break
+				}
+			}
+		case eventStateChanged:
+			// The parameters contain the localized display name and state,
+			// so use the binary data containing the service name and state
+			// code in the form "<service name>/<state>" instead.
+			name, state, err := decodeStateChange(e.Binary)
+			if err != nil {
+				log.Debugf("Skipping state change event: %v", err)
+				continue
+			}
+			if state == "4" {
+				events.restarts[strings.ToLower(name)]++
+			}
+		}
+	}
+
+	return events
+}
+
+// decodeStateChange decodes the hex-encoded UTF-16 binary data of a state
+// change event into the service name and state code
+func decodeStateChange(data string) (name, state string, err error) {
+	buf, err := hex.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return "", "", fmt.Errorf("decoding binary data failed: %w", err)
+	}
+	decoded := strings.TrimRight(decodeUTF16(buf), "\x00")
+
+	idx := strings.LastIndex(decoded, "/")
+	if idx < 0 {
+		return "", "", fmt.Errorf("invalid binary data %q", decoded)
+	}
+	return decoded[:idx], decoded[idx+1:], nil
+}
+
+func decodeUTF16(buf []byte) string {
+	u16 := make([]uint16, 0, len(buf)/2)
+	for i := 0; i+1 < len(buf); i += 2 {
+		u16 = append(u16, uint16(buf[i])|uint16(buf[i+1])<<8)
+	}
+	return string(utf16.Decode(u16))
+}
+
+// evtProvider is an implementation of the eventProvider interface querying
+// the system event log
+type evtProvider struct{}
+
+func (*evtProvider) query(window time.Duration) ([][]byte, error) {
+	if err := modwevtapi.Load(); err != nil {
+		return nil, err
+	}
+
+	path, err := windows.UTF16PtrFromString("System")
+	if err != nil {
+		return nil, err
+	}
+	xpath := fmt.Sprintf(
+		"*[System[Provider[@Name='Service Control Manager'] and (EventID=%d or EventID=%d or EventID=%d) and TimeCreated[timediff(@SystemTime) <= %d]]]",
+		eventTerminatedWithAction, eventTerminated, eventStateChanged, window.Milliseconds(),
+	)
+	q, err := windows.UTF16PtrFromString(xpath)
+	if err != nil {
+		return nil, err
+	}
+
+	r, _, err := procEvtQuery.Call(
+		0,
+		uintptr(unsafe.Pointer(path)),
+		uintptr(unsafe.Pointer(q)),
+		evtQueryChannelPath|evtQueryForwardDirection,
+	)
+	if r == 0 {
+		return nil, fmt.Errorf("querying events failed: %w", err)
+	}
+	query := r
+	defer procEvtClose.Call(query) //nolint:errcheck // closing the handle cannot be handled anyway
+
+	var events [][]byte
+	handles := make([]uintptr, eventBatchSize)
+	for {
+		var returned uint32
+		r, _, err := procEvtNext.Call(
+			query,
+			uintptr(len(handles)),
+			uintptr(unsafe.Pointer(&handles[0])),
+			uintptr(windows.INFINITE),
+			0,
+			uintptr(unsafe.Pointer(&returned)),
+		)
+		if r == 0 {
+			if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+				return events, nil
+			}
+			return nil, fmt.Errorf("fetching events failed: %w", err)
+		}
+
+		var renderErr error
+		for _, h := range handles[:returned] {
+			if renderErr == nil {
+				var buf []byte
+				buf, renderErr = renderEvent(h)
+				events = append(events, buf)
+			}
+			procEvtClose.Call(h) //nolint:errcheck // closing the handle cannot be handled anyway
+		}
+		if renderErr != nil {
+			return nil, renderErr
+		}
+	}
+}
+
+func renderEvent(h uintptr) ([]byte, error) {
+	buf := make([]byte, 4096)
+	for {
+		var used, count uint32
+		r, _, err := procEvtRender.Call(
+			0,
+			h,
+			evtRenderEventXML,
+			uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&used)),
+			uintptr(unsafe.Pointer(&count)),
+		)
+		if r != 0 {
+			// The rendered XML is UTF-16 encoded
+			return []byte(strings.TrimRight(decodeUTF16(buf[:used]), "\x00")), nil
+		}
+		if !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+			return nil, fmt.Errorf("rendering event failed: %w", err)
+		}
+		buf = make([]byte, used)
+	}
+}
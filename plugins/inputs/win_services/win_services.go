@@ -8,14 +8,17 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -23,14 +26,43 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Prefix of load-order groups in the dependency list of a service
+const groupIdentifier = "+"
+
+// Mapping of the startup mode names to the values reported by the service
+// manager, the delayed automatic start is a flag in addition to the mode
+var startupModes = map[string]uint32{
+	"boot":         windows.SERVICE_BOOT_START,
+	"system":       windows.SERVICE_SYSTEM_START,
+	"auto":         windows.SERVICE_AUTO_START,
+	"auto_delayed": windows.SERVICE_AUTO_START,
+	"demand":       windows.SERVICE_DEMAND_START,
+	"disabled":     windows.SERVICE_DISABLED,
+}
+
 type WinServices struct {
-	ServiceNames         []string `toml:"service_names"`
-	ServiceNamesExcluded []string `toml:"excluded_service_names"`
+	ServiceNames         []string              `toml:"service_names"`
+	DisplayNames         []string              `toml:"display_names"`
+	ServiceNamesExcluded []string              `toml:"excluded_service_names"`
+	Collect              []string              `toml:"collect"`
+	EventLogWindow       config.Duration       `toml:"event_log_window"`
+	ExpectedStartupModes []expectedStartupMode `toml:"expected_startup_mode"`
 
 	Log telegraf.Logger `toml:"-"`
 
 	mgrProvider    managerProvider
+	eventProvider  eventProvider
 	servicesFilter filter.Filter
+	includeFilter  filter.Filter
+	excludeFilter  filter.Filter
+	displayFilter  filter.Filter
+}
+
+type expectedStartupMode struct {
+	Services []string `toml:"services"`
+	Mode     string   `toml:"mode"`
+
+	filter filter.Filter
 }
 
 // winService provides interface for svc.Service
@@ -38,6 +70,8 @@ type winService interface {
 	Close() error
 	Config() (mgr.Config, error)
 	Query() (svc.Status, error)
+	RecoveryActions() ([]mgr.RecoveryAction, error)
+	ResetPeriod() (uint32, error)
 }
 
 // managerProvider sets interface for acquiring manager instance, like mgr.Mgr
@@ -53,10 +87,19 @@ type winServiceManager interface {
 }
 
 type serviceInfo struct {
-	ServiceName string
-	DisplayName string
-	State       int
-	StartUpMode int
+	ServiceName      string
+	DisplayName      string
+	State            int
+	StartUpMode      int
+	DelayedAutoStart bool
+	Dependencies     []string
+}
+
+// dependencyInfo holds the state of a service in the dependency chain
+type dependencyInfo struct {
+	running      bool
+	dependencies []string
+	err          error
 }
 
 func (*WinServices) SampleConfig() string {
@@ -66,14 +109,8 @@ func (*WinServices) SampleConfig() string {
 func (m *WinServices) Init() error {
 	// For case insensitive comparison (see issue #8796) we need to transform the services
 	// to lowercase
-	servicesInclude := make([]string, 0, len(m.ServiceNames))
-	for _, s := range m.ServiceNames {
-		servicesInclude = append(servicesInclude, strings.ToLower(s))
-	}
-	servicesExclude := make([]string, 0, len(m.ServiceNamesExcluded))
-	for _, s := range m.ServiceNamesExcluded {
-		servicesExclude = append(servicesExclude, strings.ToLower(s))
-	}
+	servicesInclude := toLower(m.ServiceNames)
+	servicesExclude := toLower(m.ServiceNamesExcluded)
 
 	f, err := filter.NewIncludeExcludeFilter(servicesInclude, servicesExclude)
 	if err != nil {
@@ -81,6 +118,50 @@ func (m *WinServices) Init() error {
 	}
 	m.servicesFilter = f
 
+	// When selecting services by display name, the service name list only
+	// includes the given services instead of all services if empty
+	if len(m.DisplayNames) > 0 {
+		if m.includeFilter, err = filter.Compile(servicesInclude); err != nil {
+			return fmt.Errorf("creating service name filter failed: %w", err)
+		}
+		if m.excludeFilter, err = filter.Compile(servicesExclude); err != nil {
+			return fmt.Errorf("creating excluded service name filter failed: %w", err)
+		}
+		if m.displayFilter, err = filter.Compile(toLower(m.DisplayNames)); err != nil {
+			return fmt.Errorf("creating display name filter failed: %w", err)
+		}
+	}
+
+	for _, c := range m.Collect {
+		switch c {
+		case "dependencies", "recovery", "restarts":
+		default:
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+	if slices.Contains(m.Collect, "restarts") {
+		if m.EventLogWindow <= 0 {
+			return errors.New("event log window must be positive")
+		}
+		if m.eventProvider == nil {
+			m.eventProvider = &evtProvider{}
+		}
+	}
+
+	for i, e := range m.ExpectedStartupModes {
+		if _, found := startupModes[e.Mode]; !found {
+			return fmt.Errorf("invalid startup mode %q", e.Mode)
+		}
+		f, err := filter.Compile(toLower(e.Services))
+		if err != nil {
+			return fmt.Errorf("creating filter for expected startup mode %q failed: %w", e.Mode, err)
+		}
+		if f == nil {
+			return fmt.Errorf("no services given for expected startup mode %q", e.Mode)
+		}
+		m.ExpectedStartupModes[i].filter = f
+	}
+
 	return nil
 }
 
@@ -96,6 +177,21 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	// Query the service events once for all services
+	var events *serviceEvents
+	if slices.Contains(m.Collect, "restarts") {
+		raw, err := m.eventProvider.query(time.Duration(m.EventLogWindow))
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying service events failed: %w", err))
+		} else {
+			events = countServiceEvents(raw, m.Log)
+		}
+	}
+
+	// Cache the state of the services within the dependency chains for
+	// this gathering cycle
+	dependencyCache := make(map[string]*dependencyInfo)
+
 	for _, srvName := range serviceNames {
 		service, err := collectServiceInfo(scmgr, srvName)
 		if err != nil {
@@ -107,6 +203,13 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 			continue
 		}
 
+		// Services not selected by name need to be checked by display name
+		if m.displayFilter != nil && !m.includeFilter.Match(strings.ToLower(srvName)) {
+			if !m.displayFilter.Match(strings.ToLower(service.DisplayName)) {
+				continue
+			}
+		}
+
 		tags := map[string]string{
 			"service_name": service.ServiceName,
 		}
@@ -119,12 +222,91 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 			"state":        service.State,
 			"startup_mode": service.StartUpMode,
 		}
+
+		if drift, found := m.startupModeDrift(service); found {
+			fields["startup_mode_drift"] = drift
+		}
+
+		if slices.Contains(m.Collect, "dependencies") {
+			total, notRunning := m.dependencyHealth(scmgr, service.Dependencies, dependencyCache)
+			fields["dependencies"] = total
+			fields["dependencies_not_running"] = notRunning
+		}
+
+		if slices.Contains(m.Collect, "recovery") {
+			actions, resetPeriod, err := collectRecoveryInfo(scmgr, srvName)
+			if err != nil {
+				m.Log.Debugf("Collecting recovery configuration failed: %v", err)
+			} else {
+				fields["recovery_actions"] = actions
+				fields["recovery_reset_period"] = resetPeriod
+			}
+		}
+
+		if events != nil {
+			fields["restarts"] = events.restarts[strings.ToLower(service.ServiceName)]
+			fields["unexpected_terminations"] = events.terminations[strings.ToLower(service.DisplayName)]
+		}
+
 		acc.AddFields("win_services", fields, tags)
 	}
 
 	return nil
 }
 
+// startupModeDrift checks the startup mode of the service against the first
+// matching expected mode and returns if the modes differ. The second return
+// value is false if no expectation is configured for the service.
+func (m *WinServices) startupModeDrift(service *serviceInfo) (drift, found bool) {
+	name := strings.ToLower(service.ServiceName)
+	for _, e := range m.ExpectedStartupModes {
+		if !e.filter.Match(name) {
+			continue
+		}
+		drift := service.StartUpMode != int(startupModes[e.Mode])
+		if e.Mode == "auto" || e.Mode == "auto_delayed" {
+			drift = drift || service.DelayedAutoStart != (e.Mode == "auto_delayed")
+		}
+		return drift, true
+	}
+	return false, false
+}
+
+// dependencyHealth walks the dependency chain of a service and returns the
+// total number of services in the chain and the number of those not running.
+// Load-order groups and services that cannot be queried are skipped.
+func (m *WinServices) dependencyHealth(scmgr winServiceManager, dependencies []string, cache map[string]*dependencyInfo) (total, notRunning int) {
+	visited := make(map[string]bool)
+	queue := slices.Clone(dependencies)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		key := strings.ToLower(name)
+		if visited[key] || strings.HasPrefix(name, groupIdentifier) {
+			continue
+		}
+		visited[key] = true
+
+		info, found := cache[key]
+		if !found {
+			info = collectDependencyInfo(scmgr, name)
+			cache[key] = info
+		}
+		if info.err != nil {
+			m.Log.Debugf("Skipping dependency: %v", info.err)
+			continue
+		}
+
+		total++
+		if !info.running {
+			notRunning++
+		}
+		queue = append(queue, info.dependencies...)
+	}
+	return total, notRunning
+}
+
 // listServices returns a list of services to gather.
 func (m *WinServices) listServices(scmgr winServiceManager) ([]string, error) {
 	names, err := scmgr.listServices()
@@ -136,6 +318,14 @@ func (m *WinServices) listServices(scmgr winServiceManager) ([]string, error) {
 	for _, name := range names {
 		// Compare case-insensitive. Use lowercase as we already converted the filter to use it.
 		n := strings.ToLower(name)
+		if m.displayFilter != nil {
+			// Keep all services not excluded for checking the display name
+			// later as we only know it after querying the service config
+			if m.excludeFilter == nil || !m.excludeFilter.Match(n) {
+				services = append(services, name)
+			}
+			continue
+		}
 		if m.servicesFilter.Match(n) {
 			services = append(services, name)
 		}
@@ -183,14 +373,76 @@ func collectServiceInfo(scmgr winServiceManager, serviceName string) (*serviceIn
 	}
 
 	serviceInfo := &serviceInfo{
-		ServiceName: serviceName,
-		DisplayName: srvCfg.DisplayName,
-		StartUpMode: int(srvCfg.StartType),
-		State:       int(srvStatus.State),
+		ServiceName:      serviceName,
+		DisplayName:      srvCfg.DisplayName,
+		StartUpMode:      int(srvCfg.StartType),
+		State:            int(srvStatus.State),
+		DelayedAutoStart: srvCfg.DelayedAutoStart,
+		Dependencies:     srvCfg.Dependencies,
 	}
 	return serviceInfo, nil
 }
 
+// collectDependencyInfo gathers the state and dependencies of a service in a
+// dependency chain.
+func collectDependencyInfo(scmgr winServiceManager, serviceName string) *dependencyInfo {
+	srv, err := scmgr.openService(serviceName)
+	if err != nil {
+		return &dependencyInfo{err: &serviceError{message: "could not open service", service: serviceName, err: err}}
+	}
+	defer srv.Close()
+
+	srvStatus, err := srv.Query()
+	if err != nil {
+		return &dependencyInfo{err: &serviceError{message: "could not query service", service: serviceName, err: err}}
+	}
+
+	srvCfg, err := srv.Config()
+	if err != nil {
+		return &dependencyInfo{err: &serviceError{message: "could not get config of service", service: serviceName, err: err}}
+	}
+
+	return &dependencyInfo{
+		running:      srvStatus.State == svc.Running,
+		dependencies: srvCfg.Dependencies,
+	}
+}
+
+// collectRecoveryInfo returns the number of recovery actions other than
+// "no action" and the reset period of the failure count in seconds.
+func collectRecoveryInfo(scmgr winServiceManager, serviceName string) (actions int, resetPeriod int64, err error) {
+	srv, err := scmgr.openService(serviceName)
+	if err != nil {
+		return 0, 0, &serviceError{message: "could not open service", service: serviceName, err: err}
+	}
+	defer srv.Close()
+
+	recoveryActions, err := srv.RecoveryActions()
+	if err != nil {
+		return 0, 0, &serviceError{message: "could not get recovery actions of service", service: serviceName, err: err}
+	}
+	for _, a := range recoveryActions {
+		if a.Type != mgr.NoAction {
+			actions++
+		}
+	}
+
+	period, err := srv.ResetPeriod()
+	if err != nil {
+		return 0, 0, &serviceError{message: "could not get recovery reset period of service", service: serviceName, err: err}
+	}
+
+	return actions, int64(period), nil
+}
+
+func toLower(values []string) []string {
+	lowered := make([]string, 0, len(values))
+	for _, v := range values {
+		lowered = append(lowered, strings.ToLower(v))
+	}
+	return lowered
+}
+
 type serviceError struct {
 	message string
 	service string
@@ -242,7 +494,8 @@ func (*mgProvider) connect() (winServiceManager, error) {
 func init() {
 	inputs.Add("win_services", func() telegraf.Input {
 		return &WinServices{
-			mgrProvider: &mgProvider{},
+			EventLogWindow: config.Duration(24 * time.Hour),
+			mgrProvider:    &mgProvider{},
 		}
 	})
 }
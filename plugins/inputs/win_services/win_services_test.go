@@ -4,15 +4,20 @@ package win_services
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -33,6 +38,10 @@ type serviceTestInfo struct {
 	displayName        string
 	state              int
 	startUpMode        int
+	delayedAutoStart   bool
+	dependencies       []string
+	recoveryActions    []mgr.RecoveryAction
+	resetPeriod        uint32
 }
 
 type FakeSvcMgr struct {
@@ -92,11 +101,12 @@ func (m *fakeWinSvc) Config() (mgr.Config, error) {
 		BinaryPathName:   "",
 		LoadOrderGroup:   "",
 		TagId:            0,
-		Dependencies:     nil,
+		Dependencies:     m.testData.dependencies,
 		ServiceStartName: m.testData.serviceName,
 		DisplayName:      m.testData.displayName,
 		Password:         "",
 		Description:      "",
+		DelayedAutoStart: m.testData.delayedAutoStart,
 	}, nil
 }
 
@@ -112,17 +122,31 @@ func (m *fakeWinSvc) Query() (svc.Status, error) {
 	}, nil
 }
 
+func (m *fakeWinSvc) RecoveryActions() ([]mgr.RecoveryAction, error) {
+	return m.testData.recoveryActions, nil
+}
+
+func (m *fakeWinSvc) ResetPeriod() (uint32, error) {
+	return m.testData.resetPeriod, nil
+}
+
 var testErrors = []testData{
-	{nil, errors.New("fake mgr connect error"), nil, nil},
-	{nil, nil, errors.New("fake mgr list services error"), nil},
-	{[]string{"Fake service 1", "Fake service 2", "Fake service 3"}, nil, nil, []serviceTestInfo{
-		{errors.New("fake srv open error"), nil, nil, "Fake service 1", "", 0, 0},
-		{nil, errors.New("fake srv query error"), nil, "Fake service 2", "", 0, 0},
-		{nil, nil, errors.New("fake srv config error"), "Fake service 3", "", 0, 0},
-	}},
-	{[]string{"Fake service 1"}, nil, nil, []serviceTestInfo{
-		{errors.New("fake srv open error"), nil, nil, "Fake service 1", "", 0, 0},
-	}},
+	{mgrConnectError: errors.New("fake mgr connect error")},
+	{mgrListServicesError: errors.New("fake mgr list services error")},
+	{
+		queryServiceList: []string{"Fake service 1", "Fake service 2", "Fake service 3"},
+		services: []serviceTestInfo{
+			{serviceOpenError: errors.New("fake srv open error"), serviceName: "Fake service 1"},
+			{serviceQueryError: errors.New("fake srv query error"), serviceName: "Fake service 2"},
+			{serviceConfigError: errors.New("fake srv config error"), serviceName: "Fake service 3"},
+		},
+	},
+	{
+		queryServiceList: []string{"Fake service 1"},
+		services: []serviceTestInfo{
+			{serviceOpenError: errors.New("fake srv open error"), serviceName: "Fake service 1"},
+		},
+	},
 }
 
 func TestMgrErrors(t *testing.T) {
@@ -185,10 +209,13 @@ func TestServiceErrors(t *testing.T) {
 }
 
 var testSimpleData = []testData{
-	{[]string{"Service 1", "Service 2"}, nil, nil, []serviceTestInfo{
-		{nil, nil, nil, "Service 1", "Fake service 1", 1, 2},
-		{nil, nil, nil, "Service 2", "Fake service 2", 1, 2},
-	}},
+	{
+		queryServiceList: []string{"Service 1", "Service 2"},
+		services: []serviceTestInfo{
+			{serviceName: "Service 1", displayName: "Fake service 1", state: 1, startUpMode: 2},
+			{serviceName: "Service 2", displayName: "Fake service 2", state: 1, startUpMode: 2},
+		},
+	},
 }
 
 func TestGatherContainsTag(t *testing.T) {
@@ -238,3 +265,221 @@ func TestExcludingNamesTag(t *testing.T) {
 		acc1.AssertDoesNotContainsTaggedFields(t, "win_services", fields, tags)
 	}
 }
+
+var testExtendedData = testData{
+	queryServiceList: []string{"App", "Database", "Network", "Other"},
+	services: []serviceTestInfo{
+		{
+			serviceName:      "App",
+			displayName:      "Application Server",
+			state:            4,
+			startUpMode:      2,
+			delayedAutoStart: true,
+			dependencies:     []string{"Database", "+NetworkProvider"},
+			recoveryActions: []mgr.RecoveryAction{
+				{Type: mgr.ServiceRestart, Delay: time.Minute},
+				{Type: mgr.ServiceRestart, Delay: time.Minute},
+				{Type: mgr.NoAction},
+			},
+			resetPeriod: 86400,
+		},
+		{
+			serviceName:  "Database",
+			displayName:  "Database Engine",
+			state:        4,
+			startUpMode:  2,
+			dependencies: []string{"Network"},
+		},
+		{
+			serviceName:  "Network",
+			displayName:  "Network Service",
+			state:        1,
+			startUpMode:  3,
+			dependencies: []string{"Database"},
+		},
+		{
+			serviceName: "Other",
+			displayName: "Other Service",
+			state:       1,
+			startUpMode: 4,
+		},
+	},
+}
+
+type fakeEventProvider struct {
+	events [][]byte
+}
+
+func (p *fakeEventProvider) query(time.Duration) ([][]byte, error) {
+	return p.events, nil
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &WinServices{Collect: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' value "foo"`)
+
+	plugin = &WinServices{Collect: []string{"restarts"}}
+	require.ErrorContains(t, plugin.Init(), "event log window must be positive")
+
+	plugin = &WinServices{ExpectedStartupModes: []expectedStartupMode{{Services: []string{"App"}, Mode: "manual"}}}
+	require.ErrorContains(t, plugin.Init(), `invalid startup mode "manual"`)
+
+	plugin = &WinServices{ExpectedStartupModes: []expectedStartupMode{{Mode: "auto"}}}
+	require.ErrorContains(t, plugin.Init(), `no services given for expected startup mode "auto"`)
+}
+
+func TestDisplayNames(t *testing.T) {
+	plugin := &WinServices{
+		ServiceNames:         []string{"network"},
+		DisplayNames:         []string{"* server", "* engine"},
+		ServiceNamesExcluded: []string{"database"},
+		Log:                  testutil.Logger{},
+		mgrProvider:          &FakeMgProvider{testExtendedData},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	var names []string
+	for _, m := range acc.GetTelegrafMetrics() {
+		name, _ := m.GetTag("service_name")
+		names = append(names, name)
+	}
+	require.ElementsMatch(t, []string{"App", "Network"}, names)
+}
+
+func TestStartupModeDrift(t *testing.T) {
+	plugin := &WinServices{
+		ExpectedStartupModes: []expectedStartupMode{
+			{Services: []string{"app"}, Mode: "auto"},
+			{Services: []string{"Data*", "Network"}, Mode: "auto"},
+			{Services: []string{"*"}, Mode: "disabled"},
+		},
+		Log:         testutil.Logger{},
+		mgrProvider: &FakeMgProvider{testExtendedData},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := map[string]bool{
+		"App":      true, // delayed instead of immediate automatic start
+		"Database": false,
+		"Network":  true,
+		"Other":    false,
+	}
+	for _, m := range acc.GetTelegrafMetrics() {
+		name, _ := m.GetTag("service_name")
+		drift, found := m.GetField("startup_mode_drift")
+		require.Truef(t, found, "no drift for %q", name)
+		require.Equalf(t, expected[name], drift, "wrong drift for %q", name)
+	}
+}
+
+func TestDependenciesAndRecovery(t *testing.T) {
+	plugin := &WinServices{
+		ServiceNames: []string{"App", "Other"},
+		Collect:      []string{"dependencies", "recovery"},
+		Log:          testutil.Logger{},
+		mgrProvider:  &FakeMgProvider{testExtendedData},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// The load-order group is skipped and the cycle between the database and
+	// network service must be handled
+	acc.AssertContainsTaggedFields(t, "win_services",
+		map[string]interface{}{
+			"state":                    4,
+			"startup_mode":             2,
+			"dependencies":             2,
+			"dependencies_not_running": 1,
+			"recovery_actions":         2,
+			"recovery_reset_period":    int64(86400),
+		},
+		map[string]string{"service_name": "App", "display_name": "Application Server"},
+	)
+	acc.AssertContainsTaggedFields(t, "win_services",
+		map[string]interface{}{
+			"state":                    1,
+			"startup_mode":             4,
+			"dependencies":             0,
+			"dependencies_not_running": 0,
+			"recovery_actions":         0,
+			"recovery_reset_period":    int64(0),
+		},
+		map[string]string{"service_name": "Other", "display_name": "Other Service"},
+	)
+}
+
+func TestRestarts(t *testing.T) {
+	events := [][]byte{
+		stateChangeEvent("Application Server", "running", "App/4"),
+		terminationEvent(eventTerminated, "Application Server"),
+		stateChangeEvent("Application Server", "stopped", "App/1"),
+		stateChangeEvent("Application Server", "running", "App/4"),
+		terminationEvent(eventTerminatedWithAction, "application server"),
+		stateChangeEvent("Database Engine", "running", "Database/4"),
+		[]byte("<Event><invalid"),
+	}
+
+	plugin := &WinServices{
+		ServiceNames:   []string{"App", "Database", "Network"},
+		Collect:        []string{"restarts"},
+		EventLogWindow: config.Duration(time.Hour),
+		Log:            testutil.Logger{},
+		mgrProvider:    &FakeMgProvider{testExtendedData},
+		eventProvider:  &fakeEventProvider{events: events},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := map[string][2]int{
+		"App":      {2, 2},
+		"Database": {1, 0},
+		"Network":  {0, 0},
+	}
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, len(expected))
+	for _, m := range metrics {
+		name, _ := m.GetTag("service_name")
+		restarts, found := m.GetField("restarts")
+		require.True(t, found)
+		terminations, found := m.GetField("unexpected_terminations")
+		require.True(t, found)
+		require.Equalf(t, int64(expected[name][0]), restarts, "wrong restarts for %q", name)
+		require.Equalf(t, int64(expected[name][1]), terminations, "wrong terminations for %q", name)
+	}
+}
+
+func stateChangeEvent(displayName, state, binary string) []byte {
+	u16 := utf16.Encode([]rune(binary + "\x00"))
+	buf := make([]byte, 0, 2*len(u16))
+	for _, c := range u16 {
+		buf = append(buf, byte(c), byte(c>>8))
+	}
+
+	return []byte(fmt.Sprintf(`<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>`+
+		`<System><Provider Name='Service Control Manager'/><EventID Qualifiers='16384'>%d</EventID></System>`+
+		`<EventData><Data Name='param1'>%s</Data><Data Name='param2'>%s</Data><Binary>%s</Binary></EventData>`+
+		`</Event>`,
+		eventStateChanged, displayName, state, strings.ToUpper(hex.EncodeToString(buf)),
+	))
+}
+
+func terminationEvent(id int, displayName string) []byte {
+	return []byte(fmt.Sprintf(`<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>`+
+		`<System><Provider Name='Service Control Manager'/><EventID Qualifiers='49152'>%d</EventID></System>`+
+		`<EventData><Data Name='param1'>%s</Data><Data Name='param2'>1</Data></EventData>`+
+		`</Event>`,
+		id, displayName,
+	))
+}
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.azure_data_lake
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/azure_data_lake" // register plugin
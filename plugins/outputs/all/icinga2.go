@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.icinga2
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/icinga2" // register plugin
@@ -47,6 +47,13 @@ type AMQP struct {
 	ExchangePassive    bool              `toml:"exchange_passive"`
 	ExchangeDurability string            `toml:"exchange_durability"`
 	ExchangeArguments  map[string]string `toml:"exchange_arguments"`
+	Queue              string            `toml:"queue"`
+	QueueDurability    string            `toml:"queue_durability"`
+	QueueBindingKeys   []string          `toml:"queue_binding_keys"`
+	PublisherConfirms  bool              `toml:"publisher_confirms"`
+	MaxInFlight        int               `toml:"max_in_flight"`
+	Mandatory          bool              `toml:"mandatory"`
+	AlternateExchange  string            `toml:"alternate_exchange"`
 	Username           config.Secret     `toml:"username"`
 	Password           config.Secret     `toml:"password"`
 	MaxMessages        int               `toml:"max_messages"`
@@ -72,6 +79,7 @@ type AMQP struct {
 
 type Client interface {
 	Publish(key string, body []byte) error
+	Confirm() ([]bool, error)
 	Close() error
 }
 
@@ -84,6 +92,16 @@ func (q *AMQP) SetSerializer(serializer telegraf.Serializer) {
 }
 
 func (q *AMQP) Init() error {
+	if q.MaxInFlight <= 0 {
+		q.MaxInFlight = 100
+	}
+	if q.Mandatory && !q.PublisherConfirms {
+		return errors.New("'mandatory' requires 'publisher_confirms' to be enabled")
+	}
+	if q.AlternateExchange != "" && !q.Mandatory {
+		return errors.New("'alternate_exchange' requires 'mandatory' to be enabled")
+	}
+
 	var err error
 	q.config, err = q.makeClientConfig()
 	if err != nil {
@@ -123,21 +141,31 @@ func (q *AMQP) routingKey(metric telegraf.Metric) string {
 
 func (q *AMQP) Write(metrics []telegraf.Metric) error {
 	batches := make(map[string][]telegraf.Metric)
+	indices := make(map[string][]int)
 	if q.ExchangeType == "header" {
 		// Since the routing_key is ignored for this exchange type send as a
 		// single batch.
 		batches[""] = metrics
+		indices[""] = make([]int, 0, len(metrics))
+		for i := range metrics {
+			indices[""] = append(indices[""], i)
+		}
 	} else {
-		for _, metric := range metrics {
+		for i, metric := range metrics {
 			routingKey := q.routingKey(metric)
 			if _, ok := batches[routingKey]; !ok {
 				batches[routingKey] = make([]telegraf.Metric, 0)
 			}
 
 			batches[routingKey] = append(batches[routingKey], metric)
+			indices[routingKey] = append(indices[routingKey], i)
 		}
 	}
 
+	// Keep the metric indices of each published message for mapping the
+	// publisher confirms back to the metrics
+	published := make([][]int, 0, len(batches))
+
 	first := true
 	for key, metrics := range batches {
 		body, err := q.serialize(metrics)
@@ -170,9 +198,22 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 				return err
 			}
 		}
+		published = append(published, indices[key])
 		first = false
 	}
 
+	if q.client == nil {
+		return nil
+	}
+	results, err := q.client.Confirm()
+	if err != nil {
+		if err := q.client.Close(); err != nil {
+			q.Log.Errorf("Closing connection failed: %v", err)
+		}
+		q.client = nil
+		return err
+	}
+
 	if q.sentMessages >= q.MaxMessages && q.MaxMessages > 0 {
 		q.Log.Debug("Sent MaxMessages; closing connection")
 		if err := q.client.Close(); err != nil {
@@ -181,6 +222,27 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 		q.client = nil
 	}
 
+	// Only keep the metrics of messages not acknowledged by the broker for
+	// retrying without resending the acknowledged ones
+	if results == nil {
+		return nil
+	}
+	accepted := make([]int, 0, len(metrics))
+	var nacked int
+	for i, acked := range results {
+		if acked {
+			accepted = append(accepted, published[i]...)
+		} else {
+			nacked++
+		}
+	}
+	if nacked > 0 {
+		return &internal.PartialWriteError{
+			Err:           fmt.Errorf("%d of %d messages not acknowledged by the broker", nacked, len(results)),
+			MetricsAccept: accepted,
+		}
+	}
+
 	return nil
 }
 
@@ -222,12 +284,19 @@ func (q *AMQP) serialize(metrics []telegraf.Metric) ([]byte, error) {
 
 func (q *AMQP) makeClientConfig() (*ClientConfig, error) {
 	clientConfig := &ClientConfig{
-		exchange:        q.Exchange,
-		exchangeType:    q.ExchangeType,
-		exchangePassive: q.ExchangePassive,
-		encoding:        q.ContentEncoding,
-		timeout:         time.Duration(q.Timeout),
-		log:             q.Log,
+		exchange:          q.Exchange,
+		exchangeType:      q.ExchangeType,
+		exchangePassive:   q.ExchangePassive,
+		queue:             q.Queue,
+		queueDurable:      q.QueueDurability != "transient",
+		queueBindingKeys:  q.QueueBindingKeys,
+		confirms:          q.PublisherConfirms,
+		maxInFlight:       q.MaxInFlight,
+		mandatory:         q.Mandatory,
+		alternateExchange: q.AlternateExchange,
+		encoding:          q.ContentEncoding,
+		timeout:           time.Duration(q.Timeout),
+		log:               q.Log,
 	}
 
 	switch q.ExchangeDurability {
@@ -7,22 +7,37 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 type MockClient struct {
 	PublishF func() error
+	ConfirmF func() ([]bool, error)
 	CloseF   func() error
 
 	PublishCallCount int
 	CloseCallCount   int
+	PublishedKeys    []string
 }
 
-func (c *MockClient) Publish(string, []byte) error {
+func (c *MockClient) Publish(key string, _ []byte) error {
 	c.PublishCallCount++
+	c.PublishedKeys = append(c.PublishedKeys, key)
 	return c.PublishF()
 }
 
+func (c *MockClient) Confirm() ([]bool, error) {
+	if c.ConfirmF == nil {
+		return nil, nil
+	}
+	return c.ConfirmF()
+}
+
 func (c *MockClient) Close() error {
 	c.CloseCallCount++
 	return c.CloseF()
@@ -158,3 +173,56 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+func TestInitConfirmOptionsFail(t *testing.T) {
+	plugin := &AMQP{Mandatory: true}
+	require.ErrorContains(t, plugin.Init(), "'mandatory' requires 'publisher_confirms'")
+
+	plugin = &AMQP{PublisherConfirms: true, AlternateExchange: "unroutable"}
+	require.ErrorContains(t, plugin.Init(), "'alternate_exchange' requires 'mandatory'")
+}
+
+func TestWritePartiallyConfirmed(t *testing.T) {
+	client := &MockClient{
+		PublishF: func() error { return nil },
+		CloseF:   func() error { return nil },
+	}
+	// Reject the message published for routing key "b"
+	client.ConfirmF = func() ([]bool, error) {
+		results := make([]bool, 0, len(client.PublishedKeys))
+		for _, key := range client.PublishedKeys {
+			results = append(results, key != "b")
+		}
+		return results, nil
+	}
+
+	plugin := &AMQP{
+		RoutingTag:        "key",
+		PublisherConfirms: true,
+		Log:               &testutil.Logger{},
+		connect: func(*ClientConfig) (Client, error) {
+			return client, nil
+		},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 100, plugin.config.maxInFlight)
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{"key": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"key": "b"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"key": "a"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"key": "b"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+	}
+
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorContains(t, err, "1 of 2 messages not acknowledged by the broker")
+	require.ElementsMatch(t, []int{0, 2}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"strconv"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -22,6 +23,13 @@ type ClientConfig struct {
 	exchangePassive   bool
 	exchangeDurable   bool
 	exchangeArguments amqp.Table
+	queue             string
+	queueDurable      bool
+	queueBindingKeys  []string
+	confirms          bool
+	maxInFlight       int
+	mandatory         bool
+	alternateExchange string
 	encoding          string
 	headers           amqp.Table
 	deliveryMode      uint8
@@ -36,6 +44,22 @@ type client struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	config  *ClientConfig
+
+	// State for publisher confirms, all messages published since the last
+	// call to Confirm and the number of those messages already confirmed
+	published []*message
+	confirmed int
+	sequence  uint64
+	returns   chan amqp.Return
+	returned  []amqp.Return
+}
+
+type message struct {
+	id           string
+	key          string
+	body         []byte
+	confirmation *amqp.DeferredConfirmation
+	acked        bool
 }
 
 // newClient opens a connection to one of the brokers at random
@@ -79,6 +103,23 @@ func newClient(config *ClientConfig) (*client, error) {
 		return nil, err
 	}
 
+	err = client.DeclareQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.confirms {
+		if err := channel.Confirm(false); err != nil {
+			return nil, fmt.Errorf("enabling publisher confirms failed: %w", err)
+		}
+		if config.mandatory {
+			// Returns are sent before the confirmation of the message so
+			// with at most max_in_flight unconfirmed messages between two
+			// drains of the channel, the buffer size prevents blocking.
+			client.returns = channel.NotifyReturn(make(chan amqp.Return, config.maxInFlight+1))
+		}
+	}
+
 	return client, nil
 }
 
@@ -115,22 +156,195 @@ func (c *client) DeclareExchange() error {
 	return nil
 }
 
+func (c *client) DeclareQueue() error {
+	if c.config.queue == "" {
+		return nil
+	}
+
+	_, err := c.channel.QueueDeclare(
+		c.config.queue,
+		c.config.queueDurable,
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("error declaring queue: %w", err)
+	}
+
+	if c.config.exchange == "" {
+		return nil
+	}
+	for _, key := range c.config.queueBindingKeys {
+		if err := c.channel.QueueBind(c.config.queue, key, c.config.exchange, false, nil); err != nil {
+			return fmt.Errorf("error binding queue with key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
 func (c *client) Publish(key string, body []byte) error {
-	// Note that since the channel is not in confirm mode, the absence of
-	// an error does not indicate successful delivery.
-	return c.channel.PublishWithContext(
+	if !c.config.confirms {
+		// Note that since the channel is not in confirm mode, the absence of
+		// an error does not indicate successful delivery.
+		return c.channel.PublishWithContext(
+			context.Background(),
+			c.config.exchange, // exchange
+			key,               // routing key
+			false,             // mandatory
+			false,             // immediate
+			c.publishing("", body),
+		)
+	}
+
+	c.drainReturns()
+
+	// Wait for the oldest unconfirmed messages if the in-flight window is full
+	for len(c.published)-c.confirmed >= c.config.maxInFlight {
+		if err := c.waitOldest(); err != nil {
+			return err
+		}
+	}
+
+	c.sequence++
+	msg := &message{
+		id:   strconv.FormatUint(c.sequence, 10),
+		key:  key,
+		body: body,
+	}
+	confirmation, err := c.channel.PublishWithDeferredConfirmWithContext(
 		context.Background(),
-		c.config.exchange, // exchange
-		key,               // routing key
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			Headers:         c.config.headers,
-			ContentType:     "text/plain",
-			ContentEncoding: c.config.encoding,
-			Body:            body,
-			DeliveryMode:    c.config.deliveryMode,
-		})
+		c.config.exchange,
+		key,
+		c.config.mandatory,
+		false, // immediate
+		c.publishing(msg.id, body),
+	)
+	if err != nil {
+		return err
+	}
+	msg.confirmation = confirmation
+	c.published = append(c.published, msg)
+
+	return nil
+}
+
+// Confirm waits for the confirmation of all messages published since the last
+// call and returns whether the messages were acknowledged by the broker in
+// publishing order. If publisher confirms are disabled nil is returned.
+func (c *client) Confirm() ([]bool, error) {
+	if !c.config.confirms {
+		return nil, nil
+	}
+
+	for c.confirmed < len(c.published) {
+		if err := c.waitOldest(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle messages returned as unroutable by the broker
+	c.drainReturns()
+	if len(c.returned) > 0 {
+		byID := make(map[string]*message, len(c.published))
+		for _, msg := range c.published {
+			byID[msg.id] = msg
+		}
+		for _, ret := range c.returned {
+			msg, found := byID[ret.MessageId]
+			if !found {
+				continue
+			}
+			if c.config.alternateExchange == "" {
+				c.config.log.Warnf("Dropping message with routing key %q returned by broker: %s", msg.key, ret.ReplyText)
+				continue
+			}
+			acked, err := c.reroute(msg)
+			if err != nil {
+				return nil, err
+			}
+			msg.acked = acked
+		}
+		c.returned = c.returned[:0]
+	}
+
+	results := make([]bool, 0, len(c.published))
+	for _, msg := range c.published {
+		results = append(results, msg.acked)
+	}
+	c.published = c.published[:0]
+	c.confirmed = 0
+
+	return results, nil
+}
+
+func (c *client) publishing(id string, body []byte) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:         c.config.headers,
+		ContentType:     "text/plain",
+		ContentEncoding: c.config.encoding,
+		MessageId:       id,
+		Body:            body,
+		DeliveryMode:    c.config.deliveryMode,
+	}
+}
+
+func (c *client) waitOldest() error {
+	msg := c.published[c.confirmed]
+	acked, err := c.wait(msg.confirmation)
+	if err != nil {
+		return fmt.Errorf("waiting for confirmation failed: %w", err)
+	}
+	msg.acked = acked
+	c.confirmed++
+	return nil
+}
+
+func (c *client) drainReturns() {
+	if c.returns == nil {
+		return
+	}
+	for {
+		select {
+		case ret := <-c.returns:
+			c.returned = append(c.returned, ret)
+		default:
+			return
+		}
+	}
+}
+
+// reroute publishes a returned message to the alternate exchange and waits
+// for its confirmation
+func (c *client) reroute(msg *message) (bool, error) {
+	confirmation, err := c.channel.PublishWithDeferredConfirmWithContext(
+		context.Background(),
+		c.config.alternateExchange,
+		msg.key,
+		false, // mandatory
+		false, // immediate
+		c.publishing(msg.id, msg.body),
+	)
+	if err != nil {
+		return false, fmt.Errorf("publishing to alternate exchange failed: %w", err)
+	}
+
+	acked, err := c.wait(confirmation)
+	if err != nil {
+		return false, fmt.Errorf("waiting for confirmation of alternate exchange failed: %w", err)
+	}
+	return acked, nil
+}
+
+func (c *client) wait(confirmation *amqp.DeferredConfirmation) (bool, error) {
+	ctx := context.Background()
+	if c.config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.timeout)
+		defer cancel()
+	}
+	return confirmation.WaitContext(ctx)
 }
 
 func (c *client) Close() error {
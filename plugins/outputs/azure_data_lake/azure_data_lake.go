@@ -0,0 +1,301 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package azure_data_lake
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type uploader interface {
+	upload(ctx context.Context, name string, data []byte) error
+}
+
+type AzureDataLake struct {
+	Endpoint        string          `toml:"endpoint"`
+	Container       string          `toml:"container"`
+	PathPrefix      string          `toml:"path_prefix"`
+	PartitionBy     string          `toml:"partition_by"`
+	FileMaxSize     config.Size     `toml:"file_max_size"`
+	FileMaxAge      config.Duration `toml:"file_max_age"`
+	MaxBufferedSize config.Size     `toml:"max_buffered_size"`
+	TimestampColumn string          `toml:"timestamp_column"`
+	TenantID        string          `toml:"tenant_id"`
+	ClientID        string          `toml:"client_id"`
+	ClientSecret    config.Secret   `toml:"client_secret"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+
+	uploader uploader
+	files    map[string]*file
+	buffered int64
+}
+
+// file contains the rows buffered for a partition of a measurement until
+// the file is finalized
+type file struct {
+	dir     string
+	name    string
+	rows    []row
+	size    int64
+	created time.Time
+}
+
+func (*AzureDataLake) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AzureDataLake) Init() error {
+	if a.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	if _, err := url.Parse(a.Endpoint); err != nil {
+		return fmt.Errorf("parsing endpoint failed: %w", err)
+	}
+	if a.Container == "" {
+		return errors.New("container must not be empty")
+	}
+	a.PathPrefix = strings.Trim(a.PathPrefix, "/")
+
+	switch a.PartitionBy {
+	case "":
+		a.PartitionBy = "day"
+	case "none", "day", "hour":
+	default:
+		return fmt.Errorf("invalid partition_by %q", a.PartitionBy)
+	}
+	if a.FileMaxSize <= 0 {
+		return errors.New("file_max_size must be positive")
+	}
+	if a.FileMaxAge <= 0 {
+		return errors.New("file_max_age must be positive")
+	}
+	if a.MaxBufferedSize < a.FileMaxSize {
+		return errors.New("max_buffered_size must not be less than file_max_size")
+	}
+	if a.TimestampColumn == "" {
+		a.TimestampColumn = "time"
+	}
+	if (a.ClientID == "") != a.ClientSecret.Empty() {
+		return errors.New("client_id and client_secret must be set together")
+	}
+
+	a.files = make(map[string]*file)
+
+	return nil
+}
+
+func (a *AzureDataLake) Connect() error {
+	if a.uploader != nil {
+		return nil
+	}
+
+	var cred azcore.TokenCredential
+	if a.ClientID != "" {
+		secret, err := a.ClientSecret.Get()
+		if err != nil {
+			return fmt.Errorf("getting client secret failed: %w", err)
+		}
+		c, err := azidentity.NewClientSecretCredential(a.TenantID, a.ClientID, secret.String(), nil)
+		secret.Destroy()
+		if err != nil {
+			return fmt.Errorf("creating client secret credential failed: %w", err)
+		}
+		cred = c
+	} else {
+		c, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: a.TenantID})
+		if err != nil {
+			return fmt.Errorf("creating default credential failed: %w", err)
+		}
+		cred = c
+	}
+
+	client, err := azblob.NewClient(a.Endpoint, cred, nil)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	a.uploader = &blobUploader{client: client, container: a.Container}
+
+	return nil
+}
+
+func (a *AzureDataLake) Close() error {
+	err := a.finalize(time.Now(), true)
+	if err != nil {
+		var dropped int
+		for _, f := range a.files {
+			dropped += len(f.rows)
+		}
+		a.Log.Errorf("Dropping %d buffered metrics in %d files as uploading failed", dropped, len(a.files))
+	}
+	return err
+}
+
+func (a *AzureDataLake) Write(metrics []telegraf.Metric) error {
+	now := time.Now()
+	accepted := make([]int, 0, len(metrics))
+	var flushed bool
+	var errs []error
+	for i, m := range metrics {
+		r := row{
+			timestamp: m.Time(),
+			tags:      m.Tags(),
+			fields:    m.Fields(),
+		}
+		size := r.size()
+
+		// Upload all files once the buffer is full to make room for new
+		// metrics and leave the metrics still exceeding the limit to the
+		// agent's buffer
+		if a.buffered+size > int64(a.MaxBufferedSize) {
+			if !flushed {
+				errs = append(errs, a.finalize(now, true))
+				flushed = true
+			}
+			if a.buffered+size > int64(a.MaxBufferedSize) {
+				continue
+			}
+		}
+		dir := a.directory(m.Name(), m.Time())
+		f, found := a.files[dir]
+		if !found {
+			f = &file{
+				dir:     dir,
+				name:    fileName(m.Name(), now),
+				created: now,
+			}
+			a.files[dir] = f
+		}
+		f.rows = append(f.rows, r)
+		f.size += size
+		a.buffered += size
+		accepted = append(accepted, i)
+	}
+
+	errs = append(errs, a.finalize(now, false))
+	if len(accepted) < len(metrics) {
+		errs = append(errs, fmt.Errorf("buffer limit reached, keeping %d metrics for the next write", len(metrics)-len(accepted)))
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		return nil
+	}
+
+	// The accepted metrics are kept in the file buffers until the upload
+	// succeeds, so accept them even if finalizing a file failed to avoid
+	// writing duplicates on the next write.
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accepted,
+	}
+}
+
+// finalize encodes and uploads the files exceeding the maximum size or age
+// or all files if forced. Files failing to upload are kept for the next try.
+func (a *AzureDataLake) finalize(now time.Time, force bool) error {
+	dirs := make([]string, 0, len(a.files))
+	for dir, f := range a.files {
+		if force || f.size >= int64(a.FileMaxSize) || now.Sub(f.created) >= time.Duration(a.FileMaxAge) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	var errs []error
+	for _, dir := range dirs {
+		f := a.files[dir]
+		data, mismatches, err := encodeParquet(f.rows, a.TimestampColumn)
+		if err != nil {
+			// The data cannot be encoded so retrying is pointless
+			a.Log.Errorf("Encoding %d metrics for %q failed, dropping them: %v", len(f.rows), dir, err)
+			a.buffered -= f.size
+			delete(a.files, dir)
+			continue
+		}
+		if mismatches > 0 {
+			a.Log.Warnf("Stored %d field values with mismatching type as null in %q", mismatches, dir)
+		}
+
+		name := path.Join(f.dir, f.name)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
+		err = a.uploader.upload(ctx, name, data)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("uploading %q failed: %w", name, err))
+			continue
+		}
+		a.Log.Debugf("Uploaded %d metrics in %d bytes to %q", len(f.rows), len(data), name)
+		a.buffered -= f.size
+		delete(a.files, dir)
+	}
+
+	return errors.Join(errs...)
+}
+
+// directory returns the path of the partition the metric belongs to within
+// the container
+func (a *AzureDataLake) directory(name string, t time.Time) string {
+	elements := []string{a.PathPrefix, strings.ReplaceAll(name, "/", "_")}
+	t = t.UTC()
+	switch a.PartitionBy {
+	case "day":
+		elements = append(elements, "date="+t.Format("2006-01-02"))
+	case "hour":
+		elements = append(elements, "date="+t.Format("2006-01-02"), "hour="+t.Format("15"))
+	}
+	return path.Join(elements...)
+}
+
+// fileName returns a unique name for a file created at the given time, the
+// random suffix avoids collisions between multiple Telegraf instances
+// writing to the same location
+func fileName(name string, created time.Time) string {
+	suffix, err := internal.RandomString(8)
+	if err != nil {
+		suffix = "0"
+	}
+	name = strings.ReplaceAll(name, "/", "_")
+	return name + "_" + strconv.FormatInt(created.UnixNano(), 10) + "_" + suffix + ".parquet"
+}
+
+type blobUploader struct {
+	client    *azblob.Client
+	container string
+}
+
+func (u *blobUploader) upload(ctx context.Context, name string, data []byte) error {
+	_, err := u.client.UploadBuffer(ctx, u.container, name, data, nil)
+	return err
+}
+
+func init() {
+	outputs.Add("azure_data_lake", func() telegraf.Output {
+		return &AzureDataLake{
+			PartitionBy:     "day",
+			FileMaxSize:     config.Size(64 * 1024 * 1024),
+			FileMaxAge:      config.Duration(5 * time.Minute),
+			MaxBufferedSize: config.Size(256 * 1024 * 1024),
+			TimestampColumn: "time",
+			Timeout:         config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,330 @@
+package azure_data_lake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	parquet_file "github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeUploader struct {
+	files map[string][]byte
+	err   error
+}
+
+func (u *fakeUploader) upload(_ context.Context, name string, data []byte) error {
+	if u.err != nil {
+		return u.err
+	}
+	u.files[name] = data
+	return nil
+}
+
+func newPlugin(t *testing.T) (*AzureDataLake, *fakeUploader) {
+	t.Helper()
+
+	plugin := &AzureDataLake{
+		Endpoint:    "https://myaccount.blob.core.windows.net",
+		Container:   "telegraf",
+		PathPrefix:  "/metrics/",
+		FileMaxSize: config.Size(1024 * 1024),
+		FileMaxAge:      config.Duration(time.Hour),
+		MaxBufferedSize: config.Size(1024 * 1024),
+		Timeout:         config.Duration(time.Second),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	up := &fakeUploader{files: make(map[string][]byte)}
+	plugin.uploader = up
+	require.NoError(t, plugin.Connect())
+
+	return plugin, up
+}
+
+// readParquet returns the schema and the rows of the file as values of the
+// columns in the order of the schema
+func readParquet(t *testing.T, data []byte) (*arrow.Schema, [][]interface{}) {
+	t.Helper()
+
+	reader, err := parquet_file.NewParquetReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	fileReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	require.NoError(t, err)
+	table, err := fileReader.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer table.Release()
+
+	rows := make([][]interface{}, table.NumRows())
+	for i := range rows {
+		rows[i] = make([]interface{}, table.NumCols())
+	}
+	for c := 0; c < int(table.NumCols()); c++ {
+		var offset int
+		for _, chunk := range table.Column(c).Data().Chunks() {
+			for i := 0; i < chunk.Len(); i++ {
+				rows[offset+i][c] = chunk.GetOneForMarshal(i)
+			}
+			offset += chunk.Len()
+		}
+	}
+	return table.Schema(), rows
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AzureDataLake
+		expected string
+	}{
+		{
+			name:     "no endpoint",
+			plugin:   &AzureDataLake{Container: "telegraf"},
+			expected: "endpoint must not be empty",
+		},
+		{
+			name:     "no container",
+			plugin:   &AzureDataLake{Endpoint: "https://onelake.blob.fabric.microsoft.com"},
+			expected: "container must not be empty",
+		},
+		{
+			name: "invalid partitioning",
+			plugin: &AzureDataLake{
+				Endpoint:    "https://onelake.blob.fabric.microsoft.com",
+				Container:   "workspace",
+				PartitionBy: "month",
+			},
+			expected: `invalid partition_by "month"`,
+		},
+		{
+			name: "no file size",
+			plugin: &AzureDataLake{
+				Endpoint:   "https://onelake.blob.fabric.microsoft.com",
+				Container:  "workspace",
+				FileMaxAge: config.Duration(time.Minute),
+			},
+			expected: "file_max_size must be positive",
+		},
+		{
+			name: "buffer smaller than file",
+			plugin: &AzureDataLake{
+				Endpoint:        "https://onelake.blob.fabric.microsoft.com",
+				Container:       "workspace",
+				FileMaxSize:     config.Size(1024),
+				FileMaxAge:      config.Duration(time.Minute),
+				MaxBufferedSize: config.Size(512),
+			},
+			expected: "max_buffered_size must not be less than file_max_size",
+		},
+		{
+			name: "client id without secret",
+			plugin: &AzureDataLake{
+				Endpoint:        "https://onelake.blob.fabric.microsoft.com",
+				Container:       "workspace",
+				FileMaxSize:     config.Size(1024),
+				FileMaxAge:      config.Duration(time.Minute),
+				MaxBufferedSize: config.Size(1024),
+				ClientID:        "00000000-0000-0000-0000-000000000000",
+			},
+			expected: "client_id and client_secret must be set together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 17, 30, 0, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		partitionBy string
+		prefix      string
+		expected    string
+	}{
+		{partitionBy: "none", prefix: "", expected: "cpu"},
+		{partitionBy: "day", prefix: "lakehouse.Lakehouse/Files/telegraf", expected: "lakehouse.Lakehouse/Files/telegraf/cpu/date=2024-03-05"},
+		{partitionBy: "hour", prefix: "telegraf", expected: "telegraf/cpu/date=2024-03-05/hour=16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.partitionBy, func(t *testing.T) {
+			plugin := &AzureDataLake{PathPrefix: tt.prefix, PartitionBy: tt.partitionBy}
+			require.Equal(t, tt.expected, plugin.directory("cpu", ts))
+		})
+	}
+
+	plugin := &AzureDataLake{PartitionBy: "none"}
+	require.Equal(t, "a_b", plugin.directory("a/b", ts))
+}
+
+func TestWriteFinalizeOnClose(t *testing.T) {
+	plugin, up := newPlugin(t)
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 42.5, "cores": int64(4)},
+			time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b", "region": "eu"},
+			map[string]interface{}{"usage": 12.0, "cores": "eight", "up": true},
+			time.Date(2024, 3, 5, 10, 0, 10, 0, time.UTC),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"free": uint64(1024)},
+			time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Empty(t, up.files)
+
+	require.NoError(t, plugin.Close())
+	require.Len(t, up.files, 2)
+
+	var cpu, mem []byte
+	for name, data := range up.files {
+		require.True(t, strings.HasSuffix(name, ".parquet"), name)
+		switch path.Dir(name) {
+		case "metrics/cpu/date=2024-03-05":
+			cpu = data
+		case "metrics/mem/date=2024-03-06":
+			mem = data
+		default:
+			require.Failf(t, "unexpected file", "%q", name)
+		}
+	}
+
+	schema, rows := readParquet(t, cpu)
+	columns := make([]string, 0, schema.NumFields())
+	for _, f := range schema.Fields() {
+		columns = append(columns, f.Name)
+	}
+	require.Equal(t, []string{"time", "host", "region", "cores", "up", "usage"}, columns)
+	require.Equal(t, "timestamp[us, tz=UTC]", schema.Field(0).Type.String())
+	require.Equal(t, [][]interface{}{
+		{"2024-03-05 10:00:00Z", "a", nil, int64(4), nil, 42.5},
+		{"2024-03-05 10:00:10Z", "b", "eu", nil, true, 12.0},
+	}, rows)
+
+	_, rows = readParquet(t, mem)
+	require.Equal(t, [][]interface{}{{"2024-03-06 00:00:00Z", "a", uint64(1024)}}, rows)
+}
+
+func TestWriteFinalizeBySize(t *testing.T) {
+	plugin, up := newPlugin(t)
+	plugin.FileMaxSize = config.Size(100)
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}, time.Now())
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Empty(t, up.files)
+
+	metrics := make([]telegraf.Metric, 0, 10)
+	for range 10 {
+		metrics = append(metrics, m)
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, up.files, 1)
+	require.Empty(t, plugin.files)
+
+	for _, data := range up.files {
+		_, rows := readParquet(t, data)
+		require.Len(t, rows, 11)
+	}
+}
+
+func TestWriteFinalizeByAge(t *testing.T) {
+	plugin, up := newPlugin(t)
+
+	m := metric.New("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Now())
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Empty(t, up.files)
+
+	for _, f := range plugin.files {
+		f.created = f.created.Add(-time.Hour)
+	}
+	require.NoError(t, plugin.Write(nil))
+	require.Len(t, up.files, 1)
+}
+
+func TestWriteUploadFailure(t *testing.T) {
+	plugin, up := newPlugin(t)
+	plugin.FileMaxSize = config.Size(1)
+	up.err = errors.New("service unavailable")
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Now()),
+		metric.New("cpu", nil, map[string]interface{}{"usage": 2.0}, time.Now()),
+	}
+
+	// The metrics must be accepted as they are kept for the next upload
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "service unavailable")
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0, 1}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+	require.Len(t, plugin.files, 1)
+
+	up.err = nil
+	require.NoError(t, plugin.Write(metrics[:1]))
+	require.Len(t, up.files, 1)
+	require.Empty(t, plugin.files)
+	for _, data := range up.files {
+		_, rows := readParquet(t, data)
+		require.Len(t, rows, 3)
+	}
+}
+
+func TestWriteBufferLimit(t *testing.T) {
+	plugin, up := newPlugin(t)
+	up.err = errors.New("service unavailable")
+
+	m := metric.New("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Now())
+	r := row{timestamp: m.Time(), tags: m.Tags(), fields: m.Fields()}
+	size := r.size()
+	plugin.FileMaxSize = config.Size(10 * size)
+	plugin.MaxBufferedSize = config.Size(3 * size)
+
+	// Metrics exceeding the limit must neither be accepted nor rejected to
+	// keep them in the agent's buffer
+	metrics := []telegraf.Metric{m, m, m, m, m}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "buffer limit reached")
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0, 1, 2}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+
+	// Uploads are forced once the buffer is full to make room
+	up.err = nil
+	require.NoError(t, plugin.Write(metrics[3:]))
+	require.Len(t, up.files, 1)
+	require.Len(t, plugin.files, 1)
+	require.NoError(t, plugin.Close())
+	require.Len(t, up.files, 2)
+	require.Zero(t, plugin.buffered)
+}
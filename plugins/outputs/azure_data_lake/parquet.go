@@ -0,0 +1,183 @@
+package azure_data_lake
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// row contains the data of a single metric buffered for a file
+type row struct {
+	timestamp time.Time
+	tags      map[string]string
+	fields    map[string]interface{}
+}
+
+// size returns an estimate of the uncompressed size of the row in bytes
+func (r *row) size() int64 {
+	n := 8
+	for k, v := range r.tags {
+		n += len(k) + len(v)
+	}
+	for k, v := range r.fields {
+		n += len(k) + 8
+		if s, ok := v.(string); ok {
+			n += len(s)
+		}
+	}
+	return int64(n)
+}
+
+// encodeParquet returns a Parquet file containing the rows with the timestamp
+// as first column followed by the tags and fields in alphabetical order.
+// Tags and fields missing in a row are stored as null. The type of a field
+// column is determined by the first value of the field and values of a
+// different type are stored as null as well.
+func encodeParquet(rows []row, timestampColumn string) ([]byte, int, error) {
+	tagTypes := make(map[string]arrow.DataType)
+	fieldTypes := make(map[string]arrow.DataType)
+	for _, r := range rows {
+		for k := range r.tags {
+			tagTypes[k] = arrow.BinaryTypes.String
+		}
+		for k, v := range r.fields {
+			if _, found := fieldTypes[k]; found {
+				continue
+			}
+			if _, found := tagTypes[k]; found || k == timestampColumn {
+				continue
+			}
+			if t := arrowType(v); t != nil {
+				fieldTypes[k] = t
+			}
+		}
+	}
+	// A field might have been seen before a tag of the same name
+	for k := range tagTypes {
+		delete(fieldTypes, k)
+	}
+	delete(tagTypes, timestampColumn)
+
+	columns := []arrow.Field{{Name: timestampColumn, Type: arrow.FixedWidthTypes.Timestamp_us}}
+	columns = append(columns, sortedColumns(tagTypes)...)
+	ntags := len(tagTypes)
+	columns = append(columns, sortedColumns(fieldTypes)...)
+	schema := arrow.NewSchema(columns, nil)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+
+	var mismatches int
+	for _, r := range rows {
+		ts, err := arrow.TimestampFromTime(r.timestamp, arrow.Microsecond)
+		if err != nil {
+			return nil, 0, fmt.Errorf("converting timestamp failed: %w", err)
+		}
+		builder.Field(0).(*array.TimestampBuilder).Append(ts)
+
+		for i, column := range columns[1:] {
+			fb := builder.Field(i + 1)
+			if i < ntags {
+				if v, found := r.tags[column.Name]; found {
+					fb.(*array.StringBuilder).Append(v)
+				} else {
+					fb.AppendNull()
+				}
+				continue
+			}
+			v, found := r.fields[column.Name]
+			if !found {
+				fb.AppendNull()
+				continue
+			}
+			if !appendValue(fb, v) {
+				fb.AppendNull()
+				mismatches++
+			}
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	writer, err := pqarrow.NewFileWriter(schema, &buf, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating writer failed: %w", err)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, 0, fmt.Errorf("writing record failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, 0, fmt.Errorf("closing writer failed: %w", err)
+	}
+
+	return buf.Bytes(), mismatches, nil
+}
+
+func sortedColumns(types map[string]arrow.DataType) []arrow.Field {
+	columns := make([]arrow.Field, 0, len(types))
+	for name, t := range types {
+		columns = append(columns, arrow.Field{Name: name, Type: t, Nullable: true})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return columns
+}
+
+func arrowType(v interface{}) arrow.DataType {
+	switch v.(type) {
+	case int64:
+		return arrow.PrimitiveTypes.Int64
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case float64:
+		return arrow.PrimitiveTypes.Float64
+	case string:
+		return arrow.BinaryTypes.String
+	case bool:
+		return arrow.FixedWidthTypes.Boolean
+	}
+	return nil
+}
+
+// appendValue appends the value to the column and returns false if the type
+// of the value does not match the column
+func appendValue(b array.Builder, v interface{}) bool {
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		if v, ok := v.(int64); ok {
+			b.Append(v)
+			return true
+		}
+	case *array.Uint64Builder:
+		if v, ok := v.(uint64); ok {
+			b.Append(v)
+			return true
+		}
+	case *array.Float64Builder:
+		if v, ok := v.(float64); ok {
+			b.Append(v)
+			return true
+		}
+	case *array.StringBuilder:
+		if v, ok := v.(string); ok {
+			b.Append(v)
+			return true
+		}
+	case *array.BooleanBuilder:
+		if v, ok := v.(bool); ok {
+			b.Append(v)
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,16 +38,23 @@ type PubSub struct {
 	Base64Data            bool            `toml:"base64_data"`
 	ContentEncoding       string          `toml:"content_encoding"`
 
+	OrderingKeyTag         string `toml:"ordering_key_tag"`
+	UseTopicSchema         bool   `toml:"use_topic_schema"`
+	SchemaMeasurementField string `toml:"schema_measurement_field"`
+	SchemaTimestampField   string `toml:"schema_timestamp_field"`
+
 	Log telegraf.Logger `toml:"-"`
 
-	t topic
-	c *pubsub.Client
+	t          topic
+	c          *pubsub.Client
+	clientOpts []option.ClientOption
 
 	stubTopic func(id string) topic
 
 	serializer     telegraf.Serializer
 	publishResults []publishResult
 	encoder        internal.ContentEncoder
+	schema         schemaEncoder
 }
 
 func (*PubSub) SampleConfig() string {
@@ -58,10 +66,16 @@ func (ps *PubSub) SetSerializer(serializer telegraf.Serializer) {
 }
 
 func (ps *PubSub) Connect() error {
-	if ps.stubTopic == nil {
-		return ps.initPubSubClient()
+	if ps.stubTopic != nil {
+		return nil
 	}
 
+	if err := ps.initPubSubClient(); err != nil {
+		return err
+	}
+	if ps.UseTopicSchema {
+		return ps.loadTopicSchema()
+	}
 	return nil
 }
 
@@ -109,13 +123,12 @@ func (ps *PubSub) initPubSubClient() error {
 		}
 		credsOpt = option.WithCredentials(creds)
 	}
-	client, err := pubsub.NewClient(
-		context.Background(),
-		ps.Project,
+	ps.clientOpts = []option.ClientOption{
 		credsOpt,
 		option.WithScopes(pubsub.ScopeCloudPlatform),
 		option.WithUserAgent(internal.ProductToken()),
-	)
+	}
+	client, err := pubsub.NewClient(context.Background(), ps.Project, ps.clientOpts...)
 	if err != nil {
 		return fmt.Errorf("unable to generate PubSub client: %w", err)
 	}
@@ -123,6 +136,46 @@ func (ps *PubSub) initPubSubClient() error {
 	return nil
 }
 
+// loadTopicSchema retrieves the schema attached to the topic for encoding
+// the messages accordingly
+func (ps *PubSub) loadTopicSchema() error {
+	ctx := context.Background()
+
+	cfg, err := ps.c.Topic(ps.Topic).Config(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration of topic %q failed: %w", ps.Topic, err)
+	}
+	if cfg.SchemaSettings == nil || cfg.SchemaSettings.Schema == "" {
+		return fmt.Errorf("topic %q has no schema attached", ps.Topic)
+	}
+
+	// Schemas might belong to a different project than the topic
+	parts := strings.Split(cfg.SchemaSettings.Schema, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "schemas" {
+		return fmt.Errorf("invalid schema name %q", cfg.SchemaSettings.Schema)
+	}
+	project, id := parts[1], parts[3]
+
+	client, err := pubsub.NewSchemaClient(ctx, project, ps.clientOpts...)
+	if err != nil {
+		return fmt.Errorf("unable to generate schema client: %w", err)
+	}
+	defer client.Close()
+
+	schema, err := client.Schema(ctx, id, pubsub.SchemaViewFull)
+	if err != nil {
+		return fmt.Errorf("getting schema %q failed: %w", cfg.SchemaSettings.Schema, err)
+	}
+
+	ps.schema, err = newSchemaEncoder(schema, cfg.SchemaSettings.Encoding, ps.SchemaMeasurementField, ps.SchemaTimestampField)
+	if err != nil {
+		return fmt.Errorf("schema %q: %w", cfg.SchemaSettings.Schema, err)
+	}
+	ps.Log.Debugf("Using schema %q revision %q", schema.Name, schema.RevisionID)
+
+	return nil
+}
+
 func (ps *PubSub) refreshTopic() {
 	if ps.stubTopic != nil {
 		ps.t = ps.stubTopic(ps.Topic)
@@ -131,6 +184,7 @@ func (ps *PubSub) refreshTopic() {
 		ps.t = &topicWrapper{t}
 	}
 	ps.t.SetPublishSettings(ps.publishSettings())
+	ps.t.SetMessageOrdering(ps.OrderingKeyTag != "")
 }
 
 func (ps *PubSub) publishSettings() pubsub.PublishSettings {
@@ -158,27 +212,49 @@ func (ps *PubSub) publishSettings() pubsub.PublishSettings {
 
 func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, error) {
 	if ps.SendBatched {
-		b, err := ps.serializer.SerializeBatch(metrics)
-		if err != nil {
-			return nil, err
+		// Messages can only have a single ordering key so send one message
+		// per key, preserving the order of the metrics for each key
+		var keys []string
+		batches := make(map[string][]telegraf.Metric)
+		for _, m := range metrics {
+			key := ps.orderingKey(m)
+			if _, found := batches[key]; !found {
+				keys = append(keys, key)
+			}
+			batches[key] = append(batches[key], m)
 		}
 
-		b = ps.encodeB64Data(b)
+		msgs := make([]*pubsub.Message, 0, len(keys))
+		for _, key := range keys {
+			b, err := ps.serializer.SerializeBatch(batches[key])
+			if err != nil {
+				return nil, err
+			}
 
-		b, err = ps.compressData(b)
-		if err != nil {
-			return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
-		}
+			b = ps.encodeB64Data(b)
+
+			b, err = ps.compressData(b)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
+			}
 
-		msg := &pubsub.Message{Data: b}
-		if ps.Attributes != nil {
-			msg.Attributes = ps.Attributes
+			msgs = append(msgs, ps.newMessage(b, key))
 		}
-		return []*pubsub.Message{msg}, nil
+		return msgs, nil
 	}
 
 	msgs := make([]*pubsub.Message, 0, len(metrics))
 	for _, m := range metrics {
+		if ps.schema != nil {
+			b, err := ps.schema.encode(m)
+			if err != nil {
+				ps.Log.Errorf("Could not encode metric according to the topic schema: %v", err)
+				continue
+			}
+			msgs = append(msgs, ps.newMessage(b, ps.orderingKey(m)))
+			continue
+		}
+
 		b, err := ps.serializer.Serialize(m)
 		if err != nil {
 			ps.Log.Debugf("Could not serialize metric: %v", err)
@@ -193,18 +269,33 @@ func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, erro
 			continue
 		}
 
-		msg := &pubsub.Message{
-			Data: b,
-		}
-		if ps.Attributes != nil {
-			msg.Attributes = ps.Attributes
-		}
-		msgs = append(msgs, msg)
+		msgs = append(msgs, ps.newMessage(b, ps.orderingKey(m)))
 	}
 
 	return msgs, nil
 }
 
+func (ps *PubSub) newMessage(data []byte, orderingKey string) *pubsub.Message {
+	msg := &pubsub.Message{
+		Data:        data,
+		OrderingKey: orderingKey,
+	}
+	if ps.Attributes != nil {
+		msg.Attributes = ps.Attributes
+	}
+	return msg
+}
+
+// orderingKey returns the value of the ordering key tag or an empty string
+// for publishing the metric without ordering
+func (ps *PubSub) orderingKey(m telegraf.Metric) string {
+	if ps.OrderingKeyTag == "" {
+		return ""
+	}
+	key, _ := m.GetTag(ps.OrderingKeyTag)
+	return key
+}
+
 func (ps *PubSub) encodeB64Data(data []byte) []byte {
 	if ps.Base64Data {
 		encoded := base64.StdEncoding.EncodeToString(data)
@@ -278,11 +369,27 @@ func (ps *PubSub) Init() error {
 		return fmt.Errorf("invalid value %q for content_encoding", ps.ContentEncoding)
 	}
 
+	if ps.UseTopicSchema {
+		// Messages must match the schema exactly to pass validation
+		if ps.SendBatched {
+			return errors.New("send_batched cannot be used with the topic schema")
+		}
+		if ps.Base64Data || ps.ContentEncoding != "identity" {
+			return errors.New("base64_data and content_encoding cannot be used with the topic schema")
+		}
+		if ps.SchemaMeasurementField == "" || ps.SchemaTimestampField == "" {
+			return errors.New("schema_measurement_field and schema_timestamp_field are required")
+		}
+	}
+
 	return nil
 }
 
 func init() {
 	outputs.Add("cloud_pubsub", func() telegraf.Output {
-		return &PubSub{}
+		return &PubSub{
+			SchemaMeasurementField: "measurement",
+			SchemaTimestampField:   "timestamp",
+		}
 	})
 }
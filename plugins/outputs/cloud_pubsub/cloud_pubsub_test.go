@@ -189,6 +189,86 @@ func TestPubSub_WriteGzipAndBase64Single(t *testing.T) {
 	}
 }
 
+func TestPubSub_WriteOrderingKey(t *testing.T) {
+	m1 := testutil.TestMetric("value_1", "test")
+	m1.AddTag("device", "a")
+	m2 := testutil.TestMetric("value_2", "test")
+	m2.AddTag("device", "b")
+	m3 := testutil.TestMetric("value_3", "test")
+	testMetrics := []testMetric{{m1, false}, {m2, false}, {m3, false}}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.OrderingKeyTag = "device"
+
+	require.NoError(t, ps.Write(metrics))
+	require.True(t, topic.Ordering)
+
+	require.Equal(t, "a", verifyRawMetricPublished(t, m1, topic.published).OrderingKey)
+	require.Equal(t, "b", verifyRawMetricPublished(t, m2, topic.published).OrderingKey)
+	require.Empty(t, verifyRawMetricPublished(t, m3, topic.published).OrderingKey)
+}
+
+func TestPubSub_WriteBatchedOrderingKey(t *testing.T) {
+	m1 := testutil.TestMetric("value_1", "test")
+	m1.AddTag("device", "a")
+	m2 := testutil.TestMetric("value_2", "test")
+	m2.AddTag("device", "b")
+	m3 := testutil.TestMetric("value_3", "test")
+	m3.AddTag("device", "a")
+	testMetrics := []testMetric{{m1, false}, {m2, false}, {m3, false}}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.SendBatched = true
+	ps.OrderingKeyTag = "device"
+
+	require.NoError(t, ps.Write(metrics))
+
+	// Metrics with the same key are sent in one message in order
+	require.Len(t, topic.published, 3)
+	require.Same(t, topic.published["value_1"], topic.published["value_3"])
+	require.Equal(t, "a", topic.published["value_1"].OrderingKey)
+	require.Equal(t, "test,device=a,tag1=value1 value=\"value_1\" 1257894000000000000\n"+
+		"test,device=a,tag1=value1 value=\"value_3\" 1257894000000000000\n", string(topic.published["value_1"].Data))
+	require.Equal(t, "b", topic.published["value_2"].OrderingKey)
+}
+
+func TestPubSub_InitTopicSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *PubSub
+		expected string
+	}{
+		{
+			name:     "batched",
+			plugin:   &PubSub{SendBatched: true},
+			expected: "send_batched cannot be used with the topic schema",
+		},
+		{
+			name:     "base64",
+			plugin:   &PubSub{Base64Data: true},
+			expected: "base64_data and content_encoding cannot be used with the topic schema",
+		},
+		{
+			name:     "gzip",
+			plugin:   &PubSub{ContentEncoding: "gzip"},
+			expected: "base64_data and content_encoding cannot be used with the topic schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Project = "test-project"
+			tt.plugin.Topic = "test-topic"
+			tt.plugin.UseTopicSchema = true
+			tt.plugin.SchemaMeasurementField = "measurement"
+			tt.plugin.SchemaTimestampField = "timestamp"
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
 func verifyRawMetricPublished(t *testing.T, m telegraf.Metric, published map[string]*pubsub.Message) *pubsub.Message {
 	return verifyMetricPublished(t, m, published, false, false)
 }
@@ -0,0 +1,377 @@
+package cloud_pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/bufbuild/protocompile"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// schemaEncoder encodes a single metric according to the schema of the topic
+type schemaEncoder interface {
+	encode(m telegraf.Metric) ([]byte, error)
+}
+
+// newSchemaEncoder creates an encoder for the given schema definition and
+// message encoding of the topic
+func newSchemaEncoder(cfg *pubsub.SchemaConfig, encoding pubsub.SchemaEncoding, measurementField, timestampField string) (schemaEncoder, error) {
+	if encoding != pubsub.EncodingJSON && encoding != pubsub.EncodingBinary {
+		return nil, fmt.Errorf("unsupported schema encoding %d", encoding)
+	}
+	fields := schemaFields{measurement: measurementField, timestamp: timestampField}
+
+	switch cfg.Type {
+	case pubsub.SchemaAvro:
+		return newAvroEncoder(cfg.Definition, encoding == pubsub.EncodingJSON, fields)
+	case pubsub.SchemaProtocolBuffer:
+		return newProtobufEncoder(cfg.Definition, encoding == pubsub.EncodingJSON, fields)
+	}
+	return nil, fmt.Errorf("unsupported schema type %d", cfg.Type)
+}
+
+// schemaFields determines the values of the schema fields from a metric
+type schemaFields struct {
+	measurement string
+	timestamp   string
+}
+
+// value returns the metric name, timestamp, field or tag value for the
+// schema field of the given name
+func (s schemaFields) value(m telegraf.Metric, name string) (interface{}, bool) {
+	switch name {
+	case s.measurement:
+		return m.Name(), true
+	case s.timestamp:
+		return m.Time(), true
+	}
+	if v, found := m.GetField(name); found {
+		return v, true
+	}
+	if v, found := m.GetTag(name); found {
+		return v, true
+	}
+	return nil, false
+}
+
+// Timestamps are written as nanoseconds for integer and RFC3339 for string
+// schema fields
+func toInt64(v interface{}) (int64, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano(), nil
+	}
+	return internal.ToInt64(v)
+}
+
+func toString(v interface{}) (string, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return internal.ToString(v)
+}
+
+type converter func(interface{}) (interface{}, error)
+
+type avroField struct {
+	name     string
+	convert  converter
+	nullable bool
+	optional bool
+}
+
+type avroEncoder struct {
+	codec  *goavro.Codec
+	fields []avroField
+	json   bool
+	schemaFields
+}
+
+func newAvroEncoder(definition string, textual bool, sf schemaFields) (*avroEncoder, error) {
+	codec, err := goavro.NewCodec(definition)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Avro schema failed: %w", err)
+	}
+
+	var schema struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name    string           `json:"name"`
+			Type    interface{}      `json:"type"`
+			Default *json.RawMessage `json:"default"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(definition), &schema); err != nil {
+		return nil, fmt.Errorf("decoding Avro schema failed: %w", err)
+	}
+	if schema.Type != "record" {
+		return nil, fmt.Errorf("unsupported Avro schema type %q, expected a record", schema.Type)
+	}
+
+	fields := make([]avroField, 0, len(schema.Fields))
+	for _, f := range schema.Fields {
+		convert, nullable, err := avroConverter(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		fields = append(fields, avroField{
+			name:     f.Name,
+			convert:  convert,
+			nullable: nullable,
+			optional: f.Default != nil,
+		})
+	}
+
+	return &avroEncoder{
+		codec:        codec,
+		fields:       fields,
+		json:         textual,
+		schemaFields: sf,
+	}, nil
+}
+
+func (e *avroEncoder) encode(m telegraf.Metric) ([]byte, error) {
+	record := make(map[string]interface{}, len(e.fields))
+	for _, f := range e.fields {
+		v, found := e.value(m, f.name)
+		if !found {
+			switch {
+			case f.nullable:
+				record[f.name] = nil
+			case f.optional:
+				// The codec uses the default value of the schema
+			default:
+				return nil, fmt.Errorf("no value for field %q", f.name)
+			}
+			continue
+		}
+
+		converted, err := f.convert(v)
+		if err != nil {
+			return nil, fmt.Errorf("converting field %q failed: %w", f.name, err)
+		}
+		record[f.name] = converted
+	}
+
+	if e.json {
+		return e.codec.TextualFromNative(nil, record)
+	}
+	return e.codec.BinaryFromNative(nil, record)
+}
+
+// avroConverter returns the conversion of metric values to the native Go type
+// of the Avro type and whether the type is a union including null
+func avroConverter(typ interface{}) (converter, bool, error) {
+	switch t := typ.(type) {
+	case string:
+		convert, err := avroPrimitiveConverter(t)
+		return convert, false, err
+	case map[string]interface{}:
+		name, _ := t["type"].(string)
+		if name == "enum" {
+			return func(v interface{}) (interface{}, error) { return toString(v) }, false, nil
+		}
+		if logical, ok := t["logicalType"].(string); ok && name == "long" {
+			switch logical {
+			case "timestamp-millis", "timestamp-micros":
+				return avroTimestamp, false, nil
+			}
+		}
+		convert, err := avroPrimitiveConverter(name)
+		return convert, false, err
+	case []interface{}:
+		// Only unions of a single type with null are supported as the
+		// type of the value cannot be determined otherwise.
+		var branch interface{}
+		var nullable bool
+		for _, b := range t {
+			if b == "null" {
+				nullable = true
+				continue
+			}
+			if branch != nil {
+				return nil, false, errors.New("unions of multiple non-null types are not supported")
+			}
+			branch = b
+		}
+		name, ok := branch.(string)
+		if !ok {
+			return nil, false, errors.New("unions of complex types are not supported")
+		}
+		convert, err := avroPrimitiveConverter(name)
+		if err != nil {
+			return nil, false, err
+		}
+		return func(v interface{}) (interface{}, error) {
+			converted, err := convert(v)
+			if err != nil {
+				return nil, err
+			}
+			return goavro.Union(name, converted), nil
+		}, nullable, nil
+	}
+	return nil, false, fmt.Errorf("unsupported type %v", typ)
+}
+
+func avroPrimitiveConverter(name string) (converter, error) {
+	switch name {
+	case "boolean":
+		return func(v interface{}) (interface{}, error) { return internal.ToBool(v) }, nil
+	case "int":
+		return func(v interface{}) (interface{}, error) { return internal.ToInt32(v) }, nil
+	case "long":
+		return func(v interface{}) (interface{}, error) { return toInt64(v) }, nil
+	case "float":
+		return func(v interface{}) (interface{}, error) { return internal.ToFloat32(v) }, nil
+	case "double":
+		return func(v interface{}) (interface{}, error) { return internal.ToFloat64(v) }, nil
+	case "string":
+		return func(v interface{}) (interface{}, error) { return toString(v) }, nil
+	case "bytes":
+		return func(v interface{}) (interface{}, error) {
+			s, err := toString(v)
+			return []byte(s), err
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", name)
+}
+
+// avroTimestamp passes times to the codec for conversion to the unit of the
+// logical type and integers as-is
+func avroTimestamp(v interface{}) (interface{}, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	return internal.ToInt64(v)
+}
+
+type protobufEncoder struct {
+	desc protoreflect.MessageDescriptor
+	json bool
+	schemaFields
+}
+
+func newProtobufEncoder(definition string, textual bool, sf schemaFields) (*protobufEncoder, error) {
+	const filename = "schema.proto"
+
+	resolver := &protocompile.SourceResolver{
+		Accessor: protocompile.SourceAccessorFromMap(map[string]string{filename: definition}),
+	}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	files, err := compiler.Compile(context.Background(), filename)
+	if err != nil {
+		return nil, fmt.Errorf("parsing protocol-buffer schema failed: %w", err)
+	}
+
+	// Pub/Sub requires schemas to contain a single top-level message type
+	messages := files[0].Messages()
+	if messages.Len() == 0 {
+		return nil, errors.New("protocol-buffer schema does not contain a message type")
+	}
+	desc := messages.Get(0)
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("field %q: repeated fields are not supported", fd.Name())
+		}
+		if fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() != "google.protobuf.Timestamp" {
+			return nil, fmt.Errorf("field %q: message type %q is not supported", fd.Name(), fd.Message().FullName())
+		}
+	}
+
+	return &protobufEncoder{
+		desc:         desc,
+		json:         textual,
+		schemaFields: sf,
+	}, nil
+}
+
+func (e *protobufEncoder) encode(m telegraf.Metric) ([]byte, error) {
+	msg := dynamicpb.NewMessage(e.desc)
+
+	fields := e.desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		v, found := e.value(m, string(fd.Name()))
+		if !found {
+			continue
+		}
+		value, err := protobufValue(msg, fd, v)
+		if err != nil {
+			return nil, fmt.Errorf("converting field %q failed: %w", fd.Name(), err)
+		}
+		msg.Set(fd, value)
+	}
+
+	if e.json {
+		return protojson.Marshal(msg)
+	}
+	return proto.Marshal(msg)
+}
+
+func protobufValue(msg *dynamicpb.Message, fd protoreflect.FieldDescriptor, v interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := internal.ToBool(v)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := internal.ToInt32(v)
+		return protoreflect.ValueOfInt32(n), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := toInt64(v)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := internal.ToUint32(v)
+		return protoreflect.ValueOfUint32(n), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := internal.ToUint64(v)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := internal.ToFloat32(v)
+		return protoreflect.ValueOfFloat32(f), err
+	case protoreflect.DoubleKind:
+		f, err := internal.ToFloat64(v)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		s, err := toString(v)
+		return protoreflect.ValueOfString(s), err
+	case protoreflect.BytesKind:
+		s, err := toString(v)
+		return protoreflect.ValueOfBytes([]byte(s)), err
+	case protoreflect.EnumKind:
+		if s, ok := v.(string); ok {
+			ev := fd.Enum().Values().ByName(protoreflect.Name(s))
+			if ev == nil {
+				return protoreflect.Value{}, fmt.Errorf("unknown enum value %q", s)
+			}
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := internal.ToInt32(v)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), err
+	case protoreflect.MessageKind:
+		// Only google.protobuf.Timestamp is allowed, see newProtobufEncoder
+		t, ok := v.(time.Time)
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("cannot convert %T to timestamp", v)
+		}
+		ts := msg.NewField(fd).Message()
+		ts.Set(ts.Descriptor().Fields().ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		ts.Set(ts.Descriptor().Fields().ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return protoreflect.ValueOfMessage(ts), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %v", fd.Kind())
+}
@@ -0,0 +1,193 @@
+package cloud_pubsub
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const avroSchema = `{
+  "type": "record",
+  "name": "Reading",
+  "fields": [
+    {"name": "measurement", "type": "string"},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+    {"name": "device", "type": "string"},
+    {"name": "temperature", "type": "double"},
+    {"name": "humidity", "type": ["null", "float"], "default": null},
+    {"name": "status", "type": "string", "default": "ok"}
+  ]
+}`
+
+const protobufSchema = `
+syntax = "proto3";
+
+import "google/protobuf/timestamp.proto";
+
+message Reading {
+  enum Status {
+    UNKNOWN = 0;
+    OK = 1;
+    FAILED = 2;
+  }
+  string measurement = 1;
+  google.protobuf.Timestamp timestamp = 2;
+  string device = 3;
+  double temperature = 4;
+  int64 count = 5;
+  Status status = 6;
+}
+`
+
+func testSchemaMetric() telegraf.Metric {
+	return metric.New(
+		"sensor",
+		map[string]string{"device": "dev01", "status": "FAILED"},
+		map[string]interface{}{"temperature": int64(21), "count": 3.0, "unknown": "ignored"},
+		time.Date(2024, time.June, 14, 8, 0, 0, 123456000, time.UTC),
+	)
+}
+
+func TestSchemaAvro(t *testing.T) {
+	cfg := &pubsub.SchemaConfig{Type: pubsub.SchemaAvro, Definition: avroSchema}
+	codec, err := goavro.NewCodec(avroSchema)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"measurement": "sensor",
+		"timestamp":   time.Date(2024, time.June, 14, 8, 0, 0, 123456000, time.UTC),
+		"device":      "dev01",
+		"temperature": float64(21),
+		"humidity":    nil,
+		"status":      "FAILED",
+	}
+
+	// Binary encoding
+	encoder, err := newSchemaEncoder(cfg, pubsub.EncodingBinary, "measurement", "timestamp")
+	require.NoError(t, err)
+	buf, err := encoder.encode(testSchemaMetric())
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+	require.Equal(t, expected, native)
+
+	// JSON encoding
+	encoder, err = newSchemaEncoder(cfg, pubsub.EncodingJSON, "measurement", "timestamp")
+	require.NoError(t, err)
+	buf, err = encoder.encode(testSchemaMetric())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"measurement": "sensor",
+		"timestamp": 1718352000123456,
+		"device": "dev01",
+		"temperature": 21,
+		"humidity": null,
+		"status": "FAILED"
+	}`, string(buf))
+
+	// Union values and defaults
+	m := testSchemaMetric()
+	m.AddField("humidity", 45.5)
+	m.RemoveTag("status")
+	buf, err = encoder.encode(m)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `"humidity":{"float":45.5}`)
+	require.Contains(t, string(buf), `"status":"ok"`)
+
+	// Missing required fields
+	m.RemoveTag("device")
+	_, err = encoder.encode(m)
+	require.ErrorContains(t, err, `no value for field "device"`)
+}
+
+func TestSchemaProtobuf(t *testing.T) {
+	cfg := &pubsub.SchemaConfig{Type: pubsub.SchemaProtocolBuffer, Definition: protobufSchema}
+
+	// Binary encoding
+	encoder, err := newSchemaEncoder(cfg, pubsub.EncodingBinary, "measurement", "timestamp")
+	require.NoError(t, err)
+	buf, err := encoder.encode(testSchemaMetric())
+	require.NoError(t, err)
+
+	desc := encoder.(*protobufEncoder).desc
+	msg := dynamicpb.NewMessage(desc)
+	require.NoError(t, proto.Unmarshal(buf, msg))
+	fields := desc.Fields()
+	require.Equal(t, "sensor", msg.Get(fields.ByName("measurement")).String())
+	require.Equal(t, "dev01", msg.Get(fields.ByName("device")).String())
+	require.InDelta(t, 21.0, msg.Get(fields.ByName("temperature")).Float(), 0)
+	require.Equal(t, int64(3), msg.Get(fields.ByName("count")).Int())
+	require.EqualValues(t, 2, msg.Get(fields.ByName("status")).Enum())
+
+	// JSON encoding
+	encoder, err = newSchemaEncoder(cfg, pubsub.EncodingJSON, "measurement", "timestamp")
+	require.NoError(t, err)
+	buf, err = encoder.encode(testSchemaMetric())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"measurement": "sensor",
+		"timestamp": "2024-06-14T08:00:00.123456Z",
+		"device": "dev01",
+		"temperature": 21,
+		"count": "3",
+		"status": "FAILED"
+	}`, string(buf))
+}
+
+func TestSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *pubsub.SchemaConfig
+		expected string
+	}{
+		{
+			name: "avro union",
+			cfg: &pubsub.SchemaConfig{
+				Type:       pubsub.SchemaAvro,
+				Definition: `{"type": "record", "name": "R", "fields": [{"name": "v", "type": ["long", "string"]}]}`,
+			},
+			expected: `field "v": unions of multiple non-null types are not supported`,
+		},
+		{
+			name: "avro nested record",
+			cfg: &pubsub.SchemaConfig{
+				Type: pubsub.SchemaAvro,
+				Definition: `{"type": "record", "name": "R", "fields": [
+					{"name": "v", "type": {"type": "record", "name": "N", "fields": []}}
+				]}`,
+			},
+			expected: `field "v": unsupported type "record"`,
+		},
+		{
+			name: "protobuf repeated",
+			cfg: &pubsub.SchemaConfig{
+				Type:       pubsub.SchemaProtocolBuffer,
+				Definition: `syntax = "proto3"; message R { repeated double v = 1; }`,
+			},
+			expected: `field "v": repeated fields are not supported`,
+		},
+		{
+			name: "protobuf syntax",
+			cfg: &pubsub.SchemaConfig{
+				Type:       pubsub.SchemaProtocolBuffer,
+				Definition: `message R {`,
+			},
+			expected: "parsing protocol-buffer schema failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSchemaEncoder(tt.cfg, pubsub.EncodingBinary, "measurement", "timestamp")
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
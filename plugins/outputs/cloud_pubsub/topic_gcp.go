@@ -13,6 +13,7 @@ type (
 		Publish(ctx context.Context, msg *pubsub.Message) publishResult
 		PublishSettings() pubsub.PublishSettings
 		SetPublishSettings(settings pubsub.PublishSettings)
+		SetMessageOrdering(enabled bool)
 	}
 
 	publishResult interface {
@@ -43,3 +44,7 @@ func (tw *topicWrapper) PublishSettings() pubsub.PublishSettings {
 func (tw *topicWrapper) SetPublishSettings(settings pubsub.PublishSettings) {
 	tw.topic.PublishSettings = settings
 }
+
+func (tw *topicWrapper) SetMessageOrdering(enabled bool) {
+	tw.topic.EnableMessageOrdering = enabled
+}
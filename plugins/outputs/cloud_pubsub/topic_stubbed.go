@@ -50,6 +50,7 @@ type (
 		*testing.T
 		Base64Data      bool
 		ContentEncoding string
+		Ordering        bool
 
 		stopped bool
 		pLock   sync.Mutex
@@ -122,6 +123,9 @@ func (t *stubTopic) Publish(ctx context.Context, msg *pubsub.Message) publishRes
 	if t.stopped || ctx.Err() != nil {
 		t.Fatalf("publish called after stop")
 	}
+	if !t.Ordering && msg.OrderingKey != "" {
+		t.Fatalf("ordering key set without enabling message ordering")
+	}
 
 	ids := t.parseIDs(msg)
 	r := &stubResult{
@@ -151,6 +155,10 @@ func (t *stubTopic) SetPublishSettings(settings pubsub.PublishSettings) {
 	t.initBundler()
 }
 
+func (t *stubTopic) SetMessageOrdering(enabled bool) {
+	t.Ordering = enabled
+}
+
 func (t *stubTopic) initBundler() *stubTopic {
 	t.bundler = bundler.NewBundler(&bundledMsg{}, t.sendBundle())
 	t.bundler.DelayThreshold = 10 * time.Second
@@ -0,0 +1,369 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package icinga2
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Check states as defined by the Nagios plugin API
+const (
+	stateOK       = 0
+	stateWarning  = 1
+	stateCritical = 2
+	stateUnknown  = 3
+)
+
+var stateNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+type Icinga2 struct {
+	URL         string          `toml:"url"`
+	Username    config.Secret   `toml:"username"`
+	Password    config.Secret   `toml:"password"`
+	HostTag     string          `toml:"host_tag"`
+	CheckSource string          `toml:"check_source"`
+	TTL         config.Duration `toml:"ttl"`
+	Checks      []*check        `toml:"check"`
+	Log         telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client   *http.Client
+	endpoint string
+	hostname string
+}
+
+type check struct {
+	Measurement string            `toml:"measurement"`
+	Field       string            `toml:"field"`
+	Service     string            `toml:"service"`
+	Warning     string            `toml:"warning"`
+	Critical    string            `toml:"critical"`
+	States      map[string]string `toml:"states"`
+	Unit        string            `toml:"unit"`
+
+	measurement filter.Filter
+	service     *template.Template
+	warning     *threshold
+	critical    *threshold
+	states      map[string]int
+}
+
+type checkResult struct {
+	Type            string            `json:"type"`
+	Filter          string            `json:"filter"`
+	FilterVars      map[string]string `json:"filter_vars"`
+	ExitStatus      int               `json:"exit_status"`
+	PluginOutput    string            `json:"plugin_output"`
+	PerformanceData []string          `json:"performance_data,omitempty"`
+	CheckSource     string            `json:"check_source,omitempty"`
+	ExecutionStart  float64           `json:"execution_start"`
+	ExecutionEnd    float64           `json:"execution_end"`
+	TTL             float64           `json:"ttl,omitempty"`
+}
+
+func (*Icinga2) SampleConfig() string {
+	return sampleConfig
+}
+
+func (i *Icinga2) Init() error {
+	if i.URL == "" {
+		return errors.New("url required")
+	}
+	i.endpoint = strings.TrimSuffix(i.URL, "/") + "/v1/actions/process-check-result"
+
+	if len(i.Checks) == 0 {
+		return errors.New("no checks configured")
+	}
+
+	for idx, c := range i.Checks {
+		if err := c.init(); err != nil {
+			return fmt.Errorf("initializing check %d failed: %w", idx+1, err)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("getting hostname failed: %w", err)
+	}
+	i.hostname = hostname
+	if i.CheckSource == "" {
+		i.CheckSource = hostname
+	}
+
+	return nil
+}
+
+func (c *check) init() error {
+	if c.Measurement == "" {
+		return errors.New("measurement required")
+	}
+	if c.Field == "" {
+		return errors.New("field required")
+	}
+
+	var err error
+	if c.measurement, err = filter.Compile([]string{c.Measurement}); err != nil {
+		return fmt.Errorf("compiling measurement filter failed: %w", err)
+	}
+
+	service := c.Service
+	if service == "" {
+		service = "{{.Name}}." + c.Field
+	}
+	if c.service, err = template.New("service").Parse(service); err != nil {
+		return fmt.Errorf("parsing service template failed: %w", err)
+	}
+
+	if c.Warning != "" {
+		if c.warning, err = parseThreshold(c.Warning); err != nil {
+			return fmt.Errorf("parsing warning threshold failed: %w", err)
+		}
+	}
+	if c.Critical != "" {
+		if c.critical, err = parseThreshold(c.Critical); err != nil {
+			return fmt.Errorf("parsing critical threshold failed: %w", err)
+		}
+	}
+
+	c.states = make(map[string]int, len(c.States))
+	for value, name := range c.States {
+		switch strings.ToLower(name) {
+		case "ok":
+			c.states[value] = stateOK
+		case "warning":
+			c.states[value] = stateWarning
+		case "critical":
+			c.states[value] = stateCritical
+		case "unknown":
+			c.states[value] = stateUnknown
+		default:
+			return fmt.Errorf("invalid state %q for value %q", name, value)
+		}
+	}
+
+	return nil
+}
+
+func (i *Icinga2) Connect() error {
+	ctx := context.Background()
+	client, err := i.HTTPClientConfig.CreateClient(ctx, i.Log)
+	if err != nil {
+		return err
+	}
+	i.client = client
+
+	return nil
+}
+
+func (i *Icinga2) Close() error {
+	if i.client != nil {
+		i.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (i *Icinga2) Write(metrics []telegraf.Metric) error {
+	// Submit the results of all metrics even if some of them fail to avoid
+	// resending already accepted results of the batch. Only metrics with
+	// failures that might be resolved by retrying are kept for the next
+	// write, all others are either accepted or rejected.
+	writeErr := &internal.PartialWriteError{
+		MetricsAccept: make([]int, 0, len(metrics)),
+	}
+	for idx, raw := range metrics {
+		m := raw
+		if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+			m = wm.Unwrap()
+		}
+
+		var retry, reject bool
+		for _, c := range i.Checks {
+			result, err := i.evaluate(c, m)
+			if err != nil {
+				i.Log.Errorf("Evaluating check for metric %q failed: %v", m.Name(), err)
+				continue
+			}
+			if result == nil {
+				continue
+			}
+			if temporary, err := i.send(result); err != nil {
+				if temporary {
+					retry = true
+				} else {
+					reject = true
+				}
+				if writeErr.Err == nil {
+					writeErr.Err = err
+				}
+			}
+		}
+
+		switch {
+		case retry:
+			// Keep the metric for the next write
+		case reject:
+			writeErr.MetricsReject = append(writeErr.MetricsReject, idx)
+		default:
+			writeErr.MetricsAccept = append(writeErr.MetricsAccept, idx)
+		}
+	}
+
+	if writeErr.Err != nil {
+		return writeErr
+	}
+	return nil
+}
+
+// evaluate computes the check result of the given check for the metric.
+// A nil result is returned if the check does not apply to the metric.
+func (i *Icinga2) evaluate(c *check, m telegraf.Metric) (*checkResult, error) {
+	if !c.measurement.Match(m.Name()) {
+		return nil, nil
+	}
+	raw, found := m.GetField(c.Field)
+	if !found {
+		return nil, nil
+	}
+
+	host, found := m.GetTag(i.HostTag)
+	if !found {
+		host = i.hostname
+	}
+
+	var buf bytes.Buffer
+	if err := c.service.Execute(&buf, &templateMetric{m}); err != nil {
+		return nil, fmt.Errorf("creating service name failed: %w", err)
+	}
+	service := buf.String()
+
+	state := stateUnknown
+	value, err := internal.ToFloat64(raw)
+	numeric := err == nil
+	if s, found := c.states[fmt.Sprintf("%v", raw)]; found {
+		state = s
+	} else if numeric {
+		switch {
+		case c.critical != nil && c.critical.alert(value):
+			state = stateCritical
+		case c.warning != nil && c.warning.alert(value):
+			state = stateWarning
+		default:
+			state = stateOK
+		}
+	}
+
+	ts := float64(m.Time().UnixNano()) / float64(time.Second)
+	result := &checkResult{
+		Type:   "Service",
+		Filter: "host.name==hostname && service.name==servicename",
+		FilterVars: map[string]string{
+			"hostname":    host,
+			"servicename": service,
+		},
+		ExitStatus:     state,
+		PluginOutput:   fmt.Sprintf("%s - %s = %v", stateNames[state], c.Field, raw),
+		CheckSource:    i.CheckSource,
+		ExecutionStart: ts,
+		ExecutionEnd:   ts,
+		TTL:            time.Duration(i.TTL).Seconds(),
+	}
+	if numeric {
+		result.PerformanceData = []string{c.perfdata(value)}
+	}
+
+	return result, nil
+}
+
+// perfdata formats the value according to the Nagios performance data format
+// 'label'=value[UOM];[warn];[crit];[min];[max]
+// Labels must not contain equal signs and single quotes must be doubled.
+func (c *check) perfdata(value float64) string {
+	label := strings.ReplaceAll(c.Field, "=", "_")
+	label = strings.ReplaceAll(label, "'", "''")
+	v := strconv.FormatFloat(value, 'f', -1, 64)
+	return fmt.Sprintf("'%s'=%s%s;%s;%s;;", label, v, c.Unit, c.warning, c.critical)
+}
+
+// send submits the check result and returns an error if this failed. The
+// returned flag indicates if the failure is temporary, i.e. if the result
+// should be resent later.
+func (i *Icinga2) send(result *checkResult) (bool, error) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return false, fmt.Errorf("serializing check result failed: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, i.endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return false, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	if !i.Username.Empty() || !i.Password.Empty() {
+		username, err := i.Username.Get()
+		if err != nil {
+			return false, fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := i.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return false, fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("sending check result failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		// Icinga2 returns "not found" if no object matches the filter, so
+		// retrying would not help. Drop the result instead of blocking the
+		// output forever.
+		i.Log.Warnf("No service %q found for host %q", result.FilterVars["servicename"], result.FilterVars["hostname"])
+		return false, nil
+	}
+
+	// Server-side and rate-limiting errors might resolve over time, all
+	// other client errors will persist when resending the result.
+	temporary := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return temporary, fmt.Errorf("sending check result failed with status %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func init() {
+	outputs.Add("icinga2", func() telegraf.Output {
+		return &Icinga2{
+			HostTag: "host",
+		}
+	})
+}
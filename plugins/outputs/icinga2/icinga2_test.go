@@ -0,0 +1,382 @@
+package icinga2
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		alert    []float64
+		noAlert  []float64
+		expected *threshold
+	}{
+		{
+			name:    "single value",
+			raw:     "10",
+			alert:   []float64{-1, 10.5},
+			noAlert: []float64{0, 5, 10},
+		},
+		{
+			name:    "lower bound",
+			raw:     "10:",
+			alert:   []float64{-1, 9.9},
+			noAlert: []float64{10, 1e9},
+		},
+		{
+			name:    "upper bound",
+			raw:     "~:10",
+			alert:   []float64{10.1, 100},
+			noAlert: []float64{-1e9, 0, 10},
+		},
+		{
+			name:    "range",
+			raw:     "10:20",
+			alert:   []float64{9, 21},
+			noAlert: []float64{10, 15, 20},
+		},
+		{
+			name:    "inverted range",
+			raw:     "@10:20",
+			alert:   []float64{10, 15, 20},
+			noAlert: []float64{9, 21},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th, err := parseThreshold(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.raw, th.String())
+			for _, v := range tt.alert {
+				require.Truef(t, th.alert(v), "expected alert for %v", v)
+			}
+			for _, v := range tt.noAlert {
+				require.Falsef(t, th.alert(v), "expected no alert for %v", v)
+			}
+		})
+	}
+}
+
+func TestThresholdInvalid(t *testing.T) {
+	for _, raw := range []string{"", "abc", "a:10", "10:b", "20:10"} {
+		_, err := parseThreshold(raw)
+		require.Errorf(t, err, "expected error for %q", raw)
+	}
+	th, err := parseThreshold("~:")
+	require.NoError(t, err)
+	require.Equal(t, math.Inf(-1), th.start)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Icinga2
+		expected string
+	}{
+		{
+			name:     "no url",
+			plugin:   &Icinga2{},
+			expected: "url required",
+		},
+		{
+			name:     "no checks",
+			plugin:   &Icinga2{URL: "http://localhost:5665"},
+			expected: "no checks configured",
+		},
+		{
+			name: "no field",
+			plugin: &Icinga2{
+				URL:    "http://localhost:5665",
+				Checks: []*check{{Measurement: "cpu"}},
+			},
+			expected: "field required",
+		},
+		{
+			name: "invalid state",
+			plugin: &Icinga2{
+				URL: "http://localhost:5665",
+				Checks: []*check{{
+					Measurement: "cpu",
+					Field:       "usage_idle",
+					States:      map[string]string{"up": "fine"},
+				}},
+			},
+			expected: `invalid state "fine" for value "up"`,
+		},
+		{
+			name: "invalid threshold",
+			plugin: &Icinga2{
+				URL: "http://localhost:5665",
+				Checks: []*check{{
+					Measurement: "cpu",
+					Field:       "usage_idle",
+					Warning:     "20:10",
+				}},
+			},
+			expected: "parsing warning threshold failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var mu sync.Mutex
+	var received []checkResult
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/actions/process-check-result" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "telegraf" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var result checkResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if result.FilterVars["hostname"] == "unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		mu.Lock()
+		received = append(received, result)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &Icinga2{
+		URL:         ts.URL,
+		Username:    config.NewSecret([]byte("telegraf")),
+		Password:    config.NewSecret([]byte("secret")),
+		HostTag:     "host",
+		CheckSource: "telegraf",
+		Checks: []*check{
+			{
+				Measurement: "cpu",
+				Field:       "usage_idle",
+				Service:     `{{.Name}}-{{.Tag "cpu"}}`,
+				Warning:     "20:",
+				Critical:    "10:",
+				Unit:        "%",
+			},
+			{
+				Measurement: "service*",
+				Field:       "status",
+				States:      map[string]string{"up": "ok", "down": "critical"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	ts0 := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"usage_idle": 50.0}, ts0),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu1"}, map[string]interface{}{"usage_idle": 15.0}, ts0),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu2"}, map[string]interface{}{"usage_idle": 5}, ts0),
+		metric.New("cpu", map[string]string{"host": "unknown", "cpu": "cpu0"}, map[string]interface{}{"usage_idle": 5}, ts0),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_user": 5}, ts0),
+		metric.New("service_status", map[string]string{"host": "b"}, map[string]interface{}{"status": "down"}, ts0),
+		metric.New("service_status", map[string]string{"host": "b"}, map[string]interface{}{"status": "starting"}, ts0),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"status": "down"}, ts0),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []checkResult{
+		{
+			FilterVars:      map[string]string{"hostname": "a", "servicename": "cpu-cpu0"},
+			ExitStatus:      stateOK,
+			PluginOutput:    "OK - usage_idle = 50",
+			PerformanceData: []string{"'usage_idle'=50%;20:;10:;;"},
+		},
+		{
+			FilterVars:      map[string]string{"hostname": "a", "servicename": "cpu-cpu1"},
+			ExitStatus:      stateWarning,
+			PluginOutput:    "WARNING - usage_idle = 15",
+			PerformanceData: []string{"'usage_idle'=15%;20:;10:;;"},
+		},
+		{
+			FilterVars:      map[string]string{"hostname": "a", "servicename": "cpu-cpu2"},
+			ExitStatus:      stateCritical,
+			PluginOutput:    "CRITICAL - usage_idle = 5",
+			PerformanceData: []string{"'usage_idle'=5%;20:;10:;;"},
+		},
+		{
+			FilterVars:   map[string]string{"hostname": "b", "servicename": "service_status.status"},
+			ExitStatus:   stateCritical,
+			PluginOutput: "CRITICAL - status = down",
+		},
+		{
+			FilterVars:   map[string]string{"hostname": "b", "servicename": "service_status.status"},
+			ExitStatus:   stateUnknown,
+			PluginOutput: "UNKNOWN - status = starting",
+		},
+	}
+	for i := range expected {
+		expected[i].Type = "Service"
+		expected[i].Filter = "host.name==hostname && service.name==servicename"
+		expected[i].CheckSource = "telegraf"
+		expected[i].ExecutionStart = 1700000000
+		expected[i].ExecutionEnd = 1700000000
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, expected, received)
+}
+
+func TestWriteError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	plugin := &Icinga2{
+		URL:     ts.URL,
+		HostTag: "host",
+		Checks:  []*check{{Measurement: "cpu", Field: "usage_idle"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "500 Internal Server Error")
+
+	// The metric must be kept for retrying
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
+func TestWritePartialFailure(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result checkResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch result.FilterVars["hostname"] {
+		case "temporary":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "permanent":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		received = append(received, result.FilterVars["hostname"])
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &Icinga2{
+		URL:     ts.URL,
+		HostTag: "host",
+		Checks:  []*check{{Measurement: "cpu", Field: "usage_idle"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "temporary"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "permanent"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "c"}, map[string]interface{}{"used": 50.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "503 Service Unavailable")
+
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0, 2, 4}, writeErr.MetricsAccept)
+	require.Equal(t, []int{3}, writeErr.MetricsReject)
+
+	// All results after the failing one must still be sent
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"a", "b"}, received)
+}
+
+func TestPerfdata(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    *check
+		value    float64
+		expected string
+	}{
+		{
+			name:     "plain",
+			check:    &check{Measurement: "cpu", Field: "usage_idle"},
+			value:    1.5,
+			expected: "'usage_idle'=1.5;;;;",
+		},
+		{
+			name: "thresholds and unit",
+			check: &check{
+				Measurement: "cpu",
+				Field:       "usage_idle",
+				Warning:     "20:",
+				Critical:    "@0:10",
+				Unit:        "%",
+			},
+			value:    42,
+			expected: "'usage_idle'=42%;20:;@0:10;;",
+		},
+		{
+			name:     "escaped label",
+			check:    &check{Measurement: "app", Field: "it's a=b"},
+			value:    3,
+			expected: "'it''s a_b'=3;;;;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.check.init())
+			require.Equal(t, tt.expected, tt.check.perfdata(tt.value))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package icinga2
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// templateMetric exposes the metric to the service name template
+type templateMetric struct {
+	metric telegraf.Metric
+}
+
+func (m *templateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *templateMetric) Tag(key string) string {
+	v, _ := m.metric.GetTag(key)
+	return v
+}
+
+func (m *templateMetric) Field(key string) interface{} {
+	v, _ := m.metric.GetField(key)
+	return v
+}
+
+func (m *templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
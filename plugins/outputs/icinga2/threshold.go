@@ -0,0 +1,83 @@
+package icinga2
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// threshold implements the Nagios plugin range format, see
+// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
+type threshold struct {
+	raw    string
+	start  float64
+	end    float64
+	inside bool
+}
+
+func parseThreshold(raw string) (*threshold, error) {
+	t := &threshold{raw: raw, start: 0, end: math.Inf(1)}
+
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, errors.New("empty threshold")
+	}
+	if strings.HasPrefix(s, "@") {
+		t.inside = true
+		s = s[1:]
+	}
+
+	lower, upper, found := strings.Cut(s, ":")
+	if !found {
+		// A single value denotes the range from zero to the given value
+		v, err := strconv.ParseFloat(lower, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end value %q: %w", lower, err)
+		}
+		t.end = v
+		return t, nil
+	}
+
+	switch lower {
+	case "":
+	case "~":
+		t.start = math.Inf(-1)
+	default:
+		v, err := strconv.ParseFloat(lower, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start value %q: %w", lower, err)
+		}
+		t.start = v
+	}
+
+	if upper != "" {
+		v, err := strconv.ParseFloat(upper, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end value %q: %w", upper, err)
+		}
+		t.end = v
+	}
+
+	if t.start > t.end {
+		return nil, fmt.Errorf("start %v is greater than end %v", t.start, t.end)
+	}
+
+	return t, nil
+}
+
+// alert returns true if the given value should raise an alert for the range
+func (t *threshold) alert(v float64) bool {
+	if t.inside {
+		return v >= t.start && v <= t.end
+	}
+	return v < t.start || v > t.end
+}
+
+func (t *threshold) String() string {
+	if t == nil {
+		return ""
+	}
+	return t.raw
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
@@ -16,6 +17,7 @@ type batch struct {
 	indices   []int
 	payload   []byte
 	processed bool
+	fromSplit bool
 	err       error
 }
 
@@ -66,38 +68,47 @@ func createBatchesFromTag(metrics []telegraf.Metric, tag, fallback string, size
 
 	batches := make([]*batch, 0, len(collector))
 	for _, b := range collector {
-		if len(b.metrics) <= size {
-			batches = append(batches, b)
-			continue
-		}
-		// The batch is larger than it should be, so split it
-		var begin int
-		for begin < len(b.metrics) {
-			end := min(begin+size, len(b.metrics))
-			batches = append(batches, &batch{
-				bucket:  b.bucket,
-				metrics: b.metrics[begin:end],
-				indices: b.indices[begin:end],
-			})
-			begin = end
-		}
+		// The batch might be larger than it should be, so split it
+		batches = append(batches, b.chunk(size)...)
 	}
 
 	return batches
 }
 
+// chunk splits the batch into parts with at most the given number of metrics
+func (b *batch) chunk(size int) []*batch {
+	if len(b.metrics) <= size {
+		return []*batch{b}
+	}
+
+	chunks := make([]*batch, 0, len(b.metrics)/size+1)
+	var begin int
+	for begin < len(b.metrics) {
+		end := min(begin+size, len(b.metrics))
+		chunks = append(chunks, &batch{
+			bucket:  b.bucket,
+			metrics: b.metrics[begin:end],
+			indices: b.indices[begin:end],
+		})
+		begin = end
+	}
+	return chunks
+}
+
 func (b *batch) split() (first, second *batch) {
 	midpoint := len(b.metrics) / 2
 
 	return &batch{
-			bucket:  b.bucket,
-			metrics: b.metrics[:midpoint],
-			indices: b.indices[:midpoint],
+			bucket:    b.bucket,
+			metrics:   b.metrics[:midpoint],
+			indices:   b.indices[:midpoint],
+			fromSplit: true,
 		},
 		&batch{
-			bucket:  b.bucket,
-			metrics: b.metrics[midpoint:],
-			indices: b.indices[midpoint:],
+			bucket:    b.bucket,
+			metrics:   b.metrics[midpoint:],
+			indices:   b.indices[midpoint:],
+			fromSplit: true,
 		}
 }
 
@@ -124,3 +135,98 @@ func (b *batch) serialize(serializer ratelimiter.Serializer, limit int64, encode
 
 	return int64(len(body)), serr
 }
+
+// batchSizer adapts the maximum number of metrics per request for each bucket
+// to the limits imposed by the server. The limit is halved whenever the server
+// rejects a request as too large or throttles the client and is slowly raised
+// again on success.
+type batchSizer struct {
+	limits map[string]int
+	sync.Mutex
+}
+
+func newBatchSizer() *batchSizer {
+	return &batchSizer{limits: make(map[string]int)}
+}
+
+// apply splits all batches exceeding the current limit of their bucket
+func (s *batchSizer) apply(batches []*batch) []*batch {
+	if s == nil {
+		return batches
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	result := make([]*batch, 0, len(batches))
+	for _, b := range batches {
+		if limit, found := s.limits[b.bucket]; found {
+			result = append(result, b.chunk(limit)...)
+		} else {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// shrink reduces the limit of the bucket after a request of the given size
+// failed
+func (s *batchSizer) shrink(bucket string, size int) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	limit := max(size/2, 1)
+	if current, found := s.limits[bucket]; !found || limit < current {
+		s.limits[bucket] = limit
+	}
+}
+
+// update adapts the limits to the outcome of sending the given batches. The
+// limits of buckets with throttled requests are reduced while the others are
+// raised if the requests were constrained by the limit. Buckets with split
+// requests are left alone as their limit was already reduced when splitting.
+func (s *batchSizer) update(batches []*batch) {
+	if s == nil {
+		return
+	}
+
+	failed := make(map[string]bool)
+	largest := make(map[string]int)
+	for _, b := range batches {
+		var terr *ThrottleError
+		switch {
+		case b.fromSplit:
+			failed[b.bucket] = true
+		case errors.As(b.err, &terr):
+			failed[b.bucket] = true
+			s.shrink(b.bucket, len(b.metrics))
+		case b.err == nil && b.processed:
+			largest[b.bucket] = max(largest[b.bucket], len(b.metrics))
+		}
+	}
+
+	for bucket, size := range largest {
+		if !failed[bucket] {
+			s.grow(bucket, size)
+		}
+	}
+}
+
+// grow raises the limit of the bucket after a request of the given size
+// succeeded, but only if the request was actually constrained by the limit
+func (s *batchSizer) grow(bucket string, size int) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if current, found := s.limits[bucket]; found && size >= current {
+		s.limits[bucket] = current + max(current/4, 1)
+	}
+}
@@ -59,6 +59,7 @@ type httpClient struct {
 	retryTime        time.Time
 	retryCount       atomic.Int64
 	concurrent       uint64
+	sizer            *batchSizer
 	log              telegraf.Logger
 
 	// Mutex to protect the retry-time field
@@ -155,6 +156,7 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	} else {
 		batches = createBatchesFromTag(metrics, c.bucketTag, c.bucket, batchSize, c.excludeBucketTag)
 	}
+	batches = c.sizer.apply(batches)
 
 	// Serialize the data in the batches
 	ratets := time.Now()
@@ -217,15 +219,7 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 						splitMu.Unlock()
 					} else {
 						throttle.Store(true)
-
-						// Remember when we can send again
-						// To be on the safe side use the latest time we encounter
-						retryAfter := time.Now().Add(terr.RetryAfter)
-						c.Lock()
-						if retryAfter.After(c.retryTime) {
-							c.retryTime = retryAfter
-						}
-						c.Unlock()
+						c.holdOff(terr.RetryAfter)
 					}
 				}
 				batch.err = err
@@ -256,6 +250,9 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		}
 	}
 
+	// Adapt the batch sizes to the outcome
+	c.sizer.update(batches)
+
 	// Check the errors
 	allProcessed := true
 	for _, batch := range batches {
@@ -322,6 +319,12 @@ func (c *httpClient) writeBatch(ctx context.Context, b *batch) error {
 	}
 	defer resp.Body.Close()
 
+	// Stop sending before the server starts throttling us
+	if wait, found := getRateLimitWait(resp.Header, time.Now()); found {
+		c.log.Debugf("Rate-limit of %s exhausted, waiting %s before sending metrics again", b.bucket, wait)
+		c.holdOff(wait)
+	}
+
 	// Check for success
 	switch resp.StatusCode {
 	case
@@ -410,6 +413,9 @@ func (c *httpClient) splitAndWrite(ctx context.Context, b *batch) []*batch {
 	// able to make progress here.
 	limit := int64(math.MaxInt64)
 
+	// Avoid sending requests of this size in the future
+	c.sizer.shrink(b.bucket, len(b.metrics))
+
 	// Split the batch and resend both parts
 	first, second := b.split()
 
@@ -420,11 +426,12 @@ func (c *httpClient) splitAndWrite(ctx context.Context, b *batch) []*batch {
 			current.err = err
 			splits = append(splits, current)
 		} else {
+			current.processed = true
 			if err := c.writeBatch(ctx, current); err != nil {
 				current.err = err
 
 				var terr *ThrottleError
-				if errors.As(err, &terr) && terr.StatusCode == http.StatusRequestEntityTooLarge && len(b.metrics) > 1 {
+				if errors.As(err, &terr) && terr.StatusCode == http.StatusRequestEntityTooLarge && len(current.metrics) > 1 {
 					s := c.splitAndWrite(ctx, current)
 					splits = append(splits, s...)
 				} else {
@@ -453,8 +460,13 @@ func getRetryDuration(headers http.Header, count int64) time.Duration {
 		var err error
 		retryAfterHeader, err = strconv.ParseFloat(retryAfterHeaderString, 64)
 		if err != nil {
-			// there was a value but we couldn't parse it? guess minimum 10 sec
-			retryAfterHeader = 10
+			if ts, err := http.ParseTime(retryAfterHeaderString); err == nil {
+				// the header might also contain a HTTP date
+				retryAfterHeader = max(time.Until(ts).Seconds(), 0)
+			} else {
+				// there was a value but we couldn't parse it? guess minimum 10 sec
+				retryAfterHeader = 10
+			}
 		}
 		// protect against excessively large retry-after
 		retryAfterHeader = math.Min(retryAfterHeader, defaultMaxWaitRetryAfterSeconds)
@@ -464,6 +476,52 @@ func getRetryDuration(headers http.Header, count int64) time.Duration {
 	return time.Duration(retry*1000) * time.Millisecond
 }
 
+// getRateLimitWait returns the time to wait until the rate-limit of the server
+// is reset if the remaining quota is exhausted. Both the common
+// X-RateLimit-Remaining/X-RateLimit-Reset headers and the RateLimit-Remaining/
+// RateLimit-Reset headers are supported. The reset value is either a number of
+// seconds or a unix timestamp.
+func getRateLimitWait(headers http.Header, now time.Time) (time.Duration, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		remaining := headers.Get(prefix + "Remaining")
+		reset := headers.Get(prefix + "Reset")
+		if remaining == "" || reset == "" {
+			continue
+		}
+		if r, err := strconv.ParseFloat(remaining, 64); err != nil || r > 0 {
+			return 0, false
+		}
+
+		value, err := strconv.ParseFloat(reset, 64)
+		if err != nil || value <= 0 {
+			return 0, false
+		}
+
+		// Large values cannot be a delay but must be a timestamp
+		seconds := value
+		if value > float64(now.Unix()/2) {
+			seconds = value - float64(now.UnixNano())/1e9
+		}
+		seconds = math.Min(seconds, defaultMaxWaitRetryAfterSeconds)
+		if seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(seconds*1000) * time.Millisecond, true
+	}
+	return 0, false
+}
+
+// holdOff prevents sending for the given duration, to be on the safe side
+// the latest time requested is used
+func (c *httpClient) holdOff(wait time.Duration) {
+	retryAfter := time.Now().Add(wait)
+	c.Lock()
+	if retryAfter.After(c.retryTime) {
+		c.retryTime = retryAfter
+	}
+	c.Unlock()
+}
+
 func (c *httpClient) addHeaders(req *http.Request) error {
 	for header, value := range c.headers {
 		secret, err := value.Get()
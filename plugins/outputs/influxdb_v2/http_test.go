@@ -1,7 +1,9 @@
 package influxdb_v2
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -363,3 +365,218 @@ func oldMakeWriteURL(loc url.URL) (string, error) {
 	loc.RawQuery = params.Encode()
 	return loc.String(), nil
 }
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	hdr := http.Header{}
+	hdr.Add("Retry-After", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+	require.InDelta(t, 30*time.Second, getRetryDuration(hdr, 0), float64(time.Second))
+
+	// Dates in the past should not cause a negative wait time
+	hdr.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	require.Zero(t, getRetryDuration(hdr, 0))
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected time.Duration
+		found    bool
+	}{
+		{
+			name: "no headers",
+		},
+		{
+			name:    "quota left",
+			headers: map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": "30"},
+		},
+		{
+			name:     "exhausted with delay",
+			headers:  map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "30"},
+			expected: 30 * time.Second,
+			found:    true,
+		},
+		{
+			name:     "exhausted with timestamp",
+			headers:  map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1700000045"},
+			expected: 45 * time.Second,
+			found:    true,
+		},
+		{
+			name:    "exhausted with passed timestamp",
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1699999990"},
+		},
+		{
+			name:     "standard headers",
+			headers:  map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "5"},
+			expected: 5 * time.Second,
+			found:    true,
+		},
+		{
+			name:     "limited to maximum",
+			headers:  map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "3600"},
+			expected: 600 * time.Second,
+			found:    true,
+		},
+		{
+			name:    "invalid reset",
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "soon"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hdr := http.Header{}
+			for k, v := range tt.headers {
+				hdr.Set(k, v)
+			}
+			wait, found := getRateLimitWait(hdr, now)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.expected, wait)
+		})
+	}
+}
+
+func TestRateLimitHeadersHoldOff(t *testing.T) {
+	var received atomic.Int64
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			received.Add(1)
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "60")
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	var limiterCfg ratelimiter.RateLimitConfig
+	limiter, err := limiterCfg.CreateRateLimiter()
+	require.NoError(t, err)
+
+	u, err := url.Parse("http://" + ts.Listener.Addr().String())
+	require.NoError(t, err)
+	c := &httpClient{
+		url:             u,
+		bucket:          "telegraf",
+		contentEncoding: "identity",
+		serializer:      ratelimiter.NewIndividualSerializer(serializer),
+		rateLimiter:     limiter,
+		log:             &testutil.Logger{},
+	}
+	require.NoError(t, c.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// The write succeeds but further writes are suspended until the reset
+	require.NoError(t, c.Write(t.Context(), metrics))
+	require.InDelta(t, 60*time.Second, time.Until(c.retryTime), float64(time.Second))
+	require.ErrorContains(t, c.Write(t.Context(), metrics), "retry time has not elapsed")
+	require.EqualValues(t, 1, received.Load())
+}
+
+func TestAdaptiveBatching(t *testing.T) {
+	var received atomic.Int64
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received.Add(1)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+
+			// Only accept two metrics per request
+			if bytes.Count(body, []byte("\n")) > 2 {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	var limiterCfg ratelimiter.RateLimitConfig
+	limiter, err := limiterCfg.CreateRateLimiter()
+	require.NoError(t, err)
+
+	u, err := url.Parse("http://" + ts.Listener.Addr().String())
+	require.NoError(t, err)
+	c := &httpClient{
+		url:             u,
+		bucket:          "telegraf",
+		contentEncoding: "identity",
+		serializer:      ratelimiter.NewIndividualSerializer(serializer),
+		rateLimiter:     limiter,
+		sizer:           newBatchSizer(),
+		log:             &testutil.Logger{},
+	}
+	require.NoError(t, c.Init())
+
+	metrics := make([]telegraf.Metric, 0, 8)
+	for i := range 8 {
+		metrics = append(metrics, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": float64(i)}, time.Unix(int64(i), 0)))
+	}
+
+	// The batch is split twice until the server accepts it and all metrics
+	// are accepted
+	require.NoError(t, c.Write(t.Context(), metrics))
+	require.EqualValues(t, 7, received.Load())
+
+	// The limit was reduced to the accepted size
+	require.Equal(t, map[string]int{"telegraf": 2}, c.sizer.limits)
+
+	// Subsequent writes start with the reduced size and raise the limit again
+	// on success
+	received.Store(0)
+	require.NoError(t, c.Write(t.Context(), metrics[:4]))
+	require.EqualValues(t, 2, received.Load())
+	require.Equal(t, map[string]int{"telegraf": 3}, c.sizer.limits)
+}
+
+func TestBatchSizer(t *testing.T) {
+	s := newBatchSizer()
+
+	b := &batch{bucket: "foo", metrics: make([]telegraf.Metric, 10), indices: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	require.Len(t, s.apply([]*batch{b}), 1)
+
+	// Shrinking limits the batches of the bucket only
+	s.shrink("foo", 10)
+	other := &batch{bucket: "bar", metrics: make([]telegraf.Metric, 10), indices: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	batches := s.apply([]*batch{b, other})
+	require.Len(t, batches, 3)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, batches[0].indices)
+	require.Equal(t, []int{5, 6, 7, 8, 9}, batches[1].indices)
+	require.Equal(t, "bar", batches[2].bucket)
+
+	// Unconstrained batches do not raise the limit
+	s.grow("foo", 3)
+	require.Equal(t, 5, s.limits["foo"])
+	s.grow("foo", 5)
+	require.Equal(t, 6, s.limits["foo"])
+
+	// The limit never drops below one metric
+	s.shrink("foo", 1)
+	require.Equal(t, 1, s.limits["foo"])
+
+	// Throttled buckets are reduced, successful ones raised
+	s.update([]*batch{
+		{bucket: "foo", metrics: make([]telegraf.Metric, 1), processed: true},
+		{bucket: "bar", metrics: make([]telegraf.Metric, 4), err: &ThrottleError{StatusCode: http.StatusTooManyRequests}},
+		{bucket: "baz", metrics: make([]telegraf.Metric, 4), processed: true, fromSplit: true},
+	})
+	require.Equal(t, map[string]int{"foo": 2, "bar": 2}, s.limits)
+
+	// A disabled sizer does not modify anything
+	var disabled *batchSizer
+	require.Len(t, disabled.apply([]*batch{b}), 1)
+	disabled.shrink("foo", 10)
+	disabled.grow("foo", 10)
+	disabled.update([]*batch{b})
+}
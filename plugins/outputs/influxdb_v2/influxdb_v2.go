@@ -44,6 +44,7 @@ type InfluxDB struct {
 	PingTimeout      config.Duration           `toml:"ping_timeout"`
 	ReadIdleTimeout  config.Duration           `toml:"read_idle_timeout"`
 	ConcurrentWrites uint64                    `toml:"concurrent_writes"`
+	AdaptiveBatching bool                      `toml:"adaptive_batching"`
 	Log              telegraf.Logger           `toml:"-"`
 	commontls.ClientConfig
 	ratelimiter.RateLimitConfig
@@ -150,6 +151,10 @@ func (i *InfluxDB) Connect() error {
 			if err != nil {
 				return err
 			}
+			var sizer *batchSizer
+			if i.AdaptiveBatching {
+				sizer = newBatchSizer()
+			}
 			c := &httpClient{
 				url:              parts,
 				localAddr:        localAddr,
@@ -170,6 +175,7 @@ func (i *InfluxDB) Connect() error {
 				rateLimiter:      limiter,
 				serializer:       i.serializer,
 				concurrent:       i.ConcurrentWrites,
+				sizer:            sizer,
 				log:              i.Log,
 			}
 
@@ -0,0 +1,98 @@
+package kinesis
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 checksum required by the KPL aggregation format
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Magic number prefixing records in the aggregation format of the Kinesis
+// Producer Library (KPL), see
+// https://github.com/awslabs/amazon-kinesis-producer/blob/master/aggregation-format.md
+var kplMagic = []byte{0xf3, 0x89, 0x9a, 0xc2}
+
+// Field numbers of the AggregatedRecord and Record protobuf messages
+const (
+	kplPartitionKeyTable protowire.Number = 1
+	kplRecords           protowire.Number = 3
+
+	kplRecordPartitionKeyIndex protowire.Number = 1
+	kplRecordData              protowire.Number = 3
+)
+
+type aggregatedRecord struct {
+	keyIndex uint64
+	data     []byte
+}
+
+// aggregator collects multiple user records into a single Kinesis record
+// using the KPL aggregation format. Consumers using the Kinesis Client
+// Library (KCL) or the KPL deaggregation modules transparently unpack the
+// contained records.
+type aggregator struct {
+	keys    []string
+	index   map[string]uint64
+	records []aggregatedRecord
+	metrics []int
+	size    int
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{
+		index: make(map[string]uint64),
+		size:  len(kplMagic) + md5.Size,
+	}
+}
+
+// sizeWith returns the encoded size of the aggregated record if the given
+// record would be added
+func (a *aggregator) sizeWith(key string, data []byte) int {
+	size := a.size
+	idx, found := a.index[key]
+	if !found {
+		idx = uint64(len(a.keys))
+		size += protowire.SizeTag(kplPartitionKeyTable) + protowire.SizeBytes(len(key))
+	}
+	return size + protowire.SizeTag(kplRecords) + protowire.SizeBytes(recordSize(idx, data))
+}
+
+func (a *aggregator) add(key string, data []byte, metricIndex int) {
+	a.size = a.sizeWith(key, data)
+	idx, found := a.index[key]
+	if !found {
+		idx = uint64(len(a.keys))
+		a.index[key] = idx
+		a.keys = append(a.keys, key)
+	}
+	a.records = append(a.records, aggregatedRecord{keyIndex: idx, data: data})
+	a.metrics = append(a.metrics, metricIndex)
+}
+
+// encode returns the aggregated record consisting of the magic number, the
+// protobuf encoded records and the MD5 checksum of the protobuf message
+func (a *aggregator) encode() []byte {
+	msg := make([]byte, 0, a.size-len(kplMagic)-md5.Size)
+	for _, key := range a.keys {
+		msg = protowire.AppendTag(msg, kplPartitionKeyTable, protowire.BytesType)
+		msg = protowire.AppendString(msg, key)
+	}
+	for _, r := range a.records {
+		msg = protowire.AppendTag(msg, kplRecords, protowire.BytesType)
+		msg = protowire.AppendVarint(msg, uint64(recordSize(r.keyIndex, r.data)))
+		msg = protowire.AppendTag(msg, kplRecordPartitionKeyIndex, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, r.keyIndex)
+		msg = protowire.AppendTag(msg, kplRecordData, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, r.data)
+	}
+	checksum := md5.Sum(msg) //nolint:gosec // MD5 checksum required by the KPL aggregation format
+
+	buf := make([]byte, 0, a.size)
+	buf = append(buf, kplMagic...)
+	buf = append(buf, msg...)
+	return append(buf, checksum[:]...)
+}
+
+func recordSize(keyIndex uint64, data []byte) int {
+	return protowire.SizeTag(kplRecordPartitionKeyIndex) + protowire.SizeVarint(keyIndex) +
+		protowire.SizeTag(kplRecordData) + protowire.SizeBytes(len(data))
+}
@@ -2,16 +2,25 @@
 package kinesis
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	ftypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go"
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -22,15 +31,33 @@ var sampleConfig string
 // Limit set by AWS (https://docs.aws.amazon.com/kinesis/latest/APIReference/API_PutRecords.html)
 const maxRecordsPerRequest uint32 = 500
 
+// Size limits set by AWS for a single record and a request to Kinesis data
+// streams and Firehose respectively
+const (
+	maxRecordSize           = 1024 * 1024
+	maxStreamRequestSize    = 5 * 1024 * 1024
+	maxFirehoseRequestSize  = 4 * 1024 * 1024
+	defaultAggregateMaxSize = 50 * 1024
+)
+
 type (
 	KinesisOutput struct {
-		StreamName string     `toml:"streamname"`
-		Partition  *Partition `toml:"partition"`
-		Debug      bool       `toml:"debug"`
+		StreamName         string          `toml:"streamname"`
+		Target             string          `toml:"target"`
+		Partition          *Partition      `toml:"partition"`
+		Aggregate          bool            `toml:"aggregate"`
+		AggregateMaxSize   config.Size     `toml:"aggregate_max_size"`
+		ThrottleBackoff    config.Duration `toml:"throttle_backoff"`
+		ThrottleBackoffMax config.Duration `toml:"throttle_backoff_max"`
+		Debug              bool            `toml:"debug"`
 
 		Log        telegraf.Logger `toml:"-"`
 		serializer telegraf.Serializer
 		svc        kinesisClient
+		firehose   firehoseClient
+
+		partitionTemplate *template.Template
+		backoffs          map[string]*backoff
 
 		common_aws.CredentialConfig
 	}
@@ -46,12 +73,70 @@ type kinesisClient interface {
 	PutRecords(context.Context, *kinesis.PutRecordsInput, ...func(*kinesis.Options)) (*kinesis.PutRecordsOutput, error)
 }
 
+type firehoseClient interface {
+	PutRecordBatch(context.Context, *firehose.PutRecordBatchInput, ...func(*firehose.Options)) (*firehose.PutRecordBatchOutput, error)
+}
+
+// entry is a record to be sent containing one or more metrics
+type entry struct {
+	key     string
+	data    []byte
+	metrics []int
+}
+
+// backoff is the throttling state of a partition key, as records with the
+// same partition key are always written to the same shard
+type backoff struct {
+	until time.Time
+	delay time.Duration
+}
+
 func (*KinesisOutput) SampleConfig() string {
 	return sampleConfig
 }
 
+func (k *KinesisOutput) Init() error {
+	switch k.Target {
+	case "":
+		k.Target = "stream"
+	case "stream", "firehose":
+	default:
+		return fmt.Errorf("invalid target %q", k.Target)
+	}
+
+	if k.Aggregate {
+		if k.Target == "firehose" {
+			return errors.New("aggregation is not supported for target \"firehose\"")
+		}
+		if k.AggregateMaxSize == 0 {
+			k.AggregateMaxSize = defaultAggregateMaxSize
+		}
+		if k.AggregateMaxSize > maxRecordSize {
+			return fmt.Errorf("aggregate_max_size exceeds the record limit of %d bytes", maxRecordSize)
+		}
+	}
+
+	if k.ThrottleBackoff <= 0 {
+		k.ThrottleBackoff = config.Duration(time.Second)
+	}
+	if k.ThrottleBackoffMax < k.ThrottleBackoff {
+		k.ThrottleBackoffMax = k.ThrottleBackoff
+	}
+	k.backoffs = make(map[string]*backoff)
+
+	if k.Partition != nil && k.Partition.Method == "template" {
+		tmpl, err := template.New("partition").Parse(k.Partition.Key)
+		if err != nil {
+			return fmt.Errorf("parsing partition key template failed: %w", err)
+		}
+		k.partitionTemplate = tmpl
+	}
+
+	return nil
+}
+
 func (k *KinesisOutput) Connect() error {
-	if k.Partition == nil {
+	if k.Partition == nil && k.Target != "firehose" {
 		k.Log.Error("Deprecated partitionkey configuration in use, please consider using outputs.kinesis.partition")
 	}
 
@@ -70,6 +155,15 @@ func (k *KinesisOutput) Connect() error {
 		cfg.BaseEndpoint = &k.EndpointURL
 	}
 
+	if k.Target == "firehose" {
+		svc := firehose.NewFromConfig(cfg)
+		_, err = svc.DescribeDeliveryStream(context.Background(), &firehose.DescribeDeliveryStreamInput{
+			DeliveryStreamName: aws.String(k.StreamName),
+		})
+		k.firehose = svc
+		return err
+	}
+
 	svc := kinesis.NewFromConfig(cfg)
 
 	_, err = svc.DescribeStreamSummary(context.Background(), &kinesis.DescribeStreamSummaryInput{
@@ -87,8 +181,9 @@ func (k *KinesisOutput) SetSerializer(serializer telegraf.Serializer) {
 	k.serializer = serializer
 }
 
-func (k *KinesisOutput) writeKinesis(r []types.PutRecordsRequestEntry) time.Duration {
-	start := time.Now()
+// writeKinesis puts the records to the data stream and returns the error
+// code of each record, empty for successfully written records
+func (k *KinesisOutput) writeKinesis(r []types.PutRecordsRequestEntry) ([]string, error) {
 	payload := &kinesis.PutRecordsInput{
 		Records:    r,
 		StreamName: aws.String(k.StreamName),
@@ -96,20 +191,67 @@ func (k *KinesisOutput) writeKinesis(r []types.PutRecordsRequestEntry) time.Dura
 
 	resp, err := k.svc.PutRecords(context.Background(), payload)
 	if err != nil {
-		k.Log.Errorf("Unable to write to Kinesis : %s", err.Error())
-		return time.Since(start)
+		return nil, err
 	}
 
 	if k.Debug {
 		k.Log.Infof("Wrote: '%+v'", resp)
 	}
 
-	failed := *resp.FailedRecordCount
-	if failed > 0 {
-		k.Log.Errorf("Unable to write %+v of %+v record(s) to Kinesis", failed, len(r))
+	codes := make([]string, len(r))
+	for i, record := range resp.Records {
+		if i < len(codes) && record.ErrorCode != nil {
+			codes[i] = *record.ErrorCode
+			k.Log.Debugf("Writing record failed: %s", aws.ToString(record.ErrorMessage))
+		}
 	}
+	return codes, nil
+}
 
-	return time.Since(start)
+// writeFirehose puts the records to the delivery stream and returns the
+// error code of each record, empty for successfully written records
+func (k *KinesisOutput) writeFirehose(r []ftypes.Record) ([]string, error) {
+	payload := &firehose.PutRecordBatchInput{
+		Records:            r,
+		DeliveryStreamName: aws.String(k.StreamName),
+	}
+
+	resp, err := k.firehose.PutRecordBatch(context.Background(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Debug {
+		k.Log.Infof("Wrote: '%+v'", resp)
+	}
+
+	codes := make([]string, len(r))
+	for i, record := range resp.RequestResponses {
+		if i < len(codes) && record.ErrorCode != nil {
+			codes[i] = *record.ErrorCode
+			k.Log.Debugf("Writing record failed: %s", aws.ToString(record.ErrorMessage))
+		}
+	}
+	return codes, nil
+}
+
+func (k *KinesisOutput) send(entries []entry) ([]string, error) {
+	if k.Target == "firehose" {
+		records := make([]ftypes.Record, 0, len(entries))
+		for _, e := range entries {
+			records = append(records, ftypes.Record{Data: e.data})
+		}
+		return k.writeFirehose(records)
+	}
+
+	records := make([]types.PutRecordsRequestEntry, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, types.PutRecordsRequestEntry{
+			Data:         e.data,
+			PartitionKey: aws.String(e.key),
+		})
+	}
+	return k.writeKinesis(records)
 }
 
 func (k *KinesisOutput) getPartitionKey(metric telegraf.Metric) string {
@@ -132,54 +274,199 @@ func (k *KinesisOutput) getPartitionKey(metric telegraf.Metric) string {
 		}
 		// Default partition name if default is not set
 		return "telegraf"
+	case "template":
+		var buf bytes.Buffer
+		if err := k.partitionTemplate.Execute(&buf, &templateMetric{metric}); err != nil {
+			k.Log.Errorf("Executing partition key template failed: %v", err)
+		}
+		if buf.Len() > 0 {
+			return buf.String()
+		} else if len(k.Partition.Default) > 0 {
+			return k.Partition.Default
+		}
+		return "telegraf"
 	default:
 		k.Log.Errorf("You have configured a Partition method of %q which is not supported", k.Partition.Method)
 		return ""
 	}
 }
 
-func (k *KinesisOutput) Write(metrics []telegraf.Metric) error {
-	var sz uint32
+// throttled returns true if the given partition key is within its backoff
+// period after exceeding the throughput of its shard
+func (k *KinesisOutput) throttled(key string, now time.Time) bool {
+	b, found := k.backoffs[key]
+	return found && now.Before(b.until)
+}
+
+// throttle starts or extends the backoff period of the given partition key
+func (k *KinesisOutput) throttle(key string, now time.Time) {
+	b, found := k.backoffs[key]
+	if !found {
+		b = &backoff{delay: time.Duration(k.ThrottleBackoff)}
+		k.backoffs[key] = b
+	} else {
+		b.delay = min(2*b.delay, time.Duration(k.ThrottleBackoffMax))
+	}
+	b.until = now.Add(b.delay)
+}
 
+func (k *KinesisOutput) Write(metrics []telegraf.Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
-	r := make([]types.PutRecordsRequestEntry, 0, len(metrics))
-	for _, metric := range metrics {
-		sz++
+	// Forget about partition keys not being throttled for a while to keep
+	// the state bounded, e.g. for random keys
+	now := time.Now()
+	for key, b := range k.backoffs {
+		if now.Sub(b.until) > time.Duration(k.ThrottleBackoffMax) {
+			delete(k.backoffs, key)
+		}
+	}
+
+	// Serialize the metrics skipping all metrics of throttled partitions
+	entries := make([]entry, 0, len(metrics))
+	accepted := make([]int, 0, len(metrics))
+	aggregators := make(map[string]*aggregator)
+	var groups []string
+	var skipped int
+	for i, metric := range metrics {
+		var partitionKey string
+		if k.Target != "firehose" {
+			partitionKey = k.getPartitionKey(metric)
+		}
+		if k.throttled(partitionKey, now) {
+			skipped++
+			continue
+		}
 
 		values, err := k.serializer.Serialize(metric)
 		if err != nil {
 			k.Log.Debugf("Could not serialize metric: %v", err)
+			accepted = append(accepted, i)
 			continue
 		}
 
-		partitionKey := k.getPartitionKey(metric)
+		if !k.Aggregate {
+			entries = append(entries, entry{key: partitionKey, data: values, metrics: []int{i}})
+			continue
+		}
 
-		d := types.PutRecordsRequestEntry{
-			Data:         values,
-			PartitionKey: aws.String(partitionKey),
+		// Aggregate records per partition key to keep the shard assignment,
+		// random keys are not bound to a shard so aggregate them all together
+		group := partitionKey
+		if k.Partition != nil && k.Partition.Method == "random" {
+			group = ""
+		}
+		agg, found := aggregators[group]
+		if !found {
+			agg = newAggregator()
+			aggregators[group] = agg
+			groups = append(groups, group)
+		} else if agg.sizeWith(partitionKey, values) > int(k.AggregateMaxSize) {
+			entries = append(entries, entry{key: agg.keys[0], data: agg.encode(), metrics: agg.metrics})
+			agg = newAggregator()
+			aggregators[group] = agg
 		}
+		agg.add(partitionKey, values, i)
+	}
+	for _, group := range groups {
+		agg := aggregators[group]
+		entries = append(entries, entry{key: agg.keys[0], data: agg.encode(), metrics: agg.metrics})
+	}
 
-		r = append(r, d)
-		if sz == maxRecordsPerRequest {
-			elapsed := k.writeKinesis(r)
-			k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
-			sz = 0
-			r = nil
+	// Send the records in chunks not exceeding the request limits
+	maxRequestSize := maxStreamRequestSize
+	if k.Target == "firehose" {
+		maxRequestSize = maxFirehoseRequestSize
+	}
+	var rejected []int
+	var rejectErrors []error
+	var failed int
+	for len(entries) > 0 {
+		var n, size int
+		for n < len(entries) && n < int(maxRecordsPerRequest) {
+			recordSize := len(entries[n].data) + len(entries[n].key)
+			if n > 0 && size+recordSize > maxRequestSize {
+				break
+			}
+			size += recordSize
+			n++
 		}
+		chunk := entries[:n]
+		entries = entries[n:]
+
+		start := time.Now()
+		codes, err := k.send(chunk)
+		if err != nil {
+			k.Log.Errorf("Unable to write to Kinesis : %s", err.Error())
+			// Keep the metrics for retrying unless the request is invalid
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) && apiErr.ErrorFault() == smithy.FaultClient && !isThrottling(apiErr.ErrorCode()) {
+				for _, e := range chunk {
+					for _, idx := range e.metrics {
+						rejected = append(rejected, idx)
+						rejectErrors = append(rejectErrors, err)
+					}
+				}
+				continue
+			}
+			for _, e := range chunk {
+				failed += len(e.metrics)
+			}
+			continue
+		}
+		k.Log.Debugf("Wrote a %d record batch to Kinesis in %+v.", len(chunk), time.Since(start))
+
+		var failedRecords int
+		for i, e := range chunk {
+			switch {
+			case codes[i] == "":
+				accepted = append(accepted, e.metrics...)
+				delete(k.backoffs, e.key)
+			case isThrottling(codes[i]):
+				k.throttle(e.key, now)
+				failed += len(e.metrics)
+				failedRecords++
+			default:
+				failed += len(e.metrics)
+				failedRecords++
+			}
+		}
+		if failedRecords > 0 {
+			k.Log.Errorf("Unable to write %+v of %+v record(s) to Kinesis", failedRecords, len(chunk))
+		}
+	}
+
+	if len(accepted) == len(metrics) {
+		return nil
 	}
-	if sz > 0 {
-		elapsed := k.writeKinesis(r)
-		k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
+	return &internal.PartialWriteError{
+		Err: fmt.Errorf("%d metric(s) failed, %d throttled and %d rejected",
+			failed, skipped, len(rejected)),
+		MetricsAccept:       accepted,
+		MetricsReject:       rejected,
+		MetricsRejectErrors: rejectErrors,
 	}
+}
 
-	return nil
+// isThrottling returns true for error codes indicating the throughput limit
+// of the shard or stream is exceeded
+func isThrottling(code string) bool {
+	switch code {
+	case "ProvisionedThroughputExceededException", "ServiceUnavailableException", "ThrottlingException":
+		return true
+	}
+	return false
 }
 
 func init() {
 	outputs.Add("kinesis", func() telegraf.Output {
-		return &KinesisOutput{}
+		return &KinesisOutput{
+			Target:             "stream",
+			AggregateMaxSize:   config.Size(defaultAggregateMaxSize),
+			ThrottleBackoff:    config.Duration(time.Second),
+			ThrottleBackoffMax: config.Duration(time.Minute),
+		}
 	})
 }
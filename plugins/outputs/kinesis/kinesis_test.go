@@ -2,16 +2,24 @@ package kinesis
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec // MD5 checksum required by the KPL aggregation format
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	ftypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
 	"github.com/gofrs/uuid/v5"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -20,7 +28,6 @@ const testPartitionKey = "partitionKey"
 const testShardID = "shardId-000000000003"
 const testSequenceNumber = "49543463076570308322303623326179887152428262250726293588"
 const testStreamName = "streamName"
-const zero int64 = 0
 
 func TestPartitionKey(t *testing.T) {
 	testPoint := testutil.TestMetric(1)
@@ -115,8 +122,9 @@ func TestWriteKinesis_WhenSuccess(t *testing.T) {
 		svc:        svc,
 	}
 
-	elapsed := k.writeKinesis(records)
-	require.GreaterOrEqual(t, elapsed.Nanoseconds(), zero)
+	codes, err := k.writeKinesis(records)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, codes)
 
 	svc.AssertRequests(t, []*kinesis.PutRecordsInput{
 		{
@@ -151,8 +159,9 @@ func TestWriteKinesis_WhenRecordErrors(t *testing.T) {
 		svc:        svc,
 	}
 
-	elapsed := k.writeKinesis(records)
-	require.GreaterOrEqual(t, elapsed.Nanoseconds(), zero)
+	codes, err := k.writeKinesis(records)
+	require.NoError(t, err)
+	require.Equal(t, []string{"InternalFailure"}, codes)
 
 	svc.AssertRequests(t, []*kinesis.PutRecordsInput{
 		{
@@ -180,8 +189,8 @@ func TestWriteKinesis_WhenServiceError(t *testing.T) {
 		svc:        svc,
 	}
 
-	elapsed := k.writeKinesis(records)
-	require.GreaterOrEqual(t, elapsed.Nanoseconds(), zero)
+	_, err := k.writeKinesis(records)
+	require.Error(t, err)
 
 	svc.AssertRequests(t, []*kinesis.PutRecordsInput{
 		{
@@ -579,3 +588,350 @@ func createPutRecordsRequestEntries(
 
 	return records
 }
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *KinesisOutput
+		expected string
+	}{
+		{
+			name:     "invalid target",
+			plugin:   &KinesisOutput{Target: "foo"},
+			expected: `invalid target "foo"`,
+		},
+		{
+			name:     "aggregation with firehose",
+			plugin:   &KinesisOutput{Target: "firehose", Aggregate: true},
+			expected: "aggregation is not supported",
+		},
+		{
+			name:     "aggregate size too large",
+			plugin:   &KinesisOutput{Aggregate: true, AggregateMaxSize: 2 * maxRecordSize},
+			expected: "aggregate_max_size exceeds the record limit",
+		},
+		{
+			name:     "invalid template",
+			plugin:   &KinesisOutput{Partition: &Partition{Method: "template", Key: "{{.Name"}},
+			expected: "parsing partition key template failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPartitionKeyTemplate(t *testing.T) {
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method:  "template",
+			Key:     `{{.Tag "region"}}-{{.Name}}`,
+			Default: "somedefault",
+		},
+	}
+	require.NoError(t, k.Init())
+
+	m := metric.New("cpu", map[string]string{"region": "eu"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, "eu-cpu", k.getPartitionKey(m))
+
+	k.Partition.Key = `{{.Tag "region"}}`
+	require.NoError(t, k.Init())
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, "somedefault", k.getPartitionKey(m))
+}
+
+func TestWrite_Aggregation(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(2, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "tag",
+			Key:    "region",
+		},
+		Aggregate:  true,
+		StreamName: testStreamName,
+		serializer: serializer,
+		svc:        svc,
+	}
+	require.NoError(t, k.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"region": "eu"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"region": "us"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"region": "eu"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	require.NoError(t, k.Write(metrics))
+
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 2)
+
+	expected := map[string][][]byte{
+		"eu": {mustSerialize(t, serializer, metrics[0]), mustSerialize(t, serializer, metrics[2])},
+		"us": {mustSerialize(t, serializer, metrics[1])},
+	}
+	for _, r := range records {
+		key := aws.ToString(r.PartitionKey)
+		keys, data := deaggregate(t, r.Data)
+		for _, pk := range keys {
+			require.Equal(t, key, pk)
+		}
+		require.Equal(t, expected[key], data)
+	}
+}
+
+func TestWrite_AggregationMaxSize(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(5, 0)
+
+	metrics, metricsData := createTestMetrics(t, 10, serializer)
+
+	// Allow two metrics per aggregated record
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "static",
+			Key:    testPartitionKey,
+		},
+		Aggregate:        true,
+		AggregateMaxSize: config.Size(2*len(metricsData[0]) + 50),
+		StreamName:       testStreamName,
+		serializer:       serializer,
+		svc:              svc,
+	}
+	require.NoError(t, k.Init())
+	require.NoError(t, k.Write(metrics))
+
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 5)
+	var actual [][]byte
+	for _, r := range records {
+		require.LessOrEqual(t, len(r.Data), int(k.AggregateMaxSize))
+		_, data := deaggregate(t, r.Data)
+		actual = append(actual, data...)
+	}
+	require.Equal(t, metricsData, actual)
+}
+
+func TestWrite_Throttled(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupResponse(
+		1,
+		[]types.PutRecordsResultEntry{
+			{
+				SequenceNumber: aws.String(testSequenceNumber),
+				ShardId:        aws.String(testShardID),
+			},
+			{
+				ErrorCode:    aws.String("ProvisionedThroughputExceededException"),
+				ErrorMessage: aws.String("Rate exceeded for shard"),
+			},
+		},
+	)
+	svc.SetupGenericResponse(1, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "tag",
+			Key:    "region",
+		},
+		ThrottleBackoff:    config.Duration(time.Hour),
+		ThrottleBackoffMax: config.Duration(time.Hour),
+		StreamName:         testStreamName,
+		serializer:         serializer,
+		svc:                svc,
+	}
+	require.NoError(t, k.Init())
+
+	eu := metric.New("cpu", map[string]string{"region": "eu"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	us := metric.New("cpu", map[string]string{"region": "us"}, map[string]interface{}{"value": 2}, time.Unix(0, 0))
+
+	// The throttled metric must be kept for retrying
+	err := k.Write([]telegraf.Metric{eu, us})
+	var pwErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &pwErr)
+	require.Equal(t, []int{0}, pwErr.MetricsAccept)
+	require.Empty(t, pwErr.MetricsReject)
+
+	// Metrics of the throttled partition are skipped during the backoff
+	err = k.Write([]telegraf.Metric{us, eu})
+	require.ErrorAs(t, err, &pwErr)
+	require.Equal(t, []int{1}, pwErr.MetricsAccept)
+	require.Empty(t, pwErr.MetricsReject)
+
+	require.Len(t, svc.requests, 2)
+	require.Len(t, svc.requests[1].Records, 1)
+	require.Equal(t, "eu", aws.ToString(svc.requests[1].Records[0].PartitionKey))
+}
+
+func TestWrite_ServiceError(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupErrorResponse(&types.InvalidArgumentException{Message: aws.String("Invalid record")})
+	svc.SetupErrorResponse(&types.ProvisionedThroughputExceededException{Message: aws.String("Rate exceeded")})
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "static",
+			Key:    testPartitionKey,
+		},
+		StreamName: testStreamName,
+		serializer: serializer,
+		svc:        svc,
+	}
+	require.NoError(t, k.Init())
+
+	metrics, _ := createTestMetrics(t, 2, serializer)
+
+	// Invalid requests are rejected
+	err := k.Write(metrics)
+	var pwErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &pwErr)
+	require.Empty(t, pwErr.MetricsAccept)
+	require.Equal(t, []int{0, 1}, pwErr.MetricsReject)
+
+	// Throttled requests are kept for retrying
+	err = k.Write(metrics)
+	require.ErrorAs(t, err, &pwErr)
+	require.Empty(t, pwErr.MetricsAccept)
+	require.Empty(t, pwErr.MetricsReject)
+}
+
+func TestWrite_Firehose(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockFirehosePutRecordBatch{
+		responses: []*firehose.PutRecordBatchOutput{
+			{
+				FailedPutCount: aws.Int32(1),
+				RequestResponses: []ftypes.PutRecordBatchResponseEntry{
+					{RecordId: aws.String("1")},
+					{ErrorCode: aws.String("ServiceUnavailableException"), ErrorMessage: aws.String("Slow down")},
+				},
+			},
+		},
+	}
+
+	k := KinesisOutput{
+		Log:        testutil.Logger{},
+		Target:     "firehose",
+		StreamName: testStreamName,
+		serializer: serializer,
+		firehose:   svc,
+	}
+	require.NoError(t, k.Init())
+
+	metrics, metricsData := createTestMetrics(t, 2, serializer)
+	err := k.Write(metrics)
+	var pwErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &pwErr)
+	require.Equal(t, []int{0}, pwErr.MetricsAccept)
+	require.Empty(t, pwErr.MetricsReject)
+
+	require.Len(t, svc.requests, 1)
+	require.Equal(t, testStreamName, aws.ToString(svc.requests[0].DeliveryStreamName))
+	require.Len(t, svc.requests[0].Records, 2)
+	for i, r := range svc.requests[0].Records {
+		require.Equal(t, metricsData[i], r.Data)
+	}
+}
+
+type mockFirehosePutRecordBatch struct {
+	requests  []*firehose.PutRecordBatchInput
+	responses []*firehose.PutRecordBatchOutput
+}
+
+func (m *mockFirehosePutRecordBatch) PutRecordBatch(
+	_ context.Context,
+	input *firehose.PutRecordBatchInput,
+	_ ...func(*firehose.Options),
+) (*firehose.PutRecordBatchOutput, error) {
+	reqNum := len(m.requests)
+	if reqNum >= len(m.responses) {
+		return nil, fmt.Errorf("response for request %+v not setup", reqNum)
+	}
+	m.requests = append(m.requests, input)
+	return m.responses[reqNum], nil
+}
+
+func mustSerialize(t *testing.T, serializer telegraf.Serializer, m telegraf.Metric) []byte {
+	data, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	return data
+}
+
+// deaggregate decodes a record in KPL aggregation format and returns the
+// partition keys and data of the contained records
+func deaggregate(t *testing.T, buf []byte) (keys []string, data [][]byte) {
+	t.Helper()
+
+	require.Equal(t, kplMagic, buf[:len(kplMagic)])
+	msg := buf[len(kplMagic) : len(buf)-md5.Size]
+	checksum := md5.Sum(msg) //nolint:gosec // MD5 checksum required by the KPL aggregation format
+	require.Equal(t, checksum[:], buf[len(buf)-md5.Size:])
+
+	var table []string
+	var indices []uint64
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		require.GreaterOrEqual(t, n, 0)
+		require.Equal(t, protowire.BytesType, typ)
+		msg = msg[n:]
+		value, n := protowire.ConsumeBytes(msg)
+		require.GreaterOrEqual(t, n, 0)
+		msg = msg[n:]
+
+		switch num {
+		case kplPartitionKeyTable:
+			table = append(table, string(value))
+		case kplRecords:
+			var idx uint64
+			var content []byte
+			for len(value) > 0 {
+				rnum, rtyp, rn := protowire.ConsumeTag(value)
+				require.GreaterOrEqual(t, rn, 0)
+				value = value[rn:]
+				if rtyp == protowire.VarintType {
+					v, vn := protowire.ConsumeVarint(value)
+					require.GreaterOrEqual(t, vn, 0)
+					require.Equal(t, kplRecordPartitionKeyIndex, rnum)
+					idx = v
+					value = value[vn:]
+					continue
+				}
+				v, vn := protowire.ConsumeBytes(value)
+				require.GreaterOrEqual(t, vn, 0)
+				require.Equal(t, kplRecordData, rnum)
+				content = v
+				value = value[vn:]
+			}
+			indices = append(indices, idx)
+			data = append(data, content)
+		}
+	}
+	for _, idx := range indices {
+		require.Less(t, idx, uint64(len(table)))
+		keys = append(keys, table[idx])
+	}
+	return keys, data
+}
@@ -0,0 +1,29 @@
+package kinesis
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// templateMetric exposes the metric to the partition key template
+type templateMetric struct {
+	metric telegraf.Metric
+}
+
+func (m *templateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *templateMetric) Tag(key string) string {
+	v, _ := m.metric.GetTag(key)
+	return v
+}
+
+func (m *templateMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+func (m *templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
@@ -2,8 +2,10 @@
 package mqtt
 
 import (
+	"bytes"
 	// Blank import to support go:embed compile directive
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -30,20 +33,29 @@ var hostnameRe = regexp.MustCompile(`({{.*\B)\.Hostname(\b[^}]*}})`)
 type message struct {
 	topic   string
 	payload []byte
+	retain  bool
+}
+
+// batchKey identifies the messages of batched layouts
+type batchKey struct {
+	topic  string
+	retain bool
 }
 
 type MQTT struct {
-	Topic           string          `toml:"topic"`
-	BatchMessage    bool            `toml:"batch" deprecated:"1.25.2;1.35.0;use 'layout = \"batch\"' instead"`
-	Layout          string          `toml:"layout"`
-	HomieDeviceName string          `toml:"homie_device_name"`
-	HomieNodeID     string          `toml:"homie_node_id"`
-	Log             telegraf.Logger `toml:"-"`
+	Topic              string          `toml:"topic"`
+	BatchMessage       bool            `toml:"batch" deprecated:"1.25.2;1.35.0;use 'layout = \"batch\"' instead"`
+	Layout             string          `toml:"layout"`
+	HomieDeviceName    string          `toml:"homie_device_name"`
+	HomieNodeID        string          `toml:"homie_node_id"`
+	RetainMeasurements []string        `toml:"retain_measurements"`
+	Log                telegraf.Logger `toml:"-"`
 	mqtt.MqttConfig
 
-	client     mqtt.Client
-	serializer telegraf.Serializer
-	template   *template.Template
+	client       mqtt.Client
+	serializer   telegraf.Serializer
+	template     *template.Template
+	retainFilter filter.Filter
 
 	homieDeviceNameGenerator *template.Template
 	homieNodeIDGenerator     *template.Template
@@ -91,7 +103,7 @@ func (m *MQTT) Init() error {
 		} else {
 			m.Layout = "non-batch"
 		}
-	case "non-batch", "batch", "field":
+	case "non-batch", "batch", "json-array", "field":
 	case "homie-v4":
 		if m.HomieDeviceName == "" {
 			return errors.New("missing 'homie_device_name' option")
@@ -116,6 +128,11 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	m.retainFilter, err = filter.Compile(m.RetainMeasurements)
+	if err != nil {
+		return fmt.Errorf("creating retain filter failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -167,6 +184,8 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	switch m.Layout {
 	case "batch":
 		topicMessages = m.collectBatch(metrics)
+	case "json-array":
+		topicMessages = m.collectJSONArray(metrics)
 	case "non-batch":
 		topicMessages = m.collectNonBatch(metrics)
 	case "field":
@@ -178,7 +197,11 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	}
 
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+		publish := m.client.Publish
+		if msg.retain {
+			publish = m.client.PublishRetained
+		}
+		if err := publish(msg.topic, msg.payload); err != nil {
 			// We do receive a timeout error if the remote broker is down,
 			// so let's retry the metrics in this case and drop them otherwise.
 			if errors.Is(err, internal.ErrTimeout) {
@@ -207,36 +230,91 @@ func (m *MQTT) collectNonBatch(metrics []telegraf.Metric) []message {
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.retained(metric)})
 	}
 
 	return collection
 }
 
 func (m *MQTT) collectBatch(metrics []telegraf.Metric) []message {
-	metricsCollection := make(map[string][]telegraf.Metric)
-	for _, metric := range metrics {
-		topic, err := m.generateTopic(metric)
+	keys, metricsCollection := m.groupBatches(metrics)
+
+	collection := make([]message, 0, len(keys))
+	for _, key := range keys {
+		buf, err := m.serializer.SerializeBatch(metricsCollection[key])
 		if err != nil {
-			m.Log.Warnf("Generating topic name failed: %v", err)
-			m.Log.Debugf("metric was: %v", metric)
+			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", key.topic, err)
 			continue
 		}
-		metricsCollection[topic] = append(metricsCollection[topic], metric)
+		collection = append(collection, message{key.topic, buf, key.retain})
 	}
+	return collection
+}
 
-	collection := make([]message, 0, len(metricsCollection))
-	for topic, ms := range metricsCollection {
-		buf, err := m.serializer.SerializeBatch(ms)
-		if err != nil {
-			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
+// collectJSONArray sends the metrics of each topic as a JSON array with the
+// serialized metrics as elements. The data format must thus produce a
+// single JSON value per metric.
+func (m *MQTT) collectJSONArray(metrics []telegraf.Metric) []message {
+	keys, metricsCollection := m.groupBatches(metrics)
+
+	collection := make([]message, 0, len(keys))
+	for _, key := range keys {
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for _, metric := range metricsCollection[key] {
+			element, err := m.serializer.Serialize(metric)
+			if err != nil {
+				m.Log.Warnf("Could not serialize metric for topic %q: %v", key.topic, err)
+				m.Log.Debugf("metric was: %v", metric)
+				continue
+			}
+			element = bytes.TrimSpace(element)
+			if !json.Valid(element) {
+				m.Log.Warnf("Serialized metric for topic %q is not a valid JSON value", key.topic)
+				m.Log.Debugf("metric was: %v", metric)
+				continue
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(element)
+		}
+		if buf.Len() == 1 {
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		buf.WriteByte(']')
+		collection = append(collection, message{key.topic, buf.Bytes(), key.retain})
 	}
 	return collection
 }
 
+// groupBatches groups the metrics by topic and retain flag keeping the order
+// of the topics.
+func (m *MQTT) groupBatches(metrics []telegraf.Metric) ([]batchKey, map[batchKey][]telegraf.Metric) {
+	var keys []batchKey
+	metricsCollection := make(map[batchKey][]telegraf.Metric)
+	for _, metric := range metrics {
+		topic, err := m.generateTopic(metric)
+		if err != nil {
+			m.Log.Warnf("Generating topic name failed: %v", err)
+			m.Log.Debugf("metric was: %v", metric)
+			continue
+		}
+		key := batchKey{topic, m.retained(metric)}
+		if _, found := metricsCollection[key]; !found {
+			keys = append(keys, key)
+		}
+		metricsCollection[key] = append(metricsCollection[key], metric)
+	}
+	return keys, metricsCollection
+}
+
+// retained returns true if the messages of the metric must be retained by the
+// broker independent of the global retain setting.
+func (m *MQTT) retained(metric telegraf.Metric) bool {
+	return m.retainFilter != nil && m.retainFilter.Match(metric.Name())
+}
+
 func (m *MQTT) collectField(metrics []telegraf.Metric) []message {
 	var collection []message
 	for _, metric := range metrics {
@@ -254,7 +332,7 @@ func (m *MQTT) collectField(metrics []telegraf.Metric) []message {
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic + "/" + n, []byte(buf), m.retained(metric)})
 		}
 	}
 
@@ -280,12 +358,15 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 		path := topic + "/" + nodeID
 		collection = append(collection, msgs...)
 
+		// Only the property values are retained, the device and node
+		// attributes are updated when seen for the first time anyway
+		retain := m.retained(metric)
 		for _, tag := range metric.TagList() {
 			propID := normalizeID(tag.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(tag.Value)},
-				message{path + "/" + propID + "/$name", []byte(tag.Key)},
-				message{path + "/" + propID + "/$datatype", []byte("string")},
+				message{path + "/" + propID, []byte(tag.Value), retain},
+				message{path + "/" + propID + "/$name", []byte(tag.Key), false},
+				message{path + "/" + propID + "/$datatype", []byte("string"), false},
 			)
 		}
 
@@ -298,9 +379,9 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 			}
 			propID := normalizeID(field.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(v)},
-				message{path + "/" + propID + "/$name", []byte(field.Key)},
-				message{path + "/" + propID + "/$datatype", []byte(dt)},
+				message{path + "/" + propID, []byte(v), retain},
+				message{path + "/" + propID + "/$name", []byte(field.Key), false},
+				message{path + "/" + propID + "/$datatype", []byte(dt), false},
 			)
 		}
 	}
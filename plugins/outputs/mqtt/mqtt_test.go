@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
+	serializers_json "github.com/influxdata/telegraf/plugins/serializers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -508,7 +509,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -625,7 +626,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -935,3 +936,141 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteJSONArray(t *testing.T) {
+	serializer := &serializers_json.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &fakeClient{}
+	plugin := &MQTT{
+		Topic:      `telegraf/{{ .Name }}/{{ .Tag "room" }}`,
+		Layout:     "json-array",
+		MqttConfig: mqtt.MqttConfig{Servers: []string{"tcp://localhost:1883"}},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.SetSerializer(serializer)
+	plugin.client = client
+
+	metrics := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"room": "kitchen"}, map[string]interface{}{"temperature": 21.4}, time.Unix(1676522982, 0)),
+		metric.New("sensor", map[string]string{"room": "bath"}, map[string]interface{}{"temperature": 23.0}, time.Unix(1676522982, 0)),
+		metric.New("sensor", map[string]string{"room": "kitchen"}, map[string]interface{}{"temperature": 21.6}, time.Unix(1676522992, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, client.published, 2)
+	require.Equal(t, "telegraf/sensor/kitchen", client.published[0].topic)
+	require.JSONEq(t, `[
+		{"fields": {"temperature": 21.4}, "name": "sensor", "tags": {"room": "kitchen"}, "timestamp": 1676522982},
+		{"fields": {"temperature": 21.6}, "name": "sensor", "tags": {"room": "kitchen"}, "timestamp": 1676522992}
+	]`, string(client.published[0].payload))
+	require.Equal(t, "telegraf/sensor/bath", client.published[1].topic)
+	require.JSONEq(t, `[
+		{"fields": {"temperature": 23}, "name": "sensor", "tags": {"room": "bath"}, "timestamp": 1676522982}
+	]`, string(client.published[1].payload))
+}
+
+func TestWriteJSONArrayInvalidFormat(t *testing.T) {
+	serializer := &serializers_influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &fakeClient{}
+	plugin := &MQTT{
+		Topic:      "telegraf",
+		Layout:     "json-array",
+		MqttConfig: mqtt.MqttConfig{Servers: []string{"tcp://localhost:1883"}},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.SetSerializer(serializer)
+	plugin.client = client
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(1.0)}))
+	require.Empty(t, client.published)
+}
+
+func TestRetainMeasurements(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("status", map[string]string{}, map[string]interface{}{"online": true}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		layout   string
+		expected []message
+	}{
+		{
+			layout: "non-batch",
+			expected: []message{
+				{topic: "telegraf", payload: []byte("status online=true 0\n"), retain: true},
+				{topic: "telegraf", payload: []byte("cpu usage=42 0\n")},
+			},
+		},
+		{
+			layout: "batch",
+			expected: []message{
+				{topic: "telegraf", payload: []byte("status online=true 0\n"), retain: true},
+				{topic: "telegraf", payload: []byte("cpu usage=42 0\n")},
+			},
+		},
+		{
+			layout: "field",
+			expected: []message{
+				{topic: "telegraf/online", payload: []byte("true"), retain: true},
+				{topic: "telegraf/usage", payload: []byte("42")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			serializer := &serializers_influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			client := &fakeClient{}
+			plugin := &MQTT{
+				Topic:              "telegraf",
+				Layout:             tt.layout,
+				RetainMeasurements: []string{"stat*"},
+				MqttConfig:         mqtt.MqttConfig{Servers: []string{"tcp://localhost:1883"}},
+				Log:                testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			plugin.SetSerializer(serializer)
+			plugin.client = client
+
+			require.NoError(t, plugin.Write(metrics))
+			require.Equal(t, tt.expected, client.published)
+		})
+	}
+}
+
+// fakeClient records the published messages
+type fakeClient struct {
+	published []message
+}
+
+func (*fakeClient) Connect() (bool, error) {
+	return false, nil
+}
+
+func (c *fakeClient) Publish(topic string, data []byte) error {
+	c.published = append(c.published, message{topic: topic, payload: data})
+	return nil
+}
+
+func (c *fakeClient) PublishRetained(topic string, data []byte) error {
+	c.published = append(c.published, message{topic: topic, payload: data, retain: true})
+	return nil
+}
+
+func (*fakeClient) SubscribeMultiple(map[string]byte, paho.MessageHandler) error {
+	return nil
+}
+
+func (*fakeClient) AddRoute(string, paho.MessageHandler) {}
+
+func (*fakeClient) Close() error {
+	return nil
+}
@@ -0,0 +1,162 @@
+package opentelemetry
+
+import (
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Range of scales as defined by the OpenTelemetry specification
+const (
+	maxExponentialScale = 20
+	minExponentialScale = -10
+)
+
+// toExponentialHistograms converts all histograms with explicit buckets to
+// exponential histograms with at most the given number of buckets for the
+// positive and negative range each
+func toExponentialHistograms(md pmetric.Metrics, maxBuckets int) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				m := sm.Metrics().At(k)
+				if m.Type() != pmetric.MetricTypeHistogram {
+					continue
+				}
+
+				explicit := pmetric.NewHistogram()
+				m.Histogram().MoveTo(explicit)
+
+				exponential := m.SetEmptyExponentialHistogram()
+				exponential.SetAggregationTemporality(explicit.AggregationTemporality())
+				for l := 0; l < explicit.DataPoints().Len(); l++ {
+					toExponentialDataPoint(explicit.DataPoints().At(l), exponential.DataPoints().AppendEmpty(), maxBuckets)
+				}
+			}
+		}
+	}
+}
+
+// toExponentialDataPoint converts the data point by assigning the count of
+// each explicit bucket to the exponential bucket containing the upper bound
+// of the explicit bucket. The overflow bucket is assigned to the exponential
+// bucket following the one of the largest bound. The resulting histogram is
+// therefore only as accurate as the explicit buckets.
+func toExponentialDataPoint(src pmetric.HistogramDataPoint, dst pmetric.ExponentialHistogramDataPoint, maxBuckets int) {
+	src.Attributes().CopyTo(dst.Attributes())
+	dst.SetStartTimestamp(src.StartTimestamp())
+	dst.SetTimestamp(src.Timestamp())
+	dst.SetFlags(src.Flags())
+	dst.SetCount(src.Count())
+	if src.HasSum() {
+		dst.SetSum(src.Sum())
+	}
+	if src.HasMin() {
+		dst.SetMin(src.Min())
+	}
+	if src.HasMax() {
+		dst.SetMax(src.Max())
+	}
+
+	// Determine the representative value of each explicit bucket
+	bounds := src.ExplicitBounds().AsRaw()
+	counts := src.BucketCounts().AsRaw()
+	values := make([]float64, len(counts))
+	overflow := make([]bool, len(counts))
+	for i := range counts {
+		switch {
+		case i < len(bounds):
+			values[i] = bounds[i]
+		case len(bounds) > 0:
+			values[i] = bounds[len(bounds)-1]
+			overflow[i] = true
+		case src.Count() > 0:
+			values[i] = src.Sum() / float64(src.Count())
+		}
+	}
+
+	// Find the largest scale fitting all values into the maximum number of
+	// buckets for both the positive and negative range
+	ranges := func(scale int) (pos, neg *bucketRange) {
+		pos = newBucketRange()
+		neg = newBucketRange()
+		for i, v := range values {
+			if counts[i] == 0 {
+				continue
+			}
+			switch {
+			case v > 0:
+				pos.add(exponentialIndex(v, scale, overflow[i]))
+			case v < 0:
+				neg.add(exponentialIndex(-v, scale, false))
+			}
+		}
+		return pos, neg
+	}
+	scale := maxExponentialScale
+	pos, neg := ranges(scale)
+	for scale > minExponentialScale && (pos.size() > maxBuckets || neg.size() > maxBuckets) {
+		scale--
+		pos, neg = ranges(scale)
+	}
+	dst.SetScale(int32(scale))
+
+	positive := make([]uint64, pos.size())
+	negative := make([]uint64, neg.size())
+	var zero uint64
+	for i, v := range values {
+		if counts[i] == 0 {
+			continue
+		}
+		switch {
+		case v > 0:
+			positive[exponentialIndex(v, scale, overflow[i])-pos.min] += counts[i]
+		case v < 0:
+			negative[exponentialIndex(-v, scale, false)-neg.min] += counts[i]
+		default:
+			zero += counts[i]
+		}
+	}
+	dst.SetZeroCount(zero)
+	if len(positive) > 0 {
+		dst.Positive().SetOffset(pos.min)
+		dst.Positive().BucketCounts().FromRaw(positive)
+	}
+	if len(negative) > 0 {
+		dst.Negative().SetOffset(neg.min)
+		dst.Negative().BucketCounts().FromRaw(negative)
+	}
+}
+
+// exponentialIndex returns the index of the exponential bucket containing the
+// value, i.e. the bucket (base^index, base^(index+1)] with base=2^(2^-scale).
+// For overflowing values the next bucket is returned.
+func exponentialIndex(value float64, scale int, overflow bool) int32 {
+	index := int32(math.Ceil(math.Ldexp(math.Log2(value), scale))) - 1
+	if overflow {
+		index++
+	}
+	return index
+}
+
+type bucketRange struct {
+	min, max int32
+}
+
+func newBucketRange() *bucketRange {
+	return &bucketRange{min: math.MaxInt32, max: math.MinInt32}
+}
+
+func (r *bucketRange) add(index int32) {
+	r.min = min(r.min, index)
+	r.max = max(r.max, index)
+}
+
+func (r *bucketRange) size() int {
+	if r.max < r.min {
+		return 0
+	}
+	return int(r.max-r.min) + 1
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	ntls "crypto/tls"
 	_ "embed"
+	"fmt"
 	"sort"
 	"time"
 
@@ -39,8 +40,15 @@ type OpenTelemetry struct {
 	Attributes  map[string]string `toml:"attributes"`
 	Coralogix   *CoralogixConfig  `toml:"coralogix"`
 
+	ResourceAttributes             []*resourceRule `toml:"resource_attribute"`
+	Temporality                    string          `toml:"temporality"`
+	DeltaExpiryInterval            config.Duration `toml:"delta_expiry_interval"`
+	HistogramFormat                string          `toml:"histogram_format"`
+	ExponentialHistogramMaxBuckets int             `toml:"exponential_histogram_max_buckets"`
+
 	Log telegraf.Logger `toml:"-"`
 
+	deltaConverter       *deltaConverter
 	metricsConverter     *influx2otel.LineProtocolToOtelMetrics
 	grpcClientConn       *grpc.ClientConn
 	metricsServiceClient pmetricotlp.GRPCClient
@@ -57,6 +65,34 @@ func (*OpenTelemetry) SampleConfig() string {
 	return sampleConfig
 }
 
+func (o *OpenTelemetry) Init() error {
+	for i, rule := range o.ResourceAttributes {
+		if err := rule.init(); err != nil {
+			return fmt.Errorf("resource attribute rule %d: %w", i+1, err)
+		}
+	}
+
+	switch o.Temporality {
+	case "", "cumulative":
+	case "delta":
+		o.deltaConverter = newDeltaConverter(time.Duration(o.DeltaExpiryInterval))
+	default:
+		return fmt.Errorf("invalid temporality %q", o.Temporality)
+	}
+
+	switch o.HistogramFormat {
+	case "", "explicit":
+	case "exponential":
+		if o.ExponentialHistogramMaxBuckets < 1 {
+			return fmt.Errorf("invalid maximum number of exponential histogram buckets %d", o.ExponentialHistogramMaxBuckets)
+		}
+	default:
+		return fmt.Errorf("invalid histogram format %q", o.HistogramFormat)
+	}
+
+	return nil
+}
+
 func (o *OpenTelemetry) Connect() error {
 	logger := &otelLogger{o.Log}
 
@@ -150,7 +186,10 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
-	batch := o.metricsConverter.NewBatch()
+	// Group the metrics by the promoted resource attributes as the converter
+	// only uses tags matching the semantic conventions as resource attributes
+	batches := make(map[string]*resourceBatch)
+	keys := make([]string, 0, 1)
 	for _, metric := range metrics {
 		var vType common.InfluxMetricValueType
 		switch metric.Type() {
@@ -168,15 +207,49 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 			o.Log.Warnf("Unrecognized metric type %v", metric.Type())
 			continue
 		}
-		err := batch.AddPoint(metric.Name(), metric.Tags(), metric.Fields(), metric.Time(), vType)
+
+		tags := metric.Tags()
+		attributes := promote(o.ResourceAttributes, tags)
+		key := attributesKey(attributes)
+		batch, found := batches[key]
+		if !found {
+			batch = &resourceBatch{attributes: attributes, batch: o.metricsConverter.NewBatch()}
+			batches[key] = batch
+			keys = append(keys, key)
+		}
+
+		err := batch.batch.AddPoint(metric.Name(), tags, metric.Fields(), metric.Time(), vType)
 		if err != nil {
 			o.Log.Warnf("Failed to add point: %v", err)
 			continue
 		}
 	}
 
-	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
+	md := pmetricotlp.NewExportRequest()
+	for _, key := range keys {
+		batch := batches[key]
+		converted := batch.batch.GetMetrics()
+		for i := 0; i < converted.ResourceMetrics().Len(); i++ {
+			for k, v := range batch.attributes {
+				converted.ResourceMetrics().At(i).Resource().Attributes().PutStr(k, v)
+			}
+		}
+		converted.ResourceMetrics().MoveAndAppendTo(md.Metrics().ResourceMetrics())
+	}
+
+	now := time.Now()
+	var pending map[string]*deltaState
+	if o.deltaConverter != nil {
+		pending = o.deltaConverter.convert(md.Metrics(), now)
+	}
+	if o.HistogramFormat == "exponential" {
+		toExponentialHistograms(md.Metrics(), o.ExponentialHistogramMaxBuckets)
+	}
+
 	if md.Metrics().ResourceMetrics().Len() == 0 {
+		if o.deltaConverter != nil {
+			o.deltaConverter.commit(pending, now)
+		}
 		return nil
 	}
 
@@ -194,8 +267,21 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
 	}
 	defer cancel()
-	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
-	return err
+	if _, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...); err != nil {
+		return err
+	}
+
+	// Only keep the state of the delta conversion if the data was sent to
+	// not lose the deltas of failed writes on retry
+	if o.deltaConverter != nil {
+		o.deltaConverter.commit(pending, now)
+	}
+	return nil
+}
+
+type resourceBatch struct {
+	attributes map[string]string
+	batch      *influx2otel.MetricsBatch
 }
 
 const (
@@ -210,6 +296,8 @@ func init() {
 			ServiceAddress: defaultServiceAddress,
 			Timeout:        defaultTimeout,
 			Compression:    defaultCompression,
+
+			ExponentialHistogramMaxBuckets: 160,
 		}
 	})
 }
@@ -102,6 +102,7 @@ func newMockOtelService(t *testing.T) *mockOtelService {
 		t:          t,
 		listener:   listener,
 		grpcServer: grpcServer,
+		metrics:    pmetric.NewMetrics(),
 	}
 
 	pmetricotlp.RegisterGRPCServer(grpcServer, mockOtelService)
@@ -144,3 +145,247 @@ func (m *mockOtelService) Export(ctx context.Context, request pmetricotlp.Export
 	require.True(m.t, ok)
 	return pmetricotlp.NewExportResponse(), nil
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *OpenTelemetry
+		expected string
+	}{
+		{
+			name:     "empty resource attribute tag",
+			plugin:   &OpenTelemetry{ResourceAttributes: []*resourceRule{{Attribute: "host.name"}}},
+			expected: "resource attribute rule 1: tag required",
+		},
+		{
+			name:     "rename with wildcard",
+			plugin:   &OpenTelemetry{ResourceAttributes: []*resourceRule{{Tag: "host*", Attribute: "host.name"}}},
+			expected: `resource attribute rule 1: cannot rename tags matching pattern "host*"`,
+		},
+		{
+			name:     "invalid temporality",
+			plugin:   &OpenTelemetry{Temporality: "foo"},
+			expected: `invalid temporality "foo"`,
+		},
+		{
+			name:     "invalid histogram format",
+			plugin:   &OpenTelemetry{HistogramFormat: "foo"},
+			expected: `invalid histogram format "foo"`,
+		},
+		{
+			name:     "invalid bucket limit",
+			plugin:   &OpenTelemetry{HistogramFormat: "exponential"},
+			expected: "invalid maximum number of exponential histogram buckets 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestResourceAttributePromotion(t *testing.T) {
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress: m.Address(),
+		Timeout:        config.Duration(time.Second),
+		Headers:        map[string]string{"test": "header1"},
+		ResourceAttributes: []*resourceRule{
+			{Tag: "host", Attribute: "host.name"},
+			{Tag: "region*"},
+		},
+		metricsConverter:     metricsConverter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"host": "a", "region": "eu", "core": "0"},
+			map[string]interface{}{"gauge": 87.3},
+			time.Unix(0, 1622848686000000000),
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"host": "b", "region": "eu", "core": "0"},
+			map[string]interface{}{"gauge": 42.1},
+			time.Unix(0, 1622848686000000000),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	got := m.GotMetrics()
+	require.Equal(t, 2, got.ResourceMetrics().Len())
+	for i, host := range []string{"a", "b"} {
+		rm := got.ResourceMetrics().At(i)
+		require.Equal(t, map[string]interface{}{"host.name": host, "region": "eu"}, rm.Resource().Attributes().AsRaw())
+		dp := rm.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+		require.Equal(t, map[string]interface{}{"core": "0"}, dp.Attributes().AsRaw())
+	}
+}
+
+func TestDeltaTemporality(t *testing.T) {
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress:       m.Address(),
+		Timeout:              config.Duration(time.Second),
+		Headers:              map[string]string{"test": "header1"},
+		Temporality:          "delta",
+		metricsConverter:     metricsConverter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i, v := range []float64{100, 150, 20} {
+		input = append(input, testutil.MustMetric(
+			"requests",
+			map[string]string{"path": "/"},
+			map[string]interface{}{"counter": v},
+			time.Unix(1622848686+int64(i), 0),
+			telegraf.Counter,
+		))
+	}
+
+	// The first value is only used as reference
+	require.NoError(t, plugin.Write(input[:1]))
+	require.Equal(t, 0, m.GotMetrics().ResourceMetrics().Len())
+
+	require.NoError(t, plugin.Write(input[1:2]))
+	sum := m.GotMetrics().ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum()
+	require.Equal(t, pmetric.AggregationTemporalityDelta, sum.AggregationTemporality())
+	require.Equal(t, 1, sum.DataPoints().Len())
+	require.InDelta(t, 50.0, sum.DataPoints().At(0).DoubleValue(), 1e-9)
+	require.Equal(t, pcommon.Timestamp(1622848686000000000), sum.DataPoints().At(0).StartTimestamp())
+
+	// Counter reset
+	require.NoError(t, plugin.Write(input[2:]))
+	sum = m.GotMetrics().ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum()
+	require.InDelta(t, 20.0, sum.DataPoints().At(0).DoubleValue(), 1e-9)
+	require.Equal(t, pcommon.Timestamp(1622848687000000000), sum.DataPoints().At(0).StartTimestamp())
+}
+
+func TestDeltaHistogram(t *testing.T) {
+	newMetrics := func(ts int64, count uint64, sum float64, buckets []uint64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		m.SetName("latency")
+		m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := m.Histogram().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(ts))
+		dp.SetCount(count)
+		dp.SetSum(sum)
+		dp.ExplicitBounds().FromRaw([]float64{0.1, 1})
+		dp.BucketCounts().FromRaw(buckets)
+		return md
+	}
+
+	now := time.Now()
+	c := newDeltaConverter(0)
+
+	md := newMetrics(1, 10, 5, []uint64{5, 3, 2})
+	c.commit(c.convert(md, now), now)
+	require.Equal(t, 0, md.ResourceMetrics().Len())
+
+	// Not committed, e.g. due to a failed write
+	md = newMetrics(2, 15, 6, []uint64{8, 4, 3})
+	c.convert(md, now)
+
+	md = newMetrics(2, 15, 6, []uint64{8, 4, 3})
+	c.commit(c.convert(md, now), now)
+	h := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram()
+	require.Equal(t, pmetric.AggregationTemporalityDelta, h.AggregationTemporality())
+	dp := h.DataPoints().At(0)
+	require.Equal(t, uint64(5), dp.Count())
+	require.InDelta(t, 1.0, dp.Sum(), 1e-9)
+	require.Equal(t, []uint64{3, 1, 1}, dp.BucketCounts().AsRaw())
+	require.Equal(t, pcommon.Timestamp(1), dp.StartTimestamp())
+
+	// Duplicates are dropped
+	md = newMetrics(2, 15, 6, []uint64{8, 4, 3})
+	c.commit(c.convert(md, now), now)
+	require.Equal(t, 0, md.ResourceMetrics().Len())
+}
+
+func TestExponentialHistogram(t *testing.T) {
+	tests := []struct {
+		name       string
+		bounds     []float64
+		counts     []uint64
+		maxBuckets int
+		scale      int32
+		offset     int32
+		positive   []uint64
+		negative   []uint64
+		zero       uint64
+	}{
+		{
+			name:       "minimal scale",
+			bounds:     []float64{1, 2, 4},
+			counts:     []uint64{1, 2, 3, 4},
+			maxBuckets: 4,
+			scale:      0,
+			offset:     -1,
+			positive:   []uint64{1, 2, 3, 4},
+		},
+		{
+			name:       "negative and zero",
+			bounds:     []float64{-1, 0, 1},
+			counts:     []uint64{1, 2, 3, 4},
+			maxBuckets: 2,
+			scale:      20,
+			offset:     -1,
+			positive:   []uint64{3, 4},
+			negative:   []uint64{1},
+			zero:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := pmetric.NewHistogramDataPoint()
+			src.ExplicitBounds().FromRaw(tt.bounds)
+			src.BucketCounts().FromRaw(tt.counts)
+			src.SetCount(10)
+			src.SetSum(12.5)
+			src.Attributes().PutStr("foo", "bar")
+
+			dst := pmetric.NewExponentialHistogramDataPoint()
+			toExponentialDataPoint(src, dst, tt.maxBuckets)
+			require.Equal(t, tt.scale, dst.Scale())
+			require.Equal(t, uint64(10), dst.Count())
+			require.InDelta(t, 12.5, dst.Sum(), 1e-9)
+			require.Equal(t, tt.zero, dst.ZeroCount())
+			require.Equal(t, tt.offset, dst.Positive().Offset())
+			require.Equal(t, tt.positive, dst.Positive().BucketCounts().AsRaw())
+			if tt.negative != nil {
+				require.Equal(t, tt.negative, dst.Negative().BucketCounts().AsRaw())
+			}
+			require.Equal(t, map[string]interface{}{"foo": "bar"}, dst.Attributes().AsRaw())
+		})
+	}
+
+	// Check the scale is reduced to fit the bucket limit
+	src := pmetric.NewHistogramDataPoint()
+	src.ExplicitBounds().FromRaw([]float64{1, 2, 4})
+	src.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+	dst := pmetric.NewExponentialHistogramDataPoint()
+	toExponentialDataPoint(src, dst, 160)
+	require.Equal(t, int32(6), dst.Scale())
+	require.Equal(t, 130, dst.Positive().BucketCounts().Len())
+}
@@ -0,0 +1,79 @@
+package opentelemetry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf/filter"
+)
+
+// resourceRule promotes the tags matching the pattern to resource attributes
+// optionally renaming the tag
+type resourceRule struct {
+	Tag       string `toml:"tag"`
+	Attribute string `toml:"attribute"`
+
+	filter filter.Filter
+}
+
+func (r *resourceRule) init() error {
+	if r.Tag == "" {
+		return errors.New("tag required")
+	}
+	if r.Attribute != "" && strings.ContainsAny(r.Tag, "*?[") {
+		return fmt.Errorf("cannot rename tags matching pattern %q", r.Tag)
+	}
+
+	f, err := filter.Compile([]string{r.Tag})
+	if err != nil {
+		return fmt.Errorf("compiling tag pattern failed: %w", err)
+	}
+	r.filter = f
+
+	return nil
+}
+
+// promote removes the tags matching any of the rules and returns them as
+// resource attributes; the first matching rule wins
+func promote(rules []*resourceRule, tags map[string]string) map[string]string {
+	var attributes map[string]string
+	for key, value := range tags {
+		for _, rule := range rules {
+			if !rule.filter.Match(key) {
+				continue
+			}
+			if attributes == nil {
+				attributes = make(map[string]string)
+			}
+			name := key
+			if rule.Attribute != "" {
+				name = rule.Attribute
+			}
+			attributes[name] = value
+			delete(tags, key)
+			break
+		}
+	}
+	return attributes
+}
+
+// attributesKey returns a unique key for the given attributes independent of
+// the order
+func attributesKey(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(attributes[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package opentelemetry
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// deltaConverter converts cumulative sums and histograms to delta temporality
+// by keeping the previous data point of each series
+type deltaConverter struct {
+	expiry time.Duration
+	state  map[string]*deltaState
+}
+
+type deltaState struct {
+	timestamp pcommon.Timestamp
+	seen      time.Time
+
+	// Sums
+	intValue   int64
+	floatValue float64
+
+	// Histograms
+	count   uint64
+	sum     float64
+	bounds  []float64
+	buckets []uint64
+}
+
+func newDeltaConverter(expiry time.Duration) *deltaConverter {
+	return &deltaConverter{
+		expiry: expiry,
+		state:  make(map[string]*deltaState),
+	}
+}
+
+// convert modifies the cumulative data points to deltas in place and removes
+// the data points without a previous one. The returned state must be
+// committed after successfully sending the metrics to not lose deltas on
+// retries.
+func (c *deltaConverter) convert(md pmetric.Metrics, now time.Time) map[string]*deltaState {
+	pending := make(map[string]*deltaState)
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		resourceKey := attributesKey(toStringMap(rm.Resource().Attributes()))
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			prefix := resourceKey + "\n" + sm.Scope().Name() + "\x00" + sm.Scope().Version() + "\n"
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				switch m.Type() {
+				case pmetric.MetricTypeSum:
+					if m.Sum().AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
+						return false
+					}
+					m.Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+						key := prefix + m.Name() + "\n" + attributesKey(toStringMap(dp.Attributes()))
+						return !c.sumDelta(key, dp, pending, now)
+					})
+					m.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+					return m.Sum().DataPoints().Len() == 0
+				case pmetric.MetricTypeHistogram:
+					if m.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
+						return false
+					}
+					m.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+						key := prefix + m.Name() + "\n" + attributesKey(toStringMap(dp.Attributes()))
+						return !c.histogramDelta(key, dp, pending, now)
+					})
+					m.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+					return m.Histogram().DataPoints().Len() == 0
+				}
+				return false
+			})
+		}
+	}
+
+	// Remove empty resources and scopes
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+
+	return pending
+}
+
+// commit stores the state of a successfully sent conversion and removes
+// series not seen within the expiry interval
+func (c *deltaConverter) commit(pending map[string]*deltaState, now time.Time) {
+	maps.Copy(c.state, pending)
+
+	if c.expiry > 0 {
+		threshold := now.Add(-c.expiry)
+		maps.DeleteFunc(c.state, func(_ string, s *deltaState) bool {
+			return s.seen.Before(threshold)
+		})
+	}
+}
+
+// sumDelta converts the data point to a delta and returns false if no delta
+// can be computed. A decreasing value is treated as counter reset.
+func (c *deltaConverter) sumDelta(key string, dp pmetric.NumberDataPoint, pending map[string]*deltaState, now time.Time) bool {
+	previous, found := c.state[key]
+	if found && dp.Timestamp() <= previous.timestamp {
+		// Duplicate or out-of-order data point
+		return false
+	}
+	current := &deltaState{
+		timestamp:  dp.Timestamp(),
+		seen:       now,
+		intValue:   dp.IntValue(),
+		floatValue: dp.DoubleValue(),
+	}
+	pending[key] = current
+	if !found {
+		return false
+	}
+
+	dp.SetStartTimestamp(previous.timestamp)
+	switch dp.ValueType() {
+	case pmetric.NumberDataPointValueTypeInt:
+		if current.intValue >= previous.intValue {
+			dp.SetIntValue(current.intValue - previous.intValue)
+		}
+	case pmetric.NumberDataPointValueTypeDouble:
+		if current.floatValue >= previous.floatValue {
+			dp.SetDoubleValue(current.floatValue - previous.floatValue)
+		}
+	}
+	return true
+}
+
+// histogramDelta converts the data point to a delta and returns false if no
+// delta can be computed. A decreasing count or changed bucket boundaries are
+// treated as reset of the histogram.
+func (c *deltaConverter) histogramDelta(key string, dp pmetric.HistogramDataPoint, pending map[string]*deltaState, now time.Time) bool {
+	previous, found := c.state[key]
+	if found && dp.Timestamp() <= previous.timestamp {
+		// Duplicate or out-of-order data point
+		return false
+	}
+	current := &deltaState{
+		timestamp: dp.Timestamp(),
+		seen:      now,
+		count:     dp.Count(),
+		sum:       dp.Sum(),
+		bounds:    dp.ExplicitBounds().AsRaw(),
+		buckets:   dp.BucketCounts().AsRaw(),
+	}
+	pending[key] = current
+	if !found {
+		return false
+	}
+
+	dp.SetStartTimestamp(previous.timestamp)
+	reset := current.count < previous.count ||
+		!slices.Equal(current.bounds, previous.bounds) ||
+		len(current.buckets) != len(previous.buckets)
+	if reset {
+		return true
+	}
+
+	dp.SetCount(current.count - previous.count)
+	dp.SetSum(current.sum - previous.sum)
+	buckets := make([]uint64, len(current.buckets))
+	for i, v := range current.buckets {
+		if v < previous.buckets[i] {
+			// Inconsistent buckets, keep the cumulative values
+			return true
+		}
+		buckets[i] = v - previous.buckets[i]
+	}
+	dp.BucketCounts().FromRaw(buckets)
+
+	// Minimum and maximum are not defined for the delta interval
+	dp.RemoveMin()
+	dp.RemoveMax()
+
+	return true
+}
+
+func toStringMap(attributes pcommon.Map) map[string]string {
+	m := make(map[string]string, attributes.Len())
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		m[k] = v.AsString()
+		return true
+	})
+	return m
+}
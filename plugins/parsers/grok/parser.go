@@ -3,9 +3,12 @@ package grok
 import (
 	"bufio"
 	"bytes"
+	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,8 +20,15 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
+// Logstash pattern library bundled with the grok package, adapted where
+// patterns are not supported by Go regular expressions
+//
+//go:embed patterns/*
+var patternLibrary embed.FS
+
 var timeLayouts = map[string]string{
 	"ts-ansic":       "Mon Jan _2 15:04:05 2006",
 	"ts-unix":        "Mon Jan _2 15:04:05 MST 2006",
@@ -65,6 +75,8 @@ var (
 	modifierRe = regexp.MustCompile(`%{\w+:(\w+):(ts-".+"|t?s?-?\w+)}`)
 	// matches a plain pattern name. ie, %{NUMBER}
 	patternOnlyRe = regexp.MustCompile(`%{(\w+)}`)
+	// matches a pattern consisting of a single pattern name, ie %{SYSLOGLINE}
+	singlePatternRe = regexp.MustCompile(`^%{(\w+)}$`)
 )
 
 // Parser is the primary struct to handle and grok-patterns defined in the config toml
@@ -77,7 +89,11 @@ type Parser struct {
 	NamedPatterns      []string          `toml:"grok_named_patterns"`
 	CustomPatterns     string            `toml:"grok_custom_patterns"`
 	CustomPatternFiles []string          `toml:"grok_custom_pattern_files"`
+	CustomPatternDirs  []string          `toml:"grok_custom_pattern_dirs"`
 	Multiline          bool              `toml:"grok_multiline"`
+	PatternTag         string            `toml:"grok_pattern_tag"`
+	Unmatched          string            `toml:"grok_unmatched"`
+	CollectStats       bool              `toml:"grok_collect_stats"`
 	Measurement        string            `toml:"-"`
 	DefaultTags        map[string]string `toml:"-"`
 	Log                telegraf.Logger   `toml:"-"`
@@ -126,11 +142,23 @@ type Parser struct {
 	// layouts.
 	foundTSLayouts []string
 
+	// patternNames are the names of the patterns in NamedPatterns reported
+	// in the pattern tag and statistics
+	patternNames []string
+	patternStats []patternStats
+	unmatched    selfstat.Stat
+
 	timeFunc func() time.Time
 	g        *grok.Grok
 	tsModder *tsModder
 }
 
+type patternStats struct {
+	tried     selfstat.Stat
+	matched   selfstat.Stat
+	matchTime selfstat.Stat
+}
+
 // Compile is a bound method to Parser which will process the options for our parser
 func (p *Parser) Compile() error {
 	p.typeMap = make(map[string]map[string]string)
@@ -147,9 +175,18 @@ func (p *Parser) Compile() error {
 		p.UniqueTimestamp = "auto"
 	}
 
+	switch p.Unmatched {
+	case "":
+		p.Unmatched = "drop"
+	case "drop", "keep":
+	default:
+		return fmt.Errorf("invalid value %q for unmatched lines", p.Unmatched)
+	}
+
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
 	p.NamedPatterns = make([]string, 0, len(p.Patterns))
+	p.patternNames = make([]string, 0, len(p.Patterns))
 	for i, pattern := range p.Patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
@@ -158,12 +195,44 @@ func (p *Parser) Compile() error {
 		name := fmt.Sprintf("GROK_INTERNAL_PATTERN_%d", i)
 		p.CustomPatterns += "\n" + name + " " + pattern + "\n"
 		p.NamedPatterns = append(p.NamedPatterns, "%{"+name+"}")
+
+		// Use the name of patterns referencing a single named pattern and the
+		// index otherwise
+		displayName := fmt.Sprintf("pattern_%d", i)
+		if match := singlePatternRe.FindStringSubmatch(pattern); match != nil {
+			displayName = match[1]
+		}
+		p.patternNames = append(p.patternNames, displayName)
 	}
 
 	if len(p.NamedPatterns) == 0 {
 		return errors.New("pattern required")
 	}
 
+	if p.CollectStats {
+		// Tag the statistics with the measurement to separate the parsers of
+		// different plugins
+		p.patternStats = make([]patternStats, 0, len(p.patternNames))
+		for _, name := range p.patternNames {
+			tags := map[string]string{
+				"measurement": p.Measurement,
+				"pattern":     name,
+			}
+			p.patternStats = append(p.patternStats, patternStats{
+				tried:     selfstat.Register("grok", "lines_tried", tags),
+				matched:   selfstat.Register("grok", "lines_matched", tags),
+				matchTime: selfstat.RegisterTiming("grok", "match_time_ns", tags),
+			})
+		}
+		p.unmatched = selfstat.Register("grok", "lines_unmatched", map[string]string{"measurement": p.Measurement})
+	}
+
+	// Load the bundled pattern library first so all other patterns take
+	// precedence over the library patterns
+	if err := p.addLibraryPatterns(); err != nil {
+		return fmt.Errorf("loading pattern library failed: %w", err)
+	}
+
 	// Combine user-supplied CustomPatterns with DEFAULT_PATTERNS and parse
 	// them together as the same type of pattern.
 	p.CustomPatterns = DefaultPatterns + p.CustomPatterns
@@ -172,15 +241,27 @@ func (p *Parser) Compile() error {
 		p.addCustomPatterns(scanner)
 	}
 
+	// Parse all files in the custom pattern directories
+	for _, dir := range p.CustomPatternDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if err := p.addCustomPatternFile(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Parse any custom pattern files supplied.
 	for _, filename := range p.CustomPatternFiles {
-		file, fileErr := os.Open(filename)
-		if fileErr != nil {
-			return fileErr
+		if err := p.addCustomPatternFile(filename); err != nil {
+			return err
 		}
-
-		scanner := bufio.NewScanner(bufio.NewReader(file))
-		p.addCustomPatterns(scanner)
 	}
 
 	p.loc, err = time.LoadLocation(p.Timezone)
@@ -203,18 +284,38 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	var values map[string]string
 	// the matching pattern string
 	var patternName string
-	for _, pattern := range p.NamedPatterns {
-		if values, err = p.g.Parse(pattern, line); err != nil {
+	var patternIndex int
+	for i, pattern := range p.NamedPatterns {
+		var start time.Time
+		if p.CollectStats {
+			start = time.Now()
+		}
+		values, err = p.g.Parse(pattern, line)
+		if p.CollectStats {
+			p.patternStats[i].tried.Incr(1)
+			p.patternStats[i].matchTime.Set(time.Since(start).Nanoseconds())
+			if len(values) != 0 {
+				p.patternStats[i].matched.Incr(1)
+			}
+		}
+		if err != nil {
 			return nil, err
 		}
 		if len(values) != 0 {
 			patternName = pattern
+			patternIndex = i
 			break
 		}
 	}
 
 	if len(values) == 0 {
 		p.Log.Debugf("Grok no match found for or no data extracted from: %q", line)
+		if p.CollectStats {
+			p.unmatched.Incr(1)
+		}
+		if p.Unmatched == "keep" {
+			return p.unmatchedMetric(line), nil
+		}
 		return nil, nil
 	}
 
@@ -225,6 +326,9 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	for k, v := range p.DefaultTags {
 		tags[k] = v
 	}
+	if p.PatternTag != "" {
+		tags[p.PatternTag] = p.patternNames[patternIndex]
+	}
 
 	timestamp := time.Now()
 	for k, v := range values {
@@ -381,6 +485,24 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return metric.New(p.Measurement, tags, fields, p.tsModder.tsMod(timestamp)), nil
 }
 
+// unmatchedMetric returns the line not matching any pattern as metric
+func (p *Parser) unmatchedMetric(line string) telegraf.Metric {
+	tags := make(map[string]string, len(p.DefaultTags)+1)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	if p.PatternTag != "" {
+		tags[p.PatternTag] = "unmatched"
+	}
+	fields := map[string]interface{}{"message": line}
+
+	timestamp := p.timeFunc()
+	if p.UniqueTimestamp == "auto" {
+		timestamp = p.tsModder.tsMod(timestamp)
+	}
+	return metric.New(p.Measurement, tags, fields, timestamp)
+}
+
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	metrics := make([]telegraf.Metric, 0)
 
@@ -416,6 +538,34 @@ func (p *Parser) SetDefaultTags(tags map[string]string) {
 	p.DefaultTags = tags
 }
 
+// addLibraryPatterns adds the patterns of all files of the bundled library
+func (p *Parser) addLibraryPatterns() error {
+	return fs.WalkDir(patternLibrary, "patterns", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		file, err := patternLibrary.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		p.addCustomPatterns(bufio.NewScanner(file))
+		return nil
+	})
+}
+
+func (p *Parser) addCustomPatternFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p.addCustomPatterns(bufio.NewScanner(bufio.NewReader(file)))
+	return nil
+}
+
 func (p *Parser) addCustomPatterns(scanner *bufio.Scanner) {
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
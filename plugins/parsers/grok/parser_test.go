@@ -1,8 +1,10 @@
 package grok
 
 import (
+	"bufio"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -1241,3 +1244,171 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestPatternLibrary(t *testing.T) {
+	// Make sure all patterns of the bundled library compile
+	p := &Parser{
+		Patterns: []string{"%{GREEDYDATA}"},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+
+	entries, err := patternLibrary.ReadDir("patterns")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		file, err := patternLibrary.Open("patterns/" + entry.Name())
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			name := strings.SplitN(line, " ", 2)[0]
+			_, err := p.g.Match("%{"+name+"}", "")
+			require.NoErrorf(t, err, "pattern %q of file %q", name, entry.Name())
+		}
+		require.NoError(t, file.Close())
+	}
+}
+
+func TestPatternLibraryParse(t *testing.T) {
+	p := &Parser{
+		Measurement: "haproxy",
+		Patterns:    []string{"%{HAPROXYHTTPBASE}"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine(`10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 10/0/30/69/109 200 2750 - - ---- 1/1/1/1/0 0/0 "GET /index.html HTTP/1.1"`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+
+	require.Equal(t, "2750", m.Fields()["bytes_read"])
+	require.Equal(t, "200", m.Fields()["http_status_code"])
+	require.Equal(t, "srv1", m.Fields()["server_name"])
+	require.Equal(t, "/index.html", m.Fields()["http_request"])
+}
+
+func TestCustomPatternDirs(t *testing.T) {
+	p := &Parser{
+		Measurement:       "app",
+		Patterns:          []string{"%{APP_LOG}"},
+		CustomPatternDirs: []string{"./testdata/patterns_dir"},
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+	require.Equal(t, "%{DATA:request_host}", p.patternsMap["HAPROXYCAPTUREDREQUESTHEADERS"])
+
+	m, err := p.ParseLine("info 0.25")
+	require.NoError(t, err)
+	expected := metric.New("app", map[string]string{"level": "info"}, map[string]interface{}{"duration": 0.25}, time.Unix(0, 0))
+	testutil.RequireMetricEqual(t, expected, m, testutil.IgnoreTime())
+
+	p = &Parser{
+		Patterns:          []string{"%{APP_LOG}"},
+		CustomPatternDirs: []string{"./testdata/non_existing"},
+		Log:               testutil.Logger{},
+	}
+	require.Error(t, p.Compile())
+}
+
+func TestPatternTagAndUnmatched(t *testing.T) {
+	p := &Parser{
+		Measurement: "logs",
+		Patterns: []string{
+			"%{COMMON_LOG_FORMAT}",
+			"%{WORD:level:tag}: %{GREEDYDATA:message}",
+		},
+		PatternTag: "pattern",
+		Unmatched:  "keep",
+		Log:        testutil.Logger{},
+		timeFunc:   func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine(`127.0.0.1 user-identifier frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`)
+	require.NoError(t, err)
+	require.Equal(t, "COMMON_LOG_FORMAT", m.Tags()["pattern"])
+
+	m, err = p.ParseLine("error: disk full")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"pattern": "pattern_1", "level": "error"}, m.Tags())
+
+	m, err = p.ParseLine("--- something else ---")
+	require.NoError(t, err)
+	expected := metric.New(
+		"logs",
+		map[string]string{"pattern": "unmatched"},
+		map[string]interface{}{"message": "--- something else ---"},
+		time.Unix(1700000000, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+
+	p = &Parser{
+		Patterns:  []string{"%{GREEDYDATA}"},
+		Unmatched: "log",
+		Log:       testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Compile(), `invalid value "log" for unmatched lines`)
+}
+
+func TestCollectStats(t *testing.T) {
+	p := &Parser{
+		Measurement:    "logs",
+		Patterns:       []string{"%{NUMBER:value:int}", "%{STATS_TEST_WORD}"},
+		CustomPatterns: "STATS_TEST_WORD %{WORD:word}",
+		CollectStats:   true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+	defer unregisterStats("logs", "pattern_0", "STATS_TEST_WORD")
+
+	_, err := p.Parse([]byte("42\nhello\n---\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), p.patternStats[0].tried.Get())
+	require.Equal(t, int64(1), p.patternStats[0].matched.Get())
+	require.Equal(t, int64(2), p.patternStats[1].tried.Get())
+	require.Equal(t, int64(1), p.patternStats[1].matched.Get())
+	require.Equal(t, int64(1), p.unmatched.Get())
+}
+
+func TestCollectStatsPerMeasurement(t *testing.T) {
+	parsers := make([]*Parser, 0, 2)
+	for _, name := range []string{"access", "error"} {
+		p := &Parser{
+			Measurement:  name,
+			Patterns:     []string{"%{NUMBER:value:int}"},
+			CollectStats: true,
+			Log:          testutil.Logger{},
+		}
+		require.NoError(t, p.Compile())
+		defer unregisterStats(name, "pattern_0")
+		parsers = append(parsers, p)
+	}
+
+	// Parsers of different plugins must not share their statistics
+	_, err := parsers[0].Parse([]byte("42\n---\n"))
+	require.NoError(t, err)
+	_, err = parsers[1].Parse([]byte("---\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), parsers[0].patternStats[0].tried.Get())
+	require.Equal(t, int64(1), parsers[0].patternStats[0].matched.Get())
+	require.Equal(t, int64(1), parsers[0].unmatched.Get())
+	require.Equal(t, int64(1), parsers[1].patternStats[0].tried.Get())
+	require.Equal(t, int64(0), parsers[1].patternStats[0].matched.Get())
+	require.Equal(t, int64(1), parsers[1].unmatched.Get())
+}
+
+func unregisterStats(measurement string, patterns ...string) {
+	for _, name := range patterns {
+		tags := map[string]string{"measurement": measurement, "pattern": name}
+		selfstat.Unregister("grok", "lines_tried", tags)
+		selfstat.Unregister("grok", "lines_matched", tags)
+		selfstat.Unregister("grok", "match_time_ns", tags)
+	}
+	selfstat.Unregister("grok", "lines_unmatched", map[string]string{"measurement": measurement})
+}
@@ -5,14 +5,11 @@ import (
 	"math"
 	"time"
 
-	"github.com/prometheus/common/model"
-	"github.com/prometheus/prometheus/prompb"
-
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
-func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, error) {
+func (p *Parser) extractMetricsV1(ts *timeSeries) ([]telegraf.Metric, error) {
 	t := time.Now()
 
 	// Convert each prometheus metrics to the corresponding telegraf metrics.
@@ -25,23 +22,14 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 	// write requests, so we won't try to aggregate them here.
 	// However, for Native Histogram, you will get one telegraf metric with
 	// multiple fields.
-	metrics := make([]telegraf.Metric, 0, len(ts.Samples)+len(ts.Histograms))
+	metrics := make([]telegraf.Metric, 0, len(ts.samples)+len(ts.histograms)+len(ts.exemplars))
 
-	tags := make(map[string]string, len(p.DefaultTags)+len(ts.Labels))
-	for key, value := range p.DefaultTags {
-		tags[key] = value
-	}
-	for _, l := range ts.Labels {
-		tags[l.Name] = l.Value
+	metricName, tags, err := p.tags(ts)
+	if err != nil {
+		return nil, err
 	}
 
-	metricName := tags[model.MetricNameLabel]
-	if metricName == "" {
-		return nil, fmt.Errorf("metric name %q not found in tag-set or empty", model.MetricNameLabel)
-	}
-	delete(tags, model.MetricNameLabel)
-
-	for _, s := range ts.Samples {
+	for _, s := range ts.samples {
 		if math.IsNaN(s.Value) {
 			continue
 		}
@@ -51,15 +39,15 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		if s.Timestamp > 0 {
 			t = time.Unix(0, s.Timestamp*1000000)
 		}
-		m := metric.New(metricName, tags, fields, t)
+		m := metric.New(metricName, tags, fields, t, ts.valueType)
 		metrics = append(metrics, m)
 	}
 
-	for _, hp := range ts.Histograms {
-		h := hp.ToFloatHistogram()
+	for _, hs := range ts.histograms {
+		h := hs.histogram
 
-		if hp.Timestamp > 0 {
-			t = time.Unix(0, hp.Timestamp*1000000)
+		if hs.timestamp > 0 {
+			t = time.Unix(0, hs.timestamp*1000000)
 		}
 
 		fields := map[string]any{
@@ -102,5 +90,20 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		metrics = append(metrics, m)
 	}
 
+	// Exemplars are reported as separate metrics with the exemplar value and
+	// labels as fields
+	for _, e := range ts.exemplars {
+		fields := make(map[string]interface{}, len(e.labels)+1)
+		fields["exemplar_value"] = e.value
+		for _, l := range e.labels {
+			fields["exemplar_"+l.Name] = l.Value
+		}
+		if e.timestamp > 0 {
+			t = time.Unix(0, e.timestamp*1000000)
+		}
+		m := metric.New(metricName, tags, fields, t, ts.valueType)
+		metrics = append(metrics, m)
+	}
+
 	return metrics, nil
 }
@@ -5,14 +5,11 @@ import (
 	"math"
 	"time"
 
-	"github.com/prometheus/common/model"
-	"github.com/prometheus/prometheus/prompb"
-
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
-func (p *Parser) extractMetricsV2(ts *prompb.TimeSeries) ([]telegraf.Metric, error) {
+func (p *Parser) extractMetricsV2(ts *timeSeries) ([]telegraf.Metric, error) {
 	t := time.Now()
 
 	// Convert each prometheus metric to a corresponding telegraf metric
@@ -20,21 +17,12 @@ func (p *Parser) extractMetricsV2(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 	// the corresponding metrics.
 	metrics := make([]telegraf.Metric, 0)
 
-	tags := make(map[string]string, len(p.DefaultTags)+len(ts.Labels))
-	for key, value := range p.DefaultTags {
-		tags[key] = value
-	}
-	for _, l := range ts.Labels {
-		tags[l.Name] = l.Value
+	metricName, tags, err := p.tags(ts)
+	if err != nil {
+		return nil, err
 	}
 
-	metricName := tags[model.MetricNameLabel]
-	if metricName == "" {
-		return nil, fmt.Errorf("metric name %q not found in tag-set or empty", model.MetricNameLabel)
-	}
-	delete(tags, model.MetricNameLabel)
-
-	for _, s := range ts.Samples {
+	for _, s := range ts.samples {
 		if math.IsNaN(s.Value) {
 			continue
 		}
@@ -43,15 +31,15 @@ func (p *Parser) extractMetricsV2(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		if s.Timestamp > 0 {
 			t = time.Unix(0, s.Timestamp*1000000)
 		}
-		m := metric.New("prometheus_remote_write", tags, fields, t)
+		m := metric.New("prometheus_remote_write", tags, fields, t, ts.valueType)
 		metrics = append(metrics, m)
 	}
 
-	for _, hp := range ts.Histograms {
-		h := hp.ToFloatHistogram()
+	for _, hs := range ts.histograms {
+		h := hs.histogram
 
-		if hp.Timestamp > 0 {
-			t = time.Unix(0, hp.Timestamp*1000000)
+		if hs.timestamp > 0 {
+			t = time.Unix(0, hs.timestamp*1000000)
 		}
 
 		fields := map[string]any{
@@ -87,5 +75,20 @@ func (p *Parser) extractMetricsV2(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 		}
 	}
 
+	// Exemplars are reported as separate metrics with the exemplar value and
+	// labels as fields prefixed by the metric name
+	for _, e := range ts.exemplars {
+		fields := make(map[string]interface{}, len(e.labels)+1)
+		fields[metricName+"_exemplar_value"] = e.value
+		for _, l := range e.labels {
+			fields[metricName+"_exemplar_"+l.Name] = l.Value
+		}
+		if e.timestamp > 0 {
+			t = time.Unix(0, e.timestamp*1000000)
+		}
+		m := metric.New("prometheus_remote_write", tags, fields, t, ts.valueType)
+		metrics = append(metrics, m)
+	}
+
 	return metrics, nil
 }
@@ -5,32 +5,47 @@ import (
 	"fmt"
 
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
 type Parser struct {
-	MetricVersion int `toml:"prometheus_metric_version"`
+	MetricVersion int  `toml:"prometheus_metric_version"`
+	MetadataTags  bool `toml:"prometheus_metadata_tags"`
 	DefaultTags   map[string]string
 }
 
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
-	var err error
-	var metrics []telegraf.Metric
-	var req prompb.WriteRequest
-
-	if err := req.Unmarshal(buf); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal request body: %w", err)
+	var series []timeSeries
+	if isWriteV2(buf) {
+		var req writev2.Request
+		if err := req.Unmarshal(buf); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal request body: %w", err)
+		}
+		s, err := fromWriteV2(&req)
+		if err != nil {
+			return nil, err
+		}
+		series = s
+	} else {
+		var req prompb.WriteRequest
+		if err := req.Unmarshal(buf); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal request body: %w", err)
+		}
+		series = fromWriteV1(&req)
 	}
 
-	for _, ts := range req.Timeseries {
+	var metrics []telegraf.Metric
+	for i := range series {
 		var metricsFromTS []telegraf.Metric
+		var err error
 		switch p.MetricVersion {
 		case 0, 2:
-			metricsFromTS, err = p.extractMetricsV2(&ts)
+			metricsFromTS, err = p.extractMetricsV2(&series[i])
 		case 1:
-			metricsFromTS, err = p.extractMetricsV1(&ts)
+			metricsFromTS, err = p.extractMetricsV1(&series[i])
 		default:
 			return nil, fmt.Errorf("unknown prometheus metric version %d", p.MetricVersion)
 		}
@@ -40,7 +55,7 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		metrics = append(metrics, metricsFromTS...)
 	}
 
-	return metrics, err
+	return metrics, nil
 }
 
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
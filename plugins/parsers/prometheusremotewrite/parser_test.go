@@ -11,6 +11,7 @@ import (
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -447,3 +448,169 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse(benchmarkData)
 	}
 }
+
+func TestWriteV2(t *testing.T) {
+	st := writev2.NewSymbolTable()
+	ts := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	input := writev2.Request{
+		Timeseries: []writev2.TimeSeries{
+			{
+				LabelsRefs: []uint32{
+					st.Symbolize("__name__"), st.Symbolize("http_requests_total"),
+					st.Symbolize("job"), st.Symbolize("api"),
+				},
+				Samples: []writev2.Sample{{Value: 42, Timestamp: ts}},
+				Exemplars: []writev2.Exemplar{
+					{
+						LabelsRefs: []uint32{st.Symbolize("trace_id"), st.Symbolize("abc123")},
+						Value:      1,
+						Timestamp:  ts + 1000,
+					},
+				},
+				Metadata: writev2.Metadata{
+					Type:    writev2.Metadata_METRIC_TYPE_COUNTER,
+					HelpRef: st.Symbolize("Total number of requests"),
+					UnitRef: st.Symbolize("requests"),
+				},
+			},
+			{
+				LabelsRefs: []uint32{st.Symbolize("__name__"), st.Symbolize("request_duration_seconds")},
+				Histograms: []writev2.Histogram{
+					writev2.FromFloatHistogram(ts, &histogram.FloatHistogram{
+						Schema:          histogram.CustomBucketsSchema,
+						Count:           6,
+						Sum:             12.5,
+						PositiveSpans:   []histogram.Span{{Offset: 0, Length: 3}},
+						PositiveBuckets: []float64{1, 2, 3},
+						CustomValues:    []float64{0.5, 1},
+					}),
+				},
+				Metadata: writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+			},
+		},
+	}
+	input.Symbols = st.Symbols()
+	buf, err := input.Marshal()
+	require.NoError(t, err)
+	require.True(t, isWriteV2(buf))
+
+	stats, err := CountWriteV2(buf)
+	require.NoError(t, err)
+	require.Equal(t, WriteStats{Samples: 1, Histograms: 1, Exemplars: 1}, stats)
+
+	expectedV1 := []telegraf.Metric{
+		metric.New(
+			"http_requests_total",
+			map[string]string{"job": "api", "metric_type": "counter", "metric_unit": "requests", "metric_help": "Total number of requests"},
+			map[string]interface{}{"value": float64(42)},
+			time.UnixMilli(ts),
+			telegraf.Counter,
+		),
+		metric.New(
+			"http_requests_total",
+			map[string]string{"job": "api", "metric_type": "counter", "metric_unit": "requests", "metric_help": "Total number of requests"},
+			map[string]interface{}{"exemplar_value": float64(1), "exemplar_trace_id": "abc123"},
+			time.UnixMilli(ts+1000),
+			telegraf.Counter,
+		),
+		metric.New(
+			"request_duration_seconds",
+			map[string]string{"metric_type": "histogram"},
+			map[string]interface{}{
+				"counter_reset_hint":     uint64(0),
+				"schema":                 int64(histogram.CustomBucketsSchema),
+				"zero_threshold":         float64(0),
+				"zero_count":             float64(0),
+				"count":                  float64(6),
+				"sum":                    float64(12.5),
+				"0.5":                    float64(1),
+				"1":                      float64(3),
+				"+Inf":                   float64(6),
+				"positive_span_0_offset": int64(0),
+				"positive_span_0_length": uint64(3),
+				"positive_bucket_0":      float64(1),
+				"positive_bucket_1":      float64(2),
+				"positive_bucket_2":      float64(3),
+			},
+			time.UnixMilli(ts),
+			telegraf.Histogram,
+		),
+	}
+	parser := &Parser{MetricVersion: 1, MetadataTags: true}
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expectedV1, metrics)
+
+	expectedV2 := []telegraf.Metric{
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{"job": "api"},
+			map[string]interface{}{"http_requests_total": float64(42)},
+			time.UnixMilli(ts),
+			telegraf.Counter,
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{"job": "api"},
+			map[string]interface{}{
+				"http_requests_total_exemplar_value":    float64(1),
+				"http_requests_total_exemplar_trace_id": "abc123",
+			},
+			time.UnixMilli(ts+1000),
+			telegraf.Counter,
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{},
+			map[string]interface{}{"request_duration_seconds_sum": float64(12.5)},
+			time.UnixMilli(ts),
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{},
+			map[string]interface{}{"request_duration_seconds_count": float64(6)},
+			time.UnixMilli(ts),
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{"request_duration_seconds_le": "0.5"},
+			map[string]interface{}{"request_duration_seconds": float64(1)},
+			time.UnixMilli(ts),
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{"request_duration_seconds_le": "1"},
+			map[string]interface{}{"request_duration_seconds": float64(3)},
+			time.UnixMilli(ts),
+		),
+		metric.New(
+			"prometheus_remote_write",
+			map[string]string{"request_duration_seconds_le": "+Inf"},
+			map[string]interface{}{"request_duration_seconds": float64(6)},
+			time.UnixMilli(ts),
+		),
+	}
+	parser = &Parser{MetricVersion: 2}
+	metrics, err = parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expectedV2, metrics)
+}
+
+func TestWriteV2InvalidSymbol(t *testing.T) {
+	input := writev2.Request{
+		Symbols: []string{"", "__name__", "foo"},
+		Timeseries: []writev2.TimeSeries{
+			{
+				LabelsRefs: []uint32{1, 3},
+				Samples:    []writev2.Sample{{Value: 1}},
+			},
+		},
+	}
+	buf, err := input.Marshal()
+	require.NoError(t, err)
+
+	parser := &Parser{}
+	_, err = parser.Parse(buf)
+	require.ErrorContains(t, err, "series 0: symbol reference 3 out of range")
+}
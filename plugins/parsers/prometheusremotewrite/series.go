@@ -0,0 +1,272 @@
+package prometheusremotewrite
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+)
+
+// timeSeries is the representation of a series independent of the version
+// of the remote-write protocol
+type timeSeries struct {
+	labels     []prompb.Label
+	samples    []prompb.Sample
+	histograms []histogramSample
+	exemplars  []exemplarSample
+
+	// Metadata only available for Remote-Write 2.0
+	valueType telegraf.ValueType
+	typeName  string
+	unit      string
+	help      string
+}
+
+type histogramSample struct {
+	timestamp int64
+	histogram *histogram.FloatHistogram
+}
+
+type exemplarSample struct {
+	labels    []prompb.Label
+	value     float64
+	timestamp int64
+}
+
+// WriteStats contains the number of elements in a remote-write request as
+// required for the response headers of Remote-Write 2.0
+type WriteStats struct {
+	Samples    int
+	Histograms int
+	Exemplars  int
+}
+
+// isWriteV2 checks if the given protobuf message is a Remote-Write 2.0
+// request. The field numbers of the 1.0 request (1 and 3) are reserved in the
+// 2.0 request (4 and 5), so the first field identifies the version.
+func isWriteV2(buf []byte) bool {
+	num, _, n := protowire.ConsumeTag(buf)
+	if n < 0 {
+		return false
+	}
+	return num == 4 || num == 5
+}
+
+// CountWriteV2 returns the number of samples, histograms and exemplars
+// contained in the given, uncompressed Remote-Write 2.0 request without
+// decoding the whole message.
+func CountWriteV2(buf []byte) (WriteStats, error) {
+	var stats WriteStats
+	err := walkFields(buf, func(num protowire.Number, value []byte) error {
+		if num != 5 {
+			return nil
+		}
+		return walkFields(value, func(num protowire.Number, _ []byte) error {
+			switch num {
+			case 2:
+				stats.Samples++
+			case 3:
+				stats.Histograms++
+			case 4:
+				stats.Exemplars++
+			}
+			return nil
+		})
+	})
+	return stats, err
+}
+
+// walkFields calls the given function for all fields of the protobuf
+// message, the value is only passed for length-delimited fields
+func walkFields(buf []byte, fn func(protowire.Number, []byte) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(buf)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fromWriteV1(req *prompb.WriteRequest) []timeSeries {
+	series := make([]timeSeries, 0, len(req.Timeseries))
+	for _, ts := range req.Timeseries {
+		s := timeSeries{
+			labels:     ts.Labels,
+			samples:    ts.Samples,
+			histograms: make([]histogramSample, 0, len(ts.Histograms)),
+			exemplars:  make([]exemplarSample, 0, len(ts.Exemplars)),
+			valueType:  telegraf.Untyped,
+		}
+		for _, h := range ts.Histograms {
+			s.histograms = append(s.histograms, histogramSample{timestamp: h.Timestamp, histogram: h.ToFloatHistogram()})
+		}
+		for _, e := range ts.Exemplars {
+			s.exemplars = append(s.exemplars, exemplarSample{labels: e.Labels, value: e.Value, timestamp: e.Timestamp})
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+func fromWriteV2(req *writev2.Request) ([]timeSeries, error) {
+	series := make([]timeSeries, 0, len(req.Timeseries))
+	for i, ts := range req.Timeseries {
+		labels, err := resolveLabels(ts.LabelsRefs, req.Symbols)
+		if err != nil {
+			return nil, fmt.Errorf("series %d: %w", i, err)
+		}
+		s := timeSeries{
+			labels:     labels,
+			samples:    make([]prompb.Sample, 0, len(ts.Samples)),
+			histograms: make([]histogramSample, 0, len(ts.Histograms)),
+			exemplars:  make([]exemplarSample, 0, len(ts.Exemplars)),
+			valueType:  valueType(ts.Metadata.Type),
+			typeName:   typeName(ts.Metadata.Type),
+		}
+		for _, sample := range ts.Samples {
+			s.samples = append(s.samples, prompb.Sample{Value: sample.Value, Timestamp: sample.Timestamp})
+		}
+		for _, h := range ts.Histograms {
+			s.histograms = append(s.histograms, histogramSample{timestamp: h.Timestamp, histogram: h.ToFloatHistogram()})
+		}
+		for _, e := range ts.Exemplars {
+			labels, err := resolveLabels(e.LabelsRefs, req.Symbols)
+			if err != nil {
+				return nil, fmt.Errorf("exemplar of series %d: %w", i, err)
+			}
+			s.exemplars = append(s.exemplars, exemplarSample{labels: labels, value: e.Value, timestamp: e.Timestamp})
+		}
+
+		if s.unit, err = resolveSymbol(ts.Metadata.UnitRef, req.Symbols); err != nil {
+			return nil, fmt.Errorf("unit of series %d: %w", i, err)
+		}
+		if s.help, err = resolveSymbol(ts.Metadata.HelpRef, req.Symbols); err != nil {
+			return nil, fmt.Errorf("help of series %d: %w", i, err)
+		}
+
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// resolveLabels converts the pairs of symbol references to labels
+func resolveLabels(refs []uint32, symbols []string) ([]prompb.Label, error) {
+	if len(refs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of label references %d", len(refs))
+	}
+	labels := make([]prompb.Label, 0, len(refs)/2)
+	for i := 0; i < len(refs); i += 2 {
+		name, err := resolveSymbol(refs[i], symbols)
+		if err != nil {
+			return nil, err
+		}
+		value, err := resolveSymbol(refs[i+1], symbols)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, prompb.Label{Name: name, Value: value})
+	}
+	return labels, nil
+}
+
+func resolveSymbol(ref uint32, symbols []string) (string, error) {
+	if int(ref) >= len(symbols) {
+		// An empty symbol table is valid for requests without any strings
+		if ref == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("symbol reference %d out of range", ref)
+	}
+	return symbols[ref], nil
+}
+
+func valueType(t writev2.Metadata_MetricType) telegraf.ValueType {
+	switch t {
+	case writev2.Metadata_METRIC_TYPE_COUNTER:
+		return telegraf.Counter
+	case writev2.Metadata_METRIC_TYPE_GAUGE:
+		return telegraf.Gauge
+	case writev2.Metadata_METRIC_TYPE_HISTOGRAM, writev2.Metadata_METRIC_TYPE_GAUGEHISTOGRAM:
+		return telegraf.Histogram
+	case writev2.Metadata_METRIC_TYPE_SUMMARY:
+		return telegraf.Summary
+	}
+	return telegraf.Untyped
+}
+
+// typeName returns the name of the metric type as used in the Prometheus
+// exposition format
+func typeName(t writev2.Metadata_MetricType) string {
+	switch t {
+	case writev2.Metadata_METRIC_TYPE_COUNTER:
+		return string(model.MetricTypeCounter)
+	case writev2.Metadata_METRIC_TYPE_GAUGE:
+		return string(model.MetricTypeGauge)
+	case writev2.Metadata_METRIC_TYPE_HISTOGRAM:
+		return string(model.MetricTypeHistogram)
+	case writev2.Metadata_METRIC_TYPE_GAUGEHISTOGRAM:
+		return string(model.MetricTypeGaugeHistogram)
+	case writev2.Metadata_METRIC_TYPE_SUMMARY:
+		return string(model.MetricTypeSummary)
+	case writev2.Metadata_METRIC_TYPE_INFO:
+		return string(model.MetricTypeInfo)
+	case writev2.Metadata_METRIC_TYPE_STATESET:
+		return string(model.MetricTypeStateset)
+	}
+	return ""
+}
+
+// tags returns the metric name and the tags of the series
+func (p *Parser) tags(ts *timeSeries) (string, map[string]string, error) {
+	tags := make(map[string]string, len(p.DefaultTags)+len(ts.labels)+3)
+	for key, value := range p.DefaultTags {
+		tags[key] = value
+	}
+	for _, l := range ts.labels {
+		tags[l.Name] = l.Value
+	}
+
+	metricName := tags[model.MetricNameLabel]
+	if metricName == "" {
+		return "", nil, fmt.Errorf("metric name %q not found in tag-set or empty", model.MetricNameLabel)
+	}
+	delete(tags, model.MetricNameLabel)
+
+	// Add the metadata without overwriting labels of the same name
+	if p.MetadataTags {
+		metadata := map[string]string{
+			"metric_type": ts.typeName,
+			"metric_unit": ts.unit,
+			"metric_help": ts.help,
+		}
+		for key, value := range metadata {
+			if _, found := tags[key]; !found && value != "" {
+				tags[key] = value
+			}
+		}
+	}
+
+	return metricName, tags, nil
+}
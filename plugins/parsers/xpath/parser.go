@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/antchfx/jsonquery"
+	"github.com/antchfx/xmlquery"
 	path "github.com/antchfx/xpath"
 	"github.com/srebhan/cborquery"
 	"github.com/srebhan/protobufquery"
@@ -45,6 +46,9 @@ type Parser struct {
 	PrintDocument        bool              `toml:"xpath_print_document"`
 	AllowEmptySelection  bool              `toml:"xpath_allow_empty_selection"`
 	NativeTypes          bool              `toml:"xpath_native_types"`
+	Namespaces           map[string]string `toml:"xpath_namespaces"`
+	StreamSelection      string            `toml:"xpath_stream_selection"`
+	XSDFiles             []string          `toml:"xpath_xsd_files"`
 	Trace                bool              `toml:"xpath_trace" deprecated:"1.35.0;use 'log_level' 'trace' instead"`
 	Configs              []Config          `toml:"xpath"`
 	DefaultMetricName    string            `toml:"-"`
@@ -58,6 +62,7 @@ type Parser struct {
 	ConfigsProto   []Config `toml:"xpath_protobuf" deprecated:"1.23.1;1.35.0;use 'xpath' instead"`
 
 	document dataDocument
+	xsdTypes *xsdTypes
 }
 
 type Config struct {
@@ -90,7 +95,7 @@ type Config struct {
 func (p *Parser) Init() error {
 	switch p.Format {
 	case "", "xml":
-		p.document = &xmlDocument{}
+		p.document = &xmlDocument{namespaces: p.Namespaces}
 
 		// Required for backward compatibility
 		if len(p.ConfigsXML) > 0 {
@@ -161,10 +166,38 @@ func (p *Parser) Init() error {
 		return errors.New("missing default metric name")
 	}
 
+	// Check the XML specific options
+	isXML := p.Format == "" || p.Format == "xml"
+	if !isXML && (len(p.Namespaces) > 0 || p.StreamSelection != "" || len(p.XSDFiles) > 0) {
+		return fmt.Errorf("namespaces, stream selection and XSD files are not supported for data-format %q", p.Format)
+	}
+	for prefix, uri := range p.Namespaces {
+		if prefix == "" || uri == "" {
+			return fmt.Errorf("invalid namespace mapping %q to %q", prefix, uri)
+		}
+	}
+	if p.StreamSelection != "" {
+		if _, err := path.Compile(p.StreamSelection); err != nil {
+			return fmt.Errorf("invalid stream selection: %w", err)
+		}
+	}
+	if len(p.XSDFiles) > 0 {
+		types, err := loadXSDFiles(p.XSDFiles, p.Log)
+		if err != nil {
+			return err
+		}
+		p.xsdTypes = types
+	}
+
 	// Update the configs with default values
 	for i, cfg := range p.Configs {
 		if cfg.Selection == "" {
+			// In streaming mode the selection is relative to the streamed
+			// element, so select the element itself by default
 			cfg.Selection = "/"
+			if p.StreamSelection != "" {
+				cfg.Selection = "."
+			}
 		}
 		if cfg.TimestampFmt == "" {
 			cfg.TimestampFmt = "unix"
@@ -199,6 +232,10 @@ func (p *Parser) Init() error {
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	t := time.Now()
 
+	if p.StreamSelection != "" {
+		return p.parseStream(t, buf)
+	}
+
 	// Parse the XML
 	doc, err := p.document.Parse(buf)
 	if err != nil {
@@ -208,16 +245,49 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		p.Log.Debugf("XML document equivalent: %q", p.document.OutputXML(doc))
 	}
 
-	// Queries
+	return p.parseNode(t, doc, doc)
+}
+
+// parseStream parses the XML document element-by-element for the elements
+// matching the stream selection to avoid keeping the whole document in memory
+func (p *Parser) parseStream(t time.Time, buf []byte) ([]telegraf.Metric, error) {
+	// If this panics it's a programming error as streaming is only allowed for XML
+	xmldoc := p.document.(*xmlDocument)
+
+	metrics := make([]telegraf.Metric, 0)
+	var count int
+	err := xmldoc.Stream(buf, p.StreamSelection, func(doc, element dataNode) error {
+		count++
+		if p.PrintDocument {
+			p.Log.Debugf("XML element equivalent: %q", p.document.OutputXML(element))
+		}
+		m, err := p.parseNode(t, doc, element)
+		metrics = append(metrics, m...)
+		return err
+	})
+	if err != nil {
+		return metrics, err
+	}
+	if count == 0 && !p.AllowEmptySelection {
+		return metrics, errors.New("cannot parse with empty stream selection")
+	}
+	p.Log.Debugf("Number of streamed elements: %d", count)
+
+	return metrics, nil
+}
+
+// parseNode applies all configs with the metric selection being relative to
+// the given root node
+func (p *Parser) parseNode(t time.Time, doc, root dataNode) ([]telegraf.Metric, error) {
 	metrics := make([]telegraf.Metric, 0)
 	p.Log.Debugf("Number of configs: %d", len(p.Configs))
 	for _, cfg := range p.Configs {
-		selectedNodes, err := p.document.QueryAll(doc, cfg.Selection)
+		selectedNodes, err := p.document.QueryAll(root, cfg.Selection)
 		if err != nil {
 			return nil, err
 		}
 		if (len(selectedNodes) < 1 || selectedNodes[0] == nil) && !p.AllowEmptySelection {
-			p.debugEmptyQuery("metric selection", doc, cfg.Selection)
+			p.debugEmptyQuery("metric selection", root, cfg.Selection)
 			return metrics, errors.New("cannot parse with empty selection node")
 		}
 		p.Log.Debugf("Number of selected metric nodes: %d", len(selectedNodes))
@@ -410,7 +480,7 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, cfg Con
 				}
 				name = p.constructFieldName(selected, selectedfield, name, cfg.FieldNameExpand)
 
-				v, err := p.executeQuery(doc, selectedfield, fieldvaluequery)
+				v, err := p.executeFieldQuery(doc, selectedfield, fieldvaluequery)
 				if err != nil {
 					return nil, fmt.Errorf("failed to query field value for %q: %w", name, err)
 				}
@@ -480,7 +550,7 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, cfg Con
 
 	for name, query := range cfg.Fields {
 		// Execute the query and store the result in fields
-		v, err := p.executeQuery(doc, selected, query)
+		v, err := p.executeFieldQuery(doc, selected, query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query field %q: %w", name, err)
 		}
@@ -510,6 +580,23 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, cfg Con
 }
 
 func (p *Parser) executeQuery(doc, selected dataNode, query string) (r interface{}, err error) {
+	return p.evaluate(doc, selected, query, false)
+}
+
+// executeFieldQuery executes the query and converts the result to the type
+// declared in the XML schema definitions if any
+func (p *Parser) executeFieldQuery(doc, selected dataNode, query string) (r interface{}, err error) {
+	return p.evaluate(doc, selected, query, p.xsdTypes != nil)
+}
+
+func (p *Parser) compile(query string) (*path.Expr, error) {
+	if len(p.Namespaces) > 0 {
+		return path.CompileWithNS(query, p.Namespaces)
+	}
+	return path.Compile(query)
+}
+
+func (p *Parser) evaluate(doc, selected dataNode, query string, typed bool) (r interface{}, err error) {
 	// Check if the query is relative or absolute and set the root for the query
 	root := selected
 	if strings.HasPrefix(query, "/") {
@@ -517,7 +604,7 @@ func (p *Parser) executeQuery(doc, selected dataNode, query string) (r interface
 	}
 
 	// Compile the query
-	expr, err := path.Compile(query)
+	expr, err := p.compile(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile query %q: %w", query, err)
 	}
@@ -547,6 +634,13 @@ func (p *Parser) executeQuery(doc, selected dataNode, query string) (r interface
 			}
 		}
 
+		// Use the type declared in the XML schema definitions if any
+		if nn, ok := current.(*xmlquery.NodeNavigator); ok && typed {
+			if v, ok := p.xsdTypes.convert(nn); ok {
+				return v, nil
+			}
+		}
+
 		return iter.Current().Value(), nil
 	}
 
@@ -1117,6 +1117,61 @@ func TestParseErrors(t *testing.T) {
 	}
 }
 
+func TestInitXMLOptionsFail(t *testing.T) {
+	var tests = []struct {
+		name     string
+		parser   *Parser
+		expected string
+	}{
+		{
+			name:     "namespaces for json",
+			parser:   &Parser{Format: "xpath_json", Namespaces: map[string]string{"a": "http://example.com"}},
+			expected: `namespaces, stream selection and XSD files are not supported for data-format "xpath_json"`,
+		},
+		{
+			name:     "empty namespace",
+			parser:   &Parser{Namespaces: map[string]string{"a": ""}},
+			expected: `invalid namespace mapping "a" to ""`,
+		},
+		{
+			name:     "invalid stream selection",
+			parser:   &Parser{StreamSelection: "/a["},
+			expected: "invalid stream selection",
+		},
+		{
+			name:     "missing XSD file",
+			parser:   &Parser{XSDFiles: []string{"testcases/non_existing.xsd"}},
+			expected: `parsing XSD file "testcases/non_existing.xsd" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.parser.DefaultMetricName = "test"
+			tt.parser.Log = testutil.Logger{Name: "parsers.xpath"}
+			require.ErrorContains(t, tt.parser.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStreamEmptySelection(t *testing.T) {
+	parser := &Parser{
+		DefaultMetricName: "test",
+		StreamSelection:   "/Device_1/NonExisting",
+		Configs:           []Config{{Fields: map[string]string{"value": "."}}},
+		Log:               testutil.Logger{Name: "parsers.xpath"},
+	}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte(metricNameQueryXML))
+	require.EqualError(t, err, "cannot parse with empty stream selection")
+
+	parser.AllowEmptySelection = true
+	metrics, err := parser.Parse([]byte(metricNameQueryXML))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
 func TestEmptySelection(t *testing.T) {
 	var tests = []struct {
 		name    string
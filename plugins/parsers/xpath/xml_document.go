@@ -1,21 +1,54 @@
 package xpath
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
 	path "github.com/antchfx/xpath"
 )
 
-type xmlDocument struct{}
+type xmlDocument struct {
+	namespaces map[string]string
+}
 
 func (*xmlDocument) Parse(buf []byte) (dataNode, error) {
 	return xmlquery.Parse(strings.NewReader(string(buf)))
 }
 
-func (*xmlDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
+// Stream calls the given function for each element matching the selection
+// with the document only containing the element and its ancestors. Previous
+// elements are removed from the document to limit the memory consumption.
+func (*xmlDocument) Stream(buf []byte, selection string, fn func(doc, element dataNode) error) error {
+	parser, err := xmlquery.CreateStreamParser(bytes.NewReader(buf), selection)
+	if err != nil {
+		return err
+	}
+
+	for {
+		element, err := parser.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		doc := element
+		for doc.Parent != nil {
+			doc = doc.Parent
+		}
+		if err := fn(doc, element); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *xmlDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
 	// If this panics it's a programming error as we changed the document type while processing
-	native, err := xmlquery.QueryAll(node.(*xmlquery.Node), expr)
+	native, err := d.queryAll(node.(*xmlquery.Node), expr)
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +60,18 @@ func (*xmlDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
 	return nodes, nil
 }
 
+func (d *xmlDocument) queryAll(node *xmlquery.Node, expr string) ([]*xmlquery.Node, error) {
+	if len(d.namespaces) == 0 {
+		return xmlquery.QueryAll(node, expr)
+	}
+
+	selector, err := path.CompileWithNS(expr, d.namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return xmlquery.QuerySelectorAll(node, selector), nil
+}
+
 func (*xmlDocument) CreateXPathNavigator(node dataNode) path.NodeNavigator {
 	// If this panics it's a programming error as we changed the document type while processing
 	return xmlquery.CreateXPathNavigator(node.(*xmlquery.Node))
@@ -0,0 +1,238 @@
+package xpath
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	path "github.com/antchfx/xpath"
+
+	"github.com/influxdata/telegraf"
+)
+
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// Native types of the XSD built-in types
+var xsdBuiltinTypes = map[string]string{
+	"boolean":            "bool",
+	"byte":               "int",
+	"int":                "int",
+	"integer":            "int",
+	"long":               "int",
+	"negativeInteger":    "int",
+	"nonPositiveInteger": "int",
+	"short":              "int",
+	"nonNegativeInteger": "uint",
+	"positiveInteger":    "uint",
+	"unsignedByte":       "uint",
+	"unsignedInt":        "uint",
+	"unsignedLong":       "uint",
+	"unsignedShort":      "uint",
+	"decimal":            "float",
+	"double":             "float",
+	"float":              "float",
+}
+
+// xsdTypes contains the native types of elements and attributes, by local
+// name, declared with a numeric or boolean type in the schema definitions
+type xsdTypes struct {
+	elements   map[string]string
+	attributes map[string]string
+}
+
+// xsdDeclaration is an element, attribute or named simple type declaration
+type xsdDeclaration struct {
+	kind     string
+	name     string
+	typename string
+}
+
+func loadXSDFiles(files []string, log telegraf.Logger) (*xsdTypes, error) {
+	var declarations []xsdDeclaration
+	simpleTypes := make(map[string]string)
+	for _, fn := range files {
+		decls, err := parseXSDFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("parsing XSD file %q failed: %w", fn, err)
+		}
+		for _, d := range decls {
+			if d.kind == "simpleType" {
+				simpleTypes[d.name] = d.typename
+				continue
+			}
+			declarations = append(declarations, d)
+		}
+	}
+
+	// Collect the native types per name, as the same local name might be
+	// declared multiple times, e.g. in different complex types
+	natives := make(map[xsdDeclaration]map[string]bool)
+	for _, d := range declarations {
+		id := xsdDeclaration{kind: d.kind, name: d.name}
+		if natives[id] == nil {
+			natives[id] = make(map[string]bool)
+		}
+		natives[id][resolveXSDType(d.typename, simpleTypes)] = true
+	}
+
+	types := &xsdTypes{
+		elements:   make(map[string]string),
+		attributes: make(map[string]string),
+	}
+	for id, candidates := range natives {
+		if len(candidates) > 1 {
+			// Names declared with different types cannot be converted reliably
+			log.Warnf("Ignoring type of %s %q declared with different types", id.kind, id.name)
+			continue
+		}
+		for native := range candidates {
+			if native == "" {
+				continue
+			}
+			if id.kind == "attribute" {
+				types.attributes[id.name] = native
+			} else {
+				types.elements[id.name] = native
+			}
+		}
+	}
+	log.Debugf("Using XSD types for %d elements and %d attributes", len(types.elements), len(types.attributes))
+
+	return types, nil
+}
+
+func parseXSDFile(fn string) ([]xsdDeclaration, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var declarations []xsdDeclaration
+	var stack []*xsdDeclaration
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != xsdNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "element", "attribute", "simpleType":
+				d := &xsdDeclaration{kind: t.Name.Local}
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "name":
+						d.name = attr.Value
+					case "type":
+						d.typename = attr.Value
+					}
+				}
+				stack = append(stack, d)
+			case "restriction", "extension":
+				// Derived types inherit the type of their base
+				if len(stack) == 0 || stack[len(stack)-1].typename != "" {
+					continue
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "base" {
+						stack[len(stack)-1].typename = attr.Value
+					}
+				}
+			case "list", "union":
+				// The values of lists and unions cannot be converted
+				if len(stack) > 0 && stack[len(stack)-1].typename == "" {
+					stack[len(stack)-1].typename = "-"
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != xsdNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "element", "attribute", "simpleType":
+				if len(stack) == 0 {
+					continue
+				}
+				d := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				// Anonymous simple types define the type of the enclosing
+				// element or attribute
+				if d.kind == "simpleType" && d.name == "" && len(stack) > 0 && stack[len(stack)-1].typename == "" {
+					stack[len(stack)-1].typename = d.typename
+					continue
+				}
+				// Skip references and anonymous types
+				if d.name != "" && d.typename != "" {
+					declarations = append(declarations, *d)
+				}
+			}
+		}
+	}
+
+	return declarations, nil
+}
+
+// resolveXSDType returns the native type of the given type name following
+// derived simple types or an empty string for non-convertible types
+func resolveXSDType(typename string, simpleTypes map[string]string) string {
+	// Limit the depth to prevent endless loops for circular definitions
+	for range 16 {
+		// Strip the namespace prefix
+		if idx := strings.LastIndex(typename, ":"); idx >= 0 {
+			typename = typename[idx+1:]
+		}
+		base, found := simpleTypes[typename]
+		if !found {
+			return xsdBuiltinTypes[typename]
+		}
+		typename = base
+	}
+	return ""
+}
+
+// convert returns the value of the element or attribute converted to its
+// declared type
+func (x *xsdTypes) convert(nav *xmlquery.NodeNavigator) (interface{}, bool) {
+	var native string
+	switch nav.NodeType() {
+	case path.ElementNode:
+		native = x.elements[nav.LocalName()]
+	case path.AttributeNode:
+		native = x.attributes[nav.LocalName()]
+	}
+
+	value := strings.TrimSpace(nav.Value())
+	switch native {
+	case "bool":
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v, true
+		}
+	case "int":
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v, true
+		}
+	case "uint":
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v, true
+		}
+	case "float":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
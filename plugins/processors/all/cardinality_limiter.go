@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.cardinality_limiter
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/cardinality_limiter" // register plugin
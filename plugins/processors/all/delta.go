@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.delta
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/delta" // register plugin
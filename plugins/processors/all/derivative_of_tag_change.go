@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.derivative_of_tag_change
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/derivative_of_tag_change" // register plugin
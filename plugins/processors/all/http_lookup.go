@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.http_lookup
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/http_lookup" // register plugin
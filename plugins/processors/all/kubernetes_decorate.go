@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.kubernetes_decorate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/kubernetes_decorate" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.kv_lookup
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/kv_lookup" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.lua
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/lua" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.math_window
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/math_window" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.moving_average
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/moving_average" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.outlier
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/outlier" // register plugin
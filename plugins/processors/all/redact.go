@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.redact
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/redact" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.template_rename
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/template_rename" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.timezone
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/timezone" // register plugin
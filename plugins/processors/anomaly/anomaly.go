@@ -0,0 +1,210 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package anomaly
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Anomaly struct {
+	Fields         []string        `toml:"fields"`
+	WindowSize     int             `toml:"window_size"`
+	MinSamples     int             `toml:"min_samples"`
+	Threshold      float64         `toml:"threshold"`
+	ScoreSuffix    string          `toml:"score_suffix"`
+	FlagSuffix     string          `toml:"flag_suffix"`
+	Tag            string          `toml:"tag"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	cache  map[uint64]*entry
+}
+
+type entry struct {
+	windows map[string]*window
+	seen    time.Time
+}
+
+func (*Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Init() error {
+	if len(a.Fields) == 0 {
+		a.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(a.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	a.accept = f
+
+	if a.WindowSize < 2 {
+		return errors.New("window size must be at least two")
+	}
+	if a.MinSamples < 2 || a.MinSamples > a.WindowSize {
+		return fmt.Errorf("minimum samples must be between two and the window size %d", a.WindowSize)
+	}
+	if a.Threshold <= 0 {
+		return errors.New("threshold must be positive")
+	}
+	if a.ScoreSuffix == "" && a.FlagSuffix == "" && a.Tag == "" {
+		return errors.New("at least one of 'score_suffix', 'flag_suffix' or 'tag' must be set")
+	}
+
+	a.cache = make(map[uint64]*entry)
+
+	return nil
+}
+
+func (a *Anomaly) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	for _, m := range in {
+		id := m.HashID()
+		// Create a new entry for unseen metrics
+		stored, ok := a.cache[id]
+		if !ok {
+			stored = &entry{windows: make(map[string]*window)}
+		}
+
+		// Collect the scores to not invalidate the field list while iterating
+		fields := make(map[string]interface{})
+		var anomalous bool
+		for _, field := range m.FieldList() {
+			if !a.accept.Match(field.Key) {
+				continue
+			}
+
+			var v float64
+			switch fv := field.Value.(type) {
+			case float64:
+				v = fv
+			case int64:
+				v = float64(fv)
+			case uint64:
+				v = float64(fv)
+			default:
+				a.Log.Tracef("Skipping non-numerical field %q with value %v (%T)", field.Key, field.Value, field.Value)
+				continue
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			w, found := stored.windows[field.Key]
+			if !found {
+				w = &window{values: make([]float64, 0, a.WindowSize)}
+				stored.windows[field.Key] = w
+			}
+
+			// Score the value against the statistics of the previous values
+			score, valid := a.score(w, v)
+			w.add(v)
+			if !valid {
+				continue
+			}
+
+			flagged := math.Abs(score) > a.Threshold
+			if a.ScoreSuffix != "" {
+				fields[field.Key+a.ScoreSuffix] = score
+			}
+			if a.FlagSuffix != "" {
+				fields[field.Key+a.FlagSuffix] = flagged
+			}
+			anomalous = anomalous || flagged
+		}
+		stored.seen = now
+		a.cache[id] = stored
+
+		for key, value := range fields {
+			m.AddField(key, value)
+		}
+		if anomalous && a.Tag != "" {
+			m.AddTag(a.Tag, "true")
+		}
+	}
+
+	// Cleanup cache entries that are too old
+	if a.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(a.ExpiryInterval))
+		maps.DeleteFunc(a.cache, func(_ uint64, e *entry) bool {
+			return e.seen.Before(threshold)
+		})
+	}
+
+	return in
+}
+
+// score returns the z-score of the value with respect to the values in the
+// window and whether the score could be computed
+func (a *Anomaly) score(w *window, v float64) (float64, bool) {
+	if len(w.values) < a.MinSamples {
+		return 0, false
+	}
+
+	// Without any variance in the window every deviation would be infinitely
+	// large, so we cannot score the value
+	mean, stddev := w.meanStddev()
+	if stddev == 0 {
+		return 0, false
+	}
+	return (v - mean) / stddev, true
+}
+
+// window holds the last values of a field in a ring buffer
+type window struct {
+	values []float64
+	next   int
+}
+
+func (w *window) add(v float64) {
+	if len(w.values) < cap(w.values) {
+		w.values = append(w.values, v)
+		return
+	}
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+}
+
+// meanStddev computes the statistics from the values instead of keeping
+// running sums to avoid accumulating rounding errors
+func (w *window) meanStddev() (mean, stddev float64) {
+	for _, v := range w.values {
+		mean += v
+	}
+	mean /= float64(len(w.values))
+
+	var variance float64
+	for _, v := range w.values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(w.values))
+
+	return mean, math.Sqrt(variance)
+}
+
+func init() {
+	processors.Add("anomaly", func() telegraf.Processor {
+		return &Anomaly{
+			WindowSize:  30,
+			MinSamples:  10,
+			Threshold:   3.0,
+			ScoreSuffix: "_anomaly_score",
+			FlagSuffix:  "_anomaly",
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Anomaly{WindowSize: 1, MinSamples: 1, Threshold: 3}
+	require.ErrorContains(t, plugin.Init(), "window size must be at least two")
+
+	plugin = &Anomaly{WindowSize: 5, MinSamples: 10, Threshold: 3}
+	require.ErrorContains(t, plugin.Init(), "minimum samples must be between two and the window size 5")
+
+	plugin = &Anomaly{WindowSize: 5, MinSamples: 5}
+	require.ErrorContains(t, plugin.Init(), "threshold must be positive")
+
+	plugin = &Anomaly{WindowSize: 5, MinSamples: 5, Threshold: 3}
+	require.ErrorContains(t, plugin.Init(), "at least one of")
+}
+
+func TestScore(t *testing.T) {
+	plugin := &Anomaly{
+		WindowSize:  5,
+		MinSamples:  5,
+		Threshold:   3,
+		ScoreSuffix: "_anomaly_score",
+		FlagSuffix:  "_anomaly",
+		Tag:         "anomaly",
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	values := []int64{10, 11, 9, 10, 10, 12, 11}
+	input := make([]telegraf.Metric, 0, len(values))
+	for i, v := range values {
+		input = append(input, metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{"temperature": v, "state": "ok"},
+			time.Unix(1700000000+int64(i)*10, 0),
+		))
+	}
+
+	expected := make([]telegraf.Metric, 0, len(values))
+	for i, v := range values[:5] {
+		expected = append(expected, metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{"temperature": v, "state": "ok"},
+			time.Unix(1700000000+int64(i)*10, 0),
+		))
+	}
+	expected = append(expected,
+		metric.New(
+			"sensor",
+			map[string]string{"id": "1", "anomaly": "true"},
+			map[string]interface{}{
+				"temperature":               int64(12),
+				"state":                     "ok",
+				"temperature_anomaly_score": 3.1622776601683795,
+				"temperature_anomaly":       true,
+			},
+			time.Unix(1700000050, 0),
+		),
+		metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{
+				"temperature":               int64(11),
+				"state":                     "ok",
+				"temperature_anomaly_score": 0.5883484054145518,
+				"temperature_anomaly":       false,
+			},
+			time.Unix(1700000060, 0),
+		),
+	)
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestConstantValues(t *testing.T) {
+	plugin := &Anomaly{
+		WindowSize:  3,
+		MinSamples:  2,
+		Threshold:   3,
+		ScoreSuffix: "_score",
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Values cannot be scored without any variance in the window
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 5.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 5.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 50.0}, time.Unix(2, 0)),
+	}
+	for _, m := range plugin.Apply(input...) {
+		require.False(t, m.HasField("usage_score"))
+	}
+}
+
+func TestSeries(t *testing.T) {
+	plugin := &Anomaly{
+		WindowSize: 2,
+		MinSamples: 2,
+		Threshold:  1,
+		FlagSuffix: "_anomaly",
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Each series is scored separately
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 100.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 2.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 102.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 1.5}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 110.0}, time.Unix(2, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 100.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 2.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 102.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 1.5, "usage_anomaly": false}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"usage": 110.0, "usage_anomaly": true}, time.Unix(2, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &Anomaly{
+		WindowSize:     5,
+		MinSamples:     2,
+		Threshold:      3,
+		ScoreSuffix:    "_score",
+		ExpiryInterval: config.Duration(10 * time.Millisecond),
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, time.Unix(0, 0))
+	plugin.Apply(m.Copy())
+	require.Len(t, plugin.cache, 1)
+
+	time.Sleep(20 * time.Millisecond)
+	other := metric.New("other", map[string]string{}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0))
+	plugin.Apply(other)
+	require.Len(t, plugin.cache, 1)
+
+	// The series restarts after being evicted
+	plugin.Apply(m.Copy())
+	require.Len(t, plugin.cache[m.HashID()].windows["value"].values, 1)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	plugin := &Anomaly{
+		WindowSize:  5,
+		MinSamples:  2,
+		Threshold:   3,
+		ScoreSuffix: "_score",
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": float64(i)}, time.Unix(int64(i), 0))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	for _, m := range plugin.Apply(input...) {
+		m.Accept()
+	}
+	require.Equal(t, 3, delivered)
+}
@@ -0,0 +1,84 @@
+package processors
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// NewStreamingProcessorFromBatchProcessor is a converter that turns a batch processor into a streaming processor
+// buffering the metrics until being flushed.
+func NewStreamingProcessorFromBatchProcessor(p telegraf.BatchProcessor) telegraf.StreamingProcessor {
+	bp := &batchProcessor{
+		processor: p,
+	}
+	return bp
+}
+
+// BatchFinalizer is implemented by batch processors holding back metrics
+// across flushes, e.g. to wait for further metrics. Finalize is called once
+// after the last flush when stopping and returns the held back metrics.
+type BatchFinalizer interface {
+	Finalize() []telegraf.Metric
+}
+
+// batchProcessor is not safe for concurrent use, Add, Flush and Stop are
+// called from the same goroutine.
+type batchProcessor struct {
+	processor telegraf.BatchProcessor
+	acc       telegraf.Accumulator
+	buffer    []telegraf.Metric
+	Log       telegraf.Logger
+}
+
+func (bp *batchProcessor) SampleConfig() string {
+	return bp.processor.SampleConfig()
+}
+
+func (bp *batchProcessor) Start(acc telegraf.Accumulator) error {
+	bp.acc = acc
+	return nil
+}
+
+func (bp *batchProcessor) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	bp.buffer = append(bp.buffer, m)
+	return nil
+}
+
+// Flush hands the buffered metrics to the processor and passes the resulting
+// metrics downstream.
+func (bp *batchProcessor) Flush() {
+	batch := bp.buffer
+	bp.buffer = nil
+	for _, m := range bp.processor.ApplyBatch(batch) {
+		bp.acc.AddMetric(m)
+	}
+}
+
+// Stop flushes the remaining metrics including the ones held back by the
+// processor.
+func (bp *batchProcessor) Stop() {
+	bp.Flush()
+	if p, ok := bp.processor.(BatchFinalizer); ok {
+		for _, m := range p.Finalize() {
+			bp.acc.AddMetric(m)
+		}
+	}
+}
+
+// Init makes the batchProcessor of type Initializer to be able to call the Init method of the wrapped processor if needed.
+func (bp *batchProcessor) Init() error {
+	models.SetLoggerOnPlugin(bp.processor, bp.Log)
+	if p, ok := bp.processor.(telegraf.Initializer); ok {
+		err := p.Init()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnwrapBatch lets you retrieve the original telegraf.BatchProcessor from the StreamingProcessor.
+// This is necessary because the toml Unmarshaller won't look inside composed types.
+func (bp *batchProcessor) UnwrapBatch() telegraf.BatchProcessor {
+	return bp.processor
+}
@@ -0,0 +1,186 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cardinality_limiter
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// instances counts the created plugin instances to tag their statistics
+var instances atomic.Int64
+
+type CardinalityLimiter struct {
+	Limit         int             `toml:"limit"`
+	Action        string          `toml:"action"`
+	Tags          []string        `toml:"tags"`
+	Buckets       uint64          `toml:"buckets"`
+	OverflowValue string          `toml:"overflow_value"`
+	SeriesTTL     config.Duration `toml:"series_ttl"`
+	Log           telegraf.Logger `toml:"-"`
+
+	series    map[string]map[uint64]time.Time
+	warned    map[string]bool
+	lastSweep time.Time
+	timeFunc  func() time.Time
+
+	instance          string
+	seriesActive      selfstat.Stat
+	metricsSuppressed selfstat.Stat
+}
+
+func (*CardinalityLimiter) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CardinalityLimiter) Init() error {
+	if c.Limit <= 0 {
+		return errors.New("limit must be positive")
+	}
+
+	switch c.Action {
+	case "":
+		c.Action = "drop"
+	case "drop":
+	case "hash":
+		if c.Buckets == 0 {
+			c.Buckets = 100
+		}
+	case "overflow":
+		if c.OverflowValue == "" {
+			c.OverflowValue = "overflow"
+		}
+	default:
+		return fmt.Errorf("invalid action %q", c.Action)
+	}
+	if c.Action != "drop" && len(c.Tags) == 0 {
+		return fmt.Errorf("action %q requires at least one tag", c.Action)
+	}
+
+	if c.timeFunc == nil {
+		c.timeFunc = time.Now
+	}
+	c.series = make(map[string]map[uint64]time.Time)
+	c.warned = make(map[string]bool)
+	c.lastSweep = c.timeFunc()
+
+	// Identify the plugin instance by a unique number as instances with the
+	// same settings must not share their statistics
+	c.instance = strconv.FormatInt(instances.Add(1), 10)
+	tags := map[string]string{
+		"action":   c.Action,
+		"instance": c.instance,
+	}
+	c.seriesActive = selfstat.Register("cardinality_limiter", "series_active", tags)
+	c.metricsSuppressed = selfstat.Register("cardinality_limiter", "metrics_suppressed", tags)
+
+	return nil
+}
+
+func (c *CardinalityLimiter) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := c.timeFunc()
+	c.expire(now)
+
+	out := in[:0]
+	for _, m := range in {
+		name := m.Name()
+		id := m.HashID()
+
+		known, found := c.series[name]
+		if !found {
+			known = make(map[uint64]time.Time)
+			c.series[name] = known
+		}
+
+		// Known series and new series within the limit are passed as-is
+		if _, ok := known[id]; ok || len(known) < c.Limit {
+			if !ok {
+				c.seriesActive.Incr(1)
+			}
+			known[id] = now
+			out = append(out, m)
+			continue
+		}
+
+		if !c.warned[name] {
+			c.Log.Warnf("Series limit of %d exceeded for measurement %q, applying action %q", c.Limit, name, c.Action)
+			c.warned[name] = true
+		}
+		c.metricsSuppressed.Incr(1)
+
+		// Metrics without any of the tags to modify cannot be reduced so
+		// they are dropped.
+		if c.Action == "drop" || !c.reduce(m) {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// reduce replaces the values of the configured tags according to the action
+// and returns false if the metric does not contain any of those tags.
+func (c *CardinalityLimiter) reduce(m telegraf.Metric) bool {
+	var modified bool
+	for _, key := range c.Tags {
+		value, found := m.GetTag(key)
+		if !found {
+			continue
+		}
+		switch c.Action {
+		case "hash":
+			h := fnv.New64a()
+			h.Write([]byte(value))
+			m.AddTag(key, strconv.FormatUint(h.Sum64()%c.Buckets, 10))
+		case "overflow":
+			m.AddTag(key, c.OverflowValue)
+		}
+		modified = true
+	}
+	return modified
+}
+
+// expire forgets about series not seen within the series TTL. To limit the
+// overhead, the series are checked at most every half TTL.
+func (c *CardinalityLimiter) expire(now time.Time) {
+	ttl := time.Duration(c.SeriesTTL)
+	if ttl <= 0 || now.Sub(c.lastSweep) < ttl/2 {
+		return
+	}
+	c.lastSweep = now
+
+	for name, known := range c.series {
+		var removed int64
+		for id, lastSeen := range known {
+			if now.Sub(lastSeen) >= ttl {
+				delete(known, id)
+				removed++
+			}
+		}
+		c.seriesActive.Incr(-removed)
+		if len(known) == 0 {
+			delete(c.series, name)
+			delete(c.warned, name)
+		}
+	}
+}
+
+func init() {
+	processors.Add("cardinality_limiter", func() telegraf.Processor {
+		return &CardinalityLimiter{}
+	})
+}
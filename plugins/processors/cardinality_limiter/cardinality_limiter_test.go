@@ -0,0 +1,217 @@
+package cardinality_limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CardinalityLimiter
+		expected string
+	}{
+		{
+			name:     "no limit",
+			plugin:   &CardinalityLimiter{},
+			expected: "limit must be positive",
+		},
+		{
+			name:     "invalid action",
+			plugin:   &CardinalityLimiter{Limit: 1, Action: "foo"},
+			expected: `invalid action "foo"`,
+		},
+		{
+			name:     "hash without tags",
+			plugin:   &CardinalityLimiter{Limit: 1, Action: "hash"},
+			expected: `action "hash" requires at least one tag`,
+		},
+		{
+			name:     "overflow without tags",
+			plugin:   &CardinalityLimiter{Limit: 1, Action: "overflow"},
+			expected: `action "overflow" requires at least one tag`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 1}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"user": "bob"}, map[string]interface{}{"count": 2}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"user": "carol"}, map[string]interface{}{"count": 3}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"host": "a"}, map[string]interface{}{"count": 4}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 5}, time.Unix(0, 0)),
+		metric.New("disk", map[string]string{"user": "dave"}, map[string]interface{}{"count": 6}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "drop",
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 1}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "bob"}, map[string]interface{}{"count": 2}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 5}, time.Unix(0, 0)),
+				metric.New("disk", map[string]string{"user": "dave"}, map[string]interface{}{"count": 6}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "hash",
+			action: "hash",
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 1}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "bob"}, map[string]interface{}{"count": 2}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "0"}, map[string]interface{}{"count": 3}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 5}, time.Unix(0, 0)),
+				metric.New("disk", map[string]string{"user": "dave"}, map[string]interface{}{"count": 6}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "overflow",
+			action: "overflow",
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 1}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "bob"}, map[string]interface{}{"count": 2}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "overflow"}, map[string]interface{}{"count": 3}, time.Unix(0, 0)),
+				metric.New("http", map[string]string{"user": "alice"}, map[string]interface{}{"count": 5}, time.Unix(0, 0)),
+				metric.New("disk", map[string]string{"user": "dave"}, map[string]interface{}{"count": 6}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &CardinalityLimiter{
+				Limit:   2,
+				Action:  tt.action,
+				Tags:    []string{"user"},
+				Buckets: 1,
+				Log:     &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			defer unregisterStats(plugin)
+
+			metrics := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				metrics = append(metrics, m.Copy())
+			}
+			actual := plugin.Apply(metrics...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+
+			require.Equal(t, int64(3), plugin.seriesActive.Get())
+			require.Equal(t, int64(len(input)-4), plugin.metricsSuppressed.Get())
+		})
+	}
+}
+
+func TestSeriesTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &CardinalityLimiter{
+		Limit:     1,
+		SeriesTTL: config.Duration(time.Minute),
+		Log:       &testutil.Logger{},
+		timeFunc:  func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	defer unregisterStats(plugin)
+
+	a := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now)
+	b := metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 1}, now)
+
+	require.Len(t, plugin.Apply(a.Copy()), 1)
+	require.Empty(t, plugin.Apply(b.Copy()))
+
+	// Keep the series alive for longer than the TTL
+	now = now.Add(45 * time.Second)
+	require.Len(t, plugin.Apply(a.Copy()), 1)
+	now = now.Add(45 * time.Second)
+	require.Empty(t, plugin.Apply(b.Copy()))
+
+	// The series of "a" expired so "b" can take its place
+	now = now.Add(time.Minute)
+	require.Len(t, plugin.Apply(b.Copy()), 1)
+	require.Empty(t, plugin.Apply(a.Copy()))
+	require.Equal(t, int64(1), plugin.seriesActive.Get())
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for _, host := range []string{"a", "b", "c"} {
+		m := metric.New("cpu", map[string]string{"host": host}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &CardinalityLimiter{
+		Limit: 1,
+		Log:   &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer unregisterStats(plugin)
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		return delivered == 3
+	}, time.Second, 100*time.Millisecond)
+}
+
+func TestStatsPerInstance(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"user": "alice"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"user": "bob"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+
+	// Limiters with identical settings must not share their statistics
+	for range 2 {
+		plugin := &CardinalityLimiter{
+			Limit: 1,
+			Log:   &testutil.Logger{},
+		}
+		require.NoError(t, plugin.Init())
+		defer unregisterStats(plugin)
+
+		metrics := make([]telegraf.Metric, 0, len(input))
+		for _, m := range input {
+			metrics = append(metrics, m.Copy())
+		}
+		plugin.Apply(metrics...)
+		require.Equal(t, int64(1), plugin.seriesActive.Get())
+		require.Equal(t, int64(1), plugin.metricsSuppressed.Get())
+	}
+}
+
+func unregisterStats(plugin *CardinalityLimiter) {
+	tags := map[string]string{
+		"action":   plugin.Action,
+		"instance": plugin.instance,
+	}
+	selfstat.Unregister("cardinality_limiter", "series_active", tags)
+	selfstat.Unregister("cardinality_limiter", "metrics_suppressed", tags)
+}
@@ -4,12 +4,17 @@ package converter
 import (
 	_ "embed"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"net"
+	"net/netip"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -40,6 +45,12 @@ type conversion struct {
 	Timestamp         []string `toml:"timestamp"`
 	TimestampFormat   string   `toml:"timestamp_format"`
 	Base64IEEEFloat32 []string `toml:"base64_ieee_float32"`
+	Base64Decode      []string `toml:"base64_decode"`
+	HexDecode         []string `toml:"hex_decode"`
+	IPToInteger       []string `toml:"ip_to_integer"`
+	IntegerToIP       []string `toml:"integer_to_ip"`
+	MACAddress        []string `toml:"mac_address"`
+	DurationSeconds   []string `toml:"duration_seconds"`
 }
 
 type conversionFilter struct {
@@ -52,6 +63,12 @@ type conversionFilter struct {
 	Float             filter.Filter
 	Timestamp         filter.Filter
 	Base64IEEEFloat32 filter.Filter
+	Base64Decode      filter.Filter
+	HexDecode         filter.Filter
+	IPToInteger       filter.Filter
+	IntegerToIP       filter.Filter
+	MACAddress        filter.Filter
+	DurationSeconds   filter.Filter
 }
 
 func (*Converter) SampleConfig() string {
@@ -142,6 +159,36 @@ func compileFilter(conv *conversion) (*conversionFilter, error) {
 		return nil, err
 	}
 
+	cf.Base64Decode, err = filter.Compile(conv.Base64Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.HexDecode, err = filter.Compile(conv.HexDecode)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.IPToInteger, err = filter.Compile(conv.IPToInteger)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.IntegerToIP, err = filter.Compile(conv.IntegerToIP)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.MACAddress, err = filter.Compile(conv.MACAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.DurationSeconds, err = filter.Compile(conv.DurationSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	return cf, nil
 }
 
@@ -267,6 +314,48 @@ func (p *Converter) convertFields(metric telegraf.Metric) {
 			} else {
 				metric.AddField(key, v)
 			}
+		case p.fieldConversions.Base64Decode != nil && p.fieldConversions.Base64Decode.Match(key):
+			if v, err := decodeBase64(value); err != nil {
+				p.Log.Errorf("Converting to base64_decode [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.HexDecode != nil && p.fieldConversions.HexDecode.Match(key):
+			if v, err := decodeHex(value); err != nil {
+				p.Log.Errorf("Converting to hex_decode [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.IPToInteger != nil && p.fieldConversions.IPToInteger.Match(key):
+			if v, err := ipToInteger(value); err != nil {
+				p.Log.Errorf("Converting to ip_to_integer [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.IntegerToIP != nil && p.fieldConversions.IntegerToIP.Match(key):
+			if v, err := integerToIP(value); err != nil {
+				p.Log.Errorf("Converting to integer_to_ip [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.MACAddress != nil && p.fieldConversions.MACAddress.Match(key):
+			if v, err := normalizeMAC(value); err != nil {
+				p.Log.Errorf("Converting to mac_address [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.DurationSeconds != nil && p.fieldConversions.DurationSeconds.Match(key):
+			if v, err := durationToSeconds(value); err != nil {
+				p.Log.Errorf("Converting to duration_seconds [%T] failed: %v", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
 		}
 	}
 }
@@ -389,6 +478,101 @@ func base64ToFloat32(encoded string) (float32, error) {
 	return math.Float32frombits(uint32(bits)), nil
 }
 
+func decodeBase64(v interface{}) (string, error) {
+	encoded, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+
+	// Accept both padded and unpadded input
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(encoded)
+	}
+	return string(decoded), err
+}
+
+func decodeHex(v interface{}) (string, error) {
+	encoded, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+
+	encoded = strings.TrimPrefix(strings.TrimPrefix(encoded, "0x"), "0X")
+	decoded, err := hex.DecodeString(encoded)
+	return string(decoded), err
+}
+
+// ipToInteger converts an IPv4 address to its integer representation
+func ipToInteger(v interface{}) (uint64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return 0, err
+	}
+	addr = addr.Unmap()
+	if !addr.Is4() {
+		return 0, fmt.Errorf("%q is not an IPv4 address", s)
+	}
+	b := addr.As4()
+	return uint64(binary.BigEndian.Uint32(b[:])), nil
+}
+
+// integerToIP converts an integer to the corresponding IPv4 address
+func integerToIP(v interface{}) (string, error) {
+	i, err := internal.ToUint64(v)
+	if err != nil {
+		return "", err
+	}
+	if i > math.MaxUint32 {
+		return "", fmt.Errorf("%d exceeds the IPv4 address range", i)
+	}
+
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(i))
+	return netip.AddrFrom4(b).String(), nil
+}
+
+// normalizeMAC converts MAC addresses in colon, hyphen, dot or plain
+// hexadecimal notation to lower-case colon notation
+func normalizeMAC(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+
+	// Plain hexadecimal notation, e.g. "0011223344ff"
+	if len(s) == 12 || len(s) == 16 {
+		if b, err := hex.DecodeString(s); err == nil {
+			return net.HardwareAddr(b).String(), nil
+		}
+	}
+
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		return "", err
+	}
+	return mac.String(), nil
+}
+
+// durationToSeconds converts duration strings such as "1h30m" to seconds
+func durationToSeconds(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return d.Seconds(), nil
+}
+
 func init() {
 	processors.Add("converter", func() telegraf.Processor {
 		return &Converter{}
@@ -668,6 +668,93 @@ func TestConverter(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "binary and address conversions",
+			converter: &Converter{
+				Fields: &conversion{
+					Base64Decode:    []string{"b64*"},
+					HexDecode:       []string{"hex*"},
+					IPToInteger:     []string{"ip"},
+					IntegerToIP:     []string{"ip_int"},
+					MACAddress:      []string{"mac*"},
+					DurationSeconds: []string{"uptime"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"b64":       "aGVsbG8=",
+					"b64_raw":   "aGVsbG8",
+					"hex":       "68656c6c6f",
+					"hex_0x":    "0x68656c6c6f",
+					"ip":        "192.168.1.10",
+					"ip_int":    uint64(3232235786),
+					"mac":       "00-1A-2B-3C-4D-5E",
+					"mac_cisco": "001a.2b3c.4d5e",
+					"mac_plain": "001A2B3C4D5E",
+					"uptime":    "1h30m",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"b64":       "hello",
+						"b64_raw":   "hello",
+						"hex":       "hello",
+						"hex_0x":    "hello",
+						"ip":        uint64(3232235786),
+						"ip_int":    "192.168.1.10",
+						"mac":       "00:1a:2b:3c:4d:5e",
+						"mac_cisco": "00:1a:2b:3c:4d:5e",
+						"mac_plain": "00:1a:2b:3c:4d:5e",
+						"uptime":    float64(5400),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "invalid binary and address conversions",
+			converter: &Converter{
+				Fields: &conversion{
+					Base64Decode:    []string{"b64"},
+					HexDecode:       []string{"hex"},
+					IPToInteger:     []string{"ip*"},
+					IntegerToIP:     []string{"int"},
+					MACAddress:      []string{"mac"},
+					DurationSeconds: []string{"uptime"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a":      42.0,
+					"b64":    "!!!",
+					"hex":    "xyz",
+					"ip":     "not an ip",
+					"ipv6":   "2001:db8::1",
+					"int":    int64(1) << 40,
+					"mac":    "00:1a:2b",
+					"uptime": int64(42),
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
 	}
 
 	for _, tt := range tests {
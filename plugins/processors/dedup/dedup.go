@@ -17,11 +17,13 @@ import (
 var sampleConfig string
 
 type Dedup struct {
-	DedupInterval config.Duration `toml:"dedup_interval"`
-	Log           telegraf.Logger `toml:"-"`
+	DedupInterval     config.Duration `toml:"dedup_interval"`
+	HeartbeatInterval config.Duration `toml:"heartbeat_interval"`
+	Log               telegraf.Logger `toml:"-"`
 
 	flushTime time.Time
 	cache     map[uint64]telegraf.Metric
+	seen      map[uint64]time.Time
 }
 
 func (*Dedup) SampleConfig() string {
@@ -29,6 +31,17 @@ func (*Dedup) SampleConfig() string {
 }
 
 func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	if d.seen == nil {
+		d.seen = make(map[uint64]time.Time)
+	}
+
+	// Without a heartbeat interval repeated values are passed once the
+	// dedup interval has passed since the last emission
+	heartbeat := time.Duration(d.HeartbeatInterval)
+	if heartbeat <= 0 {
+		heartbeat = time.Duration(d.DedupInterval)
+	}
+
 	idx := 0
 	for _, metric := range metrics {
 		id := metric.HashID()
@@ -41,9 +54,12 @@ func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 			idx++
 			continue
 		}
+		lastSeen := d.lastSeen(id)
+		d.touch(metric, id)
 
-		// If cache item has expired then refresh it
-		if time.Since(m.Time()) >= time.Duration(d.DedupInterval) {
+		// If cache item has expired, i.e. the series was not seen within the
+		// dedup interval, or the heartbeat is due then refresh it
+		if time.Since(lastSeen) >= time.Duration(d.DedupInterval) || time.Since(m.Time()) >= heartbeat {
 			d.save(metric, id)
 			metrics[idx] = metric
 			idx++
@@ -137,8 +153,10 @@ func (d *Dedup) cleanup() {
 	d.flushTime = time.Now()
 	keep := make(map[uint64]telegraf.Metric)
 	for id, metric := range d.cache {
-		if time.Since(metric.Time()) < time.Duration(d.DedupInterval) {
+		if time.Since(d.lastSeen(id)) < time.Duration(d.DedupInterval) {
 			keep[id] = metric
+		} else {
+			delete(d.seen, id)
 		}
 	}
 	d.cache = keep
@@ -148,6 +166,23 @@ func (d *Dedup) cleanup() {
 func (d *Dedup) save(metric telegraf.Metric, id uint64) {
 	d.cache[id] = metric.Copy()
 	d.cache[id].Accept()
+	d.touch(metric, id)
+}
+
+// Record the time the series was last seen, including suppressed metrics
+func (d *Dedup) touch(metric telegraf.Metric, id uint64) {
+	if metric.Time().After(d.seen[id]) {
+		d.seen[id] = metric.Time()
+	}
+}
+
+// Get the time the series was last seen falling back to the last emission
+// for items restored from the state
+func (d *Dedup) lastSeen(id uint64) time.Time {
+	if t, found := d.seen[id]; found {
+		return t
+	}
+	return d.cache[id].Time()
 }
 
 func init() {
@@ -156,6 +191,7 @@ func init() {
 			DedupInterval: config.Duration(10 * time.Minute),
 			flushTime:     time.Now(),
 			cache:         make(map[uint64]telegraf.Metric),
+			seen:          make(map[uint64]time.Time),
 		}
 	})
 }
@@ -530,3 +530,32 @@ func TestStatePersistence(t *testing.T) {
 	}
 	require.Len(t, actualState, expectedLen)
 }
+
+func TestHeartbeat(t *testing.T) {
+	now := time.Now()
+
+	plugin := &Dedup{
+		DedupInterval:     config.Duration(time.Hour),
+		HeartbeatInterval: config.Duration(time.Minute),
+		flushTime:         now,
+		cache:             make(map[uint64]telegraf.Metric),
+	}
+
+	input := []telegraf.Metric{
+		metric.New("m1", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-30*time.Second)),
+		metric.New("m1", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-20*time.Second)),
+		metric.New("m1", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-10*time.Second)),
+		metric.New("m2", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-2*time.Minute)),
+		metric.New("m2", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-time.Second)),
+		metric.New("m2", map[string]string{}, map[string]interface{}{"value": 1}, now),
+	}
+	// The heartbeat passes the repeated m2 as the last emission was more
+	// than a minute ago
+	expected := []telegraf.Metric{input[0], input[3], input[4]}
+
+	var actual []telegraf.Metric
+	for _, m := range input {
+		actual = append(actual, plugin.Apply(m)...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
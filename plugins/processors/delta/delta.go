@@ -0,0 +1,163 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package delta
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Delta struct {
+	Fields         []string        `toml:"fields"`
+	Suffix         string          `toml:"suffix"`
+	DropOriginal   bool            `toml:"drop_original"`
+	SkipFirst      bool            `toml:"skip_first"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	cache  map[uint64]*entry
+}
+
+type entry struct {
+	values map[string]interface{}
+	seen   time.Time
+}
+
+func (*Delta) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Delta) Init() error {
+	if len(d.Fields) == 0 {
+		d.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(d.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	d.accept = f
+
+	if d.Suffix == "" && !d.DropOriginal {
+		return fmt.Errorf("empty suffix requires %q to be set", "drop_original")
+	}
+
+	d.cache = make(map[uint64]*entry)
+
+	return nil
+}
+
+func (d *Delta) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		id := m.HashID()
+		// Create a new entry for unseen metrics
+		stored, ok := d.cache[id]
+		if !ok {
+			stored = &entry{values: make(map[string]interface{})}
+		}
+
+		deltas := make(map[string]interface{})
+		var originals []string
+		for _, field := range m.FieldList() {
+			if !d.accept.Match(field.Key) {
+				continue
+			}
+
+			// Ignore all fields not being numbers
+			current, err := numeric(field.Value)
+			if err != nil {
+				d.Log.Tracef("Skipping field %q with value %v (%T): %v", field.Key, field.Value, field.Value, err)
+				continue
+			}
+			originals = append(originals, field.Key)
+
+			previous, found := stored.values[field.Key]
+			stored.values[field.Key] = current
+			if !found {
+				if !d.SkipFirst {
+					deltas[field.Key+d.Suffix] = current
+				}
+				continue
+			}
+			deltas[field.Key+d.Suffix] = difference(previous, current)
+		}
+
+		// Modify the fields after iterating them to not invalidate the field list
+		if d.DropOriginal {
+			for _, key := range originals {
+				m.RemoveField(key)
+			}
+		}
+		for key, value := range deltas {
+			m.AddField(key, value)
+		}
+		stored.seen = now
+		d.cache[id] = stored
+
+		// Drop metrics without any remaining field
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	// Cleanup cache entries that are too old
+	if d.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(d.ExpiryInterval))
+		maps.DeleteFunc(d.cache, func(_ uint64, e *entry) bool {
+			return e.seen.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// numeric converts integer values to int64 to keep the integer type for
+// the delta and all other values to float64
+func numeric(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return internal.ToInt64(value)
+	case float32, float64:
+		return internal.ToFloat64(value)
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+// difference returns the difference of the values, the result is an integer
+// if both values are integers and a float otherwise
+func difference(previous, current interface{}) interface{} {
+	pi, pIsInt := previous.(int64)
+	ci, cIsInt := current.(int64)
+	if pIsInt && cIsInt {
+		return ci - pi
+	}
+
+	//nolint:errcheck // values were converted by numeric() before
+	pf, _ := internal.ToFloat64(previous)
+	//nolint:errcheck // values were converted by numeric() before
+	cf, _ := internal.ToFloat64(current)
+	return cf - pf
+}
+
+func init() {
+	processors.Add("delta", func() telegraf.Processor {
+		return &Delta{
+			Suffix: "_delta",
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package delta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Delta{}
+	require.ErrorContains(t, plugin.Init(), `empty suffix requires "drop_original" to be set`)
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	input := []telegraf.Metric{
+		metric.New("foo", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(100), "value": 1.5, "state": "ok"}, now),
+		metric.New("foo", map[string]string{"host": "b"}, map[string]interface{}{"count": uint64(10), "value": 2.0, "state": "ok"}, now),
+		metric.New("foo", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(150), "value": 1.0, "state": "ok"}, now.Add(time.Second)),
+		metric.New("foo", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(120), "value": 1.25, "state": "ok"}, now.Add(2*time.Second)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"foo",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"count": int64(100), "count_delta": int64(100), "value": 1.5, "value_delta": 1.5, "state": "ok"},
+			now,
+		),
+		metric.New(
+			"foo",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"count": uint64(10), "count_delta": int64(10), "value": 2.0, "value_delta": 2.0, "state": "ok"},
+			now,
+		),
+		metric.New(
+			"foo",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"count": int64(150), "count_delta": int64(50), "value": 1.0, "value_delta": -0.5, "state": "ok"},
+			now.Add(time.Second),
+		),
+		metric.New(
+			"foo",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"count": int64(120), "count_delta": int64(-30), "value": 1.25, "value_delta": 0.25, "state": "ok"},
+			now.Add(2*time.Second),
+		),
+	}
+
+	plugin := &Delta{
+		Suffix: "_delta",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSkipFirstDropOriginal(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i, v := range []int64{10, 30, 35} {
+		m := metric.New(
+			"foo",
+			map[string]string{},
+			map[string]interface{}{"count": v, "value": float64(v)},
+			now.Add(time.Duration(i)*time.Second),
+		)
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("foo", map[string]string{}, map[string]interface{}{"count": int64(20), "value": float64(30)}, now.Add(time.Second)),
+		metric.New("foo", map[string]string{}, map[string]interface{}{"count": int64(5), "value": float64(35)}, now.Add(2*time.Second)),
+	}
+
+	plugin := &Delta{
+		Fields:       []string{"count"},
+		DropOriginal: true,
+		SkipFirst:    true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first metric keeps the value field and is not dropped
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 3)
+	require.Equal(t, map[string]interface{}{"value": float64(10)}, actual[0].Fields())
+	testutil.RequireMetricsEqual(t, expected, actual[1:])
+
+	// Metrics without remaining fields are dropped
+	plugin.Fields = []string{"*"}
+	require.NoError(t, plugin.Init())
+	actual = plugin.Apply(input[0])
+	require.Empty(t, actual)
+	require.Len(t, delivered, 1)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	plugin := &Delta{
+		Suffix:         "_delta",
+		ExpiryInterval: config.Duration(10 * time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("foo", map[string]string{}, map[string]interface{}{"value": int64(1)}, now)
+	plugin.Apply(m)
+	require.Len(t, plugin.cache, 1)
+
+	// Artificially age the cache entry and check it is removed
+	plugin.cache[m.HashID()].seen = time.Now().Add(-11 * time.Second)
+	plugin.Apply(metric.New("bar", map[string]string{}, map[string]interface{}{"value": int64(1)}, now))
+	require.Len(t, plugin.cache, 1)
+	require.NotContains(t, plugin.cache, m.HashID())
+}
@@ -0,0 +1,166 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package derivative_of_tag_change
+
+import (
+	_ "embed"
+	"errors"
+	"hash/fnv"
+	"maps"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DerivativeOfTagChange struct {
+	Tag            string          `toml:"tag"`
+	Field          string          `toml:"field"`
+	Suffix         string          `toml:"suffix"`
+	DropOriginal   bool            `toml:"drop_original"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	key   string
+	cache map[uint64]*state
+}
+
+// state is the current state of a series and the time it was entered
+type state struct {
+	value string
+	since time.Time
+	seen  time.Time
+}
+
+func (*DerivativeOfTagChange) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DerivativeOfTagChange) Init() error {
+	if (d.Tag == "") == (d.Field == "") {
+		return errors.New("exactly one of tag or field required")
+	}
+	d.key = d.Tag
+	if d.Field != "" {
+		d.key = d.Field
+	}
+
+	if d.Suffix == "" {
+		return errors.New("suffix must not be empty")
+	}
+
+	d.cache = make(map[uint64]*state)
+
+	return nil
+}
+
+func (d *DerivativeOfTagChange) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		value, found := d.value(m)
+		if !found {
+			out = append(out, m)
+			continue
+		}
+
+		id := d.seriesID(m)
+		current, found := d.cache[id]
+		switch {
+		case !found:
+			d.cache[id] = &state{value: value, since: m.Time(), seen: now}
+		case current.value != value:
+			out = append(out, d.transition(m, current, value))
+			current.value = value
+			current.since = m.Time()
+			current.seen = now
+		default:
+			current.seen = now
+		}
+
+		if d.DropOriginal {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	// Cleanup cache entries that are too old
+	if d.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(d.ExpiryInterval))
+		maps.DeleteFunc(d.cache, func(_ uint64, s *state) bool {
+			return s.seen.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// value returns the watched state of the metric
+func (d *DerivativeOfTagChange) value(m telegraf.Metric) (string, bool) {
+	if d.Tag != "" {
+		return m.GetTag(d.Tag)
+	}
+
+	v, found := m.GetField(d.Field)
+	if !found {
+		return "", false
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.Log.Tracef("Ignoring non-string field %q of metric %q", d.Field, m.Name())
+		return "", false
+	}
+	return s, true
+}
+
+// seriesID identifies the series of the metric without the watched tag as
+// the tag would otherwise start a new series on every change
+func (d *DerivativeOfTagChange) seriesID(m telegraf.Metric) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+	for _, tag := range m.TagList() {
+		if tag.Key == d.Tag {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tag.Value))
+		h.Write([]byte("\n"))
+	}
+	return h.Sum64()
+}
+
+// transition creates the event for the change from the previous state to the
+// given value of the metric
+func (d *DerivativeOfTagChange) transition(m telegraf.Metric, previous *state, value string) telegraf.Metric {
+	tags := make(map[string]string, len(m.TagList())+2)
+	for _, tag := range m.TagList() {
+		if tag.Key == d.Tag {
+			continue
+		}
+		tags[tag.Key] = tag.Value
+	}
+	tags[d.key+"_from"] = previous.value
+	tags[d.key+"_to"] = value
+
+	fields := map[string]interface{}{
+		"duration": m.Time().Sub(previous.since).Seconds(),
+	}
+
+	return metric.New(m.Name()+d.Suffix, tags, fields, m.Time(), telegraf.Untyped)
+}
+
+func init() {
+	processors.Add("derivative_of_tag_change", func() telegraf.Processor {
+		return &DerivativeOfTagChange{
+			Suffix: "_transition",
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package derivative_of_tag_change
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DerivativeOfTagChange
+		expected string
+	}{
+		{
+			name:     "no tag or field",
+			plugin:   &DerivativeOfTagChange{Suffix: "_transition"},
+			expected: "exactly one of tag or field required",
+		},
+		{
+			name:     "tag and field",
+			plugin:   &DerivativeOfTagChange{Tag: "state", Field: "state", Suffix: "_transition"},
+			expected: "exactly one of tag or field required",
+		},
+		{
+			name:     "empty suffix",
+			plugin:   &DerivativeOfTagChange{Tag: "state"},
+			expected: "suffix must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTagTransitions(t *testing.T) {
+	plugin := &DerivativeOfTagChange{
+		Tag:    "state",
+		Suffix: "_transition",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(0, 0)),
+		metric.New("service", map[string]string{"name": "b", "state": "running"}, map[string]interface{}{"pid": 2}, time.Unix(0, 0)),
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(10, 0)),
+		metric.New("service", map[string]string{"name": "a", "state": "stopped"}, map[string]interface{}{"pid": 0}, time.Unix(20, 0)),
+		metric.New("service", map[string]string{"name": "b"}, map[string]interface{}{"pid": 2}, time.Unix(25, 0)),
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 3}, time.Unix(25, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(0, 0)),
+		metric.New("service", map[string]string{"name": "b", "state": "running"}, map[string]interface{}{"pid": 2}, time.Unix(0, 0)),
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(10, 0)),
+		metric.New(
+			"service_transition",
+			map[string]string{"name": "a", "state_from": "running", "state_to": "stopped"},
+			map[string]interface{}{"duration": float64(20)},
+			time.Unix(20, 0),
+		),
+		metric.New("service", map[string]string{"name": "a", "state": "stopped"}, map[string]interface{}{"pid": 0}, time.Unix(20, 0)),
+		metric.New("service", map[string]string{"name": "b"}, map[string]interface{}{"pid": 2}, time.Unix(25, 0)),
+		metric.New(
+			"service_transition",
+			map[string]string{"name": "a", "state_from": "stopped", "state_to": "running"},
+			map[string]interface{}{"duration": float64(5)},
+			time.Unix(25, 0),
+		),
+		metric.New("service", map[string]string{"name": "a", "state": "running"}, map[string]interface{}{"pid": 3}, time.Unix(25, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestFieldTransitionsDropOriginal(t *testing.T) {
+	plugin := &DerivativeOfTagChange{
+		Field:        "status",
+		Suffix:       "_event",
+		DropOriginal: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("ups", map[string]string{"id": "1"}, map[string]interface{}{"status": "online"}, time.Unix(0, 0)),
+		metric.New("ups", map[string]string{"id": "1"}, map[string]interface{}{"status": int64(3)}, time.Unix(5, 0)),
+		metric.New("ups", map[string]string{"id": "1"}, map[string]interface{}{"status": "on battery"}, time.Unix(60, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("ups", map[string]string{"id": "1"}, map[string]interface{}{"status": int64(3)}, time.Unix(5, 0)),
+		metric.New(
+			"ups_event",
+			map[string]string{"id": "1", "status_from": "online", "status_to": "on battery"},
+			map[string]interface{}{"duration": float64(60)},
+			time.Unix(60, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	plugin := &DerivativeOfTagChange{
+		Tag:          "state",
+		Suffix:       "_transition",
+		DropOriginal: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+	input := []telegraf.Metric{
+		metric.New("service", map[string]string{"state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(0, 0)),
+		metric.New("service", map[string]string{"state": "stopped"}, map[string]interface{}{"pid": 0}, time.Unix(10, 0)),
+	}
+	for i, m := range input {
+		input[i], _ = metric.WithTracking(m, notify)
+	}
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	require.Equal(t, 2, delivered)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	plugin := &DerivativeOfTagChange{
+		Tag:            "state",
+		Suffix:         "_transition",
+		ExpiryInterval: config.Duration(time.Hour),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("service", map[string]string{"state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(0, 0))
+	plugin.Apply(m)
+	require.Len(t, plugin.cache, 1)
+
+	// Age the entry beyond the expiry interval
+	for _, s := range plugin.cache {
+		s.seen = time.Now().Add(-2 * time.Hour)
+	}
+	m = metric.New("other", map[string]string{"state": "running"}, map[string]interface{}{"pid": 1}, time.Unix(0, 0))
+	plugin.Apply(m)
+	require.Len(t, plugin.cache, 1)
+}
@@ -0,0 +1,227 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package downsample
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Downsample struct {
+	Resolution config.Duration `toml:"resolution"`
+	Method     string          `toml:"method"`
+	Fields     []string        `toml:"fields"`
+	Log        telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+
+	// buckets not yet emitted in the order of their creation
+	pending []*bucket
+	index   map[bucketKey]*bucket
+	now     func() time.Time
+}
+
+type bucketKey struct {
+	id    uint64
+	start int64
+}
+
+// bucket collects the metrics of a series within one resolution interval
+type bucket struct {
+	key    bucketKey
+	start  time.Time
+	name   string
+	tags   map[string]string
+	tp     telegraf.ValueType
+	fields map[string]*reduction
+	order  []string
+}
+
+// reduction accumulates the values of a single field
+type reduction struct {
+	reduce      bool
+	first, last interface{}
+	min, max    interface{}
+	minValue    float64
+	maxValue    float64
+	sum         float64
+	count       int
+}
+
+func (*Downsample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Downsample) Init() error {
+	if d.Resolution <= 0 {
+		return errors.New("resolution must be positive")
+	}
+
+	switch d.Method {
+	case "":
+		d.Method = "last"
+	case "first", "last", "mean", "min", "max":
+	default:
+		return fmt.Errorf("invalid method %q", d.Method)
+	}
+
+	if len(d.Fields) == 0 {
+		d.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(d.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	d.accept = f
+
+	d.index = make(map[bucketKey]*bucket)
+	if d.now == nil {
+		d.now = time.Now
+	}
+
+	return nil
+}
+
+func (d *Downsample) ApplyBatch(in []telegraf.Metric) []telegraf.Metric {
+	resolution := time.Duration(d.Resolution)
+
+	// Sort the metrics into their buckets, the original metrics are consumed
+	// and replaced by the reduced metric of the bucket
+	for _, m := range in {
+		id := m.HashID()
+		start := m.Time().Truncate(resolution)
+		key := bucketKey{id: id, start: start.UnixNano()}
+		b, found := d.index[key]
+		if !found {
+			b = &bucket{
+				key:    key,
+				start:  start,
+				name:   m.Name(),
+				tags:   m.Tags(),
+				tp:     m.Type(),
+				fields: make(map[string]*reduction),
+			}
+			d.index[key] = b
+			d.pending = append(d.pending, b)
+		}
+		for _, field := range m.FieldList() {
+			r, found := b.fields[field.Key]
+			if !found {
+				r = &reduction{reduce: d.accept.Match(field.Key)}
+				b.fields[field.Key] = r
+				b.order = append(b.order, field.Key)
+			}
+			r.add(field.Value)
+		}
+		m.Drop()
+	}
+
+	// A bucket is complete if its interval passed or if the series already
+	// received metrics for a later bucket
+	latest := make(map[uint64]int64, len(d.pending))
+	for _, b := range d.pending {
+		latest[b.key.id] = max(latest[b.key.id], b.key.start)
+	}
+	now := d.now()
+
+	out := make([]telegraf.Metric, 0, len(d.pending))
+	remaining := d.pending[:0]
+	for _, b := range d.pending {
+		if b.start.Add(resolution).After(now) && b.key.start == latest[b.key.id] {
+			remaining = append(remaining, b)
+			continue
+		}
+		out = append(out, d.emit(b))
+		delete(d.index, b.key)
+	}
+	clear(d.pending[len(remaining):])
+	d.pending = remaining
+
+	return out
+}
+
+// Finalize emits the incomplete buckets when stopping
+func (d *Downsample) Finalize() []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(d.pending))
+	for _, b := range d.pending {
+		out = append(out, d.emit(b))
+	}
+	d.pending = nil
+	d.index = make(map[bucketKey]*bucket)
+
+	return out
+}
+
+func (d *Downsample) emit(b *bucket) telegraf.Metric {
+	fields := make(map[string]interface{}, len(b.order))
+	for _, key := range b.order {
+		fields[key] = b.fields[key].result(d.Method)
+	}
+	return metric.New(b.name, b.tags, fields, b.start, b.tp)
+}
+
+func (r *reduction) add(v interface{}) {
+	if r.count == 0 {
+		r.first = v
+	}
+	r.last = v
+
+	var fv float64
+	switch v := v.(type) {
+	case int64:
+		fv = float64(v)
+	case uint64:
+		fv = float64(v)
+	case float64:
+		fv = v
+	default:
+		// Only numeric values are reduced
+		r.reduce = false
+		return
+	}
+
+	if r.count == 0 || fv < r.minValue {
+		r.min, r.minValue = v, fv
+	}
+	if r.count == 0 || fv > r.maxValue {
+		r.max, r.maxValue = v, fv
+	}
+	r.sum += fv
+	r.count++
+}
+
+func (r *reduction) result(method string) interface{} {
+	if !r.reduce {
+		return r.last
+	}
+
+	switch method {
+	case "first":
+		return r.first
+	case "mean":
+		return r.sum / float64(r.count)
+	case "min":
+		return r.min
+	case "max":
+		return r.max
+	}
+	return r.last
+}
+
+func init() {
+	processors.AddBatch("downsample", func() telegraf.BatchProcessor {
+		return &Downsample{
+			Resolution: config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package downsample
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Downsample{}
+	require.ErrorContains(t, plugin.Init(), "resolution must be positive")
+
+	plugin = &Downsample{Resolution: config.Duration(time.Minute), Method: "median"}
+	require.ErrorContains(t, plugin.Init(), `invalid method "median"`)
+}
+
+func TestMethods(t *testing.T) {
+	start := time.Unix(1700000040, 0)
+	values := []interface{}{int64(3), int64(7), int64(2), int64(4)}
+
+	tests := []struct {
+		method   string
+		expected interface{}
+	}{
+		{method: "first", expected: int64(3)},
+		{method: "last", expected: int64(4)},
+		{method: "mean", expected: float64(4)},
+		{method: "min", expected: int64(2)},
+		{method: "max", expected: int64(7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			input := make([]telegraf.Metric, 0, len(values))
+			for i, v := range values {
+				input = append(input, metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"value": v, "state": "ok"},
+					start.Add(time.Duration(i)*5*time.Second),
+				))
+			}
+
+			plugin := &Downsample{
+				Resolution: config.Duration(time.Minute),
+				Method:     tt.method,
+				Log:        &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			expected := []telegraf.Metric{
+				metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"value": tt.expected, "state": "ok"},
+					time.Unix(1700000040, 0),
+				),
+			}
+			actual := plugin.ApplyBatch(input)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestAlignment(t *testing.T) {
+	plugin := &Downsample{
+		Resolution: config.Duration(10 * time.Second),
+		Method:     "mean",
+		Fields:     []string{"temperature"},
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 20.0, "count": int64(1)}, time.Unix(1700000001, 0)),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"temperature": 10.0}, time.Unix(1700000002, 0)),
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 22.0, "count": int64(2)}, time.Unix(1700000009, 0)),
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 30.0, "count": int64(3)}, time.Unix(1700000010, 0)),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"temperature": 12.0}, time.Unix(1700000015, 0)),
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 35.0, "count": int64(4)}, time.Unix(1700000025, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 21.0, "count": int64(2)}, time.Unix(1700000000, 0)),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"temperature": 10.0}, time.Unix(1700000000, 0)),
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 30.0, "count": int64(3)}, time.Unix(1700000010, 0)),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"temperature": 12.0}, time.Unix(1700000010, 0)),
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"temperature": 35.0, "count": int64(4)}, time.Unix(1700000020, 0)),
+	}
+	actual := plugin.ApplyBatch(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Empty(t, plugin.Finalize())
+}
+
+func TestIncompleteBucket(t *testing.T) {
+	now := time.Unix(1700000035, 0)
+	plugin := &Downsample{
+		Resolution: config.Duration(10 * time.Second),
+		Method:     "max",
+		Log:        &testutil.Logger{},
+		now:        func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// The current bucket is held back until it is complete
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 10.0}, time.Unix(1700000025, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 40.0}, time.Unix(1700000031, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 10.0}, time.Unix(1700000020, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.ApplyBatch(input))
+
+	// Metrics of the next flush are merged into the held back bucket
+	input = []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 50.0}, time.Unix(1700000034, 0)),
+	}
+	require.Empty(t, plugin.ApplyBatch(input))
+
+	// The bucket is emitted once its interval passed
+	now = time.Unix(1700000040, 0)
+	expected = []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 50.0}, time.Unix(1700000030, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.ApplyBatch(nil))
+
+	// Incomplete buckets are emitted when stopping
+	input = []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 20.0}, time.Unix(1700000041, 0)),
+	}
+	require.Empty(t, plugin.ApplyBatch(input))
+	expected = []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 20.0}, time.Unix(1700000040, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Finalize())
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": float64(i)}, time.Unix(1700000000+int64(i), 0))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Downsample{
+		Resolution: config.Duration(time.Minute),
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.ApplyBatch(input)
+	require.Len(t, actual, 1)
+	require.Equal(t, 3, delivered)
+}
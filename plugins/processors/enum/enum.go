@@ -3,11 +3,16 @@ package enum
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -26,10 +31,24 @@ type mapping struct {
 	Dest    string      `toml:"dest"`
 	Default interface{} `toml:"default"`
 
+	UnmappedTag string `toml:"unmapped_tag"`
+
 	fieldFilter filter.Filter
 	tagFilter   filter.Filter
 
 	ValueMappings map[string]interface{}
+	RangeMappings []*rangeMapping `toml:"range_mappings"`
+}
+
+// rangeMapping maps all numeric values within the inclusive range to the
+// given value. Unset bounds denote an unbounded range.
+type rangeMapping struct {
+	Min   interface{} `toml:"min"`
+	Max   interface{} `toml:"max"`
+	Value interface{} `toml:"value"`
+
+	min float64
+	max float64
 }
 
 func (*Enum) SampleConfig() string {
@@ -59,6 +78,12 @@ func (mapper *Enum) Init() error {
 			return fmt.Errorf("failed to create new tag filter: %w", err)
 		}
 		mapping.tagFilter = tagFilter
+
+		for i, r := range mapping.RangeMappings {
+			if err := r.init(); err != nil {
+				return fmt.Errorf("invalid range mapping %d: %w", i+1, err)
+			}
+		}
 	}
 
 	return nil
@@ -74,16 +99,24 @@ func (mapper *Enum) Apply(in ...telegraf.Metric) []telegraf.Metric {
 func (mapper *Enum) applyMappings(metric telegraf.Metric) telegraf.Metric {
 	newFields := make(map[string]interface{})
 	newTags := make(map[string]string)
+	unmapped := make(map[string][]string)
 
 	for _, mapping := range mapper.Mappings {
 		if mapping.fieldFilter != nil {
-			fieldMapping(metric, mapping, newFields)
+			fieldMapping(metric, mapping, newFields, unmapped)
 		}
 		if mapping.tagFilter != nil {
-			tagMapping(metric, mapping, newTags)
+			tagMapping(metric, mapping, newTags, unmapped)
 		}
 	}
 
+	// Mark the metric with the names of all fields and tags with unmapped
+	// values if requested
+	for k, names := range unmapped {
+		slices.Sort(names)
+		newTags[k] = strings.Join(slices.Compact(names), ",")
+	}
+
 	for k, v := range newFields {
 		writeField(metric, k, v)
 	}
@@ -95,7 +128,7 @@ func (mapper *Enum) applyMappings(metric telegraf.Metric) telegraf.Metric {
 	return metric
 }
 
-func fieldMapping(metric telegraf.Metric, mapping *mapping, newFields map[string]interface{}) {
+func fieldMapping(metric telegraf.Metric, mapping *mapping, newFields map[string]interface{}, unmapped map[string][]string) {
 	fields := metric.FieldList()
 	for _, f := range fields {
 		if !mapping.fieldFilter.Match(f.Key) {
@@ -104,12 +137,16 @@ func fieldMapping(metric telegraf.Metric, mapping *mapping, newFields map[string
 		if adjustedValue, isString := adjustValue(f.Value).(string); isString {
 			if mappedValue, isMappedValuePresent := mapping.mapValue(adjustedValue); isMappedValuePresent {
 				newFields[mapping.getDestination(f.Key)] = mappedValue
+				continue
 			}
 		}
+		if mapping.UnmappedTag != "" {
+			unmapped[mapping.UnmappedTag] = append(unmapped[mapping.UnmappedTag], f.Key)
+		}
 	}
 }
 
-func tagMapping(metric telegraf.Metric, mapping *mapping, newTags map[string]string) {
+func tagMapping(metric telegraf.Metric, mapping *mapping, newTags map[string]string, unmapped map[string][]string) {
 	tags := metric.TagList()
 	for _, t := range tags {
 		if !mapping.tagFilter.Match(t.Key) {
@@ -122,6 +159,10 @@ func tagMapping(metric telegraf.Metric, mapping *mapping, newTags map[string]str
 			default:
 				newTags[mapping.getDestination(t.Key)] = fmt.Sprintf("%v", val)
 			}
+			continue
+		}
+		if mapping.UnmappedTag != "" {
+			unmapped[mapping.UnmappedTag] = append(unmapped[mapping.UnmappedTag], t.Key)
 		}
 	}
 }
@@ -145,12 +186,52 @@ func (mapping *mapping) mapValue(original string) (interface{}, bool) {
 	if mapped, found := mapping.ValueMappings[original]; found {
 		return mapped, true
 	}
+	if len(mapping.RangeMappings) > 0 {
+		if v, err := strconv.ParseFloat(original, 64); err == nil {
+			for _, r := range mapping.RangeMappings {
+				if r.contains(v) {
+					return r.Value, true
+				}
+			}
+		}
+	}
 	if mapping.Default != nil {
 		return mapping.Default, true
 	}
 	return original, false
 }
 
+func (r *rangeMapping) init() error {
+	if r.Value == nil {
+		return errors.New("value required")
+	}
+
+	r.min, r.max = math.Inf(-1), math.Inf(1)
+	if r.Min != nil {
+		v, err := internal.ToFloat64(r.Min)
+		if err != nil {
+			return fmt.Errorf("invalid minimum: %w", err)
+		}
+		r.min = v
+	}
+	if r.Max != nil {
+		v, err := internal.ToFloat64(r.Max)
+		if err != nil {
+			return fmt.Errorf("invalid maximum: %w", err)
+		}
+		r.max = v
+	}
+	if r.min > r.max {
+		return errors.New("minimum must not exceed the maximum")
+	}
+
+	return nil
+}
+
+func (r *rangeMapping) contains(v float64) bool {
+	return v >= r.min && v <= r.max
+}
+
 func (mapping *mapping) getDestination(defaultDest string) string {
 	if mapping.Dest != "" {
 		return mapping.Dest
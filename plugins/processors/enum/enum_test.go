@@ -7,7 +7,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
 )
 
 func createTestMetric() telegraf.Metric {
@@ -295,3 +297,94 @@ func TestTracking(t *testing.T) {
 		return delivered
 	}, time.Second, 100*time.Millisecond, "no metrics delivered")
 }
+
+func TestRangeMappings(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields: []string{"*_value"},
+		ValueMappings: map[string]interface{}{
+			"500": "exact",
+		},
+		RangeMappings: []*rangeMapping{
+			{Max: int64(199), Value: int64(1)},
+			{Min: int64(200), Max: 200.0, Value: int64(2)},
+			{Min: int64(200), Value: int64(3)},
+		},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+	assertFieldValue(t, int64(2), "int_value", fields)
+	assertFieldValue(t, "exact", "uint_value", fields)
+	assertFieldValue(t, int64(1), "float_value", fields)
+	assertFieldValue(t, "test", "string_value", fields)
+	assertFieldValue(t, true, "true_value", fields)
+}
+
+func TestRangeMappingsConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[processors.enum]]
+  [[processors.enum.mapping]]
+    tags = ["code"]
+    [[processors.enum.mapping.range_mappings]]
+      min = 0
+      max = 199
+      value = "low"
+    [[processors.enum.mapping.range_mappings]]
+      min = 199.5
+      value = "high"
+`), config.EmptySourcePath))
+	require.Len(t, cfg.Processors, 1)
+
+	proc := cfg.Processors[0].Processor.(processors.HasUnwrap)
+	mapper := proc.Unwrap().(*Enum)
+	require.NoError(t, mapper.Init())
+
+	for value, expected := range map[string]string{"0": "low", "150.5": "low", "250": "high", "-1": "-1"} {
+		m := metric.New("m1", map[string]string{"code": value}, map[string]interface{}{"value": 1}, time.Now())
+		assertTagValue(t, expected, "code", calculateProcessedTags(*mapper, m))
+	}
+}
+
+func TestRangeMappingsInvalid(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"value"},
+		RangeMappings: []*rangeMapping{{Min: 10, Max: 1, Value: "x"}},
+	}}}
+	require.ErrorContains(t, mapper.Init(), "must not exceed")
+
+	mapper = Enum{Mappings: []*mapping{{
+		Fields:        []string{"value"},
+		RangeMappings: []*rangeMapping{{Min: 10}},
+	}}}
+	require.ErrorContains(t, mapper.Init(), "value required")
+}
+
+func TestUnmappedTag(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{
+		{
+			Fields:        []string{"*_value"},
+			ValueMappings: map[string]interface{}{"test": 1, "true": 2},
+			UnmappedTag:   "unmapped",
+		},
+		{
+			Tags:          []string{"*tag"},
+			ValueMappings: map[string]interface{}{"other": "x"},
+			UnmappedTag:   "unmapped",
+		},
+	}}
+	require.NoError(t, mapper.Init())
+
+	tags := calculateProcessedTags(mapper, createTestMetric())
+	assertTagValue(t, "duplicate_tag,float_value,int_value,tag,uint_value", "unmapped", tags)
+
+	mapper = Enum{Mappings: []*mapping{{
+		Fields:        []string{"string_value"},
+		ValueMappings: map[string]interface{}{"test": 1},
+		UnmappedTag:   "unmapped",
+	}}}
+	require.NoError(t, mapper.Init())
+
+	tags = calculateProcessedTags(mapper, createTestMetric())
+	require.NotContains(t, tags, "unmapped")
+}
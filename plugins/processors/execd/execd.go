@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -22,15 +23,26 @@ import (
 var sampleConfig string
 
 type Execd struct {
-	Command      []string        `toml:"command"`
-	Environment  []string        `toml:"environment"`
-	RestartDelay config.Duration `toml:"restart_delay"`
-	Log          telegraf.Logger `toml:"-"`
+	Command       []string        `toml:"command"`
+	Environment   []string        `toml:"environment"`
+	RestartDelay  config.Duration `toml:"restart_delay"`
+	Framing       string          `toml:"framing"`
+	BatchSize     int             `toml:"batch_size"`
+	BatchInterval config.Duration `toml:"batch_interval"`
+	Log           telegraf.Logger `toml:"-"`
 
 	parser     telegraf.Parser
 	serializer telegraf.Serializer
 	acc        telegraf.Accumulator
 	process    *process.Process
+
+	// State of the protobuf framing
+	mu         sync.Mutex
+	batch      []telegraf.Metric
+	buf        []byte
+	handshaked io.Writer
+	done       chan struct{}
+	wg         sync.WaitGroup
 }
 
 func (*Execd) SampleConfig() string {
@@ -41,6 +53,21 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+
+	switch e.Framing {
+	case "":
+		e.Framing = "line"
+	case "line", "protobuf":
+	default:
+		return fmt.Errorf("invalid framing %q", e.Framing)
+	}
+	if e.BatchSize < 1 {
+		e.BatchSize = 1000
+	}
+	if e.BatchInterval <= 0 {
+		e.BatchInterval = config.Duration(100 * time.Millisecond)
+	}
+
 	return nil
 }
 
@@ -64,6 +91,9 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
+	if e.Framing == "protobuf" {
+		e.process.ReadStdoutFn = e.cmdReadOutProtobuf
+	}
 
 	if err = e.process.Start(); err != nil {
 		// if there was only one argument, and it contained spaces, warn the user
@@ -76,10 +106,30 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("failed to start process %s: %w", e.Command, err)
 	}
 
+	if e.Framing == "protobuf" {
+		e.done = make(chan struct{})
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			e.flushPeriodically()
+		}()
+	}
+
 	return nil
 }
 
 func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	if e.Framing == "protobuf" {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.batch = append(e.batch, m)
+		if len(e.batch) < e.BatchSize {
+			return nil
+		}
+		return e.flush()
+	}
+
 	b, err := e.serializer.Serialize(m)
 	if err != nil {
 		return fmt.Errorf("metric serializing error: %w", err)
@@ -98,9 +148,82 @@ func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
 }
 
 func (e *Execd) Stop() {
+	if e.done != nil {
+		close(e.done)
+		e.wg.Wait()
+
+		e.mu.Lock()
+		if err := e.flush(); err != nil {
+			e.Log.Error(err)
+		}
+		e.mu.Unlock()
+	}
 	e.process.Stop()
 }
 
+func (e *Execd) flushPeriodically() {
+	ticker := time.NewTicker(time.Duration(e.BatchInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.mu.Lock()
+			if err := e.flush(); err != nil {
+				e.Log.Error(err)
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// flush writes the batched metrics as a single frame to the process, the
+// caller must hold the lock
+func (e *Execd) flush() error {
+	if len(e.batch) == 0 {
+		return nil
+	}
+	batch := e.batch
+	e.batch = e.batch[:0]
+
+	if err := e.handshake(); err != nil {
+		for _, m := range batch {
+			m.Drop()
+		}
+		return err
+	}
+
+	e.buf = appendFrame(e.buf[:0], batch)
+	if _, err := e.process.Stdin.Write(e.buf); err != nil {
+		for _, m := range batch {
+			m.Drop()
+		}
+		return fmt.Errorf("error writing to process stdin: %w", err)
+	}
+
+	// See the comment on accepting metrics in Add
+	for _, m := range batch {
+		m.Accept()
+	}
+	return nil
+}
+
+// handshake announces the protobuf framing to a newly (re)started process
+// before sending any frame, the caller must hold the lock
+func (e *Execd) handshake() error {
+	stdin := e.process.Stdin
+	if e.handshaked == stdin {
+		return nil
+	}
+	if err := writeHandshake(stdin); err != nil {
+		return fmt.Errorf("error writing handshake to process stdin: %w", err)
+	}
+	e.handshaked = stdin
+	return nil
+}
+
 func (e *Execd) cmdReadOut(out io.Reader) {
 	// Prefer using the StreamParser when parsing influx format.
 	var parser telegraf.Parser
@@ -163,6 +286,47 @@ func (e *Execd) cmdReadOutStream(out io.Reader) {
 	}
 }
 
+func (e *Execd) cmdReadOutProtobuf(out io.Reader) {
+	// Negotiate the framing as soon as the process is started instead of
+	// waiting for the first batch
+	e.mu.Lock()
+	err := e.handshake()
+	e.mu.Unlock()
+	if err != nil {
+		e.acc.AddError(err)
+		return
+	}
+
+	reader := bufio.NewReader(out)
+	if err := readHandshake(reader); err != nil {
+		e.acc.AddError(err)
+		// Drain the output to not block the process
+		_, _ = io.Copy(io.Discard, reader)
+		return
+	}
+
+	var buf []byte
+	for {
+		frame, err := readFrame(reader, buf)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				e.acc.AddError(fmt.Errorf("reading frame failed: %w", err))
+			}
+			return
+		}
+		buf = frame
+
+		metrics, err := decodeBatch(frame)
+		if err != nil {
+			// Frames are length-prefixed so we can continue with the next one
+			e.acc.AddError(fmt.Errorf("decoding frame failed: %w", err))
+		}
+		for _, m := range metrics {
+			e.acc.AddMetric(m)
+		}
+	}
+}
+
 func (e *Execd) cmdReadErr(out io.Reader) {
 	scanner := bufio.NewScanner(out)
 
@@ -1,9 +1,11 @@
 package execd
 
 import (
+	"bufio"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -389,9 +391,77 @@ func TestTracking(t *testing.T) {
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
 
+func TestInitInvalidFraming(t *testing.T) {
+	plugin := &Execd{
+		Command: []string{"cat"},
+		Framing: "json",
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid framing "json"`)
+}
+
+func TestProtobufFraming(t *testing.T) {
+	// Determine name of the test executable for mocking an external program
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// Setup the plugin
+	plugin := &Execd{
+		Command: []string{
+			exe,
+			"-case", "multiply-protobuf",
+			"-field", "count",
+		},
+		Environment:   []string{"PLUGINS_PROCESSORS_EXECD_MODE=application"},
+		RestartDelay:  config.Duration(5 * time.Second),
+		Framing:       "protobuf",
+		BatchSize:     4,
+		BatchInterval: config.Duration(50 * time.Millisecond),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Setup the input and expected output metrics, the remaining metrics not
+	// filling a complete batch are flushed by the interval
+	now := time.Now()
+	var input []telegraf.Metric
+	var expected []telegraf.Metric
+	for i := 0; i < 10; i++ {
+		m := metric.New(
+			"test",
+			map[string]string{"city": "Toronto"},
+			map[string]interface{}{
+				"population": int64(6000000),
+				"count":      int64(i),
+				"note":       "multi\nline",
+			},
+			now.Add(time.Duration(i)),
+		)
+		input = append(input, m)
+
+		e := m.Copy()
+		e.AddField("count", int64(2*i))
+		expected = append(expected, e)
+	}
+
+	// Perform the test and check the result
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 100*time.Millisecond)
+
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 func TestMain(m *testing.M) {
 	var testcase, field string
-	flag.StringVar(&testcase, "case", "", "test-case to mock [multiply, long]")
+	flag.StringVar(&testcase, "case", "", "test-case to mock [multiply, multiply-protobuf, long]")
 	flag.StringVar(&field, "field", "count", "name of the field to multiply")
 	flag.Parse()
 
@@ -402,6 +472,8 @@ func TestMain(m *testing.M) {
 	switch testcase {
 	case "multiply":
 		os.Exit(runTestCaseMultiply(field))
+	case "multiply-protobuf":
+		os.Exit(runTestCaseMultiplyProtobuf(field))
 	case "long":
 		os.Exit(runTestCaseLong(field))
 	}
@@ -454,6 +526,54 @@ func runTestCaseMultiply(field string) int {
 	}
 }
 
+func runTestCaseMultiplyProtobuf(field string) int {
+	reader := bufio.NewReader(os.Stdin)
+	if err := readHandshake(reader); err != nil {
+		fmt.Fprintf(os.Stderr, "handshake ERR %v\n", err)
+		return 1
+	}
+	if err := writeHandshake(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "handshake ERR %v\n", err)
+		return 1
+	}
+
+	var buf []byte
+	for {
+		frame, err := readFrame(reader, buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0
+			}
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			return 1
+		}
+		buf = frame
+
+		metrics, err := decodeBatch(frame)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "decode ERR %v\n", err)
+			return 1
+		}
+		for _, m := range metrics {
+			c, found := m.GetField(field)
+			if !found {
+				fmt.Fprintf(os.Stderr, "metric has no field %q\n", field)
+				return 1
+			}
+			v, ok := c.(int64)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "%s has an unknown type, it's a %T\n", field, c)
+				return 1
+			}
+			m.AddField(field, v*2)
+		}
+		if _, err := os.Stdout.Write(appendFrame(nil, metrics)); err != nil {
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			return 1
+		}
+	}
+}
+
 func runTestCaseLong(field string) int {
 	parser := influx.NewStreamParser(os.Stdin)
 	serializer := &serializers_influx.Serializer{}
@@ -0,0 +1,312 @@
+package execd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Field numbers of the messages defined in metric.proto
+const (
+	batchMetrics protowire.Number = 1
+
+	metricName      protowire.Number = 1
+	metricTags      protowire.Number = 2
+	metricFields    protowire.Number = 3
+	metricTimestamp protowire.Number = 4
+
+	tagKey   protowire.Number = 1
+	tagValue protowire.Number = 2
+
+	fieldKey    protowire.Number = 1
+	fieldDouble protowire.Number = 2
+	fieldInt    protowire.Number = 3
+	fieldUint   protowire.Number = 4
+	fieldString protowire.Number = 5
+	fieldBool   protowire.Number = 6
+)
+
+const (
+	framingVersion = 1
+	maxFrameSize   = 64 * 1024 * 1024
+)
+
+var framingMagic = []byte("TGPB")
+
+// writeHandshake announces the protobuf framing and its version
+func writeHandshake(w io.Writer) error {
+	_, err := w.Write(append(bytes.Clone(framingMagic), framingVersion))
+	return err
+}
+
+// readHandshake checks that the peer acknowledged the protobuf framing with
+// a supported version
+func readHandshake(r io.Reader) error {
+	buf := make([]byte, len(framingMagic)+1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return fmt.Errorf("reading handshake failed: %w", err)
+	}
+	if !bytes.Equal(buf[:len(framingMagic)], framingMagic) {
+		return fmt.Errorf("invalid handshake %q, does the program support protobuf framing?", buf)
+	}
+	if v := buf[len(framingMagic)]; v != framingVersion {
+		return fmt.Errorf("unsupported framing version %d", v)
+	}
+	return nil
+}
+
+// appendFrame encodes the metrics as length-prefixed MetricBatch message
+func appendFrame(buf []byte, metrics []telegraf.Metric) []byte {
+	var size int
+	for _, m := range metrics {
+		size += protowire.SizeTag(batchMetrics) + protowire.SizeBytes(sizeMetric(m))
+	}
+
+	buf = protowire.AppendVarint(buf, uint64(size))
+	for _, m := range metrics {
+		buf = protowire.AppendTag(buf, batchMetrics, protowire.BytesType)
+		buf = protowire.AppendVarint(buf, uint64(sizeMetric(m)))
+		buf = appendMetric(buf, m)
+	}
+	return buf
+}
+
+func sizeMetric(m telegraf.Metric) int {
+	size := protowire.SizeTag(metricName) + protowire.SizeBytes(len(m.Name()))
+	for _, tag := range m.TagList() {
+		size += protowire.SizeTag(metricTags) + protowire.SizeBytes(sizeTag(tag))
+	}
+	for _, field := range m.FieldList() {
+		size += protowire.SizeTag(metricFields) + protowire.SizeBytes(sizeField(field))
+	}
+	size += protowire.SizeTag(metricTimestamp) + protowire.SizeVarint(uint64(m.Time().UnixNano()))
+	return size
+}
+
+func sizeTag(tag *telegraf.Tag) int {
+	return protowire.SizeTag(tagKey) + protowire.SizeBytes(len(tag.Key)) +
+		protowire.SizeTag(tagValue) + protowire.SizeBytes(len(tag.Value))
+}
+
+func sizeField(field *telegraf.Field) int {
+	size := protowire.SizeTag(fieldKey) + protowire.SizeBytes(len(field.Key))
+	switch v := field.Value.(type) {
+	case float64:
+		size += protowire.SizeTag(fieldDouble) + protowire.SizeFixed64()
+	case int64:
+		size += protowire.SizeTag(fieldInt) + protowire.SizeVarint(uint64(v))
+	case uint64:
+		size += protowire.SizeTag(fieldUint) + protowire.SizeVarint(v)
+	case string:
+		size += protowire.SizeTag(fieldString) + protowire.SizeBytes(len(v))
+	case bool:
+		size += protowire.SizeTag(fieldBool) + protowire.SizeVarint(protowire.EncodeBool(v))
+	}
+	return size
+}
+
+func appendMetric(buf []byte, m telegraf.Metric) []byte {
+	buf = protowire.AppendTag(buf, metricName, protowire.BytesType)
+	buf = protowire.AppendString(buf, m.Name())
+	for _, tag := range m.TagList() {
+		buf = protowire.AppendTag(buf, metricTags, protowire.BytesType)
+		buf = protowire.AppendVarint(buf, uint64(sizeTag(tag)))
+		buf = protowire.AppendTag(buf, tagKey, protowire.BytesType)
+		buf = protowire.AppendString(buf, tag.Key)
+		buf = protowire.AppendTag(buf, tagValue, protowire.BytesType)
+		buf = protowire.AppendString(buf, tag.Value)
+	}
+	for _, field := range m.FieldList() {
+		buf = protowire.AppendTag(buf, metricFields, protowire.BytesType)
+		buf = protowire.AppendVarint(buf, uint64(sizeField(field)))
+		buf = protowire.AppendTag(buf, fieldKey, protowire.BytesType)
+		buf = protowire.AppendString(buf, field.Key)
+		switch v := field.Value.(type) {
+		case float64:
+			buf = protowire.AppendTag(buf, fieldDouble, protowire.Fixed64Type)
+			buf = protowire.AppendFixed64(buf, math.Float64bits(v))
+		case int64:
+			buf = protowire.AppendTag(buf, fieldInt, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(v))
+		case uint64:
+			buf = protowire.AppendTag(buf, fieldUint, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, v)
+		case string:
+			buf = protowire.AppendTag(buf, fieldString, protowire.BytesType)
+			buf = protowire.AppendString(buf, v)
+		case bool:
+			buf = protowire.AppendTag(buf, fieldBool, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, protowire.EncodeBool(v))
+		}
+	}
+	buf = protowire.AppendTag(buf, metricTimestamp, protowire.VarintType)
+	return protowire.AppendVarint(buf, uint64(m.Time().UnixNano()))
+}
+
+// readFrame reads the next length-prefixed message into the given buffer,
+// io.EOF is only returned if the stream ends at a frame boundary
+func readFrame(r *bufio.Reader, buf []byte) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("frame size %d exceeds limit of %d bytes", size, maxFrameSize)
+	}
+	if uint64(cap(buf)) < size {
+		buf = make([]byte, size)
+	}
+	buf = buf[:size]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// wireField is a single field of an encoded protobuf message with the value
+// stored depending on the wire type
+type wireField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	value uint64
+	data  []byte
+}
+
+func walkFields(buf []byte, fn func(wireField) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		f := wireField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(buf)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(buf)
+		case protowire.BytesType:
+			f.data, n = protowire.ConsumeBytes(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeBatch decodes a MetricBatch message, unknown fields are skipped
+func decodeBatch(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	err := walkFields(buf, func(f wireField) error {
+		if f.num != batchMetrics || f.typ != protowire.BytesType {
+			return nil
+		}
+		m, err := decodeMetric(f.data)
+		if err != nil {
+			return fmt.Errorf("decoding metric %d failed: %w", len(metrics)+1, err)
+		}
+		metrics = append(metrics, m)
+		return nil
+	})
+	return metrics, err
+}
+
+func decodeMetric(buf []byte) (telegraf.Metric, error) {
+	m := metric.New("", nil, nil, time.Time{})
+	var timestamp int64
+	err := walkFields(buf, func(f wireField) error {
+		switch {
+		case f.num == metricName && f.typ == protowire.BytesType:
+			m.SetName(string(f.data))
+		case f.num == metricTags && f.typ == protowire.BytesType:
+			var key, value string
+			err := walkFields(f.data, func(f wireField) error {
+				switch {
+				case f.num == tagKey && f.typ == protowire.BytesType:
+					key = string(f.data)
+				case f.num == tagValue && f.typ == protowire.BytesType:
+					value = string(f.data)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			m.AddTag(key, value)
+		case f.num == metricFields && f.typ == protowire.BytesType:
+			key, value, err := decodeField(f.data)
+			if err != nil {
+				return err
+			}
+			m.AddField(key, value)
+		case f.num == metricTimestamp && f.typ == protowire.VarintType:
+			timestamp = int64(f.value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m.Name() == "" {
+		return nil, errors.New("missing metric name")
+	}
+	if len(m.FieldList()) == 0 {
+		return nil, fmt.Errorf("metric %q has no fields", m.Name())
+	}
+	m.SetTime(time.Unix(0, timestamp))
+	return m, nil
+}
+
+func decodeField(buf []byte) (string, interface{}, error) {
+	var key string
+	var value interface{}
+	err := walkFields(buf, func(f wireField) error {
+		switch {
+		case f.num == fieldKey && f.typ == protowire.BytesType:
+			key = string(f.data)
+		case f.num == fieldDouble && f.typ == protowire.Fixed64Type:
+			value = math.Float64frombits(f.value)
+		case f.num == fieldInt && f.typ == protowire.VarintType:
+			value = int64(f.value)
+		case f.num == fieldUint && f.typ == protowire.VarintType:
+			value = f.value
+		case f.num == fieldString && f.typ == protowire.BytesType:
+			value = string(f.data)
+		case f.num == fieldBool && f.typ == protowire.VarintType:
+			value = protowire.DecodeBool(f.value)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if key == "" {
+		return "", nil, errors.New("missing field key")
+	}
+	if value == nil {
+		return "", nil, fmt.Errorf("field %q has no value", key)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,116 @@
+package execd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestProtobufRoundtrip(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "localhost", "binary": "a\x00b\nc"},
+			map[string]interface{}{
+				"float":  3.14,
+				"int":    int64(-42),
+				"uint":   uint64(math.MaxUint64),
+				"string": "line one\nline two",
+				"bool":   true,
+				"false":  false,
+				"zero":   int64(0),
+			},
+			time.Unix(1700000000, 123456789),
+		),
+		metric.New("before_epoch", nil, map[string]interface{}{"value": 1.0}, time.Unix(-10, 0)),
+	}
+
+	// Write two frames with a reused buffer
+	var stream bytes.Buffer
+	var buf []byte
+	for _, m := range input {
+		buf = appendFrame(buf[:0], []telegraf.Metric{m})
+		stream.Write(buf)
+	}
+
+	reader := bufio.NewReader(&stream)
+	var actual []telegraf.Metric
+	for {
+		frame, err := readFrame(reader, nil)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		metrics, err := decodeBatch(frame)
+		require.NoError(t, err)
+		actual = append(actual, metrics...)
+	}
+	testutil.RequireMetricsEqual(t, input, actual)
+}
+
+func TestProtobufTruncatedFrame(t *testing.T) {
+	m := metric.New("test", nil, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	frame := appendFrame(nil, []telegraf.Metric{m})
+
+	_, err := readFrame(bufio.NewReader(bytes.NewReader(frame[:len(frame)-3])), nil)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// A corrupted message is reported when decoding
+	_, err = decodeBatch(frame[1 : len(frame)-3])
+	require.Error(t, err)
+}
+
+func TestProtobufDecodeInvalid(t *testing.T) {
+	m := metric.New("test", nil, nil, time.Unix(0, 0))
+	frame := appendFrame(nil, []telegraf.Metric{m})
+
+	_, err := decodeBatch(frame[1:])
+	require.ErrorContains(t, err, `metric "test" has no fields`)
+}
+
+func TestProtobufHandshake(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeHandshake(&buf))
+	require.NoError(t, readHandshake(&buf))
+
+	err := readHandshake(bytes.NewBufferString("test value=1\n"))
+	require.ErrorContains(t, err, "does the program support protobuf framing?")
+
+	err = readHandshake(bytes.NewBufferString("TGPB\x02"))
+	require.ErrorContains(t, err, "unsupported framing version 2")
+
+	err = readHandshake(bytes.NewBufferString("TG"))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func BenchmarkProtobufFrame(b *testing.B) {
+	batch := make([]telegraf.Metric, 0, 1000)
+	for i := range 1000 {
+		batch = append(batch, metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_user": 42.5, "usage_system": 3.2, "count": int64(i)},
+			time.Unix(1700000000, int64(i)),
+		))
+	}
+
+	var buf []byte
+	for b.Loop() {
+		buf = appendFrame(buf[:0], batch)
+		_, n := protowire.ConsumeVarint(buf)
+		if _, err := decodeBatch(buf[n:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package expression
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Expression struct {
+	NumericType string          `toml:"numeric_type"`
+	OnError     string          `toml:"on_error"`
+	Fields      []*field        `toml:"field"`
+	Log         telegraf.Logger `toml:"-"`
+}
+
+type field struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+
+	program cel.Program
+}
+
+func (*Expression) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Expression) Init() error {
+	switch e.NumericType {
+	case "":
+		e.NumericType = "float"
+	case "float", "native":
+	default:
+		return fmt.Errorf("invalid numeric type %q", e.NumericType)
+	}
+
+	switch e.OnError {
+	case "":
+		e.OnError = "ignore"
+	case "ignore", "warn", "drop":
+	default:
+		return fmt.Errorf("invalid error handling %q", e.OnError)
+	}
+
+	if len(e.Fields) == 0 {
+		return errors.New("no fields defined")
+	}
+
+	// Declare the computation environment equivalent to the metric filter
+	env, err := cel.NewEnv(
+		cel.VariableDecls(
+			decls.NewVariable("name", types.StringType),
+			decls.NewVariable("tags", types.NewMapType(types.StringType, types.StringType)),
+			decls.NewVariable("fields", types.NewMapType(types.StringType, types.DynType)),
+			decls.NewVariable("time", types.TimestampType),
+		),
+		cel.Function(
+			"now",
+			cel.Overload("now", nil, cel.TimestampType),
+			cel.SingletonFunctionBinding(func(_ ...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }),
+		),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	)
+	if err != nil {
+		return fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	for i, f := range e.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("field %d: name required", i+1)
+		}
+		ast, issues := env.Compile(f.Expression)
+		if issues.Err() != nil {
+			return fmt.Errorf("compiling expression for field %q failed: %w", f.Name, issues.Err())
+		}
+		f.program, err = env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+		if err != nil {
+			return fmt.Errorf("creating program for field %q failed: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Expression) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if err := e.apply(m); err != nil {
+			switch e.OnError {
+			case "warn":
+				e.Log.Warnf("Evaluating metric %q failed: %v", m.Name(), err)
+			case "drop":
+				e.Log.Debugf("Dropping metric %q: %v", m.Name(), err)
+				m.Drop()
+				continue
+			default:
+				e.Log.Tracef("Evaluating metric %q failed: %v", m.Name(), err)
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// apply evaluates all expressions and returns the joined errors of the
+// failing ones
+func (e *Expression) apply(m telegraf.Metric) error {
+	fields := m.Fields()
+	if e.NumericType == "float" {
+		for k, v := range fields {
+			fields[k] = toFloat(v)
+		}
+	}
+	vars := map[string]interface{}{
+		"name":   m.Name(),
+		"tags":   m.Tags(),
+		"fields": fields,
+		"time":   m.Time(),
+	}
+
+	var errs []error
+	for _, f := range e.Fields {
+		result, _, err := f.program.Eval(vars)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", f.Name, err))
+			continue
+		}
+
+		var value interface{}
+		switch v := result.Value().(type) {
+		case int64, uint64, float64, bool, string:
+			value = v
+		default:
+			errs = append(errs, fmt.Errorf("field %q: unsupported result type %T", f.Name, v))
+			continue
+		}
+		m.AddField(f.Name, value)
+
+		// Make the field available to the following expressions
+		if e.NumericType == "float" {
+			value = toFloat(value)
+		}
+		fields[f.Name] = value
+	}
+
+	return errors.Join(errs...)
+}
+
+func toFloat(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return value
+}
+
+func init() {
+	processors.Add("expression", func() telegraf.Processor {
+		return &Expression{}
+	})
+}
@@ -0,0 +1,161 @@
+package expression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Expression
+		expected string
+	}{
+		{
+			name:     "no fields",
+			plugin:   &Expression{},
+			expected: "no fields defined",
+		},
+		{
+			name:     "invalid numeric type",
+			plugin:   &Expression{NumericType: "decimal"},
+			expected: `invalid numeric type "decimal"`,
+		},
+		{
+			name:     "invalid error handling",
+			plugin:   &Expression{OnError: "fail"},
+			expected: `invalid error handling "fail"`,
+		},
+		{
+			name:     "missing name",
+			plugin:   &Expression{Fields: []*field{{Expression: "1"}}},
+			expected: "field 1: name required",
+		},
+		{
+			name:     "invalid expression",
+			plugin:   &Expression{Fields: []*field{{Name: "foo", Expression: "fields.a +"}}},
+			expected: `compiling expression for field "foo" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	tests := []struct {
+		name        string
+		numericType string
+		fields      []*field
+		input       telegraf.Metric
+		expected    telegraf.Metric
+	}{
+		{
+			name: "float arithmetic",
+			fields: []*field{
+				{Name: "usage_percent", Expression: "fields.used / fields.total * 100.0"},
+				{Name: "critical", Expression: "fields.usage_percent > 90.0 && tags.path == '/'"},
+			},
+			input: metric.New(
+				"disk",
+				map[string]string{"path": "/"},
+				map[string]interface{}{"used": int64(950), "total": uint64(1000)},
+				now,
+			),
+			expected: metric.New(
+				"disk",
+				map[string]string{"path": "/"},
+				map[string]interface{}{"used": int64(950), "total": uint64(1000), "usage_percent": float64(95), "critical": true},
+				now,
+			),
+		},
+		{
+			name:        "native types",
+			numericType: "native",
+			fields: []*field{
+				{Name: "free", Expression: "fields.total - fields.used"},
+				{Name: "ratio", Expression: "double(fields.used) / double(fields.total)"},
+				{Name: "label", Expression: "name + ':' + tags.path"},
+			},
+			input: metric.New(
+				"disk",
+				map[string]string{"path": "/data"},
+				map[string]interface{}{"used": int64(250), "total": int64(1000)},
+				now,
+			),
+			expected: metric.New(
+				"disk",
+				map[string]string{"path": "/data"},
+				map[string]interface{}{"used": int64(250), "total": int64(1000), "free": int64(750), "ratio": 0.25, "label": "disk:/data"},
+				now,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Expression{
+				NumericType: tt.numericType,
+				Fields:      tt.fields,
+				Log:         testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	newMetrics := func() []telegraf.Metric {
+		return []telegraf.Metric{
+			metric.New("disk", map[string]string{}, map[string]interface{}{"used": 1.0, "total": 2.0}, now),
+			metric.New("disk", map[string]string{}, map[string]interface{}{"used": 1.0}, now),
+		}
+	}
+	fields := []*field{
+		{Name: "ratio", Expression: "fields.used / fields.total"},
+		{Name: "doubled", Expression: "fields.used * 2.0"},
+	}
+
+	for _, mode := range []string{"ignore", "warn"} {
+		t.Run(mode, func(t *testing.T) {
+			plugin := &Expression{OnError: mode, Fields: fields, Log: testutil.Logger{}}
+			require.NoError(t, plugin.Init())
+
+			expected := []telegraf.Metric{
+				metric.New("disk", map[string]string{}, map[string]interface{}{"used": 1.0, "total": 2.0, "ratio": 0.5, "doubled": 2.0}, now),
+				metric.New("disk", map[string]string{}, map[string]interface{}{"used": 1.0, "doubled": 2.0}, now),
+			}
+			testutil.RequireMetricsEqual(t, expected, plugin.Apply(newMetrics()...))
+		})
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		plugin := &Expression{OnError: "drop", Fields: fields, Log: testutil.Logger{}}
+		require.NoError(t, plugin.Init())
+
+		var delivered int
+		input := make([]telegraf.Metric, 0, 2)
+		for _, m := range newMetrics() {
+			tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
+			input = append(input, tm)
+		}
+
+		actual := plugin.Apply(input...)
+		require.Len(t, actual, 1)
+		require.Equal(t, 1, delivered)
+	})
+}
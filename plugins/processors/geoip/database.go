@@ -0,0 +1,96 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record contains the information of the City, Country and ASN databases
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Continent struct {
+		Code  string            `maxminddb:"code"`
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude       *float64 `maxminddb:"latitude"`
+		Longitude      *float64 `maxminddb:"longitude"`
+		AccuracyRadius uint16   `maxminddb:"accuracy_radius"`
+		TimeZone       string   `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// database is a memory-mapped mmdb file reloaded on changes of the file
+type database struct {
+	path    string
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+func openDatabase(path string) (*database, error) {
+	db := &database{path: path}
+	if _, err := db.reload(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// reload opens the database file again if its modification time or size
+// changed and returns true in this case. The current database is kept on
+// errors.
+func (db *database) reload() (bool, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return false, err
+	}
+	if db.reader != nil && info.ModTime().Equal(db.modTime) && info.Size() == db.size {
+		return false, nil
+	}
+
+	reader, err := maxminddb.Open(db.path)
+	if err != nil {
+		return false, fmt.Errorf("opening database %q failed: %w", db.path, err)
+	}
+	if db.reader != nil {
+		db.reader.Close()
+	}
+	db.reader = reader
+	db.modTime = info.ModTime()
+	db.size = info.Size()
+
+	return true, nil
+}
+
+// lookup decodes the information for the given address into the record and
+// returns false if the address is not contained in the database
+func (db *database) lookup(ip net.IP, r *record) (bool, error) {
+	_, found, err := db.reader.LookupNetwork(ip, r)
+	return found, err
+}
+
+func (db *database) close() {
+	if db.reader != nil {
+		db.reader.Close()
+		db.reader = nil
+	}
+}
@@ -0,0 +1,258 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package geoip
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// properties contains the available information of an address
+var properties = []string{
+	"asn",
+	"as_org",
+	"city",
+	"continent",
+	"continent_code",
+	"country",
+	"country_code",
+	"latitude",
+	"longitude",
+	"accuracy_radius",
+	"postal_code",
+	"subdivision",
+	"subdivision_code",
+	"time_zone",
+}
+
+type GeoIP struct {
+	Database       string          `toml:"database"`
+	ASNDatabase    string          `toml:"asn_database"`
+	ReloadInterval config.Duration `toml:"reload_interval"`
+	Language       string          `toml:"language"`
+	Tags           []string        `toml:"tags"`
+	Fields         []string        `toml:"fields"`
+	Lookups        []lookup        `toml:"lookup"`
+	Log            telegraf.Logger `toml:"-"`
+
+	databases  []*database
+	lastReload time.Time
+}
+
+type lookup struct {
+	Tag    string  `toml:"tag"`
+	Field  string  `toml:"field"`
+	Prefix *string `toml:"prefix"`
+}
+
+func (*GeoIP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GeoIP) Init() error {
+	if g.Database == "" {
+		return errors.New("database required")
+	}
+	if len(g.Lookups) == 0 {
+		return errors.New("no lookups defined")
+	}
+	for i, l := range g.Lookups {
+		if (l.Tag == "") == (l.Field == "") {
+			return fmt.Errorf("lookup %d: exactly one of tag or field required", i+1)
+		}
+		if l.Prefix == nil {
+			prefix := "geoip_"
+			g.Lookups[i].Prefix = &prefix
+		}
+	}
+
+	if g.Language == "" {
+		g.Language = "en"
+	}
+	if g.Tags == nil && g.Fields == nil {
+		g.Tags = []string{"country_code", "city"}
+		g.Fields = []string{"latitude", "longitude"}
+	}
+	for _, p := range append(slices.Clone(g.Tags), g.Fields...) {
+		if !slices.Contains(properties, p) {
+			return fmt.Errorf("unknown property %q", p)
+		}
+	}
+
+	return nil
+}
+
+func (g *GeoIP) Start(telegraf.Accumulator) error {
+	for _, path := range []string{g.Database, g.ASNDatabase} {
+		if path == "" {
+			continue
+		}
+		db, err := openDatabase(path)
+		if err != nil {
+			g.Stop()
+			return err
+		}
+		g.databases = append(g.databases, db)
+	}
+	g.lastReload = time.Now()
+
+	return nil
+}
+
+func (g *GeoIP) Stop() {
+	for _, db := range g.databases {
+		db.close()
+	}
+	g.databases = nil
+}
+
+func (g *GeoIP) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	g.reload()
+
+	for _, l := range g.Lookups {
+		g.enrich(m, l)
+	}
+	acc.AddMetric(m)
+
+	return nil
+}
+
+// reload checks the database files for changes at most once per reload
+// interval and reopens the changed ones
+func (g *GeoIP) reload() {
+	if g.ReloadInterval <= 0 || time.Since(g.lastReload) < time.Duration(g.ReloadInterval) {
+		return
+	}
+	g.lastReload = time.Now()
+
+	for _, db := range g.databases {
+		reloaded, err := db.reload()
+		if err != nil {
+			g.Log.Errorf("Reloading database failed, keeping the current one: %v", err)
+			continue
+		}
+		if reloaded {
+			g.Log.Infof("Reloaded changed database %q", db.path)
+		}
+	}
+}
+
+func (g *GeoIP) enrich(m telegraf.Metric, l lookup) {
+	var address string
+	if l.Tag != "" {
+		v, found := m.GetTag(l.Tag)
+		if !found {
+			return
+		}
+		address = v
+	} else {
+		v, found := m.GetField(l.Field)
+		if !found {
+			return
+		}
+		s, ok := v.(string)
+		if !ok {
+			g.Log.Debugf("Field %q of metric %q is not a string", l.Field, m.Name())
+			return
+		}
+		address = s
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		g.Log.Debugf("Invalid IP address %q in metric %q", address, m.Name())
+		return
+	}
+
+	var r record
+	var found bool
+	for _, db := range g.databases {
+		ok, err := db.lookup(ip, &r)
+		if err != nil {
+			g.Log.Errorf("Looking up %q in %q failed: %v", address, db.path, err)
+			continue
+		}
+		found = found || ok
+	}
+	if !found {
+		return
+	}
+
+	values := g.properties(&r)
+	for _, name := range g.Tags {
+		if v, ok := values[name]; ok {
+			m.AddTag(*l.Prefix+name, toString(v))
+		}
+	}
+	for _, name := range g.Fields {
+		if v, ok := values[name]; ok {
+			m.AddField(*l.Prefix+name, v)
+		}
+	}
+}
+
+// properties returns the non-empty information of the record
+func (g *GeoIP) properties(r *record) map[string]interface{} {
+	values := make(map[string]interface{}, len(properties))
+	setString := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	if r.AutonomousSystemNumber > 0 {
+		values["asn"] = int64(r.AutonomousSystemNumber)
+	}
+	setString("as_org", r.AutonomousSystemOrganization)
+	setString("city", r.City.Names[g.Language])
+	setString("continent", r.Continent.Names[g.Language])
+	setString("continent_code", r.Continent.Code)
+	setString("country", r.Country.Names[g.Language])
+	setString("country_code", r.Country.ISOCode)
+	if r.Location.Latitude != nil && r.Location.Longitude != nil {
+		values["latitude"] = *r.Location.Latitude
+		values["longitude"] = *r.Location.Longitude
+	}
+	if r.Location.AccuracyRadius > 0 {
+		values["accuracy_radius"] = int64(r.Location.AccuracyRadius)
+	}
+	setString("postal_code", r.Postal.Code)
+	if len(r.Subdivisions) > 0 {
+		// Use the most specific subdivision
+		s := r.Subdivisions[len(r.Subdivisions)-1]
+		setString("subdivision", s.Names[g.Language])
+		setString("subdivision_code", s.ISOCode)
+	}
+	setString("time_zone", r.Location.TimeZone)
+
+	return values
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func init() {
+	processors.AddStreaming("geoip", func() telegraf.StreamingProcessor {
+		return &GeoIP{}
+	})
+}
@@ -0,0 +1,191 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GeoIP
+		expected string
+	}{
+		{
+			name:     "no database",
+			plugin:   &GeoIP{Lookups: []lookup{{Tag: "ip"}}},
+			expected: "database required",
+		},
+		{
+			name:     "no lookups",
+			plugin:   &GeoIP{Database: "testdata/city.mmdb"},
+			expected: "no lookups defined",
+		},
+		{
+			name:     "tag and field",
+			plugin:   &GeoIP{Database: "testdata/city.mmdb", Lookups: []lookup{{Tag: "ip", Field: "ip"}}},
+			expected: "exactly one of tag or field required",
+		},
+		{
+			name: "unknown property",
+			plugin: &GeoIP{
+				Database: "testdata/city.mmdb",
+				Lookups:  []lookup{{Tag: "ip"}},
+				Tags:     []string{"region"},
+			},
+			expected: `unknown property "region"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStartFail(t *testing.T) {
+	plugin := &GeoIP{
+		Database: "testdata/missing.mmdb",
+		Lookups:  []lookup{{Tag: "ip"}},
+	}
+	require.NoError(t, plugin.Init())
+	require.Error(t, plugin.Start(nil))
+}
+
+func TestLookup(t *testing.T) {
+	prefix := "src_"
+	plugin := &GeoIP{
+		Database:    "testdata/city.mmdb",
+		ASNDatabase: "testdata/asn.mmdb",
+		Language:    "de",
+		Tags:        []string{"country_code", "country", "subdivision_code", "city", "asn"},
+		Fields:      []string{"latitude", "longitude", "accuracy_radius", "as_org"},
+		Lookups: []lookup{
+			{Tag: "source", Prefix: &prefix},
+			{Field: "destination"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"flow",
+			map[string]string{"source": "81.2.69.160"},
+			map[string]interface{}{"destination": "2001:db8::1", "bytes": int64(42)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"flow",
+			map[string]string{"source": "10.0.0.1"},
+			map[string]interface{}{"destination": "invalid", "bytes": int64(23)},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"flow",
+			map[string]string{
+				"source":               "81.2.69.160",
+				"src_country_code":     "GB",
+				"src_country":          "Vereinigtes Königreich",
+				"src_subdivision_code": "ENG",
+				"src_city":             "London",
+				"src_asn":              "20712",
+				"geoip_country_code":   "AU",
+				"geoip_country":        "Australien",
+			},
+			map[string]interface{}{
+				"destination":           "2001:db8::1",
+				"bytes":                 int64(42),
+				"src_latitude":          51.5142,
+				"src_longitude":         -0.0931,
+				"src_accuracy_radius":   int64(10),
+				"src_as_org":            "Andrews & Arnold Ltd",
+				"geoip_latitude":        -33.494,
+				"geoip_longitude":       143.2104,
+				"geoip_accuracy_radius": int64(1000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"flow",
+			map[string]string{"source": "10.0.0.1"},
+			map[string]interface{}{"destination": "invalid", "bytes": int64(23)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestReload(t *testing.T) {
+	// Start with a database not containing the geographical information
+	dir := t.TempDir()
+	path := filepath.Join(dir, "geoip.mmdb")
+	copyFile(t, "testdata/asn.mmdb", path)
+
+	plugin := &GeoIP{
+		Database:       path,
+		ReloadInterval: config.Duration(time.Millisecond),
+		Tags:           []string{"country_code"},
+		Lookups:        []lookup{{Tag: "ip"}},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	m := metric.New("test", map[string]string{"ip": "81.2.69.160"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m.Copy(), &acc))
+	require.False(t, acc.HasTag("test", "geoip_country_code"))
+
+	// Replace the database and make sure the change is detected even within
+	// the resolution of the modification time
+	copyFile(t, "testdata/city.mmdb", path)
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	time.Sleep(5 * time.Millisecond)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Add(m.Copy(), &acc))
+	tag, found := acc.Metrics[0].Tags["geoip_country_code"]
+	require.True(t, found)
+	require.Equal(t, "GB", tag)
+
+	// Failing reloads keep the current database
+	require.NoError(t, os.WriteFile(path+".tmp", []byte("garbage"), 0600))
+	require.NoError(t, os.Rename(path+".tmp", path))
+	time.Sleep(5 * time.Millisecond)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Add(m.Copy(), &acc))
+	require.Equal(t, "GB", acc.Metrics[0].Tags["geoip_country_code"])
+}
+
+// copyFile atomically replaces the destination as the database is memory
+// mapped and must not be modified in place
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+
+	buf, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst+".tmp", buf, 0600))
+	require.NoError(t, os.Rename(dst+".tmp", dst))
+}
@@ -0,0 +1,76 @@
+package http_lookup
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// cache keeps the tags of a key until its expiry. Concurrent lookups of the
+// same key wait for the single request in flight.
+type cache struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	lastCleanup time.Time
+	entries     map[string]*cacheEntry
+	sync.Mutex
+}
+
+type cacheEntry struct {
+	done    chan struct{}
+	tags    map[string]string
+	expires time.Time
+}
+
+func newCache(ttl, negativeTTL time.Duration) *cache {
+	return &cache{
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		lastCleanup: time.Now(),
+		entries:     make(map[string]*cacheEntry),
+	}
+}
+
+// get returns the tags for the key, calling the resolve function if the key
+// is not cached. A nil result of the function is cached as negative entry.
+func (c *cache) get(key string, resolve func(key string) map[string]string) map[string]string {
+	now := time.Now()
+
+	c.Lock()
+	c.cleanup(now)
+	if e, found := c.entries[key]; found && (e.expires.IsZero() || now.Before(e.expires)) {
+		c.Unlock()
+		// Wait for the request in flight if any
+		<-e.done
+		return e.tags
+	}
+	e := &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.Unlock()
+
+	tags := resolve(key)
+
+	c.Lock()
+	e.tags = tags
+	if tags != nil {
+		e.expires = time.Now().Add(c.ttl)
+	} else {
+		e.expires = time.Now().Add(c.negativeTTL)
+	}
+	c.Unlock()
+	close(e.done)
+
+	return tags
+}
+
+// cleanup removes expired entries at most once per TTL; the lock must be held
+func (c *cache) cleanup(now time.Time) {
+	if now.Sub(c.lastCleanup) < min(c.ttl, c.negativeTTL) {
+		return
+	}
+	c.lastCleanup = now
+	maps.DeleteFunc(c.entries, func(_ string, e *cacheEntry) bool {
+		return !e.expires.IsZero() && now.After(e.expires)
+	})
+}
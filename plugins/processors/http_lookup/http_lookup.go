@@ -0,0 +1,192 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package http_lookup
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/parallel"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const keyPlaceholder = "{key}"
+
+type HTTPLookup struct {
+	Tag                string                    `toml:"tag"`
+	URL                string                    `toml:"url"`
+	Headers            map[string]*config.Secret `toml:"headers"`
+	Tags               map[string]string         `toml:"tags"`
+	CacheTTL           config.Duration           `toml:"cache_ttl"`
+	NegativeCacheTTL   config.Duration           `toml:"negative_cache_ttl"`
+	MaxParallelLookups int                       `toml:"max_parallel_lookups"`
+	Ordered            bool                      `toml:"ordered"`
+	Log                telegraf.Logger           `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client   *http.Client
+	cache    *cache
+	parallel parallel.Parallel
+}
+
+func (*HTTPLookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HTTPLookup) Init() error {
+	if h.Tag == "" {
+		return errors.New("tag required")
+	}
+	if h.URL == "" {
+		return errors.New("url required")
+	}
+	if !strings.Contains(h.URL, keyPlaceholder) {
+		return fmt.Errorf("url does not contain the %q placeholder", keyPlaceholder)
+	}
+	if len(h.Tags) == 0 {
+		return errors.New("no tags defined")
+	}
+	for name, path := range h.Tags {
+		if path == "" {
+			return fmt.Errorf("empty path for tag %q", name)
+		}
+	}
+	if h.MaxParallelLookups <= 0 {
+		h.MaxParallelLookups = 10
+	}
+
+	client, err := h.HTTPClientConfig.CreateClient(context.Background(), h.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	h.client = client
+
+	return nil
+}
+
+func (h *HTTPLookup) Start(acc telegraf.Accumulator) error {
+	h.cache = newCache(time.Duration(h.CacheTTL), time.Duration(h.NegativeCacheTTL))
+	if h.Ordered {
+		h.parallel = parallel.NewOrdered(acc, h.asyncAdd, 10000, h.MaxParallelLookups)
+	} else {
+		h.parallel = parallel.NewUnordered(acc, h.asyncAdd, h.MaxParallelLookups)
+	}
+	return nil
+}
+
+func (h *HTTPLookup) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	h.parallel.Enqueue(m)
+	return nil
+}
+
+func (h *HTTPLookup) Stop() {
+	h.parallel.Stop()
+	h.client.CloseIdleConnections()
+}
+
+func (h *HTTPLookup) asyncAdd(m telegraf.Metric) []telegraf.Metric {
+	key, found := m.GetTag(h.Tag)
+	if !found || key == "" {
+		return []telegraf.Metric{m}
+	}
+
+	for name, value := range h.cache.get(key, h.lookup) {
+		m.AddTag(name, value)
+	}
+	return []telegraf.Metric{m}
+}
+
+// lookup queries the API for the given key and returns the mapped tags or
+// nil if the key is unknown or the request failed
+func (h *HTTPLookup) lookup(key string) map[string]string {
+	body, err := h.request(key)
+	if err != nil {
+		h.Log.Errorf("Looking up %q failed: %v", key, err)
+		return nil
+	}
+	if body == nil {
+		h.Log.Debugf("Key %q not found", key)
+		return nil
+	}
+	if !gjson.ValidBytes(body) {
+		h.Log.Errorf("Looking up %q failed: invalid JSON response", key)
+		return nil
+	}
+
+	tags := make(map[string]string, len(h.Tags))
+	for name, path := range h.Tags {
+		result := gjson.GetBytes(body, path)
+		if !result.Exists() || result.Type == gjson.Null {
+			continue
+		}
+		tags[name] = result.String()
+	}
+	return tags
+}
+
+// request returns the response body for the key or nil if the key is unknown
+func (h *HTTPLookup) request(key string) ([]byte, error) {
+	// Escape the key for use in both path and query
+	escaped := strings.ReplaceAll(url.QueryEscape(key), "+", "%20")
+	address := strings.ReplaceAll(h.URL, keyPlaceholder, escaped)
+
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range h.Headers {
+		secret, err := v.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting header %q failed: %w", k, err)
+		}
+		if strings.EqualFold(k, "host") {
+			req.Host = secret.String()
+		} else {
+			req.Header.Set(k, secret.String())
+		}
+		secret.Destroy()
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		//nolint:errcheck // LimitReader returns io.EOF and we're not interested in read errors.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("received status %s: %q", resp.Status, body)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func init() {
+	processors.AddStreaming("http_lookup", func() telegraf.StreamingProcessor {
+		return &HTTPLookup{
+			CacheTTL:           config.Duration(time.Hour),
+			NegativeCacheTTL:   config.Duration(5 * time.Minute),
+			MaxParallelLookups: 10,
+		}
+	})
+}
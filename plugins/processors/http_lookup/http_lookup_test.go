@@ -0,0 +1,187 @@
+package http_lookup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *HTTPLookup
+		expected string
+	}{
+		{
+			name:     "no tag",
+			plugin:   &HTTPLookup{URL: "http://localhost/{key}", Tags: map[string]string{"a": "a"}},
+			expected: "tag required",
+		},
+		{
+			name:     "no url",
+			plugin:   &HTTPLookup{Tag: "id", Tags: map[string]string{"a": "a"}},
+			expected: "url required",
+		},
+		{
+			name:     "no placeholder",
+			plugin:   &HTTPLookup{Tag: "id", URL: "http://localhost/", Tags: map[string]string{"a": "a"}},
+			expected: `url does not contain the "{key}" placeholder`,
+		},
+		{
+			name:     "no tags",
+			plugin:   &HTTPLookup{Tag: "id", URL: "http://localhost/{key}"},
+			expected: "no tags defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/devices/sensor%201":
+			if _, err := w.Write([]byte(`{"site": {"name": "Berlin", "rack": 12}, "owner": "ops", "comment": null}`)); err != nil {
+				t.Error(err)
+			}
+		case "/devices/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	token := config.NewSecret([]byte("Bearer secret"))
+	plugin := &HTTPLookup{
+		Tag:     "device",
+		URL:     server.URL + "/devices/{key}",
+		Headers: map[string]*config.Secret{"Authorization": &token},
+		Tags: map[string]string{
+			"location": "site.name",
+			"rack":     "site.rack",
+			"owner":    "owner",
+			"comment":  "comment",
+		},
+		CacheTTL:           config.Duration(time.Hour),
+		NegativeCacheTTL:   config.Duration(time.Hour),
+		MaxParallelLookups: 2,
+		Ordered:            true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("temp", map[string]string{"device": "sensor 1"}, map[string]interface{}{"value": 21.5}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "unknown"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "broken"}, map[string]interface{}{"value": 19.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{}, map[string]interface{}{"value": 18.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "sensor 1"}, map[string]interface{}{"value": 21.7}, time.Unix(10, 0)),
+		metric.New("temp", map[string]string{"device": "unknown"}, map[string]interface{}{"value": 20.1}, time.Unix(10, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"temp",
+			map[string]string{"device": "sensor 1", "location": "Berlin", "rack": "12", "owner": "ops"},
+			map[string]interface{}{"value": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New("temp", map[string]string{"device": "unknown"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "broken"}, map[string]interface{}{"value": 19.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{}, map[string]interface{}{"value": 18.0}, time.Unix(0, 0)),
+		metric.New(
+			"temp",
+			map[string]string{"device": "sensor 1", "location": "Berlin", "rack": "12", "owner": "ops"},
+			map[string]interface{}{"value": 21.7},
+			time.Unix(10, 0),
+		),
+		metric.New("temp", map[string]string{"device": "unknown"}, map[string]interface{}{"value": 20.1}, time.Unix(10, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Each key must only be requested once including the negative entries
+	require.Equal(t, int64(3), requests.Load())
+}
+
+func TestCacheSingleRequest(t *testing.T) {
+	c := newCache(time.Hour, time.Minute)
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	resolve := func(string) map[string]string {
+		calls.Add(1)
+		<-release
+		return map[string]string{"a": "b"}
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.Equal(t, map[string]string{"a": "b"}, c.get("key", resolve))
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := newCache(50*time.Millisecond, 10*time.Millisecond)
+
+	var calls atomic.Int64
+	found := func(string) map[string]string {
+		calls.Add(1)
+		return map[string]string{"a": "b"}
+	}
+	notFound := func(string) map[string]string {
+		calls.Add(1)
+		return nil
+	}
+
+	require.Nil(t, c.get("missing", notFound))
+	require.Nil(t, c.get("missing", notFound))
+	require.NotNil(t, c.get("present", found))
+	require.Equal(t, int64(2), calls.Load())
+
+	// The negative entry expires earlier than the positive one
+	time.Sleep(20 * time.Millisecond)
+	require.Nil(t, c.get("missing", notFound))
+	require.NotNil(t, c.get("present", found))
+	require.Equal(t, int64(3), calls.Load())
+
+	time.Sleep(50 * time.Millisecond)
+	require.NotNil(t, c.get("present", found))
+	require.Equal(t, int64(4), calls.Load())
+}
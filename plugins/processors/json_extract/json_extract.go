@@ -0,0 +1,166 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package json_extract
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JSONExtract struct {
+	Field        string          `toml:"field"`
+	DropOriginal bool            `toml:"drop_original"`
+	Fields       []extraction    `toml:"field_extraction"`
+	Tags         []extraction    `toml:"tag_extraction"`
+	Log          telegraf.Logger `toml:"-"`
+}
+
+type extraction struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
+	Type string `toml:"type"`
+}
+
+func (*JSONExtract) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JSONExtract) Init() error {
+	if j.Field == "" {
+		return errors.New("field required")
+	}
+	if len(j.Fields) == 0 && len(j.Tags) == 0 {
+		return errors.New("no field or tag extraction defined")
+	}
+
+	for i, e := range j.Fields {
+		if e.Name == "" || e.Path == "" {
+			return fmt.Errorf("name and path required for field extraction %d", i+1)
+		}
+		switch e.Type {
+		case "":
+			j.Fields[i].Type = "auto"
+		case "auto", "int", "uint", "float", "bool", "string":
+		default:
+			return fmt.Errorf("invalid type %q for field %q", e.Type, e.Name)
+		}
+	}
+	for i, e := range j.Tags {
+		if e.Name == "" || e.Path == "" {
+			return fmt.Errorf("name and path required for tag extraction %d", i+1)
+		}
+		if e.Type != "" {
+			return fmt.Errorf("type not supported for tag %q", e.Name)
+		}
+	}
+
+	return nil
+}
+
+func (j *JSONExtract) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		raw, found := m.GetField(j.Field)
+		if !found {
+			continue
+		}
+
+		var doc string
+		switch v := raw.(type) {
+		case string:
+			doc = v
+		case []byte:
+			doc = string(v)
+		default:
+			j.Log.Debugf("Ignoring non-string field %q of metric %q", j.Field, m.Name())
+			continue
+		}
+		if !gjson.Valid(doc) {
+			j.Log.Debugf("Ignoring invalid JSON in field %q of metric %q", j.Field, m.Name())
+			continue
+		}
+
+		// Remove the source field first to allow extractions using the same name
+		if j.DropOriginal {
+			m.RemoveField(j.Field)
+		}
+
+		for _, e := range j.Tags {
+			result := gjson.Get(doc, e.Path)
+			if !result.Exists() || result.Type == gjson.Null {
+				continue
+			}
+			m.AddTag(e.Name, result.String())
+		}
+
+		for _, e := range j.Fields {
+			result := gjson.Get(doc, e.Path)
+			if !result.Exists() || result.Type == gjson.Null {
+				continue
+			}
+			v, err := convert(result, e.Type)
+			if err != nil {
+				j.Log.Errorf("Converting field %q of metric %q failed: %v", e.Name, m.Name(), err)
+				continue
+			}
+			m.AddField(e.Name, v)
+		}
+	}
+	return in
+}
+
+// convert returns the value of the result in the given type, objects and
+// arrays are returned as raw JSON strings
+func convert(result gjson.Result, typ string) (interface{}, error) {
+	var v interface{}
+	switch result.Type {
+	case gjson.True, gjson.False:
+		v = result.Bool()
+	case gjson.Number:
+		v = result.Num
+	case gjson.String:
+		v = result.Str
+	default:
+		v = result.Raw
+	}
+
+	switch typ {
+	case "int":
+		// Parse integers directly to avoid losing precision for large values
+		if i, err := strconv.ParseInt(result.Raw, 10, 64); err == nil {
+			return i, nil
+		}
+		return internal.ToInt64(v)
+	case "uint":
+		if u, err := strconv.ParseUint(result.Raw, 10, 64); err == nil {
+			return u, nil
+		}
+		return internal.ToUint64(v)
+	case "float":
+		return internal.ToFloat64(v)
+	case "bool":
+		return internal.ToBool(v)
+	case "string":
+		if result.Type == gjson.Number {
+			// Keep the original representation of the number
+			return result.Raw, nil
+		}
+		return internal.ToString(v)
+	}
+	return v, nil
+}
+
+func init() {
+	processors.Add("json_extract", func() telegraf.Processor {
+		return &JSONExtract{}
+	})
+}
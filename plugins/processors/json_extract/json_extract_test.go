@@ -0,0 +1,154 @@
+package json_extract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const payload = `{
+	"device": {"id": "s1", "location": null},
+	"readings": {"temperature": 21.5, "counter": 9007199254740993, "values": [1, 2]},
+	"status": {"ok": true, "code": "42"}
+}`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONExtract
+		expected string
+	}{
+		{
+			name:     "no field",
+			plugin:   &JSONExtract{Fields: []extraction{{Name: "a", Path: "a"}}},
+			expected: "field required",
+		},
+		{
+			name:     "no extraction",
+			plugin:   &JSONExtract{Field: "payload"},
+			expected: "no field or tag extraction defined",
+		},
+		{
+			name:     "no path",
+			plugin:   &JSONExtract{Field: "payload", Fields: []extraction{{Name: "a"}}},
+			expected: "name and path required for field extraction 1",
+		},
+		{
+			name:     "invalid type",
+			plugin:   &JSONExtract{Field: "payload", Fields: []extraction{{Name: "a", Path: "a", Type: "time"}}},
+			expected: `invalid type "time" for field "a"`,
+		},
+		{
+			name:     "tag with type",
+			plugin:   &JSONExtract{Field: "payload", Tags: []extraction{{Name: "a", Path: "a", Type: "int"}}},
+			expected: `type not supported for tag "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	plugin := &JSONExtract{
+		Field: "payload",
+		Tags: []extraction{
+			{Name: "device", Path: "device.id"},
+			{Name: "location", Path: "device.location"},
+			{Name: "missing", Path: "device.missing"},
+		},
+		Fields: []extraction{
+			{Name: "temperature", Path: "readings.temperature"},
+			{Name: "counter", Path: "readings.counter", Type: "int"},
+			{Name: "counter_unsigned", Path: "readings.counter", Type: "uint"},
+			{Name: "temperature_int", Path: "readings.temperature", Type: "int"},
+			{Name: "counter_string", Path: "readings.counter", Type: "string"},
+			{Name: "values", Path: "readings.values"},
+			{Name: "first", Path: "readings.values.0", Type: "float"},
+			{Name: "ok", Path: "status.ok"},
+			{Name: "code", Path: "status.code", Type: "int"},
+			{Name: "invalid", Path: "device.id", Type: "int"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("mqtt", map[string]string{"topic": "a"}, map[string]interface{}{"payload": payload}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New(
+			"mqtt",
+			map[string]string{"topic": "a", "device": "s1"},
+			map[string]interface{}{
+				"payload":          payload,
+				"temperature":      float64(21.5),
+				"counter":          int64(9007199254740993),
+				"counter_unsigned": uint64(9007199254740993),
+				"temperature_int":  int64(21),
+				"counter_string":   "9007199254740993",
+				"values":           "[1, 2]",
+				"first":            float64(1),
+				"ok":               true,
+				"code":             int64(42),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestDropOriginal(t *testing.T) {
+	plugin := &JSONExtract{
+		Field:        "payload",
+		DropOriginal: true,
+		Fields:       []extraction{{Name: "payload", Path: "readings.temperature"}},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": payload}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": "not json"}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": int64(42)}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": 21.5}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": "not json"}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": int64(42)}, time.Unix(0, 0)),
+		metric.New("mqtt", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	plugin := &JSONExtract{
+		Field:  "payload",
+		Fields: []extraction{{Name: "temperature", Path: "readings.temperature"}},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+	m := metric.New("mqtt", map[string]string{}, map[string]interface{}{"payload": payload}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(m, notify)
+
+	actual := plugin.Apply(tm)
+	require.Len(t, actual, 1)
+	actual[0].Accept()
+	require.Equal(t, 1, delivered)
+}
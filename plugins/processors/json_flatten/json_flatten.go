@@ -0,0 +1,282 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package json_flatten
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JSONFlatten struct {
+	Field          string          `toml:"field"`
+	DropOriginal   bool            `toml:"drop_original"`
+	Prefix         string          `toml:"prefix"`
+	Separator      string          `toml:"separator"`
+	MaxDepth       int             `toml:"max_depth"`
+	ArrayMode      string          `toml:"array_mode"`
+	ArraySeparator string          `toml:"array_separator"`
+	Log            telegraf.Logger `toml:"-"`
+}
+
+// entry is a flattened field or, for exploded arrays, the index tag
+type entry struct {
+	key   string
+	value interface{}
+	tag   bool
+}
+
+func (*JSONFlatten) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *JSONFlatten) Init() error {
+	if p.Field == "" {
+		return errors.New("field required")
+	}
+	if p.MaxDepth < 0 {
+		return errors.New("max_depth must not be negative")
+	}
+	switch p.ArrayMode {
+	case "":
+		p.ArrayMode = "index"
+	case "index", "join", "explode":
+	default:
+		return fmt.Errorf("invalid array_mode %q", p.ArrayMode)
+	}
+	return nil
+}
+
+func (p *JSONFlatten) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		raw, found := m.GetField(p.Field)
+		if !found {
+			out = append(out, m)
+			continue
+		}
+
+		var doc []byte
+		switch v := raw.(type) {
+		case string:
+			doc = []byte(v)
+		case []byte:
+			doc = v
+		default:
+			p.Log.Debugf("Ignoring non-string field %q of metric %q", p.Field, m.Name())
+			out = append(out, m)
+			continue
+		}
+
+		// Decode numbers as json.Number to keep integers
+		var data interface{}
+		decoder := json.NewDecoder(bytes.NewReader(doc))
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err != nil {
+			p.Log.Debugf("Ignoring invalid JSON in field %q of metric %q: %v", p.Field, m.Name(), err)
+			out = append(out, m)
+			continue
+		}
+		switch data.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			p.Log.Debugf("Ignoring JSON without object or array in field %q of metric %q", p.Field, m.Name())
+			out = append(out, m)
+			continue
+		}
+
+		if p.DropOriginal {
+			m.RemoveField(p.Field)
+		}
+
+		rows := p.flatten(p.Prefix, data, 0)
+		if len(rows) == 1 {
+			apply(m, rows[0])
+			out = append(out, m)
+			continue
+		}
+
+		// Create a copy without tracking information per exploded row
+		for _, row := range rows {
+			e := metric.New(m.Name(), nil, nil, m.Time(), m.Type())
+			for _, t := range m.TagList() {
+				e.AddTag(t.Key, t.Value)
+			}
+			for _, f := range m.FieldList() {
+				e.AddField(f.Key, f.Value)
+			}
+			apply(e, row)
+			out = append(out, e)
+		}
+		m.Accept()
+	}
+	return out
+}
+
+func apply(m telegraf.Metric, row []entry) {
+	for _, e := range row {
+		if e.tag {
+			m.AddTag(e.key, e.value.(string))
+		} else {
+			m.AddField(e.key, e.value)
+		}
+	}
+}
+
+// flatten returns the entries of the given value, every exploded array
+// element results in a separate row of entries
+func (p *JSONFlatten) flatten(key string, v interface{}, depth int) [][]entry {
+	switch v := v.(type) {
+	case nil:
+		return [][]entry{nil}
+	case map[string]interface{}:
+		if p.MaxDepth > 0 && depth >= p.MaxDepth {
+			return p.raw(key, v)
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		rows := [][]entry{nil}
+		for _, k := range keys {
+			rows = product(rows, p.flatten(p.join(key, k), v[k], depth+1))
+		}
+		return rows
+	case []interface{}:
+		if p.MaxDepth > 0 && depth >= p.MaxDepth {
+			return p.raw(key, v)
+		}
+		switch p.ArrayMode {
+		case "join":
+			return [][]entry{{{key: key, value: p.joinElements(v)}}}
+		case "explode":
+			if len(v) == 0 {
+				return [][]entry{nil}
+			}
+			indexTag := p.join(key, "index")
+			var rows [][]entry
+			for i, elem := range v {
+				// Elements are flattened using the key of the array, except
+				// for nested arrays to avoid clashing index tags
+				elemKey := key
+				if _, ok := elem.([]interface{}); ok {
+					elemKey = p.join(key, strconv.Itoa(i))
+				}
+				for _, row := range p.flatten(elemKey, elem, depth+1) {
+					index := entry{key: indexTag, value: strconv.Itoa(i), tag: true}
+					rows = append(rows, append([]entry{index}, row...))
+				}
+			}
+			return rows
+		default:
+			rows := [][]entry{nil}
+			for i, elem := range v {
+				rows = product(rows, p.flatten(p.join(key, strconv.Itoa(i)), elem, depth+1))
+			}
+			return rows
+		}
+	case json.Number:
+		return [][]entry{{{key: key, value: convertNumber(v)}}}
+	default:
+		// Strings and booleans
+		return [][]entry{{{key: key, value: v}}}
+	}
+}
+
+// raw returns the value as JSON string for objects and arrays exceeding the
+// maximum depth
+func (p *JSONFlatten) raw(key string, v interface{}) [][]entry {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		p.Log.Errorf("Encoding value of %q failed: %v", key, err)
+		return [][]entry{nil}
+	}
+	return [][]entry{{{key: key, value: string(buf)}}}
+}
+
+func (p *JSONFlatten) joinElements(elements []interface{}) string {
+	parts := make([]string, 0, len(elements))
+	for _, elem := range elements {
+		switch v := elem.(type) {
+		case nil:
+		case string:
+			parts = append(parts, v)
+		case json.Number:
+			parts = append(parts, v.String())
+		case bool:
+			parts = append(parts, strconv.FormatBool(v))
+		default:
+			buf, err := json.Marshal(v)
+			if err != nil {
+				p.Log.Errorf("Encoding array element failed: %v", err)
+				continue
+			}
+			parts = append(parts, string(buf))
+		}
+	}
+	return strings.Join(parts, p.ArraySeparator)
+}
+
+func (p *JSONFlatten) join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + p.Separator + key
+}
+
+// product returns all combinations of the given rows
+func product(left, right [][]entry) [][]entry {
+	if len(right) == 1 {
+		for i := range left {
+			left[i] = append(left[i], right[0]...)
+		}
+		return left
+	}
+	rows := make([][]entry, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			row := make([]entry, 0, len(l)+len(r))
+			row = append(row, l...)
+			rows = append(rows, append(row, r...))
+		}
+	}
+	return rows
+}
+
+// convertNumber returns integers as int64, or uint64 if exceeding the int64
+// range, and all other numbers as float64
+func convertNumber(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+func init() {
+	processors.Add("json_flatten", func() telegraf.Processor {
+		return &JSONFlatten{
+			Separator:      "_",
+			ArrayMode:      "index",
+			ArraySeparator: ",",
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package json_flatten
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const payload = `{
+	"device": {"id": "s1", "location": null, "online": true},
+	"readings": {"temperature": 21.5, "counter": 9007199254740993, "big": 18446744073709551615},
+	"values": [1, "two", {"three": 3}],
+	"message": "line one\nline two"
+}`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONFlatten
+		expected string
+	}{
+		{
+			name:     "no field",
+			plugin:   &JSONFlatten{},
+			expected: "field required",
+		},
+		{
+			name:     "negative depth",
+			plugin:   &JSONFlatten{Field: "payload", MaxDepth: -1},
+			expected: "max_depth must not be negative",
+		},
+		{
+			name:     "invalid array mode",
+			plugin:   &JSONFlatten{Field: "payload", ArrayMode: "first"},
+			expected: `invalid array_mode "first"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONFlatten
+		expected []telegraf.Metric
+	}{
+		{
+			name: "index",
+			plugin: &JSONFlatten{
+				Field:        "payload",
+				DropOriginal: true,
+				Separator:    "_",
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"source": "mqtt"},
+					map[string]interface{}{
+						"value":                1,
+						"device_id":            "s1",
+						"device_online":        true,
+						"readings_temperature": 21.5,
+						"readings_counter":     int64(9007199254740993),
+						"readings_big":         uint64(18446744073709551615),
+						"values_0":             int64(1),
+						"values_1":             "two",
+						"values_2_three":       int64(3),
+						"message":              "line one\nline two",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "join with prefix",
+			plugin: &JSONFlatten{
+				Field:          "payload",
+				DropOriginal:   true,
+				Prefix:         "json",
+				Separator:      ".",
+				ArrayMode:      "join",
+				ArraySeparator: ";",
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"source": "mqtt"},
+					map[string]interface{}{
+						"value":                     1,
+						"json.device.id":            "s1",
+						"json.device.online":        true,
+						"json.readings.temperature": 21.5,
+						"json.readings.counter":     int64(9007199254740993),
+						"json.readings.big":         uint64(18446744073709551615),
+						"json.values":               `1;two;{"three":3}`,
+						"json.message":              "line one\nline two",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "depth limit",
+			plugin: &JSONFlatten{
+				Field:     "payload",
+				Separator: "_",
+				MaxDepth:  1,
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"source": "mqtt"},
+					map[string]interface{}{
+						"value":    1,
+						"payload":  payload,
+						"device":   `{"id":"s1","location":null,"online":true}`,
+						"readings": `{"big":18446744073709551615,"counter":9007199254740993,"temperature":21.5}`,
+						"values":   `[1,"two",{"three":3}]`,
+						"message":  "line one\nline two",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			input := metric.New(
+				"test",
+				map[string]string{"source": "mqtt"},
+				map[string]interface{}{"value": 1, "payload": payload},
+				time.Unix(0, 0),
+			)
+			actual := tt.plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestExplode(t *testing.T) {
+	plugin := &JSONFlatten{
+		Field:        "payload",
+		DropOriginal: true,
+		Separator:    "_",
+		ArrayMode:    "explode",
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New(
+		"sensors",
+		map[string]string{"site": "berlin"},
+		map[string]interface{}{
+			"payload": `{"gateway": "gw1", "sensors": [{"id": "a", "temp": 20}, {"id": "b", "temp": 21.5}], "matrix": [[1, 2], []]}`,
+		},
+		time.Unix(0, 0),
+	)
+	expected := []telegraf.Metric{
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "0", "matrix_0_index": "0", "sensors_index": "0"},
+			map[string]interface{}{"gateway": "gw1", "matrix_0": int64(1), "sensors_id": "a", "sensors_temp": int64(20)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "0", "matrix_0_index": "0", "sensors_index": "1"},
+			map[string]interface{}{"gateway": "gw1", "matrix_0": int64(1), "sensors_id": "b", "sensors_temp": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "0", "matrix_0_index": "1", "sensors_index": "0"},
+			map[string]interface{}{"gateway": "gw1", "matrix_0": int64(2), "sensors_id": "a", "sensors_temp": int64(20)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "0", "matrix_0_index": "1", "sensors_index": "1"},
+			map[string]interface{}{"gateway": "gw1", "matrix_0": int64(2), "sensors_id": "b", "sensors_temp": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "1", "sensors_index": "0"},
+			map[string]interface{}{"gateway": "gw1", "sensors_id": "a", "sensors_temp": int64(20)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{"site": "berlin", "matrix_index": "1", "sensors_index": "1"},
+			map[string]interface{}{"gateway": "gw1", "sensors_id": "b", "sensors_temp": 21.5},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestIgnoreInvalid(t *testing.T) {
+	plugin := &JSONFlatten{
+		Field:        "payload",
+		DropOriginal: true,
+		Separator:    "_",
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"payload": `{"broken"`}, time.Unix(0, 0)),
+		metric.New("b", nil, map[string]interface{}{"payload": `"scalar"`}, time.Unix(0, 0)),
+		metric.New("c", nil, map[string]interface{}{"payload": int64(42)}, time.Unix(0, 0)),
+		metric.New("d", nil, map[string]interface{}{"value": int64(42)}, time.Unix(0, 0)),
+	}
+	expected := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		expected = append(expected, m.Copy())
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("single", nil, map[string]interface{}{"payload": `{"a": 1}`}, time.Unix(0, 0)),
+		metric.New("exploded", nil, map[string]interface{}{"payload": `{"a": [1, 2]}`}, time.Unix(0, 0)),
+	}
+
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &JSONFlatten{
+		Field:     "payload",
+		Separator: "_",
+		ArrayMode: "explode",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 3)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Len(t, delivered, len(input))
+}
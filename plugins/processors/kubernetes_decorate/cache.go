@@ -0,0 +1,170 @@
+package kubernetes_decorate
+
+import (
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Length of the short container IDs used e.g. by Docker
+const shortIDLength = 12
+
+// podInfo is the pod metadata added to the metrics
+type podInfo struct {
+	uid          string
+	name         string
+	namespace    string
+	node         string
+	workloadKind string
+	workloadName string
+	labels       map[string]string
+}
+
+type podKeys struct {
+	containers []string
+	ips        []string
+}
+
+// podCache indexes the pods by container ID and pod IP. It is updated by the
+// informer and queried for every metric.
+type podCache struct {
+	// labels to add, no labels are added if unset
+	labelFilter filter.Filter
+
+	containers map[string]*podInfo
+	ips        map[string]*podInfo
+	// keys indexed per pod UID to cleanup on updates and deletions
+	keys map[string]podKeys
+
+	sync.RWMutex
+}
+
+func newPodCache(labelFilter filter.Filter) *podCache {
+	return &podCache{
+		labelFilter: labelFilter,
+		containers:  make(map[string]*podInfo),
+		ips:         make(map[string]*podInfo),
+		keys:        make(map[string]podKeys),
+	}
+}
+
+func (c *podCache) upsert(pod *corev1.Pod) {
+	info := &podInfo{
+		uid:       string(pod.UID),
+		name:      pod.Name,
+		namespace: pod.Namespace,
+		node:      pod.Spec.NodeName,
+		labels:    make(map[string]string),
+	}
+	info.workloadKind, info.workloadName = workload(pod)
+	if c.labelFilter != nil {
+		for k, v := range pod.Labels {
+			if c.labelFilter.Match(k) {
+				info.labels[k] = v
+			}
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.remove(info.uid)
+
+	var keys podKeys
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		id := normalizeContainerID(status.ContainerID)
+		if id == "" {
+			continue
+		}
+		c.containers[id] = info
+		keys.containers = append(keys.containers, id)
+		if len(id) > shortIDLength {
+			c.containers[id[:shortIDLength]] = info
+			keys.containers = append(keys.containers, id[:shortIDLength])
+		}
+	}
+
+	// Pods in the host network share the IP of the node and cannot be
+	// identified by their IP
+	if !pod.Spec.HostNetwork && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		for _, ip := range pod.Status.PodIPs {
+			if ip.IP == "" {
+				continue
+			}
+			c.ips[ip.IP] = info
+			keys.ips = append(keys.ips, ip.IP)
+		}
+	}
+	c.keys[info.uid] = keys
+}
+
+func (c *podCache) delete(uid string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.remove(uid)
+}
+
+// remove drops the entries of the pod with the given UID, entries taken over
+// by other pods, e.g. reused IPs, are kept
+func (c *podCache) remove(uid string) {
+	keys := c.keys[uid]
+	for _, id := range keys.containers {
+		if info, found := c.containers[id]; found && info.uid == uid {
+			delete(c.containers, id)
+		}
+	}
+	for _, ip := range keys.ips {
+		if info, found := c.ips[ip]; found && info.uid == uid {
+			delete(c.ips, ip)
+		}
+	}
+	delete(c.keys, uid)
+}
+
+func (c *podCache) byContainerID(id string) *podInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.containers[normalizeContainerID(id)]
+}
+
+func (c *podCache) byIP(ip string) *podInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.ips[ip]
+}
+
+// normalizeContainerID strips the runtime prefix like "containerd://" from
+// the container ID
+func normalizeContainerID(id string) string {
+	if _, after, found := strings.Cut(id, "://"); found {
+		id = after
+	}
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// workload returns the kind and name of the controller owning the pod. Pods
+// of a ReplicaSet created by a Deployment are attributed to the Deployment
+// based on the pod-template-hash label.
+func workload(pod *corev1.Pod) (kind, name string) {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return "Deployment", strings.TrimSuffix(ref.Name, "-"+hash)
+			}
+		}
+		return ref.Kind, ref.Name
+	}
+	return "", ""
+}
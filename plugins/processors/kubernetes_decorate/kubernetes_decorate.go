@@ -0,0 +1,192 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kubernetes_decorate
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type KubernetesDecorate struct {
+	KubeConfig     string          `toml:"kube_config"`
+	NodeName       string          `toml:"node_name"`
+	Namespace      string          `toml:"namespace"`
+	ContainerIDTag string          `toml:"container_id_tag"`
+	PodIPTag       string          `toml:"pod_ip_tag"`
+	LabelInclude   []string        `toml:"label_include"`
+	LabelExclude   []string        `toml:"label_exclude"`
+	ResyncInterval config.Duration `toml:"resync_interval"`
+	SyncTimeout    config.Duration `toml:"sync_timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	cache  *podCache
+	cancel context.CancelFunc
+}
+
+func (*KubernetesDecorate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *KubernetesDecorate) Init() error {
+	if k.ContainerIDTag == "" && k.PodIPTag == "" {
+		return errors.New("either 'container_id_tag' or 'pod_ip_tag' must be set")
+	}
+
+	// Labels are only added if explicitly selected
+	var labelFilter filter.Filter
+	if len(k.LabelInclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(k.LabelInclude, k.LabelExclude)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+		labelFilter = f
+	}
+	k.cache = newPodCache(labelFilter)
+
+	return nil
+}
+
+func (k *KubernetesDecorate) Start(telegraf.Accumulator) error {
+	cfg, err := loadConfig(k.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("loading kubernetes config failed: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client failed: %w", err)
+	}
+
+	options := []informers.SharedInformerOption{
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			if k.NodeName != "" {
+				options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", k.NodeName).String()
+			}
+		}),
+	}
+	if k.Namespace != "" {
+		options = append(options, informers.WithNamespace(k.Namespace))
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(client, time.Duration(k.ResyncInterval), options...)
+
+	informer := factory.Core().V1().Pods().Informer()
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				k.cache.upsert(pod)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				k.cache.upsert(pod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok {
+				k.cache.delete(string(pod.UID))
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("adding pod event handler failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	factory.Start(ctx.Done())
+
+	// Do not block the startup forever but decorate metrics once the pods
+	// are known
+	syncCtx, syncCancel := context.WithTimeout(ctx, time.Duration(k.SyncTimeout))
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		k.Log.Warnf("Pods not synced within %s, metrics are not decorated until the sync completes", k.SyncTimeout)
+	}
+
+	return nil
+}
+
+func (k *KubernetesDecorate) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	k.decorate(m)
+	acc.AddMetric(m)
+	return nil
+}
+
+func (k *KubernetesDecorate) Stop() {
+	if k.cancel != nil {
+		k.cancel()
+	}
+}
+
+func (k *KubernetesDecorate) decorate(m telegraf.Metric) {
+	var info *podInfo
+	if k.ContainerIDTag != "" {
+		if id, found := m.GetTag(k.ContainerIDTag); found {
+			info = k.cache.byContainerID(id)
+		}
+	}
+	if info == nil && k.PodIPTag != "" {
+		if ip, found := m.GetTag(k.PodIPTag); found {
+			info = k.cache.byIP(ip)
+		}
+	}
+	if info == nil {
+		return
+	}
+
+	// Existing tags take precedence over the pod metadata
+	addTag := func(key, value string) {
+		if value != "" && !m.HasTag(key) {
+			m.AddTag(key, value)
+		}
+	}
+	addTag("pod_name", info.name)
+	addTag("namespace", info.namespace)
+	addTag("node_name", info.node)
+	addTag("workload_kind", info.workloadKind)
+	addTag("workload_name", info.workloadName)
+	for key, value := range info.labels {
+		addTag(key, value)
+	}
+}
+
+// loadConfig parses a kubeconfig from a file and returns a Kubernetes
+// rest.Config, using the in-cluster configuration if no file is given
+func loadConfig(kubeconfigPath string) (*rest.Config, error) {
+	if kubeconfigPath == "" {
+		return rest.InClusterConfig()
+	}
+	return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+}
+
+func init() {
+	processors.AddStreaming("kubernetes_decorate", func() telegraf.StreamingProcessor {
+		return &KubernetesDecorate{
+			ContainerIDTag: "container_id",
+			PodIPTag:       "pod_ip",
+			ResyncInterval: config.Duration(time.Hour),
+			SyncTimeout:    config.Duration(30 * time.Second),
+		}
+	})
+}
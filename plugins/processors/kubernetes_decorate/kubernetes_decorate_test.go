@@ -0,0 +1,195 @@
+package kubernetes_decorate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const containerID = "3f4b3c7e9a0d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f"
+
+func newPod(uid, name, ip string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(uid),
+			Name:      name,
+			Namespace: "shop",
+			Labels: map[string]string{
+				"app":               "checkout",
+				"team":              "payments",
+				"pod-template-hash": "5d8f7c6b9",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "checkout-5d8f7c6b9", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: "worker-1"},
+		Status: corev1.PodStatus{
+			Phase:  corev1.PodRunning,
+			PodIPs: []corev1.PodIP{{IP: ip}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ContainerID: "containerd://" + containerID},
+			},
+		},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &KubernetesDecorate{}
+	require.ErrorContains(t, plugin.Init(), "either 'container_id_tag' or 'pod_ip_tag' must be set")
+}
+
+func TestDecorate(t *testing.T) {
+	plugin := &KubernetesDecorate{
+		ContainerIDTag: "container_id",
+		PodIPTag:       "pod_ip",
+		LabelInclude:   []string{"app"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.cache.upsert(newPod("uid-1", "checkout-5d8f7c6b9-x2v7q", "10.1.2.3"))
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("statsd", map[string]string{"container_id": containerID}, map[string]interface{}{"value": 1}, now),
+		metric.New("statsd", map[string]string{"container_id": containerID[:12]}, map[string]interface{}{"value": 2}, now),
+		metric.New("syslog", map[string]string{"pod_ip": "10.1.2.3", "namespace": "custom"}, map[string]interface{}{"value": 3}, now),
+		metric.New("syslog", map[string]string{"pod_ip": "10.9.9.9"}, map[string]interface{}{"value": 4}, now),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"statsd",
+			map[string]string{
+				"container_id":  containerID,
+				"pod_name":      "checkout-5d8f7c6b9-x2v7q",
+				"namespace":     "shop",
+				"node_name":     "worker-1",
+				"workload_kind": "Deployment",
+				"workload_name": "checkout",
+				"app":           "checkout",
+			},
+			map[string]interface{}{"value": 1},
+			now,
+		),
+		metric.New(
+			"statsd",
+			map[string]string{
+				"container_id":  containerID[:12],
+				"pod_name":      "checkout-5d8f7c6b9-x2v7q",
+				"namespace":     "shop",
+				"node_name":     "worker-1",
+				"workload_kind": "Deployment",
+				"workload_name": "checkout",
+				"app":           "checkout",
+			},
+			map[string]interface{}{"value": 2},
+			now,
+		),
+		metric.New(
+			"syslog",
+			map[string]string{
+				"pod_ip":        "10.1.2.3",
+				"pod_name":      "checkout-5d8f7c6b9-x2v7q",
+				"namespace":     "custom",
+				"node_name":     "worker-1",
+				"workload_kind": "Deployment",
+				"workload_name": "checkout",
+				"app":           "checkout",
+			},
+			map[string]interface{}{"value": 3},
+			now,
+		),
+		metric.New("syslog", map[string]string{"pod_ip": "10.9.9.9"}, map[string]interface{}{"value": 4}, now),
+	}
+
+	var acc testutil.Accumulator
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestCacheUpdates(t *testing.T) {
+	c := newPodCache(nil)
+
+	old := newPod("uid-1", "checkout-old", "10.1.2.3")
+	c.upsert(old)
+	require.NotNil(t, c.byIP("10.1.2.3"))
+	require.NotNil(t, c.byContainerID("containerd://" + containerID))
+	require.Empty(t, c.byIP("10.1.2.3").labels)
+
+	// The IP is reused by a new pod before the old one is deleted
+	reused := newPod("uid-2", "checkout-new", "10.1.2.3")
+	reused.Status.ContainerStatuses = nil
+	c.upsert(reused)
+	c.delete("uid-1")
+	require.Equal(t, "checkout-new", c.byIP("10.1.2.3").name)
+	require.Nil(t, c.byContainerID(containerID))
+
+	// Finished pods and pods in the host network are not looked up by IP
+	reused.Status.Phase = corev1.PodSucceeded
+	c.upsert(reused)
+	require.Nil(t, c.byIP("10.1.2.3"))
+
+	host := newPod("uid-3", "node-exporter", "192.168.0.10")
+	host.Spec.HostNetwork = true
+	c.upsert(host)
+	require.Nil(t, c.byIP("192.168.0.10"))
+	require.Equal(t, "node-exporter", c.byContainerID(containerID).name)
+}
+
+func TestWorkload(t *testing.T) {
+	controller := true
+	tests := []struct {
+		name   string
+		owners []metav1.OwnerReference
+		labels map[string]string
+		kind   string
+		owner  string
+	}{
+		{
+			name:   "deployment",
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7c9f8d", Controller: &controller}},
+			labels: map[string]string{"pod-template-hash": "7c9f8d"},
+			kind:   "Deployment",
+			owner:  "web",
+		},
+		{
+			name:   "replicaset",
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web", Controller: &controller}},
+			kind:   "ReplicaSet",
+			owner:  "web",
+		},
+		{
+			name:   "statefulset",
+			owners: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &controller}},
+			kind:   "StatefulSet",
+			owner:  "db",
+		},
+		{
+			name:   "no controller",
+			owners: []metav1.OwnerReference{{Kind: "ConfigMap", Name: "settings"}},
+		},
+		{
+			name: "standalone",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: tt.owners, Labels: tt.labels}}
+			kind, name := workload(pod)
+			require.Equal(t, tt.kind, kind)
+			require.Equal(t, tt.owner, name)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package kv_lookup
+
+import (
+	"maps"
+	"sync"
+)
+
+// cache keeps the tags of all keys in use. Concurrent lookups of the same key
+// wait for the single query in flight.
+type cache struct {
+	entries map[string]*cacheEntry
+	sync.Mutex
+}
+
+type cacheEntry struct {
+	done chan struct{}
+	tags map[string]string
+	used bool
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[string]*cacheEntry)}
+}
+
+// get returns the tags for the key, calling the resolve function if the key
+// is not cached. A nil result of the function is cached as unknown key.
+func (c *cache) get(key string, resolve func(key string) map[string]string) map[string]string {
+	c.Lock()
+	if e, found := c.entries[key]; found {
+		e.used = true
+		c.Unlock()
+		// Wait for the query in flight if any
+		<-e.done
+		c.Lock()
+		defer c.Unlock()
+		return e.tags
+	}
+	e := &cacheEntry{done: make(chan struct{}), used: true}
+	c.entries[key] = e
+	c.Unlock()
+
+	tags := resolve(key)
+
+	c.Lock()
+	e.tags = tags
+	c.Unlock()
+	close(e.done)
+
+	return tags
+}
+
+// active returns the resolved keys used since the last call and removes the
+// keys not used anymore to keep the cache limited to the keys in use
+func (c *cache) active() []string {
+	c.Lock()
+	defer c.Unlock()
+
+	maps.DeleteFunc(c.entries, func(_ string, e *cacheEntry) bool {
+		select {
+		case <-e.done:
+			return !e.used
+		default:
+			// Keep the queries in flight
+			return false
+		}
+	})
+
+	keys := make([]string, 0, len(c.entries))
+	for key, e := range c.entries {
+		select {
+		case <-e.done:
+			keys = append(keys, key)
+			e.used = false
+		default:
+		}
+	}
+	return keys
+}
+
+// update sets the tags of the given keys, keys missing in the results are
+// marked as unknown
+func (c *cache) update(keys []string, results map[string]map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, key := range keys {
+		if e, found := c.entries[key]; found {
+			e.tags = results[key]
+		}
+	}
+}
@@ -0,0 +1,197 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kv_lookup
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/parallel"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// store returns the tags for each of the given keys found in the store
+type store interface {
+	connect(ctx context.Context) error
+	lookup(ctx context.Context, keys []string) (map[string]map[string]string, error)
+	close() error
+}
+
+type KVLookup struct {
+	Tag                string            `toml:"tag"`
+	Redis              *redisStore       `toml:"redis"`
+	SQLite             *sqliteStore      `toml:"sqlite"`
+	Fallback           map[string]string `toml:"fallback"`
+	Timeout            config.Duration   `toml:"timeout"`
+	RefreshInterval    config.Duration   `toml:"refresh_interval"`
+	MaxParallelLookups int               `toml:"max_parallel_lookups"`
+	Ordered            bool              `toml:"ordered"`
+	Log                telegraf.Logger   `toml:"-"`
+
+	store    store
+	cache    *cache
+	parallel parallel.Parallel
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func (*KVLookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *KVLookup) Init() error {
+	if k.Tag == "" {
+		return errors.New("tag required")
+	}
+
+	switch {
+	case k.Redis != nil && k.SQLite != nil:
+		return errors.New("only one of redis or sqlite can be configured")
+	case k.Redis != nil:
+		if err := k.Redis.init(); err != nil {
+			return fmt.Errorf("initializing redis failed: %w", err)
+		}
+		k.store = k.Redis
+	case k.SQLite != nil:
+		if err := k.SQLite.init(); err != nil {
+			return fmt.Errorf("initializing sqlite failed: %w", err)
+		}
+		k.store = k.SQLite
+	default:
+		return errors.New("no store configured")
+	}
+
+	if k.MaxParallelLookups <= 0 {
+		k.MaxParallelLookups = 10
+	}
+
+	return nil
+}
+
+func (k *KVLookup) Start(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(k.Timeout))
+	defer cancel()
+	if err := k.store.connect(ctx); err != nil {
+		return fmt.Errorf("connecting to store failed: %w", err)
+	}
+
+	k.cache = newCache()
+	if k.Ordered {
+		k.parallel = parallel.NewOrdered(acc, k.asyncAdd, 10000, k.MaxParallelLookups)
+	} else {
+		k.parallel = parallel.NewUnordered(acc, k.asyncAdd, k.MaxParallelLookups)
+	}
+
+	if k.RefreshInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		k.cancel = cancel
+		k.wg.Add(1)
+		go func() {
+			defer k.wg.Done()
+			k.refreshLoop(ctx)
+		}()
+	}
+
+	return nil
+}
+
+func (k *KVLookup) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	k.parallel.Enqueue(m)
+	return nil
+}
+
+func (k *KVLookup) Stop() {
+	if k.cancel != nil {
+		k.cancel()
+	}
+	k.wg.Wait()
+	k.parallel.Stop()
+	if err := k.store.close(); err != nil {
+		k.Log.Errorf("Closing store failed: %v", err)
+	}
+}
+
+func (k *KVLookup) asyncAdd(m telegraf.Metric) []telegraf.Metric {
+	key, found := m.GetTag(k.Tag)
+	if !found || key == "" {
+		return []telegraf.Metric{m}
+	}
+
+	tags := k.cache.get(key, k.lookup)
+	if tags == nil {
+		tags = k.Fallback
+	}
+	for name, value := range tags {
+		m.AddTag(name, value)
+	}
+	return []telegraf.Metric{m}
+}
+
+// lookup queries the store for a single key and returns nil if the key is
+// unknown or the query failed
+func (k *KVLookup) lookup(key string) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(k.Timeout))
+	defer cancel()
+
+	results, err := k.store.lookup(ctx, []string{key})
+	if err != nil {
+		k.Log.Errorf("Looking up %q failed: %v", key, err)
+		return nil
+	}
+	if _, found := results[key]; !found {
+		k.Log.Debugf("Key %q not found", key)
+	}
+	return results[key]
+}
+
+// refreshLoop periodically updates all keys used since the last refresh,
+// including the unknown ones, so changes in the store become visible
+func (k *KVLookup) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(k.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.refresh(ctx)
+		}
+	}
+}
+
+func (k *KVLookup) refresh(ctx context.Context) {
+	keys := k.cache.active()
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(k.Timeout))
+	defer cancel()
+
+	results, err := k.store.lookup(ctx, keys)
+	if err != nil {
+		// Keep serving the previous values while the store is unavailable
+		k.Log.Errorf("Refreshing %d keys failed: %v", len(keys), err)
+		return
+	}
+	k.cache.update(keys, results)
+}
+
+func init() {
+	processors.AddStreaming("kv_lookup", func() telegraf.StreamingProcessor {
+		return &KVLookup{
+			Timeout:            config.Duration(5 * time.Second),
+			RefreshInterval:    config.Duration(5 * time.Minute),
+			MaxParallelLookups: 10,
+		}
+	})
+}
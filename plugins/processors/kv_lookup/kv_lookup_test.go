@@ -0,0 +1,278 @@
+package kv_lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockStore serves the lookups from a map
+type mockStore struct {
+	data    map[string]map[string]string
+	fail    bool
+	queries atomic.Int64
+	sync.Mutex
+}
+
+func (*mockStore) connect(context.Context) error {
+	return nil
+}
+
+func (s *mockStore) lookup(_ context.Context, keys []string) (map[string]map[string]string, error) {
+	s.queries.Add(1)
+
+	s.Lock()
+	defer s.Unlock()
+	if s.fail {
+		return nil, errors.New("store unavailable")
+	}
+	results := make(map[string]map[string]string)
+	for _, key := range keys {
+		if tags, found := s.data[key]; found {
+			results[key] = tags
+		}
+	}
+	return results, nil
+}
+
+func (*mockStore) close() error {
+	return nil
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *KVLookup
+		expected string
+	}{
+		{
+			name:     "no tag",
+			plugin:   &KVLookup{Redis: &redisStore{}},
+			expected: "tag required",
+		},
+		{
+			name:     "no store",
+			plugin:   &KVLookup{Tag: "id"},
+			expected: "no store configured",
+		},
+		{
+			name:     "multiple stores",
+			plugin:   &KVLookup{Tag: "id", Redis: &redisStore{}, SQLite: &sqliteStore{Path: "test.db", Query: "SELECT"}},
+			expected: "only one of redis or sqlite can be configured",
+		},
+		{
+			name:     "sqlite without query",
+			plugin:   &KVLookup{Tag: "id", SQLite: &sqliteStore{Path: "test.db"}},
+			expected: "query required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	store := &mockStore{
+		data: map[string]map[string]string{
+			"sensor01": {"location": "Berlin", "rack": "12"},
+		},
+	}
+	plugin := &KVLookup{
+		Tag:                "device",
+		Fallback:           map[string]string{"location": "unknown"},
+		Timeout:            config.Duration(time.Second),
+		MaxParallelLookups: 2,
+		Ordered:            true,
+		Log:                testutil.Logger{},
+		store:              store,
+	}
+
+	input := []telegraf.Metric{
+		metric.New("temp", map[string]string{"device": "sensor01"}, map[string]interface{}{"value": 21.5}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "sensor02"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{}, map[string]interface{}{"value": 18.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "sensor01"}, map[string]interface{}{"value": 21.7}, time.Unix(10, 0)),
+		metric.New("temp", map[string]string{"device": "sensor02"}, map[string]interface{}{"value": 20.1}, time.Unix(10, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"temp",
+			map[string]string{"device": "sensor01", "location": "Berlin", "rack": "12"},
+			map[string]interface{}{"value": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New("temp", map[string]string{"device": "sensor02", "location": "unknown"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{}, map[string]interface{}{"value": 18.0}, time.Unix(0, 0)),
+		metric.New(
+			"temp",
+			map[string]string{"device": "sensor01", "location": "Berlin", "rack": "12"},
+			map[string]interface{}{"value": 21.7},
+			time.Unix(10, 0),
+		),
+		metric.New("temp", map[string]string{"device": "sensor02", "location": "unknown"}, map[string]interface{}{"value": 20.1}, time.Unix(10, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Each key must only be queried once including the unknown ones
+	require.Equal(t, int64(2), store.queries.Load())
+}
+
+func TestRefresh(t *testing.T) {
+	store := &mockStore{
+		data: map[string]map[string]string{
+			"sensor01": {"location": "Berlin"},
+		},
+	}
+	plugin := &KVLookup{
+		Tag:     "device",
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+		store:   store,
+		cache:   newCache(),
+	}
+
+	m := metric.New("temp", map[string]string{"device": "sensor01"}, map[string]interface{}{"value": 21.5}, time.Unix(0, 0))
+	plugin.asyncAdd(m.Copy())
+	m = metric.New("temp", map[string]string{"device": "sensor02"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0))
+	plugin.asyncAdd(m.Copy())
+
+	// Change the store and refresh all keys including the unknown one
+	store.Lock()
+	store.data = map[string]map[string]string{
+		"sensor01": {"location": "Paris"},
+		"sensor02": {"location": "Rome"},
+	}
+	store.Unlock()
+	plugin.refresh(t.Context())
+
+	actual := plugin.asyncAdd(m.Copy())
+	require.Equal(t, map[string]string{"device": "sensor02", "location": "Rome"}, actual[0].Tags())
+
+	// A failing store keeps the previous values
+	store.Lock()
+	store.fail = true
+	store.Unlock()
+	plugin.refresh(t.Context())
+
+	actual = plugin.asyncAdd(m.Copy())
+	require.Equal(t, map[string]string{"device": "sensor02", "location": "Rome"}, actual[0].Tags())
+}
+
+func TestCacheActive(t *testing.T) {
+	c := newCache()
+	resolve := func(key string) map[string]string {
+		return map[string]string{"key": key}
+	}
+
+	c.get("a", resolve)
+	c.get("b", resolve)
+	require.ElementsMatch(t, []string{"a", "b"}, c.active())
+
+	// Keys not used since the last refresh are removed
+	c.get("a", resolve)
+	require.ElementsMatch(t, []string{"a"}, c.active())
+	require.Empty(t, c.active())
+	require.Empty(t, c.entries)
+}
+
+func TestCacheSingleRequest(t *testing.T) {
+	c := newCache()
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	resolve := func(string) map[string]string {
+		calls.Add(1)
+		<-release
+		return map[string]string{"a": "b"}
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.Equal(t, map[string]string{"a": "b"}, c.get("key", resolve))
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestRedisIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	const servicePort = "6379"
+	container := testutil.Container{
+		Image:        "redis:alpine",
+		ExposedPorts: []string{servicePort},
+		WaitingFor:   wait.ForListeningPort(nat.Port(servicePort)),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+	address := fmt.Sprintf("%s:%s", container.Address, container.Ports[servicePort])
+
+	client := redis.NewClient(&redis.Options{Addr: address})
+	defer client.Close()
+	require.NoError(t, client.HSet(t.Context(), "device:sensor01", "location", "Berlin", "rack", "12").Err())
+
+	plugin := &KVLookup{
+		Tag:     "device",
+		Redis:   &redisStore{Address: address, KeyPrefix: "device:"},
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("temp", map[string]string{"device": "sensor01"}, map[string]interface{}{"value": 21.5}, time.Unix(0, 0)),
+		metric.New("temp", map[string]string{"device": "sensor02"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"temp",
+			map[string]string{"device": "sensor01", "location": "Berlin", "rack": "12"},
+			map[string]interface{}{"value": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New("temp", map[string]string{"device": "sensor02"}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
@@ -0,0 +1,86 @@
+package kv_lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// redisStore looks up keys as hashes with the hash fields being the tags
+type redisStore struct {
+	Address   string        `toml:"address"`
+	Username  config.Secret `toml:"username"`
+	Password  config.Secret `toml:"password"`
+	Database  int           `toml:"database"`
+	KeyPrefix string        `toml:"key_prefix"`
+	tls.ClientConfig
+
+	client *redis.Client
+}
+
+func (r *redisStore) init() error {
+	if r.Address == "" {
+		r.Address = "127.0.0.1:6379"
+	}
+	return nil
+}
+
+func (r *redisStore) connect(ctx context.Context) error {
+	username, err := r.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	tlsConfig, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	r.client = redis.NewClient(&redis.Options{
+		Addr:      r.Address,
+		Username:  username.String(),
+		Password:  password.String(),
+		DB:        r.Database,
+		TLSConfig: tlsConfig,
+	})
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisStore) lookup(ctx context.Context, keys []string) (map[string]map[string]string, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.HGetAll(ctx, r.KeyPrefix+key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	results := make(map[string]map[string]string, len(keys))
+	for i, cmd := range cmds {
+		// Missing keys result in an empty hash
+		if tags := cmd.Val(); len(tags) > 0 {
+			results[keys[i]] = tags
+		}
+	}
+	return results, nil
+}
+
+func (r *redisStore) close() error {
+	if r.client == nil {
+		return nil
+	}
+	return r.client.Close()
+}
@@ -0,0 +1,116 @@
+package kv_lookup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// sqliteStore looks up keys using a query with the columns of the first
+// returned row being the tags
+type sqliteStore struct {
+	Path  string `toml:"path"`
+	Query string `toml:"query"`
+
+	db   *sql.DB
+	stmt *sql.Stmt
+}
+
+func (s *sqliteStore) init() error {
+	if s.Path == "" {
+		return errors.New("path required")
+	}
+	if s.Query == "" {
+		return errors.New("query required")
+	}
+	return nil
+}
+
+func (s *sqliteStore) connect(ctx context.Context) error {
+	// Open the database read-only as we never modify it
+	dsn := "file:" + s.Path + "?" + url.Values{"mode": []string{"ro"}}.Encode()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return err
+	}
+
+	stmt, err := db.PrepareContext(ctx, s.Query)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("preparing query failed: %w", err)
+	}
+	s.db = db
+	s.stmt = stmt
+
+	return nil
+}
+
+func (s *sqliteStore) lookup(ctx context.Context, keys []string) (map[string]map[string]string, error) {
+	results := make(map[string]map[string]string, len(keys))
+	for _, key := range keys {
+		tags, err := s.query(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("querying %q failed: %w", key, err)
+		}
+		if tags != nil {
+			results[key] = tags
+		}
+	}
+	return results, nil
+}
+
+func (s *sqliteStore) query(ctx context.Context, key string) (map[string]string, error) {
+	rows, err := s.stmt.QueryContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(columns))
+	for i, column := range columns {
+		if values[i] == nil {
+			continue
+		}
+		v, err := internal.ToString(values[i])
+		if err != nil {
+			return nil, fmt.Errorf("converting column %q failed: %w", column, err)
+		}
+		tags[column] = v
+	}
+	return tags, nil
+}
+
+func (s *sqliteStore) close() error {
+	if s.db == nil {
+		return nil
+	}
+	if err := s.stmt.Close(); err != nil {
+		return err
+	}
+	return s.db.Close()
+}
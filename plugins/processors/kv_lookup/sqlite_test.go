@@ -0,0 +1,49 @@
+//go:build !mips && !mipsle && !mips64 && !ppc64 && !riscv64 && !loong64 && !mips64le && !(windows && (386 || arm)) && !(freebsd && (386 || arm))
+
+package kv_lookup
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE devices (id TEXT PRIMARY KEY, location TEXT, rack INTEGER, comment TEXT);
+		INSERT INTO devices VALUES ('sensor01', 'Berlin', 12, NULL);
+		INSERT INTO devices VALUES ('sensor02', 'Paris', 3, 'spare');
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s := &sqliteStore{
+		Path:  path,
+		Query: "SELECT location, rack, comment FROM devices WHERE id = ?",
+	}
+	require.NoError(t, s.init())
+	require.NoError(t, s.connect(t.Context()))
+	defer s.close()
+
+	results, err := s.lookup(t.Context(), []string{"sensor01", "sensor02", "unknown"})
+	require.NoError(t, err)
+	expected := map[string]map[string]string{
+		"sensor01": {"location": "Berlin", "rack": "12"},
+		"sensor02": {"location": "Paris", "rack": "3", "comment": "spare"},
+	}
+	require.Equal(t, expected, results)
+}
+
+func TestSQLiteMissingFile(t *testing.T) {
+	s := &sqliteStore{
+		Path:  filepath.Join(t.TempDir(), "missing.db"),
+		Query: "SELECT location FROM devices WHERE id = ?",
+	}
+	require.NoError(t, s.init())
+	require.Error(t, s.connect(t.Context()))
+}
@@ -0,0 +1,191 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package lua
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Lua struct {
+	Source      string                 `toml:"source"`
+	Script      string                 `toml:"script"`
+	ModulePaths []string               `toml:"module_paths"`
+	Constants   map[string]interface{} `toml:"constants"`
+	Log         telegraf.Logger        `toml:"-"`
+
+	state *lua.LState
+	apply *lua.LFunction
+}
+
+func (*Lua) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Lua) Init() error {
+	if l.Source == "" && l.Script == "" {
+		return errors.New("one of source or script must be set")
+	}
+	if l.Source != "" && l.Script != "" {
+		return errors.New("both source or script cannot be set")
+	}
+
+	l.state = newState(l.Log)
+
+	if len(l.ModulePaths) > 0 {
+		pkg := l.state.GetGlobal("package").(*lua.LTable)
+		paths := make([]string, 0, len(l.ModulePaths)+1)
+		for _, dir := range l.ModulePaths {
+			paths = append(paths, filepath.Join(dir, "?.lua"))
+		}
+		paths = append(paths, lua.LVAsString(pkg.RawGetString("path")))
+		pkg.RawSetString("path", lua.LString(strings.Join(paths, ";")))
+	}
+
+	for name, value := range l.Constants {
+		v, err := toLuaValue(l.state, value)
+		if err != nil {
+			return fmt.Errorf("invalid constant %q: %w", name, err)
+		}
+		l.state.SetGlobal(name, v)
+	}
+
+	if l.Source != "" {
+		if err := l.state.DoString(l.Source); err != nil {
+			return fmt.Errorf("loading source failed: %w", err)
+		}
+	} else {
+		if err := l.state.DoFile(l.Script); err != nil {
+			return fmt.Errorf("loading script %q failed: %w", l.Script, err)
+		}
+	}
+
+	fn, ok := l.state.GetGlobal("apply").(*lua.LFunction)
+	if !ok {
+		return errors.New("script does not define an 'apply' function")
+	}
+	l.apply = fn
+
+	return nil
+}
+
+func (l *Lua) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		results, err := l.call(m)
+		if err != nil {
+			l.Log.Errorf("Calling apply failed: %v", err)
+			out = append(out, m)
+			continue
+		}
+
+		// Mark the original metric as handled if the script didn't return it
+		var origFound bool
+		for _, r := range results {
+			if r == m {
+				origFound = true
+				break
+			}
+		}
+		if !origFound {
+			m.Drop()
+		}
+		out = append(out, results...)
+	}
+	return out
+}
+
+func (l *Lua) Stop() {
+	if l.state != nil {
+		l.state.Close()
+	}
+}
+
+// call runs the apply function for the metric and returns the resulting
+// metrics which may include the original one
+func (l *Lua) call(m telegraf.Metric) ([]telegraf.Metric, error) {
+	err := l.state.CallByParam(lua.P{Fn: l.apply, NRet: 1, Protect: true}, newMetric(l.state, m))
+	if err != nil {
+		return nil, err
+	}
+	ret := l.state.Get(-1)
+	l.state.Pop(1)
+
+	switch rv := ret.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case *lua.LUserData:
+		metric, err := checkMetric(rv)
+		if err != nil {
+			return nil, err
+		}
+		return []telegraf.Metric{metric}, nil
+	case *lua.LTable:
+		results := make([]telegraf.Metric, 0, rv.Len())
+		for i := 1; i <= rv.Len(); i++ {
+			metric, err := checkMetric(rv.RawGetInt(i))
+			if err != nil {
+				return nil, fmt.Errorf("invalid list entry %d: %w", i, err)
+			}
+			if containsMetric(results, metric) {
+				l.Log.Errorf("Duplicate metric reference detected")
+				continue
+			}
+			results = append(results, metric)
+		}
+		return results, nil
+	default:
+		return nil, fmt.Errorf("invalid type returned: %s", ret.Type())
+	}
+}
+
+// newState creates a Lua state without access to the operating system and
+// the filesystem except for loading modules
+func newState(log telegraf.Logger) *lua.LState {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	libs := []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	}
+	for _, lib := range libs {
+		state.Push(state.NewFunction(lib.fn))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+
+	registerMetricType(state)
+	registerModule(state, log)
+
+	return state
+}
+
+func containsMetric(metrics []telegraf.Metric, target telegraf.Metric) bool {
+	for _, m := range metrics {
+		if m == target {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	processors.Add("lua", func() telegraf.Processor {
+		return &Lua{}
+	})
+}
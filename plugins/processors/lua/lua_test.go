@@ -0,0 +1,313 @@
+package lua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Lua
+		expected string
+	}{
+		{
+			name:     "no source",
+			plugin:   &Lua{},
+			expected: "one of source or script must be set",
+		},
+		{
+			name:     "source and script",
+			plugin:   &Lua{Source: "function apply(m) return m end", Script: "testdata/script.lua"},
+			expected: "both source or script cannot be set",
+		},
+		{
+			name:     "syntax error",
+			plugin:   &Lua{Source: "function apply(m) return m"},
+			expected: "loading source failed",
+		},
+		{
+			name:     "missing script",
+			plugin:   &Lua{Script: "testdata/nonexistent.lua"},
+			expected: `loading script "testdata/nonexistent.lua" failed`,
+		},
+		{
+			name:     "no apply function",
+			plugin:   &Lua{Source: "function process(m) return m end"},
+			expected: "script does not define an 'apply' function",
+		},
+		{
+			name: "unsupported constant",
+			plugin: &Lua{
+				Source:    "function apply(m) return m end",
+				Constants: map[string]interface{}{"since": time.Unix(0, 0)},
+			},
+			expected: `invalid constant "since"`,
+		},
+		{
+			name:     "sandboxed",
+			plugin:   &Lua{Source: `os.remove("/tmp/file")`},
+			expected: "loading source failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+			tt.plugin.Stop()
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "passthrough",
+			source: `function apply(metric) return metric end`,
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "modify metric",
+			source: `
+function apply(metric)
+  metric:set_name(metric:name() .. "_total")
+  metric:set_tag("region", string.upper(metric:get_tag("host")))
+  metric:remove_tag("host")
+  metric:set_field("count", metric:get_field("count") * 2)
+  metric:set_field("ratio", metric:get_field("count") / 3)
+  metric:set_field("limit", 10, "uint")
+  metric:set_field("ok", true)
+  metric:set_field("state", "running")
+  local sec, nsec = metric:time()
+  metric:set_time(sec + 60, nsec)
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(3)}, time.Unix(10, 5)),
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu_total",
+					map[string]string{"region": "A"},
+					map[string]interface{}{
+						"count": int64(6),
+						"ratio": float64(2),
+						"limit": uint64(10),
+						"ok":    true,
+						"state": "running",
+					},
+					time.Unix(70, 5),
+				),
+			},
+		},
+		{
+			name: "integer field with fraction",
+			source: `
+function apply(metric)
+  metric:set_field("count", metric:get_field("count") / 2)
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"count": int64(3)}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"count": float64(1.5)}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "drop",
+			source: `
+function apply(metric)
+  if metric:get_tag("host") == "a" then
+    return nil
+  end
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "multiple metrics",
+			source: `
+function apply(metric)
+  local copy = metric:copy()
+  copy:set_name("copy")
+  local new = telegraf.new_metric("new")
+  for key, value in pairs(metric:fields()) do
+    new:set_field(key, value, "int")
+  end
+  for key, value in pairs(metric:tags()) do
+    new:set_tag(key, value)
+  end
+  new:set_time(metric:time())
+  return {metric, copy, new, metric}
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(5, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(5, 0)),
+				metric.New("copy", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(5, 0)),
+				metric.New("new", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, time.Unix(5, 0)),
+			},
+		},
+		{
+			name: "error keeps metric",
+			source: `
+function apply(metric)
+  error("failed")
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "invalid return type",
+			source: `
+function apply(metric)
+  return 42
+end
+`,
+			input: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Lua{
+				Source: tt.source,
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			defer plugin.Stop()
+
+			actual := plugin.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestScriptWithModule(t *testing.T) {
+	plugin := &Lua{
+		Script:      "testdata/script.lua",
+		ModulePaths: []string{"testdata/modules"},
+		Constants:   map[string]interface{}{"unit": "celsius"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	input := metric.New("weather", nil, map[string]interface{}{"temp_f": 212.0}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New("weather", map[string]string{"unit": "celsius"}, map[string]interface{}{"temp_c": 100.0}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input))
+}
+
+func TestConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[processors.lua]]
+  source = '''
+function apply(metric)
+  for _, name in ipairs(names) do
+    metric:set_tag(name, tostring(limits[name]))
+  end
+  return metric
+end
+'''
+
+  [processors.lua.constants]
+    names = ["low", "high"]
+    limits = {low = 1, high = 2.5}
+`), config.EmptySourcePath))
+	require.Len(t, cfg.Processors, 1)
+
+	plugin := cfg.Processors[0].Processor.(processors.HasUnwrap).Unwrap().(*Lua)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	input := metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"low": "1", "high": "2.5"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input))
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("keep", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("drop", nil, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("copy", nil, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Lua{
+		Source: `
+function apply(metric)
+  if metric:name() == "drop" then
+    return nil
+  elseif metric:name() == "copy" then
+    return {metric:copy(), telegraf.new_metric("new")}
+  end
+  return metric
+end
+`,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 3)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Len(t, delivered, len(input))
+}
@@ -0,0 +1,280 @@
+package lua
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const metricTypeName = "telegraf.metric"
+
+var metricMethods = map[string]lua.LGFunction{
+	"name":         metricName,
+	"set_name":     metricSetName,
+	"time":         metricTime,
+	"set_time":     metricSetTime,
+	"get_tag":      metricGetTag,
+	"set_tag":      metricSetTag,
+	"remove_tag":   metricRemoveTag,
+	"tags":         metricTags,
+	"get_field":    metricGetField,
+	"set_field":    metricSetField,
+	"remove_field": metricRemoveField,
+	"fields":       metricFields,
+	"copy":         metricCopy,
+}
+
+func registerMetricType(state *lua.LState) {
+	mt := state.NewTypeMetatable(metricTypeName)
+	state.SetField(mt, "__index", state.SetFuncs(state.NewTable(), metricMethods))
+}
+
+// registerModule adds the global 'telegraf' table for creating metrics and
+// logging
+func registerModule(state *lua.LState, log telegraf.Logger) {
+	logFunc := func(fn func(...interface{})) lua.LGFunction {
+		return func(state *lua.LState) int {
+			args := make([]string, 0, state.GetTop())
+			for i := 1; i <= state.GetTop(); i++ {
+				args = append(args, state.ToStringMeta(state.Get(i)).String())
+			}
+			fn(strings.Join(args, " "))
+			return 0
+		}
+	}
+
+	module := state.SetFuncs(state.NewTable(), map[string]lua.LGFunction{
+		"new_metric": func(state *lua.LState) int {
+			name := state.CheckString(1)
+			state.Push(newMetric(state, metric.New(name, nil, nil, time.Now())))
+			return 1
+		},
+		"debug": logFunc(log.Debug),
+		"info":  logFunc(log.Info),
+		"warn":  logFunc(log.Warn),
+		"error": logFunc(log.Error),
+	})
+	state.SetGlobal("telegraf", module)
+}
+
+func newMetric(state *lua.LState, m telegraf.Metric) *lua.LUserData {
+	ud := state.NewUserData()
+	ud.Value = m
+	state.SetMetatable(ud, state.GetTypeMetatable(metricTypeName))
+	return ud
+}
+
+func checkMetric(v lua.LValue) (telegraf.Metric, error) {
+	if ud, ok := v.(*lua.LUserData); ok {
+		if m, ok := ud.Value.(telegraf.Metric); ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("expected metric but got %s", v.Type())
+}
+
+func argMetric(state *lua.LState) telegraf.Metric {
+	m, err := checkMetric(state.CheckUserData(1))
+	if err != nil {
+		state.ArgError(1, err.Error())
+	}
+	return m
+}
+
+func metricName(state *lua.LState) int {
+	state.Push(lua.LString(argMetric(state).Name()))
+	return 1
+}
+
+func metricSetName(state *lua.LState) int {
+	argMetric(state).SetName(state.CheckString(2))
+	return 0
+}
+
+// metricTime returns the seconds and nanoseconds of the timestamp separately
+// as Lua numbers cannot represent the timestamp in nanoseconds exactly
+func metricTime(state *lua.LState) int {
+	t := argMetric(state).Time()
+	state.Push(lua.LNumber(t.Unix()))
+	state.Push(lua.LNumber(t.Nanosecond()))
+	return 2
+}
+
+func metricSetTime(state *lua.LState) int {
+	m := argMetric(state)
+	m.SetTime(time.Unix(state.CheckInt64(2), state.OptInt64(3, 0)))
+	return 0
+}
+
+func metricGetTag(state *lua.LState) int {
+	if v, found := argMetric(state).GetTag(state.CheckString(2)); found {
+		state.Push(lua.LString(v))
+	} else {
+		state.Push(lua.LNil)
+	}
+	return 1
+}
+
+func metricSetTag(state *lua.LState) int {
+	argMetric(state).AddTag(state.CheckString(2), state.CheckString(3))
+	return 0
+}
+
+func metricRemoveTag(state *lua.LState) int {
+	argMetric(state).RemoveTag(state.CheckString(2))
+	return 0
+}
+
+func metricTags(state *lua.LState) int {
+	tags := state.NewTable()
+	for _, tag := range argMetric(state).TagList() {
+		tags.RawSetString(tag.Key, lua.LString(tag.Value))
+	}
+	state.Push(tags)
+	return 1
+}
+
+func metricGetField(state *lua.LState) int {
+	v, found := argMetric(state).GetField(state.CheckString(2))
+	if !found {
+		state.Push(lua.LNil)
+		return 1
+	}
+	state.Push(fieldToLua(v))
+	return 1
+}
+
+// metricSetField sets the field to the given value, the optional third
+// argument forces the type of numbers to "int", "uint" or "float". Without
+// type, whole numbers keep the integer type of an existing field.
+func metricSetField(state *lua.LState) int {
+	m := argMetric(state)
+	key := state.CheckString(2)
+	value := state.CheckAny(3)
+	typ := state.OptString(4, "")
+
+	var v interface{}
+	switch value := value.(type) {
+	case lua.LString:
+		v = string(value)
+	case lua.LBool:
+		v = bool(value)
+	case lua.LNumber:
+		if typ == "" {
+			if existing, found := m.GetField(key); found {
+				switch existing.(type) {
+				case int64:
+					typ = "int"
+				case uint64:
+					typ = "uint"
+				}
+			}
+			if f := float64(value); typ != "" && f != math.Trunc(f) {
+				typ = ""
+			}
+		}
+		var err error
+		if v, err = convertNumber(float64(value), typ); err != nil {
+			state.ArgError(4, err.Error())
+		}
+	default:
+		state.ArgError(3, "unsupported field type "+value.Type().String())
+	}
+	m.AddField(key, v)
+	return 0
+}
+
+func metricRemoveField(state *lua.LState) int {
+	argMetric(state).RemoveField(state.CheckString(2))
+	return 0
+}
+
+func metricFields(state *lua.LState) int {
+	fields := state.NewTable()
+	for _, field := range argMetric(state).FieldList() {
+		fields.RawSetString(field.Key, fieldToLua(field.Value))
+	}
+	state.Push(fields)
+	return 1
+}
+
+func metricCopy(state *lua.LState) int {
+	state.Push(newMetric(state, argMetric(state).Copy()))
+	return 1
+}
+
+func fieldToLua(v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case float64:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case uint64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case bool:
+		return lua.LBool(v)
+	default:
+		return lua.LNil
+	}
+}
+
+func convertNumber(v float64, typ string) (interface{}, error) {
+	switch typ {
+	case "", "float":
+		return v, nil
+	case "int":
+		return int64(v), nil
+	case "uint":
+		if v < 0 {
+			return nil, errors.New("negative value for unsigned integer")
+		}
+		return uint64(v), nil
+	default:
+		return nil, fmt.Errorf("invalid type %q", typ)
+	}
+}
+
+// toLuaValue converts the value of a constant
+func toLuaValue(state *lua.LState, value interface{}) (lua.LValue, error) {
+	switch v := value.(type) {
+	case string:
+		return lua.LString(v), nil
+	case bool:
+		return lua.LBool(v), nil
+	case int64:
+		return lua.LNumber(v), nil
+	case float64:
+		return lua.LNumber(v), nil
+	case []interface{}:
+		table := state.NewTable()
+		for i, elem := range v {
+			lv, err := toLuaValue(state, elem)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			table.Append(lv)
+		}
+		return table, nil
+	case map[string]interface{}:
+		table := state.NewTable()
+		for key, elem := range v {
+			lv, err := toLuaValue(state, elem)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			table.RawSetString(key, lv)
+		}
+		return table, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
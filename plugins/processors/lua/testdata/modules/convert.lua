@@ -0,0 +1,7 @@
+local convert = {}
+
+function convert.celsius(fahrenheit)
+  return (fahrenheit - 32) * 5 / 9
+end
+
+return convert
@@ -0,0 +1,12 @@
+local convert = require("convert")
+
+function apply(metric)
+  local temp = metric:get_field("temp_f")
+  if temp == nil then
+    return metric
+  end
+  metric:remove_field("temp_f")
+  metric:set_field("temp_c", convert.celsius(temp))
+  metric:set_tag("unit", unit)
+  return metric
+end
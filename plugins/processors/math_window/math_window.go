@@ -0,0 +1,258 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package math_window
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type MathWindow struct {
+	GroupBy        []string        `toml:"group_by"`
+	Window         config.Duration `toml:"window"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Computations   []computation   `toml:"computation"`
+	Log            telegraf.Logger `toml:"-"`
+
+	// operands received per computation and group
+	cache map[groupKey]*group
+	now   func() time.Time
+}
+
+type computation struct {
+	Name      string    `toml:"name"`
+	Field     string    `toml:"field"`
+	Operation string    `toml:"operation"`
+	Operands  []operand `toml:"operands"`
+}
+
+type operand struct {
+	Measurement string `toml:"measurement"`
+	Field       string `toml:"field"`
+}
+
+type groupKey struct {
+	computation int
+	tags        string
+}
+
+type group struct {
+	tags    map[string]string
+	values  []float64
+	times   []time.Time
+	present []bool
+	seen    time.Time
+}
+
+func (*MathWindow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *MathWindow) Init() error {
+	if len(p.Computations) == 0 {
+		return errors.New("no computation defined")
+	}
+	if p.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if p.ExpiryInterval <= 0 {
+		return errors.New("expiry interval must be positive")
+	}
+
+	for i := range p.Computations {
+		c := &p.Computations[i]
+		if c.Name == "" {
+			return fmt.Errorf("computation %d has no name", i+1)
+		}
+		if c.Field == "" {
+			c.Field = "value"
+		}
+		switch c.Operation {
+		case "ratio":
+			if len(c.Operands) != 2 {
+				return fmt.Errorf("computation %q requires exactly two operands", c.Name)
+			}
+		case "sum", "difference":
+			if len(c.Operands) < 2 {
+				return fmt.Errorf("computation %q requires at least two operands", c.Name)
+			}
+		default:
+			return fmt.Errorf("invalid operation %q of computation %q", c.Operation, c.Name)
+		}
+		for j, o := range c.Operands {
+			if o.Measurement == "" || o.Field == "" {
+				return fmt.Errorf("measurement and field required for operand %d of computation %q", j+1, c.Name)
+			}
+		}
+	}
+
+	p.cache = make(map[groupKey]*group)
+	if p.now == nil {
+		p.now = time.Now
+	}
+
+	return nil
+}
+
+func (p *MathWindow) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := p.now()
+
+	// Evict incomplete operand sets of vanished series
+	threshold := now.Add(-time.Duration(p.ExpiryInterval))
+	maps.DeleteFunc(p.cache, func(_ groupKey, g *group) bool {
+		return g.seen.Before(threshold)
+	})
+
+	out := in
+	for _, m := range in {
+		tags, key, ok := p.groupTags(m)
+		if !ok {
+			continue
+		}
+
+		for ci, c := range p.Computations {
+			for oi, o := range c.Operands {
+				if m.Name() != o.Measurement {
+					continue
+				}
+				raw, found := m.GetField(o.Field)
+				if !found {
+					continue
+				}
+				v, ok := toFloat(raw)
+				if !ok {
+					p.Log.Debugf("Ignoring non-numeric field %q of metric %q", o.Field, m.Name())
+					continue
+				}
+
+				gk := groupKey{computation: ci, tags: key}
+				g, found := p.cache[gk]
+				if !found {
+					g = &group{
+						tags:    tags,
+						values:  make([]float64, len(c.Operands)),
+						times:   make([]time.Time, len(c.Operands)),
+						present: make([]bool, len(c.Operands)),
+					}
+					p.cache[gk] = g
+				}
+				g.seen = now
+				p.set(g, oi, v, m.Time())
+
+				if derived := p.compute(c, g); derived != nil {
+					out = append(out, derived)
+					delete(p.cache, gk)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// groupTags returns the grouping tags of the metric and the key identifying
+// the group, false is returned if any of the tags is missing
+func (p *MathWindow) groupTags(m telegraf.Metric) (map[string]string, string, bool) {
+	tags := make(map[string]string, len(p.GroupBy))
+	var key strings.Builder
+	for _, k := range p.GroupBy {
+		v, found := m.GetTag(k)
+		if !found {
+			return nil, "", false
+		}
+		tags[k] = v
+		key.WriteString(v)
+		key.WriteByte(0)
+	}
+	return tags, key.String(), true
+}
+
+// set stores the operand value and discards operands not within the window of
+// the others, keeping the most recent ones
+func (p *MathWindow) set(g *group, idx int, v float64, ts time.Time) {
+	window := time.Duration(p.Window)
+	for i := range g.present {
+		if !g.present[i] || i == idx {
+			continue
+		}
+		switch {
+		case g.times[i].Before(ts.Add(-window)):
+			g.present[i] = false
+		case g.times[i].After(ts.Add(window)):
+			// The operand is outdated compared to the already received ones
+			return
+		}
+	}
+	g.values[idx] = v
+	g.times[idx] = ts
+	g.present[idx] = true
+}
+
+// compute returns the derived metric if all operands are present
+func (p *MathWindow) compute(c computation, g *group) telegraf.Metric {
+	var ts time.Time
+	for i, ok := range g.present {
+		if !ok {
+			return nil
+		}
+		if g.times[i].After(ts) {
+			ts = g.times[i]
+		}
+	}
+
+	var result float64
+	switch c.Operation {
+	case "ratio":
+		if g.values[1] == 0 {
+			p.Log.Debugf("Skipping computation %q due to division by zero", c.Name)
+			for i := range g.present {
+				g.present[i] = false
+			}
+			return nil
+		}
+		result = g.values[0] / g.values[1]
+	case "sum":
+		for _, v := range g.values {
+			result += v
+		}
+	case "difference":
+		result = g.values[0]
+		for _, v := range g.values[1:] {
+			result -= v
+		}
+	}
+
+	return metric.New(c.Name, maps.Clone(g.tags), map[string]interface{}{c.Field: result}, ts)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("math_window", func() telegraf.Processor {
+		return &MathWindow{
+			Window:         config.Duration(10 * time.Second),
+			ExpiryInterval: config.Duration(time.Minute),
+		}
+	})
+}
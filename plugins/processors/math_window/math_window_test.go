@@ -0,0 +1,269 @@
+package math_window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name         string
+		computations []computation
+		expected     string
+	}{
+		{
+			name:     "no computation",
+			expected: "no computation defined",
+		},
+		{
+			name:         "no name",
+			computations: []computation{{Operation: "sum"}},
+			expected:     "computation 1 has no name",
+		},
+		{
+			name:         "invalid operation",
+			computations: []computation{{Name: "a", Operation: "median"}},
+			expected:     `invalid operation "median" of computation "a"`,
+		},
+		{
+			name: "ratio with three operands",
+			computations: []computation{{
+				Name:      "a",
+				Operation: "ratio",
+				Operands:  []operand{{"m", "a"}, {"m", "b"}, {"m", "c"}},
+			}},
+			expected: `computation "a" requires exactly two operands`,
+		},
+		{
+			name: "sum with one operand",
+			computations: []computation{{
+				Name:      "a",
+				Operation: "sum",
+				Operands:  []operand{{"m", "a"}},
+			}},
+			expected: `computation "a" requires at least two operands`,
+		},
+		{
+			name: "operand without field",
+			computations: []computation{{
+				Name:      "a",
+				Operation: "sum",
+				Operands:  []operand{{"m", "a"}, {Measurement: "m"}},
+			}},
+			expected: `measurement and field required for operand 2 of computation "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &MathWindow{
+				Window:         config.Duration(10 * time.Second),
+				ExpiryInterval: config.Duration(time.Minute),
+				Computations:   tt.computations,
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSampleConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(sampleConfig), config.EmptySourcePath))
+	require.Len(t, cfg.Processors, 1)
+
+	plugin := cfg.Processors[0].Processor.(processors.HasUnwrap).Unwrap().(*MathWindow)
+	require.NoError(t, plugin.Init())
+	expected := []computation{{
+		Name:      "http_error_ratio",
+		Field:     "value",
+		Operation: "ratio",
+		Operands:  []operand{{"http_errors", "count"}, {"http_requests", "count"}},
+	}}
+	require.Equal(t, expected, plugin.Computations)
+}
+
+func TestRatio(t *testing.T) {
+	plugin := &MathWindow{
+		GroupBy:        []string{"host"},
+		Window:         config.Duration(10 * time.Second),
+		ExpiryInterval: config.Duration(time.Minute),
+		Computations: []computation{{
+			Name:      "http_error_ratio",
+			Operation: "ratio",
+			Operands:  []operand{{"http_errors", "count"}, {"http_requests", "count"}},
+		}},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("http_errors", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(5)}, time.Unix(100, 0)),
+		metric.New("http_errors", map[string]string{"host": "b"}, map[string]interface{}{"count": int64(1)}, time.Unix(100, 0)),
+		metric.New("http_requests", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(200)}, time.Unix(102, 0)),
+		metric.New("http_requests", map[string]string{"host": "c"}, map[string]interface{}{"count": int64(10)}, time.Unix(102, 0)),
+		metric.New("http_requests", map[string]string{}, map[string]interface{}{"count": int64(10)}, time.Unix(102, 0)),
+		// Operand outside of the window replacing the previous value
+		metric.New("http_requests", map[string]string{"host": "b"}, map[string]interface{}{"count": uint64(20)}, time.Unix(111, 0)),
+	}
+	expected := make([]telegraf.Metric, 0, len(input)+1)
+	for _, m := range input {
+		expected = append(expected, m.Copy())
+	}
+	expected = append(expected, metric.New(
+		"http_error_ratio",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": 0.025},
+		time.Unix(102, 0),
+	))
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The derived metric is emitted once the missing operand arrives
+	input = []telegraf.Metric{
+		metric.New("http_errors", map[string]string{"host": "b"}, map[string]interface{}{"count": 2.0}, time.Unix(112, 0)),
+	}
+	expected = []telegraf.Metric{
+		input[0].Copy(),
+		metric.New(
+			"http_error_ratio",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"value": 0.1},
+			time.Unix(112, 0),
+		),
+	}
+	actual = plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSameMetric(t *testing.T) {
+	plugin := &MathWindow{
+		Window:         config.Duration(10 * time.Second),
+		ExpiryInterval: config.Duration(time.Minute),
+		Computations: []computation{
+			{
+				Name:      "mem_used_other",
+				Field:     "bytes",
+				Operation: "difference",
+				Operands:  []operand{{"mem", "used"}, {"mem", "buffered"}, {"mem", "cached"}},
+			},
+			{
+				Name:      "disk_io_total",
+				Operation: "sum",
+				Operands:  []operand{{"diskio", "read_bytes"}, {"diskio", "write_bytes"}},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(1000), "buffered": int64(100), "cached": int64(300)},
+			time.Unix(100, 0),
+		),
+		metric.New(
+			"diskio",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"read_bytes": uint64(10), "write_bytes": uint64(5), "name": "sda"},
+			time.Unix(100, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		input[0].Copy(),
+		input[1].Copy(),
+		metric.New("mem_used_other", map[string]string{}, map[string]interface{}{"bytes": float64(600)}, time.Unix(100, 0)),
+		metric.New("disk_io_total", map[string]string{}, map[string]interface{}{"value": float64(15)}, time.Unix(100, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestDivisionByZero(t *testing.T) {
+	plugin := &MathWindow{
+		Window:         config.Duration(10 * time.Second),
+		ExpiryInterval: config.Duration(time.Minute),
+		Computations: []computation{{
+			Name:      "ratio",
+			Operation: "ratio",
+			Operands:  []operand{{"m", "a"}, {"m", "b"}},
+		}},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("m", nil, map[string]interface{}{"a": int64(1), "b": int64(0)}, time.Unix(100, 0))
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input.Copy()}, actual)
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	plugin := &MathWindow{
+		Window:         config.Duration(10 * time.Second),
+		ExpiryInterval: config.Duration(time.Minute),
+		Computations: []computation{{
+			Name:      "sum",
+			Operation: "sum",
+			Operands:  []operand{{"a", "value"}, {"b", "value"}},
+		}},
+		Log: testutil.Logger{},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("a", nil, map[string]interface{}{"value": 1}, time.Unix(100, 0)))
+	require.Len(t, plugin.cache, 1)
+
+	// The incomplete set is evicted after the expiry interval
+	now = now.Add(2 * time.Minute)
+	actual := plugin.Apply(metric.New("b", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(105, 0)))
+	require.Len(t, actual, 1)
+	require.Len(t, plugin.cache, 1)
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"value": 1}, time.Unix(100, 0)),
+		metric.New("b", nil, map[string]interface{}{"value": 2}, time.Unix(100, 0)),
+	}
+
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &MathWindow{
+		Window:         config.Duration(10 * time.Second),
+		ExpiryInterval: config.Duration(time.Minute),
+		Computations: []computation{{
+			Name:      "sum",
+			Operation: "sum",
+			Operands:  []operand{{"a", "value"}, {"b", "value"}},
+		}},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 3)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Len(t, delivered, len(input))
+}
@@ -0,0 +1,193 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package moving_average
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type MovingAverage struct {
+	Fields         []string        `toml:"fields"`
+	Method         string          `toml:"method"`
+	WindowSize     int             `toml:"window_size"`
+	Alpha          float64         `toml:"alpha"`
+	Suffix         string          `toml:"suffix"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	cache  map[uint64]*entry
+}
+
+type entry struct {
+	averages map[string]average
+	seen     time.Time
+}
+
+// average accumulates the values of a field and returns the current average
+type average interface {
+	add(v float64) float64
+}
+
+func (*MovingAverage) SampleConfig() string {
+	return sampleConfig
+}
+
+func (ma *MovingAverage) Init() error {
+	if len(ma.Fields) == 0 {
+		ma.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(ma.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	ma.accept = f
+
+	if ma.WindowSize < 1 {
+		return errors.New("window size must be at least one")
+	}
+	switch ma.Method {
+	case "", "simple":
+		ma.Method = "simple"
+		if ma.Suffix == "" {
+			ma.Suffix = "_sma"
+		}
+	case "exponential":
+		if ma.Alpha < 0 || ma.Alpha > 1 {
+			return fmt.Errorf("invalid alpha %v, has to be in the range (0, 1]", ma.Alpha)
+		}
+		// Derive the smoothing factor from the window size by default
+		if ma.Alpha == 0 {
+			ma.Alpha = 2.0 / float64(ma.WindowSize+1)
+		}
+		if ma.Suffix == "" {
+			ma.Suffix = "_ewma"
+		}
+	default:
+		return fmt.Errorf("invalid method %q", ma.Method)
+	}
+
+	ma.cache = make(map[uint64]*entry)
+
+	return nil
+}
+
+func (ma *MovingAverage) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	for _, m := range in {
+		id := m.HashID()
+		// Create a new entry for unseen metrics
+		stored, ok := ma.cache[id]
+		if !ok {
+			stored = &entry{averages: make(map[string]average)}
+		}
+
+		averages := make(map[string]interface{})
+		for _, field := range m.FieldList() {
+			if !ma.accept.Match(field.Key) {
+				continue
+			}
+
+			// Ignore all fields not convertible to float
+			fv, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				ma.Log.Tracef("Skipping field %q with value %v (%T) as it is not convertible to float: %v", field.Key, field.Value, field.Value, err)
+				continue
+			}
+
+			avg, found := stored.averages[field.Key]
+			if !found {
+				avg = ma.newAverage()
+				stored.averages[field.Key] = avg
+			}
+			averages[field.Key+ma.Suffix] = avg.add(fv)
+		}
+
+		// Modify the fields after iterating them to not invalidate the field list
+		for key, value := range averages {
+			m.AddField(key, value)
+		}
+		stored.seen = now
+		ma.cache[id] = stored
+	}
+
+	// Cleanup cache entries that are too old
+	if ma.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(ma.ExpiryInterval))
+		maps.DeleteFunc(ma.cache, func(_ uint64, e *entry) bool {
+			return e.seen.Before(threshold)
+		})
+	}
+
+	return in
+}
+
+func (ma *MovingAverage) newAverage() average {
+	if ma.Method == "exponential" {
+		return &exponentialAverage{alpha: ma.Alpha}
+	}
+	return &simpleAverage{values: make([]float64, 0, ma.WindowSize)}
+}
+
+// simpleAverage is the arithmetic mean of the last values within the window,
+// using all values received until the window is filled
+type simpleAverage struct {
+	values []float64
+	next   int
+}
+
+func (a *simpleAverage) add(v float64) float64 {
+	if len(a.values) < cap(a.values) {
+		a.values = append(a.values, v)
+	} else {
+		a.values[a.next] = v
+		a.next = (a.next + 1) % len(a.values)
+	}
+
+	// Sum up the values instead of keeping a running sum to avoid
+	// accumulating rounding errors
+	var sum float64
+	for _, x := range a.values {
+		sum += x
+	}
+	return sum / float64(len(a.values))
+}
+
+// exponentialAverage is the exponentially weighted moving average starting
+// with the first value received
+type exponentialAverage struct {
+	alpha   float64
+	value   float64
+	started bool
+}
+
+func (a *exponentialAverage) add(v float64) float64 {
+	if !a.started {
+		a.value = v
+		a.started = true
+		return a.value
+	}
+	a.value = a.alpha*v + (1-a.alpha)*a.value
+	return a.value
+}
+
+func init() {
+	processors.Add("moving_average", func() telegraf.Processor {
+		return &MovingAverage{
+			WindowSize: 10,
+		}
+	})
+}
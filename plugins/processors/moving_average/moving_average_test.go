@@ -0,0 +1,154 @@
+package moving_average
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &MovingAverage{}
+	require.ErrorContains(t, plugin.Init(), "window size must be at least one")
+
+	plugin = &MovingAverage{WindowSize: 3, Method: "median"}
+	require.ErrorContains(t, plugin.Init(), `invalid method "median"`)
+
+	plugin = &MovingAverage{WindowSize: 3, Method: "exponential", Alpha: 1.5}
+	require.ErrorContains(t, plugin.Init(), "invalid alpha 1.5")
+}
+
+func TestSimple(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	values := []float64{20, 23, 20, 29, 30}
+	expectedAverages := []float64{20, 21.5, 21, 24, 26.333333333333332}
+
+	input := make([]telegraf.Metric, 0, len(values)+1)
+	expected := make([]telegraf.Metric, 0, len(values)+1)
+	for i, v := range values {
+		ts := now.Add(time.Duration(i) * 10 * time.Second)
+		input = append(input, metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{"temperature": v, "state": "ok"},
+			ts,
+		))
+		expected = append(expected, metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{"temperature": v, "temperature_sma": expectedAverages[i], "state": "ok"},
+			ts,
+		))
+	}
+	// Other series are averaged independently
+	input = append(input, metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"temperature": int64(5)}, now))
+	expected = append(expected, metric.New(
+		"sensor",
+		map[string]string{"id": "2"},
+		map[string]interface{}{"temperature": int64(5), "temperature_sma": float64(5)},
+		now,
+	))
+
+	plugin := &MovingAverage{
+		WindowSize: 3,
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestExponential(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0, "other": 1.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 20.0, "other": 1.0}, now.Add(time.Second)),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 20.0, "other": 1.0}, now.Add(2*time.Second)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0, "value_smooth": 10.0, "other": 1.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 20.0, "value_smooth": 15.0, "other": 1.0}, now.Add(time.Second)),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 20.0, "value_smooth": 17.5, "other": 1.0}, now.Add(2*time.Second)),
+	}
+
+	plugin := &MovingAverage{
+		Fields:     []string{"value"},
+		Method:     "exponential",
+		WindowSize: 10,
+		Alpha:      0.5,
+		Suffix:     "_smooth",
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestExponentialDefaultAlpha(t *testing.T) {
+	plugin := &MovingAverage{
+		Method:     "exponential",
+		WindowSize: 9,
+	}
+	require.NoError(t, plugin.Init())
+	require.InDelta(t, 0.2, plugin.Alpha, 1e-12)
+	require.Equal(t, "_ewma", plugin.Suffix)
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &MovingAverage{
+		WindowSize:     3,
+		ExpiryInterval: config.Duration(10 * time.Millisecond),
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, time.Unix(0, 0))
+	plugin.Apply(m.Copy())
+	require.Len(t, plugin.cache, 1)
+
+	time.Sleep(20 * time.Millisecond)
+	other := metric.New("other", map[string]string{}, map[string]interface{}{"value": 20.0}, time.Unix(0, 0))
+	plugin.Apply(other)
+	require.Len(t, plugin.cache, 1)
+
+	// The series restarts after being evicted
+	actual := plugin.Apply(metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 30.0}, time.Unix(0, 0)))
+	require.Len(t, actual, 1)
+	v, found := actual[0].GetField("value_sma")
+	require.True(t, found)
+	require.InDelta(t, 30.0, v, 1e-12)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": float64(i)}, time.Unix(int64(i), 0))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &MovingAverage{
+		WindowSize: 3,
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, m := range plugin.Apply(input...) {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		return delivered == 3
+	}, time.Second, 100*time.Millisecond)
+}
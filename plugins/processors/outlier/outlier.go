@@ -0,0 +1,295 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package outlier
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Scale factor to make the median absolute deviation a consistent estimator
+// of the standard deviation for normally distributed data
+const madScale = 1.4826
+
+type Outlier struct {
+	Fields         []string        `toml:"fields"`
+	Method         string          `toml:"method"`
+	Threshold      float64         `toml:"threshold"`
+	WindowSize     int             `toml:"window_size"`
+	MinSamples     int             `toml:"min_samples"`
+	Action         string          `toml:"action"`
+	Tag            string          `toml:"tag"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	cache  map[uint64]*entry
+}
+
+type entry struct {
+	baselines map[string]*baseline
+	seen      time.Time
+}
+
+func (*Outlier) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *Outlier) Init() error {
+	if len(o.Fields) == 0 {
+		o.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(o.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	o.accept = f
+
+	switch o.Method {
+	case "":
+		o.Method = "stddev"
+	case "stddev", "mad":
+	default:
+		return fmt.Errorf("invalid method %q", o.Method)
+	}
+
+	switch o.Action {
+	case "":
+		o.Action = "drop"
+	case "drop", "clamp":
+	case "tag":
+		if o.Tag == "" {
+			return errors.New("tag name required for action \"tag\"")
+		}
+	default:
+		return fmt.Errorf("invalid action %q", o.Action)
+	}
+
+	if o.Threshold <= 0 {
+		return errors.New("threshold must be positive")
+	}
+	if o.WindowSize < 2 {
+		return errors.New("window size must be at least two")
+	}
+	if o.MinSamples < 2 || o.MinSamples > o.WindowSize {
+		return fmt.Errorf("minimum samples must be between two and the window size %d", o.WindowSize)
+	}
+
+	o.cache = make(map[uint64]*entry)
+
+	return nil
+}
+
+func (o *Outlier) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		id := m.HashID()
+		// Create a new entry for unseen metrics
+		stored, ok := o.cache[id]
+		if !ok {
+			stored = &entry{baselines: make(map[string]*baseline)}
+		}
+
+		// Check the fields for outliers and collect the modifications to
+		// not invalidate the field list while iterating
+		var outliers []string
+		clamped := make(map[string]interface{})
+		for _, field := range m.FieldList() {
+			if !o.accept.Match(field.Key) {
+				continue
+			}
+
+			// Only consider numerical fields as clamping would otherwise
+			// change the field type
+			var v float64
+			switch fv := field.Value.(type) {
+			case float64:
+				v = fv
+			case int64:
+				v = float64(fv)
+			case uint64:
+				v = float64(fv)
+			default:
+				o.Log.Tracef("Skipping non-numerical field %q with value %v (%T)", field.Key, field.Value, field.Value)
+				continue
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			b, found := stored.baselines[field.Key]
+			if !found {
+				b = &baseline{values: make([]float64, 0, o.WindowSize)}
+				stored.baselines[field.Key] = b
+			}
+
+			// Outliers enter the baseline with their clamped value to limit
+			// their influence while still allowing the baseline to follow
+			// lasting changes of the level
+			limited, outlier := o.check(b, v)
+			b.add(limited)
+			if !outlier {
+				continue
+			}
+			o.Log.Debugf("Field %q of %q with value %v is an outlier", field.Key, m.Name(), field.Value)
+			outliers = append(outliers, field.Key)
+			clamped[field.Key] = convert(limited, field.Value)
+		}
+		stored.seen = now
+		o.cache[id] = stored
+
+		if len(outliers) == 0 {
+			out = append(out, m)
+			continue
+		}
+
+		switch o.Action {
+		case "drop":
+			for _, key := range outliers {
+				m.RemoveField(key)
+			}
+			if len(m.FieldList()) == 0 {
+				m.Drop()
+				continue
+			}
+		case "clamp":
+			for key, value := range clamped {
+				m.AddField(key, value)
+			}
+		case "tag":
+			slices.Sort(outliers)
+			m.AddTag(o.Tag, strings.Join(outliers, ","))
+		}
+		out = append(out, m)
+	}
+
+	// Cleanup cache entries that are too old
+	if o.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(o.ExpiryInterval))
+		maps.DeleteFunc(o.cache, func(_ uint64, e *entry) bool {
+			return e.seen.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// check returns the value limited to the maximum allowed deviation from the
+// baseline and whether the value is an outlier
+func (o *Outlier) check(b *baseline, v float64) (float64, bool) {
+	if len(b.values) < o.MinSamples {
+		return v, false
+	}
+
+	var center, spread float64
+	switch o.Method {
+	case "stddev":
+		center, spread = b.meanStddev()
+	case "mad":
+		center, spread = b.medianMAD()
+		spread *= madScale
+	}
+
+	// Without any spread in the baseline every deviation would be infinitely
+	// large, so we cannot judge the value
+	if spread == 0 {
+		return v, false
+	}
+
+	lower := center - o.Threshold*spread
+	upper := center + o.Threshold*spread
+	switch {
+	case v < lower:
+		return lower, true
+	case v > upper:
+		return upper, true
+	}
+	return v, false
+}
+
+// convert returns the value in the type of the original field value
+func convert(v float64, original interface{}) interface{} {
+	switch original.(type) {
+	case int64:
+		return int64(math.Round(v))
+	case uint64:
+		return uint64(math.Round(math.Max(v, 0)))
+	}
+	return v
+}
+
+// baseline holds the last values of a field in a ring buffer
+type baseline struct {
+	values []float64
+	next   int
+}
+
+func (b *baseline) add(v float64) {
+	if len(b.values) < cap(b.values) {
+		b.values = append(b.values, v)
+		return
+	}
+	b.values[b.next] = v
+	b.next = (b.next + 1) % len(b.values)
+}
+
+func (b *baseline) meanStddev() (mean, stddev float64) {
+	for _, v := range b.values {
+		mean += v
+	}
+	mean /= float64(len(b.values))
+
+	var variance float64
+	for _, v := range b.values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(b.values))
+
+	return mean, math.Sqrt(variance)
+}
+
+func (b *baseline) medianMAD() (median, mad float64) {
+	sorted := slices.Clone(b.values)
+	median = medianOf(sorted)
+
+	for i, v := range b.values {
+		sorted[i] = math.Abs(v - median)
+	}
+	return median, medianOf(sorted)
+}
+
+// medianOf returns the median of the values, sorting the given slice in place
+func medianOf(values []float64) float64 {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+func init() {
+	processors.Add("outlier", func() telegraf.Processor {
+		return &Outlier{
+			Threshold:  3.0,
+			WindowSize: 30,
+			MinSamples: 10,
+			Tag:        "outlier",
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package outlier
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Outlier
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			plugin:   &Outlier{Method: "iqr", Threshold: 3, WindowSize: 10, MinSamples: 5},
+			expected: `invalid method "iqr"`,
+		},
+		{
+			name:     "invalid action",
+			plugin:   &Outlier{Action: "replace", Threshold: 3, WindowSize: 10, MinSamples: 5},
+			expected: `invalid action "replace"`,
+		},
+		{
+			name:     "missing tag",
+			plugin:   &Outlier{Action: "tag", Threshold: 3, WindowSize: 10, MinSamples: 5},
+			expected: "tag name required",
+		},
+		{
+			name:     "invalid threshold",
+			plugin:   &Outlier{WindowSize: 10, MinSamples: 5},
+			expected: "threshold must be positive",
+		},
+		{
+			name:     "invalid window size",
+			plugin:   &Outlier{Threshold: 3, WindowSize: 1, MinSamples: 1},
+			expected: "window size must be at least two",
+		},
+		{
+			name:     "min samples exceeding window",
+			plugin:   &Outlier{Threshold: 3, WindowSize: 10, MinSamples: 20},
+			expected: "minimum samples must be between two and the window size 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	baseline := []int64{10, 11, 9, 10, 10}
+
+	tests := []struct {
+		name     string
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "drop",
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"state": "ok"}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(11), "state": "ok"}, now),
+			},
+		},
+		{
+			name:   "clamp",
+			action: "clamp",
+			expected: []telegraf.Metric{
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(12), "state": "ok"}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(7)}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(11), "state": "ok"}, now),
+			},
+		},
+		{
+			name:   "tag",
+			action: "tag",
+			expected: []telegraf.Metric{
+				metric.New("sensor", map[string]string{"id": "1", "outlier": "value"}, map[string]interface{}{"value": int64(50), "state": "ok"}, now),
+				metric.New("sensor", map[string]string{"id": "1", "outlier": "value"}, map[string]interface{}{"value": int64(-30)}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(11), "state": "ok"}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Outlier{
+				Action:     tt.action,
+				Threshold:  3,
+				WindowSize: 5,
+				MinSamples: 5,
+				Tag:        "outlier",
+				Log:        &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			// Fill the baseline, those metrics must be passed unmodified
+			input := make([]telegraf.Metric, 0, len(baseline))
+			for _, v := range baseline {
+				input = append(input, metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": v}, now))
+			}
+			testutil.RequireMetricsEqual(t, input, plugin.Apply(input...))
+
+			input = []telegraf.Metric{
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(50), "state": "ok"}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(-30)}, now),
+				metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": int64(11), "state": "ok"}, now),
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, plugin.Apply(input...))
+		})
+	}
+}
+
+func TestMethods(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	baseline := []float64{10, 12, 9, 11, 10}
+
+	// The deviation of 14 exceeds three standard deviations but not three
+	// scaled median absolute deviations while 20 is an outlier for both
+	tests := []struct {
+		name     string
+		method   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "stddev",
+			method: "stddev",
+			expected: []telegraf.Metric{
+				metric.New("sensor", map[string]string{"outlier": "value"}, map[string]interface{}{"value": 14.0}, now),
+				metric.New("sensor", map[string]string{"outlier": "value"}, map[string]interface{}{"value": 20.0}, now),
+			},
+		},
+		{
+			name:   "mad",
+			method: "mad",
+			expected: []telegraf.Metric{
+				metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 14.0}, now),
+				metric.New("sensor", map[string]string{"outlier": "value"}, map[string]interface{}{"value": 20.0}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Outlier{
+				Method:     tt.method,
+				Action:     "tag",
+				Threshold:  3,
+				WindowSize: 10,
+				MinSamples: 5,
+				Tag:        "outlier",
+				Log:        &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			for _, v := range baseline {
+				plugin.Apply(metric.New("sensor", map[string]string{}, map[string]interface{}{"value": v}, now))
+			}
+
+			input := []telegraf.Metric{
+				metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 14.0}, now),
+				metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 20.0}, now),
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, plugin.Apply(input...))
+		})
+	}
+}
+
+func TestNoSpread(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &Outlier{
+		Action:     "drop",
+		Threshold:  3,
+		WindowSize: 5,
+		MinSamples: 2,
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// A constant baseline does not allow to judge deviations so all metrics
+	// are passed
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 100.0}, now),
+	}
+	testutil.RequireMetricsEqual(t, input, plugin.Apply(input...))
+}
+
+func TestFieldsAndSeries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &Outlier{
+		Fields:     []string{"value"},
+		Action:     "drop",
+		Threshold:  3,
+		WindowSize: 5,
+		MinSamples: 3,
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, v := range []float64{1, 2, 3} {
+		plugin.Apply(
+			metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": v, "other": v}, now),
+			metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"value": 100 * v}, now),
+		)
+	}
+
+	// Fields not selected are not checked and each series uses its own
+	// baseline
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": 200.0, "other": 200.0}, now),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"value": 200.0}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"other": 200.0}, now),
+		metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"value": 200.0}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Now()
+
+	plugin := &Outlier{
+		Threshold:      3,
+		WindowSize:     5,
+		MinSamples:     2,
+		ExpiryInterval: config.Duration(time.Hour),
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("sensor", map[string]string{"id": "1"}, map[string]interface{}{"value": 1.0}, now))
+	require.Len(t, plugin.cache, 1)
+
+	// Simulate the series not being seen for longer than the expiry interval
+	for _, e := range plugin.cache {
+		e.seen = now.Add(-2 * time.Hour)
+	}
+	plugin.Apply(metric.New("sensor", map[string]string{"id": "2"}, map[string]interface{}{"value": 1.0}, now))
+	require.Len(t, plugin.cache, 1)
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	inputRaw := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 11.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 9.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 100.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 11.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 9.0}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 10.0}, now),
+	}
+
+	plugin := &Outlier{
+		Action:     "drop",
+		Threshold:  3,
+		WindowSize: 5,
+		MinSamples: 3,
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
+}
@@ -23,8 +23,21 @@ type Parser struct {
 	ParseFields  []string        `toml:"parse_fields"`
 	Base64Fields []string        `toml:"parse_fields_base64"`
 	ParseTags    []string        `toml:"parse_tags"`
+	FieldParsers []*fieldParser  `toml:"field_parser"`
 	Log          telegraf.Logger `toml:"-"`
 	parser       telegraf.Parser
+
+	tableParsers []telegraf.Parser
+	fieldParsers map[string]*fieldParser
+}
+
+// fieldParser parses a single field using a dedicated parser configured in
+// the same table
+type fieldParser struct {
+	Field  string `toml:"field"`
+	Base64 bool   `toml:"base64"`
+
+	parser telegraf.Parser
 }
 
 func (*Parser) SampleConfig() string {
@@ -38,6 +51,24 @@ func (p *Parser) Init() error {
 		return fmt.Errorf("unrecognized merge value: %s", p.Merge)
 	}
 
+	if len(p.FieldParsers) != len(p.tableParsers) {
+		return fmt.Errorf("expected %d field parsers but got %d", len(p.FieldParsers), len(p.tableParsers))
+	}
+	p.fieldParsers = make(map[string]*fieldParser, len(p.FieldParsers))
+	for i, fp := range p.FieldParsers {
+		if fp.Field == "" {
+			return fmt.Errorf("field required for field parser %d", i+1)
+		}
+		if _, found := p.fieldParsers[fp.Field]; found {
+			return fmt.Errorf("duplicate field parser for field %q", fp.Field)
+		}
+		if slices.Contains(p.ParseFields, fp.Field) || slices.Contains(p.Base64Fields, fp.Field) {
+			return fmt.Errorf("field %q is listed in both parse fields and field parsers", fp.Field)
+		}
+		fp.parser = p.tableParsers[i]
+		p.fieldParsers[fp.Field] = fp
+	}
+
 	return nil
 }
 
@@ -45,6 +76,14 @@ func (p *Parser) SetParser(parser telegraf.Parser) {
 	p.parser = parser
 }
 
+func (*Parser) ParserTables() string {
+	return "field_parser"
+}
+
+func (p *Parser) SetParserTables(parsers []telegraf.Parser) {
+	p.tableParsers = parsers
+}
+
 func (p *Parser) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 	results := make([]telegraf.Metric, 0, len(metrics))
 	for _, metric := range metrics {
@@ -59,8 +98,9 @@ func (p *Parser) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 		for _, field := range metric.FieldList() {
 			plain := slices.Contains(p.ParseFields, field.Key)
 			b64 := slices.Contains(p.Base64Fields, field.Key)
+			fp := p.fieldParsers[field.Key]
 
-			if !plain && !b64 {
+			if !plain && !b64 && fp == nil {
 				continue
 			}
 
@@ -69,6 +109,12 @@ func (p *Parser) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 				continue
 			}
 
+			parser := p.parser
+			if fp != nil {
+				parser = fp.parser
+				b64 = fp.Base64
+			}
+
 			value, err := toBytes(field.Value)
 			if err != nil {
 				p.Log.Errorf("could not convert field %s: %v; skipping", field.Key, err)
@@ -85,7 +131,7 @@ func (p *Parser) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 				value = decoded[:n]
 			}
 
-			fromFieldMetric, err := p.parser.Parse(value)
+			fromFieldMetric, err := parser.Parse(value)
 			if err != nil {
 				p.Log.Errorf("could not parse field %s: %v", field.Key, err)
 				continue
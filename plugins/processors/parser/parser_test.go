@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/binary"
@@ -16,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/logfmt"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
+	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -942,6 +944,113 @@ func TestBase64FieldValidation(t *testing.T) {
 	require.NotEmpty(t, testLogger.Errors())
 }
 
+func TestFieldParsers(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[processors.parser]]
+  merge = "override"
+
+  [[processors.parser.field_parser]]
+    field = "payload"
+    data_format = "json"
+    json_query = "data"
+    tag_keys = ["device"]
+
+  [[processors.parser.field_parser]]
+    field = "message"
+    base64 = true
+    data_format = "logfmt"
+`), config.EmptySourcePath))
+	require.Len(t, cfg.Processors, 1)
+
+	plugin := cfg.Processors[0].Processor.(processors.HasUnwrap).Unwrap().(*Parser)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.FieldParsers, 2)
+
+	input := metric.New(
+		"event",
+		map[string]string{},
+		map[string]interface{}{
+			"payload": `{"data": {"device": "s1", "temperature": 21.5}}`,
+			// level=info duration=42
+			"message": "bGV2ZWw9aW5mbyBkdXJhdGlvbj00Mg==",
+		},
+		time.Unix(0, 0),
+	)
+	expected := []telegraf.Metric{
+		metric.New(
+			"event",
+			map[string]string{"device": "s1"},
+			map[string]interface{}{
+				"payload":     `{"data": {"device": "s1", "temperature": 21.5}}`,
+				"message":     "bGV2ZWw9aW5mbyBkdXJhdGlvbj00Mg==",
+				"temperature": 21.5,
+				"level":       "info",
+				"duration":    int64(42),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestFieldParsersUnknownOption(t *testing.T) {
+	cfg := config.NewConfig()
+	err := cfg.LoadConfigData([]byte(`
+[[processors.parser]]
+  [[processors.parser.field_parser]]
+    field = "payload"
+    data_format = "json"
+    json_qeury = "data"
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, "json_qeury")
+}
+
+func TestFieldParsersInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Parser
+		parsers  []telegraf.Parser
+		expected string
+	}{
+		{
+			name:     "missing parser",
+			plugin:   &Parser{FieldParsers: []*fieldParser{{Field: "a"}}},
+			expected: "expected 1 field parsers but got 0",
+		},
+		{
+			name:     "no field",
+			plugin:   &Parser{FieldParsers: []*fieldParser{{}}},
+			parsers:  []telegraf.Parser{&json.Parser{}},
+			expected: "field required for field parser 1",
+		},
+		{
+			name:     "duplicate field",
+			plugin:   &Parser{FieldParsers: []*fieldParser{{Field: "a"}, {Field: "a"}}},
+			parsers:  []telegraf.Parser{&json.Parser{}, &json.Parser{}},
+			expected: `duplicate field parser for field "a"`,
+		},
+		{
+			name: "field in parse fields",
+			plugin: &Parser{
+				ParseFields:  []string{"a"},
+				FieldParsers: []*fieldParser{{Field: "a"}},
+			},
+			parsers:  []telegraf.Parser{&json.Parser{}},
+			expected: `field "a" is listed in both parse fields and field parsers`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.SetParserTables(tt.parsers)
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
 func TestTracking(t *testing.T) {
 	var testCases = []struct {
 		name       string
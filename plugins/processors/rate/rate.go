@@ -0,0 +1,165 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rate struct {
+	Fields         []string        `toml:"fields"`
+	Suffix         string          `toml:"suffix"`
+	DropOriginal   bool            `toml:"drop_original"`
+	CounterMax     float64         `toml:"counter_max"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	cache  map[uint64]*entry
+}
+
+type entry struct {
+	samples map[string]sample
+	seen    time.Time
+}
+
+type sample struct {
+	value     float64
+	timestamp time.Time
+}
+
+func (*Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Init() error {
+	if len(r.Fields) == 0 {
+		r.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(r.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	r.accept = f
+
+	if r.Suffix == "" && !r.DropOriginal {
+		return fmt.Errorf("empty suffix requires %q to be set", "drop_original")
+	}
+	if r.CounterMax < 0 {
+		return fmt.Errorf("invalid counter maximum %v", r.CounterMax)
+	}
+
+	r.cache = make(map[uint64]*entry)
+
+	return nil
+}
+
+func (r *Rate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		id := m.HashID()
+		// Create a new entry for unseen metrics
+		stored, ok := r.cache[id]
+		if !ok {
+			stored = &entry{samples: make(map[string]sample)}
+		}
+
+		rates := make(map[string]interface{})
+		var counters []string
+		for _, field := range m.FieldList() {
+			// Ignore all non-counter fields and keep them
+			if !r.accept.Match(field.Key) {
+				continue
+			}
+
+			// Ignore all fields not convertible to float
+			fv, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				r.Log.Tracef("Skipping field %q with value %v (%T) as it is not convertible to float: %v", field.Key, field.Value, field.Value, err)
+				continue
+			}
+			counters = append(counters, field.Key)
+
+			current := sample{value: fv, timestamp: m.Time()}
+			previous, found := stored.samples[field.Key]
+			if !found {
+				stored.samples[field.Key] = current
+				continue
+			}
+
+			// Ignore samples not newer than the previous one, e.g. duplicates
+			// or metrics arriving out of order
+			elapsed := current.timestamp.Sub(previous.timestamp).Seconds()
+			if elapsed <= 0 {
+				r.Log.Tracef("Skipping field %q as timestamp %v is not after the previous one", field.Key, current.timestamp)
+				continue
+			}
+			stored.samples[field.Key] = current
+
+			rates[field.Key+r.Suffix] = r.increase(previous.value, current.value) / elapsed
+		}
+
+		// Modify the fields after iterating them to not invalidate the field list
+		if r.DropOriginal {
+			for _, key := range counters {
+				m.RemoveField(key)
+			}
+		}
+		for key, value := range rates {
+			m.AddField(key, value)
+		}
+		stored.seen = now
+		r.cache[id] = stored
+
+		// Drop metrics without any remaining field
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	// Cleanup cache entries that are too old
+	if r.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(r.ExpiryInterval))
+		maps.DeleteFunc(r.cache, func(_ uint64, e *entry) bool {
+			return e.seen.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// increase computes the increase of the counter between the two values. A
+// decreasing value is treated as rollover if a maximum is configured and as
+// reset to zero otherwise.
+func (r *Rate) increase(previous, current float64) float64 {
+	if current >= previous {
+		return current - previous
+	}
+	if r.CounterMax > 0 {
+		return r.CounterMax - previous + current + 1
+	}
+	return current
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return &Rate{
+			Suffix: "_rate",
+		}
+	})
+}
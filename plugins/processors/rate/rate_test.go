@@ -0,0 +1,189 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Rate{DropOriginal: false}
+	require.ErrorContains(t, plugin.Init(), `empty suffix requires "drop_original" to be set`)
+
+	plugin = &Rate{Suffix: "_rate", CounterMax: -1}
+	require.ErrorContains(t, plugin.Init(), "invalid counter maximum -1")
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	input := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(1000), "bytes_sent": uint64(500), "err_in": int64(0), "state": "up"},
+			now,
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": int64(10), "bytes_sent": uint64(10), "err_in": int64(0), "state": "up"},
+			now,
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(3000), "bytes_sent": uint64(1500), "err_in": int64(1), "state": "up"},
+			now.Add(10*time.Second),
+		),
+		// Duplicate timestamp
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(4000), "bytes_sent": uint64(1500), "err_in": int64(1), "state": "up"},
+			now.Add(10*time.Second),
+		),
+		// Counter reset
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(100), "bytes_sent": uint64(1700), "err_in": int64(1), "state": "up"},
+			now.Add(20*time.Second),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(1000), "bytes_sent": uint64(500), "err_in": int64(0), "state": "up"},
+			now,
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": int64(10), "bytes_sent": uint64(10), "err_in": int64(0), "state": "up"},
+			now,
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{
+				"bytes_recv":      int64(3000),
+				"bytes_recv_rate": float64(200),
+				"bytes_sent":      uint64(1500),
+				"bytes_sent_rate": float64(100),
+				"err_in":          int64(1),
+				"state":           "up",
+			},
+			now.Add(10*time.Second),
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": int64(4000), "bytes_sent": uint64(1500), "err_in": int64(1), "state": "up"},
+			now.Add(10*time.Second),
+		),
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{
+				"bytes_recv":      int64(100),
+				"bytes_recv_rate": float64(10),
+				"bytes_sent":      uint64(1700),
+				"bytes_sent_rate": float64(20),
+				"err_in":          int64(1),
+				"state":           "up",
+			},
+			now.Add(20*time.Second),
+		),
+	}
+
+	plugin := &Rate{
+		Fields: []string{"bytes_*"},
+		Suffix: "_rate",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestRollover(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	input := []telegraf.Metric{
+		metric.New("snmp", map[string]string{}, map[string]interface{}{"in_octets": uint64(4294967000)}, now),
+		metric.New("snmp", map[string]string{}, map[string]interface{}{"in_octets": uint64(704)}, now.Add(10*time.Second)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("snmp", map[string]string{}, map[string]interface{}{"in_octets": float64(100)}, now.Add(10*time.Second)),
+	}
+
+	plugin := &Rate{
+		DropOriginal: true,
+		CounterMax:   4294967295,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, 2)
+	for i, v := range []int64{10, 30} {
+		m := metric.New("foo", map[string]string{}, map[string]interface{}{"value": v}, now.Add(time.Duration(i)*time.Second))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Rate{
+		Suffix:       "_rate",
+		DropOriginal: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first metric has no fields left and is dropped
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"value_rate": float64(20)}, actual[0].Fields())
+	require.Len(t, delivered, 1)
+
+	actual[0].Accept()
+	require.Len(t, delivered, 2)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Unix(1718352000, 0)
+	plugin := &Rate{
+		Suffix:         "_rate",
+		ExpiryInterval: config.Duration(10 * time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("foo", map[string]string{}, map[string]interface{}{"value": int64(1)}, now)
+	plugin.Apply(m)
+	require.Len(t, plugin.cache, 1)
+
+	// Artificially age the cache entry and check it is removed
+	plugin.cache[m.HashID()].seen = time.Now().Add(-11 * time.Second)
+	plugin.Apply(metric.New("bar", map[string]string{}, map[string]interface{}{"value": int64(1)}, now))
+	require.Len(t, plugin.cache, 1)
+	require.NotContains(t, plugin.cache, m.HashID())
+}
@@ -0,0 +1,182 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"regexp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Redact struct {
+	HMACKey    config.Secret   `toml:"hmac_key"`
+	HashLength int             `toml:"hash_length"`
+	Tags       []rule          `toml:"tags"`
+	Fields     []rule          `toml:"fields"`
+	Log        telegraf.Logger `toml:"-"`
+
+	needsKey bool
+}
+
+type rule struct {
+	Key         string `toml:"key"`
+	Pattern     string `toml:"pattern"`
+	Action      string `toml:"action"`
+	Replacement string `toml:"replacement"`
+
+	filter filter.Filter
+	regex  *regexp.Regexp
+}
+
+func (*Redact) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Redact) Init() error {
+	if len(r.Tags) == 0 && len(r.Fields) == 0 {
+		return errors.New("no tags or fields to redact")
+	}
+	if r.HashLength < 0 || r.HashLength > 2*sha256.Size {
+		return fmt.Errorf("hash length has to be between 0 and %d", 2*sha256.Size)
+	}
+
+	for i := range r.Tags {
+		if err := r.Tags[i].init(); err != nil {
+			return fmt.Errorf("invalid tag rule %d: %w", i+1, err)
+		}
+		r.needsKey = r.needsKey || r.Tags[i].Action == "hash"
+	}
+	for i := range r.Fields {
+		if err := r.Fields[i].init(); err != nil {
+			return fmt.Errorf("invalid field rule %d: %w", i+1, err)
+		}
+		r.needsKey = r.needsKey || r.Fields[i].Action == "hash"
+	}
+
+	// Unkeyed hashes of values with a small domain, like IP addresses, can
+	// easily be reversed using a dictionary so require a key.
+	if r.needsKey && r.HMACKey.Empty() {
+		return errors.New("'hmac_key' required for action \"hash\"")
+	}
+
+	return nil
+}
+
+func (r *Redact) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	var mac hash.Hash
+	if r.needsKey {
+		key, err := r.HMACKey.Get()
+		if err != nil {
+			r.Log.Errorf("Getting HMAC key failed, dropping metrics: %v", err)
+			for _, m := range in {
+				m.Drop()
+			}
+			return nil
+		}
+		mac = hmac.New(sha256.New, key.Bytes())
+		key.Destroy()
+	}
+
+	hashFunc := func(value string) string {
+		mac.Reset()
+		mac.Write([]byte(value))
+		digest := hex.EncodeToString(mac.Sum(nil))
+		if r.HashLength > 0 {
+			return digest[:r.HashLength]
+		}
+		return digest
+	}
+
+	for _, m := range in {
+		for _, t := range m.TagList() {
+			value := t.Value
+			for _, rule := range r.Tags {
+				if rule.filter.Match(t.Key) {
+					value = rule.apply(value, hashFunc)
+				}
+			}
+			if value != t.Value {
+				m.AddTag(t.Key, value)
+			}
+		}
+		for _, f := range m.FieldList() {
+			original, ok := f.Value.(string)
+			if !ok {
+				continue
+			}
+			value := original
+			for _, rule := range r.Fields {
+				if rule.filter.Match(f.Key) {
+					value = rule.apply(value, hashFunc)
+				}
+			}
+			if value != original {
+				m.AddField(f.Key, value)
+			}
+		}
+	}
+
+	return in
+}
+
+func (r *rule) init() error {
+	if r.Key == "" {
+		return errors.New("key required")
+	}
+
+	switch r.Action {
+	case "":
+		r.Action = "mask"
+	case "mask", "hash":
+	default:
+		return fmt.Errorf("invalid action %q", r.Action)
+	}
+	if r.Action == "mask" && r.Replacement == "" {
+		r.Replacement = "***"
+	}
+
+	var err error
+	if r.filter, err = filter.Compile([]string{r.Key}); err != nil {
+		return fmt.Errorf("compiling key filter failed: %w", err)
+	}
+	if r.Pattern != "" {
+		if r.regex, err = regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("compiling pattern failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// apply redacts the whole value or the parts matching the pattern if any
+func (r *rule) apply(value string, hashFunc func(string) string) string {
+	redact := func(s string) string {
+		if r.Action == "hash" {
+			return hashFunc(s)
+		}
+		return r.Replacement
+	}
+
+	if r.regex == nil {
+		return redact(value)
+	}
+	return r.regex.ReplaceAllStringFunc(value, redact)
+}
+
+func init() {
+	processors.Add("redact", func() telegraf.Processor {
+		return &Redact{}
+	})
+}
@@ -0,0 +1,162 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Redact
+		expected string
+	}{
+		{
+			name:     "no rules",
+			plugin:   &Redact{},
+			expected: "no tags or fields to redact",
+		},
+		{
+			name: "no key",
+			plugin: &Redact{
+				Tags: []rule{{}},
+			},
+			expected: "invalid tag rule 1: key required",
+		},
+		{
+			name: "invalid action",
+			plugin: &Redact{
+				Fields: []rule{{Key: "message", Action: "drop"}},
+			},
+			expected: `invalid field rule 1: invalid action "drop"`,
+		},
+		{
+			name: "invalid pattern",
+			plugin: &Redact{
+				Fields: []rule{{Key: "message", Pattern: "a(b"}},
+			},
+			expected: "invalid field rule 1: compiling pattern failed",
+		},
+		{
+			name: "hash without hmac key",
+			plugin: &Redact{
+				Tags: []rule{{Key: "user", Action: "hash"}},
+			},
+			expected: "'hmac_key' required",
+		},
+		{
+			name: "invalid hash length",
+			plugin: &Redact{
+				HashLength: 65,
+				Tags:       []rule{{Key: "user"}},
+			},
+			expected: "hash length has to be between 0 and 64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	const key = "secret"
+	hashed := func(value string, length int) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))[:length]
+	}
+
+	plugin := &Redact{
+		HMACKey:    config.NewSecret([]byte(key)),
+		HashLength: 16,
+		Tags: []rule{
+			{Key: "client_ip", Action: "hash"},
+			{Key: "user*"},
+		},
+		Fields: []rule{
+			{Key: "message", Pattern: `[\w.+-]+@[\w-]+(\.[\w-]+)+`, Replacement: "<email>"},
+			{Key: "message", Pattern: `\d+\.\d+\.\d+\.\d+`, Action: "hash"},
+			{Key: "uid"},
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"login",
+			map[string]string{"client_ip": "10.0.0.1", "username": "jane", "host": "server"},
+			map[string]interface{}{
+				"message":  "login of jane.doe@example.com from 10.0.0.1 succeeded",
+				"uid":      int64(42),
+				"duration": 1.5,
+			},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"login",
+			map[string]string{"host": "server"},
+			map[string]interface{}{"message": "nothing to redact"},
+			time.Unix(1700000000, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"login",
+			map[string]string{"client_ip": hashed("10.0.0.1", 16), "username": "***", "host": "server"},
+			map[string]interface{}{
+				"message":  "login of <email> from " + hashed("10.0.0.1", 16) + " succeeded",
+				"uid":      int64(42),
+				"duration": 1.5,
+			},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"login",
+			map[string]string{"host": "server"},
+			map[string]interface{}{"message": "nothing to redact"},
+			time.Unix(1700000000, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	m := metric.New("test", map[string]string{"user": "jane"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(m, notify)
+
+	plugin := &Redact{
+		Tags: []rule{{Key: "user"}},
+		Log:  &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(tm)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]string{"user": "***"}, actual[0].Tags())
+	actual[0].Accept()
+	require.Eventually(t, func() bool {
+		return delivered == 1
+	}, time.Second, 100*time.Millisecond)
+}
@@ -36,18 +36,27 @@ func (ct converterType) String() string {
 }
 
 type converter struct {
-	Key         string `toml:"key"`
-	Pattern     string `toml:"pattern"`
-	Replacement string `toml:"replacement"`
-	ResultKey   string `toml:"result_key"`
-	Append      bool   `toml:"append"`
+	Key          string   `toml:"key"`
+	Pattern      string   `toml:"pattern"`
+	Replacement  string   `toml:"replacement"`
+	ResultKey    string   `toml:"result_key"`
+	Append       bool     `toml:"append"`
+	ResultTags   []string `toml:"result_tags"`
+	ResultFields []string `toml:"result_fields"`
 
 	filter filter.Filter
 	re     *regexp.Regexp
-	groups []string
+	groups []group
 	apply  func(m telegraf.Metric)
 }
 
+// group is a named capture group with the type of the target
+type group struct {
+	index int
+	name  string
+	tag   bool
+}
+
 func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	// Compile the pattern
 	re, err := regexp.Compile(c.Pattern)
@@ -56,6 +65,10 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	}
 	c.re = re
 
+	if ct != convertTags && ct != convertFields && (len(c.ResultTags) > 0 || len(c.ResultFields) > 0) {
+		return errors.New("'result_tags' and 'result_fields' are not supported")
+	}
+
 	switch ct {
 	case convertTags, convertFields:
 		if c.Key == "" {
@@ -67,6 +80,18 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 		}
 		c.filter = f
 
+		// Check for explicitly selected named groups
+		if len(c.ResultTags) > 0 || len(c.ResultFields) > 0 {
+			if c.ResultKey != "" || c.Replacement != "" {
+				return errors.New("'result_tags' and 'result_fields' cannot be used with 'replacement' or 'result_key'")
+			}
+			if err := c.selectGroups(); err != nil {
+				return err
+			}
+			log.Debugf("%s: Using selected named-group mode...", ct)
+			break
+		}
+
 		// Check for named groups
 		if c.ResultKey == "" && c.Replacement == "" {
 			groups := c.re.SubexpNames()
@@ -79,7 +104,9 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 			}
 			if allNamed {
 				log.Debugf("%s: Using named-group mode...", ct)
-				c.groups = groups[1:]
+				for i, name := range groups[1:] {
+					c.groups = append(c.groups, group{index: i + 1, name: name, tag: ct == convertTags})
+				}
 			} else {
 				msg := "Neither 'result_key' nor 'replacement' given with unnamed or mixed groups;"
 				msg += " using explicit, empty replacement!"
@@ -116,6 +143,66 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	return nil
 }
 
+// selectGroups collects the named groups given in 'result_tags' and
+// 'result_fields' allowing to populate tags and fields in one pass
+func (c *converter) selectGroups() error {
+	indices := make(map[string]int)
+	for i, name := range c.re.SubexpNames() {
+		if name != "" {
+			indices[name] = i
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(names []string, tag bool) error {
+		for _, name := range names {
+			idx, found := indices[name]
+			if !found {
+				return fmt.Errorf("named group %q not found in pattern", name)
+			}
+			if seen[name] {
+				return fmt.Errorf("named group %q selected multiple times", name)
+			}
+			seen[name] = true
+			c.groups = append(c.groups, group{index: idx, name: name, tag: tag})
+		}
+		return nil
+	}
+	if err := add(c.ResultTags, true); err != nil {
+		return err
+	}
+	return add(c.ResultFields, false)
+}
+
+// applyGroups adds the content of the named groups matching the given value
+// as tags or fields
+func (c *converter) applyGroups(m telegraf.Metric, value string) {
+	matches := c.re.FindStringSubmatch(value)
+	for _, g := range c.groups {
+		match := matches[g.index]
+		if match == "" {
+			continue
+		}
+		if g.tag {
+			if c.Append {
+				if v, ok := m.GetTag(g.name); ok {
+					match = v + match
+				}
+			}
+			m.AddTag(g.name, match)
+			continue
+		}
+		if c.Append {
+			if v, ok := m.GetField(g.name); ok {
+				if s, ok := v.(string); ok {
+					match = s + match
+				}
+			}
+		}
+		m.AddField(g.name, match)
+	}
+}
+
 func (c *converter) applyTags(m telegraf.Metric) {
 	for _, tag := range m.TagList() {
 		if !c.filter.Match(tag.Key) || !c.re.MatchString(tag.Value) {
@@ -124,19 +211,7 @@ func (c *converter) applyTags(m telegraf.Metric) {
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(tag.Value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddTag(name, match)
-			}
+			c.applyGroups(m, tag.Value)
 			continue
 		}
 
@@ -169,19 +244,7 @@ func (c *converter) applyFields(m telegraf.Metric) {
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddField(name, match)
-			}
+			c.applyGroups(m, value)
 			continue
 		}
 
@@ -820,6 +820,121 @@ func TestNamedGroups(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual)
 }
 
+func TestSelectedNamedGroups(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:          "resp_code",
+				Pattern:      `^(?P<resp_class>\d)(\d\d)$`,
+				ResultFields: []string{"resp_class"},
+			},
+		},
+		Fields: []converter{
+			{
+				Key:          "request",
+				Pattern:      `^/(api)/(?P<method>\w+)[/?].*category=(?P<search_category>\w+)&q=(?P<query>\w+)`,
+				ResultTags:   []string{"method", "search_category"},
+				ResultFields: []string{"query"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	input := testutil.MustMetric(
+		"access_log",
+		map[string]string{
+			"verb":      "GET",
+			"resp_code": "200",
+		},
+		map[string]interface{}{
+			"request":       "/api/search/?category=plugins&q=regex&sort=asc",
+			"ignore_number": int64(200),
+			"ignore_bool":   true,
+		},
+		time.Unix(1695243874, 0),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"access_log",
+			map[string]string{
+				"verb":            "GET",
+				"resp_code":       "200",
+				"method":          "search",
+				"search_category": "plugins",
+			},
+			map[string]interface{}{
+				"request":       "/api/search/?category=plugins&q=regex&sort=asc",
+				"resp_class":    "2",
+				"query":         "regex",
+				"ignore_number": int64(200),
+				"ignore_bool":   true,
+			},
+			time.Unix(1695243874, 0),
+		),
+	}
+	actual := regex.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSelectedNamedGroupsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		regex    Regex
+		expected string
+	}{
+		{
+			name: "unknown group",
+			regex: Regex{
+				Fields: []converter{
+					{Key: "request", Pattern: `^(?P<method>\w+)$`, ResultTags: []string{"foo"}},
+				},
+			},
+			expected: `named group "foo" not found in pattern`,
+		},
+		{
+			name: "group selected twice",
+			regex: Regex{
+				Fields: []converter{
+					{
+						Key:          "request",
+						Pattern:      `^(?P<method>\w+)$`,
+						ResultTags:   []string{"method"},
+						ResultFields: []string{"method"},
+					},
+				},
+			},
+			expected: `named group "method" selected multiple times`,
+		},
+		{
+			name: "with replacement",
+			regex: Regex{
+				Tags: []converter{
+					{Key: "code", Pattern: `^(?P<class>\d)$`, Replacement: "${class}", ResultFields: []string{"class"}},
+				},
+			},
+			expected: "cannot be used with 'replacement' or 'result_key'",
+		},
+		{
+			name: "rename",
+			regex: Regex{
+				TagRename: []converter{
+					{Pattern: `^(?P<name>\w+)$`, Replacement: "${name}", ResultTags: []string{"name"}},
+				},
+			},
+			expected: "'result_tags' and 'result_fields' are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.regex.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.regex.Init(), tt.expected)
+		})
+	}
+}
+
 func TestNoMatches(t *testing.T) {
 	tests := []struct {
 		message        string
@@ -8,15 +8,25 @@ type Creator func() telegraf.Processor
 // StreamingCreator is a function that returns a new instance of a telegraf.StreamingProcessor.
 type StreamingCreator func() telegraf.StreamingProcessor
 
+// BatchCreator is a function that returns a new instance of a telegraf.BatchProcessor.
+type BatchCreator func() telegraf.BatchProcessor
+
 // HasUnwrap indicates the presence of an Unwrap() function to retrieve the underlying telegraf.Processor.
 type HasUnwrap interface {
 	// Unwrap returns the underlying telegraf.Processor.
 	Unwrap() telegraf.Processor
 }
 
+// HasBatchUnwrap indicates the presence of an UnwrapBatch() function to retrieve the underlying telegraf.BatchProcessor.
+type HasBatchUnwrap interface {
+	// UnwrapBatch returns the underlying telegraf.BatchProcessor.
+	UnwrapBatch() telegraf.BatchProcessor
+}
+
 // Processors is a map of processor names to their respective creator functions.
 // All processors are streaming processors.
-// telegraf.Processor processors are upgraded to telegraf.StreamingProcessor.
+// telegraf.Processor and telegraf.BatchProcessor processors are upgraded to
+// telegraf.StreamingProcessor.
 var Processors = make(map[string]StreamingCreator)
 
 // Add adds a telegraf.Processor processor
@@ -29,6 +39,13 @@ func AddStreaming(name string, creator StreamingCreator) {
 	Processors[name] = creator
 }
 
+// AddBatch adds a telegraf.BatchProcessor batch processor
+func AddBatch(name string, creator BatchCreator) {
+	Processors[name] = func() telegraf.StreamingProcessor {
+		return NewStreamingProcessorFromBatchProcessor(creator())
+	}
+}
+
 func upgradeToStreamingProcessor(oldCreator Creator) StreamingCreator {
 	return func() telegraf.StreamingProcessor {
 		return NewStreamingProcessorFromProcessor(oldCreator())
@@ -2,13 +2,17 @@
 package round
 
 import (
+	"cmp"
 	_ "embed"
 	"fmt"
 	"math"
+	"slices"
+	"time"
 
 	"golang.org/x/exp/constraints"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
@@ -16,14 +20,27 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// maxPrecision limits the precision as larger factors overflow int64
+const maxPrecision = 18
+
 type Round struct {
-	Precision     int             `toml:"precision"`
-	IncludeFields []string        `toml:"include_fields"`
-	ExcludeFields []string        `toml:"exclude_fields"`
-	Log           telegraf.Logger `toml:"-"`
+	Precision            int             `toml:"precision"`
+	PrecisionOverrides   map[string]int  `toml:"precision_overrides"`
+	Method               string          `toml:"method"`
+	IncludeFields        []string        `toml:"include_fields"`
+	ExcludeFields        []string        `toml:"exclude_fields"`
+	TimestampGranularity config.Duration `toml:"timestamp_granularity"`
+	Log                  telegraf.Logger `toml:"-"`
+
+	fields    filter.Filter
+	overrides []override
+	roundFunc func(float64) float64
+}
 
-	factor float64
-	fields filter.Filter
+type override struct {
+	pattern   string
+	filter    filter.Filter
+	precision int
 }
 
 func (*Round) SampleConfig() string {
@@ -31,13 +48,55 @@ func (*Round) SampleConfig() string {
 }
 
 func (p *Round) Init() error {
+	switch p.Method {
+	case "", "half_away_from_zero":
+		p.Method = "half_away_from_zero"
+		p.roundFunc = math.Round
+	case "half_even":
+		p.roundFunc = math.RoundToEven
+	case "floor":
+		p.roundFunc = math.Floor
+	case "ceil":
+		p.roundFunc = math.Ceil
+	case "truncate":
+		p.roundFunc = math.Trunc
+	default:
+		return fmt.Errorf("invalid method %q", p.Method)
+	}
+
+	if err := checkPrecision(p.Precision); err != nil {
+		return err
+	}
+
+	if p.TimestampGranularity < 0 {
+		return fmt.Errorf("invalid timestamp granularity %s", time.Duration(p.TimestampGranularity))
+	}
+
 	fieldFilter, err := filter.NewIncludeExcludeFilter(p.IncludeFields, p.ExcludeFields)
 	if err != nil {
 		return fmt.Errorf("creating fieldFilter failed: %w", err)
 	}
 	p.fields = fieldFilter
 
-	p.factor = math.Pow10(p.Precision * -1)
+	// Check the overrides in a deterministic order with the longest, i.e.
+	// most specific, pattern first
+	p.overrides = make([]override, 0, len(p.PrecisionOverrides))
+	for pattern, precision := range p.PrecisionOverrides {
+		if err := checkPrecision(precision); err != nil {
+			return fmt.Errorf("invalid precision override %q: %w", pattern, err)
+		}
+		f, err := filter.Compile([]string{pattern})
+		if err != nil {
+			return fmt.Errorf("creating filter for precision override %q failed: %w", pattern, err)
+		}
+		p.overrides = append(p.overrides, override{pattern: pattern, filter: f, precision: precision})
+	}
+	slices.SortFunc(p.overrides, func(a, b override) int {
+		if c := cmp.Compare(len(b.pattern), len(a.pattern)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.pattern, b.pattern)
+	})
 
 	return nil
 }
@@ -48,77 +107,124 @@ func (p *Round) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 			if !p.fields.Match(field.Key) {
 				continue
 			}
-			field.Value = p.round(field.Value)
+			field.Value = p.round(field.Value, p.precision(field.Key))
+		}
+		if p.TimestampGranularity > 0 {
+			ts := roundInt(metric.Time().UnixNano(), int64(p.TimestampGranularity), p.Method)
+			metric.SetTime(time.Unix(0, ts))
 		}
 	}
 	return metrics
 }
 
-// rounds the provided value to Precision.
-func (p *Round) round(value interface{}) interface{} {
+// precision returns the precision of the first override matching the field
+// or the default precision.
+func (p *Round) precision(field string) int {
+	for _, o := range p.overrides {
+		if o.filter.Match(field) {
+			return o.precision
+		}
+	}
+	return p.Precision
+}
+
+func checkPrecision(precision int) error {
+	if precision < -maxPrecision || precision > maxPrecision {
+		return fmt.Errorf("precision %d out of range [%d, %d]", precision, -maxPrecision, maxPrecision)
+	}
+	return nil
+}
+
+// rounds the provided value to the given precision.
+func (p *Round) round(value interface{}, precision int) interface{} {
+	factor := int64(math.Pow10(-precision))
 	switch v := value.(type) {
 	case int:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case int8:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case int16:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case int32:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case int64:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case uint:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case uint8:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case uint16:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case uint32:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case uint64:
-		return roundInt(v, int64(p.factor))
+		return roundInt(v, factor, p.Method)
 	case float32:
-		return roundFloat(v, p.factor)
+		return roundFloat(v, precision, p.roundFunc)
 	case float64:
-		return roundFloat(v, p.factor)
+		return roundFloat(v, precision, p.roundFunc)
 	default:
 		p.Log.Tracef("Invalid type %T for value '%v'", value, value)
 	}
 	return value
 }
 
-func roundInt[V constraints.Integer](value V, factor int64) V {
+func roundInt[V constraints.Integer](value V, factor int64, method string) V {
 	// Rounding to the full integer or a fraction will result
 	// in the integer itself, so skip the computation.
 	if factor < 10 {
 		return value
 	}
 
-	// Compute relevant operators. As we need to round we
-	// use an effective factor of one order of magnitude
-	// less to keep the fractional part in the resulting
-	// integer.
-	f := factor / 10
-	v := int64(value) / f
-	r := v % 10
-
-	// Round away from zero for positive and negative
-	// values with an absolute fraction greater or
-	// equal 1/2.
-	if r <= -5 {
-		return V((v - r - 10) * f)
+	// Split the value into the multiple of the factor and the
+	// remainder. Both are truncated towards zero and the
+	// remainder has the sign of the value.
+	v := int64(value)
+	q := v / factor
+	r := v % factor
+
+	// Compare the absolute remainder to the half of the factor
+	// to decide on the direction of rounding.
+	half := factor / 2
+	absR := r
+	if absR < 0 {
+		absR = -absR
 	}
-	if r >= 5 {
-		return V((v - r + 10) * f)
+
+	var away bool
+	switch method {
+	case "floor":
+		away = r < 0
+	case "ceil":
+		away = r > 0
+	case "truncate":
+		away = false
+	case "half_even":
+		away = absR > half || (absR == half && q%2 != 0)
+	default:
+		away = absR >= half
 	}
 
-	// Floor the value as the absolute fraction is less
-	// than 1/2.
-	return V((v - r) * f)
+	if away {
+		if v < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return V(q * factor)
 }
 
-func roundFloat[V constraints.Float](value V, factor float64) V {
-	return V(math.Round(float64(value)/factor) * factor)
+// roundFloat scales the value according to the precision, rounds it using the
+// given function and scales it back. Scaling uses the exact powers of ten to
+// avoid representation errors of the fractional factor.
+func roundFloat[V constraints.Float](value V, precision int, round func(float64) float64) V {
+	if precision >= 0 {
+		scale := math.Pow10(precision)
+		return V(round(float64(value)*scale) / scale)
+	}
+	scale := math.Pow10(-precision)
+	return V(round(float64(value)/scale) * scale)
 }
 
 func init() {
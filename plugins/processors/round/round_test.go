@@ -1,6 +1,7 @@
 package round
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -274,6 +276,200 @@ func TestRound(t *testing.T) {
 	}
 }
 
+func TestRoundMethods(t *testing.T) {
+	tests := []struct {
+		method    string
+		precision int
+		input     []interface{}
+		expected  []interface{}
+	}{
+		{
+			method:    "half_away_from_zero",
+			precision: 0,
+			input:     []interface{}{2.5, 3.5, -2.5, 2.4, 2.675},
+			expected:  []interface{}{3.0, 4.0, -3.0, 2.0, 3.0},
+		},
+		{
+			method:    "half_even",
+			precision: 0,
+			input:     []interface{}{2.5, 3.5, -2.5, -3.5, 2.6},
+			expected:  []interface{}{2.0, 4.0, -2.0, -4.0, 3.0},
+		},
+		{
+			method:    "half_even",
+			precision: 2,
+			input:     []interface{}{0.125, 0.135, 2.675, float32(0.375)},
+			expected:  []interface{}{0.12, 0.14, 2.68, float32(0.38)},
+		},
+		{
+			method:    "half_even",
+			precision: -2,
+			input:     []interface{}{int64(250), int64(350), int64(-250), int64(251), uint64(1450), 2450.0},
+			expected:  []interface{}{int64(200), int64(400), int64(-200), int64(300), uint64(1400), 2400.0},
+		},
+		{
+			method:    "floor",
+			precision: 1,
+			input:     []interface{}{1.29, -1.21},
+			expected:  []interface{}{1.2, -1.3},
+		},
+		{
+			method:    "floor",
+			precision: -1,
+			input:     []interface{}{int64(19), int64(-11), int64(20)},
+			expected:  []interface{}{int64(10), int64(-20), int64(20)},
+		},
+		{
+			method:    "ceil",
+			precision: 1,
+			input:     []interface{}{1.21, -1.29},
+			expected:  []interface{}{1.3, -1.2},
+		},
+		{
+			method:    "ceil",
+			precision: -1,
+			input:     []interface{}{int64(11), int64(-19), uint32(20)},
+			expected:  []interface{}{int64(20), int64(-10), uint32(20)},
+		},
+		{
+			method:    "truncate",
+			precision: 1,
+			input:     []interface{}{1.29, -1.29},
+			expected:  []interface{}{1.2, -1.2},
+		},
+		{
+			method:    "truncate",
+			precision: -1,
+			input:     []interface{}{int64(19), int64(-19)},
+			expected:  []interface{}{int64(10), int64(-10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.method, tt.precision), func(t *testing.T) {
+			plugin := &Round{
+				Precision: tt.precision,
+				Method:    tt.method,
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := make([]interface{}, 0, len(tt.input))
+			for _, v := range tt.input {
+				actual = append(actual, plugin.round(v, plugin.Precision))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInvalidMethod(t *testing.T) {
+	plugin := &Round{Method: "stochastic"}
+	require.ErrorContains(t, plugin.Init(), `invalid method "stochastic"`)
+}
+
+func TestPrecisionOverrides(t *testing.T) {
+	input := metric.New("http",
+		map[string]string{},
+		map[string]interface{}{
+			"request_latency":   float64(0.123456),
+			"response_latency":  float64(1.987654),
+			"error_percent":     float64(12.345),
+			"cpu_percent":       float64(45.678),
+			"requests":          int64(1234),
+			"request_size":      float64(512.789),
+			"skipped_latency":   float64(0.123456),
+			"skipped_requests":  int64(5678),
+			"response_size_avg": float64(98.765),
+		},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New("http",
+			map[string]string{},
+			map[string]interface{}{
+				"request_latency":   float64(0.123),
+				"response_latency":  float64(1.988),
+				"error_percent":     float64(12.3),
+				"cpu_percent":       float64(45.68),
+				"requests":          int64(1200),
+				"request_size":      float64(513),
+				"skipped_latency":   float64(0.123456),
+				"skipped_requests":  int64(5678),
+				"response_size_avg": float64(99),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &Round{
+		PrecisionOverrides: map[string]int{
+			"*_latency":   3,
+			"*_percent":   1,
+			"cpu_percent": 2,
+			"requests":    -2,
+		},
+		ExcludeFields: []string{"skipped_*"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestFieldFilter(t *testing.T) {
+	input := metric.New("cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"usage_idle": float64(94.3999999994412),
+			"usage_user": float64(4.000000000014552),
+		},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"usage_idle": float64(94.4),
+				"usage_user": float64(4.000000000014552),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &Round{
+		Precision:     1,
+		ExcludeFields: []string{"usage_user"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInvalidPrecisionOverride(t *testing.T) {
+	plugin := &Round{PrecisionOverrides: map[string]int{"[": 1}}
+	require.ErrorContains(t, plugin.Init(), `creating filter for precision override "["`)
+}
+
+func TestInvalidPrecision(t *testing.T) {
+	for _, precision := range []int{-19, 19, -1000} {
+		plugin := &Round{Precision: precision}
+		require.ErrorContains(t, plugin.Init(), "out of range", "precision %d", precision)
+
+		plugin = &Round{PrecisionOverrides: map[string]int{"value": precision}}
+		require.ErrorContains(t, plugin.Init(), `invalid precision override "value"`, "precision %d", precision)
+	}
+
+	for _, precision := range []int{-18, 18} {
+		plugin := &Round{Precision: precision, PrecisionOverrides: map[string]int{"value": precision}}
+		require.NoError(t, plugin.Init(), "precision %d", precision)
+	}
+}
+
 func TestRoundPreservesNonNumericValues(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -436,3 +632,57 @@ func TestTracking(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestRoundTimestamp(t *testing.T) {
+	input := []time.Time{
+		time.Unix(1718352004, 999999999),
+		time.Unix(1718352005, 0),
+		time.Unix(1718352009, 500000000),
+		time.Unix(1718352010, 0),
+	}
+
+	tests := []struct {
+		method   string
+		expected []int64
+	}{
+		{
+			method:   "half_away_from_zero",
+			expected: []int64{1718352000, 1718352010, 1718352010, 1718352010},
+		},
+		{
+			method:   "half_even",
+			expected: []int64{1718352000, 1718352000, 1718352010, 1718352010},
+		},
+		{
+			method:   "floor",
+			expected: []int64{1718352000, 1718352000, 1718352000, 1718352010},
+		},
+		{
+			method:   "ceil",
+			expected: []int64{1718352010, 1718352010, 1718352010, 1718352010},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			plugin := &Round{
+				Method:               tt.method,
+				ExcludeFields:        []string{"*"},
+				TimestampGranularity: config.Duration(10 * time.Second),
+				Log:                  testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			metrics := make([]telegraf.Metric, 0, len(input))
+			for _, ts := range input {
+				metrics = append(metrics, metric.New("test", map[string]string{}, map[string]interface{}{"value": 1.5}, ts))
+			}
+
+			actual := plugin.Apply(metrics...)
+			for i, m := range actual {
+				require.Equal(t, time.Unix(tt.expected[i], 0), m.Time(), "metric %d", i)
+				require.Equal(t, map[string]interface{}{"value": 1.5}, m.Fields())
+			}
+		})
+	}
+}
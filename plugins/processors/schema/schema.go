@@ -0,0 +1,251 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package schema
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Schema struct {
+	Action            string              `toml:"action"`
+	Fallback          string              `toml:"fallback"`
+	RejectMeasurement string              `toml:"reject_measurement"`
+	Measurements      []measurementSchema `toml:"measurement"`
+	Log               telegraf.Logger     `toml:"-"`
+}
+
+type measurementSchema struct {
+	Name           string            `toml:"name"`
+	RequiredTags   []string          `toml:"required_tags"`
+	RequiredFields []string          `toml:"required_fields"`
+	Strict         bool              `toml:"strict"`
+	Fields         map[string]string `toml:"fields"`
+
+	filter filter.Filter
+}
+
+// result of checking a metric against the schema
+type result struct {
+	// converted field values when coercing
+	converted map[string]interface{}
+	// fields to remove when coercing
+	remove []string
+	// reason of the first violation not fixable by coercion
+	violation string
+}
+
+func (*Schema) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Schema) Init() error {
+	switch s.Action {
+	case "":
+		s.Action = "coerce"
+	case "coerce", "drop", "reject":
+	default:
+		return fmt.Errorf("invalid action %q", s.Action)
+	}
+
+	switch s.Fallback {
+	case "":
+		s.Fallback = "drop"
+	case "drop", "reject":
+	default:
+		return fmt.Errorf("invalid fallback %q", s.Fallback)
+	}
+
+	if s.RejectMeasurement == "" {
+		return errors.New("reject measurement cannot be empty")
+	}
+
+	if len(s.Measurements) == 0 {
+		return errors.New("no measurement schema given")
+	}
+	for i, m := range s.Measurements {
+		if m.Name == "" {
+			return errors.New("measurement schema without name")
+		}
+		f, err := filter.Compile([]string{m.Name})
+		if err != nil {
+			return fmt.Errorf("creating filter for measurement %q failed: %w", m.Name, err)
+		}
+		s.Measurements[i].filter = f
+
+		for field, t := range m.Fields {
+			switch t {
+			case "float", "integer", "unsigned", "string", "boolean":
+			default:
+				return fmt.Errorf("invalid type %q for field %q of measurement %q", t, field, m.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		ms := s.lookup(m.Name())
+		if ms == nil {
+			out = append(out, m)
+			continue
+		}
+
+		res := ms.check(m, s.Action == "coerce")
+		if res.violation == "" {
+			for _, key := range res.remove {
+				m.RemoveField(key)
+			}
+			for key, value := range res.converted {
+				m.AddField(key, value)
+			}
+			if len(m.FieldList()) == 0 {
+				s.Log.Debugf("Dropping metric %q without fields after removing unknown fields", m.Name())
+				m.Drop()
+				continue
+			}
+			out = append(out, m)
+			continue
+		}
+
+		action := s.Action
+		if action == "coerce" {
+			action = s.Fallback
+		}
+		switch action {
+		case "drop":
+			s.Log.Debugf("Dropping metric %q: %s", m.Name(), res.violation)
+			m.Drop()
+		case "reject":
+			m.AddTag("schema_measurement", m.Name())
+			m.AddField("schema_violation", res.violation)
+			m.SetName(s.RejectMeasurement)
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+func (s *Schema) lookup(name string) *measurementSchema {
+	for i := range s.Measurements {
+		if s.Measurements[i].filter.Match(name) {
+			return &s.Measurements[i]
+		}
+	}
+	return nil
+}
+
+// check the metric against the schema. If coercing, fields of the wrong type
+// are converted and unknown fields of strict schemas are removed instead of
+// being a violation.
+func (ms *measurementSchema) check(m telegraf.Metric, coerce bool) result {
+	var res result
+
+	for _, key := range ms.RequiredTags {
+		if !m.HasTag(key) {
+			res.violation = fmt.Sprintf("missing tag %q", key)
+			return res
+		}
+	}
+	for _, key := range ms.RequiredFields {
+		if !m.HasField(key) {
+			res.violation = fmt.Sprintf("missing field %q", key)
+			return res
+		}
+	}
+
+	// Check the fields in key order to report the same violation for a given
+	// metric independent of the field insertion order
+	fields := slices.Clone(m.FieldList())
+	slices.SortFunc(fields, func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	for _, field := range fields {
+		expected, found := ms.Fields[field.Key]
+		if !found {
+			if !ms.Strict {
+				continue
+			}
+			if !coerce {
+				res.violation = fmt.Sprintf("unknown field %q", field.Key)
+				return res
+			}
+			res.remove = append(res.remove, field.Key)
+			continue
+		}
+
+		actual := typeName(field.Value)
+		if actual == expected {
+			continue
+		}
+		if !coerce {
+			res.violation = fmt.Sprintf("field %q has type %s instead of %s", field.Key, actual, expected)
+			return res
+		}
+		v, err := convert(field.Value, expected)
+		if err != nil {
+			res.violation = fmt.Sprintf("converting field %q from %s to %s failed: %v", field.Key, actual, expected, err)
+			return res
+		}
+		if res.converted == nil {
+			res.converted = make(map[string]interface{})
+		}
+		res.converted[field.Key] = v
+	}
+
+	return res
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "float"
+	case int64:
+		return "integer"
+	case uint64:
+		return "unsigned"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func convert(v interface{}, t string) (interface{}, error) {
+	switch t {
+	case "float":
+		return internal.ToFloat64(v)
+	case "integer":
+		return internal.ToInt64(v)
+	case "unsigned":
+		return internal.ToUint64(v)
+	case "string":
+		return internal.ToString(v)
+	case "boolean":
+		return internal.ToBool(v)
+	}
+	return nil, fmt.Errorf("invalid type %q", t)
+}
+
+func init() {
+	processors.Add("schema", func() telegraf.Processor {
+		return &Schema{
+			RejectMeasurement: "schema_reject",
+		}
+	})
+}
@@ -0,0 +1,371 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Schema
+		expected string
+	}{
+		{
+			name:     "invalid action",
+			plugin:   &Schema{Action: "fix", RejectMeasurement: "reject"},
+			expected: `invalid action "fix"`,
+		},
+		{
+			name:     "invalid fallback",
+			plugin:   &Schema{Fallback: "coerce", RejectMeasurement: "reject"},
+			expected: `invalid fallback "coerce"`,
+		},
+		{
+			name:     "empty reject measurement",
+			plugin:   &Schema{},
+			expected: "reject measurement cannot be empty",
+		},
+		{
+			name:     "no schema",
+			plugin:   &Schema{RejectMeasurement: "reject"},
+			expected: "no measurement schema given",
+		},
+		{
+			name:     "no name",
+			plugin:   &Schema{RejectMeasurement: "reject", Measurements: []measurementSchema{{}}},
+			expected: "measurement schema without name",
+		},
+		{
+			name: "invalid type",
+			plugin: &Schema{
+				RejectMeasurement: "reject",
+				Measurements:      []measurementSchema{{Name: "cpu", Fields: map[string]string{"usage": "double"}}},
+			},
+			expected: `invalid type "double" for field "usage" of measurement "cpu"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	input := []telegraf.Metric{
+		// Conforming metric
+		metric.New(
+			"sensor",
+			map[string]string{"id": "1"},
+			map[string]interface{}{"temperature": 21.5, "count": int64(3), "status": "ok"},
+			now,
+		),
+		// Fields with wrong but convertible types and an unknown field
+		metric.New(
+			"sensor",
+			map[string]string{"id": "2"},
+			map[string]interface{}{"temperature": int64(21), "count": "4", "status": "ok", "debug": true},
+			now,
+		),
+		// Field with a type not convertible
+		metric.New(
+			"sensor",
+			map[string]string{"id": "3"},
+			map[string]interface{}{"temperature": "hot", "count": int64(5)},
+			now,
+		),
+		// Missing required tag
+		metric.New(
+			"sensor",
+			map[string]string{},
+			map[string]interface{}{"temperature": 21.5, "count": int64(6)},
+			now,
+		),
+		// Missing required field
+		metric.New(
+			"sensor",
+			map[string]string{"id": "5"},
+			map[string]interface{}{"count": int64(7)},
+			now,
+		),
+		// Metric without schema
+		metric.New(
+			"other",
+			map[string]string{},
+			map[string]interface{}{"value": "any"},
+			now,
+		),
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		fallback string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "coerce",
+			action: "coerce",
+			expected: []telegraf.Metric{
+				metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(3), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"sensor",
+					map[string]string{"id": "2"},
+					map[string]interface{}{"temperature": float64(21), "count": int64(4), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"other",
+					map[string]string{},
+					map[string]interface{}{"value": "any"},
+					now,
+				),
+			},
+		},
+		{
+			name:     "coerce with reject fallback",
+			action:   "coerce",
+			fallback: "reject",
+			expected: []telegraf.Metric{
+				metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(3), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"sensor",
+					map[string]string{"id": "2"},
+					map[string]interface{}{"temperature": float64(21), "count": int64(4), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"id": "3", "schema_measurement": "sensor"},
+					map[string]interface{}{
+						"temperature":      "hot",
+						"count":            int64(5),
+						"schema_violation": `converting field "temperature" from string to float failed: strconv.ParseFloat: parsing "hot": invalid syntax`,
+					},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"schema_measurement": "sensor"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(6), "schema_violation": `missing tag "id"`},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"id": "5", "schema_measurement": "sensor"},
+					map[string]interface{}{"count": int64(7), "schema_violation": `missing field "temperature"`},
+					now,
+				),
+				metric.New(
+					"other",
+					map[string]string{},
+					map[string]interface{}{"value": "any"},
+					now,
+				),
+			},
+		},
+		{
+			name:   "drop",
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(3), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"other",
+					map[string]string{},
+					map[string]interface{}{"value": "any"},
+					now,
+				),
+			},
+		},
+		{
+			name:   "reject",
+			action: "reject",
+			expected: []telegraf.Metric{
+				metric.New(
+					"sensor",
+					map[string]string{"id": "1"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(3), "status": "ok"},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"id": "2", "schema_measurement": "sensor"},
+					map[string]interface{}{
+						"temperature":      int64(21),
+						"count":            "4",
+						"status":           "ok",
+						"debug":            true,
+						"schema_violation": `field "count" has type string instead of integer`,
+					},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"id": "3", "schema_measurement": "sensor"},
+					map[string]interface{}{
+						"temperature":      "hot",
+						"count":            int64(5),
+						"schema_violation": `field "temperature" has type string instead of float`,
+					},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"schema_measurement": "sensor"},
+					map[string]interface{}{"temperature": 21.5, "count": int64(6), "schema_violation": `missing tag "id"`},
+					now,
+				),
+				metric.New(
+					"rejected",
+					map[string]string{"id": "5", "schema_measurement": "sensor"},
+					map[string]interface{}{"count": int64(7), "schema_violation": `missing field "temperature"`},
+					now,
+				),
+				metric.New(
+					"other",
+					map[string]string{},
+					map[string]interface{}{"value": "any"},
+					now,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Schema{
+				Action:            tt.action,
+				Fallback:          tt.fallback,
+				RejectMeasurement: "rejected",
+				Measurements: []measurementSchema{
+					{
+						Name:           "sens*",
+						RequiredTags:   []string{"id"},
+						RequiredFields: []string{"temperature"},
+						Strict:         true,
+						Fields: map[string]string{
+							"temperature": "float",
+							"count":       "integer",
+							"status":      "string",
+						},
+					},
+					{
+						Name:   "sensor",
+						Fields: map[string]string{"temperature": "string"},
+					},
+				},
+				Log: &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			inputs := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				inputs = append(inputs, m.Copy())
+			}
+			actual := plugin.Apply(inputs...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestNonStrict(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &Schema{
+		Action:            "drop",
+		RejectMeasurement: "rejected",
+		Measurements: []measurementSchema{
+			{Name: "sensor", Fields: map[string]string{"temperature": "float"}},
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": 21.5, "debug": true}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"humidity": 50.0}, now),
+	}
+	testutil.RequireMetricsEqual(t, input, plugin.Apply(input...))
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	inputRaw := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": 21.5}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": "hot"}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": "22"}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"debug": true}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	// The last metric is dropped as no field remains after removing the
+	// unknown fields
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": 21.5}, now),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"temperature": 22.0}, now),
+	}
+
+	plugin := &Schema{
+		RejectMeasurement: "rejected",
+		Measurements: []measurementSchema{
+			{Name: "sensor", Strict: true, Fields: map[string]string{"temperature": "float"}},
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
+}
@@ -5,10 +5,12 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.starlark.net/starlark"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	common "github.com/influxdata/telegraf/plugins/common/starlark"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
@@ -18,6 +20,7 @@ var sampleConfig string
 
 type Starlark struct {
 	common.Common
+	common.Network
 
 	results []telegraf.Metric
 }
@@ -27,6 +30,11 @@ func (*Starlark) SampleConfig() string {
 }
 
 func (s *Starlark) Init() error {
+	if err := s.Network.Init(); err != nil {
+		return err
+	}
+	s.StarlarkLoadFunc = s.Network.LoadFunc(s.StarlarkLoadFunc)
+
 	if err := s.Common.Init(); err != nil {
 		return err
 	}
@@ -137,6 +145,11 @@ func init() {
 			Common: common.Common{
 				StarlarkLoadFunc: common.LoadFunc,
 			},
+			Network: common.Network{
+				NetworkTimeout:        config.Duration(5 * time.Second),
+				NetworkBudget:         100,
+				NetworkBudgetInterval: config.Duration(time.Minute),
+			},
 		}
 	})
 }
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/gob"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -3794,6 +3797,156 @@ func testNow(_ *starlark.Thread, _ *starlark.Builtin, _ starlark.Tuple, _ []star
 	return starlarktime.Time(time.Date(2021, 4, 15, 12, 0, 0, 999, time.UTC)), nil
 }
 
+func TestNetwork(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/owner":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write([]byte(`{"owner":"team-a"}`)); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		case "/redirect":
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	source := `
+load("http.star", "http")
+load("dns.star", "dns")
+load("json.star", "json")
+
+def apply(metric):
+    resp = http.get(metric.tags["url"] + "/owner", headers={"Authorization": "Bearer secret"})
+    metric.fields["status"] = resp.status_code
+    metric.fields["content_type"] = resp.headers["content-type"]
+    metric.tags["owner"] = json.decode(resp.body)["owner"]
+    metric.fields["localhost"] = len(dns.lookup("localhost")) > 0
+    metric.fields["redirect_error"] = catch(lambda: http.get(metric.tags["url"] + "/redirect")) or ""
+    metric.fields["host_error"] = catch(lambda: http.get("http://example.com/")) or ""
+    metric.fields["domain_error"] = catch(lambda: dns.lookup("example.com")) or ""
+    metric.fields["scheme_error"] = catch(lambda: http.get("file:///etc/passwd")) or ""
+    metric.fields["budget_error"] = catch(lambda: http.get(metric.tags["url"] + "/owner")) or ""
+    return metric
+`
+
+	plugin := newStarlarkFromSource(source)
+	plugin.Network = common.Network{
+		HTTPAllowedHosts:      []string{"127.0.0.1"},
+		DNSAllowedDomains:     []string{"localhost"},
+		NetworkTimeout:        config.Duration(5 * time.Second),
+		NetworkBudget:         3,
+		NetworkBudgetInterval: config.Duration(time.Hour),
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("service", map[string]string{"url": ts.URL}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(input, &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"service",
+			map[string]string{"url": ts.URL, "owner": "team-a"},
+			map[string]interface{}{
+				"value":          1,
+				"status":         200,
+				"content_type":   "application/json",
+				"localhost":      true,
+				"redirect_error": `http.get: Get "http://example.com/": host "example.com" not allowed`,
+				"host_error":     `http.get: host "example.com" not allowed`,
+				"domain_error":   `dns.lookup: domain "example.com" not allowed`,
+				"scheme_error":   `http.get: scheme "file" not allowed`,
+				"budget_error":   "http.get: network budget of 3 requests per 1h0m0s exhausted",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestNetworkRedirects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	source := `
+load("http.star", "http")
+
+def apply(metric):
+    metric.fields["error"] = catch(lambda: http.get(metric.tags["url"] + "/loop")) or ""
+    return metric
+`
+
+	tests := []struct {
+		name     string
+		budget   int
+		expected string
+	}{
+		{
+			name:     "redirect limit",
+			budget:   100,
+			expected: `http.get: Get "/loop": stopped after 10 redirects`,
+		},
+		{
+			name:     "budget",
+			budget:   5,
+			expected: `http.get: Get "/loop": network budget of 5 requests per 1h0m0s exhausted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newStarlarkFromSource(source)
+			plugin.Network = common.Network{
+				HTTPAllowedHosts:      []string{"127.0.0.1"},
+				NetworkTimeout:        config.Duration(5 * time.Second),
+				NetworkBudget:         tt.budget,
+				NetworkBudgetInterval: config.Duration(time.Hour),
+			}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("service", map[string]string{"url": ts.URL}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			require.NoError(t, plugin.Add(input, &acc))
+			plugin.Stop()
+
+			actual := acc.GetTelegrafMetrics()
+			require.Len(t, actual, 1)
+			require.Equal(t, tt.expected, actual[0].Fields()["error"])
+		})
+	}
+}
+
+func TestNetworkDisabled(t *testing.T) {
+	for _, module := range []string{"http", "dns"} {
+		plugin := newStarlarkFromSource(fmt.Sprintf("load(%q, %q)\ndef apply(metric):\n    return metric", module+".star", module))
+		require.ErrorContains(t, plugin.Init(), "module "+module+".star is not available")
+	}
+}
+
+func TestNetworkInvalidSettings(t *testing.T) {
+	plugin := newStarlarkFromSource("def apply(metric):\n    return metric")
+	plugin.Network = common.Network{HTTPAllowedHosts: []string{"127.0.0.1"}}
+	require.ErrorContains(t, plugin.Init(), "network_timeout must be positive")
+}
+
 func newStarlarkFromSource(source string) *Starlark {
 	return &Starlark{
 		Common: common.Common{
@@ -0,0 +1,121 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tag_join
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TagJoin struct {
+	Reference []string        `toml:"reference"`
+	JoinKey   string          `toml:"join_key"`
+	Tags      []string        `toml:"tags"`
+	Overwrite bool            `toml:"overwrite"`
+	TTL       config.Duration `toml:"ttl"`
+	Log       telegraf.Logger `toml:"-"`
+
+	referenceFilter filter.Filter
+	tagFilter       filter.Filter
+
+	// learned tags per join key value
+	table map[string]*entry
+	now   func() time.Time
+}
+
+type entry struct {
+	tags    map[string]string
+	expires time.Time
+}
+
+func (*TagJoin) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *TagJoin) Init() error {
+	if len(j.Reference) == 0 {
+		return errors.New("no reference measurement defined")
+	}
+	if j.JoinKey == "" {
+		return errors.New("join key required")
+	}
+	if len(j.Tags) == 0 {
+		return errors.New("no tags defined")
+	}
+	if j.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+
+	var err error
+	if j.referenceFilter, err = filter.Compile(j.Reference); err != nil {
+		return fmt.Errorf("creating reference filter failed: %w", err)
+	}
+	if j.tagFilter, err = filter.Compile(j.Tags); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+
+	j.table = make(map[string]*entry)
+	if j.now == nil {
+		j.now = time.Now
+	}
+
+	return nil
+}
+
+func (j *TagJoin) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := j.now()
+
+	// Cleanup expired associations
+	maps.DeleteFunc(j.table, func(_ string, e *entry) bool {
+		return !e.expires.After(now)
+	})
+
+	for _, m := range in {
+		key, found := m.GetTag(j.JoinKey)
+		if !found {
+			continue
+		}
+
+		// Learn the tags from reference metrics and pass them unchanged
+		if j.referenceFilter.Match(m.Name()) {
+			tags := make(map[string]string)
+			for _, tag := range m.TagList() {
+				if tag.Key != j.JoinKey && j.tagFilter.Match(tag.Key) {
+					tags[tag.Key] = tag.Value
+				}
+			}
+			j.table[key] = &entry{tags: tags, expires: now.Add(time.Duration(j.TTL))}
+			continue
+		}
+
+		e, found := j.table[key]
+		if !found {
+			continue
+		}
+		for k, v := range e.tags {
+			if j.Overwrite || !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+
+	return in
+}
+
+func init() {
+	processors.Add("tag_join", func() telegraf.Processor {
+		return &TagJoin{
+			TTL: config.Duration(10 * time.Minute),
+		}
+	})
+}
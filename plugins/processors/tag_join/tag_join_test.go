@@ -0,0 +1,131 @@
+package tag_join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &TagJoin{TTL: config.Duration(time.Minute)}
+	require.ErrorContains(t, plugin.Init(), "no reference measurement defined")
+
+	plugin = &TagJoin{Reference: []string{"docker"}, TTL: config.Duration(time.Minute)}
+	require.ErrorContains(t, plugin.Init(), "join key required")
+
+	plugin = &TagJoin{Reference: []string{"docker"}, JoinKey: "id", TTL: config.Duration(time.Minute)}
+	require.ErrorContains(t, plugin.Init(), "no tags defined")
+
+	plugin = &TagJoin{Reference: []string{"docker"}, JoinKey: "id", Tags: []string{"*"}}
+	require.ErrorContains(t, plugin.Init(), "ttl must be positive")
+}
+
+func TestJoin(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &TagJoin{
+		Reference: []string{"docker_container_*"},
+		JoinKey:   "container_id",
+		Tags:      []string{"container_name", "app"},
+		TTL:       config.Duration(time.Minute),
+		Log:       testutil.Logger{},
+		now:       func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		// Not yet known
+		metric.New("statsd", map[string]string{"container_id": "abc"}, map[string]interface{}{"value": 1}, now),
+		// Reference metrics
+		metric.New(
+			"docker_container_status",
+			map[string]string{"container_id": "abc", "container_name": "web", "app": "shop", "engine_host": "node1"},
+			map[string]interface{}{"pid": 42},
+			now,
+		),
+		metric.New(
+			"docker_container_status",
+			map[string]string{"container_id": "def", "container_name": "db"},
+			map[string]interface{}{"pid": 43},
+			now,
+		),
+		// Decorated metrics
+		metric.New("statsd", map[string]string{"container_id": "abc"}, map[string]interface{}{"value": 2}, now),
+		metric.New("syslog", map[string]string{"container_id": "def", "container_name": "custom"}, map[string]interface{}{"value": 3}, now),
+		metric.New("syslog", map[string]string{"container_id": "xyz"}, map[string]interface{}{"value": 4}, now),
+		metric.New("syslog", map[string]string{}, map[string]interface{}{"value": 5}, now),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("statsd", map[string]string{"container_id": "abc"}, map[string]interface{}{"value": 1}, now),
+		metric.New(
+			"docker_container_status",
+			map[string]string{"container_id": "abc", "container_name": "web", "app": "shop", "engine_host": "node1"},
+			map[string]interface{}{"pid": 42},
+			now,
+		),
+		metric.New(
+			"docker_container_status",
+			map[string]string{"container_id": "def", "container_name": "db"},
+			map[string]interface{}{"pid": 43},
+			now,
+		),
+		metric.New(
+			"statsd",
+			map[string]string{"container_id": "abc", "container_name": "web", "app": "shop"},
+			map[string]interface{}{"value": 2},
+			now,
+		),
+		metric.New("syslog", map[string]string{"container_id": "def", "container_name": "custom"}, map[string]interface{}{"value": 3}, now),
+		metric.New("syslog", map[string]string{"container_id": "xyz"}, map[string]interface{}{"value": 4}, now),
+		metric.New("syslog", map[string]string{}, map[string]interface{}{"value": 5}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+
+	// Overwrite existing tags
+	plugin.Overwrite = true
+	input = []telegraf.Metric{
+		metric.New("syslog", map[string]string{"container_id": "def", "container_name": "custom"}, map[string]interface{}{"value": 6}, now),
+	}
+	expected = []telegraf.Metric{
+		metric.New("syslog", map[string]string{"container_id": "def", "container_name": "db"}, map[string]interface{}{"value": 6}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &TagJoin{
+		Reference: []string{"docker"},
+		JoinKey:   "id",
+		Tags:      []string{"name"},
+		TTL:       config.Duration(time.Minute),
+		Log:       testutil.Logger{},
+		now:       func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("docker", map[string]string{"id": "1", "name": "web"}, map[string]interface{}{"value": 1}, now))
+
+	// Still known before the TTL passed
+	now = now.Add(59 * time.Second)
+	actual := plugin.Apply(metric.New("app", map[string]string{"id": "1"}, map[string]interface{}{"value": 2}, now))
+	expected := []telegraf.Metric{
+		metric.New("app", map[string]string{"id": "1", "name": "web"}, map[string]interface{}{"value": 2}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Expired after the TTL
+	now = now.Add(time.Second)
+	actual = plugin.Apply(metric.New("app", map[string]string{"id": "1"}, map[string]interface{}{"value": 3}, now))
+	expected = []telegraf.Metric{
+		metric.New("app", map[string]string{"id": "1"}, map[string]interface{}{"value": 3}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Empty(t, plugin.table)
+}
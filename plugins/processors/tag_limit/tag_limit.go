@@ -4,6 +4,8 @@ package tag_limit
 import (
 	_ "embed"
 	"fmt"
+	"hash/fnv"
+	"slices"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/processors"
@@ -13,11 +15,15 @@ import (
 var sampleConfig string
 
 type TagLimit struct {
-	Limit    int             `toml:"limit"`
-	Keep     []string        `toml:"keep"`
-	Log      telegraf.Logger `toml:"-"`
-	init     bool
-	keepTags map[string]string
+	Limit      int             `toml:"limit"`
+	Keep       []string        `toml:"keep"`
+	Priority   []string        `toml:"priority"`
+	HashDrops  bool            `toml:"hash_dropped_tags"`
+	HashTagKey string          `toml:"hash_tag_key"`
+	Log        telegraf.Logger `toml:"-"`
+	init       bool
+	keepTags   map[string]string
+	priorities map[string]int
 }
 
 func (*TagLimit) SampleConfig() string {
@@ -36,37 +42,103 @@ func (d *TagLimit) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		if lenPointTags <= d.Limit {
 			continue
 		}
-		tagsToRemove := make([]string, 0, lenPointTags-d.Limit)
+
+		// the hash tag takes one of the available slots
+		limit := d.Limit
+		if d.HashDrops {
+			limit--
+		}
+
 		// remove extraneous tags, stop once we're at the limit
-		for _, t := range pointOriginalTags {
-			if _, ok := d.keepTags[t.Key]; !ok {
-				tagsToRemove = append(tagsToRemove, t.Key)
-				lenPointTags--
-			}
-			if lenPointTags <= d.Limit {
+		tagsToRemove := make([]*telegraf.Tag, 0, lenPointTags-limit)
+		for _, t := range d.removalOrder(pointOriginalTags) {
+			if lenPointTags <= limit {
 				break
 			}
+			tagsToRemove = append(tagsToRemove, t)
+			lenPointTags--
+		}
+		if d.HashDrops && len(tagsToRemove) > 0 {
+			point.AddTag(d.HashTagKey, hashTags(tagsToRemove))
 		}
 		for _, t := range tagsToRemove {
-			point.RemoveTag(t)
+			point.RemoveTag(t.Key)
 		}
 	}
 
 	return in
 }
 
+// removalOrder returns the tags that might be removed, starting with the tags
+// not mentioned in the priority list followed by the priority tags with the
+// lowest priority first. Tags to keep are never removed.
+func (d *TagLimit) removalOrder(tags []*telegraf.Tag) []*telegraf.Tag {
+	candidates := make([]*telegraf.Tag, 0, len(tags))
+	prioritized := make([]*telegraf.Tag, 0, len(d.priorities))
+	for _, t := range tags {
+		if _, ok := d.keepTags[t.Key]; ok {
+			continue
+		}
+		if _, ok := d.priorities[t.Key]; ok {
+			prioritized = append(prioritized, t)
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+	slices.SortFunc(prioritized, func(a, b *telegraf.Tag) int {
+		return d.priorities[b.Key] - d.priorities[a.Key]
+	})
+	return append(candidates, prioritized...)
+}
+
+// hashTags computes a hash of the tags independent of their order
+func hashTags(tags []*telegraf.Tag) string {
+	pairs := make([]string, 0, len(tags))
+	for _, t := range tags {
+		pairs = append(pairs, t.Key+"="+t.Value)
+	}
+	slices.Sort(pairs)
+
+	h := fnv.New64a()
+	for _, p := range pairs {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
 func (d *TagLimit) initOnce() error {
 	if d.init {
 		return nil
 	}
-	if len(d.Keep) > d.Limit {
-		return fmt.Errorf("%d keep tags is greater than %d total tag limit", len(d.Keep), d.Limit)
+	limit := d.Limit
+	if d.HashDrops {
+		if d.HashTagKey == "" {
+			d.HashTagKey = "tags_hash"
+		}
+		limit--
+		if limit < 0 {
+			return fmt.Errorf("tag limit of %d leaves no room for the hash tag", d.Limit)
+		}
+		if slices.Contains(d.Keep, d.HashTagKey) || slices.Contains(d.Priority, d.HashTagKey) {
+			return fmt.Errorf("hash tag %q must not be part of the keep or priority lists", d.HashTagKey)
+		}
+	}
+	if len(d.Keep) > limit {
+		return fmt.Errorf("%d keep tags is greater than %d total tag limit", len(d.Keep), limit)
 	}
 	d.keepTags = make(map[string]string)
 	// convert list of tags-to-keep to a map so we can do constant-time lookups
 	for _, tagKey := range d.Keep {
 		d.keepTags[tagKey] = ""
 	}
+	d.priorities = make(map[string]int, len(d.Priority))
+	for i, tagKey := range d.Priority {
+		if _, found := d.priorities[tagKey]; found {
+			return fmt.Errorf("duplicate tag %q in priority list", tagKey)
+		}
+		d.priorities[tagKey] = i
+	}
 	d.init = true
 	return nil
 }
@@ -148,3 +148,100 @@ func TestTracking(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestPriority(t *testing.T) {
+	input := mustMetric("foo", map[string]string{
+		"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6",
+	}, time.Unix(0, 0))
+
+	plugin := &TagLimit{
+		Limit:    4,
+		Keep:     []string{"f"},
+		Priority: []string{"b", "a", "e"},
+	}
+
+	actual := plugin.Apply(input)
+	require.Equal(t, map[string]string{"a": "1", "b": "2", "e": "5", "f": "6"}, actual[0].Tags())
+
+	// Priority tags are removed in reverse order if required
+	input = mustMetric("foo", map[string]string{
+		"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6",
+	}, time.Unix(0, 0))
+	plugin = &TagLimit{
+		Limit:    2,
+		Keep:     []string{"f"},
+		Priority: []string{"b", "a", "e"},
+	}
+
+	actual = plugin.Apply(input)
+	require.Equal(t, map[string]string{"b": "2", "f": "6"}, actual[0].Tags())
+}
+
+func TestHashDroppedTags(t *testing.T) {
+	plugin := &TagLimit{
+		Limit:     3,
+		Keep:      []string{"environment"},
+		Priority:  []string{"region"},
+		HashDrops: true,
+	}
+
+	m1 := mustMetric("throughput", map[string]string{
+		"environment": "qa", "month": "Jun", "path": "/api", "region": "us-east1",
+	}, time.Unix(0, 0))
+	m2 := mustMetric("throughput", map[string]string{
+		"environment": "qa", "month": "Jun", "path": "/web", "region": "us-east1",
+	}, time.Unix(0, 0))
+	m3 := mustMetric("throughput", map[string]string{
+		"environment": "qa", "region": "us-east1",
+	}, time.Unix(0, 0))
+
+	actual := plugin.Apply(m1, m2, m3)
+	require.Equal(t, map[string]string{
+		"environment": "qa", "region": "us-east1", "tags_hash": "9b750621911fc0c0",
+	}, actual[0].Tags())
+
+	// Different removed tags result in a different hash
+	h1, _ := actual[0].GetTag("tags_hash")
+	h2, found := actual[1].GetTag("tags_hash")
+	require.True(t, found)
+	require.Len(t, actual[1].TagList(), 3)
+	require.NotEqual(t, h1, h2)
+
+	// Metrics under the limit are not modified
+	require.Equal(t, map[string]string{"environment": "qa", "region": "us-east1"}, actual[2].Tags())
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TagLimit
+		expected string
+	}{
+		{
+			name:     "too many keep tags",
+			plugin:   &TagLimit{Limit: 1, Keep: []string{"a", "b"}},
+			expected: "2 keep tags is greater than 1 total tag limit",
+		},
+		{
+			name:     "too many keep tags with hash",
+			plugin:   &TagLimit{Limit: 2, Keep: []string{"a", "b"}, HashDrops: true},
+			expected: "2 keep tags is greater than 1 total tag limit",
+		},
+		{
+			name:     "hash tag in keep list",
+			plugin:   &TagLimit{Limit: 2, Keep: []string{"tags_hash"}, HashDrops: true},
+			expected: `hash tag "tags_hash" must not be part of the keep or priority lists`,
+		},
+		{
+			name:     "duplicate priority",
+			plugin:   &TagLimit{Limit: 2, Priority: []string{"a", "a"}},
+			expected: `duplicate tag "a" in priority list`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.initOnce(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package template_rename
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// templateMetric exposes the metric and the tag or field currently renamed
+// to the templates
+type templateMetric struct {
+	metric telegraf.Metric
+	key    string
+	value  interface{}
+}
+
+func (m *templateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *templateMetric) Tag(key string) string {
+	v, _ := m.metric.GetTag(key)
+	return v
+}
+
+func (m *templateMetric) Field(key string) interface{} {
+	v, _ := m.metric.GetField(key)
+	return v
+}
+
+func (m *templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
+
+func (m *templateMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+func (m *templateMetric) Fields() map[string]interface{} {
+	return m.metric.Fields()
+}
+
+func (m *templateMetric) Key() string {
+	return m.key
+}
+
+func (m *templateMetric) Value() interface{} {
+	return m.value
+}
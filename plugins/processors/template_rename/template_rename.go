@@ -0,0 +1,160 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package template_rename
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TemplateRename struct {
+	Measurement string          `toml:"measurement"`
+	TagKey      string          `toml:"tag_key"`
+	FieldKey    string          `toml:"field_key"`
+	Tags        []string        `toml:"tags"`
+	Fields      []string        `toml:"fields"`
+	Log         telegraf.Logger `toml:"-"`
+
+	tmplMeasurement *template.Template
+	tmplTag         *template.Template
+	tmplField       *template.Template
+	tagFilter       filter.Filter
+	fieldFilter     filter.Filter
+}
+
+type rename struct {
+	from  string
+	to    string
+	value interface{}
+}
+
+func (*TemplateRename) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *TemplateRename) Init() error {
+	if r.Measurement == "" && r.TagKey == "" && r.FieldKey == "" {
+		return errors.New("no template defined")
+	}
+
+	var err error
+	if r.tmplMeasurement, err = parse("measurement", r.Measurement); err != nil {
+		return err
+	}
+	if r.tmplTag, err = parse("tag_key", r.TagKey); err != nil {
+		return err
+	}
+	if r.tmplField, err = parse("field_key", r.FieldKey); err != nil {
+		return err
+	}
+
+	if len(r.Tags) == 0 {
+		r.Tags = []string{"*"}
+	}
+	if r.tagFilter, err = filter.Compile(r.Tags); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if len(r.Fields) == 0 {
+		r.Fields = []string{"*"}
+	}
+	if r.fieldFilter, err = filter.Compile(r.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TemplateRename) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		// Evaluate all templates on the original metric before modifying it
+		tm := &templateMetric{metric: m}
+
+		var name string
+		if r.tmplMeasurement != nil {
+			name = r.execute(r.tmplMeasurement, tm)
+		}
+
+		var tags []rename
+		if r.tmplTag != nil {
+			for _, tag := range m.TagList() {
+				if !r.tagFilter.Match(tag.Key) {
+					continue
+				}
+				tm.key, tm.value = tag.Key, tag.Value
+				if key := r.execute(r.tmplTag, tm); key != "" && key != tag.Key {
+					tags = append(tags, rename{from: tag.Key, to: key, value: tag.Value})
+				}
+			}
+		}
+
+		var fields []rename
+		if r.tmplField != nil {
+			for _, field := range m.FieldList() {
+				if !r.fieldFilter.Match(field.Key) {
+					continue
+				}
+				tm.key, tm.value = field.Key, field.Value
+				if key := r.execute(r.tmplField, tm); key != "" && key != field.Key {
+					fields = append(fields, rename{from: field.Key, to: key, value: field.Value})
+				}
+			}
+		}
+
+		// Remove all renamed keys first to allow swapping names
+		for _, tag := range tags {
+			m.RemoveTag(tag.from)
+		}
+		for _, tag := range tags {
+			m.AddTag(tag.to, tag.value.(string))
+		}
+		for _, field := range fields {
+			m.RemoveField(field.from)
+		}
+		for _, field := range fields {
+			m.AddField(field.to, field.value)
+		}
+		if name != "" {
+			m.SetName(name)
+		}
+	}
+
+	return in
+}
+
+// execute returns the result of the template or an empty string on errors
+func (r *TemplateRename) execute(tmpl *template.Template, tm *templateMetric) string {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, tm); err != nil {
+		r.Log.Errorf("Executing %s template failed: %v", tmpl.Name(), err)
+		return ""
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func parse(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s template failed: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func init() {
+	processors.Add("template_rename", func() telegraf.Processor {
+		return &TemplateRename{}
+	})
+}
@@ -0,0 +1,142 @@
+package template_rename
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &TemplateRename{}
+	require.ErrorContains(t, plugin.Init(), "no template defined")
+
+	plugin = &TemplateRename{TagKey: "{{ .Key"}
+	require.ErrorContains(t, plugin.Init(), "creating tag_key template failed")
+}
+
+func TestRename(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		plugin   *TemplateRename
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name:   "measurement from tag",
+			plugin: &TemplateRename{Measurement: `{{ .Tag "service" }}_{{ .Name }}`},
+			input: metric.New(
+				"requests",
+				map[string]string{"service": "checkout"},
+				map[string]interface{}{"count": int64(5)},
+				now,
+			),
+			expected: metric.New(
+				"checkout_requests",
+				map[string]string{"service": "checkout"},
+				map[string]interface{}{"count": int64(5)},
+				now,
+			),
+		},
+		{
+			name: "tag keys",
+			plugin: &TemplateRename{
+				TagKey: `{{ .Key | lower }}`,
+				Tags:   []string{"Host*", "Region"},
+			},
+			input: metric.New(
+				"cpu",
+				map[string]string{"HostName": "web01", "Region": "eu", "DC": "fra"},
+				map[string]interface{}{"usage": 42.0},
+				now,
+			),
+			expected: metric.New(
+				"cpu",
+				map[string]string{"hostname": "web01", "region": "eu", "DC": "fra"},
+				map[string]interface{}{"usage": 42.0},
+				now,
+			),
+		},
+		{
+			name: "field keys using tags and values",
+			plugin: &TemplateRename{
+				FieldKey: `{{ .Tag "unit" | printf "%s_" }}{{ .Key | replace "." "_" }}{{ if kindIs "string" .Value }}_text{{ end }}`,
+			},
+			input: metric.New(
+				"sensor",
+				map[string]string{"unit": "celsius"},
+				map[string]interface{}{"temp.inner": 21.5, "state": "ok"},
+				now,
+			),
+			expected: metric.New(
+				"sensor",
+				map[string]string{"unit": "celsius"},
+				map[string]interface{}{"celsius_temp_inner": 21.5, "celsius_state_text": "ok"},
+				now,
+			),
+		},
+		{
+			name: "swap tag names",
+			plugin: &TemplateRename{
+				TagKey: `{{ if eq .Key "a" }}b{{ else }}a{{ end }}`,
+			},
+			input: metric.New(
+				"swap",
+				map[string]string{"a": "1", "b": "2"},
+				map[string]interface{}{"value": 1},
+				now,
+			),
+			expected: metric.New(
+				"swap",
+				map[string]string{"a": "2", "b": "1"},
+				map[string]interface{}{"value": 1},
+				now,
+			),
+		},
+		{
+			name: "empty result keeps name",
+			plugin: &TemplateRename{
+				Measurement: `{{ .Tag "missing" }}`,
+				FieldKey:    `{{ if ne .Key "keep" }}{{ .Key | upper }}{{ end }}`,
+			},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"keep": 1, "value": 2},
+				now,
+			),
+			expected: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"keep": 1, "VALUE": 2},
+				now,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	plugin := &TemplateRename{
+		Measurement: `{{ .Field "value" | upper }}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0))
+	actual := plugin.Apply(input.Copy())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input}, actual)
+}
@@ -0,0 +1,251 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package throttle
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Throttle struct {
+	Limit      int             `toml:"limit"`
+	Window     config.Duration `toml:"window"`
+	Mode       string          `toml:"mode"`
+	SeriesTags []string        `toml:"series_tags"`
+	Log        telegraf.Logger `toml:"-"`
+
+	// number of metrics passed in the current window of each series in head
+	// mode
+	counts map[uint64]*counter
+
+	// windows not yet emitted in tail and random mode in the order of their
+	// creation
+	pending []*window
+	index   map[windowKey]*window
+
+	now  func() time.Time
+	intn func(int) int
+}
+
+type windowKey struct {
+	id    uint64
+	start int64
+}
+
+// counter contains the number of metrics passed in the latest window of a
+// series and the arrival time of the last metric
+type counter struct {
+	start int64
+	n     int
+	seen  time.Time
+}
+
+// window holds back the selected metrics of a series within one window
+type window struct {
+	key     windowKey
+	end     time.Time
+	seen    int
+	samples []sample
+}
+
+// sample is a selected metric with its arrival sequence number used to
+// restore the order of the metrics
+type sample struct {
+	seq    int
+	metric telegraf.Metric
+}
+
+func (*Throttle) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Throttle) Init() error {
+	if t.Limit < 1 {
+		return errors.New("limit must be at least one")
+	}
+	if t.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+
+	switch t.Mode {
+	case "":
+		t.Mode = "head"
+	case "head", "tail", "random":
+	default:
+		return fmt.Errorf("invalid mode %q", t.Mode)
+	}
+
+	t.counts = make(map[uint64]*counter)
+	t.index = make(map[windowKey]*window)
+	if t.now == nil {
+		t.now = time.Now
+	}
+	if t.intn == nil {
+		t.intn = rand.Intn
+	}
+
+	return nil
+}
+
+func (t *Throttle) ApplyBatch(in []telegraf.Metric) []telegraf.Metric {
+	if t.Mode == "head" {
+		return t.head(in)
+	}
+
+	// Select the metrics of each window, the metrics not selected are dropped
+	duration := time.Duration(t.Window)
+	for _, m := range in {
+		key := t.key(m)
+		w, found := t.index[key]
+		if !found {
+			w = &window{
+				key: key,
+				end: time.Unix(0, key.start).Add(duration),
+			}
+			t.index[key] = w
+			t.pending = append(t.pending, w)
+		}
+		w.seen++
+		s := sample{seq: w.seen, metric: m}
+
+		switch {
+		case len(w.samples) < t.Limit:
+			w.samples = append(w.samples, s)
+		case t.Mode == "tail":
+			w.samples[0].metric.Drop()
+			copy(w.samples, w.samples[1:])
+			w.samples[len(w.samples)-1] = s
+		default:
+			// Reservoir sampling keeping each metric of the window with the
+			// same probability
+			if i := t.intn(w.seen); i < t.Limit {
+				w.samples[i].metric.Drop()
+				w.samples[i] = s
+			} else {
+				m.Drop()
+			}
+		}
+	}
+
+	// A window is complete if its time range passed or if the series already
+	// received metrics for a later window
+	latest := make(map[uint64]int64, len(t.pending))
+	for _, w := range t.pending {
+		latest[w.key.id] = max(latest[w.key.id], w.key.start)
+	}
+	now := t.now()
+
+	var out []telegraf.Metric
+	remaining := t.pending[:0]
+	for _, w := range t.pending {
+		if w.end.After(now) && w.key.start == latest[w.key.id] {
+			remaining = append(remaining, w)
+			continue
+		}
+		out = w.emit(out)
+		delete(t.index, w.key)
+	}
+	clear(t.pending[len(remaining):])
+	t.pending = remaining
+
+	return out
+}
+
+// Finalize emits the metrics of the incomplete windows when stopping
+func (t *Throttle) Finalize() []telegraf.Metric {
+	var out []telegraf.Metric
+	for _, w := range t.pending {
+		out = w.emit(out)
+	}
+	t.pending = nil
+	t.index = make(map[windowKey]*window)
+
+	return out
+}
+
+// head passes the first metrics of each window and drops the remaining ones
+func (t *Throttle) head(in []telegraf.Metric) []telegraf.Metric {
+	now := t.now()
+	out := in[:0]
+	for _, m := range in {
+		key := t.key(m)
+		c, found := t.counts[key.id]
+		if !found {
+			c = &counter{start: key.start}
+			t.counts[key.id] = c
+		}
+		c.seen = now
+
+		// Late metrics of previous windows count against the latest window
+		if key.start > c.start {
+			c.start = key.start
+			c.n = 0
+		}
+		if c.n >= t.Limit {
+			m.Drop()
+			continue
+		}
+		c.n++
+		out = append(out, m)
+	}
+
+	// Forget the series not seen for a while
+	cutoff := now.Add(-2 * time.Duration(t.Window))
+	for id, c := range t.counts {
+		if c.seen.Before(cutoff) {
+			delete(t.counts, id)
+		}
+	}
+
+	return out
+}
+
+// key returns the series and window of the metric
+func (t *Throttle) key(m telegraf.Metric) windowKey {
+	start := m.Time().Truncate(time.Duration(t.Window)).UnixNano()
+	if len(t.SeriesTags) == 0 {
+		return windowKey{id: m.HashID(), start: start}
+	}
+
+	// Identify the series by the name and the selected tags only
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+	for _, k := range t.SeriesTags {
+		if v, found := m.GetTag(k); found {
+			h.Write([]byte(k))
+			h.Write([]byte("\x00"))
+			h.Write([]byte(v))
+		}
+		h.Write([]byte("\n"))
+	}
+	return windowKey{id: h.Sum64(), start: start}
+}
+
+func (w *window) emit(out []telegraf.Metric) []telegraf.Metric {
+	// Random sampling replaces samples in place so restore the arrival order
+	sort.Slice(w.samples, func(i, j int) bool { return w.samples[i].seq < w.samples[j].seq })
+	for _, s := range w.samples {
+		out = append(out, s.metric)
+	}
+	return out
+}
+
+func init() {
+	processors.AddBatch("throttle", func() telegraf.BatchProcessor {
+		return &Throttle{
+			Window: config.Duration(time.Minute),
+		}
+	})
+}
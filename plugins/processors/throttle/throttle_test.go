@@ -0,0 +1,175 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var start = time.Unix(1700000040, 0)
+
+// events returns the given number of metrics of a series one second apart
+func events(tags map[string]string, n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			"event",
+			tags,
+			map[string]interface{}{"value": int64(i)},
+			start.Add(time.Duration(i)*time.Second),
+		))
+	}
+	return metrics
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &Throttle{Window: config.Duration(time.Minute)}
+	require.ErrorContains(t, plugin.Init(), "limit must be at least one")
+
+	plugin = &Throttle{Limit: 1}
+	require.ErrorContains(t, plugin.Init(), "window must be positive")
+
+	plugin = &Throttle{Limit: 1, Window: config.Duration(time.Minute), Mode: "middle"}
+	require.ErrorContains(t, plugin.Init(), `invalid mode "middle"`)
+}
+
+func TestHead(t *testing.T) {
+	plugin := &Throttle{
+		Limit:  2,
+		Window: config.Duration(time.Minute),
+		Log:    &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	a := events(map[string]string{"source": "a"}, 4)
+	b := events(map[string]string{"source": "b"}, 1)
+	input := []telegraf.Metric{a[0], b[0], a[1], a[2], a[3]}
+	expected := []telegraf.Metric{a[0], b[0], a[1]}
+
+	// Metrics must be passed immediately
+	actual := plugin.ApplyBatch(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The limit must apply across batches of the same window
+	actual = plugin.ApplyBatch(a[:1])
+	require.Empty(t, actual)
+
+	// A new window starts a new count
+	next := metric.New("event", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(5)}, start.Add(time.Minute))
+	actual = plugin.ApplyBatch([]telegraf.Metric{next})
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{next}, actual)
+}
+
+func TestTail(t *testing.T) {
+	now := start
+	plugin := &Throttle{
+		Limit:  2,
+		Window: config.Duration(time.Minute),
+		Mode:   "tail",
+		Log:    &testutil.Logger{},
+		now:    func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	a := events(map[string]string{"source": "a"}, 5)
+	b := events(map[string]string{"source": "b"}, 1)
+
+	// Incomplete windows are held back
+	actual := plugin.ApplyBatch(append(a[:3:3], b...))
+	require.Empty(t, actual)
+
+	// Window of series a is completed by a metric of a later window
+	next := metric.New("event", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(5)}, start.Add(time.Minute))
+	actual = plugin.ApplyBatch(append(a[3:], next))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{a[3], a[4]}, actual)
+
+	// Window of series b is completed by the time passing
+	now = start.Add(time.Minute)
+	actual = plugin.ApplyBatch(nil)
+	testutil.RequireMetricsEqual(t, b, actual)
+
+	// Remaining windows are emitted when stopping
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{next}, plugin.Finalize())
+}
+
+func TestRandom(t *testing.T) {
+	// Fake random source cycling through the given indices
+	indices := []int{0, 5, 1}
+	plugin := &Throttle{
+		Limit:  2,
+		Window: config.Duration(time.Minute),
+		Mode:   "random",
+		Log:    &testutil.Logger{},
+		now:    func() time.Time { return start.Add(time.Minute) },
+		intn: func(n int) int {
+			i := indices[0] % n
+			indices = indices[1:]
+			return i
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The third metric replaces the first, the fourth is dropped and the
+	// fifth replaces the second sample
+	input := events(map[string]string{"source": "a"}, 5)
+	actual := plugin.ApplyBatch(input)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input[2], input[4]}, actual)
+}
+
+func TestSeriesTags(t *testing.T) {
+	plugin := &Throttle{
+		Limit:      1,
+		Window:     config.Duration(time.Minute),
+		SeriesTags: []string{"host"},
+		Log:        &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("event", map[string]string{"host": "a", "id": "1"}, map[string]interface{}{"value": 1}, start),
+		metric.New("event", map[string]string{"host": "a", "id": "2"}, map[string]interface{}{"value": 2}, start),
+		metric.New("event", map[string]string{"host": "b", "id": "3"}, map[string]interface{}{"value": 3}, start),
+		metric.New("event", map[string]string{"id": "4"}, map[string]interface{}{"value": 4}, start),
+		metric.New("event", map[string]string{"id": "5"}, map[string]interface{}{"value": 5}, start),
+	}
+	expected := []telegraf.Metric{input[0], input[2], input[3]}
+
+	actual := plugin.ApplyBatch(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	for _, mode := range []string{"head", "tail", "random"} {
+		t.Run(mode, func(t *testing.T) {
+			var delivered int
+			notify := func(telegraf.DeliveryInfo) {
+				delivered++
+			}
+
+			input := make([]telegraf.Metric, 0, 5)
+			for _, m := range events(map[string]string{}, 5) {
+				tm, _ := metric.WithTracking(m, notify)
+				input = append(input, tm)
+			}
+
+			plugin := &Throttle{
+				Limit:  2,
+				Window: config.Duration(time.Minute),
+				Mode:   mode,
+				Log:    &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.ApplyBatch(input)
+			actual = append(actual, plugin.Finalize()...)
+			require.Len(t, actual, 2)
+			require.Equal(t, 3, delivered)
+		})
+	}
+}
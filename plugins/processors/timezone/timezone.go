@@ -0,0 +1,135 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package timezone
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Timezone struct {
+	SourceTimezone      string          `toml:"source_timezone"`
+	MetricTimestamp     bool            `toml:"metric_timestamp"`
+	Fields              []string        `toml:"fields"`
+	SourceFormat        string          `toml:"source_format"`
+	DestinationTimezone string          `toml:"destination_timezone"`
+	DestinationFormat   string          `toml:"destination_format"`
+	Log                 telegraf.Logger `toml:"-"`
+
+	sourceLocation      *time.Location
+	destinationLocation *time.Location
+	fieldFilter         filter.Filter
+}
+
+func (*Timezone) SampleConfig() string {
+	return sampleConfig
+}
+
+func (tz *Timezone) Init() error {
+	if tz.SourceTimezone == "" {
+		return errors.New("source_timezone is required")
+	}
+	if !tz.MetricTimestamp && len(tz.Fields) == 0 {
+		return errors.New("neither metric_timestamp nor fields are set")
+	}
+
+	var err error
+	tz.sourceLocation, err = time.LoadLocation(tz.SourceTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid source_timezone %q: %w", tz.SourceTimezone, err)
+	}
+
+	if len(tz.Fields) == 0 {
+		return nil
+	}
+
+	tz.fieldFilter, err = filter.Compile(tz.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	if tz.SourceFormat == "" {
+		return errors.New("source_format is required for converting fields")
+	}
+
+	switch tz.DestinationFormat {
+	case "":
+		tz.DestinationFormat = time.RFC3339
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		if time.Now().Format(tz.DestinationFormat) == tz.DestinationFormat {
+			return fmt.Errorf("invalid destination_format %q", tz.DestinationFormat)
+		}
+	}
+
+	if tz.DestinationTimezone == "" {
+		tz.DestinationTimezone = "UTC"
+	}
+	tz.destinationLocation, err = time.LoadLocation(tz.DestinationTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid destination_timezone %q: %w", tz.DestinationTimezone, err)
+	}
+
+	return nil
+}
+
+func (tz *Timezone) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		if tz.MetricTimestamp {
+			m.SetTime(inLocation(m.Time(), tz.sourceLocation))
+		}
+
+		if tz.fieldFilter == nil {
+			continue
+		}
+		for _, field := range m.FieldList() {
+			if !tz.fieldFilter.Match(field.Key) {
+				continue
+			}
+			ts, err := internal.ParseTimestamp(tz.SourceFormat, field.Value, tz.sourceLocation)
+			if err != nil {
+				tz.Log.Errorf("Parsing field %q of %q failed: %v", field.Key, m.Name(), err)
+				continue
+			}
+			m.AddField(field.Key, tz.format(ts))
+		}
+	}
+
+	return in
+}
+
+func (tz *Timezone) format(ts time.Time) interface{} {
+	switch tz.DestinationFormat {
+	case "unix":
+		return ts.Unix()
+	case "unix_ms":
+		return ts.UnixMilli()
+	case "unix_us":
+		return ts.UnixMicro()
+	case "unix_ns":
+		return ts.UnixNano()
+	}
+	return ts.In(tz.destinationLocation).Format(tz.DestinationFormat)
+}
+
+// inLocation returns the time with the same wall-clock time in UTC as the
+// given time but in the given location
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+func init() {
+	processors.Add("timezone", func() telegraf.Processor {
+		return &Timezone{}
+	})
+}
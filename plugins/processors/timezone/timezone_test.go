@@ -0,0 +1,232 @@
+package timezone
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Timezone
+		expected string
+	}{
+		{
+			name:     "no source timezone",
+			plugin:   &Timezone{MetricTimestamp: true},
+			expected: "source_timezone is required",
+		},
+		{
+			name:     "nothing to convert",
+			plugin:   &Timezone{SourceTimezone: "UTC"},
+			expected: "neither metric_timestamp nor fields are set",
+		},
+		{
+			name:     "invalid source timezone",
+			plugin:   &Timezone{SourceTimezone: "Mars/Olympus_Mons", MetricTimestamp: true},
+			expected: `invalid source_timezone "Mars/Olympus_Mons"`,
+		},
+		{
+			name:     "no source format",
+			plugin:   &Timezone{SourceTimezone: "UTC", Fields: []string{"time"}},
+			expected: "source_format is required for converting fields",
+		},
+		{
+			name: "invalid destination format",
+			plugin: &Timezone{
+				SourceTimezone:    "UTC",
+				Fields:            []string{"time"},
+				SourceFormat:      "unix",
+				DestinationFormat: "foo",
+			},
+			expected: `invalid destination_format "foo"`,
+		},
+		{
+			name: "invalid destination timezone",
+			plugin: &Timezone{
+				SourceTimezone:      "UTC",
+				Fields:              []string{"time"},
+				SourceFormat:        "unix",
+				DestinationTimezone: "Mars/Olympus_Mons",
+			},
+			expected: `invalid destination_timezone "Mars/Olympus_Mons"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMetricTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "summer time",
+			input:    time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "winter time",
+			input:    time.Date(2024, 1, 15, 12, 0, 0, 500, time.UTC),
+			expected: time.Date(2024, 1, 15, 11, 0, 0, 500, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Timezone{
+				SourceTimezone:  "Europe/Berlin",
+				MetricTimestamp: true,
+				Log:             &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("device", map[string]string{}, map[string]interface{}{"value": 42}, tt.input)
+			actual := plugin.Apply(input)
+			require.Len(t, actual, 1)
+			require.True(t, tt.expected.Equal(actual[0].Time()), "expected %v but got %v", tt.expected, actual[0].Time())
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name                string
+		fields              []string
+		sourceFormat        string
+		destinationTimezone string
+		destinationFormat   string
+		input               map[string]interface{}
+		expected            map[string]interface{}
+	}{
+		{
+			name:         "to rfc3339 in utc",
+			fields:       []string{"event_*"},
+			sourceFormat: "2006-01-02 15:04:05",
+			input: map[string]interface{}{
+				"event_start": "2024-07-01 13:30:00",
+				"event_end":   "2024-01-15 13:30:00",
+				"other":       "2024-07-01 13:30:00",
+			},
+			expected: map[string]interface{}{
+				"event_start": "2024-07-01T11:30:00Z",
+				"event_end":   "2024-01-15T12:30:00Z",
+				"other":       "2024-07-01 13:30:00",
+			},
+		},
+		{
+			name:                "to other timezone",
+			fields:              []string{"time"},
+			sourceFormat:        "2006-01-02 15:04:05",
+			destinationTimezone: "America/New_York",
+			destinationFormat:   "2006-01-02 15:04:05 MST",
+			input:               map[string]interface{}{"time": "2024-07-01 13:30:00"},
+			expected:            map[string]interface{}{"time": "2024-07-01 07:30:00 EDT"},
+		},
+		{
+			name:              "to unix",
+			fields:            []string{"time"},
+			sourceFormat:      "2006-01-02 15:04:05",
+			destinationFormat: "unix_ms",
+			input:             map[string]interface{}{"time": "2024-07-01 12:00:00"},
+			expected:          map[string]interface{}{"time": int64(1719828000000)},
+		},
+		{
+			name:         "from unix",
+			fields:       []string{"time"},
+			sourceFormat: "unix",
+			input:        map[string]interface{}{"time": int64(1719828000)},
+			expected:     map[string]interface{}{"time": "2024-07-01T10:00:00Z"},
+		},
+		{
+			name:         "invalid value",
+			fields:       []string{"time"},
+			sourceFormat: "2006-01-02 15:04:05",
+			input:        map[string]interface{}{"time": "yesterday"},
+			expected:     map[string]interface{}{"time": "yesterday"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Timezone{
+				SourceTimezone:      "Europe/Berlin",
+				Fields:              tt.fields,
+				SourceFormat:        tt.sourceFormat,
+				DestinationTimezone: tt.destinationTimezone,
+				DestinationFormat:   tt.destinationFormat,
+				Log:                 &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("device", map[string]string{}, tt.input, now)
+			expected := []telegraf.Metric{metric.New("device", map[string]string{}, tt.expected, now)}
+			testutil.RequireMetricsEqual(t, expected, plugin.Apply(input))
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("device", map[string]string{}, map[string]interface{}{"time": "2024-07-01 13:30:00"}, time.Unix(1719835200, 0)),
+		metric.New("device", map[string]string{}, map[string]interface{}{"time": "2024-01-15 13:30:00"}, time.Unix(1705320000, 0)),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("device", map[string]string{}, map[string]interface{}{"time": "2024-07-01T11:30:00Z"}, time.Unix(1719828000, 0)),
+		metric.New("device", map[string]string{}, map[string]interface{}{"time": "2024-01-15T12:30:00Z"}, time.Unix(1705316400, 0)),
+	}
+
+	plugin := &Timezone{
+		SourceTimezone:  "Europe/Berlin",
+		MetricTimestamp: true,
+		Fields:          []string{"time"},
+		SourceFormat:    "2006-01-02 15:04:05",
+		Log:             &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
+}
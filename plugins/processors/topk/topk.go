@@ -28,6 +28,8 @@ type TopK struct {
 	AddGroupByTag      string          `toml:"add_groupby_tag"`
 	AddRankFields      []string        `toml:"add_rank_fields"`
 	AddAggregateFields []string        `toml:"add_aggregate_fields"`
+	PerMeasurement     bool            `toml:"per_measurement"`
+	OtherSeries        string          `toml:"other_series"`
 	Log                telegraf.Logger `toml:"-"`
 
 	cache           map[string][]telegraf.Metric
@@ -197,42 +199,55 @@ func (t *TopK) push() []telegraf.Metric {
 		aggregations = append(aggregations, metricAggregation{groupByKey: k, values: aggregator(ms, t.Fields)})
 	}
 
+	// Rank the groups of each measurement independently if requested
+	partitions := [][]metricAggregation{aggregations}
+	if t.PerMeasurement {
+		partitions = t.partitionByName(aggregations)
+	}
+
 	// The return value that will hold the returned metrics
 	var ret = make([]telegraf.Metric, 0)
 	// Get the top K metrics for each field and add them to the return value
 	addedKeys := make(map[string]bool)
 	for _, field := range t.Fields {
-		// Sort the aggregations
-		sortMetrics(aggregations, field, t.Bottomk)
-
-		// Create a one dimensional list with the top K metrics of each key
-		for i, ag := range aggregations[0:min(t.K, len(aggregations))] {
-			// Check whether of not we need to add fields of tags to the selected metrics
-			if len(t.aggFieldSet) != 0 || len(t.rankFieldSet) != 0 || t.AddGroupByTag != "" {
-				for _, m := range t.cache[ag.groupByKey] {
-					// Add the aggregation final value if requested
-					_, addAggField := t.aggFieldSet[field]
-					if addAggField && m.HasField(field) {
-						m.AddField(field+"_topk_aggregate", ag.values[field])
-					}
-
-					// Add the rank relative to the current field if requested
-					_, addRankField := t.rankFieldSet[field]
-					if addRankField && m.HasField(field) {
-						m.AddField(field+"_topk_rank", i+1)
+		for _, partition := range partitions {
+			// Sort the aggregations
+			sortMetrics(partition, field, t.Bottomk)
+
+			// Create a one dimensional list with the top K metrics of each key
+			for i, ag := range partition[0:min(t.K, len(partition))] {
+				// Check whether of not we need to add fields of tags to the selected metrics
+				if len(t.aggFieldSet) != 0 || len(t.rankFieldSet) != 0 || t.AddGroupByTag != "" {
+					for _, m := range t.cache[ag.groupByKey] {
+						// Add the aggregation final value if requested
+						_, addAggField := t.aggFieldSet[field]
+						if addAggField && m.HasField(field) {
+							m.AddField(field+"_topk_aggregate", ag.values[field])
+						}
+
+						// Add the rank relative to the current field if requested
+						_, addRankField := t.rankFieldSet[field]
+						if addRankField && m.HasField(field) {
+							m.AddField(field+"_topk_rank", i+1)
+						}
 					}
 				}
-			}
 
-			// Add metrics if we have not already appended them to the return value
-			_, ok := addedKeys[ag.groupByKey]
-			if !ok {
-				ret = append(ret, t.cache[ag.groupByKey]...)
-				addedKeys[ag.groupByKey] = true
+				// Add metrics if we have not already appended them to the return value
+				_, ok := addedKeys[ag.groupByKey]
+				if !ok {
+					ret = append(ret, t.cache[ag.groupByKey]...)
+					addedKeys[ag.groupByKey] = true
+				}
 			}
 		}
 	}
 
+	// Summarize the remaining groups instead of dropping them if requested
+	if t.OtherSeries != "" {
+		ret = append(ret, t.summarizeOthers(addedKeys, aggregator)...)
+	}
+
 	t.Reset()
 
 	result := make([]telegraf.Metric, 0, len(ret))
@@ -244,6 +259,80 @@ func (t *TopK) push() []telegraf.Metric {
 	return result
 }
 
+// partitionByName splits the aggregations by the measurement name of the
+// groups, sorted by name for a deterministic output order
+func (t *TopK) partitionByName(aggregations []metricAggregation) [][]metricAggregation {
+	byName := make(map[string][]metricAggregation)
+	for _, ag := range aggregations {
+		name := t.cache[ag.groupByKey][0].Name()
+		byName[name] = append(byName[name], ag)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	partitions := make([][]metricAggregation, 0, len(names))
+	for _, name := range names {
+		partitions = append(partitions, byName[name])
+	}
+	return partitions
+}
+
+// summarizeOthers creates one metric per measurement aggregating all metrics
+// of groups not selected. The group-by tags are set to the 'other_series'
+// value while all other tags are removed.
+func (t *TopK) summarizeOthers(selected map[string]bool, aggregator func([]telegraf.Metric, []string) map[string]float64) []telegraf.Metric {
+	others := make(map[string][]telegraf.Metric)
+	groups := make(map[string]int)
+	for k, ms := range t.cache {
+		if selected[k] {
+			continue
+		}
+		name := ms[0].Name()
+		others[name] = append(others[name], ms...)
+		groups[name]++
+	}
+
+	names := make([]string, 0, len(others))
+	for name := range others {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	summaries := make([]telegraf.Metric, 0, len(names))
+	for _, name := range names {
+		ms := others[name]
+		values := aggregator(ms, t.Fields)
+		if len(values) == 0 {
+			continue
+		}
+
+		tags := make(map[string]string)
+		var latest time.Time
+		for _, m := range ms {
+			for _, tag := range m.TagList() {
+				if t.tagsGlobs != nil && t.tagsGlobs.Match(tag.Key) {
+					tags[tag.Key] = t.OtherSeries
+				}
+			}
+			if m.Time().After(latest) {
+				latest = m.Time()
+			}
+		}
+
+		fields := make(map[string]interface{}, len(values)+1)
+		for field, value := range values {
+			fields[field] = value
+		}
+		fields["topk_other_series"] = int64(groups[name])
+		summaries = append(summaries, metric.New(name, tags, fields, latest))
+	}
+	return summaries
+}
+
 // Function that generates the aggregation functions
 func (t *TopK) getAggregationFunction(aggOperation string) (func([]telegraf.Metric, []string) map[string]float64, error) {
 	// This is a function aggregates a set of metrics using a given aggregation function
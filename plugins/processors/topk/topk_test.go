@@ -735,3 +735,63 @@ var metric26 = metric.New(
 )
 
 var metricsSet2 = []telegraf.Metric{metric21, metric22, metric23, metric24, metric25, metric26}
+
+func TestTopkPerMeasurementWithOther(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"pid": "1", "user": "root"}, map[string]interface{}{"value": 10.0}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"pid": "2", "user": "root"}, map[string]interface{}{"value": 5.0}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"pid": "3", "user": "jane"}, map[string]interface{}{"value": 2.0}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"pid": "4", "user": "jane"}, map[string]interface{}{"value": 1.0}, time.Unix(20, 0)),
+		metric.New("mem", map[string]string{"pid": "1"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0)),
+		metric.New("mem", map[string]string{"pid": "2"}, map[string]interface{}{"value": 3.0}, time.Unix(10, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"pid": "1", "user": "root"}, map[string]interface{}{"value": 10.0}, time.Unix(10, 0)),
+		metric.New("mem", map[string]string{"pid": "2"}, map[string]interface{}{"value": 3.0}, time.Unix(10, 0)),
+		metric.New(
+			"cpu",
+			map[string]string{"pid": "other"},
+			map[string]interface{}{"value": 8.0, "topk_other_series": int64(3)},
+			time.Unix(20, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"pid": "other"},
+			map[string]interface{}{"value": 1.0, "topk_other_series": int64(1)},
+			time.Unix(10, 0),
+		),
+	}
+
+	plugin := newTopK()
+	plugin.Period = 0
+	plugin.K = 1
+	plugin.Aggregation = "sum"
+	plugin.GroupBy = []string{"pid"}
+	plugin.PerMeasurement = true
+	plugin.OtherSeries = "other"
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
+
+func TestTopkGlobalWithoutOther(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"pid": "1"}, map[string]interface{}{"value": 10.0}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"pid": "2"}, map[string]interface{}{"value": 5.0}, time.Unix(10, 0)),
+		metric.New("mem", map[string]string{"pid": "1"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0)),
+		metric.New("mem", map[string]string{"pid": "2"}, map[string]interface{}{"value": 3.0}, time.Unix(10, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"pid": "1"}, map[string]interface{}{"value": 10.0}, time.Unix(10, 0)),
+	}
+
+	plugin := newTopK()
+	plugin.Period = 0
+	plugin.K = 1
+	plugin.GroupBy = []string{"pid"}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
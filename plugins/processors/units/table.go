@@ -0,0 +1,54 @@
+package units
+
+// unit defines the conversion of a value to the base unit of its kind by
+// base = value * factor + offset
+type unit struct {
+	kind   string
+	factor float64
+	offset float64
+	suffix string
+}
+
+var units = map[string]unit{
+	// Data with bytes as base unit
+	"bit":  {kind: "data", factor: 1.0 / 8, suffix: "bits"},
+	"Kbit": {kind: "data", factor: 1e3 / 8, suffix: "kbits"},
+	"Mbit": {kind: "data", factor: 1e6 / 8, suffix: "mbits"},
+	"Gbit": {kind: "data", factor: 1e9 / 8, suffix: "gbits"},
+	"B":    {kind: "data", factor: 1, suffix: "bytes"},
+	"KB":   {kind: "data", factor: 1e3, suffix: "kb"},
+	"MB":   {kind: "data", factor: 1e6, suffix: "mb"},
+	"GB":   {kind: "data", factor: 1e9, suffix: "gb"},
+	"TB":   {kind: "data", factor: 1e12, suffix: "tb"},
+	"KiB":  {kind: "data", factor: 1 << 10, suffix: "kib"},
+	"MiB":  {kind: "data", factor: 1 << 20, suffix: "mib"},
+	"GiB":  {kind: "data", factor: 1 << 30, suffix: "gib"},
+	"TiB":  {kind: "data", factor: 1 << 40, suffix: "tib"},
+
+	// Data rates with bytes per second as base unit
+	"bit/s":  {kind: "data rate", factor: 1.0 / 8, suffix: "bps"},
+	"Kbit/s": {kind: "data rate", factor: 1e3 / 8, suffix: "kbps"},
+	"Mbit/s": {kind: "data rate", factor: 1e6 / 8, suffix: "mbps"},
+	"Gbit/s": {kind: "data rate", factor: 1e9 / 8, suffix: "gbps"},
+	"B/s":    {kind: "data rate", factor: 1, suffix: "bytes_per_second"},
+	"KB/s":   {kind: "data rate", factor: 1e3, suffix: "kb_per_second"},
+	"MB/s":   {kind: "data rate", factor: 1e6, suffix: "mb_per_second"},
+	"GB/s":   {kind: "data rate", factor: 1e9, suffix: "gb_per_second"},
+	"KiB/s":  {kind: "data rate", factor: 1 << 10, suffix: "kib_per_second"},
+	"MiB/s":  {kind: "data rate", factor: 1 << 20, suffix: "mib_per_second"},
+	"GiB/s":  {kind: "data rate", factor: 1 << 30, suffix: "gib_per_second"},
+
+	// Time with seconds as base unit
+	"ns":  {kind: "time", factor: 1e-9, suffix: "ns"},
+	"us":  {kind: "time", factor: 1e-6, suffix: "us"},
+	"ms":  {kind: "time", factor: 1e-3, suffix: "ms"},
+	"s":   {kind: "time", factor: 1, suffix: "seconds"},
+	"min": {kind: "time", factor: 60, suffix: "minutes"},
+	"h":   {kind: "time", factor: 3600, suffix: "hours"},
+	"d":   {kind: "time", factor: 86400, suffix: "days"},
+
+	// Temperature with Kelvin as base unit
+	"C": {kind: "temperature", factor: 1, offset: 273.15, suffix: "celsius"},
+	"F": {kind: "temperature", factor: 5.0 / 9, offset: 459.67 * 5 / 9, suffix: "fahrenheit"},
+	"K": {kind: "temperature", factor: 1, suffix: "kelvin"},
+}
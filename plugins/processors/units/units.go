@@ -0,0 +1,144 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package units
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Units struct {
+	AppendSuffix bool            `toml:"append_suffix"`
+	Separator    string          `toml:"separator"`
+	Conversions  []*conversion   `toml:"conversion"`
+	Log          telegraf.Logger `toml:"-"`
+}
+
+type conversion struct {
+	Fields []string `toml:"fields"`
+	From   string   `toml:"from"`
+	To     string   `toml:"to"`
+	Suffix string   `toml:"suffix"`
+
+	filter filter.Filter
+	from   unit
+	to     unit
+}
+
+func (*Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Init() error {
+	if len(u.Conversions) == 0 {
+		return errors.New("no conversions defined")
+	}
+	if u.Separator == "" {
+		u.Separator = "_"
+	}
+
+	for i, c := range u.Conversions {
+		if err := c.init(); err != nil {
+			return fmt.Errorf("conversion %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		// Collect the converted fields first to not convert renamed fields
+		// again and to keep the iteration stable
+		converted := make(map[string]interface{})
+		var renamed []string
+		for _, field := range m.FieldList() {
+			c := u.match(field.Key)
+			if c == nil {
+				continue
+			}
+
+			// Booleans have no unit
+			if _, ok := field.Value.(bool); ok {
+				continue
+			}
+			v, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				u.Log.Debugf("Ignoring non-numeric field %q of metric %q: %v", field.Key, m.Name(), err)
+				continue
+			}
+
+			if !u.AppendSuffix {
+				field.Value = c.convert(v)
+				continue
+			}
+			converted[field.Key+u.Separator+c.Suffix] = c.convert(v)
+			renamed = append(renamed, field.Key)
+		}
+
+		for _, key := range renamed {
+			m.RemoveField(key)
+		}
+		for key, value := range converted {
+			m.AddField(key, value)
+		}
+	}
+	return in
+}
+
+// match returns the first conversion applying to the given field
+func (u *Units) match(key string) *conversion {
+	for _, c := range u.Conversions {
+		if c.filter.Match(key) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *conversion) init() error {
+	if len(c.Fields) == 0 {
+		return errors.New("no fields defined")
+	}
+	f, err := filter.Compile(c.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	c.filter = f
+
+	var found bool
+	if c.from, found = units[c.From]; !found {
+		return fmt.Errorf("unknown unit %q", c.From)
+	}
+	if c.to, found = units[c.To]; !found {
+		return fmt.Errorf("unknown unit %q", c.To)
+	}
+	if c.from.kind != c.to.kind {
+		return fmt.Errorf("cannot convert %s unit %q to %s unit %q", c.from.kind, c.From, c.to.kind, c.To)
+	}
+
+	if c.Suffix == "" {
+		c.Suffix = c.to.suffix
+	}
+
+	return nil
+}
+
+func (c *conversion) convert(value float64) float64 {
+	base := value*c.from.factor + c.from.offset
+	return (base - c.to.offset) / c.to.factor
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
@@ -0,0 +1,161 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		conversions []*conversion
+		expected    string
+	}{
+		{
+			name:     "no conversions",
+			expected: "no conversions defined",
+		},
+		{
+			name:        "no fields",
+			conversions: []*conversion{{From: "B", To: "MiB"}},
+			expected:    "no fields defined",
+		},
+		{
+			name:        "unknown source unit",
+			conversions: []*conversion{{Fields: []string{"*"}, From: "foo", To: "MiB"}},
+			expected:    `unknown unit "foo"`,
+		},
+		{
+			name:        "unknown target unit",
+			conversions: []*conversion{{Fields: []string{"*"}, From: "B", To: "mib"}},
+			expected:    `unknown unit "mib"`,
+		},
+		{
+			name:        "different kinds",
+			conversions: []*conversion{{Fields: []string{"*"}, From: "B", To: "B/s"}},
+			expected:    `cannot convert data unit "B" to data rate unit "B/s"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{Conversions: tt.conversions}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		value    float64
+		expected float64
+	}{
+		{from: "B", to: "MiB", value: 3 * 1024 * 1024, expected: 3},
+		{from: "GiB", to: "B", value: 2, expected: 2 * 1024 * 1024 * 1024},
+		{from: "bit", to: "B", value: 16, expected: 2},
+		{from: "ns", to: "ms", value: 1500000, expected: 1.5},
+		{from: "h", to: "s", value: 2, expected: 7200},
+		{from: "C", to: "F", value: 100, expected: 212},
+		{from: "F", to: "C", value: -40, expected: -40},
+		{from: "K", to: "C", value: 0, expected: -273.15},
+		{from: "Mbit/s", to: "B/s", value: 8, expected: 1e6},
+		{from: "B/s", to: "Kbit/s", value: 1000, expected: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			c := &conversion{Fields: []string{"*"}, From: tt.from, To: tt.to}
+			require.NoError(t, c.init())
+			require.InDelta(t, tt.expected, c.convert(tt.value), 1e-9)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	plugin := &Units{
+		Conversions: []*conversion{
+			{Fields: []string{"mem_*"}, From: "B", To: "MiB"},
+			{Fields: []string{"temp"}, From: "C", To: "F"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"device",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"mem_used":  int64(512 * 1024 * 1024),
+				"mem_total": uint64(1024 * 1024 * 1024),
+				"temp":      float64(20),
+				"status":    "ok",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"device",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"mem_used":  float64(512),
+				"mem_total": float64(1024),
+				"temp":      float64(68),
+				"status":    "ok",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestApplySuffix(t *testing.T) {
+	plugin := &Units{
+		AppendSuffix: true,
+		Conversions: []*conversion{
+			{Fields: []string{"latency"}, From: "ns", To: "ms"},
+			{Fields: []string{"rx", "tx"}, From: "bit/s", To: "B/s", Suffix: "Bps"},
+			{Fields: []string{"*"}, From: "ns", To: "s"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{},
+			map[string]interface{}{
+				"latency": int64(2500000),
+				"rx":      int64(800),
+				"tx":      int64(1600),
+				"name":    "eth0",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{},
+			map[string]interface{}{
+				"latency_ms": float64(2.5),
+				"rx_Bps":     float64(100),
+				"tx_Bps":     float64(200),
+				"name":       "eth0",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
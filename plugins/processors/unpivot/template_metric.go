@@ -0,0 +1,35 @@
+package unpivot
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// templateMetric exposes the source metric and the unpivoted field to the
+// name template
+type templateMetric struct {
+	metric telegraf.Metric
+	field  string
+}
+
+func (m *templateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *templateMetric) Field() string {
+	return m.field
+}
+
+func (m *templateMetric) Tag(key string) string {
+	v, _ := m.metric.GetTag(key)
+	return v
+}
+
+func (m *templateMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+func (m *templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
@@ -4,6 +4,8 @@ package unpivot
 import (
 	_ "embed"
 	"fmt"
+	"strings"
+	"text/template"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
@@ -14,9 +16,13 @@ import (
 var sampleConfig string
 
 type Unpivot struct {
-	FieldNameAs string `toml:"use_fieldname_as"`
-	TagKey      string `toml:"tag_key"`
-	ValueKey    string `toml:"value_key"`
+	FieldNameAs string          `toml:"use_fieldname_as"`
+	TagKey      string          `toml:"tag_key"`
+	ValueKey    string          `toml:"value_key"`
+	NameTmpl    string          `toml:"name_template"`
+	Log         telegraf.Logger `toml:"-"`
+
+	tmpl *template.Template
 }
 
 func (p *Unpivot) Init() error {
@@ -35,6 +41,14 @@ func (p *Unpivot) Init() error {
 		p.ValueKey = "value"
 	}
 
+	if p.NameTmpl != "" {
+		tmpl, err := template.New("name_template").Parse(p.NameTmpl)
+		if err != nil {
+			return fmt.Errorf("parsing name template failed: %w", err)
+		}
+		p.tmpl = tmpl
+	}
+
 	return nil
 }
 
@@ -67,6 +81,9 @@ func (p *Unpivot) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 			case "tag":
 				m.AddTag(p.TagKey, field.Key)
 			}
+			if p.tmpl != nil {
+				p.applyNameTemplate(m, src, field.Key)
+			}
 
 			results = append(results, m)
 		}
@@ -75,6 +92,21 @@ func (p *Unpivot) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 	return results
 }
 
+// applyNameTemplate sets the name of the unpivoted metric by executing the
+// name template for the source metric and field. On failure the name is kept.
+func (p *Unpivot) applyNameTemplate(m, src telegraf.Metric, field string) {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, &templateMetric{metric: src, field: field}); err != nil {
+		p.Log.Errorf("Executing name template for field %q of %q failed: %v", field, src.Name(), err)
+		return
+	}
+	if b.Len() == 0 {
+		p.Log.Errorf("Name template returned an empty name for field %q of %q", field, src.Name())
+		return
+	}
+	m.SetName(b.String())
+}
+
 func init() {
 	processors.Add("unpivot", func() telegraf.Processor {
 		return &Unpivot{}
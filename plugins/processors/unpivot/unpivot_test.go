@@ -231,6 +231,102 @@ func TestFieldMode(t *testing.T) {
 	}
 }
 
+func TestNameTemplate(t *testing.T) {
+	now := time.Now()
+	input := metric.New(
+		"cpu",
+		map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{
+			"time_idle": int64(42),
+			"time_user": int64(43),
+		},
+		now,
+	)
+
+	tests := []struct {
+		name        string
+		fieldNameAs string
+		template    string
+		expected    []telegraf.Metric
+	}{
+		{
+			name:     "tag mode",
+			template: "{{.Name}}_{{.Field}}",
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu_time_idle",
+					map[string]string{"cpu": "cpu0", "name": "time_idle"},
+					map[string]interface{}{"value": int64(42)},
+					now,
+				),
+				metric.New(
+					"cpu_time_user",
+					map[string]string{"cpu": "cpu0", "name": "time_user"},
+					map[string]interface{}{"value": int64(43)},
+					now,
+				),
+			},
+		},
+		{
+			name:        "metric mode",
+			fieldNameAs: "metric",
+			template:    `{{.Tag "cpu"}}.{{.Field}}`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu0.time_idle",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"value": int64(42)},
+					now,
+				),
+				metric.New(
+					"cpu0.time_user",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"value": int64(43)},
+					now,
+				),
+			},
+		},
+		{
+			name:        "empty name keeps name",
+			fieldNameAs: "metric",
+			template:    `{{.Tag "unknown"}}`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"time_idle",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"value": int64(42)},
+					now,
+				),
+				metric.New(
+					"time_user",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"value": int64(43)},
+					now,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Unpivot{
+				FieldNameAs: tt.fieldNameAs,
+				NameTmpl:    tt.template,
+				Log:         &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(input.Copy())
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestInvalidNameTemplate(t *testing.T) {
+	plugin := &Unpivot{NameTmpl: "{{.Name"}
+	require.ErrorContains(t, plugin.Init(), "parsing name template failed")
+}
+
 func TestTrackedMetricNotLost(t *testing.T) {
 	var mu sync.Mutex
 	delivered := make([]telegraf.DeliveryInfo, 0, 3)
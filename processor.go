@@ -39,3 +39,18 @@ type StreamingProcessor interface {
 	// accumulator.
 	Stop()
 }
+
+// BatchProcessor is a processor receiving metrics in batches. Telegraf buffers
+// the metrics and hands all metrics received since the last flush to the
+// processor on every flush, i.e. every flush_interval and when stopping. This
+// allows processors to operate deterministically per flush without keeping
+// timers of their own.
+type BatchProcessor interface {
+	PluginDescriber
+
+	// ApplyBatch processes the metrics received since the last flush and
+	// returns the metrics to pass downstream. The batch might be empty.
+	// Metrics you don't want to pass downstream should have metric.Drop()
+	// called, rather than simply omitting them from the result.
+	ApplyBatch(in []Metric) []Metric
+}
@@ -30,23 +30,31 @@ func ImportConfigurations(files, dirs []string) (*selection, int, error) {
 		plugins: make(map[string][]instance),
 	}
 
-	// Gather all configuration files
+	// Gather all configuration files, the directories are walked recursively
+	// in the same way as Telegraf does. Files specified multiple times, e.g.
+	// by overlapping directories, are only imported once.
 	var filenames []string
-	filenames = append(filenames, files...)
+	seen := make(map[string]bool)
+	add := func(fn string) {
+		if key := filepath.Clean(fn); !seen[key] {
+			seen[key] = true
+			filenames = append(filenames, fn)
+		}
+	}
+	for _, fn := range files {
+		add(fn)
+	}
 
 	for _, dir := range dirs {
-		// Walk the directory and get the packages
-		elements, err := os.ReadDir(dir)
+		if _, err := os.Stat(dir); err != nil {
+			return nil, 0, fmt.Errorf("reading directory %q failed: %w", dir, err)
+		}
+		fns, err := config.WalkDirectory(dir)
 		if err != nil {
 			return nil, 0, fmt.Errorf("reading directory %q failed: %w", dir, err)
 		}
-
-		for _, element := range elements {
-			if element.IsDir() || filepath.Ext(element.Name()) != ".conf" {
-				continue
-			}
-
-			filenames = append(filenames, filepath.Join(dir, element.Name()))
+		for _, fn := range fns {
+			add(fn)
 		}
 	}
 	if len(filenames) == 0 {
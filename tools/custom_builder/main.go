@@ -8,7 +8,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"strings"
+
+	"github.com/influxdata/telegraf/filter"
 )
 
 var buildTargets = []string{"build"}
@@ -47,6 +48,25 @@ or use one or more remote address(es) to load the config
 
 Combinations of local and remote config as well as config directories are
 possible.
+
+To exclude plugins selected by the configuration, e.g. plugins not
+supported on the target platform, use one or more glob patterns
+
+  custom_builder --config-dir /etc/telegraf/telegraf.d --exclude "inputs.kube*" --exclude "outputs.azure_*"
+
+To add parsers or serializers not referenced in the configuration, e.g. for
+plugins receiving the data-format at runtime, use one or more glob patterns
+
+  custom_builder --config-dir /etc/telegraf/telegraf.d --include "parsers.json*" --include "serializers.influx"
+
+To write a report of the included plugins and their contribution to the
+binary size use
+
+  custom_builder --config-dir /etc/telegraf/telegraf.d --report report.json
+
+To build the Android profile for arm64 devices run
+
+  GOOS=android GOARCH=arm64 custom_builder --config tools/custom_builder/profiles/android.conf
 `
 
 func usage() {
@@ -71,6 +91,9 @@ type cmdConfig struct {
 	root        string
 	configFiles []string
 	configDirs  []string
+	include     []string
+	exclude     []string
+	report      string
 }
 
 func main() {
@@ -89,30 +112,52 @@ func main() {
 			return nil
 		},
 	)
+	flag.Func("include",
+		"Include plugins matching the glob pattern in '<category>.<name>' form in addition to the configured ones, e.g. 'parsers.json*' (can be used multiple times)",
+		func(s string) error {
+			cfg.include = append(cfg.include, s)
+			return nil
+		},
+	)
+	flag.Func("exclude",
+		"Exclude plugins matching the glob pattern in '<category>.<name>' form, e.g. 'inputs.kube*' (can be used multiple times)",
+		func(s string) error {
+			cfg.exclude = append(cfg.exclude, s)
+			return nil
+		},
+	)
 	flag.BoolVar(&cfg.dryrun, "dry-run", false, "Skip the actual building step")
 	flag.BoolVar(&cfg.quiet, "quiet", false, "Print fewer log messages")
 	flag.BoolVar(&cfg.migrations, "migrations", false, "Include configuration migrations")
 	flag.BoolVar(&cfg.showtags, "tags", false, "Show build-tags used")
+	flag.StringVar(&cfg.report, "report", "", "Write a JSON report of the included plugins and their binary size to the given file, use '-' for stdout")
 
 	flag.Usage = usage
 	flag.Parse()
 
-	tagset, err := process(&cfg)
+	enabled, err := selectPackages(&cfg)
 	if err != nil {
 		log.Fatalln(err)
 	}
+	tagset := enabled.ExtractTags()
 	if len(tagset) == 0 {
 		log.Fatalln("Nothing selected!")
 	}
-	tags := "custom,"
+	var baseTags []string
 	if cfg.migrations {
-		tags += "migrations,"
+		baseTags = append(baseTags, "migrations")
 	}
-	tags += strings.Join(tagset, ",")
+	tags := buildTags(baseTags, tagset)
 	if cfg.showtags {
 		fmt.Printf("Build tags: %s\n", tags)
 	}
 
+	// Make sure all selected plugins are compiled into the binary for the
+	// target platform
+	if err := checkCompiled(cfg.root, tags, enabled); err != nil {
+		log.Fatalln(err)
+	}
+
 	if !cfg.dryrun {
 		// Perform the build
 		var out bytes.Buffer
@@ -134,9 +179,31 @@ func main() {
 	} else if !cfg.quiet {
 		log.Println("DRY-RUN: Skipping build.")
 	}
+
+	if cfg.report != "" {
+		r := newReport(enabled, tagset)
+		if !cfg.dryrun {
+			if err := r.addSizes(cfg.root, baseTags, cfg.quiet); err != nil {
+				log.Fatalf("Determining binary sizes failed: %v", err)
+			}
+		}
+		if err := r.write(cfg.report); err != nil {
+			log.Fatalf("Writing report failed: %v", err)
+		}
+	}
 }
 
 func process(cmdcfg *cmdConfig) ([]string, error) {
+	enabled, err := selectPackages(cmdcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the build-tags
+	return enabled.ExtractTags(), nil
+}
+
+func selectPackages(cmdcfg *cmdConfig) (*packageCollection, error) {
 	// Check configuration options
 	if len(cmdcfg.configFiles) == 0 && len(cmdcfg.configDirs) == 0 {
 		return nil, errors.New("no configuration specified")
@@ -169,10 +236,26 @@ func process(cmdcfg *cmdConfig) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("filtering packages failed: %w", err)
 	}
+
+	// Add the explicitly included plugins such as parsers and serializers
+	// not referenced in the configuration
+	for _, pattern := range cmdcfg.include {
+		if err := enabled.Include(&packages, pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	// Remove the explicitly excluded plugins
+	if len(cmdcfg.exclude) > 0 {
+		excluded, err := filter.Compile(cmdcfg.exclude)
+		if err != nil {
+			return nil, fmt.Errorf("compiling exclude patterns failed: %w", err)
+		}
+		enabled.Exclude(excluded)
+	}
 	if !cmdcfg.quiet {
 		enabled.Print()
 	}
 
-	// Extract the build-tags
-	return enabled.ExtractTags(), nil
+	return enabled, nil
 }
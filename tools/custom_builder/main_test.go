@@ -54,3 +54,222 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestExclude(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		dryrun:      true,
+		quiet:       true,
+		configFiles: []string{filepath.Join("testcases", "issue_13592", "telegraf.conf")},
+		exclude:     []string{"inputs.s*", "outputs.datadog"},
+		root:        "../..",
+	}
+
+	actual, err := process(cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"inputs.disk", "inputs.mem"}, actual)
+}
+
+func TestProfiles(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		dryrun:      true,
+		quiet:       true,
+		configFiles: []string{filepath.Join("profiles", "android.conf")},
+		root:        "../..",
+	}
+
+	actual, err := process(cfg)
+	require.NoError(t, err)
+	require.Contains(t, actual, "inputs.android")
+	require.Contains(t, actual, "serializers.influx")
+}
+
+func TestInclude(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		dryrun:      true,
+		quiet:       true,
+		configFiles: []string{filepath.Join("testcases", "issue_13592", "telegraf.conf")},
+		include:     []string{"parsers.json*", "serializers.influx", "inputs.mem"},
+		exclude:     []string{"parsers.json_v2"},
+		root:        "../..",
+	}
+
+	actual, err := process(cfg)
+	require.NoError(t, err)
+	expected := []string{
+		"inputs.disk",
+		"inputs.mem",
+		"inputs.swap",
+		"inputs.system",
+		"outputs.datadog",
+		"parsers.json",
+		"serializers.influx",
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestIncludeUnmatched(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		dryrun:      true,
+		quiet:       true,
+		configFiles: []string{filepath.Join("testcases", "issue_13592", "telegraf.conf")},
+		include:     []string{"parsers.jason"},
+		root:        "../..",
+	}
+
+	_, err := process(cfg)
+	require.ErrorContains(t, err, `include pattern "parsers.jason" does not match any plugin`)
+}
+
+func TestConfigDirs(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	// Create two configuration directories with a nested directory
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir2, "nested"), 0750))
+	configs := map[string]string{
+		filepath.Join(dir1, "cpu.conf"):              "[[inputs.cpu]]\n",
+		filepath.Join(dir1, "ignored.txt"):           "[[inputs.mem]]\n",
+		filepath.Join(dir2, "outputs.conf"):          "[[outputs.file]]\n  data_format = \"json\"\n",
+		filepath.Join(dir2, "nested", "inputs.conf"): "[[inputs.disk]]\n",
+	}
+	for fn, content := range configs {
+		require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+	}
+
+	cfg := &cmdConfig{
+		dryrun:     true,
+		quiet:      true,
+		configDirs: []string{dir1, dir2, dir1},
+		root:       "../..",
+	}
+
+	actual, err := process(cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"inputs.cpu", "inputs.disk", "outputs.file", "serializers.json"}, actual)
+}
+
+func TestMissing(t *testing.T) {
+	enabled := &packageCollection{
+		packages: map[string][]packageInfo{
+			"inputs": {
+				{Category: "inputs", Plugin: "cpu", Path: "plugins/inputs/cpu", Tag: "inputs.cpu"},
+				{Category: "inputs", Plugin: "mem", Path: "plugins/inputs/mem", Tag: "inputs.mem"},
+			},
+			"parsers": {
+				{Category: "parsers", Plugin: "influx", Path: "plugins/parsers/influx", Tag: "parsers.influx"},
+				{
+					Category: "parsers",
+					Plugin:   "influx_upstream",
+					Path:     "plugins/parsers/influx/influx_upstream",
+					Tag:      "parsers.influx",
+				},
+			},
+		},
+	}
+
+	deps := []string{
+		"github.com/influxdata/telegraf",
+		"github.com/influxdata/telegraf/plugins/inputs/cpu",
+		"github.com/influxdata/telegraf/plugins/parsers/influx",
+	}
+	require.Equal(t, []string{"inputs.mem", "parsers.influx_upstream"}, enabled.Missing(deps))
+}
+
+func TestCheckCompiled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test listing Telegraf dependencies in short mode")
+	}
+
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		quiet:       true,
+		configFiles: []string{filepath.Join("testcases", "issue_13592", "telegraf.conf")},
+		root:        "../..",
+	}
+	enabled, err := selectPackages(cfg)
+	require.NoError(t, err)
+	require.NoError(t, checkCompiled(cfg.root, buildTags(nil, enabled.ExtractTags()), enabled))
+
+	// Leaving out a tag must be detected
+	require.ErrorContains(t, checkCompiled(cfg.root, buildTags(nil, []string{"inputs.disk"}), enabled),
+		"configured plugins not compiled in: inputs.mem, inputs.swap, inputs.system, outputs.datadog")
+}
+
+func TestReport(t *testing.T) {
+	// Silence the output
+	log.SetOutput(io.Discard)
+
+	cfg := &cmdConfig{
+		quiet:       true,
+		configFiles: []string{filepath.Join("testcases", "issue_13592", "telegraf.conf")},
+		root:        "../..",
+	}
+	enabled, err := selectPackages(cfg)
+	require.NoError(t, err)
+
+	r := newReport(enabled, enabled.ExtractTags())
+	filename := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, r.write(filename))
+
+	expected := `{
+  "tags": [
+    "inputs.disk",
+    "inputs.mem",
+    "inputs.swap",
+    "inputs.system",
+    "outputs.datadog"
+  ],
+  "plugins": [
+    {
+      "category": "inputs",
+      "plugin": "disk",
+      "path": "plugins/inputs/disk",
+      "tag": "inputs.disk"
+    },
+    {
+      "category": "inputs",
+      "plugin": "mem",
+      "path": "plugins/inputs/mem",
+      "tag": "inputs.mem"
+    },
+    {
+      "category": "inputs",
+      "plugin": "swap",
+      "path": "plugins/inputs/swap",
+      "tag": "inputs.swap"
+    },
+    {
+      "category": "inputs",
+      "plugin": "system",
+      "path": "plugins/inputs/system",
+      "tag": "inputs.system"
+    },
+    {
+      "category": "outputs",
+      "plugin": "datadog",
+      "path": "plugins/outputs/datadog",
+      "tag": "outputs.datadog"
+    }
+  ]
+}
+`
+	actual, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(actual))
+}
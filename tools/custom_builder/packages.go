@@ -18,6 +18,9 @@ import (
 	"github.com/influxdata/telegraf/filter"
 )
 
+// Go module path used to construct the import path of the packages
+const modulePath = "github.com/influxdata/telegraf"
+
 // Define the categories we can handle and package filters
 var packageFilter = filter.MustCompile([]string{
 	"*/all",
@@ -151,6 +154,80 @@ func (p *packageCollection) CollectAvailable() error {
 	return nil
 }
 
+// Exclude removes the packages with a '<category>.<plugin>' name matching the
+// given filter
+func (p *packageCollection) Exclude(f filter.Filter) {
+	for category, pkgs := range p.packages {
+		kept := make([]packageInfo, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			if f.Match(category + "." + pkg.Plugin) {
+				log.Printf("Excluding %s.%s", category, pkg.Plugin)
+				continue
+			}
+			kept = append(kept, pkg)
+		}
+		p.packages[category] = kept
+	}
+}
+
+// Include adds the packages of the available collection with a
+// '<category>.<plugin>' name matching the given pattern
+func (p *packageCollection) Include(available *packageCollection, pattern string) error {
+	f, err := filter.Compile([]string{pattern})
+	if err != nil {
+		return fmt.Errorf("compiling include pattern %q failed: %w", pattern, err)
+	}
+
+	var matched bool
+	for category, pkgs := range available.packages {
+		for _, pkg := range pkgs {
+			if !f.Match(category + "." + pkg.Plugin) {
+				continue
+			}
+			matched = true
+			if p.contains(category, pkg.Plugin) {
+				continue
+			}
+			log.Printf("Including %s.%s", category, pkg.Plugin)
+			p.packages[category] = append(p.packages[category], pkg)
+		}
+	}
+	if !matched {
+		return fmt.Errorf("include pattern %q does not match any plugin", pattern)
+	}
+	return nil
+}
+
+func (p *packageCollection) contains(category, plugin string) bool {
+	for _, pkg := range p.packages[category] {
+		if pkg.Plugin == plugin {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the '<category>.<plugin>' names of the packages not
+// contained in the given list of Go import paths
+func (p *packageCollection) Missing(importPaths []string) []string {
+	linked := make(map[string]bool, len(importPaths))
+	for _, path := range importPaths {
+		linked[path] = true
+	}
+
+	var missing []string
+	for category, pkgs := range p.packages {
+		for _, pkg := range pkgs {
+			if !linked[modulePath+"/"+pkg.Path] {
+				missing = append(missing, category+"."+pkg.Plugin)
+			}
+		}
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
 func (p *packageCollection) ExtractTags() []string {
 	var tags []string
 	for category, pkgs := range p.packages {
@@ -216,7 +293,7 @@ func findAddStatements(decl *ast.FuncDecl, pluginType string) []*ast.CallExpr {
 		if !ok {
 			continue
 		}
-		if e.Name == pluginType && (fun.Sel.Name == "Add" || fun.Sel.Name == "AddStreaming") {
+		if e.Name == pluginType && (fun.Sel.Name == "Add" || fun.Sel.Name == "AddStreaming" || fun.Sel.Name == "AddBatch") {
 			statements = append(statements, call)
 		}
 	}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type reportPlugin struct {
+	Category  string `json:"category"`
+	Plugin    string `json:"plugin"`
+	Path      string `json:"path"`
+	Tag       string `json:"tag"`
+	SizeDelta int64  `json:"size_delta,omitempty"`
+}
+
+type report struct {
+	Tags       []string       `json:"tags"`
+	BinarySize int64          `json:"binary_size,omitempty"`
+	Plugins    []reportPlugin `json:"plugins"`
+}
+
+func newReport(enabled *packageCollection, tags []string) *report {
+	r := &report{Tags: tags}
+	for _, category := range categories {
+		for _, pkg := range enabled.packages[category] {
+			r.Plugins = append(r.Plugins, reportPlugin{
+				Category: category,
+				Plugin:   pkg.Plugin,
+				Path:     pkg.Path,
+				Tag:      pkg.Tag,
+			})
+		}
+	}
+	sort.SliceStable(r.Plugins, func(i, j int) bool {
+		if r.Plugins[i].Category != r.Plugins[j].Category {
+			return r.Plugins[i].Category < r.Plugins[j].Category
+		}
+		return r.Plugins[i].Plugin < r.Plugins[j].Plugin
+	})
+	return r
+}
+
+// addSizes builds Telegraf with all tags and once more for each plugin tag
+// leaving out that tag. The size difference is the amount of binary size
+// attributable to the plugin, i.e. the plugin code and all dependencies not
+// shared with other plugins. Plugins registered by the same package share the
+// same tag and consequently the same delta.
+func (r *report) addSizes(root string, baseTags []string, quiet bool) error {
+	tmpdir, err := os.MkdirTemp("", "custom_builder")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory failed: %w", err)
+	}
+	defer os.RemoveAll(tmpdir)
+	output := filepath.Join(tmpdir, "telegraf")
+
+	if !quiet {
+		log.Println("Building reference binary for size report...")
+	}
+	size, err := buildBinary(root, buildTags(baseTags, r.Tags), output)
+	if err != nil {
+		return fmt.Errorf("building reference binary failed: %w", err)
+	}
+	r.BinarySize = size
+
+	deltas := make(map[string]int64, len(r.Tags))
+	for i, tag := range r.Tags {
+		if !quiet {
+			log.Printf("Building without %q for size report (%d/%d)...", tag, i+1, len(r.Tags))
+		}
+		tags := make([]string, 0, len(r.Tags)-1)
+		tags = append(tags, r.Tags[:i]...)
+		tags = append(tags, r.Tags[i+1:]...)
+		s, err := buildBinary(root, buildTags(baseTags, tags), output)
+		if err != nil {
+			return fmt.Errorf("building without %q failed: %w", tag, err)
+		}
+		deltas[tag] = size - s
+	}
+	for i := range r.Plugins {
+		r.Plugins[i].SizeDelta = deltas[r.Plugins[i].Tag]
+	}
+
+	return nil
+}
+
+func (r *report) write(filename string) error {
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+
+	if filename == "-" {
+		_, err := os.Stdout.Write(buf)
+		return err
+	}
+	return os.WriteFile(filename, buf, 0640)
+}
+
+// buildTags returns the comma-separated build-tags for the custom build
+func buildTags(baseTags, pluginTags []string) string {
+	tags := make([]string, 0, len(baseTags)+len(pluginTags)+1)
+	tags = append(tags, "custom")
+	tags = append(tags, baseTags...)
+	tags = append(tags, pluginTags...)
+	return strings.Join(tags, ",")
+}
+
+// buildBinary builds Telegraf with the given tags and returns the size of the
+// resulting binary
+func buildBinary(root, tags, output string) (int64, error) {
+	var out bytes.Buffer
+	cmd := exec.Command("go", "build", "-tags", tags, "-o", output, "./cmd/telegraf")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("%w: %s", err, out.String())
+	}
+
+	stat, err := os.Stat(output)
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
+
+// checkCompiled fails if any of the enabled packages is not linked into
+// Telegraf when building with the given tags for the target platform
+func checkCompiled(root, tags string, enabled *packageCollection) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-tags", tags, "-deps", "-f", "{{.ImportPath}}", "./cmd/telegraf")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("listing dependencies failed: %w: %s", err, stderr.String())
+	}
+
+	if missing := enabled.Missing(strings.Fields(string(out))); len(missing) > 0 {
+		return errors.New("configured plugins not compiled in: " + strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -68,6 +68,7 @@ var (
 
 	metaOSes = []string{
 		"all",
+		"android",
 		"freebsd",
 		"linux",
 		"macos",